@@ -0,0 +1,14 @@
+package main
+
+import (
+	"devices_crud/internal/cli"
+	"fmt"
+	"os"
+)
+
+func main() {
+	if err := cli.NewRootCommand().Execute(); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+}
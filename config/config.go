@@ -1,8 +1,23 @@
 package config
 
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
 type Config struct {
 	Router         RouterConfig
 	DevicesService DevicesServiceConfig
+	Auth           AuthConfig
+	Tenancy        TenancyConfig
+	RateLimit      RateLimitConfig
+	Metrics        MetricsConfig
+	Tracing        TracingConfig
+	Logging        LoggingConfig
+	Contracts      ContractsConfig
+	Connectivity   ConnectivityConfig
 }
 
 type RouterConfig struct {
@@ -11,6 +26,89 @@ type RouterConfig struct {
 
 type DevicesServiceConfig struct {
 	UseMocks bool
+	// UniqueKeys are fields joined by "+" no two devices of a tenant may
+	// share, e.g. "brand+attributes.serial".
+	UniqueKeys []string
+}
+
+type AuthConfig struct {
+	Enabled bool
+	// APIKeysFile is a JSON array of {"name", "hash", "roles"} entries where hash is
+	// the hex encoded SHA-256 of the key.
+	APIKeysFile string
+	// RolesFile is a JSON object mapping role names to permissions, the built-in
+	// roles are used when it is empty.
+	RolesFile  string
+	JWKSFile   string
+	HMACSecret string
+	Issuer     string
+	Audience   string
+}
+
+type TenancyConfig struct {
+	// DefaultQuota is the maximum number of devices of tenants without an entry in
+	// Quotas. Zero means unlimited.
+	DefaultQuota int
+	Quotas       map[string]int
+}
+
+type RateLimitConfig struct {
+	Enabled bool
+	Default RateLimit
+	// Groups, Routes and Operations override Default for a route group
+	// ("devices", "graphql"), a REST route ("GET /v1/devices/search") or a
+	// GraphQL operation name.
+	Groups     map[string]RateLimit
+	Routes     map[string]RateLimit
+	Operations map[string]RateLimit
+}
+
+type MetricsConfig struct {
+	// Enabled exposes the Prometheus metrics on /metrics.
+	Enabled bool
+}
+
+type TracingConfig struct {
+	// Exporter is "none", "stdout" or "file".
+	Exporter string
+	// File receives the spans of the "file" exporter.
+	File string
+}
+
+type LoggingConfig struct {
+	// Format is "json" or "text".
+	Format string
+	Level  string
+	// Levels overrides Level per component ("service", "rest", "graphql").
+	Levels map[string]string
+	// Redact lists attribute keys to redact on top of the built-in ones.
+	Redact []string
+}
+
+type ContractsConfig struct {
+	// ExpiryInterval is how often contract expiries are checked, zero disables
+	// the check.
+	ExpiryInterval time.Duration
+	// ExpiryWindows are the days before the end of a contract its expiry is
+	// notified at.
+	ExpiryWindows []int
+}
+
+type ConnectivityConfig struct {
+	// HeartbeatTimeout is how long after its last heartbeat a device goes
+	// offline.
+	HeartbeatTimeout time.Duration
+	// TypeTimeouts override HeartbeatTimeout per device type.
+	TypeTimeouts map[string]time.Duration
+	// CheckInterval is how often devices missing their heartbeat are looked
+	// for, zero disables the check.
+	CheckInterval time.Duration
+}
+
+// RateLimit allows Rate requests per second with bursts of Burst requests.
+type RateLimit struct {
+	Rate  float64
+	Burst int
 }
 
 func NewConfig() *Config {
@@ -20,7 +118,184 @@ func NewConfig() *Config {
 		},
 
 		DevicesService: DevicesServiceConfig{
-			UseMocks: true,
+			UseMocks:   true,
+			UniqueKeys: getEnvList("DEVICE_UNIQUE_KEYS"),
+		},
+
+		Auth: AuthConfig{
+			Enabled:     os.Getenv("AUTH_ENABLED") == "true",
+			APIKeysFile: os.Getenv("AUTH_API_KEYS_FILE"),
+			RolesFile:   os.Getenv("AUTH_ROLES_FILE"),
+			JWKSFile:    os.Getenv("AUTH_JWKS_FILE"),
+			HMACSecret:  os.Getenv("AUTH_HMAC_SECRET"),
+			Issuer:      os.Getenv("AUTH_ISSUER"),
+			Audience:    os.Getenv("AUTH_AUDIENCE"),
+		},
+
+		Tenancy: TenancyConfig{
+			DefaultQuota: getEnvInt("TENANT_DEFAULT_QUOTA", 0),
+			Quotas:       getEnvIntMap("TENANT_QUOTAS"),
+		},
+
+		RateLimit: RateLimitConfig{
+			Enabled: os.Getenv("RATE_LIMIT_ENABLED") == "true",
+			Default: RateLimit{
+				Rate:  getEnvFloat("RATE_LIMIT_RATE", 10),
+				Burst: getEnvInt("RATE_LIMIT_BURST", 20),
+			},
+			Groups:     getEnvRateLimits("RATE_LIMIT_GROUPS"),
+			Routes:     getEnvRateLimits("RATE_LIMIT_ROUTES"),
+			Operations: getEnvRateLimits("RATE_LIMIT_OPERATIONS"),
+		},
+
+		Metrics: MetricsConfig{
+			Enabled: os.Getenv("METRICS_ENABLED") != "false",
+		},
+
+		Tracing: TracingConfig{
+			Exporter: getEnv("TRACING_EXPORTER", "none"),
+			File:     os.Getenv("TRACING_FILE"),
+		},
+
+		Logging: LoggingConfig{
+			Format: getEnv("LOG_FORMAT", "text"),
+			Level:  getEnv("LOG_LEVEL", "info"),
+			Levels: getEnvStringMap("LOG_LEVELS"),
+			Redact: getEnvList("LOG_REDACT"),
+		},
+
+		Contracts: ContractsConfig{
+			ExpiryInterval: getEnvDuration("CONTRACT_EXPIRY_INTERVAL", time.Hour),
+			ExpiryWindows:  getEnvInts("CONTRACT_EXPIRY_WINDOWS"),
 		},
+
+		Connectivity: ConnectivityConfig{
+			HeartbeatTimeout: getEnvDuration("HEARTBEAT_TIMEOUT", 5*time.Minute),
+			TypeTimeouts:     getEnvDurationMap("HEARTBEAT_TYPE_TIMEOUTS"),
+			CheckInterval:    getEnvDuration("CONNECTIVITY_CHECK_INTERVAL", 30*time.Second),
+		},
+	}
+}
+
+func getEnv(key string, fallback string) string {
+	if value, ok := os.LookupEnv(key); ok {
+		return value
+	}
+	return fallback
+}
+
+func getEnvInt(key string, fallback int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
+func getEnvFloat(key string, fallback float64) float64 {
+	value, err := strconv.ParseFloat(os.Getenv(key), 64)
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
+// getEnvRateLimits parses values of the form "name=rate:burst,other=rate:burst",
+// skipping malformed entries.
+func getEnvRateLimits(key string) map[string]RateLimit {
+	limits := make(map[string]RateLimit)
+	for _, entry := range strings.Split(os.Getenv(key), ",") {
+		name, value, found := strings.Cut(strings.TrimSpace(entry), "=")
+		if !found {
+			continue
+		}
+		rate, burst, found := strings.Cut(value, ":")
+		if !found {
+			continue
+		}
+		parsedRate, err := strconv.ParseFloat(rate, 64)
+		if err != nil {
+			continue
+		}
+		parsedBurst, err := strconv.Atoi(burst)
+		if err != nil {
+			continue
+		}
+		limits[name] = RateLimit{Rate: parsedRate, Burst: parsedBurst}
+	}
+	return limits
+}
+
+// getEnvIntMap parses values of the form "a=1,b=2", skipping malformed entries.
+func getEnvIntMap(key string) map[string]int {
+	values := make(map[string]int)
+	for _, entry := range strings.Split(os.Getenv(key), ",") {
+		name, value, found := strings.Cut(strings.TrimSpace(entry), "=")
+		if !found {
+			continue
+		}
+		number, err := strconv.Atoi(value)
+		if err != nil {
+			continue
+		}
+		values[name] = number
+	}
+	return values
+}
+
+// getEnvDurationMap parses values of the form "a=5m,b=1h", skipping malformed
+// entries.
+func getEnvDurationMap(key string) map[string]time.Duration {
+	values := make(map[string]time.Duration)
+	for name, value := range getEnvStringMap(key) {
+		duration, err := time.ParseDuration(value)
+		if err != nil {
+			continue
+		}
+		values[name] = duration
+	}
+	return values
+}
+
+// getEnvStringMap parses values of the form "a=x,b=y", skipping malformed entries.
+func getEnvStringMap(key string) map[string]string {
+	values := make(map[string]string)
+	for _, entry := range strings.Split(os.Getenv(key), ",") {
+		name, value, found := strings.Cut(strings.TrimSpace(entry), "=")
+		if !found {
+			continue
+		}
+		values[name] = value
+	}
+	return values
+}
+
+// getEnvList parses comma separated values, skipping empty entries.
+func getEnvList(key string) []string {
+	var values []string
+	for _, entry := range strings.Split(os.Getenv(key), ",") {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			values = append(values, entry)
+		}
+	}
+	return values
+}
+
+// getEnvInts parses comma separated numbers, skipping malformed entries.
+func getEnvInts(key string) []int {
+	var values []int
+	for _, entry := range getEnvList(key) {
+		if number, err := strconv.Atoi(entry); err == nil {
+			values = append(values, number)
+		}
 	}
+	return values
 }
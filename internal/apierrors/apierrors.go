@@ -0,0 +1,87 @@
+// Package apierrors maps the errors of the device service to the HTTP status
+// and error code the drivers report, so that the REST and GraphQL APIs and the
+// client agree on them.
+package apierrors
+
+import (
+	"devices_crud/internal/auth"
+	"devices_crud/internal/devices/model"
+	"errors"
+	"log/slog"
+)
+
+// Code describes how the drivers report errors matching Err.
+type Code struct {
+	Err error
+	// Status is the HTTP status of REST responses.
+	Status int
+	// Code is reported in the "code" field of REST responses and in the "code"
+	// extension of GraphQL errors.
+	Code string
+	// Message replaces the message of the error in responses when set, for
+	// errors whose details must not reach clients.
+	Message string
+	// Level is the level the error is logged with.
+	Level slog.Level
+}
+
+// Codes lists the errors the drivers report with a code. The first entry the
+// error matches with errors.Is wins; other errors are internal errors.
+var Codes = []Code{
+	{Err: auth.ErrForbidden, Status: 403, Code: "FORBIDDEN", Message: "Forbidden", Level: slog.LevelWarn},
+	{Err: auth.ErrUnauthenticated, Status: 401, Code: "UNAUTHENTICATED", Message: "Unauthorized", Level: slog.LevelWarn},
+	{Err: model.ErrDeviceNotFound, Status: 404, Code: "NOT_FOUND", Message: "Device not found"},
+	{Err: model.ErrInvalidFilter, Status: 400, Code: "INVALID_FILTER"},
+	{Err: model.ErrInvalidSelector, Status: 400, Code: "INVALID_SELECTOR"},
+	{Err: model.ErrInvalidLabels, Status: 400, Code: "INVALID_LABELS"},
+	{Err: model.ErrInvalidAttributes, Status: 400, Code: "INVALID_ATTRIBUTES"},
+	{Err: model.ErrUnknownDeviceType, Status: 400, Code: "UNKNOWN_DEVICE_TYPE"},
+	{Err: model.ErrInvalidDeviceType, Status: 400, Code: "INVALID_DEVICE_TYPE"},
+	{Err: model.ErrDeviceTypeInUse, Status: 409, Code: "DEVICE_TYPE_IN_USE"},
+	{Err: model.ErrInvalidStatus, Status: 400, Code: "INVALID_STATUS"},
+	{Err: model.ErrIllegalTransition, Status: 409, Code: "ILLEGAL_TRANSITION"},
+	{Err: model.ErrInvalidTransition, Status: 400, Code: "INVALID_TRANSITION"},
+	{Err: model.ErrInvalidAssignment, Status: 400, Code: "INVALID_ASSIGNMENT"},
+	{Err: model.ErrDeviceUnavailable, Status: 409, Code: "DEVICE_UNAVAILABLE"},
+	{Err: model.ErrDeviceAssigned, Status: 409, Code: "DEVICE_ASSIGNED"},
+	{Err: model.ErrDeviceNotAssigned, Status: 409, Code: "DEVICE_NOT_ASSIGNED"},
+	{Err: model.ErrInvalidLocation, Status: 400, Code: "INVALID_LOCATION"},
+	{Err: model.ErrUnknownLocation, Status: 400, Code: "UNKNOWN_LOCATION"},
+	{Err: model.ErrLocationCycle, Status: 409, Code: "LOCATION_CYCLE"},
+	{Err: model.ErrLocationNotEmpty, Status: 409, Code: "LOCATION_NOT_EMPTY"},
+	{Err: model.ErrDeviceMoved, Status: 409, Code: "DEVICE_MOVED"},
+	{Err: model.ErrInvalidRelationship, Status: 400, Code: "INVALID_RELATIONSHIP"},
+	{Err: model.ErrRelationshipConflict, Status: 409, Code: "RELATIONSHIP_CONFLICT"},
+	{Err: model.ErrRelationshipCycle, Status: 409, Code: "RELATIONSHIP_CYCLE"},
+	{Err: model.ErrDeviceHasChildren, Status: 409, Code: "DEVICE_HAS_CHILDREN"},
+	{Err: model.ErrInvalidMaintenancePlan, Status: 400, Code: "INVALID_MAINTENANCE_PLAN"},
+	{Err: model.ErrInvalidServiceRecord, Status: 400, Code: "INVALID_SERVICE_RECORD"},
+	{Err: model.ErrInvalidContract, Status: 400, Code: "INVALID_CONTRACT"},
+	{Err: model.ErrInvalidReading, Status: 400, Code: "INVALID_READING"},
+	{Err: model.ErrInvalidConnectivity, Status: 400, Code: "INVALID_CONNECTIVITY"},
+	{Err: model.ErrInvalidSoftware, Status: 400, Code: "INVALID_SOFTWARE"},
+	{Err: model.ErrInvalidSoftwarePolicy, Status: 400, Code: "INVALID_SOFTWARE_POLICY"},
+	{Err: model.ErrDuplicateDevice, Status: 409, Code: "DUPLICATE_DEVICE"},
+	{Err: model.ErrInvalidMerge, Status: 400, Code: "INVALID_MERGE"},
+	{Err: model.ErrInvalidThreshold, Status: 400, Code: "INVALID_THRESHOLD"},
+	{Err: model.ErrInvalidBrand, Status: 400, Code: "INVALID_BRAND"},
+	{Err: model.ErrQuotaExceeded, Status: 403, Code: "QUOTA_EXCEEDED", Message: "Device quota exceeded", Level: slog.LevelWarn},
+}
+
+// Lookup returns the entry of Codes matching err, false for internal errors.
+func Lookup(err error) (Code, bool) {
+	for _, code := range Codes {
+		if errors.Is(err, code.Err) {
+			return code, true
+		}
+	}
+	return Code{}, false
+}
+
+// MessageOf returns the message of err as reported to clients.
+func (c Code) MessageOf(err error) string {
+	if c.Message != "" {
+		return c.Message
+	}
+	return err.Error()
+}
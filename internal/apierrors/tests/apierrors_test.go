@@ -0,0 +1,36 @@
+package tests
+
+import (
+	"devices_crud/internal/apierrors"
+	"devices_crud/internal/auth"
+	"devices_crud/internal/devices/model"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShouldLookUpWrappedErrors(t *testing.T) {
+	err := fmt.Errorf("%w: d-1", model.ErrDeviceNotFound)
+	code, ok := apierrors.Lookup(err)
+	assert.True(t, ok)
+	assert.Equal(t, 404, code.Status)
+	assert.Equal(t, "NOT_FOUND", code.Code)
+	assert.Equal(t, "Device not found", code.MessageOf(err))
+
+	code, _ = apierrors.Lookup(fmt.Errorf("%w: missing devices:write", auth.ErrForbidden))
+	assert.Equal(t, 403, code.Status)
+
+	_, ok = apierrors.Lookup(errors.New("connection refused"))
+	assert.False(t, ok)
+}
+
+func TestShouldReportEveryCodeOnce(t *testing.T) {
+	seen := map[string]bool{}
+	for _, code := range apierrors.Codes {
+		assert.False(t, seen[code.Code], code.Code)
+		seen[code.Code] = true
+		assert.GreaterOrEqual(t, code.Status, 400, code.Code)
+	}
+}
@@ -0,0 +1,86 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+const apiKeyHeader = "X-API-Key"
+
+// APIKey is a static key as stored at rest: only the hex encoded SHA-256 of the
+// key is kept, see HashAPIKey.
+type APIKey struct {
+	Name   string   `json:"name"`
+	Hash   string   `json:"hash"`
+	Roles  []string `json:"roles"`
+	Tenant string   `json:"tenant"`
+}
+
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// LoadAPIKeys reads a JSON array of APIKey entries.
+func LoadAPIKeys(path string) ([]APIKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading API keys %s: %w", path, err)
+	}
+
+	var keys []APIKey
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return nil, fmt.Errorf("parsing API keys %s: %w", path, err)
+	}
+	for i, key := range keys {
+		if _, err := hex.DecodeString(key.Hash); err != nil || len(key.Hash) != sha256.Size*2 {
+			return nil, fmt.Errorf("API key %d (%s) in %s: hash must be a hex encoded SHA-256", i, key.Name, path)
+		}
+		keys[i].Hash = strings.ToLower(key.Hash)
+	}
+
+	return keys, nil
+}
+
+type apiKeyAuthenticator struct {
+	keys []APIKey
+}
+
+func NewAPIKeyAuthenticator(keys []APIKey) Authenticator {
+	return &apiKeyAuthenticator{keys: keys}
+}
+
+// Authenticate accepts the key from the X-API-Key header or as a bearer token
+// that is not a JWT.
+func (a *apiKeyAuthenticator) Authenticate(ctx context.Context, header http.Header) (*Principal, error) {
+	key := header.Get(apiKeyHeader)
+	if key == "" {
+		if token := bearerToken(header); token != "" && !looksLikeJWT(token) {
+			key = token
+		}
+	}
+	if key == "" {
+		return nil, ErrNoCredentials
+	}
+
+	hash := []byte(HashAPIKey(key))
+	for _, candidate := range a.keys {
+		if subtle.ConstantTimeCompare(hash, []byte(candidate.Hash)) == 1 {
+			return &Principal{
+				Subject:  candidate.Name,
+				Method:   MethodAPIKey,
+				Roles:    candidate.Roles,
+				TenantID: candidate.Tenant,
+			}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: unknown API key", ErrUnauthenticated)
+}
@@ -0,0 +1,87 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Authenticator resolves the caller of a request from its headers. Both the REST
+// and the GraphQL driver hand over the incoming request headers.
+type Authenticator interface {
+	Authenticate(ctx context.Context, header http.Header) (*Principal, error)
+}
+
+type Options struct {
+	APIKeysFile string
+	JWKSFile    string
+	HMACSecret  string
+	Issuer      string
+	Audience    string
+}
+
+// NewAuthenticator builds an authenticator accepting every credential type
+// configured in opts.
+func NewAuthenticator(opts *Options) (Authenticator, error) {
+	chain := Chain{}
+
+	if opts.APIKeysFile != "" {
+		keys, err := LoadAPIKeys(opts.APIKeysFile)
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, NewAPIKeyAuthenticator(keys))
+	}
+
+	if opts.JWKSFile != "" || opts.HMACSecret != "" {
+		jwtOptions := &JWTOptions{
+			HMACSecret: []byte(opts.HMACSecret),
+			Issuer:     opts.Issuer,
+			Audience:   opts.Audience,
+		}
+		if opts.JWKSFile != "" {
+			jwks, err := LoadJWKS(opts.JWKSFile)
+			if err != nil {
+				return nil, err
+			}
+			jwtOptions.JWKS = jwks
+		}
+		chain = append(chain, NewJWTAuthenticator(jwtOptions))
+	}
+
+	if len(chain) == 0 {
+		return nil, errors.New("authentication is enabled but no API keys, JWKS or HMAC secret are configured")
+	}
+
+	return chain, nil
+}
+
+// Chain tries each authenticator in turn until one recognizes the credentials.
+type Chain []Authenticator
+
+func (c Chain) Authenticate(ctx context.Context, header http.Header) (*Principal, error) {
+	for _, authenticator := range c {
+		principal, err := authenticator.Authenticate(ctx, header)
+		if errors.Is(err, ErrNoCredentials) {
+			continue
+		}
+		return principal, err
+	}
+	return nil, fmt.Errorf("%w: %w", ErrUnauthenticated, ErrNoCredentials)
+}
+
+func bearerToken(header http.Header) string {
+	value := header.Get("Authorization")
+	scheme, token, found := strings.Cut(value, " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
+
+// looksLikeJWT tells JWTs apart from opaque API keys sent as bearer tokens.
+func looksLikeJWT(token string) bool {
+	return strings.Count(token, ".") == 2
+}
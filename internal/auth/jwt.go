@@ -0,0 +1,90 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type JWTOptions struct {
+	// HMACSecret verifies HS256 tokens that do not match a symmetric JWKS key.
+	HMACSecret []byte
+	JWKS       *JWKS
+	// Issuer and Audience are checked against the iss and aud claims when set.
+	Issuer   string
+	Audience string
+}
+
+type claims struct {
+	jwt.RegisteredClaims
+	Roles  []string `json:"roles"`
+	Tenant string   `json:"tenant"`
+}
+
+type jwtAuthenticator struct {
+	opts   *JWTOptions
+	parser *jwt.Parser
+}
+
+func NewJWTAuthenticator(opts *JWTOptions) Authenticator {
+	parserOptions := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg(), jwt.SigningMethodRS256.Alg()}),
+		jwt.WithExpirationRequired(),
+	}
+	if opts.Issuer != "" {
+		parserOptions = append(parserOptions, jwt.WithIssuer(opts.Issuer))
+	}
+	if opts.Audience != "" {
+		parserOptions = append(parserOptions, jwt.WithAudience(opts.Audience))
+	}
+
+	return &jwtAuthenticator{
+		opts:   opts,
+		parser: jwt.NewParser(parserOptions...),
+	}
+}
+
+func (a *jwtAuthenticator) Authenticate(ctx context.Context, header http.Header) (*Principal, error) {
+	token := bearerToken(header)
+	if token == "" || !looksLikeJWT(token) {
+		return nil, ErrNoCredentials
+	}
+
+	parsed := &claims{}
+	if _, err := a.parser.ParseWithClaims(token, parsed, a.key); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUnauthenticated, err)
+	}
+
+	return &Principal{
+		Subject:  parsed.Subject,
+		Method:   MethodJWT,
+		Roles:    parsed.Roles,
+		TenantID: parsed.Tenant,
+	}, nil
+}
+
+func (a *jwtAuthenticator) key(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	switch token.Method.Alg() {
+	case jwt.SigningMethodRS256.Alg():
+		if a.opts.JWKS != nil {
+			if key, ok := a.opts.JWKS.rsaKey(kid); ok {
+				return key, nil
+			}
+		}
+	case jwt.SigningMethodHS256.Alg():
+		if a.opts.JWKS != nil {
+			if key, ok := a.opts.JWKS.hmacKey(kid); ok {
+				return key, nil
+			}
+		}
+		if len(a.opts.HMACSecret) > 0 {
+			return a.opts.HMACSecret, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no %s verification key for kid %q", token.Method.Alg(), kid)
+}
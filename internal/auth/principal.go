@@ -0,0 +1,38 @@
+package auth
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	// ErrNoCredentials is returned by an Authenticator when the request carries no
+	// credentials it understands, so that the next authenticator can be tried.
+	ErrNoCredentials   = errors.New("no credentials")
+	ErrUnauthenticated = errors.New("unauthenticated")
+)
+
+const (
+	MethodAPIKey = "api_key"
+	MethodJWT    = "jwt"
+)
+
+// Principal is the authenticated caller of a request.
+type Principal struct {
+	Subject string
+	Method  string
+	Roles   []string
+	// TenantID binds the principal to a single tenant when set.
+	TenantID string
+}
+
+type principalKey struct{}
+
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(*Principal)
+	return principal, ok && principal != nil
+}
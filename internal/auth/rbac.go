@@ -0,0 +1,77 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+var ErrForbidden = errors.New("forbidden")
+
+type Permission string
+
+const (
+	PermissionDevicesRead   Permission = "devices:read"
+	PermissionDevicesWrite  Permission = "devices:write"
+	PermissionDevicesDelete Permission = "devices:delete"
+	// PermissionDevicesAdmin implies every other permission.
+	PermissionDevicesAdmin Permission = "devices:admin"
+)
+
+// DefaultRoles is used when no roles file is configured.
+var DefaultRoles = map[string][]Permission{
+	"intern":   {PermissionDevicesRead},
+	"viewer":   {PermissionDevicesRead},
+	"editor":   {PermissionDevicesRead, PermissionDevicesWrite},
+	"operator": {PermissionDevicesRead, PermissionDevicesWrite, PermissionDevicesDelete},
+	"admin":    {PermissionDevicesAdmin},
+}
+
+// Authorizer grants permissions to principals through their roles.
+type Authorizer struct {
+	roles map[string][]Permission
+}
+
+func NewAuthorizer(roles map[string][]Permission) *Authorizer {
+	return &Authorizer{roles: roles}
+}
+
+// LoadRoles reads a JSON object mapping role names to lists of permissions.
+func LoadRoles(path string) (map[string][]Permission, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading roles %s: %w", path, err)
+	}
+
+	var roles map[string][]Permission
+	if err := json.Unmarshal(data, &roles); err != nil {
+		return nil, fmt.Errorf("parsing roles %s: %w", path, err)
+	}
+	return roles, nil
+}
+
+// Authorize checks that the principal in ctx holds permission.
+func (a *Authorizer) Authorize(ctx context.Context, permission Permission) error {
+	principal, ok := PrincipalFromContext(ctx)
+	if !ok {
+		return ErrUnauthenticated
+	}
+
+	if a.HasPermission(principal, permission) {
+		return nil
+	}
+	return fmt.Errorf("%w: %s requires %s", ErrForbidden, principal.Subject, permission)
+}
+
+func (a *Authorizer) HasPermission(principal *Principal, permission Permission) bool {
+	for _, role := range principal.Roles {
+		for _, granted := range a.roles[role] {
+			if granted == permission || granted == PermissionDevicesAdmin {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,193 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"devices_crud/internal/auth"
+	"devices_crud/internal/devices"
+	"devices_crud/internal/drivers/graph"
+	"devices_crud/internal/drivers/rest"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	apiKey     = "s3cr3t-api-key"
+	hmacSecret = "hmac-secret"
+)
+
+func writeFile(t *testing.T, name string, content any) string {
+	data, err := json.Marshal(content)
+	assert.Nil(t, err)
+	path := filepath.Join(t.TempDir(), name)
+	assert.Nil(t, os.WriteFile(path, data, 0o600))
+	return path
+}
+
+func setupAuthenticator(t *testing.T, rsaKey *rsa.PrivateKey) auth.Authenticator {
+	keysFile := writeFile(t, "keys.json", []auth.APIKey{
+		{Name: "ops", Hash: auth.HashAPIKey(apiKey), Roles: []string{"admin"}},
+	})
+	jwksFile := writeFile(t, "jwks.json", map[string]any{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "rsa-1",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(rsaKey.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(rsaKey.E)).Bytes()),
+		}},
+	})
+
+	authenticator, err := auth.NewAuthenticator(&auth.Options{
+		APIKeysFile: keysFile,
+		JWKSFile:    jwksFile,
+		HMACSecret:  hmacSecret,
+		Issuer:      "devices-tests",
+	})
+	assert.Nil(t, err)
+	return authenticator
+}
+
+func signToken(t *testing.T, method jwt.SigningMethod, key any, kid string, expiresAt time.Time) string {
+	token := jwt.NewWithClaims(method, jwt.MapClaims{
+		"sub":   "jane",
+		"iss":   "devices-tests",
+		"exp":   expiresAt.Unix(),
+		"roles": []string{"viewer"},
+	})
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString(key)
+	assert.Nil(t, err)
+	return signed
+}
+
+func headerWith(key string, value string) http.Header {
+	header := http.Header{}
+	header.Set(key, value)
+	return header
+}
+
+func TestShouldAuthenticateCredentials(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	authenticator := setupAuthenticator(t, rsaKey)
+	ctx := context.Background()
+	hour := time.Now().Add(time.Hour)
+
+	principal, err := authenticator.Authenticate(ctx, headerWith("X-API-Key", apiKey))
+	assert.Nil(t, err)
+	assert.Equal(t, "ops", principal.Subject)
+	assert.Equal(t, auth.MethodAPIKey, principal.Method)
+	assert.Equal(t, []string{"admin"}, principal.Roles)
+
+	principal, err = authenticator.Authenticate(ctx, headerWith("Authorization", "Bearer "+apiKey))
+	assert.Nil(t, err)
+	assert.Equal(t, "ops", principal.Subject)
+
+	hs256 := signToken(t, jwt.SigningMethodHS256, []byte(hmacSecret), "", hour)
+	principal, err = authenticator.Authenticate(ctx, headerWith("Authorization", "Bearer "+hs256))
+	assert.Nil(t, err)
+	assert.Equal(t, "jane", principal.Subject)
+	assert.Equal(t, auth.MethodJWT, principal.Method)
+	assert.Equal(t, []string{"viewer"}, principal.Roles)
+
+	rs256 := signToken(t, jwt.SigningMethodRS256, rsaKey, "rsa-1", hour)
+	principal, err = authenticator.Authenticate(ctx, headerWith("Authorization", "Bearer "+rs256))
+	assert.Nil(t, err)
+	assert.Equal(t, "jane", principal.Subject)
+}
+
+func TestShouldRejectInvalidCredentials(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	otherKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	authenticator := setupAuthenticator(t, rsaKey)
+	ctx := context.Background()
+	hour := time.Now().Add(time.Hour)
+
+	invalid := []http.Header{
+		{},
+		headerWith("X-API-Key", "wrong"),
+		headerWith("Authorization", "Bearer "+signToken(t, jwt.SigningMethodHS256, []byte("wrong"), "", hour)),
+		headerWith("Authorization", "Bearer "+signToken(t, jwt.SigningMethodHS256, []byte(hmacSecret), "", time.Now().Add(-time.Hour))),
+		headerWith("Authorization", "Bearer "+signToken(t, jwt.SigningMethodRS256, otherKey, "rsa-1", hour)),
+		headerWith("Authorization", "Bearer "+signToken(t, jwt.SigningMethodRS256, rsaKey, "unknown", hour)),
+	}
+
+	for i, header := range invalid {
+		_, err := authenticator.Authenticate(ctx, header)
+		assert.True(t, errors.Is(err, auth.ErrUnauthenticated), fmt.Sprintf("case %d: %v", i, err))
+	}
+}
+
+func setupRouter(t *testing.T) *gin.Engine {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	deps := devices.NewDevicesDependencies(&devices.DeviceDependencies{
+		UseMocks:      true,
+		Logger:        slog.New(slog.NewTextHandler(os.Stdout, nil)),
+		Authenticator: setupAuthenticator(t, rsaKey),
+	})
+	rest.BuildRoutes(router, deps)
+	graph.BuildRoutes(router, deps)
+	return router
+}
+
+func TestShouldRequireAuthenticationOnRestEndpoints(t *testing.T) {
+	router := setupRouter(t)
+
+	httpReq, _ := http.NewRequest("DELETE", "/v1/devices/1", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httpReq)
+	assert.Equal(t, 401, w.Code)
+	assert.Equal(t, `Bearer realm="devices"`, w.Header().Get("WWW-Authenticate"))
+
+	httpReq, _ = http.NewRequest("GET", "/v1/devices", nil)
+	httpReq.Header.Set("X-API-Key", apiKey)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httpReq)
+	assert.Equal(t, 200, w.Code)
+
+	httpReq, _ = http.NewRequest("GET", "/ping", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httpReq)
+	assert.Equal(t, 200, w.Code)
+}
+
+func TestShouldRequireAuthenticationOnGraphQLOperations(t *testing.T) {
+	router := setupRouter(t)
+	body := `{"query":"mutation { createDevice(input: {name: \"test\", deviceBrand: \"test\"}) { id } }"}`
+
+	httpReq, _ := http.NewRequest("POST", "/query", bytes.NewReader([]byte(body)))
+	httpReq.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httpReq)
+	assert.Contains(t, w.Body.String(), "UNAUTHENTICATED")
+	assert.NotContains(t, w.Body.String(), "createDevice")
+
+	httpReq, _ = http.NewRequest("POST", "/query", bytes.NewReader([]byte(body)))
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Authorization", "Bearer "+apiKey)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httpReq)
+	assert.Equal(t, 200, w.Code)
+	assert.Contains(t, w.Body.String(), "createDevice")
+	assert.NotContains(t, w.Body.String(), "errors")
+}
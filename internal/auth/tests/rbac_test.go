@@ -0,0 +1,113 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"devices_crud/internal/auth"
+	"devices_crud/internal/devices"
+	"devices_crud/internal/devices/app"
+	"devices_crud/internal/devices/app/ports"
+	"devices_crud/internal/devices/model"
+	"devices_crud/internal/drivers/graph"
+	"devices_crud/internal/drivers/rest"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func asRole(role string) context.Context {
+	return auth.WithPrincipal(context.Background(), &auth.Principal{Subject: role + "-user", Roles: []string{role}})
+}
+
+func getAuthorizedService() *app.DeviceService {
+	service := app.NewDeviceService(ports.NewDevicesRepositoryMock(), slog.New(slog.NewTextHandler(os.Stdout, nil)))
+	service.Authorizer = auth.NewAuthorizer(auth.DefaultRoles)
+	return service
+}
+
+func TestShouldLetInternsReadButNotDelete(t *testing.T) {
+	service := getAuthorizedService()
+
+	id, err := service.AddDevice(asRole("editor"), &model.NewDeviceRequest{Name: "phone", DeviceBrand: "Apple"})
+	assert.Nil(t, err)
+
+	device, err := service.GetDevice(asRole("intern"), *id)
+	assert.Nil(t, err)
+	assert.Equal(t, "phone", device.Name)
+
+	_, err = service.AddDevice(asRole("intern"), &model.NewDeviceRequest{Name: "tablet"})
+	assert.True(t, errors.Is(err, auth.ErrForbidden))
+
+	err = service.DeleteDevice(asRole("intern"), *id)
+	assert.True(t, errors.Is(err, auth.ErrForbidden))
+
+	err = service.DeleteDevice(asRole("editor"), *id)
+	assert.True(t, errors.Is(err, auth.ErrForbidden))
+
+	err = service.DeleteDevice(asRole("admin"), *id)
+	assert.Nil(t, err)
+}
+
+func TestShouldRequirePrincipalWhenAuthorizing(t *testing.T) {
+	service := getAuthorizedService()
+
+	_, err := service.GetAllDevices(context.Background())
+	assert.True(t, errors.Is(err, auth.ErrUnauthenticated))
+}
+
+func setupRBACRouter(t *testing.T) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	keysFile := writeFile(t, "keys.json", []auth.APIKey{
+		{Name: "intern", Hash: auth.HashAPIKey("intern-key"), Roles: []string{"intern"}},
+	})
+	authenticator, err := auth.NewAuthenticator(&auth.Options{APIKeysFile: keysFile})
+	assert.Nil(t, err)
+
+	router := gin.New()
+	deps := devices.NewDevicesDependencies(&devices.DeviceDependencies{
+		UseMocks:      true,
+		Logger:        slog.New(slog.NewTextHandler(os.Stdout, nil)),
+		Authenticator: authenticator,
+		Authorizer:    auth.NewAuthorizer(auth.DefaultRoles),
+	})
+	rest.BuildRoutes(router, deps)
+	graph.BuildRoutes(router, deps)
+	return router
+}
+
+func TestShouldReturnForbiddenOnRestEndpoints(t *testing.T) {
+	router := setupRBACRouter(t)
+
+	httpReq, _ := http.NewRequest("GET", "/v1/devices", nil)
+	httpReq.Header.Set("X-API-Key", "intern-key")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httpReq)
+	assert.Equal(t, 200, w.Code)
+
+	httpReq, _ = http.NewRequest("DELETE", "/v1/devices/1", nil)
+	httpReq.Header.Set("X-API-Key", "intern-key")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httpReq)
+	assert.Equal(t, 403, w.Code)
+	assert.Equal(t, `{"code":"FORBIDDEN","message":"Forbidden"}`, w.Body.String())
+}
+
+func TestShouldReturnForbiddenOnGraphQLMutations(t *testing.T) {
+	router := setupRBACRouter(t)
+	body := `{"query":"mutation { deleteDevice(id: \"1\") }"}`
+
+	httpReq, _ := http.NewRequest("POST", "/query", bytes.NewReader([]byte(body)))
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("X-API-Key", "intern-key")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httpReq)
+
+	assert.Contains(t, w.Body.String(), `"code":"FORBIDDEN"`)
+	assert.Contains(t, w.Body.String(), `"path":["deleteDevice"]`)
+}
@@ -0,0 +1,149 @@
+package cli
+
+import (
+	"context"
+	"devices_crud/pkg/client"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+func newCheckOutCommand(opts *options) *cobra.Command {
+	return newAssignCommand(opts, "checkout", "Lend a device to a person", (*client.Client).CheckOutDevice)
+}
+
+func newTransferCommand(opts *options) *cobra.Command {
+	return newAssignCommand(opts, "transfer", "Hand a checked out device over to another person", (*client.Client).TransferDevice)
+}
+
+// newAssignCommand builds the commands creating an assignment, which share
+// their flags.
+func newAssignCommand(opts *options, use string, short string, assign func(*client.Client, context.Context, string, *client.CheckOutRequest) (*client.Assignment, error)) *cobra.Command {
+	request := &client.CheckOutRequest{}
+	var due time.Duration
+
+	cmd := &cobra.Command{
+		Use:               use + " ID",
+		Short:             short,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeDeviceIDs(opts),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := opts.validateOutput(); err != nil {
+				return err
+			}
+			if due > 0 {
+				dueAt := time.Now().Add(due)
+				request.DueAt = &dueAt
+			}
+			c, err := opts.client()
+			if err != nil {
+				return err
+			}
+
+			assignment, err := assign(c, cmd.Context(), args[0], request)
+			if err != nil {
+				return err
+			}
+			return printAssignments(cmd.OutOrStdout(), opts.output, []client.Assignment{*assignment})
+		},
+	}
+
+	cmd.Flags().StringVar(&request.Assignee, "to", "", "person receiving the device")
+	cmd.Flags().DurationVar(&due, "due", 0, "time until the device is due back, e.g. 168h")
+	cmd.Flags().StringVar(&request.Note, "note", "", "note on the assignment")
+	cmd.MarkFlagRequired("to")
+
+	return cmd
+}
+
+func newCheckInCommand(opts *options) *cobra.Command {
+	var note string
+	cmd := &cobra.Command{
+		Use:               "checkin ID",
+		Short:             "Return a checked out device",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeDeviceIDs(opts),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := opts.validateOutput(); err != nil {
+				return err
+			}
+			c, err := opts.client()
+			if err != nil {
+				return err
+			}
+
+			assignment, err := c.CheckInDevice(cmd.Context(), args[0], note)
+			if err != nil {
+				return err
+			}
+			return printAssignments(cmd.OutOrStdout(), opts.output, []client.Assignment{*assignment})
+		},
+	}
+	cmd.Flags().StringVar(&note, "note", "", "note on the return, e.g. the condition of the device")
+	return cmd
+}
+
+func newAssignmentsCommand(opts *options) *cobra.Command {
+	var device, assignee string
+	var overdue bool
+	cmd := &cobra.Command{
+		Use:   "assignments",
+		Short: "Show the assignments of a device or a person, or the overdue ones",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := opts.validateOutput(); err != nil {
+				return err
+			}
+			c, err := opts.client()
+			if err != nil {
+				return err
+			}
+
+			var assignments []client.Assignment
+			switch {
+			case overdue:
+				assignments, err = c.OverdueAssignments(cmd.Context(), time.Now())
+			case device != "":
+				assignments, err = c.DeviceAssignments(cmd.Context(), device)
+			case assignee != "":
+				assignments, err = c.AssigneeAssignments(cmd.Context(), assignee)
+			default:
+				return fmt.Errorf("set --device, --assignee or --overdue")
+			}
+			if err != nil {
+				return err
+			}
+			return printAssignments(cmd.OutOrStdout(), opts.output, assignments)
+		},
+	}
+	cmd.Flags().StringVar(&device, "device", "", "history of the device with this ID")
+	cmd.Flags().StringVar(&assignee, "assignee", "", "history of this person")
+	cmd.Flags().BoolVar(&overdue, "overdue", false, "devices not returned in time")
+	cmd.MarkFlagsMutuallyExclusive("device", "assignee", "overdue")
+	return cmd
+}
+
+func printAssignments(w io.Writer, format string, assignments []client.Assignment) error {
+	if format != formatTable {
+		return writeValue(w, format, assignments)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "DEVICE\tASSIGNEE\tCHECKED OUT AT\tDUE AT\tCHECKED IN AT")
+	for _, assignment := range assignments {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n",
+			assignment.DeviceID, assignment.Assignee, assignment.CheckedOutAt.Format(time.RFC3339),
+			formatOptionalTime(assignment.DueAt), formatOptionalTime(assignment.CheckedInAt))
+	}
+	return tw.Flush()
+}
+
+func formatOptionalTime(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return t.Format(time.RFC3339)
+}
@@ -0,0 +1,99 @@
+package cli
+
+import (
+	"devices_crud/pkg/client"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+func newBrandsCommand(opts *options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "brands",
+		Short: "List the brand catalog",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := opts.validateOutput(); err != nil {
+				return err
+			}
+			c, err := opts.client()
+			if err != nil {
+				return err
+			}
+
+			brands, err := c.ListBrands(cmd.Context())
+			if err != nil {
+				return err
+			}
+			return printBrands(cmd.OutOrStdout(), opts.output, brands)
+		},
+	}
+	cmd.AddCommand(newMigrateBrandsCommand(opts))
+	return cmd
+}
+
+func newMigrateBrandsCommand(opts *options) *cobra.Command {
+	request := &client.MigrateBrandsRequest{}
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Rename the brands of stored devices to their catalog names",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := opts.validateOutput(); err != nil {
+				return err
+			}
+			c, err := opts.client()
+			if err != nil {
+				return err
+			}
+
+			migration, err := c.MigrateBrands(cmd.Context(), request)
+			if err != nil {
+				return err
+			}
+			if opts.output != formatTable {
+				return writeValue(cmd.OutOrStdout(), opts.output, migration)
+			}
+
+			w := cmd.OutOrStdout()
+			for _, brand := range migration.Added {
+				fmt.Fprintf(w, "added brand %s\n", brand.Name)
+			}
+			tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+			fmt.Fprintln(tw, "DEVICE\tFROM\tTO")
+			for _, change := range migration.Changes {
+				fmt.Fprintf(tw, "%s\t%q\t%q\n", change.DeviceID, change.From, change.To)
+			}
+			if err := tw.Flush(); err != nil {
+				return err
+			}
+			if migration.DryRun {
+				fmt.Fprintln(w, "dry run, nothing was changed")
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&request.DryRun, "dry-run", false, "only report the changes")
+	cmd.Flags().BoolVar(&request.AddMissing, "add-missing", false, "merge the spellings of brands missing from the catalog and add them to it")
+	return cmd
+}
+
+func printBrands(w io.Writer, format string, brands []client.Brand) error {
+	if format != formatTable {
+		return writeValue(w, format, brands)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tALIASES\tMODELS")
+	for _, brand := range brands {
+		models := make([]string, len(brand.Models))
+		for i, deviceModel := range brand.Models {
+			models[i] = deviceModel.Name
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", brand.Name, strings.Join(brand.Aliases, ", "), strings.Join(models, ", "))
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	defaultServer = "http://localhost:8080"
+	configEnv     = "DEVICESCTL_CONFIG"
+)
+
+type Config struct {
+	Server string `yaml:"server"`
+	Token  string `yaml:"token"`
+}
+
+// loadConfig reads the config file at path. When no path is given it falls back
+// to $DEVICESCTL_CONFIG and then to the user config directory; a missing default
+// file is not an error, a missing explicitly requested one is.
+func loadConfig(path string) (*Config, error) {
+	explicit := true
+	if path == "" {
+		path = os.Getenv(configEnv)
+	}
+	if path == "" {
+		explicit = false
+		path = defaultConfigPath()
+	}
+
+	config := &Config{Server: defaultServer}
+	if path == "" {
+		return config, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) && !explicit {
+			return config, nil
+		}
+		return nil, fmt.Errorf("reading config %s: %w", path, err)
+	}
+
+	if err := yaml.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("parsing config %s: %w", path, err)
+	}
+	if config.Server == "" {
+		config.Server = defaultServer
+	}
+
+	return config, nil
+}
+
+func defaultConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "devicesctl", "config.yaml")
+}
@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"devices_crud/pkg/client"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+func newHeartbeatCommand(opts *options) *cobra.Command {
+	return &cobra.Command{
+		Use:               "heartbeat ID",
+		Short:             "Record that a device is alive",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeDeviceIDs(opts),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := opts.validateOutput(); err != nil {
+				return err
+			}
+			c, err := opts.client()
+			if err != nil {
+				return err
+			}
+
+			status, err := c.Heartbeat(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+			return printConnectivity(cmd.OutOrStdout(), opts.output, status)
+		},
+	}
+}
+
+func newConnectivityCommand(opts *options) *cobra.Command {
+	return &cobra.Command{
+		Use:               "connectivity ID",
+		Short:             "Show whether a device is online",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeDeviceIDs(opts),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := opts.validateOutput(); err != nil {
+				return err
+			}
+			c, err := opts.client()
+			if err != nil {
+				return err
+			}
+
+			status, err := c.DeviceConnectivity(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+			return printConnectivity(cmd.OutOrStdout(), opts.output, status)
+		},
+	}
+}
+
+func printConnectivity(w io.Writer, format string, status *client.ConnectivityStatus) error {
+	if format != formatTable {
+		return writeValue(w, format, status)
+	}
+
+	lastSeen := "never"
+	if status.LastSeenAt != nil {
+		lastSeen = status.LastSeenAt.Format(time.RFC3339)
+	}
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "DEVICE\tSTATUS\tLAST SEEN\tTIMEOUT")
+	fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", status.DeviceID, status.Status, lastSeen, status.Timeout)
+	return tw.Flush()
+}
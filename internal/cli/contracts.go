@@ -0,0 +1,140 @@
+package cli
+
+import (
+	"devices_crud/pkg/client"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+func newContractsCommand(opts *options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "contracts ID",
+		Short:             "Show the warranty and support contracts of a device",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeDeviceIDs(opts),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := opts.validateOutput(); err != nil {
+				return err
+			}
+			c, err := opts.client()
+			if err != nil {
+				return err
+			}
+
+			contracts, err := c.Contracts(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+			return printContracts(cmd.OutOrStdout(), opts.output, contracts)
+		},
+	}
+	cmd.AddCommand(newAddContractCommand(opts), newExpiringContractsCommand(opts))
+	return cmd
+}
+
+func newAddContractCommand(opts *options) *cobra.Command {
+	request := &client.ContractRequest{}
+	var kind, starts, ends string
+	cmd := &cobra.Command{
+		Use:               "add ID PROVIDER",
+		Short:             "Add a warranty or support contract to a device",
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: completeDeviceIDs(opts),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := opts.validateOutput(); err != nil {
+				return err
+			}
+			c, err := opts.client()
+			if err != nil {
+				return err
+			}
+
+			request.Provider = args[1]
+			request.Kind = client.ContractKind(kind)
+			if request.StartsAt, err = parseDate(starts); err != nil {
+				return fmt.Errorf("invalid --starts: %w", err)
+			}
+			if request.EndsAt, err = parseDate(ends); err != nil {
+				return fmt.Errorf("invalid --ends: %w", err)
+			}
+			contract, err := c.AddContract(cmd.Context(), args[0], request)
+			if err != nil {
+				return err
+			}
+			return printContracts(cmd.OutOrStdout(), opts.output, []client.Contract{*contract})
+		},
+	}
+	cmd.Flags().StringVar(&kind, "kind", string(client.ContractWarranty), "warranty or support")
+	cmd.Flags().StringVar(&request.Reference, "reference", "", "number of the contract at the provider")
+	cmd.Flags().StringVar(&starts, "starts", time.Now().Format(time.DateOnly), "first day of the contract, as YYYY-MM-DD or RFC 3339")
+	cmd.Flags().StringVar(&ends, "ends", "", "end of the contract, as YYYY-MM-DD or RFC 3339")
+	cmd.Flags().StringArrayVar(&request.Coverage, "covers", nil, "what the contract covers, e.g. parts, labor or onsite")
+	_ = cmd.MarkFlagRequired("ends")
+	return cmd
+}
+
+func newExpiringContractsCommand(opts *options) *cobra.Command {
+	var days int
+	var kind string
+	cmd := &cobra.Command{
+		Use:   "expiring",
+		Short: "Show the contracts of every device ending soon",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := opts.validateOutput(); err != nil {
+				return err
+			}
+			c, err := opts.client()
+			if err != nil {
+				return err
+			}
+
+			contracts, err := c.ExpiringContracts(cmd.Context(), days, kind)
+			if err != nil {
+				return err
+			}
+			if opts.output != formatTable {
+				return writeValue(cmd.OutOrStdout(), opts.output, contracts)
+			}
+
+			tw := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 4, 2, ' ', 0)
+			fmt.Fprintln(tw, "DEVICE\tKIND\tPROVIDER\tENDS AT\tDAYS LEFT\tRENEWED")
+			for _, expiring := range contracts {
+				contract := expiring.Contract
+				fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d\t%t\n",
+					contract.DeviceID, contract.Kind, contract.Provider, contract.EndsAt.Format(time.DateOnly), expiring.DaysLeft, expiring.Renewed)
+			}
+			return tw.Flush()
+		},
+	}
+	cmd.Flags().IntVar(&days, "days", 90, "contracts ending within this many days")
+	cmd.Flags().StringVar(&kind, "kind", "", "only warranty or only support contracts")
+	return cmd
+}
+
+func printContracts(w io.Writer, format string, contracts []client.Contract) error {
+	if format != formatTable {
+		return writeValue(w, format, contracts)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tKIND\tPROVIDER\tSTARTS AT\tENDS AT\tCOVERAGE")
+	for _, contract := range contracts {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			contract.ID, contract.Kind, contract.Provider, contract.StartsAt.Format(time.DateOnly), contract.EndsAt.Format(time.DateOnly), strings.Join(contract.Coverage, ","))
+	}
+	return tw.Flush()
+}
+
+// parseDate parses a day, taken at midnight UTC, or an RFC 3339 timestamp.
+func parseDate(value string) (time.Time, error) {
+	if parsed, err := time.Parse(time.DateOnly, value); err == nil {
+		return parsed, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
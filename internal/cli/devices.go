@@ -0,0 +1,371 @@
+package cli
+
+import (
+	"devices_crud/pkg/client"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+func newListCommand(opts *options) *cobra.Command {
+	var filter, selector, connectivity string
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List all devices",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := opts.validateOutput(); err != nil {
+				return err
+			}
+			c, err := opts.client()
+			if err != nil {
+				return err
+			}
+
+			devices, err := listDevices(cmd.Context(), c, filter, selector, client.Connectivity(connectivity))
+			if err != nil {
+				return err
+			}
+			return printDevices(cmd.OutOrStdout(), opts.output, devices)
+		},
+	}
+	cmd.Flags().StringVar(&filter, "filter", "", `filter expression, e.g. 'brand = "Apple" AND name ~ "lab-*"'`)
+	cmd.Flags().StringVarP(&selector, "selector", "l", "", "label selector, e.g. 'env=prod,team in (a,b),!retired'")
+	cmd.Flags().StringVar(&connectivity, "connectivity", "", "only list the devices which are online, offline or unknown")
+	return cmd
+}
+
+func newGetCommand(opts *options) *cobra.Command {
+	return &cobra.Command{
+		Use:               "get ID",
+		Short:             "Show a device",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeDeviceIDs(opts),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := opts.validateOutput(); err != nil {
+				return err
+			}
+			c, err := opts.client()
+			if err != nil {
+				return err
+			}
+
+			device, err := c.GetDevice(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+			return printDevice(cmd.OutOrStdout(), opts.output, device)
+		},
+	}
+}
+
+func newCreateCommand(opts *options) *cobra.Command {
+	request := &client.NewDeviceRequest{}
+	var attributes []string
+
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create a device",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := opts.validateOutput(); err != nil {
+				return err
+			}
+			var err error
+			if request.Attributes, err = parseAttributes(attributes); err != nil {
+				return err
+			}
+			c, err := opts.client()
+			if err != nil {
+				return err
+			}
+
+			id, err := c.CreateDevice(cmd.Context(), request)
+			if err != nil {
+				return err
+			}
+			device, err := c.GetDevice(cmd.Context(), id)
+			if err != nil {
+				return err
+			}
+			return printDevice(cmd.OutOrStdout(), opts.output, device)
+		},
+	}
+
+	cmd.Flags().StringVar(&request.Name, "name", "", "device name")
+	cmd.Flags().StringVar(&request.DeviceBrand, "brand", "", "device brand")
+	cmd.Flags().StringVar(&request.Type, "type", "", "device type")
+	cmd.Flags().StringArrayVar(&attributes, "attr", nil, "attribute as key=value, values are parsed as JSON when possible")
+	cmd.MarkFlagRequired("name")
+	cmd.MarkFlagRequired("brand")
+
+	return cmd
+}
+
+func newPatchCommand(opts *options) *cobra.Command {
+	var name, brand, deviceType string
+	var attributes []string
+
+	cmd := &cobra.Command{
+		Use:               "patch ID",
+		Short:             "Update selected fields of a device",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeDeviceIDs(opts),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := opts.validateOutput(); err != nil {
+				return err
+			}
+
+			request := &client.PatchDeviceRequest{ID: args[0]}
+			if cmd.Flags().Changed("name") {
+				request.Name = &name
+			}
+			if cmd.Flags().Changed("brand") {
+				request.DeviceBrand = &brand
+			}
+			if cmd.Flags().Changed("type") {
+				request.Type = &deviceType
+			}
+			var err error
+			if request.Attributes, err = parseAttributes(attributes); err != nil {
+				return err
+			}
+			if request.Name == nil && request.DeviceBrand == nil && request.Type == nil && request.Attributes == nil {
+				return errors.New("nothing to patch, set --name, --brand, --type and/or --attr")
+			}
+
+			c, err := opts.client()
+			if err != nil {
+				return err
+			}
+			if err := c.PatchDevice(cmd.Context(), request); err != nil {
+				return err
+			}
+			device, err := c.GetDevice(cmd.Context(), request.ID)
+			if err != nil {
+				return err
+			}
+			return printDevice(cmd.OutOrStdout(), opts.output, device)
+		},
+	}
+
+	cmd.Flags().StringVar(&name, "name", "", "new device name")
+	cmd.Flags().StringVar(&brand, "brand", "", "new device brand")
+	cmd.Flags().StringVar(&deviceType, "type", "", "new device type")
+	cmd.Flags().StringArrayVar(&attributes, "attr", nil, "attribute to set as key=value, key=null removes it")
+
+	return cmd
+}
+
+func newReplaceCommand(opts *options) *cobra.Command {
+	var name, brand string
+
+	cmd := &cobra.Command{
+		Use:               "replace ID",
+		Short:             "Replace a whole device",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeDeviceIDs(opts),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := opts.validateOutput(); err != nil {
+				return err
+			}
+			c, err := opts.client()
+			if err != nil {
+				return err
+			}
+
+			device, err := c.GetDevice(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+			device.Name = name
+			device.DeviceBrand = brand
+
+			replaced, err := c.ReplaceDevice(cmd.Context(), device)
+			if err != nil {
+				return err
+			}
+			return printDevice(cmd.OutOrStdout(), opts.output, replaced)
+		},
+	}
+
+	cmd.Flags().StringVar(&name, "name", "", "device name")
+	cmd.Flags().StringVar(&brand, "brand", "", "device brand")
+	cmd.MarkFlagRequired("name")
+	cmd.MarkFlagRequired("brand")
+
+	return cmd
+}
+
+// parseAttributes parses key=value flags. Values that are valid JSON, such as
+// 42, true or null, keep their type, others are strings.
+func parseAttributes(flags []string) (map[string]interface{}, error) {
+	if len(flags) == 0 {
+		return nil, nil
+	}
+	attributes := make(map[string]interface{}, len(flags))
+	for _, flag := range flags {
+		key, value, ok := strings.Cut(flag, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid attribute %q, expected key=value", flag)
+		}
+		var decoded interface{}
+		if err := json.Unmarshal([]byte(value), &decoded); err != nil {
+			decoded = value
+		}
+		attributes[key] = decoded
+	}
+	return attributes, nil
+}
+
+func newLabelCommand(opts *options) *cobra.Command {
+	return &cobra.Command{
+		Use:               "label ID key=value... key-...",
+		Short:             "Set labels of a device, key- removes the label",
+		Args:              cobra.MinimumNArgs(2),
+		ValidArgsFunction: completeDeviceIDs(opts),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := opts.validateOutput(); err != nil {
+				return err
+			}
+
+			set := map[string]string{}
+			var remove []string
+			for _, arg := range args[1:] {
+				if key, value, ok := strings.Cut(arg, "="); ok {
+					set[key] = value
+				} else if key, ok := strings.CutSuffix(arg, "-"); ok {
+					remove = append(remove, key)
+				} else {
+					return fmt.Errorf("invalid label %q, expected key=value or key-", arg)
+				}
+			}
+
+			c, err := opts.client()
+			if err != nil {
+				return err
+			}
+			var device *client.Device
+			if len(set) > 0 {
+				if device, err = c.SetLabels(cmd.Context(), args[0], set); err != nil {
+					return err
+				}
+			}
+			for _, key := range remove {
+				if device, err = c.RemoveLabel(cmd.Context(), args[0], key); err != nil {
+					return err
+				}
+			}
+			return printDevice(cmd.OutOrStdout(), opts.output, device)
+		},
+	}
+}
+
+func newTransitionCommand(opts *options) *cobra.Command {
+	var reason string
+	cmd := &cobra.Command{
+		Use:               "transition ID STATUS",
+		Short:             "Move a device to another lifecycle status",
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: completeDeviceIDs(opts),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := opts.validateOutput(); err != nil {
+				return err
+			}
+			c, err := opts.client()
+			if err != nil {
+				return err
+			}
+
+			device, err := c.TransitionDevice(cmd.Context(), args[0], client.Status(args[1]), reason)
+			if err != nil {
+				return err
+			}
+			return printDevice(cmd.OutOrStdout(), opts.output, device)
+		},
+	}
+	cmd.Flags().StringVar(&reason, "reason", "", "why the status changes")
+	cmd.MarkFlagRequired("reason")
+	return cmd
+}
+
+func newDeleteCommand(opts *options) *cobra.Command {
+	var cascade bool
+	cmd := &cobra.Command{
+		Use:               "delete ID...",
+		Short:             "Delete devices",
+		Args:              cobra.MinimumNArgs(1),
+		ValidArgsFunction: completeDeviceIDs(opts),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := opts.client()
+			if err != nil {
+				return err
+			}
+
+			deleteDevice := c.DeleteDevice
+			if cascade {
+				deleteDevice = c.DeleteDeviceTree
+			}
+			for _, id := range args {
+				if err := deleteDevice(cmd.Context(), id); err != nil {
+					return err
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "deleted %s\n", id)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&cascade, "cascade", false, "also delete the devices each device contains")
+	return cmd
+}
+
+func newSearchCommand(opts *options) *cobra.Command {
+	return &cobra.Command{
+		Use:   "search QUERY",
+		Short: "Search devices by name, brand and attributes, most relevant first",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := opts.validateOutput(); err != nil {
+				return err
+			}
+			c, err := opts.client()
+			if err != nil {
+				return err
+			}
+
+			results, err := c.SearchDevices(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+			devices := make([]client.Device, len(results))
+			for i := range results {
+				devices[i] = results[i].Device
+			}
+			return printDevices(cmd.OutOrStdout(), opts.output, devices)
+		},
+	}
+}
+
+// completeDeviceIDs offers the IDs of existing devices, described by their names.
+func completeDeviceIDs(opts *options) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		c, err := opts.client()
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+
+		devices, err := listAllDevices(cmd.Context(), c)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+
+		completions := make([]string, 0, len(devices))
+		for _, device := range devices {
+			completions = append(completions, device.ID+"\t"+device.Name)
+		}
+		return completions, cobra.ShellCompDirectiveNoFileComp
+	}
+}
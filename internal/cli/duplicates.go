@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"devices_crud/pkg/client"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+func newDuplicatesCommand(opts *options) *cobra.Command {
+	var threshold float64
+	cmd := &cobra.Command{
+		Use:               "duplicates [ID]",
+		Short:             "Show the devices which may be duplicates, or the duplicates of one device",
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completeDeviceIDs(opts),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := opts.validateOutput(); err != nil {
+				return err
+			}
+			c, err := opts.client()
+			if err != nil {
+				return err
+			}
+
+			var candidates []client.DuplicateCandidate
+			if len(args) == 1 {
+				candidates, err = c.DeviceDuplicates(cmd.Context(), args[0], threshold)
+			} else {
+				candidates, err = c.DuplicateCandidates(cmd.Context(), threshold)
+			}
+			if err != nil {
+				return err
+			}
+			return printDuplicates(cmd.OutOrStdout(), opts.output, candidates)
+		},
+	}
+	cmd.Flags().Float64Var(&threshold, "threshold", 0, "minimum score from 0 to 1, the server's default (0.8) when unset")
+	return cmd
+}
+
+func newMergeCommand(opts *options) *cobra.Command {
+	return &cobra.Command{
+		Use:               "merge ID SOURCE_ID",
+		Short:             "Merge a duplicate device into another one, its ID then leads to that device",
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: completeDeviceIDs(opts),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := opts.validateOutput(); err != nil {
+				return err
+			}
+			c, err := opts.client()
+			if err != nil {
+				return err
+			}
+
+			device, err := c.MergeDevices(cmd.Context(), args[0], args[1])
+			if err != nil {
+				return err
+			}
+			return printDevice(cmd.OutOrStdout(), opts.output, device)
+		},
+	}
+}
+
+func printDuplicates(w io.Writer, format string, candidates []client.DuplicateCandidate) error {
+	if format != formatTable {
+		return writeValue(w, format, candidates)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "DEVICE\tNAME\tDUPLICATE\tNAME\tSCORE\tKEY")
+	for _, candidate := range candidates {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%.2f\t%s\n", candidate.Device.ID, candidate.Device.Name,
+			candidate.Duplicate.ID, candidate.Duplicate.Name, candidate.Score, formatOptional(candidate.Key))
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,105 @@
+package cli
+
+import (
+	"devices_crud/pkg/client"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+func newLocationsCommand(opts *options) *cobra.Command {
+	var devices bool
+	cmd := &cobra.Command{
+		Use:   "locations [PATH]",
+		Short: "List the sites, or the locations or devices under a path such as HQ/Building A",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := opts.validateOutput(); err != nil {
+				return err
+			}
+			c, err := opts.client()
+			if err != nil {
+				return err
+			}
+
+			parentID := ""
+			if len(args) == 1 {
+				parent, err := c.ResolveLocation(cmd.Context(), args[0])
+				if err != nil {
+					return err
+				}
+				parentID = parent.ID
+			}
+			if devices {
+				if parentID == "" {
+					return fmt.Errorf("--devices needs a PATH")
+				}
+				found, err := c.LocationDevices(cmd.Context(), parentID, true)
+				if err != nil {
+					return err
+				}
+				return printDevices(cmd.OutOrStdout(), opts.output, found)
+			}
+
+			locations, err := c.ListLocations(cmd.Context(), parentID)
+			if err != nil {
+				return err
+			}
+			return printLocations(cmd.OutOrStdout(), opts.output, locations)
+		},
+	}
+	cmd.Flags().BoolVar(&devices, "devices", false, "list the devices anywhere under PATH instead")
+	return cmd
+}
+
+func newMoveCommand(opts *options) *cobra.Command {
+	var reason string
+	cmd := &cobra.Command{
+		Use:               "move ID [PATH]",
+		Short:             "Place a device in a location, or take it out of any location without PATH",
+		Args:              cobra.RangeArgs(1, 2),
+		ValidArgsFunction: completeDeviceIDs(opts),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := opts.validateOutput(); err != nil {
+				return err
+			}
+			c, err := opts.client()
+			if err != nil {
+				return err
+			}
+
+			locationID := ""
+			if len(args) == 2 {
+				location, err := c.ResolveLocation(cmd.Context(), args[1])
+				if err != nil {
+					return err
+				}
+				locationID = location.ID
+			}
+			device, err := c.MoveDevice(cmd.Context(), args[0], locationID, reason)
+			if err != nil {
+				return err
+			}
+			return printDevice(cmd.OutOrStdout(), opts.output, device)
+		},
+	}
+	cmd.Flags().StringVar(&reason, "reason", "", "why the device moves")
+	return cmd
+}
+
+func printLocations(w io.Writer, format string, locations []client.Location) error {
+	if format != formatTable {
+		return writeValue(w, format, locations)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tNAME\tKIND\tCREATED AT")
+	for _, location := range locations {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n",
+			location.ID, location.Name, location.Kind, location.CreatedAt.Format(time.RFC3339))
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,153 @@
+package cli
+
+import (
+	"devices_crud/pkg/client"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+func newMaintenanceCommand(opts *options) *cobra.Command {
+	var device string
+	var days int
+	var overdue bool
+	cmd := &cobra.Command{
+		Use:   "maintenance",
+		Short: "Show upcoming maintenance tasks of every device or one device, or the overdue ones",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := opts.validateOutput(); err != nil {
+				return err
+			}
+			c, err := opts.client()
+			if err != nil {
+				return err
+			}
+
+			var tasks []client.MaintenanceTask
+			switch {
+			case overdue:
+				tasks, err = c.OverdueMaintenance(cmd.Context(), time.Now())
+			case device != "":
+				tasks, err = c.DeviceMaintenanceTasks(cmd.Context(), device, days)
+			default:
+				tasks, err = c.UpcomingMaintenance(cmd.Context(), days)
+			}
+			if err != nil {
+				return err
+			}
+			return printMaintenanceTasks(cmd.OutOrStdout(), opts.output, tasks)
+		},
+	}
+	cmd.Flags().StringVar(&device, "device", "", "tasks of the device with this ID")
+	cmd.Flags().IntVar(&days, "days", 30, "tasks due within this many days")
+	cmd.Flags().BoolVar(&overdue, "overdue", false, "tasks not done in time")
+	cmd.MarkFlagsMutuallyExclusive("device", "overdue")
+	cmd.AddCommand(newMaintenancePlanCommand(opts), newRecordServiceCommand(opts))
+	return cmd
+}
+
+func newMaintenancePlanCommand(opts *options) *cobra.Command {
+	request := &client.NewMaintenancePlanRequest{}
+	var kind, unit string
+	cmd := &cobra.Command{
+		Use:               "plan ID NAME",
+		Short:             "Schedule periodic maintenance of a device",
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: completeDeviceIDs(opts),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := opts.validateOutput(); err != nil {
+				return err
+			}
+			c, err := opts.client()
+			if err != nil {
+				return err
+			}
+
+			request.Name = args[1]
+			request.Kind = client.MaintenanceKind(kind)
+			request.Unit = client.RecurrenceUnit(unit)
+			plan, err := c.CreateMaintenancePlan(cmd.Context(), args[0], request)
+			if err != nil {
+				return err
+			}
+			if opts.output != formatTable {
+				return writeValue(cmd.OutOrStdout(), opts.output, plan)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "plan %s due every %d %s, next on %s\n", plan.ID, plan.Every, plan.Unit, plan.StartsAt.Format(time.RFC3339))
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&kind, "kind", string(client.MaintenanceInterval), "interval, due after the last service, or calendar, due on fixed dates")
+	cmd.Flags().IntVar(&request.Every, "every", 1, "number of units between tasks")
+	cmd.Flags().StringVar(&unit, "unit", string(client.UnitMonth), "day, week, month or year")
+	cmd.Flags().StringVar(&request.Description, "description", "", "what the maintenance consists of")
+	return cmd
+}
+
+func newRecordServiceCommand(opts *options) *cobra.Command {
+	request := &client.ServiceRecordRequest{}
+	var repair bool
+	cmd := &cobra.Command{
+		Use:               "record ID",
+		Short:             "Record maintenance or a repair done on a device",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeDeviceIDs(opts),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := opts.validateOutput(); err != nil {
+				return err
+			}
+			c, err := opts.client()
+			if err != nil {
+				return err
+			}
+
+			if repair {
+				request.Kind = client.ServiceRepair
+			}
+			record, err := c.RecordService(cmd.Context(), args[0], request)
+			if err != nil {
+				return err
+			}
+			return printServiceRecords(cmd.OutOrStdout(), opts.output, []client.ServiceRecord{*record})
+		},
+	}
+	cmd.Flags().StringVar(&request.PlanID, "plan", "", "plan whose task the service completes")
+	cmd.Flags().BoolVar(&repair, "repair", false, "record a repair rather than maintenance")
+	cmd.Flags().Float64Var(&request.Cost, "cost", 0, "cost of the service")
+	cmd.Flags().StringVar(&request.Currency, "currency", "", "currency of the cost, e.g. EUR")
+	cmd.Flags().StringVar(&request.PerformedBy, "by", "", "who did the work")
+	cmd.Flags().StringVar(&request.Notes, "notes", "", "notes on the service")
+	return cmd
+}
+
+func printMaintenanceTasks(w io.Writer, format string, tasks []client.MaintenanceTask) error {
+	if format != formatTable {
+		return writeValue(w, format, tasks)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "DEVICE\tPLAN\tDUE AT\tOVERDUE\tIN REPAIR")
+	for _, task := range tasks {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%t\t%t\n",
+			task.DeviceID, task.PlanName, task.DueAt.Format(time.RFC3339), task.Overdue, task.DeviceInRepair)
+	}
+	return tw.Flush()
+}
+
+func printServiceRecords(w io.Writer, format string, records []client.ServiceRecord) error {
+	if format != formatTable {
+		return writeValue(w, format, records)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tDEVICE\tKIND\tPERFORMED AT\tCOST\tNOTES")
+	for _, record := range records {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%.2f %s\t%s\n",
+			record.ID, record.DeviceID, record.Kind, record.PerformedAt.Format(time.RFC3339), record.Cost, record.Currency, record.Notes)
+	}
+	return tw.Flush()
+}
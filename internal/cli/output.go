@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"devices_crud/pkg/client"
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+	formatYAML  = "yaml"
+)
+
+var outputFormats = []string{formatTable, formatJSON, formatYAML}
+
+func printDevices(w io.Writer, format string, devices []client.Device) error {
+	if format != formatTable {
+		return writeValue(w, format, devices)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tNAME\tBRAND\tSTATUS\tCREATED AT")
+	for _, device := range devices {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n",
+			device.ID, device.Name, device.DeviceBrand, device.Status, device.CreatedAt.Format(time.RFC3339))
+	}
+	return tw.Flush()
+}
+
+func printDevice(w io.Writer, format string, device *client.Device) error {
+	if format != formatTable {
+		return writeValue(w, format, device)
+	}
+	return printDevices(w, format, []client.Device{*device})
+}
+
+// writeValue encodes v as JSON or YAML. YAML goes through the JSON encoding first
+// so that both formats use the same field names as the REST API.
+func writeValue(w io.Writer, format string, v any) error {
+	switch format {
+	case formatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(v)
+	case formatYAML:
+		payload, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		var generic any
+		if err := json.Unmarshal(payload, &generic); err != nil {
+			return err
+		}
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(generic); err != nil {
+			return err
+		}
+		return encoder.Close()
+	default:
+		return fmt.Errorf("unsupported output format %q, expected one of %v", format, outputFormats)
+	}
+}
+
+// readValue decodes JSON or YAML from r into v, again using the JSON field names.
+func readValue(r io.Reader, format string, v any) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case formatJSON:
+		return json.Unmarshal(data, v)
+	case formatYAML:
+		var generic any
+		if err := yaml.Unmarshal(data, &generic); err != nil {
+			return err
+		}
+		payload, err := json.Marshal(generic)
+		if err != nil {
+			return err
+		}
+		return json.Unmarshal(payload, v)
+	default:
+		return fmt.Errorf("unsupported format %q, expected %s or %s", format, formatJSON, formatYAML)
+	}
+}
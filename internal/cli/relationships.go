@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"devices_crud/pkg/client"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+func newLinkCommand(opts *options) *cobra.Command {
+	return &cobra.Command{
+		Use:               "link ID TYPE TARGET",
+		Short:             "Link a device to another, TYPE is contains, connected_to or replaces",
+		Args:              cobra.ExactArgs(3),
+		ValidArgsFunction: completeDeviceIDs(opts),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := opts.validateOutput(); err != nil {
+				return err
+			}
+			c, err := opts.client()
+			if err != nil {
+				return err
+			}
+
+			relationship, err := c.LinkDevices(cmd.Context(), args[0], client.RelationType(args[1]), args[2])
+			if err != nil {
+				return err
+			}
+			return printRelationships(cmd.OutOrStdout(), opts.output, []client.Relationship{*relationship})
+		},
+	}
+}
+
+func newUnlinkCommand(opts *options) *cobra.Command {
+	return &cobra.Command{
+		Use:               "unlink ID RELATIONSHIP_ID",
+		Short:             "Remove a relationship of a device",
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: completeDeviceIDs(opts),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := opts.client()
+			if err != nil {
+				return err
+			}
+
+			if err := c.UnlinkDevices(cmd.Context(), args[0], args[1]); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "unlinked %s\n", args[1])
+			return nil
+		},
+	}
+}
+
+func newRelationshipsCommand(opts *options) *cobra.Command {
+	return &cobra.Command{
+		Use:               "relationships ID",
+		Short:             "Show the relationships of a device",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeDeviceIDs(opts),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := opts.validateOutput(); err != nil {
+				return err
+			}
+			c, err := opts.client()
+			if err != nil {
+				return err
+			}
+
+			relationships, err := c.DeviceRelationships(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+			return printRelationships(cmd.OutOrStdout(), opts.output, relationships)
+		},
+	}
+}
+
+func printRelationships(w io.Writer, format string, relationships []client.Relationship) error {
+	if format != formatTable {
+		return writeValue(w, format, relationships)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tFROM\tTYPE\tTO\tCREATED AT")
+	for _, relationship := range relationships {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n",
+			relationship.ID, relationship.FromID, relationship.Type, relationship.ToID, relationship.CreatedAt.Format(time.RFC3339))
+	}
+	return tw.Flush()
+}
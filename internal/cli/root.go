@@ -0,0 +1,113 @@
+package cli
+
+import (
+	"context"
+	"devices_crud/pkg/client"
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+type options struct {
+	configPath string
+	server     string
+	token      string
+	output     string
+}
+
+// NewRootCommand builds the devicesctl command tree. Shell completion scripts are
+// provided by cobra's built-in "completion" subcommand.
+func NewRootCommand() *cobra.Command {
+	opts := &options{}
+
+	root := &cobra.Command{
+		Use:           "devicesctl",
+		Short:         "Command-line client for the devices API",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+
+	flags := root.PersistentFlags()
+	flags.StringVar(&opts.configPath, "config", "", "config file (default is $XDG_CONFIG_HOME/devicesctl/config.yaml)")
+	flags.StringVar(&opts.server, "server", "", "devices API URL, overrides the config file")
+	flags.StringVar(&opts.token, "token", "", "API token, overrides the config file")
+	flags.StringVarP(&opts.output, "output", "o", formatTable, "output format: table, json or yaml")
+	root.RegisterFlagCompletionFunc("output", func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+		return outputFormats, cobra.ShellCompDirectiveNoFileComp
+	})
+
+	root.AddCommand(
+		newListCommand(opts),
+		newGetCommand(opts),
+		newCreateCommand(opts),
+		newPatchCommand(opts),
+		newLabelCommand(opts),
+		newTransitionCommand(opts),
+		newCheckOutCommand(opts),
+		newCheckInCommand(opts),
+		newTransferCommand(opts),
+		newAssignmentsCommand(opts),
+		newMoveCommand(opts),
+		newLocationsCommand(opts),
+		newBrandsCommand(opts),
+		newLinkCommand(opts),
+		newUnlinkCommand(opts),
+		newRelationshipsCommand(opts),
+		newMaintenanceCommand(opts),
+		newContractsCommand(opts),
+		newTelemetryCommand(opts),
+		newHeartbeatCommand(opts),
+		newConnectivityCommand(opts),
+		newSoftwareCommand(opts),
+		newComplianceCommand(opts),
+		newDuplicatesCommand(opts),
+		newMergeCommand(opts),
+		newReplaceCommand(opts),
+		newDeleteCommand(opts),
+		newSearchCommand(opts),
+		newImportCommand(opts),
+		newExportCommand(opts),
+	)
+
+	return root
+}
+
+func (o *options) client() (*client.Client, error) {
+	config, err := loadConfig(o.configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if o.server != "" {
+		config.Server = o.server
+	}
+	if o.token != "" {
+		config.Token = o.token
+	}
+
+	return client.New(config.Server, client.WithToken(config.Token)), nil
+}
+
+func listAllDevices(ctx context.Context, c *client.Client) ([]client.Device, error) {
+	return listDevices(ctx, c, "", "", "")
+}
+
+// listDevices fetches all devices matching the filter expression, label
+// selector and connectivity, or all devices when they are empty.
+func listDevices(ctx context.Context, c *client.Client, filter string, selector string, connectivity client.Connectivity) ([]client.Device, error) {
+	devices := make([]client.Device, 0)
+	it := c.DevicesMatching(filter, 0).WithSelector(selector).WithConnectivity(connectivity)
+	for it.Next(ctx) {
+		devices = append(devices, it.Device())
+	}
+	return devices, it.Err()
+}
+
+func (o *options) validateOutput() error {
+	for _, format := range outputFormats {
+		if o.output == format {
+			return nil
+		}
+	}
+	return fmt.Errorf("unsupported output format %q, expected one of %v", o.output, outputFormats)
+}
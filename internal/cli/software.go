@@ -0,0 +1,233 @@
+package cli
+
+import (
+	"devices_crud/pkg/client"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+func newSoftwareCommand(opts *options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "software ID",
+		Short:             "Show the firmware, operating systems and applications of a device",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeDeviceIDs(opts),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := opts.validateOutput(); err != nil {
+				return err
+			}
+			c, err := opts.client()
+			if err != nil {
+				return err
+			}
+
+			software, err := c.Software(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+			return printSoftware(cmd.OutOrStdout(), opts.output, software)
+		},
+	}
+	cmd.AddCommand(newRecordSoftwareCommand(opts))
+	return cmd
+}
+
+func newRecordSoftwareCommand(opts *options) *cobra.Command {
+	var kind, installed string
+	cmd := &cobra.Command{
+		Use:               "record ID NAME VERSION",
+		Short:             "Record the version of a software running on a device",
+		Args:              cobra.ExactArgs(3),
+		ValidArgsFunction: completeDeviceIDs(opts),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := opts.validateOutput(); err != nil {
+				return err
+			}
+			c, err := opts.client()
+			if err != nil {
+				return err
+			}
+
+			request := &client.SoftwareRequest{Kind: client.SoftwareKind(kind), Name: args[1], Version: args[2]}
+			if installed != "" {
+				installedAt, err := parseDate(installed)
+				if err != nil {
+					return fmt.Errorf("invalid --installed: %w", err)
+				}
+				request.InstalledAt = &installedAt
+			}
+			software, err := c.RecordSoftware(cmd.Context(), args[0], request)
+			if err != nil {
+				return err
+			}
+			return printSoftware(cmd.OutOrStdout(), opts.output, []client.InstalledSoftware{*software})
+		},
+	}
+	cmd.Flags().StringVar(&kind, "kind", string(client.SoftwareFirmware), "firmware, os or application")
+	cmd.Flags().StringVar(&installed, "installed", "", "when the version was installed, as YYYY-MM-DD or RFC 3339, now by default")
+	return cmd
+}
+
+func newComplianceCommand(opts *options) *cobra.Command {
+	var policy string
+	cmd := &cobra.Command{
+		Use:               "compliance [ID]",
+		Short:             "Show the devices violating the software policies, or the compliance of one device",
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completeDeviceIDs(opts),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := opts.validateOutput(); err != nil {
+				return err
+			}
+			c, err := opts.client()
+			if err != nil {
+				return err
+			}
+
+			var violators []client.DeviceCompliance
+			if len(args) == 1 {
+				compliance, err := c.DeviceCompliance(cmd.Context(), args[0])
+				if err != nil {
+					return err
+				}
+				if opts.output != formatTable {
+					return writeValue(cmd.OutOrStdout(), opts.output, compliance)
+				}
+				violators = []client.DeviceCompliance{*compliance}
+			} else {
+				report, err := c.ComplianceReport(cmd.Context(), policy)
+				if err != nil {
+					return err
+				}
+				if opts.output != formatTable {
+					return writeValue(cmd.OutOrStdout(), opts.output, report)
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "%d of %d devices comply with %d policies\n", report.Compliant, report.Devices, report.Policies)
+				violators = report.Violators
+			}
+
+			tw := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 4, 2, ' ', 0)
+			fmt.Fprintln(tw, "DEVICE\tNAME\tBRAND\tREASON\tSOFTWARE\tINSTALLED\tREQUIRED")
+			for _, compliance := range violators {
+				for _, violation := range compliance.Violations {
+					fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", compliance.Device.ID, compliance.Device.Name, compliance.Device.DeviceBrand,
+						violation.Reason, formatOptional(violation.Software), formatOptional(violation.Installed), violation.Required)
+				}
+			}
+			return tw.Flush()
+		},
+	}
+	cmd.Flags().StringVar(&policy, "policy", "", "only check the policy with this ID")
+	cmd.AddCommand(newSoftwarePoliciesCommand(opts))
+	return cmd
+}
+
+func newSoftwarePoliciesCommand(opts *options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "policies",
+		Short: "Show the software policies",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := opts.validateOutput(); err != nil {
+				return err
+			}
+			c, err := opts.client()
+			if err != nil {
+				return err
+			}
+
+			policies, err := c.SoftwarePolicies(cmd.Context())
+			if err != nil {
+				return err
+			}
+			return printSoftwarePolicies(cmd.OutOrStdout(), opts.output, policies)
+		},
+	}
+	cmd.AddCommand(newAddSoftwarePolicyCommand(opts), newDeleteSoftwarePolicyCommand(opts))
+	return cmd
+}
+
+func newAddSoftwarePolicyCommand(opts *options) *cobra.Command {
+	request := &client.SoftwarePolicyRequest{}
+	var kind string
+	cmd := &cobra.Command{
+		Use:   "add MIN_VERSION",
+		Short: "Require devices to run at least a version of a software",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := opts.validateOutput(); err != nil {
+				return err
+			}
+			c, err := opts.client()
+			if err != nil {
+				return err
+			}
+
+			request.MinVersion = args[0]
+			request.Kind = client.SoftwareKind(kind)
+			policy, err := c.CreateSoftwarePolicy(cmd.Context(), request)
+			if err != nil {
+				return err
+			}
+			return printSoftwarePolicies(cmd.OutOrStdout(), opts.output, []client.SoftwarePolicy{*policy})
+		},
+	}
+	cmd.Flags().StringVar(&request.Brand, "brand", "", "only the devices of this brand")
+	cmd.Flags().StringVar(&request.Type, "type", "", "only the devices of this type")
+	cmd.Flags().StringVar(&kind, "kind", string(client.SoftwareFirmware), "firmware, os or application")
+	cmd.Flags().StringVar(&request.Software, "software", "", "name of the software, any of the kind by default")
+	cmd.Flags().StringVar(&request.Description, "description", "", "why the policy exists")
+	return cmd
+}
+
+func newDeleteSoftwarePolicyCommand(opts *options) *cobra.Command {
+	return &cobra.Command{
+		Use:   "delete ID",
+		Short: "Delete a software policy",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := opts.client()
+			if err != nil {
+				return err
+			}
+			return c.DeleteSoftwarePolicy(cmd.Context(), args[0])
+		},
+	}
+}
+
+func printSoftware(w io.Writer, format string, software []client.InstalledSoftware) error {
+	if format != formatTable {
+		return writeValue(w, format, software)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tKIND\tNAME\tVERSION\tINSTALLED AT")
+	for _, installed := range software {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", installed.ID, installed.Kind, installed.Name, installed.Version, installed.InstalledAt.Format(time.DateOnly))
+	}
+	return tw.Flush()
+}
+
+func printSoftwarePolicies(w io.Writer, format string, policies []client.SoftwarePolicy) error {
+	if format != formatTable {
+		return writeValue(w, format, policies)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tBRAND\tTYPE\tKIND\tSOFTWARE\tMIN VERSION")
+	for _, policy := range policies {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", policy.ID, formatOptional(policy.Brand), formatOptional(policy.Type), policy.Kind, formatOptional(policy.Software), policy.MinVersion)
+	}
+	return tw.Flush()
+}
+
+func formatOptional(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
@@ -0,0 +1,115 @@
+package cli
+
+import (
+	"devices_crud/pkg/client"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+func newTelemetryCommand(opts *options) *cobra.Command {
+	var since, step time.Duration
+	cmd := &cobra.Command{
+		Use:               "telemetry ID [METRIC]",
+		Short:             "Show the latest readings of a device, or the history of one metric",
+		Args:              cobra.RangeArgs(1, 2),
+		ValidArgsFunction: completeDeviceIDs(opts),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := opts.validateOutput(); err != nil {
+				return err
+			}
+			c, err := opts.client()
+			if err != nil {
+				return err
+			}
+
+			if len(args) == 1 {
+				readings, err := c.LatestReadings(cmd.Context(), args[0])
+				if err != nil {
+					return err
+				}
+				return printReadings(cmd.OutOrStdout(), opts.output, readings)
+			}
+
+			to := time.Now()
+			from := to.Add(-since)
+			if step == 0 {
+				readings, err := c.Readings(cmd.Context(), args[0], args[1], from, to)
+				if err != nil {
+					return err
+				}
+				return printReadings(cmd.OutOrStdout(), opts.output, readings)
+			}
+			aggregates, err := c.ReadingAggregates(cmd.Context(), args[0], args[1], from, to, step)
+			if err != nil {
+				return err
+			}
+			if opts.output != formatTable {
+				return writeValue(cmd.OutOrStdout(), opts.output, aggregates)
+			}
+
+			tw := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 4, 2, ' ', 0)
+			fmt.Fprintln(tw, "START\tCOUNT\tMIN\tMEAN\tMAX\tLAST")
+			for _, aggregate := range aggregates {
+				fmt.Fprintf(tw, "%s\t%d\t%g\t%g\t%g\t%g\n",
+					aggregate.Start.Format(time.RFC3339), aggregate.Count, aggregate.Min, aggregate.Mean, aggregate.Max, aggregate.Last)
+			}
+			return tw.Flush()
+		},
+	}
+	cmd.Flags().DurationVar(&since, "since", 24*time.Hour, "how far back to show the readings of the metric")
+	cmd.Flags().DurationVar(&step, "step", 0, "summarize the readings of the metric per step, e.g. 1h")
+	cmd.AddCommand(newPushReadingsCommand(opts))
+	return cmd
+}
+
+func newPushReadingsCommand(opts *options) *cobra.Command {
+	return &cobra.Command{
+		Use:               "push ID METRIC=VALUE...",
+		Short:             "Send readings of a device taken now",
+		Args:              cobra.MinimumNArgs(2),
+		ValidArgsFunction: completeDeviceIDs(opts),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := opts.client()
+			if err != nil {
+				return err
+			}
+
+			readings := make([]client.Reading, 0, len(args)-1)
+			for _, arg := range args[1:] {
+				metric, value, ok := strings.Cut(arg, "=")
+				if !ok {
+					return fmt.Errorf("invalid reading %q, expected METRIC=VALUE", arg)
+				}
+				number, err := strconv.ParseFloat(value, 64)
+				if err != nil {
+					return fmt.Errorf("invalid value of %s: %w", metric, err)
+				}
+				readings = append(readings, client.Reading{Metric: metric, Value: number})
+			}
+			if err := c.IngestReadings(cmd.Context(), args[0], readings); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "%d readings sent\n", len(readings))
+			return nil
+		},
+	}
+}
+
+func printReadings(w io.Writer, format string, readings []client.Reading) error {
+	if format != formatTable {
+		return writeValue(w, format, readings)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "METRIC\tVALUE\tAT")
+	for _, reading := range readings {
+		fmt.Fprintf(tw, "%s\t%g\t%s\n", reading.Metric, reading.Value, reading.At.Format(time.RFC3339))
+	}
+	return tw.Flush()
+}
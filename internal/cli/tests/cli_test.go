@@ -0,0 +1,166 @@
+package tests
+
+import (
+	"bytes"
+	"devices_crud/internal/cli"
+	"devices_crud/internal/devices"
+	"devices_crud/internal/devices/model"
+	"devices_crud/internal/drivers/rest"
+	"encoding/json"
+	"log/slog"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func setupServer(t *testing.T) *httptest.Server {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	rest.BuildRoutes(router, devices.NewDevicesDependencies(
+		&devices.DeviceDependencies{UseMocks: true, Logger: slog.New(slog.NewTextHandler(os.Stdout, nil))}))
+
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func run(t *testing.T, server *httptest.Server, args ...string) (string, error) {
+	out := &bytes.Buffer{}
+	cmd := cli.NewRootCommand()
+	cmd.SetOut(out)
+	cmd.SetErr(out)
+	cmd.SetArgs(append([]string{"--config", writeConfig(t, server.URL)}, args...))
+
+	err := cmd.Execute()
+	return out.String(), err
+}
+
+func writeConfig(t *testing.T, server string) string {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	err := os.WriteFile(path, []byte("server: "+server+"\ntoken: secret\n"), 0o600)
+	assert.Nil(t, err)
+	return path
+}
+
+func TestShouldCreateAndGetDevice(t *testing.T) {
+	server := setupServer(t)
+
+	out, err := run(t, server, "create", "--name", "phone", "--brand", "Apple", "-o", "json")
+	assert.Nil(t, err)
+
+	created := model.Device{}
+	assert.Nil(t, json.Unmarshal([]byte(out), &created))
+	assert.NotEqual(t, "", created.ID)
+	assert.Equal(t, "phone", created.Name)
+
+	out, err = run(t, server, "get", created.ID)
+	assert.Nil(t, err)
+	assert.Contains(t, out, "NAME")
+	assert.Contains(t, out, created.ID)
+	assert.Contains(t, out, "Apple")
+}
+
+func TestShouldPatchAndDeleteDevice(t *testing.T) {
+	server := setupServer(t)
+
+	out, err := run(t, server, "create", "--name", "phone", "--brand", "Apple", "-o", "json")
+	assert.Nil(t, err)
+	created := model.Device{}
+	json.Unmarshal([]byte(out), &created)
+
+	out, err = run(t, server, "patch", created.ID, "--brand", "Samsung", "-o", "yaml")
+	assert.Nil(t, err)
+	assert.Contains(t, out, "deviceBrand: Samsung")
+	assert.Contains(t, out, "name: phone")
+
+	_, err = run(t, server, "delete", created.ID)
+	assert.Nil(t, err)
+
+	_, err = run(t, server, "get", created.ID)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "404")
+}
+
+func TestShouldExportAndImportDevices(t *testing.T) {
+	server := setupServer(t)
+	run(t, server, "create", "--name", "phone", "--brand", "Apple")
+	run(t, server, "create", "--name", "tablet", "--brand", "Samsung")
+
+	exported := filepath.Join(t.TempDir(), "devices.yaml")
+	out, err := run(t, server, "export", exported)
+	assert.Nil(t, err)
+	assert.Contains(t, out, "exported 2 devices")
+
+	data, _ := os.ReadFile(exported)
+	exportedDevices := []map[string]any{}
+	assert.Nil(t, yaml.Unmarshal(data, &exportedDevices))
+	exportedIDs := make([]string, 0)
+	for _, device := range exportedDevices {
+		exportedIDs = append(exportedIDs, device["id"].(string))
+	}
+
+	// A fresh server starts with an empty repository.
+	target := setupServer(t)
+	out, err = run(t, target, "import", exported)
+	assert.Nil(t, err)
+	assert.Contains(t, out, "imported 2 devices")
+
+	out, _ = run(t, target, "list", "-o", "json")
+	importedDevices := []model.Device{}
+	json.Unmarshal([]byte(out), &importedDevices)
+	assert.Equal(t, 2, len(importedDevices))
+	assert.ElementsMatch(t, exportedIDs, deviceIDs(importedDevices))
+}
+
+func TestShouldImportEveryFieldOfDevicesWithoutID(t *testing.T) {
+	server := setupServer(t)
+	file := filepath.Join(t.TempDir(), "devices.yaml")
+	os.WriteFile(file, []byte(`
+- name: phone
+  deviceBrand: Apple
+  attributes:
+    storage: 128
+  labels:
+    team: mobile
+  status: in_service
+`), 0o600)
+
+	out, err := run(t, server, "import", file)
+	assert.Nil(t, err)
+	assert.Contains(t, out, "imported 1 devices")
+
+	out, _ = run(t, server, "list", "-o", "json")
+	importedDevices := []model.Device{}
+	json.Unmarshal([]byte(out), &importedDevices)
+	if assert.Equal(t, 1, len(importedDevices)) {
+		device := importedDevices[0]
+		assert.NotEmpty(t, device.ID)
+		assert.Equal(t, "phone", device.Name)
+		assert.Equal(t, "Apple", device.DeviceBrand)
+		assert.EqualValues(t, 128, device.Attributes["storage"])
+		assert.Equal(t, map[string]string{"team": "mobile"}, device.Labels)
+		assert.Equal(t, model.StatusInService, device.Status)
+	}
+}
+
+func TestShouldRejectUnknownOutputFormat(t *testing.T) {
+	server := setupServer(t)
+
+	_, err := run(t, server, "list", "-o", "xml")
+	assert.NotNil(t, err)
+	assert.True(t, strings.Contains(err.Error(), "unsupported output format"))
+}
+
+func deviceIDs(devices []model.Device) []string {
+	ids := make([]string, 0, len(devices))
+	for _, device := range devices {
+		ids = append(ids, device.ID)
+	}
+	return ids
+}
@@ -0,0 +1,136 @@
+package cli
+
+import (
+	"devices_crud/pkg/client"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+func newImportCommand(opts *options) *cobra.Command {
+	var format string
+
+	cmd := &cobra.Command{
+		Use:   "import FILE",
+		Short: "Import devices from a JSON or YAML file (- reads stdin)",
+		Long: "Import devices from a JSON or YAML file (- reads stdin).\n\n" +
+			"Devices carrying an id are written with PUT so that the id and creation date\n" +
+			"are kept; devices without an id are created with a new one.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format = formatFor(args[0], format)
+
+			var reader io.Reader = cmd.InOrStdin()
+			if args[0] != "-" {
+				file, err := os.Open(args[0])
+				if err != nil {
+					return err
+				}
+				defer file.Close()
+				reader = file
+			}
+
+			var devices []client.Device
+			if err := readValue(reader, format, &devices); err != nil {
+				return fmt.Errorf("reading %s: %w", args[0], err)
+			}
+
+			c, err := opts.client()
+			if err != nil {
+				return err
+			}
+
+			for i := range devices {
+				device := &devices[i]
+				if device.ID == "" {
+					_, err = c.CreateDevice(cmd.Context(), &client.NewDeviceRequest{
+						Name:        device.Name,
+						DeviceBrand: device.DeviceBrand,
+						Type:        device.Type,
+						Attributes:  device.Attributes,
+						Labels:      device.Labels,
+						Status:      device.Status,
+						LocationID:  device.LocationID,
+					})
+				} else {
+					_, err = c.ReplaceDevice(cmd.Context(), device)
+				}
+				if err != nil {
+					return fmt.Errorf("importing device %d (%s): %w", i, device.Name, err)
+				}
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "imported %d devices\n", len(devices))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&format, "format", "", "input format: json or yaml (default is guessed from the file extension)")
+
+	return cmd
+}
+
+func newExportCommand(opts *options) *cobra.Command {
+	var format string
+
+	cmd := &cobra.Command{
+		Use:   "export [FILE]",
+		Short: "Export all devices to a JSON or YAML file (stdout by default)",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := "-"
+			if len(args) == 1 {
+				path = args[0]
+			}
+			format = formatFor(path, format)
+
+			c, err := opts.client()
+			if err != nil {
+				return err
+			}
+			devices, err := listAllDevices(cmd.Context(), c)
+			if err != nil {
+				return err
+			}
+
+			if path == "-" {
+				return writeValue(cmd.OutOrStdout(), format, devices)
+			}
+
+			file, err := os.Create(path)
+			if err != nil {
+				return err
+			}
+			if err := writeValue(file, format, devices); err != nil {
+				file.Close()
+				return err
+			}
+			if err := file.Close(); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "exported %d devices to %s\n", len(devices), path)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&format, "format", "", "output format: json or yaml (default is guessed from the file extension)")
+
+	return cmd
+}
+
+func formatFor(path string, format string) string {
+	if format != "" {
+		return format
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return formatYAML
+	default:
+		return formatJSON
+	}
+}
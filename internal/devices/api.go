@@ -1,37 +1,85 @@
 package devices
 
 import (
+	"devices_crud/internal/apierrors"
 	"devices_crud/internal/devices/app"
 	"devices_crud/internal/devices/model"
-	"log"
+	"devices_crud/internal/filter"
+	"devices_crud/internal/jsonschema"
+	"devices_crud/internal/labels"
+	"devices_crud/internal/logging"
+	"errors"
+	"log/slog"
+	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
 
 type DevicesRouter struct {
 	devicesService *app.DeviceService
-	logger         *log.Logger
+	logger         *slog.Logger
 }
 
 func BuildRoutes(router *gin.RouterGroup, devicesDeps *DependencyTree) {
 	devicesRouter := &DevicesRouter{
 		devicesService: devicesDeps.DeviceSerivce,
-		logger:         devicesDeps.Logger,
+		logger:         logging.Component(devicesDeps.Logger, "rest"),
 	}
 
+	router.Use(withDeviceID)
+
 	router.GET("", devicesRouter.listDevices)
 	router.GET("/:id", devicesRouter.getDevice)
 	router.GET("/search", devicesRouter.searchDevices)
+	router.GET("/lifecycle", devicesRouter.getLifecycle)
+	router.GET("/duplicates", devicesRouter.listDuplicates)
 	router.POST("", devicesRouter.addDevice)
 	router.DELETE("/:id", devicesRouter.deleteDevice)
 	router.PUT("/:id", devicesRouter.replaceDevice)
 	router.PATCH("/:id", devicesRouter.patchDevice)
+	router.PUT("/:id/labels", devicesRouter.setLabels)
+	router.DELETE("/:id/labels/*key", devicesRouter.removeLabel)
+	router.GET("/:id/transitions", devicesRouter.getTransitions)
+	router.POST("/:id/transitions", devicesRouter.transitionDevice)
+	router.GET("/:id/assignments", devicesRouter.listDeviceAssignments)
+	router.POST("/:id/checkout", devicesRouter.checkOutDevice)
+	router.POST("/:id/checkin", devicesRouter.checkInDevice)
+	router.POST("/:id/transfer", devicesRouter.transferDevice)
+	router.POST("/:id/move", devicesRouter.moveDevice)
+	router.GET("/:id/relationships", devicesRouter.listRelationships)
+	router.POST("/:id/relationships", devicesRouter.linkDevices)
+	router.DELETE("/:id/relationships/:relationshipId", devicesRouter.unlinkDevices)
+	router.GET("/:id/parent", devicesRouter.getParentDevice)
+	router.GET("/:id/children", devicesRouter.listChildDevices)
+	router.GET("/:id/graph", devicesRouter.getDeviceGraph)
+	router.GET("/:id/maintenance", devicesRouter.listDeviceMaintenanceTasks)
+	router.GET("/:id/maintenance-plans", devicesRouter.listMaintenancePlans)
+	router.POST("/:id/maintenance-plans", devicesRouter.createMaintenancePlan)
+	router.DELETE("/:id/maintenance-plans/:planId", devicesRouter.deleteMaintenancePlan)
+	router.GET("/:id/service-records", devicesRouter.listServiceRecords)
+	router.POST("/:id/service-records", devicesRouter.recordService)
+	router.GET("/:id/contracts", devicesRouter.listContracts)
+	router.POST("/:id/contracts", devicesRouter.addContract)
+	router.DELETE("/:id/contracts/:contractId", devicesRouter.deleteContract)
+	router.POST("/:id/telemetry", devicesRouter.ingestReadings)
+	router.GET("/:id/telemetry", devicesRouter.listLatestReadings)
+	router.GET("/:id/telemetry/:metric", devicesRouter.listReadings)
+	router.GET("/:id/telemetry/:metric/aggregate", devicesRouter.aggregateReadings)
+	router.POST("/:id/heartbeat", devicesRouter.heartbeat)
+	router.GET("/:id/connectivity", devicesRouter.getConnectivity)
+	router.GET("/:id/software", devicesRouter.listSoftware)
+	router.PUT("/:id/software", devicesRouter.recordSoftware)
+	router.DELETE("/:id/software/:softwareId", devicesRouter.deleteSoftware)
+	router.GET("/:id/compliance", devicesRouter.getDeviceCompliance)
+	router.GET("/:id/duplicates", devicesRouter.listDeviceDuplicates)
+	router.POST("/:id/merge", devicesRouter.mergeDevices)
 }
 
 func (dr *DevicesRouter) searchDevices(c *gin.Context) {
 	query := c.Query("q")
 	if query == "" {
-		dr.logger.Printf("Error searching devices: %s", "query param is required")
+		dr.logger.InfoContext(c.Request.Context(), "Missing search query")
 		c.JSON(400, gin.H{
 			"message": "query param is required",
 		})
@@ -40,10 +88,7 @@ func (dr *DevicesRouter) searchDevices(c *gin.Context) {
 
 	devices, err := dr.devicesService.SearchDevices(c.Request.Context(), query)
 	if err != nil {
-		dr.logger.Printf("Error searching devices: %s", err)
-		c.JSON(500, gin.H{
-			"message": "Error searching devices",
-		})
+		dr.respondWithError(c, err, "Error searching devices")
 		return
 	}
 
@@ -54,7 +99,7 @@ func (dr *DevicesRouter) patchDevice(c *gin.Context) {
 	var device *model.PatchDeviceRequest
 	err := c.BindJSON(&device)
 	if err != nil {
-		dr.logger.Printf("Error binding device: %s", err)
+		dr.logger.InfoContext(c.Request.Context(), "Invalid device", "error", err)
 		c.JSON(400, gin.H{
 			"message": "Error binding device",
 		})
@@ -63,7 +108,7 @@ func (dr *DevicesRouter) patchDevice(c *gin.Context) {
 
 	deviceID := c.Param("id")
 	if deviceID == "" {
-		dr.logger.Printf("Error patching device: %s", err)
+		dr.logger.InfoContext(c.Request.Context(), "Missing device ID")
 		c.JSON(400, gin.H{
 			"message": "Error patching device",
 		})
@@ -72,10 +117,7 @@ func (dr *DevicesRouter) patchDevice(c *gin.Context) {
 	device.ID = deviceID
 	_, err = dr.devicesService.PatchDevice(c.Request.Context(), device)
 	if err != nil {
-		dr.logger.Printf("Error patching device: %s", err)
-		c.JSON(500, gin.H{
-			"message": "Error patching device",
-		})
+		dr.respondWithError(c, err, "Error patching device")
 		return
 	}
 
@@ -86,7 +128,7 @@ func (dr *DevicesRouter) replaceDevice(c *gin.Context) {
 	var device *model.Device
 	err := c.BindJSON(&device)
 	if err != nil {
-		dr.logger.Printf("Error binding device: %s", err)
+		dr.logger.InfoContext(c.Request.Context(), "Invalid device", "error", err)
 		c.JSON(500, gin.H{
 			"message": "Error binding device",
 		})
@@ -96,23 +138,24 @@ func (dr *DevicesRouter) replaceDevice(c *gin.Context) {
 	device.ID = c.Param("id")
 	_, err = dr.devicesService.ReplaceDevice(c.Request.Context(), device)
 	if err != nil {
-		dr.logger.Printf("Error replacing device: %s", err)
-		c.JSON(500, gin.H{
-			"message": "Error replacing device",
-		})
+		dr.respondWithError(c, err, "Error replacing device")
 		return
 	}
 
 	c.JSON(200, device)
 }
 
+// deleteDevice deletes the device, and with cascade=true the devices it
+// contains too.
 func (dr *DevicesRouter) deleteDevice(c *gin.Context) {
-	err := dr.devicesService.DeleteDevice(c.Request.Context(), c.Param("id"))
+	var err error
+	if c.Query("cascade") == "true" {
+		err = dr.devicesService.DeleteDeviceTree(c.Request.Context(), c.Param("id"))
+	} else {
+		err = dr.devicesService.DeleteDevice(c.Request.Context(), c.Param("id"))
+	}
 	if err != nil {
-		dr.logger.Printf("Error deleting device: %s", err)
-		c.JSON(500, gin.H{
-			"message": "Error deleting device",
-		})
+		dr.respondWithError(c, err, "Error deleting device")
 		return
 	}
 
@@ -120,31 +163,101 @@ func (dr *DevicesRouter) deleteDevice(c *gin.Context) {
 }
 
 func (dr *DevicesRouter) listDevices(c *gin.Context) {
-	devices, err := dr.devicesService.GetAllDevices(c.Request.Context())
+	request, err := parseListDevicesRequest(c)
 	if err != nil {
-		dr.logger.Printf("Error getting devices: %s", err)
-		c.JSON(500, gin.H{
-			"message": "Error getting devices",
+		dr.logger.InfoContext(c.Request.Context(), "Invalid list request", "error", err)
+		c.JSON(400, gin.H{
+			"message": err.Error(),
 		})
 		return
 	}
 
+	devices, total, err := dr.devicesService.ListDevices(c.Request.Context(), request)
+	if err != nil {
+		dr.respondWithError(c, err, "Error getting devices")
+		return
+	}
+
+	c.Header("X-Total-Count", strconv.Itoa(total))
 	c.JSON(200, devices)
 }
 
+func parseListDevicesRequest(c *gin.Context) (*model.ListDevicesRequest, error) {
+	request := &model.ListDevicesRequest{
+		Filter:       c.Query("filter"),
+		Selector:     c.Query("selector"),
+		Connectivity: model.Connectivity(c.Query("connectivity")),
+	}
+
+	if limit := c.Query("limit"); limit != "" {
+		value, err := strconv.Atoi(limit)
+		if err != nil || value < 0 {
+			return nil, errors.New("limit must be a non-negative integer")
+		}
+		request.Limit = value
+	}
+	if offset := c.Query("offset"); offset != "" {
+		value, err := strconv.Atoi(offset)
+		if err != nil || value < 0 {
+			return nil, errors.New("offset must be a non-negative integer")
+		}
+		request.Offset = value
+	}
+
+	return request, nil
+}
+
+func (dr *DevicesRouter) setLabels(c *gin.Context) {
+	var values map[string]string
+	err := c.BindJSON(&values)
+	if err != nil {
+		dr.logger.InfoContext(c.Request.Context(), "Invalid labels", "error", err)
+		c.JSON(400, gin.H{
+			"message": "Error binding labels",
+		})
+		return
+	}
+
+	device, err := dr.devicesService.SetLabels(c.Request.Context(), c.Param("id"), values)
+	dr.respondWithUpdatedDevice(c, device, err, "Error setting labels")
+}
+
+func (dr *DevicesRouter) removeLabel(c *gin.Context) {
+	device, err := dr.devicesService.RemoveLabels(c.Request.Context(), c.Param("id"), []string{strings.TrimPrefix(c.Param("key"), "/")})
+	dr.respondWithUpdatedDevice(c, device, err, "Error removing label")
+}
+
+func (dr *DevicesRouter) respondWithUpdatedDevice(c *gin.Context, device *model.Device, err error, message string) {
+	if err != nil {
+		dr.respondWithError(c, err, message)
+		return
+	}
+
+	if device == nil {
+		dr.logger.InfoContext(c.Request.Context(), "Device not found")
+		c.JSON(404, gin.H{
+			"message": "Device not found",
+			"code":    "NOT_FOUND",
+		})
+		return
+	}
+
+	c.JSON(200, device)
+}
+
 func (dr *DevicesRouter) getDevice(c *gin.Context) {
 	id := c.Param("id")
 	device, err := dr.devicesService.GetDevice(c.Request.Context(), id)
 	if err != nil {
-		dr.logger.Printf("Error getting device: %s", err)
-		c.JSON(500, gin.H{
-			"message": "Error getting device",
-		})
+		dr.respondWithError(c, err, "Error getting device")
 		return
 	}
 
 	if device == nil {
-		dr.logger.Printf("Device not found: %s", id)
+		if dr.redirectMerged(c) {
+			return
+		}
+		dr.logger.InfoContext(c.Request.Context(), "Device not found")
 		c.JSON(404, gin.H{
 			"message": "Device not found",
 		})
@@ -158,7 +271,7 @@ func (dr *DevicesRouter) addDevice(c *gin.Context) {
 	var device *model.NewDeviceRequest
 	err := c.BindJSON(&device)
 	if err != nil {
-		dr.logger.Printf("Error binding device: %s", err)
+		dr.logger.InfoContext(c.Request.Context(), "Invalid device", "error", err)
 		c.JSON(500, gin.H{
 			"message": "Error binding device",
 		})
@@ -167,10 +280,7 @@ func (dr *DevicesRouter) addDevice(c *gin.Context) {
 
 	id, err := dr.devicesService.AddDevice(c.Request.Context(), device)
 	if err != nil {
-		dr.logger.Printf("Error adding device: %s", err)
-		c.JSON(500, gin.H{
-			"message": "Error adding device",
-		})
+		dr.respondWithError(c, err, "Error adding device")
 		return
 	}
 
@@ -178,3 +288,46 @@ func (dr *DevicesRouter) addDevice(c *gin.Context) {
 		UUID: *id,
 	})
 }
+
+// respondWithError maps service errors to status codes and the same error codes
+// the GraphQL driver reports. Unexpected errors are reported as 500.
+func (dr *DevicesRouter) respondWithError(c *gin.Context, err error, message string) {
+	ctx := c.Request.Context()
+
+	code, ok := apierrors.Lookup(err)
+	if !ok {
+		dr.logger.ErrorContext(ctx, message, "error", err)
+		c.JSON(500, gin.H{
+			"message": message,
+		})
+		return
+	}
+
+	dr.logger.Log(ctx, code.Level, message, "error", err)
+	response := gin.H{
+		"message": code.MessageOf(err),
+		"code":    code.Code,
+	}
+	var filterErr *filter.SyntaxError
+	if errors.As(err, &filterErr) {
+		response["position"] = filterErr.Position
+	}
+	var selectorErr *labels.SyntaxError
+	if errors.As(err, &selectorErr) {
+		response["position"] = selectorErr.Position
+	}
+	var violations jsonschema.Errors
+	if errors.As(err, &violations) {
+		response["errors"] = violations
+	}
+	c.JSON(code.Status, response)
+}
+
+// withDeviceID adds the device ID of the route to the log records of the
+// request.
+func withDeviceID(c *gin.Context) {
+	if id := c.Param("id"); id != "" {
+		c.Request = c.Request.WithContext(logging.WithAttrs(c.Request.Context(), slog.String(logging.DeviceIDKey, id)))
+	}
+	c.Next()
+}
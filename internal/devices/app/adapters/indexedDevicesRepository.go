@@ -0,0 +1,135 @@
+package adapters
+
+import (
+	"context"
+	"devices_crud/internal/devices/app/ports"
+	"devices_crud/internal/devices/model"
+	"devices_crud/internal/filter"
+	"devices_crud/internal/labels"
+	"devices_crud/internal/search"
+	"devices_crud/internal/tenancy"
+	"fmt"
+	"time"
+)
+
+// indexedDevicesRepository keeps a search index in sync with the writes to the
+// wrapped repository.
+type indexedDevicesRepository struct {
+	next  ports.DevicesRepository
+	index *search.Index
+}
+
+// NewIndexedDevicesRepository indexes every device already stored in next and
+// keeps index up to date from then on.
+func NewIndexedDevicesRepository(next ports.DevicesRepository, index *search.Index) (ports.DevicesRepository, error) {
+	devices, err := next.FindAll(tenancy.WithAllTenants(context.Background()))
+	if err != nil {
+		return nil, fmt.Errorf("indexing devices: %w", err)
+	}
+	for _, device := range devices {
+		index.Index(DeviceDocument(&device))
+	}
+
+	return &indexedDevicesRepository{next: next, index: index}, nil
+}
+
+// DeviceDocument is the searchable content of a device.
+func DeviceDocument(device *model.Device) search.Document {
+	return search.Document{
+		ID:     device.ID,
+		Tenant: device.TenantID,
+		Fields: map[string]string{
+			search.FieldName:       device.Name,
+			search.FieldBrand:      device.DeviceBrand,
+			search.FieldAttributes: device.Attributes.Text(),
+		},
+	}
+}
+
+func (r *indexedDevicesRepository) Save(ctx context.Context, device *model.Device, limit int) (*string, error) {
+	id, err := r.next.Save(ctx, device, limit)
+	if err == nil {
+		r.index.Index(DeviceDocument(device))
+	}
+	return id, err
+}
+
+func (r *indexedDevicesRepository) FindByID(ctx context.Context, id *string) (*model.Device, error) {
+	return r.next.FindByID(ctx, id)
+}
+
+func (r *indexedDevicesRepository) FindAll(ctx context.Context) ([]model.Device, error) {
+	return r.next.FindAll(ctx)
+}
+
+func (r *indexedDevicesRepository) FindByFilter(ctx context.Context, expr filter.Expr) ([]model.Device, error) {
+	return r.next.FindByFilter(ctx, expr)
+}
+
+func (r *indexedDevicesRepository) FindByLabels(ctx context.Context, selector labels.Selector) ([]model.Device, error) {
+	return r.next.FindByLabels(ctx, selector)
+}
+
+func (r *indexedDevicesRepository) Replace(ctx context.Context, device *model.Device) (*model.Device, error) {
+	replaced, err := r.next.Replace(ctx, device)
+	if err == nil && replaced != nil {
+		r.index.Index(DeviceDocument(replaced))
+	}
+	return replaced, err
+}
+
+func (r *indexedDevicesRepository) Patch(ctx context.Context, device *model.PatchDeviceRequest) (*string, error) {
+	id, err := r.next.Patch(ctx, device)
+	if err != nil || id == nil {
+		return id, err
+	}
+
+	patched, err := r.next.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if patched != nil {
+		r.index.Index(DeviceDocument(patched))
+	}
+	return id, nil
+}
+
+func (r *indexedDevicesRepository) UpdateStatus(ctx context.Context, id string, change model.StatusChange) (*model.Device, error) {
+	return r.next.UpdateStatus(ctx, id, change)
+}
+
+func (r *indexedDevicesRepository) UpdateLocation(ctx context.Context, id string, change model.LocationChange) (*model.Device, error) {
+	return r.next.UpdateLocation(ctx, id, change)
+}
+
+func (r *indexedDevicesRepository) TouchLastSeen(ctx context.Context, id string, at time.Time) (*model.Device, error) {
+	return r.next.TouchLastSeen(ctx, id, at)
+}
+
+func (r *indexedDevicesRepository) Delete(ctx context.Context, id string) error {
+	// The repository ignores devices of other tenants, so only drop the
+	// document once the device is really gone.
+	if err := r.next.Delete(ctx, id); err != nil {
+		return err
+	}
+	remaining, err := r.next.FindByID(tenancy.WithAllTenants(ctx), &id)
+	if err != nil {
+		return err
+	}
+	if remaining == nil {
+		r.index.Remove(id)
+	}
+	return nil
+}
+
+func (r *indexedDevicesRepository) Search(ctx context.Context, query string) ([]model.Device, error) {
+	return r.next.Search(ctx, query)
+}
+
+func (r *indexedDevicesRepository) Count(ctx context.Context) (int, error) {
+	return r.next.Count(ctx)
+}
+
+func (r *indexedDevicesRepository) Ping(ctx context.Context) error {
+	return r.next.Ping(ctx)
+}
@@ -0,0 +1,126 @@
+package adapters
+
+import (
+	"context"
+	"devices_crud/internal/devices/app/ports"
+	"devices_crud/internal/devices/model"
+	"devices_crud/internal/filter"
+	"devices_crud/internal/labels"
+	"devices_crud/internal/metrics"
+	"time"
+)
+
+const devicesRepositoryName = "devices"
+
+// instrumentedDevicesRepository records the latency of every call to the
+// wrapped repository.
+type instrumentedDevicesRepository struct {
+	next    ports.DevicesRepository
+	metrics *metrics.Metrics
+}
+
+func NewInstrumentedDevicesRepository(next ports.DevicesRepository, metrics *metrics.Metrics) ports.DevicesRepository {
+	return &instrumentedDevicesRepository{next: next, metrics: metrics}
+}
+
+func (r *instrumentedDevicesRepository) observe(method string, start time.Time, err error) {
+	r.metrics.ObserveRepositoryCall(devicesRepositoryName, method, err, time.Since(start))
+}
+
+func (r *instrumentedDevicesRepository) Save(ctx context.Context, device *model.Device, limit int) (*string, error) {
+	start := time.Now()
+	id, err := r.next.Save(ctx, device, limit)
+	r.observe("Save", start, err)
+	return id, err
+}
+
+func (r *instrumentedDevicesRepository) FindByID(ctx context.Context, id *string) (*model.Device, error) {
+	start := time.Now()
+	device, err := r.next.FindByID(ctx, id)
+	r.observe("FindByID", start, err)
+	return device, err
+}
+
+func (r *instrumentedDevicesRepository) FindAll(ctx context.Context) ([]model.Device, error) {
+	start := time.Now()
+	devices, err := r.next.FindAll(ctx)
+	r.observe("FindAll", start, err)
+	return devices, err
+}
+
+func (r *instrumentedDevicesRepository) FindByFilter(ctx context.Context, expr filter.Expr) ([]model.Device, error) {
+	start := time.Now()
+	devices, err := r.next.FindByFilter(ctx, expr)
+	r.observe("FindByFilter", start, err)
+	return devices, err
+}
+
+func (r *instrumentedDevicesRepository) FindByLabels(ctx context.Context, selector labels.Selector) ([]model.Device, error) {
+	start := time.Now()
+	devices, err := r.next.FindByLabels(ctx, selector)
+	r.observe("FindByLabels", start, err)
+	return devices, err
+}
+
+func (r *instrumentedDevicesRepository) Replace(ctx context.Context, device *model.Device) (*model.Device, error) {
+	start := time.Now()
+	replaced, err := r.next.Replace(ctx, device)
+	r.observe("Replace", start, err)
+	return replaced, err
+}
+
+func (r *instrumentedDevicesRepository) Patch(ctx context.Context, device *model.PatchDeviceRequest) (*string, error) {
+	start := time.Now()
+	id, err := r.next.Patch(ctx, device)
+	r.observe("Patch", start, err)
+	return id, err
+}
+
+func (r *instrumentedDevicesRepository) UpdateStatus(ctx context.Context, id string, change model.StatusChange) (*model.Device, error) {
+	start := time.Now()
+	device, err := r.next.UpdateStatus(ctx, id, change)
+	r.observe("UpdateStatus", start, err)
+	return device, err
+}
+
+func (r *instrumentedDevicesRepository) UpdateLocation(ctx context.Context, id string, change model.LocationChange) (*model.Device, error) {
+	start := time.Now()
+	device, err := r.next.UpdateLocation(ctx, id, change)
+	r.observe("UpdateLocation", start, err)
+	return device, err
+}
+
+func (r *instrumentedDevicesRepository) TouchLastSeen(ctx context.Context, id string, at time.Time) (*model.Device, error) {
+	start := time.Now()
+	device, err := r.next.TouchLastSeen(ctx, id, at)
+	r.observe("TouchLastSeen", start, err)
+	return device, err
+}
+
+func (r *instrumentedDevicesRepository) Delete(ctx context.Context, id string) error {
+	start := time.Now()
+	err := r.next.Delete(ctx, id)
+	r.observe("Delete", start, err)
+	return err
+}
+
+func (r *instrumentedDevicesRepository) Search(ctx context.Context, query string) ([]model.Device, error) {
+	start := time.Now()
+	devices, err := r.next.Search(ctx, query)
+	r.observe("Search", start, err)
+	return devices, err
+}
+
+func (r *instrumentedDevicesRepository) Count(ctx context.Context) (int, error) {
+	start := time.Now()
+	count, err := r.next.Count(ctx)
+	r.observe("Count", start, err)
+	return count, err
+}
+
+func (r *instrumentedDevicesRepository) Ping(ctx context.Context) error {
+	start := time.Now()
+	err := r.next.Ping(ctx)
+	r.observe("Ping", start, err)
+	return err
+}
@@ -0,0 +1,90 @@
+package adapters
+
+import (
+	"context"
+	"devices_crud/internal/devices/app/ports"
+	"devices_crud/internal/devices/model"
+	"devices_crud/internal/timeseries"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// telemetryRepository keeps readings in a timeseries.Store, one series per
+// device and metric.
+type telemetryRepository struct {
+	store *timeseries.Store
+}
+
+// NewTelemetryRepository stores readings in memory, downsampled as they age
+// according to the tiers of store.
+func NewTelemetryRepository(store *timeseries.Store) ports.TelemetryRepository {
+	return &telemetryRepository{store: store}
+}
+
+func (r *telemetryRepository) Append(ctx context.Context, deviceID string, readings []model.Reading) error {
+	points := map[string][]timeseries.Point{}
+	for _, reading := range readings {
+		points[reading.Metric] = append(points[reading.Metric], timeseries.Point{At: reading.At, Value: reading.Value})
+	}
+	now := time.Now()
+	for metric, metricPoints := range points {
+		r.store.Append(seriesKey(deviceID, metric), metricPoints, now)
+	}
+	return nil
+}
+
+func (r *telemetryRepository) Range(ctx context.Context, deviceID string, metric string, from time.Time, to time.Time) ([]model.Reading, error) {
+	buckets := r.store.Range(seriesKey(deviceID, metric), from, to)
+	readings := make([]model.Reading, len(buckets))
+	for i, bucket := range buckets {
+		readings[i] = model.Reading{Metric: metric, Value: bucket.Mean(), At: bucket.Start}
+	}
+	return readings, nil
+}
+
+func (r *telemetryRepository) Aggregate(ctx context.Context, deviceID string, metric string, from time.Time, to time.Time, step time.Duration) ([]model.ReadingAggregate, error) {
+	buckets := r.store.Aggregate(seriesKey(deviceID, metric), from, to, step)
+	aggregates := make([]model.ReadingAggregate, len(buckets))
+	for i, bucket := range buckets {
+		end := to
+		if step > 0 && bucket.Start.Add(step).Before(to) {
+			end = bucket.Start.Add(step)
+		}
+		aggregates[i] = model.ReadingAggregate{
+			Metric: metric,
+			Start:  bucket.Start,
+			End:    end,
+			Count:  bucket.Count,
+			Min:    bucket.Min,
+			Max:    bucket.Max,
+			Mean:   bucket.Mean(),
+			Last:   bucket.Last,
+		}
+	}
+	return aggregates, nil
+}
+
+func (r *telemetryRepository) Latest(ctx context.Context, deviceID string) ([]model.Reading, error) {
+	prefix := seriesKey(deviceID, "")
+	readings := make([]model.Reading, 0)
+	for _, key := range r.store.Keys(prefix) {
+		if point, ok := r.store.Latest(key); ok {
+			readings = append(readings, model.Reading{Metric: strings.TrimPrefix(key, prefix), Value: point.Value, At: point.At})
+		}
+	}
+	return readings, nil
+}
+
+func (r *telemetryRepository) Delete(ctx context.Context, deviceID string) error {
+	for _, key := range r.store.Keys(seriesKey(deviceID, "")) {
+		r.store.Delete(key)
+	}
+	return nil
+}
+
+// seriesKey escapes deviceID, so that the prefix of one device never matches the
+// series of another whose ID starts with it, such as "a/" and "a/b/temperature".
+func seriesKey(deviceID string, metric string) string {
+	return url.PathEscape(deviceID) + "/" + metric
+}
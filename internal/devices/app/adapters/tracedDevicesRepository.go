@@ -0,0 +1,134 @@
+package adapters
+
+import (
+	"context"
+	"devices_crud/internal/devices/app/ports"
+	"devices_crud/internal/devices/model"
+	"devices_crud/internal/filter"
+	"devices_crud/internal/labels"
+	"devices_crud/internal/tracing"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracedDevicesRepository wraps every call to the wrapped repository in a
+// span, so storage time shows up separately from the service and drivers.
+type tracedDevicesRepository struct {
+	next   ports.DevicesRepository
+	tracer trace.Tracer
+}
+
+func NewTracedDevicesRepository(next ports.DevicesRepository, provider trace.TracerProvider) ports.DevicesRepository {
+	return &tracedDevicesRepository{next: next, tracer: tracing.Tracer(provider, "repository")}
+}
+
+func (r *tracedDevicesRepository) start(ctx context.Context, method string, attributes ...attribute.KeyValue) (context.Context, trace.Span) {
+	return r.tracer.Start(ctx, "DevicesRepository."+method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attributes...),
+	)
+}
+
+func (r *tracedDevicesRepository) Save(ctx context.Context, device *model.Device, limit int) (*string, error) {
+	ctx, span := r.start(ctx, "Save", attribute.String("device.id", device.ID))
+	id, err := r.next.Save(ctx, device, limit)
+	tracing.End(span, err)
+	return id, err
+}
+
+func (r *tracedDevicesRepository) FindByID(ctx context.Context, id *string) (*model.Device, error) {
+	ctx, span := r.start(ctx, "FindByID", attribute.String("device.id", *id))
+	device, err := r.next.FindByID(ctx, id)
+	tracing.End(span, err)
+	return device, err
+}
+
+func (r *tracedDevicesRepository) FindAll(ctx context.Context) ([]model.Device, error) {
+	ctx, span := r.start(ctx, "FindAll")
+	devices, err := r.next.FindAll(ctx)
+	span.SetAttributes(attribute.Int("devices.count", len(devices)))
+	tracing.End(span, err)
+	return devices, err
+}
+
+func (r *tracedDevicesRepository) FindByFilter(ctx context.Context, expr filter.Expr) ([]model.Device, error) {
+	ctx, span := r.start(ctx, "FindByFilter", attribute.String("filter", expr.String()))
+	devices, err := r.next.FindByFilter(ctx, expr)
+	span.SetAttributes(attribute.Int("devices.count", len(devices)))
+	tracing.End(span, err)
+	return devices, err
+}
+
+func (r *tracedDevicesRepository) FindByLabels(ctx context.Context, selector labels.Selector) ([]model.Device, error) {
+	ctx, span := r.start(ctx, "FindByLabels", attribute.String("selector", selector.String()))
+	devices, err := r.next.FindByLabels(ctx, selector)
+	span.SetAttributes(attribute.Int("devices.count", len(devices)))
+	tracing.End(span, err)
+	return devices, err
+}
+
+func (r *tracedDevicesRepository) Replace(ctx context.Context, device *model.Device) (*model.Device, error) {
+	ctx, span := r.start(ctx, "Replace", attribute.String("device.id", device.ID))
+	replaced, err := r.next.Replace(ctx, device)
+	tracing.End(span, err)
+	return replaced, err
+}
+
+func (r *tracedDevicesRepository) Patch(ctx context.Context, device *model.PatchDeviceRequest) (*string, error) {
+	ctx, span := r.start(ctx, "Patch", attribute.String("device.id", device.ID))
+	id, err := r.next.Patch(ctx, device)
+	tracing.End(span, err)
+	return id, err
+}
+
+func (r *tracedDevicesRepository) UpdateStatus(ctx context.Context, id string, change model.StatusChange) (*model.Device, error) {
+	ctx, span := r.start(ctx, "UpdateStatus", attribute.String("device.id", id), attribute.String("device.status", string(change.To)))
+	device, err := r.next.UpdateStatus(ctx, id, change)
+	tracing.End(span, err)
+	return device, err
+}
+
+func (r *tracedDevicesRepository) UpdateLocation(ctx context.Context, id string, change model.LocationChange) (*model.Device, error) {
+	ctx, span := r.start(ctx, "UpdateLocation", attribute.String("device.id", id), attribute.String("location.id", change.To))
+	device, err := r.next.UpdateLocation(ctx, id, change)
+	tracing.End(span, err)
+	return device, err
+}
+
+func (r *tracedDevicesRepository) TouchLastSeen(ctx context.Context, id string, at time.Time) (*model.Device, error) {
+	ctx, span := r.start(ctx, "TouchLastSeen", attribute.String("device.id", id))
+	device, err := r.next.TouchLastSeen(ctx, id, at)
+	tracing.End(span, err)
+	return device, err
+}
+
+func (r *tracedDevicesRepository) Delete(ctx context.Context, id string) error {
+	ctx, span := r.start(ctx, "Delete", attribute.String("device.id", id))
+	err := r.next.Delete(ctx, id)
+	tracing.End(span, err)
+	return err
+}
+
+func (r *tracedDevicesRepository) Search(ctx context.Context, query string) ([]model.Device, error) {
+	ctx, span := r.start(ctx, "Search")
+	devices, err := r.next.Search(ctx, query)
+	span.SetAttributes(attribute.Int("devices.count", len(devices)))
+	tracing.End(span, err)
+	return devices, err
+}
+
+func (r *tracedDevicesRepository) Count(ctx context.Context) (int, error) {
+	ctx, span := r.start(ctx, "Count")
+	count, err := r.next.Count(ctx)
+	tracing.End(span, err)
+	return count, err
+}
+
+func (r *tracedDevicesRepository) Ping(ctx context.Context) error {
+	ctx, span := r.start(ctx, "Ping")
+	err := r.next.Ping(ctx)
+	tracing.End(span, err)
+	return err
+}
@@ -0,0 +1,249 @@
+package app
+
+import (
+	"context"
+	"devices_crud/internal/auth"
+	"devices_crud/internal/devices/model"
+	"devices_crud/internal/logging"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// CheckOutDevice lends an in-service device to a person. It returns nil when
+// there is no device with that ID.
+func (s *DeviceService) CheckOutDevice(ctx context.Context, deviceID string, request *model.CheckOutRequest) (*model.Assignment, error) {
+	ctx, span := s.startSpan(ctx, "CheckOutDevice", attribute.String("device.id", deviceID))
+	defer span.End()
+
+	if err := s.authorize(ctx, auth.PermissionDevicesWrite); err != nil {
+		return nil, err
+	}
+	device, current, err := s.assignable(ctx, deviceID)
+	if err != nil || device == nil {
+		return nil, err
+	}
+	if current != nil {
+		return nil, fmt.Errorf("%w: checked out to %s since %s", model.ErrDeviceAssigned, current.Assignee, current.CheckedOutAt.Format(time.RFC3339))
+	}
+	if device.Status != model.StatusInService {
+		return nil, fmt.Errorf("%w: device is %s", model.ErrDeviceUnavailable, device.Status)
+	}
+
+	assignment, err := s.assign(ctx, deviceID, request)
+	if err != nil {
+		return nil, err
+	}
+	s.Logger.InfoContext(ctx, "Device checked out", logging.DeviceIDKey, deviceID, "assignee", assignment.Assignee)
+	return assignment, nil
+}
+
+// CheckInDevice ends the active assignment of a device. It returns nil when
+// there is no device with that ID.
+func (s *DeviceService) CheckInDevice(ctx context.Context, deviceID string, request *model.CheckInRequest) (*model.Assignment, error) {
+	ctx, span := s.startSpan(ctx, "CheckInDevice", attribute.String("device.id", deviceID))
+	defer span.End()
+
+	if err := s.authorize(ctx, auth.PermissionDevicesWrite); err != nil {
+		return nil, err
+	}
+	device, current, err := s.assignable(ctx, deviceID)
+	if err != nil || device == nil {
+		return nil, err
+	}
+	if current == nil {
+		return nil, model.ErrDeviceNotAssigned
+	}
+
+	if err := s.release(ctx, current, request.Note); err != nil {
+		return nil, err
+	}
+	s.Logger.InfoContext(ctx, "Device checked in", logging.DeviceIDKey, deviceID, "assignee", current.Assignee)
+	return current, nil
+}
+
+// TransferDevice checks a device in from its current assignee and out to
+// another person in one step. It returns the new assignment, or nil when there
+// is no device with that ID.
+func (s *DeviceService) TransferDevice(ctx context.Context, deviceID string, request *model.CheckOutRequest) (*model.Assignment, error) {
+	ctx, span := s.startSpan(ctx, "TransferDevice", attribute.String("device.id", deviceID))
+	defer span.End()
+
+	if err := s.authorize(ctx, auth.PermissionDevicesWrite); err != nil {
+		return nil, err
+	}
+	device, current, err := s.assignable(ctx, deviceID)
+	if err != nil || device == nil {
+		return nil, err
+	}
+	if current == nil {
+		return nil, model.ErrDeviceNotAssigned
+	}
+	if err := validateCheckOut(request, time.Now()); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(request.Assignee) == current.Assignee {
+		return nil, fmt.Errorf("%w: device is already checked out to %s", model.ErrInvalidAssignment, current.Assignee)
+	}
+
+	if err := s.release(ctx, current, "transferred to "+strings.TrimSpace(request.Assignee)); err != nil {
+		return nil, err
+	}
+	assignment, err := s.assign(ctx, deviceID, request)
+	if err != nil {
+		return nil, err
+	}
+	s.Logger.InfoContext(ctx, "Device transferred", logging.DeviceIDKey, deviceID, "from", current.Assignee, "to", assignment.Assignee)
+	return assignment, nil
+}
+
+// CurrentAssignment returns the active assignment of a device, nil when it is
+// not checked out.
+func (s *DeviceService) CurrentAssignment(ctx context.Context, deviceID string) (*model.Assignment, error) {
+	ctx, span := s.startSpan(ctx, "CurrentAssignment", attribute.String("device.id", deviceID))
+	defer span.End()
+
+	if err := s.authorize(ctx, auth.PermissionDevicesRead); err != nil {
+		return nil, err
+	}
+	if s.Assignments == nil {
+		return nil, nil
+	}
+	return s.Assignments.FindActiveByDevice(ctx, deviceID)
+}
+
+// DeviceAssignments returns the assignment history of a device, oldest first.
+func (s *DeviceService) DeviceAssignments(ctx context.Context, deviceID string) ([]model.Assignment, error) {
+	ctx, span := s.startSpan(ctx, "DeviceAssignments", attribute.String("device.id", deviceID))
+	defer span.End()
+
+	return s.listAssignments(ctx, func(ctx context.Context) ([]model.Assignment, error) {
+		return s.Assignments.FindByDevice(ctx, deviceID)
+	})
+}
+
+// AssigneeAssignments returns the devices a person has held, oldest first.
+func (s *DeviceService) AssigneeAssignments(ctx context.Context, assignee string) ([]model.Assignment, error) {
+	ctx, span := s.startSpan(ctx, "AssigneeAssignments")
+	defer span.End()
+
+	return s.listAssignments(ctx, func(ctx context.Context) ([]model.Assignment, error) {
+		return s.Assignments.FindByAssignee(ctx, assignee)
+	})
+}
+
+// OverdueAssignments returns the active assignments that were due before at,
+// the longest overdue first.
+func (s *DeviceService) OverdueAssignments(ctx context.Context, at time.Time) ([]model.Assignment, error) {
+	ctx, span := s.startSpan(ctx, "OverdueAssignments")
+	defer span.End()
+
+	active, err := s.listAssignments(ctx, func(ctx context.Context) ([]model.Assignment, error) {
+		return s.Assignments.FindActive(ctx)
+	})
+	if err != nil {
+		return nil, err
+	}
+	overdue := make([]model.Assignment, 0)
+	for _, assignment := range active {
+		if assignment.Overdue(at) {
+			overdue = append(overdue, assignment)
+		}
+	}
+	sort.SliceStable(overdue, func(i, j int) bool { return overdue[i].DueAt.Before(*overdue[j].DueAt) })
+	span.SetAttributes(attribute.Int("assignments.overdue", len(overdue)))
+	return overdue, nil
+}
+
+func (s *DeviceService) listAssignments(ctx context.Context, find func(context.Context) ([]model.Assignment, error)) ([]model.Assignment, error) {
+	if err := s.authorize(ctx, auth.PermissionDevicesRead); err != nil {
+		return nil, err
+	}
+	if s.Assignments == nil {
+		return []model.Assignment{}, nil
+	}
+
+	assignments, err := find(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(assignments, func(i, j int) bool {
+		if assignments[i].CheckedOutAt.Equal(assignments[j].CheckedOutAt) {
+			return assignments[i].ID < assignments[j].ID
+		}
+		return assignments[i].CheckedOutAt.Before(assignments[j].CheckedOutAt)
+	})
+	return assignments, nil
+}
+
+// assignable returns the device and its active assignment. The device is nil
+// when it does not exist.
+func (s *DeviceService) assignable(ctx context.Context, deviceID string) (*model.Device, *model.Assignment, error) {
+	if s.Assignments == nil {
+		return nil, nil, fmt.Errorf("%w: assignments are not supported", model.ErrInvalidAssignment)
+	}
+	device, err := s.DevicesRepository.FindByID(ctx, &deviceID)
+	if err != nil || device == nil {
+		return nil, nil, err
+	}
+	current, err := s.Assignments.FindActiveByDevice(ctx, deviceID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return device, current, nil
+}
+
+func (s *DeviceService) assign(ctx context.Context, deviceID string, request *model.CheckOutRequest) (*model.Assignment, error) {
+	now := time.Now()
+	if err := validateCheckOut(request, now); err != nil {
+		return nil, err
+	}
+
+	assignment := &model.Assignment{
+		ID:           uuid.New().String(),
+		DeviceID:     deviceID,
+		Assignee:     strings.TrimSpace(request.Assignee),
+		CheckedOutAt: now,
+		DueAt:        request.DueAt,
+		Note:         request.Note,
+		CheckedOutBy: actor(ctx),
+	}
+	if err := s.Assignments.CheckOut(ctx, assignment); err != nil {
+		if !errors.Is(err, model.ErrDeviceAssigned) {
+			s.Logger.ErrorContext(ctx, "Error saving assignment", logging.DeviceIDKey, deviceID, "error", err)
+		}
+		return nil, err
+	}
+	return assignment, nil
+}
+
+func (s *DeviceService) release(ctx context.Context, assignment *model.Assignment, note string) error {
+	now := time.Now()
+	assignment.CheckedInAt = &now
+	if note != "" {
+		if assignment.Note != "" {
+			assignment.Note += "\n"
+		}
+		assignment.Note += note
+	}
+	if err := s.Assignments.Save(ctx, assignment); err != nil {
+		s.Logger.ErrorContext(ctx, "Error saving assignment", logging.DeviceIDKey, assignment.DeviceID, "error", err)
+		return err
+	}
+	return nil
+}
+
+func validateCheckOut(request *model.CheckOutRequest, now time.Time) error {
+	if strings.TrimSpace(request.Assignee) == "" {
+		return fmt.Errorf("%w: assignee is required", model.ErrInvalidAssignment)
+	}
+	if request.DueAt != nil && !request.DueAt.After(now) {
+		return fmt.Errorf("%w: due date must be in the future", model.ErrInvalidAssignment)
+	}
+	return nil
+}
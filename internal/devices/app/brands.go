@@ -0,0 +1,300 @@
+package app
+
+import (
+	"context"
+	"devices_crud/internal/auth"
+	"devices_crud/internal/devices/model"
+	"devices_crud/internal/logging"
+	"fmt"
+	"sort"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// SaveBrand creates a catalog brand or replaces the brand of the same name.
+// Every spelling, the name, aliases and the names of models, must be unique,
+// and no spelling may belong to another brand.
+func (s *DeviceService) SaveBrand(ctx context.Context, brand *model.Brand) (*model.Brand, error) {
+	ctx, span := s.startSpan(ctx, "SaveBrand", attribute.String("brand.name", brand.Name))
+	defer span.End()
+
+	if err := s.authorize(ctx, auth.PermissionDevicesAdmin); err != nil {
+		return nil, err
+	}
+	if s.Brands == nil {
+		return nil, fmt.Errorf("%w: brands are not supported", model.ErrInvalidBrand)
+	}
+
+	brand.Name = model.CleanBrand(brand.Name)
+	if model.BrandKey(brand.Name) == "" {
+		return nil, fmt.Errorf("%w: name must contain a letter or a digit", model.ErrInvalidBrand)
+	}
+	aliases, err := spellings(brand.Name, brand.Aliases)
+	if err != nil {
+		return nil, err
+	}
+	brand.Aliases = aliases
+	models := map[string]string{}
+	for i := range brand.Models {
+		deviceModel := &brand.Models[i]
+		deviceModel.Name = model.CleanBrand(deviceModel.Name)
+		if deviceModel.Aliases, err = spellings(deviceModel.Name, deviceModel.Aliases); err != nil {
+			return nil, err
+		}
+		for _, spelling := range append([]string{deviceModel.Name}, deviceModel.Aliases...) {
+			if other, ok := models[model.BrandKey(spelling)]; ok {
+				return nil, fmt.Errorf("%w: %q is already a spelling of model %s", model.ErrInvalidBrand, spelling, other)
+			}
+			models[model.BrandKey(spelling)] = deviceModel.Name
+		}
+	}
+
+	brands, err := s.Brands.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	brand.CreatedAt = time.Now()
+	created := true
+	for _, other := range brands {
+		if other.Name == brand.Name {
+			brand.CreatedAt = other.CreatedAt
+			created = false
+			continue
+		}
+		for _, spelling := range append([]string{brand.Name}, brand.Aliases...) {
+			if other.Matches(spelling) {
+				return nil, fmt.Errorf("%w: %q is already a spelling of %s", model.ErrInvalidBrand, spelling, other.Name)
+			}
+		}
+	}
+
+	if err := s.Brands.Save(ctx, brand); err != nil {
+		s.Logger.ErrorContext(ctx, "Error saving brand", "brand", brand.Name, "error", err)
+		return nil, err
+	}
+
+	s.Logger.InfoContext(ctx, "Brand saved", "brand", brand.Name, "created", created)
+	return brand, nil
+}
+
+// spellings cleans aliases, dropping those which are spelled like name or an
+// earlier alias.
+func spellings(name string, aliases []string) ([]string, error) {
+	if model.BrandKey(name) == "" {
+		return nil, fmt.Errorf("%w: names must contain a letter or a digit", model.ErrInvalidBrand)
+	}
+	seen := map[string]bool{model.BrandKey(name): true}
+	cleaned := []string{}
+	for _, alias := range aliases {
+		alias = model.CleanBrand(alias)
+		key := model.BrandKey(alias)
+		if key == "" {
+			return nil, fmt.Errorf("%w: alias %q must contain a letter or a digit", model.ErrInvalidBrand, alias)
+		}
+		if !seen[key] {
+			seen[key] = true
+			cleaned = append(cleaned, alias)
+		}
+	}
+	return cleaned, nil
+}
+
+// GetBrand returns the brand of that canonical name, nil when there is none.
+func (s *DeviceService) GetBrand(ctx context.Context, name string) (*model.Brand, error) {
+	ctx, span := s.startSpan(ctx, "GetBrand", attribute.String("brand.name", name))
+	defer span.End()
+
+	if err := s.authorize(ctx, auth.PermissionDevicesRead); err != nil {
+		return nil, err
+	}
+	if s.Brands == nil {
+		return nil, nil
+	}
+	return s.Brands.FindByName(ctx, name)
+}
+
+// ListBrands returns the catalog ordered by name.
+func (s *DeviceService) ListBrands(ctx context.Context) ([]model.Brand, error) {
+	ctx, span := s.startSpan(ctx, "ListBrands")
+	defer span.End()
+
+	if err := s.authorize(ctx, auth.PermissionDevicesRead); err != nil {
+		return nil, err
+	}
+	if s.Brands == nil {
+		return []model.Brand{}, nil
+	}
+
+	brands, err := s.Brands.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(brands, func(i, j int) bool { return brands[i].Name < brands[j].Name })
+	return brands, nil
+}
+
+// DeleteBrand removes a brand from the catalog. Devices keep their brand, new
+// spellings of it are no longer normalized.
+func (s *DeviceService) DeleteBrand(ctx context.Context, name string) error {
+	ctx, span := s.startSpan(ctx, "DeleteBrand", attribute.String("brand.name", name))
+	defer span.End()
+
+	if err := s.authorize(ctx, auth.PermissionDevicesAdmin); err != nil {
+		return err
+	}
+	if s.Brands == nil {
+		return nil
+	}
+
+	if err := s.Brands.Delete(ctx, name); err != nil {
+		s.Logger.ErrorContext(ctx, "Error deleting brand", "brand", name, "error", err)
+		return err
+	}
+	s.Logger.InfoContext(ctx, "Brand deleted", "brand", name)
+	return nil
+}
+
+// ResolveBrand returns the brand name is a spelling of, nil when the catalog
+// has none.
+func (s *DeviceService) ResolveBrand(ctx context.Context, name string) (*model.Brand, error) {
+	ctx, span := s.startSpan(ctx, "ResolveBrand")
+	defer span.End()
+
+	if err := s.authorize(ctx, auth.PermissionDevicesRead); err != nil {
+		return nil, err
+	}
+	return s.resolveBrand(ctx, name)
+}
+
+func (s *DeviceService) resolveBrand(ctx context.Context, name string) (*model.Brand, error) {
+	if s.Brands == nil {
+		return nil, nil
+	}
+	brands, err := s.Brands.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return findBrand(brands, name), nil
+}
+
+func findBrand(brands []model.Brand, name string) *model.Brand {
+	for i := range brands {
+		if brands[i].Matches(name) {
+			return &brands[i]
+		}
+	}
+	return nil
+}
+
+// normalizeBrand returns the canonical name of the brand, or the cleaned name
+// when it is not in the catalog.
+func (s *DeviceService) normalizeBrand(ctx context.Context, name string) (string, error) {
+	brand, err := s.resolveBrand(ctx, name)
+	if err != nil {
+		return "", err
+	}
+	if brand == nil {
+		return model.CleanBrand(name), nil
+	}
+	return brand.Name, nil
+}
+
+// MigrateBrands renames the brands of stored devices to their canonical names,
+// merging the spellings written before the catalog knew them.
+func (s *DeviceService) MigrateBrands(ctx context.Context, request *model.MigrateBrandsRequest) (*model.BrandMigration, error) {
+	ctx, span := s.startSpan(ctx, "MigrateBrands", attribute.Bool("dry_run", request.DryRun))
+	defer span.End()
+
+	if err := s.authorize(ctx, auth.PermissionDevicesAdmin); err != nil {
+		return nil, err
+	}
+	if request.AddMissing && s.Brands == nil {
+		return nil, fmt.Errorf("%w: brands are not supported", model.ErrInvalidBrand)
+	}
+
+	var brands []model.Brand
+	if s.Brands != nil {
+		var err error
+		if brands, err = s.Brands.FindAll(ctx); err != nil {
+			return nil, err
+		}
+	}
+	devices, err := s.DevicesRepository.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	migration := &model.BrandMigration{DryRun: request.DryRun, Changes: []model.BrandChange{}, Added: []model.Brand{}}
+	targets := make(map[string]string, len(devices))
+	// Spellings of each uncatalogued brand and how many devices use them.
+	missing := map[string]map[string]int{}
+	for _, device := range devices {
+		if brand := findBrand(brands, device.DeviceBrand); brand != nil {
+			targets[device.ID] = brand.Name
+			continue
+		}
+		cleaned := model.CleanBrand(device.DeviceBrand)
+		targets[device.ID] = cleaned
+		if key := model.BrandKey(cleaned); request.AddMissing && key != "" {
+			if missing[key] == nil {
+				missing[key] = map[string]int{}
+			}
+			missing[key][cleaned]++
+		}
+	}
+	canonical := map[string]string{}
+	for key, counts := range missing {
+		canonical[key] = mostCommon(counts)
+		migration.Added = append(migration.Added, model.Brand{Name: canonical[key], CreatedAt: time.Now()})
+	}
+	sort.Slice(migration.Added, func(i, j int) bool { return migration.Added[i].Name < migration.Added[j].Name })
+
+	for _, device := range devices {
+		to := targets[device.ID]
+		if name, ok := canonical[model.BrandKey(to)]; ok {
+			to = name
+		}
+		if to != device.DeviceBrand {
+			migration.Changes = append(migration.Changes, model.BrandChange{DeviceID: device.ID, From: device.DeviceBrand, To: to})
+		}
+	}
+	sort.Slice(migration.Changes, func(i, j int) bool { return migration.Changes[i].DeviceID < migration.Changes[j].DeviceID })
+	span.SetAttributes(attribute.Int("changes", len(migration.Changes)), attribute.Int("added", len(migration.Added)))
+	if request.DryRun {
+		return migration, nil
+	}
+
+	for i := range migration.Added {
+		if err := s.Brands.Save(ctx, &migration.Added[i]); err != nil {
+			s.Logger.ErrorContext(ctx, "Error saving brand", "brand", migration.Added[i].Name, "error", err)
+			return nil, err
+		}
+	}
+	for _, change := range migration.Changes {
+		to := change.To
+		if _, err := s.DevicesRepository.Patch(ctx, &model.PatchDeviceRequest{ID: change.DeviceID, DeviceBrand: &to}); err != nil {
+			s.Logger.ErrorContext(ctx, "Error migrating brand", logging.DeviceIDKey, change.DeviceID, "error", err)
+			return nil, err
+		}
+	}
+
+	s.Logger.InfoContext(ctx, "Brands migrated", "changes", len(migration.Changes), "added", len(migration.Added))
+	return migration, nil
+}
+
+// mostCommon returns the spelling used most. Ties go to the shortest, which
+// is the one with the fewest suffixes, and then to the first in order.
+func mostCommon(counts map[string]int) string {
+	best := ""
+	for spelling, count := range counts {
+		switch {
+		case best == "" || count > counts[best]:
+			best = spelling
+		case count < counts[best]:
+		case len(spelling) < len(best) || (len(spelling) == len(best) && spelling < best):
+			best = spelling
+		}
+	}
+	return best
+}
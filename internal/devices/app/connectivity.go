@@ -0,0 +1,125 @@
+package app
+
+import (
+	"context"
+	"devices_crud/internal/auth"
+	"devices_crud/internal/devices/model"
+	"devices_crud/internal/logging"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// ConnectivityHook is called when a device goes online or offline, see
+// ConnectivityMonitor.
+type ConnectivityHook func(ctx context.Context, change model.ConnectivityChange)
+
+// OnConnectivityChange registers hook for connectivity changes. Hooks run in
+// registration order after the change is logged, they are meant to be
+// registered while wiring the service.
+func (s *DeviceService) OnConnectivityChange(hook ConnectivityHook) {
+	s.connectivityHooks = append(s.connectivityHooks, hook)
+}
+
+// Heartbeat records that the device is alive and returns its connectivity. It
+// returns nil when there is no device with that ID.
+func (s *DeviceService) Heartbeat(ctx context.Context, id string) (*model.ConnectivityStatus, error) {
+	ctx, span := s.startSpan(ctx, "Heartbeat", attribute.String("device.id", id))
+	defer span.End()
+
+	if err := s.authorize(ctx, auth.PermissionDevicesWrite); err != nil {
+		return nil, err
+	}
+	device, err := s.DevicesRepository.FindByID(ctx, &id)
+	if err != nil || device == nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	before := s.Connectivity.Of(device, now)
+	updated, err := s.DevicesRepository.TouchLastSeen(ctx, id, now)
+	if err != nil {
+		s.Logger.ErrorContext(ctx, "Error recording heartbeat", logging.DeviceIDKey, id, "error", err)
+		return nil, err
+	}
+	if updated == nil {
+		return nil, nil
+	}
+	s.Logger.DebugContext(ctx, "Heartbeat received", logging.DeviceIDKey, id)
+
+	s.observeConnectivity(ctx, updated, before, model.ConnectivityOnline, now)
+	return s.connectivityStatus(updated, now), nil
+}
+
+// DeviceConnectivity returns whether the device is online, nil when there is
+// no device with that ID.
+func (s *DeviceService) DeviceConnectivity(ctx context.Context, id string) (*model.ConnectivityStatus, error) {
+	ctx, span := s.startSpan(ctx, "DeviceConnectivity", attribute.String("device.id", id))
+	defer span.End()
+
+	if err := s.authorize(ctx, auth.PermissionDevicesRead); err != nil {
+		return nil, err
+	}
+	device, err := s.DevicesRepository.FindByID(ctx, &id)
+	if err != nil || device == nil {
+		return nil, err
+	}
+	return s.connectivityStatus(device, time.Now()), nil
+}
+
+func (s *DeviceService) connectivityStatus(device *model.Device, at time.Time) *model.ConnectivityStatus {
+	return &model.ConnectivityStatus{
+		DeviceID:   device.ID,
+		Status:     s.Connectivity.Of(device, at),
+		LastSeenAt: device.LastSeenAt,
+		Timeout:    s.Connectivity.TimeoutOf(device).String(),
+		At:         at,
+	}
+}
+
+// observeConnectivity records the connectivity of the device and emits a
+// change when it differs from the one recorded before, or from previous for
+// devices this process did not see yet.
+func (s *DeviceService) observeConnectivity(ctx context.Context, device *model.Device, previous model.Connectivity, current model.Connectivity, at time.Time) *model.ConnectivityChange {
+	s.connectivityMutex.Lock()
+	if s.connectivity == nil {
+		s.connectivity = map[string]model.Connectivity{}
+	}
+	if recorded, ok := s.connectivity[device.ID]; ok {
+		previous = recorded
+	}
+	s.connectivity[device.ID] = current
+	s.connectivityMutex.Unlock()
+
+	if previous == current {
+		return nil
+	}
+	change := model.ConnectivityChange{Device: *device, From: previous, To: current, At: at}
+	if current == model.ConnectivityOffline {
+		s.Logger.WarnContext(ctx, "Device offline", logging.DeviceIDKey, device.ID, logging.TenantKey, device.TenantID, "last_seen_at", device.LastSeenAt)
+	} else {
+		s.Logger.InfoContext(ctx, "Device online", logging.DeviceIDKey, device.ID, logging.TenantKey, device.TenantID, "from", string(previous))
+	}
+	for _, hook := range s.connectivityHooks {
+		hook(ctx, change)
+	}
+	return &change
+}
+
+// forgetConnectivity drops the recorded connectivity of a deleted device.
+func (s *DeviceService) forgetConnectivity(id string) {
+	s.connectivityMutex.Lock()
+	defer s.connectivityMutex.Unlock()
+	delete(s.connectivity, id)
+}
+
+// filterConnectivity keeps the devices in the given state, in place.
+func (s *DeviceService) filterConnectivity(devices []model.Device, connectivity model.Connectivity, at time.Time) []model.Device {
+	kept := devices[:0]
+	for i := range devices {
+		if s.Connectivity.Of(&devices[i], at) == connectivity {
+			kept = append(kept, devices[i])
+		}
+	}
+	return kept
+}
@@ -0,0 +1,72 @@
+package app
+
+import (
+	"context"
+	"devices_crud/internal/devices/model"
+	"devices_crud/internal/health"
+	"devices_crud/internal/tenancy"
+	"time"
+)
+
+// ConnectivityMonitor emits a model.ConnectivityChange through
+// DeviceService.OnConnectivityChange hooks, and the log, when a device of any
+// tenant misses its heartbeat timeout. Devices going back online are
+// notified by DeviceService.Heartbeat. The first check only records the
+// state of every device, so a restarted monitor does not notify devices which
+// went offline while it was down.
+type ConnectivityMonitor struct {
+	service  *DeviceService
+	interval time.Duration
+	worker   *health.Worker
+}
+
+// NewConnectivityMonitor checks every interval. The worker, if any, beats
+// after every successful check.
+func NewConnectivityMonitor(service *DeviceService, interval time.Duration, worker *health.Worker) *ConnectivityMonitor {
+	return &ConnectivityMonitor{service: service, interval: interval, worker: worker}
+}
+
+// Run checks right away and then every interval until ctx is done.
+func (m *ConnectivityMonitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := m.Check(ctx, time.Now()); err != nil {
+			m.service.Logger.ErrorContext(ctx, "Error checking device connectivity", "error", err)
+		} else if m.worker != nil {
+			m.worker.Beat()
+		}
+
+		select {
+		case <-ctx.Done():
+			if m.worker != nil {
+				m.worker.Stop()
+			}
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Check emits the changes of the devices whose connectivity changed since the
+// previous check and returns them.
+func (m *ConnectivityMonitor) Check(ctx context.Context, at time.Time) ([]model.ConnectivityChange, error) {
+	ctx = tenancy.WithAllTenants(ctx)
+	devices, err := m.service.DevicesRepository.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make([]model.ConnectivityChange, 0)
+	for i := range devices {
+		if devices[i].LastSeenAt == nil {
+			continue
+		}
+		current := m.service.Connectivity.Of(&devices[i], at)
+		if change := m.service.observeConnectivity(ctx, &devices[i], current, current, at); change != nil {
+			changes = append(changes, *change)
+		}
+	}
+	return changes, nil
+}
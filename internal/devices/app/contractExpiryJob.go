@@ -0,0 +1,118 @@
+package app
+
+import (
+	"context"
+	"devices_crud/internal/devices/model"
+	"devices_crud/internal/health"
+	"devices_crud/internal/tenancy"
+	"sort"
+	"time"
+)
+
+// DefaultExpiryWindows are the days before the end of a contract its expiry
+// is notified at.
+var DefaultExpiryWindows = []int{90, 30, 7}
+
+// ContractExpiryJob emits a model.ContractExpiryEvent through
+// DeviceService.OnContractExpiry hooks, and the log, when a contract of any
+// tenant which was not renewed enters each of its windows, and once more when
+// it ends. What was notified is kept in memory, so a restarted job notifies
+// the current window of every contract again.
+type ContractExpiryJob struct {
+	service  *DeviceService
+	interval time.Duration
+	windows  []int
+	worker   *health.Worker
+
+	lastCheck time.Time
+	// notified maps contract IDs to the smallest window notified.
+	notified map[string]int
+}
+
+// NewContractExpiryJob checks every interval. The worker, if any, beats after
+// every successful check.
+func NewContractExpiryJob(service *DeviceService, interval time.Duration, windows []int, worker *health.Worker) *ContractExpiryJob {
+	if len(windows) == 0 {
+		windows = DefaultExpiryWindows
+	}
+	windows = append([]int(nil), windows...)
+	sort.Ints(windows)
+	return &ContractExpiryJob{service: service, interval: interval, windows: windows, worker: worker, notified: map[string]int{}}
+}
+
+// Run checks right away and then every interval until ctx is done.
+func (j *ContractExpiryJob) Run(ctx context.Context) {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := j.Check(ctx, time.Now()); err != nil {
+			j.service.Logger.ErrorContext(ctx, "Error checking contract expiries", "error", err)
+		} else if j.worker != nil {
+			j.worker.Beat()
+		}
+
+		select {
+		case <-ctx.Done():
+			if j.worker != nil {
+				j.worker.Stop()
+			}
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Check emits the events due at the given time and returns them.
+func (j *ContractExpiryJob) Check(ctx context.Context, at time.Time) ([]model.ContractExpiryEvent, error) {
+	if j.service.Contracts == nil {
+		return nil, nil
+	}
+	ctx = tenancy.WithAllTenants(ctx)
+
+	// Contracts which ended since the previous check are notified as expired.
+	from := j.lastCheck
+	if from.IsZero() {
+		from = at.Add(-j.interval)
+	}
+	expiring, err := j.service.expiringContracts(ctx, from, at.AddDate(0, 0, j.windows[len(j.windows)-1]).Add(time.Nanosecond), at)
+	if err != nil {
+		return nil, err
+	}
+	j.lastCheck = at
+
+	events := make([]model.ContractExpiryEvent, 0)
+	for _, contract := range expiring {
+		if contract.Renewed {
+			continue
+		}
+		window, ok := j.window(&contract.Contract, at)
+		if !ok {
+			continue
+		}
+		if notified, ok := j.notified[contract.Contract.ID]; ok && notified <= window {
+			continue
+		}
+		j.notified[contract.Contract.ID] = window
+
+		event := model.ContractExpiryEvent{Contract: contract.Contract, Window: window, DaysLeft: contract.DaysLeft, At: at}
+		j.service.emitContractExpiry(ctx, event)
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// window returns the smallest window the contract is in at the given time, 0
+// once it ended.
+func (j *ContractExpiryJob) window(contract *model.Contract, at time.Time) (int, bool) {
+	left := contract.EndsAt.Sub(at)
+	if left <= 0 {
+		return 0, true
+	}
+	for _, window := range j.windows {
+		if left <= time.Duration(window)*24*time.Hour {
+			return window, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,220 @@
+package app
+
+import (
+	"context"
+	"devices_crud/internal/auth"
+	"devices_crud/internal/devices/model"
+	"devices_crud/internal/logging"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// ContractExpiryHook is called for every contract expiry event, see
+// ContractExpiryJob.
+type ContractExpiryHook func(ctx context.Context, event model.ContractExpiryEvent)
+
+// OnContractExpiry registers hook for contract expiry events. Hooks run in
+// registration order after the event is logged, they are meant to be
+// registered while wiring the service.
+func (s *DeviceService) OnContractExpiry(hook ContractExpiryHook) {
+	s.expiryHooks = append(s.expiryHooks, hook)
+}
+
+// AddContract links a warranty or support contract to a device. It returns
+// nil when there is no device with that ID.
+func (s *DeviceService) AddContract(ctx context.Context, deviceID string, request *model.ContractRequest) (*model.Contract, error) {
+	ctx, span := s.startSpan(ctx, "AddContract", attribute.String("device.id", deviceID), attribute.String("contract.kind", string(request.Kind)))
+	defer span.End()
+
+	if err := s.authorize(ctx, auth.PermissionDevicesWrite); err != nil {
+		return nil, err
+	}
+	if s.Contracts == nil {
+		return nil, fmt.Errorf("%w: contracts are not supported", model.ErrInvalidContract)
+	}
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+
+	device, err := s.DevicesRepository.FindByID(ctx, &deviceID)
+	if err != nil || device == nil {
+		return nil, err
+	}
+	contract := &model.Contract{
+		ID:        uuid.New().String(),
+		DeviceID:  deviceID,
+		Kind:      request.Kind,
+		Provider:  request.Provider,
+		Reference: request.Reference,
+		StartsAt:  request.StartsAt,
+		EndsAt:    request.EndsAt,
+		Coverage:  request.Coverage,
+		CreatedAt: time.Now(),
+		CreatedBy: actor(ctx),
+	}
+	if err := s.Contracts.Save(ctx, contract); err != nil {
+		s.Logger.ErrorContext(ctx, "Error adding contract", logging.DeviceIDKey, deviceID, "error", err)
+		return nil, err
+	}
+	s.Logger.InfoContext(ctx, "Contract added", logging.DeviceIDKey, deviceID, "contract_id", contract.ID, "provider", contract.Provider)
+	return contract, nil
+}
+
+// DeleteContract removes a contract of the device and returns it, nil when
+// the device has no contract with that ID.
+func (s *DeviceService) DeleteContract(ctx context.Context, deviceID string, contractID string) (*model.Contract, error) {
+	ctx, span := s.startSpan(ctx, "DeleteContract", attribute.String("device.id", deviceID), attribute.String("contract.id", contractID))
+	defer span.End()
+
+	if err := s.authorize(ctx, auth.PermissionDevicesWrite); err != nil {
+		return nil, err
+	}
+	if s.Contracts == nil {
+		return nil, nil
+	}
+	contract, err := s.Contracts.FindByID(ctx, contractID)
+	if err != nil || contract == nil || contract.DeviceID != deviceID {
+		return nil, err
+	}
+
+	if err := s.Contracts.Delete(ctx, contractID); err != nil {
+		s.Logger.ErrorContext(ctx, "Error deleting contract", logging.DeviceIDKey, deviceID, "error", err)
+		return nil, err
+	}
+	s.Logger.InfoContext(ctx, "Contract deleted", logging.DeviceIDKey, deviceID, "contract_id", contractID)
+	return contract, nil
+}
+
+// DeviceContracts returns the contracts of a device, the earliest start first.
+func (s *DeviceService) DeviceContracts(ctx context.Context, deviceID string) ([]model.Contract, error) {
+	ctx, span := s.startSpan(ctx, "DeviceContracts", attribute.String("device.id", deviceID))
+	defer span.End()
+
+	if err := s.authorize(ctx, auth.PermissionDevicesRead); err != nil {
+		return nil, err
+	}
+	if s.Contracts == nil {
+		return []model.Contract{}, nil
+	}
+	contracts, err := s.Contracts.FindByDevice(ctx, deviceID)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(contracts, func(i, j int) bool {
+		if contracts[i].StartsAt.Equal(contracts[j].StartsAt) {
+			return contracts[i].ID < contracts[j].ID
+		}
+		return contracts[i].StartsAt.Before(contracts[j].StartsAt)
+	})
+	return contracts, nil
+}
+
+// ExpiringContracts returns the contracts ending within days of at, only of
+// the kind if one is given, the soonest first.
+func (s *DeviceService) ExpiringContracts(ctx context.Context, at time.Time, days int, kind model.ContractKind) ([]model.ExpiringContract, error) {
+	ctx, span := s.startSpan(ctx, "ExpiringContracts", attribute.Int("days", days))
+	defer span.End()
+
+	if err := s.authorize(ctx, auth.PermissionDevicesRead); err != nil {
+		return nil, err
+	}
+	if days < 0 {
+		return nil, fmt.Errorf("%w: days cannot be negative", model.ErrInvalidContract)
+	}
+	if kind != "" && kind != model.ContractWarranty && kind != model.ContractSupport {
+		return nil, fmt.Errorf("%w: unknown kind %q, expected one of %v", model.ErrInvalidContract, kind, model.ContractKinds)
+	}
+	if s.Contracts == nil {
+		return []model.ExpiringContract{}, nil
+	}
+
+	expiring, err := s.expiringContracts(ctx, at, at.AddDate(0, 0, days), at)
+	if err != nil {
+		return nil, err
+	}
+	filtered := make([]model.ExpiringContract, 0, len(expiring))
+	for _, contract := range expiring {
+		if kind == "" || contract.Contract.Kind == kind {
+			filtered = append(filtered, contract)
+		}
+	}
+	span.SetAttributes(attribute.Int("contracts.expiring", len(filtered)))
+	return filtered, nil
+}
+
+// expiringContracts returns the contracts ending in [from, to), the soonest
+// first, with their days left at at and whether they were renewed.
+func (s *DeviceService) expiringContracts(ctx context.Context, from time.Time, to time.Time, at time.Time) ([]model.ExpiringContract, error) {
+	contracts, err := s.Contracts.FindEndingBetween(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(contracts, func(i, j int) bool {
+		if contracts[i].EndsAt.Equal(contracts[j].EndsAt) {
+			return contracts[i].ID < contracts[j].ID
+		}
+		return contracts[i].EndsAt.Before(contracts[j].EndsAt)
+	})
+
+	expiring := make([]model.ExpiringContract, len(contracts))
+	others := map[string][]model.Contract{}
+	for i, contract := range contracts {
+		if _, ok := others[contract.DeviceID]; !ok {
+			if others[contract.DeviceID], err = s.Contracts.FindByDevice(ctx, contract.DeviceID); err != nil {
+				return nil, err
+			}
+		}
+		expiring[i] = model.ExpiringContract{
+			Contract: contract,
+			DaysLeft: contract.DaysLeft(at),
+			Renewed:  renewed(&contract, others[contract.DeviceID]),
+		}
+	}
+	return expiring, nil
+}
+
+// emitContractExpiry logs the event and passes it to the hooks.
+func (s *DeviceService) emitContractExpiry(ctx context.Context, event model.ContractExpiryEvent) {
+	message := "Contract expiring"
+	if event.Window == 0 {
+		message = "Contract expired"
+	}
+	s.Logger.WarnContext(ctx, message, logging.DeviceIDKey, event.Contract.DeviceID, logging.TenantKey, event.Contract.TenantID,
+		"contract_id", event.Contract.ID, "kind", event.Contract.Kind, "provider", event.Contract.Provider,
+		"ends_at", event.Contract.EndsAt, "days_left", event.DaysLeft)
+	for _, hook := range s.expiryHooks {
+		hook(ctx, event)
+	}
+}
+
+// dropContracts removes the contracts of a deleted device.
+func (s *DeviceService) dropContracts(ctx context.Context, deviceID string) error {
+	if s.Contracts == nil {
+		return nil
+	}
+	contracts, err := s.Contracts.FindByDevice(ctx, deviceID)
+	if err != nil {
+		return err
+	}
+	for _, contract := range contracts {
+		if err := s.Contracts.Delete(ctx, contract.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// renewed reports whether another of the contracts of the same kind takes over
+// when the contract ends.
+func renewed(contract *model.Contract, contracts []model.Contract) bool {
+	for _, other := range contracts {
+		if other.ID != contract.ID && other.Kind == contract.Kind && !other.StartsAt.After(contract.EndsAt) && other.EndsAt.After(contract.EndsAt) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,162 @@
+package app
+
+import (
+	"context"
+	"devices_crud/internal/auth"
+	"devices_crud/internal/devices/model"
+	"devices_crud/internal/filter"
+	"devices_crud/internal/jsonschema"
+	"fmt"
+	"regexp"
+	"sort"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+var deviceTypeName = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// SaveDeviceType creates a device type or replaces the type of the same name.
+// Devices of a replaced type are validated against the new schema on their
+// next write.
+func (s *DeviceService) SaveDeviceType(ctx context.Context, deviceType *model.DeviceType) (*model.DeviceType, error) {
+	ctx, span := s.startSpan(ctx, "SaveDeviceType", attribute.String("device_type.name", deviceType.Name))
+	defer span.End()
+
+	if err := s.authorize(ctx, auth.PermissionDevicesAdmin); err != nil {
+		return nil, err
+	}
+	if s.DeviceTypes == nil {
+		return nil, fmt.Errorf("%w: device types are not supported", model.ErrInvalidDeviceType)
+	}
+
+	if !deviceTypeName.MatchString(deviceType.Name) {
+		return nil, fmt.Errorf("%w: name must be 1 to 64 letters, digits, '-' or '_'", model.ErrInvalidDeviceType)
+	}
+	if len(deviceType.Schema) == 0 {
+		deviceType.Schema = []byte("{}")
+	}
+	if _, err := jsonschema.Compile(deviceType.Schema); err != nil {
+		return nil, fmt.Errorf("%w: %w", model.ErrInvalidDeviceType, err)
+	}
+
+	existing, err := s.DeviceTypes.FindByName(ctx, deviceType.Name)
+	if err != nil {
+		return nil, err
+	}
+	deviceType.CreatedAt = time.Now()
+	if existing != nil {
+		deviceType.CreatedAt = existing.CreatedAt
+	}
+
+	if err := s.DeviceTypes.Save(ctx, deviceType); err != nil {
+		s.Logger.ErrorContext(ctx, "Error saving device type", "device_type", deviceType.Name, "error", err)
+		return nil, err
+	}
+
+	s.Logger.InfoContext(ctx, "Device type saved", "device_type", deviceType.Name, "created", existing == nil)
+	return deviceType, nil
+}
+
+// GetDeviceType returns nil when there is no type of that name.
+func (s *DeviceService) GetDeviceType(ctx context.Context, name string) (*model.DeviceType, error) {
+	ctx, span := s.startSpan(ctx, "GetDeviceType", attribute.String("device_type.name", name))
+	defer span.End()
+
+	if err := s.authorize(ctx, auth.PermissionDevicesRead); err != nil {
+		return nil, err
+	}
+	if s.DeviceTypes == nil {
+		return nil, nil
+	}
+	return s.DeviceTypes.FindByName(ctx, name)
+}
+
+// ListDeviceTypes returns all device types ordered by name.
+func (s *DeviceService) ListDeviceTypes(ctx context.Context) ([]model.DeviceType, error) {
+	ctx, span := s.startSpan(ctx, "ListDeviceTypes")
+	defer span.End()
+
+	if err := s.authorize(ctx, auth.PermissionDevicesRead); err != nil {
+		return nil, err
+	}
+	if s.DeviceTypes == nil {
+		return []model.DeviceType{}, nil
+	}
+
+	deviceTypes, err := s.DeviceTypes.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(deviceTypes, func(i, j int) bool { return deviceTypes[i].Name < deviceTypes[j].Name })
+	return deviceTypes, nil
+}
+
+// DeleteDeviceType deletes a device type no device uses anymore.
+func (s *DeviceService) DeleteDeviceType(ctx context.Context, name string) error {
+	ctx, span := s.startSpan(ctx, "DeleteDeviceType", attribute.String("device_type.name", name))
+	defer span.End()
+
+	if err := s.authorize(ctx, auth.PermissionDevicesAdmin); err != nil {
+		return err
+	}
+	if s.DeviceTypes == nil {
+		return nil
+	}
+
+	devices, err := s.DevicesRepository.FindByFilter(ctx, &filter.Comparison{
+		Field: "type",
+		Op:    filter.Equal,
+		Value: filter.StringValue(name),
+	})
+	if err != nil {
+		return err
+	}
+	if len(devices) > 0 {
+		return fmt.Errorf("%w: %d devices are of type %s", model.ErrDeviceTypeInUse, len(devices), name)
+	}
+
+	if err := s.DeviceTypes.Delete(ctx, name); err != nil {
+		s.Logger.ErrorContext(ctx, "Error deleting device type", "device_type", name, "error", err)
+		return err
+	}
+
+	s.Logger.InfoContext(ctx, "Device type deleted", "device_type", name)
+	return nil
+}
+
+// validateAttributes normalizes attributes and checks them against the schema
+// of the device type. Untyped devices accept any attributes.
+func (s *DeviceService) validateAttributes(ctx context.Context, typeName string, attributes model.Attributes) (model.Attributes, error) {
+	normalized, err := attributes.Normalize()
+	if err != nil {
+		return nil, err
+	}
+	if typeName == "" {
+		return normalized, nil
+	}
+
+	var deviceType *model.DeviceType
+	if s.DeviceTypes != nil {
+		if deviceType, err = s.DeviceTypes.FindByName(ctx, typeName); err != nil {
+			return nil, err
+		}
+	}
+	if deviceType == nil {
+		return nil, fmt.Errorf("%w: %s", model.ErrUnknownDeviceType, typeName)
+	}
+
+	schema, err := jsonschema.Compile(deviceType.Schema)
+	if err != nil {
+		return nil, err
+	}
+	document := map[string]interface{}(normalized)
+	if document == nil {
+		document = map[string]interface{}{}
+	}
+	if err := schema.Validate(document); err != nil {
+		s.Logger.InfoContext(ctx, "Invalid attributes", "device_type", typeName, "error", err)
+		return nil, fmt.Errorf("%w: %w", model.ErrInvalidAttributes, err)
+	}
+	return normalized, nil
+}
@@ -0,0 +1,182 @@
+package app
+
+import (
+	"context"
+	"devices_crud/internal/auth"
+	"devices_crud/internal/devices/model"
+	"devices_crud/internal/logging"
+	"fmt"
+	"sort"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// DuplicateCandidates pairs the devices scoring at least threshold, or
+// model.DefaultDuplicateThreshold when zero, the most likely duplicates
+// first.
+func (s *DeviceService) DuplicateCandidates(ctx context.Context, threshold float64) ([]model.DuplicateCandidate, error) {
+	ctx, span := s.startSpan(ctx, "DuplicateCandidates", attribute.Float64("threshold", threshold))
+	defer span.End()
+
+	if err := s.authorize(ctx, auth.PermissionDevicesRead); err != nil {
+		return nil, err
+	}
+	threshold, err := duplicateThreshold(threshold)
+	if err != nil {
+		return nil, err
+	}
+	devices, err := s.DevicesRepository.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sortByCreation(devices)
+
+	candidates := []model.DuplicateCandidate{}
+	for i := range devices {
+		for j := i + 1; j < len(devices); j++ {
+			if candidate, ok := s.duplicateCandidate(&devices[i], &devices[j], threshold); ok {
+				candidates = append(candidates, candidate)
+			}
+		}
+	}
+	sortCandidates(candidates)
+	span.SetAttributes(attribute.Int("candidates", len(candidates)))
+	return candidates, nil
+}
+
+// DeviceDuplicates returns the devices which may be the same as the device,
+// see DuplicateCandidates. It returns nil when there is no device with that
+// ID.
+func (s *DeviceService) DeviceDuplicates(ctx context.Context, id string, threshold float64) ([]model.DuplicateCandidate, error) {
+	ctx, span := s.startSpan(ctx, "DeviceDuplicates", attribute.String("device.id", id), attribute.Float64("threshold", threshold))
+	defer span.End()
+
+	if err := s.authorize(ctx, auth.PermissionDevicesRead); err != nil {
+		return nil, err
+	}
+	threshold, err := duplicateThreshold(threshold)
+	if err != nil {
+		return nil, err
+	}
+	device, err := s.DevicesRepository.FindByID(ctx, &id)
+	if err != nil || device == nil {
+		return nil, err
+	}
+	devices, err := s.DevicesRepository.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := []model.DuplicateCandidate{}
+	for i := range devices {
+		if devices[i].ID == id {
+			continue
+		}
+		if candidate, ok := s.duplicateCandidate(device, &devices[i], threshold); ok {
+			candidates = append(candidates, candidate)
+		}
+	}
+	sortCandidates(candidates)
+	return candidates, nil
+}
+
+// DeviceRedirect returns where the ID of a device merged into another one
+// leads, nil when it is not the ID of a merged device.
+func (s *DeviceService) DeviceRedirect(ctx context.Context, id string) (*model.DeviceRedirect, error) {
+	ctx, span := s.startSpan(ctx, "DeviceRedirect", attribute.String("device.id", id))
+	defer span.End()
+
+	if err := s.authorize(ctx, auth.PermissionDevicesRead); err != nil {
+		return nil, err
+	}
+	if s.Redirects == nil {
+		return nil, nil
+	}
+	return s.Redirects.Find(ctx, id)
+}
+
+func (s *DeviceService) duplicateCandidate(device *model.Device, other *model.Device, threshold float64) (model.DuplicateCandidate, bool) {
+	for _, key := range s.UniqueKeys {
+		if key.Shared(device, other) {
+			return model.DuplicateCandidate{Device: *device, Duplicate: *other, Score: 1, Key: key.String()}, true
+		}
+	}
+	score := model.DuplicateScore(device, other)
+	if score < threshold {
+		return model.DuplicateCandidate{}, false
+	}
+	return model.DuplicateCandidate{Device: *device, Duplicate: *other, Score: score}, true
+}
+
+// checkUnique fails when another device than the ones of except shares a
+// uniqueness key with the device.
+func (s *DeviceService) checkUnique(ctx context.Context, device *model.Device, except ...string) error {
+	if len(s.UniqueKeys) == 0 {
+		return nil
+	}
+	devices, err := s.DevicesRepository.FindAll(ctx)
+	if err != nil {
+		return err
+	}
+	excluded := map[string]bool{device.ID: true}
+	for _, id := range except {
+		excluded[id] = true
+	}
+	for _, key := range s.UniqueKeys {
+		for i := range devices {
+			if !excluded[devices[i].ID] && key.Shared(device, &devices[i]) {
+				s.Logger.InfoContext(ctx, "Duplicate device rejected", logging.DeviceIDKey, devices[i].ID, "key", key.String())
+				return fmt.Errorf("%w: device %s has the same %s", model.ErrDuplicateDevice, devices[i].ID, key)
+			}
+		}
+	}
+	return nil
+}
+
+// dropRedirects removes the redirects leading to a deleted device.
+func (s *DeviceService) dropRedirects(ctx context.Context, deviceID string) error {
+	if s.Redirects == nil {
+		return nil
+	}
+	redirects, err := s.Redirects.FindByTarget(ctx, deviceID)
+	if err != nil {
+		return err
+	}
+	for _, redirect := range redirects {
+		if err := s.Redirects.Delete(ctx, redirect.FromID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func duplicateThreshold(threshold float64) (float64, error) {
+	if threshold == 0 {
+		return model.DefaultDuplicateThreshold, nil
+	}
+	if threshold < 0 || threshold > 1 {
+		return 0, fmt.Errorf("%w: threshold must be between 0 and 1", model.ErrInvalidThreshold)
+	}
+	return threshold, nil
+}
+
+func sortByCreation(devices []model.Device) {
+	sort.Slice(devices, func(i, j int) bool {
+		if devices[i].CreatedAt.Equal(devices[j].CreatedAt) {
+			return devices[i].ID < devices[j].ID
+		}
+		return devices[i].CreatedAt.Before(devices[j].CreatedAt)
+	})
+}
+
+func sortCandidates(candidates []model.DuplicateCandidate) {
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].Score != candidates[j].Score {
+			return candidates[i].Score > candidates[j].Score
+		}
+		if candidates[i].Device.ID != candidates[j].Device.ID {
+			return candidates[i].Device.ID < candidates[j].Device.ID
+		}
+		return candidates[i].Duplicate.ID < candidates[j].Duplicate.ID
+	})
+}
@@ -0,0 +1,111 @@
+package app
+
+import (
+	"context"
+	"devices_crud/internal/auth"
+	"devices_crud/internal/devices/model"
+	"devices_crud/internal/logging"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// TransitionHook is called after a device changed status, with the updated
+// device.
+type TransitionHook func(ctx context.Context, device *model.Device, change model.StatusChange)
+
+type transitionHook struct {
+	from model.Status
+	to   model.Status
+	hook TransitionHook
+}
+
+// OnTransition registers hook for transitions from one status to the other. An
+// empty from or to matches any status. Hooks run in registration order once the
+// transition is stored, they are meant to be registered while wiring the
+// service.
+func (s *DeviceService) OnTransition(from model.Status, to model.Status, hook TransitionHook) {
+	s.transitionHooks = append(s.transitionHooks, transitionHook{from: from, to: to, hook: hook})
+}
+
+// TransitionDevice moves a device to another status if model.Transitions allows
+// it, recording when, why and by whom. Every transition needs a reason. It
+// returns the updated device, or nil when there is no device with that ID.
+func (s *DeviceService) TransitionDevice(ctx context.Context, id string, request *model.TransitionRequest) (*model.Device, error) {
+	ctx, span := s.startSpan(ctx, "TransitionDevice", attribute.String("device.id", id), attribute.String("device.status", string(request.Status)))
+	defer span.End()
+
+	if err := s.authorize(ctx, auth.PermissionDevicesWrite); err != nil {
+		return nil, err
+	}
+	to, err := model.ParseStatus(string(request.Status))
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(request.Reason) == "" {
+		return nil, fmt.Errorf("%w: a reason is required", model.ErrInvalidTransition)
+	}
+
+	device, err := s.DevicesRepository.FindByID(ctx, &id)
+	if err != nil || device == nil {
+		return nil, err
+	}
+	if !model.CanTransition(device.Status, to) {
+		return nil, fmt.Errorf("%w: %s to %s, allowed: %v", model.ErrIllegalTransition, device.Status, to, model.Transitions[device.Status])
+	}
+
+	change := model.StatusChange{
+		From:   device.Status,
+		To:     to,
+		Reason: request.Reason,
+		At:     time.Now(),
+		Actor:  actor(ctx),
+	}
+	// The repository checks the status again, in case the device changed status
+	// since it was read.
+	updated, err := s.DevicesRepository.UpdateStatus(ctx, id, change)
+	if err != nil {
+		if !errors.Is(err, model.ErrIllegalTransition) {
+			s.Logger.ErrorContext(ctx, "Error changing device status", logging.DeviceIDKey, id, "error", err)
+		}
+		return nil, err
+	}
+	if updated == nil {
+		return nil, nil
+	}
+
+	s.Logger.InfoContext(ctx, "Device status changed", logging.DeviceIDKey, id, "from", string(change.From), "to", string(change.To))
+	for _, registered := range s.transitionHooks {
+		if (registered.from == "" || registered.from == change.From) && (registered.to == "" || registered.to == change.To) {
+			registered.hook(ctx, updated, change)
+		}
+	}
+	return updated, nil
+}
+
+// initialStatus returns the status a new device starts in, one of
+// model.InitialStatuses, and its first history entry.
+func initialStatus(ctx context.Context, status model.Status, at time.Time) (model.Status, []model.StatusChange, error) {
+	if status == "" {
+		status = model.StatusOrdered
+	}
+	status, err := model.ParseStatus(string(status))
+	if err != nil {
+		return "", nil, err
+	}
+	if !slices.Contains(model.InitialStatuses, status) {
+		return "", nil, fmt.Errorf("%w: devices cannot be created %s, expected one of %v", model.ErrInvalidStatus, status, model.InitialStatuses)
+	}
+	return status, []model.StatusChange{{To: status, At: at, Actor: actor(ctx)}}, nil
+}
+
+func actor(ctx context.Context) string {
+	if principal, ok := auth.PrincipalFromContext(ctx); ok {
+		return principal.Subject
+	}
+	return ""
+}
@@ -0,0 +1,384 @@
+package app
+
+import (
+	"context"
+	"devices_crud/internal/auth"
+	"devices_crud/internal/devices/model"
+	"devices_crud/internal/filter"
+	"devices_crud/internal/logging"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// CreateLocation adds a location to the tree, below ParentID or as a site when
+// it is empty. Names are unique among siblings, so that paths are unambiguous.
+func (s *DeviceService) CreateLocation(ctx context.Context, request *model.NewLocationRequest) (*model.Location, error) {
+	ctx, span := s.startSpan(ctx, "CreateLocation")
+	defer span.End()
+
+	if err := s.authorize(ctx, auth.PermissionDevicesWrite); err != nil {
+		return nil, err
+	}
+	if s.Locations == nil {
+		return nil, fmt.Errorf("%w: locations are not supported", model.ErrInvalidLocation)
+	}
+	if err := model.ValidateLocationName(request.Name); err != nil {
+		return nil, err
+	}
+	if request.Kind.Depth() < 0 {
+		return nil, fmt.Errorf("%w: kind must be one of %v", model.ErrInvalidLocation, model.LocationKinds)
+	}
+
+	location := &model.Location{
+		ID:        uuid.New().String(),
+		Name:      strings.TrimSpace(request.Name),
+		Kind:      request.Kind,
+		ParentID:  request.ParentID,
+		CreatedAt: time.Now(),
+	}
+	if err := s.checkPlacement(ctx, location); err != nil {
+		return nil, err
+	}
+
+	if err := s.Locations.Save(ctx, location); err != nil {
+		s.Logger.ErrorContext(ctx, "Error saving location", "location_id", location.ID, "error", err)
+		return nil, err
+	}
+	s.Logger.InfoContext(ctx, "Location created", "location_id", location.ID)
+	return location, nil
+}
+
+func (s *DeviceService) GetLocation(ctx context.Context, id string) (*model.Location, error) {
+	ctx, span := s.startSpan(ctx, "GetLocation", attribute.String("location.id", id))
+	defer span.End()
+
+	if err := s.authorize(ctx, auth.PermissionDevicesRead); err != nil {
+		return nil, err
+	}
+	if s.Locations == nil {
+		return nil, nil
+	}
+	return s.Locations.FindByID(ctx, id)
+}
+
+// ListLocations returns the locations directly below parentID sorted by name,
+// the sites when it is empty.
+func (s *DeviceService) ListLocations(ctx context.Context, parentID string) ([]model.Location, error) {
+	ctx, span := s.startSpan(ctx, "ListLocations")
+	defer span.End()
+
+	if err := s.authorize(ctx, auth.PermissionDevicesRead); err != nil {
+		return nil, err
+	}
+	if s.Locations == nil {
+		return []model.Location{}, nil
+	}
+
+	locations, err := s.Locations.FindChildren(ctx, parentID)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(locations, func(i, j int) bool { return locations[i].Name < locations[j].Name })
+	return locations, nil
+}
+
+// LocationPath returns the chain of locations from the site down to the
+// location, nil when there is no location with that ID.
+func (s *DeviceService) LocationPath(ctx context.Context, id string) (model.LocationPath, error) {
+	ctx, span := s.startSpan(ctx, "LocationPath", attribute.String("location.id", id))
+	defer span.End()
+
+	if err := s.authorize(ctx, auth.PermissionDevicesRead); err != nil {
+		return nil, err
+	}
+	if s.Locations == nil {
+		return nil, nil
+	}
+	return s.path(ctx, id)
+}
+
+// FindLocationByPath resolves a path of names such as "HQ/Building A/Room 101",
+// returning nil when any of them does not exist.
+func (s *DeviceService) FindLocationByPath(ctx context.Context, path string) (*model.Location, error) {
+	ctx, span := s.startSpan(ctx, "FindLocationByPath")
+	defer span.End()
+
+	if err := s.authorize(ctx, auth.PermissionDevicesRead); err != nil {
+		return nil, err
+	}
+	if s.Locations == nil {
+		return nil, nil
+	}
+
+	var current *model.Location
+	parentID := ""
+	for _, name := range strings.Split(strings.Trim(path, model.PathSeparator), model.PathSeparator) {
+		children, err := s.Locations.FindChildren(ctx, parentID)
+		if err != nil {
+			return nil, err
+		}
+		current = nil
+		for i := range children {
+			if children[i].Name == strings.TrimSpace(name) {
+				current = &children[i]
+				break
+			}
+		}
+		if current == nil {
+			return nil, nil
+		}
+		parentID = current.ID
+	}
+	return current, nil
+}
+
+// MoveLocation moves a location and its subtree below another parent. It
+// returns the moved location, or nil when there is no location with that ID.
+func (s *DeviceService) MoveLocation(ctx context.Context, id string, request *model.MoveLocationRequest) (*model.Location, error) {
+	ctx, span := s.startSpan(ctx, "MoveLocation", attribute.String("location.id", id))
+	defer span.End()
+
+	if err := s.authorize(ctx, auth.PermissionDevicesWrite); err != nil {
+		return nil, err
+	}
+	if s.Locations == nil {
+		return nil, nil
+	}
+	location, err := s.Locations.FindByID(ctx, id)
+	if err != nil || location == nil {
+		return nil, err
+	}
+
+	if request.ParentID != "" {
+		ancestors, err := s.path(ctx, request.ParentID)
+		if err != nil {
+			return nil, err
+		}
+		for _, ancestor := range ancestors {
+			if ancestor.ID == id {
+				return nil, fmt.Errorf("%w: %s cannot be moved below itself", model.ErrLocationCycle, location.Name)
+			}
+		}
+	}
+
+	from := location.ParentID
+	location.ParentID = request.ParentID
+	if err := s.checkPlacement(ctx, location); err != nil {
+		return nil, err
+	}
+	if err := s.Locations.Save(ctx, location); err != nil {
+		s.Logger.ErrorContext(ctx, "Error moving location", "location_id", id, "error", err)
+		return nil, err
+	}
+	s.Logger.InfoContext(ctx, "Location moved", "location_id", id, "from", from, "to", location.ParentID)
+	return location, nil
+}
+
+// DeleteLocation removes a location which has neither children nor devices.
+func (s *DeviceService) DeleteLocation(ctx context.Context, id string) error {
+	ctx, span := s.startSpan(ctx, "DeleteLocation", attribute.String("location.id", id))
+	defer span.End()
+
+	if err := s.authorize(ctx, auth.PermissionDevicesDelete); err != nil {
+		return err
+	}
+	if s.Locations == nil {
+		return nil
+	}
+
+	children, err := s.Locations.FindChildren(ctx, id)
+	if err != nil {
+		return err
+	}
+	if len(children) > 0 {
+		return fmt.Errorf("%w: %d locations are below it", model.ErrLocationNotEmpty, len(children))
+	}
+	devices, err := s.DevicesRepository.FindByFilter(ctx, inLocations([]string{id}))
+	if err != nil {
+		return err
+	}
+	if len(devices) > 0 {
+		return fmt.Errorf("%w: %d devices are in it", model.ErrLocationNotEmpty, len(devices))
+	}
+
+	if err := s.Locations.Delete(ctx, id); err != nil {
+		s.Logger.ErrorContext(ctx, "Error deleting location", "location_id", id, "error", err)
+		return err
+	}
+	s.Logger.InfoContext(ctx, "Location deleted", "location_id", id)
+	return nil
+}
+
+// LocationDevices returns the devices in a location, and with recursive also
+// those anywhere below it, ordered by creation time.
+func (s *DeviceService) LocationDevices(ctx context.Context, id string, recursive bool) ([]model.Device, error) {
+	ctx, span := s.startSpan(ctx, "LocationDevices", attribute.String("location.id", id), attribute.Bool("recursive", recursive))
+	defer span.End()
+
+	if err := s.authorize(ctx, auth.PermissionDevicesRead); err != nil {
+		return nil, err
+	}
+
+	ids := []string{id}
+	if recursive && s.Locations != nil {
+		// Breadth-first, ids grows while it is walked.
+		for i := 0; i < len(ids); i++ {
+			children, err := s.Locations.FindChildren(ctx, ids[i])
+			if err != nil {
+				return nil, err
+			}
+			for _, child := range children {
+				ids = append(ids, child.ID)
+			}
+		}
+	}
+	span.SetAttributes(attribute.Int("locations", len(ids)))
+
+	devices, err := s.DevicesRepository.FindByFilter(ctx, inLocations(ids))
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(devices, func(i, j int) bool {
+		if devices[i].CreatedAt.Equal(devices[j].CreatedAt) {
+			return devices[i].ID < devices[j].ID
+		}
+		return devices[i].CreatedAt.Before(devices[j].CreatedAt)
+	})
+	return devices, nil
+}
+
+// MoveDevice places a device in another location, recording the move. It
+// returns the updated device, or nil when there is no device with that ID.
+func (s *DeviceService) MoveDevice(ctx context.Context, id string, request *model.MoveDeviceRequest) (*model.Device, error) {
+	ctx, span := s.startSpan(ctx, "MoveDevice", attribute.String("device.id", id), attribute.String("location.id", request.LocationID))
+	defer span.End()
+
+	if err := s.authorize(ctx, auth.PermissionDevicesWrite); err != nil {
+		return nil, err
+	}
+	if err := s.checkLocation(ctx, request.LocationID); err != nil {
+		return nil, err
+	}
+	device, err := s.DevicesRepository.FindByID(ctx, &id)
+	if err != nil || device == nil || device.LocationID == request.LocationID {
+		return device, err
+	}
+
+	change := model.LocationChange{
+		From:   device.LocationID,
+		To:     request.LocationID,
+		Reason: request.Reason,
+		At:     time.Now(),
+		Actor:  actor(ctx),
+	}
+	// The repository checks the location again, in case the device was moved
+	// since it was read.
+	moved, err := s.DevicesRepository.UpdateLocation(ctx, id, change)
+	if err != nil {
+		if !errors.Is(err, model.ErrDeviceMoved) {
+			s.Logger.ErrorContext(ctx, "Error moving device", logging.DeviceIDKey, id, "error", err)
+		}
+		return nil, err
+	}
+	if moved == nil {
+		return nil, nil
+	}
+	s.Logger.InfoContext(ctx, "Device moved", logging.DeviceIDKey, id, "from", change.From, "to", change.To)
+	return moved, nil
+}
+
+// initialLocation checks the location a new device is placed in and returns
+// its first history entry.
+func (s *DeviceService) initialLocation(ctx context.Context, locationID string, at time.Time) ([]model.LocationChange, error) {
+	if locationID == "" {
+		return nil, nil
+	}
+	if err := s.checkLocation(ctx, locationID); err != nil {
+		return nil, err
+	}
+	return []model.LocationChange{{To: locationID, At: at, Actor: actor(ctx)}}, nil
+}
+
+// checkLocation fails unless the location is empty or exists.
+func (s *DeviceService) checkLocation(ctx context.Context, id string) error {
+	if id == "" {
+		return nil
+	}
+	if s.Locations == nil {
+		return fmt.Errorf("%w: %s", model.ErrUnknownLocation, id)
+	}
+	location, err := s.Locations.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if location == nil {
+		return fmt.Errorf("%w: %s", model.ErrUnknownLocation, id)
+	}
+	return nil
+}
+
+// checkPlacement checks that the parent of location exists, is of a higher
+// kind and has no other child with the same name.
+func (s *DeviceService) checkPlacement(ctx context.Context, location *model.Location) error {
+	if location.ParentID != "" {
+		parent, err := s.Locations.FindByID(ctx, location.ParentID)
+		if err != nil {
+			return err
+		}
+		if parent == nil {
+			return fmt.Errorf("%w: parent %s", model.ErrUnknownLocation, location.ParentID)
+		}
+		if location.Kind.Depth() <= parent.Kind.Depth() {
+			return fmt.Errorf("%w: a %s cannot be placed in a %s", model.ErrInvalidLocation, location.Kind, parent.Kind)
+		}
+	}
+
+	siblings, err := s.Locations.FindChildren(ctx, location.ParentID)
+	if err != nil {
+		return err
+	}
+	for _, sibling := range siblings {
+		if sibling.ID != location.ID && sibling.Name == location.Name {
+			return fmt.Errorf("%w: a location named %q already exists there", model.ErrInvalidLocation, location.Name)
+		}
+	}
+	return nil
+}
+
+// path walks up from the location to its site. Kinds get strictly higher on
+// the way up, which bounds the walk even if the stored tree were corrupt.
+func (s *DeviceService) path(ctx context.Context, id string) (model.LocationPath, error) {
+	var path model.LocationPath
+	for id != "" && len(path) <= len(model.LocationKinds) {
+		location, err := s.Locations.FindByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if location == nil {
+			break
+		}
+		path = append(model.LocationPath{*location}, path...)
+		id = location.ParentID
+	}
+	return path, nil
+}
+
+// inLocations is a filter matching the devices in any of the locations.
+func inLocations(ids []string) filter.Expr {
+	var expr filter.Expr
+	for _, id := range ids {
+		comparison := &filter.Comparison{Field: "locationId", Op: filter.Equal, Value: filter.StringValue(id)}
+		if expr == nil {
+			expr = comparison
+		} else {
+			expr = &filter.Binary{Op: filter.Or, Left: expr, Right: comparison}
+		}
+	}
+	return expr
+}
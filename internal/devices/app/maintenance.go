@@ -0,0 +1,352 @@
+package app
+
+import (
+	"context"
+	"devices_crud/internal/auth"
+	"devices_crud/internal/devices/model"
+	"devices_crud/internal/logging"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// CreateMaintenancePlan schedules periodic maintenance of a device. It returns
+// nil when there is no device with that ID.
+func (s *DeviceService) CreateMaintenancePlan(ctx context.Context, deviceID string, request *model.NewMaintenancePlanRequest) (*model.MaintenancePlan, error) {
+	ctx, span := s.startSpan(ctx, "CreateMaintenancePlan", attribute.String("device.id", deviceID))
+	defer span.End()
+
+	if err := s.authorize(ctx, auth.PermissionDevicesWrite); err != nil {
+		return nil, err
+	}
+	if s.Maintenance == nil {
+		return nil, fmt.Errorf("%w: maintenance is not supported", model.ErrInvalidMaintenancePlan)
+	}
+
+	now := time.Now()
+	plan := &model.MaintenancePlan{
+		ID:          uuid.New().String(),
+		DeviceID:    deviceID,
+		Name:        strings.TrimSpace(request.Name),
+		Description: request.Description,
+		Kind:        request.Kind,
+		Every:       request.Every,
+		Unit:        request.Unit,
+		CreatedAt:   now,
+		CreatedBy:   actor(ctx),
+	}
+	if err := plan.Validate(); err != nil {
+		return nil, err
+	}
+	plan.StartsAt = plan.After(now, 1)
+	if request.StartsAt != nil {
+		plan.StartsAt = *request.StartsAt
+	}
+
+	device, err := s.DevicesRepository.FindByID(ctx, &deviceID)
+	if err != nil || device == nil {
+		return nil, err
+	}
+	if err := s.Maintenance.SavePlan(ctx, plan); err != nil {
+		s.Logger.ErrorContext(ctx, "Error creating maintenance plan", logging.DeviceIDKey, deviceID, "error", err)
+		return nil, err
+	}
+	plan.NextDueAt = &plan.StartsAt
+	s.Logger.InfoContext(ctx, "Maintenance plan created", logging.DeviceIDKey, deviceID, "plan_id", plan.ID, "kind", plan.Kind)
+	return plan, nil
+}
+
+// DeleteMaintenancePlan removes a plan of the device and returns it, nil when
+// the device has no plan with that ID. The service records of the plan are
+// kept.
+func (s *DeviceService) DeleteMaintenancePlan(ctx context.Context, deviceID string, planID string) (*model.MaintenancePlan, error) {
+	ctx, span := s.startSpan(ctx, "DeleteMaintenancePlan", attribute.String("device.id", deviceID), attribute.String("plan.id", planID))
+	defer span.End()
+
+	if err := s.authorize(ctx, auth.PermissionDevicesWrite); err != nil {
+		return nil, err
+	}
+	if s.Maintenance == nil {
+		return nil, nil
+	}
+	plan, err := s.Maintenance.FindPlan(ctx, planID)
+	if err != nil || plan == nil || plan.DeviceID != deviceID {
+		return nil, err
+	}
+
+	if err := s.Maintenance.DeletePlan(ctx, planID); err != nil {
+		s.Logger.ErrorContext(ctx, "Error deleting maintenance plan", logging.DeviceIDKey, deviceID, "error", err)
+		return nil, err
+	}
+	s.Logger.InfoContext(ctx, "Maintenance plan deleted", logging.DeviceIDKey, deviceID, "plan_id", planID)
+	return plan, nil
+}
+
+// DeviceMaintenancePlans returns the plans of a device with when they are due
+// next, oldest first.
+func (s *DeviceService) DeviceMaintenancePlans(ctx context.Context, deviceID string) ([]model.MaintenancePlan, error) {
+	ctx, span := s.startSpan(ctx, "DeviceMaintenancePlans", attribute.String("device.id", deviceID))
+	defer span.End()
+
+	if err := s.authorize(ctx, auth.PermissionDevicesRead); err != nil {
+		return nil, err
+	}
+	if s.Maintenance == nil {
+		return []model.MaintenancePlan{}, nil
+	}
+	plans, err := s.Maintenance.FindPlansByDevice(ctx, deviceID)
+	if err != nil {
+		return nil, err
+	}
+	records, err := s.Maintenance.FindRecordsByDevice(ctx, deviceID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range plans {
+		nextDueAt := plans[i].NextDue(lastService(&plans[i], records))
+		plans[i].NextDueAt = &nextDueAt
+	}
+	sortPlans(plans)
+	return plans, nil
+}
+
+// RecordService records maintenance or a repair of a device. A record naming
+// a plan completes the task of the plan due next. It returns nil when there is
+// no device with that ID.
+func (s *DeviceService) RecordService(ctx context.Context, deviceID string, request *model.ServiceRecordRequest) (*model.ServiceRecord, error) {
+	ctx, span := s.startSpan(ctx, "RecordService", attribute.String("device.id", deviceID))
+	defer span.End()
+
+	if err := s.authorize(ctx, auth.PermissionDevicesWrite); err != nil {
+		return nil, err
+	}
+	if s.Maintenance == nil {
+		return nil, fmt.Errorf("%w: maintenance is not supported", model.ErrInvalidServiceRecord)
+	}
+
+	now := time.Now()
+	record := &model.ServiceRecord{
+		ID:          uuid.New().String(),
+		DeviceID:    deviceID,
+		Kind:        request.Kind,
+		PlanID:      request.PlanID,
+		PerformedAt: now,
+		PerformedBy: request.PerformedBy,
+		Cost:        request.Cost,
+		Currency:    strings.ToUpper(strings.TrimSpace(request.Currency)),
+		Notes:       request.Notes,
+		RecordedBy:  actor(ctx),
+	}
+	if record.Kind == "" {
+		record.Kind = model.ServiceMaintenance
+	}
+	if record.Kind != model.ServiceMaintenance && record.Kind != model.ServiceRepair {
+		return nil, fmt.Errorf("%w: unknown kind %q, expected one of %v", model.ErrInvalidServiceRecord, record.Kind, model.ServiceKinds)
+	}
+	if record.Cost < 0 {
+		return nil, fmt.Errorf("%w: cost cannot be negative", model.ErrInvalidServiceRecord)
+	}
+	if request.PerformedAt != nil {
+		if request.PerformedAt.After(now) {
+			return nil, fmt.Errorf("%w: performedAt is in the future", model.ErrInvalidServiceRecord)
+		}
+		record.PerformedAt = *request.PerformedAt
+	}
+
+	device, err := s.DevicesRepository.FindByID(ctx, &deviceID)
+	if err != nil || device == nil {
+		return nil, err
+	}
+	if record.PlanID != "" {
+		plan, err := s.Maintenance.FindPlan(ctx, record.PlanID)
+		if err != nil {
+			return nil, err
+		}
+		if plan == nil || plan.DeviceID != deviceID {
+			return nil, fmt.Errorf("%w: device has no plan %s", model.ErrInvalidServiceRecord, record.PlanID)
+		}
+		records, err := s.Maintenance.FindRecordsByDevice(ctx, deviceID)
+		if err != nil {
+			return nil, err
+		}
+		dueAt := plan.NextDue(lastService(plan, records))
+		record.DueAt = &dueAt
+	}
+
+	if err := s.Maintenance.SaveRecord(ctx, record); err != nil {
+		s.Logger.ErrorContext(ctx, "Error recording service", logging.DeviceIDKey, deviceID, "error", err)
+		return nil, err
+	}
+	s.Logger.InfoContext(ctx, "Service recorded", logging.DeviceIDKey, deviceID, "kind", record.Kind, "plan_id", record.PlanID)
+	return record, nil
+}
+
+// ServiceRecords returns the service history of a device, oldest first.
+func (s *DeviceService) ServiceRecords(ctx context.Context, deviceID string) ([]model.ServiceRecord, error) {
+	ctx, span := s.startSpan(ctx, "ServiceRecords", attribute.String("device.id", deviceID))
+	defer span.End()
+
+	if err := s.authorize(ctx, auth.PermissionDevicesRead); err != nil {
+		return nil, err
+	}
+	if s.Maintenance == nil {
+		return []model.ServiceRecord{}, nil
+	}
+	records, err := s.Maintenance.FindRecordsByDevice(ctx, deviceID)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(records, func(i, j int) bool {
+		if records[i].PerformedAt.Equal(records[j].PerformedAt) {
+			return records[i].ID < records[j].ID
+		}
+		return records[i].PerformedAt.Before(records[j].PerformedAt)
+	})
+	return records, nil
+}
+
+// DeviceMaintenanceTasks returns the tasks of the plans of a device due up to
+// until, overdue ones included, the earliest first.
+func (s *DeviceService) DeviceMaintenanceTasks(ctx context.Context, deviceID string, until time.Time) ([]model.MaintenanceTask, error) {
+	ctx, span := s.startSpan(ctx, "DeviceMaintenanceTasks", attribute.String("device.id", deviceID))
+	defer span.End()
+
+	return s.maintenanceTasks(ctx, until, time.Now(), func(ctx context.Context) ([]model.MaintenancePlan, error) {
+		return s.Maintenance.FindPlansByDevice(ctx, deviceID)
+	})
+}
+
+// UpcomingMaintenance returns the tasks of every device due up to until,
+// overdue ones included, the earliest first.
+func (s *DeviceService) UpcomingMaintenance(ctx context.Context, until time.Time) ([]model.MaintenanceTask, error) {
+	ctx, span := s.startSpan(ctx, "UpcomingMaintenance")
+	defer span.End()
+
+	return s.maintenanceTasks(ctx, until, time.Now(), func(ctx context.Context) ([]model.MaintenancePlan, error) {
+		return s.Maintenance.FindPlans(ctx)
+	})
+}
+
+// OverdueMaintenance returns the tasks of every device which were due before
+// at and not done, the longest overdue first.
+func (s *DeviceService) OverdueMaintenance(ctx context.Context, at time.Time) ([]model.MaintenanceTask, error) {
+	ctx, span := s.startSpan(ctx, "OverdueMaintenance")
+	defer span.End()
+
+	tasks, err := s.maintenanceTasks(ctx, at, at, func(ctx context.Context) ([]model.MaintenancePlan, error) {
+		return s.Maintenance.FindPlans(ctx)
+	})
+	if err != nil {
+		return nil, err
+	}
+	overdue := make([]model.MaintenanceTask, 0)
+	for _, task := range tasks {
+		if task.Overdue {
+			overdue = append(overdue, task)
+		}
+	}
+	span.SetAttributes(attribute.Int("maintenance.overdue", len(overdue)))
+	return overdue, nil
+}
+
+// maintenanceTasks schedules the plans found up to until. Tasks due before at
+// are overdue. Plans of devices which no longer exist are skipped.
+func (s *DeviceService) maintenanceTasks(ctx context.Context, until time.Time, at time.Time, find func(context.Context) ([]model.MaintenancePlan, error)) ([]model.MaintenanceTask, error) {
+	if err := s.authorize(ctx, auth.PermissionDevicesRead); err != nil {
+		return nil, err
+	}
+	tasks := make([]model.MaintenanceTask, 0)
+	if s.Maintenance == nil {
+		return tasks, nil
+	}
+
+	plans, err := find(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sortPlans(plans)
+	devices := map[string]*model.Device{}
+	records := map[string][]model.ServiceRecord{}
+	for i := range plans {
+		plan := &plans[i]
+		device, ok := devices[plan.DeviceID]
+		if !ok {
+			if device, err = s.DevicesRepository.FindByID(ctx, &plan.DeviceID); err != nil {
+				return nil, err
+			}
+			if records[plan.DeviceID], err = s.Maintenance.FindRecordsByDevice(ctx, plan.DeviceID); err != nil {
+				return nil, err
+			}
+			devices[plan.DeviceID] = device
+		}
+		if device == nil {
+			continue
+		}
+
+		for _, dueAt := range plan.Schedule(lastService(plan, records[plan.DeviceID]), until) {
+			tasks = append(tasks, model.MaintenanceTask{
+				PlanID:         plan.ID,
+				PlanName:       plan.Name,
+				DeviceID:       plan.DeviceID,
+				DueAt:          dueAt,
+				Overdue:        dueAt.Before(at),
+				DeviceInRepair: device.Status == model.StatusInRepair,
+			})
+		}
+	}
+	sort.SliceStable(tasks, func(i, j int) bool { return tasks[i].DueAt.Before(tasks[j].DueAt) })
+	return tasks, nil
+}
+
+// dropMaintenancePlans removes the plans of a deleted device. Its service
+// records are kept.
+func (s *DeviceService) dropMaintenancePlans(ctx context.Context, deviceID string) error {
+	if s.Maintenance == nil {
+		return nil
+	}
+	plans, err := s.Maintenance.FindPlansByDevice(ctx, deviceID)
+	if err != nil {
+		return err
+	}
+	for _, plan := range plans {
+		if err := s.Maintenance.DeletePlan(ctx, plan.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lastService returns the record of the latest task of the plan done, nil
+// when none was. Interval plans count from when the service was performed,
+// calendar plans from the due date it completed.
+func lastService(plan *model.MaintenancePlan, records []model.ServiceRecord) *model.ServiceRecord {
+	var last *model.ServiceRecord
+	for i := range records {
+		record := &records[i]
+		if record.PlanID != plan.ID {
+			continue
+		}
+		switch {
+		case last == nil:
+			last = record
+		case plan.Kind == model.MaintenanceInterval && record.PerformedAt.After(last.PerformedAt):
+			last = record
+		case plan.Kind == model.MaintenanceCalendar && record.DueAt != nil && (last.DueAt == nil || record.DueAt.After(*last.DueAt)):
+			last = record
+		}
+	}
+	return last
+}
+
+func sortPlans(plans []model.MaintenancePlan) {
+	sort.Slice(plans, func(i, j int) bool {
+		if plans[i].CreatedAt.Equal(plans[j].CreatedAt) {
+			return plans[i].ID < plans[j].ID
+		}
+		return plans[i].CreatedAt.Before(plans[j].CreatedAt)
+	})
+}
@@ -0,0 +1,365 @@
+package app
+
+import (
+	"context"
+	"devices_crud/internal/auth"
+	"devices_crud/internal/devices/model"
+	"devices_crud/internal/logging"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// MergeDevices merges the device request.SourceID into the device and
+// deletes it, leaving a redirect from its ID. The device keeps its own
+// fields and takes the ones it lacks, the attributes and the labels of the
+// source; their histories are combined. The relationships, assignments,
+// maintenance, contracts, readings and software of the source move to the
+// device, except relationships the model.RelationRules no longer allow, which
+// are dropped. Every check runs before the first write, so a merge is only
+// left halfway when the storage fails. It returns nil when there is no device
+// with that ID.
+func (s *DeviceService) MergeDevices(ctx context.Context, id string, request *model.MergeRequest) (*model.Device, error) {
+	ctx, span := s.startSpan(ctx, "MergeDevices", attribute.String("device.id", id), attribute.String("source.id", request.SourceID))
+	defer span.End()
+
+	if err := s.authorize(ctx, auth.PermissionDevicesWrite); err != nil {
+		return nil, err
+	}
+	if err := s.authorize(ctx, auth.PermissionDevicesDelete); err != nil {
+		return nil, err
+	}
+	if s.Redirects == nil {
+		return nil, fmt.Errorf("%w: merging is not supported", model.ErrInvalidMerge)
+	}
+	if request.SourceID == "" {
+		return nil, fmt.Errorf("%w: sourceId is required", model.ErrInvalidMerge)
+	}
+	if request.SourceID == id {
+		return nil, fmt.Errorf("%w: a device cannot be merged into itself", model.ErrInvalidMerge)
+	}
+
+	device, err := s.DevicesRepository.FindByID(ctx, &id)
+	if err != nil || device == nil {
+		return nil, err
+	}
+	source, err := s.DevicesRepository.FindByID(ctx, &request.SourceID)
+	if err != nil {
+		return nil, err
+	}
+	if source == nil {
+		return nil, fmt.Errorf("%w: device %s not found", model.ErrInvalidMerge, request.SourceID)
+	}
+
+	now := time.Now()
+	merged, err := s.mergedDevice(ctx, device, source, now)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkUnique(ctx, merged, source.ID); err != nil {
+		return nil, err
+	}
+
+	steps := []mergeStep{func(ctx context.Context) error {
+		_, err := s.DevicesRepository.Replace(ctx, merged)
+		return err
+	}}
+	planners := []func(context.Context, string, string) ([]mergeStep, error){
+		s.assignmentMoves,
+		s.relationshipMoves,
+		s.maintenanceMoves,
+		s.contractMoves,
+		s.readingMoves,
+		s.softwareMoves,
+		s.redirectMoves,
+	}
+	for _, plan := range planners {
+		moves, err := plan(ctx, source.ID, id)
+		if err != nil {
+			return nil, err
+		}
+		steps = append(steps, moves...)
+	}
+	redirect := &model.DeviceRedirect{FromID: source.ID, ToID: id, MergedAt: now, MergedBy: actor(ctx)}
+	steps = append(steps,
+		func(ctx context.Context) error { return s.Redirects.Save(ctx, redirect) },
+		func(ctx context.Context) error { return s.deleteDevice(ctx, source.ID) },
+	)
+
+	for _, step := range steps {
+		if err := step(ctx); err != nil {
+			s.Logger.ErrorContext(ctx, "Error merging devices", logging.DeviceIDKey, id, "source", source.ID, "error", err)
+			return nil, err
+		}
+	}
+
+	s.Logger.InfoContext(ctx, "Devices merged", logging.DeviceIDKey, id, "source", source.ID)
+	return merged, nil
+}
+
+// mergeStep is one write of a merge. The planners of MergeDevices read and
+// check what the source has and return the steps moving it to the device.
+type mergeStep func(context.Context) error
+
+// mergedDevice combines the source into a copy of the device, see
+// MergeDevices.
+func (s *DeviceService) mergedDevice(ctx context.Context, device *model.Device, source *model.Device, now time.Time) (*model.Device, error) {
+	merged := *device
+	if merged.Name == "" {
+		merged.Name = source.Name
+	}
+	if merged.DeviceBrand == "" {
+		merged.DeviceBrand = source.DeviceBrand
+	}
+	if merged.Type == "" {
+		merged.Type = source.Type
+	}
+	if source.CreatedAt.Before(merged.CreatedAt) {
+		merged.CreatedAt = source.CreatedAt
+	}
+	if merged.LastSeenAt == nil || (source.LastSeenAt != nil && source.LastSeenAt.After(*merged.LastSeenAt)) {
+		merged.LastSeenAt = source.LastSeenAt
+	}
+
+	attributes, err := s.validateAttributes(ctx, merged.Type, source.Attributes.Merge(device.Attributes))
+	if err != nil {
+		return nil, err
+	}
+	merged.Attributes = attributes
+	merged.Labels = map[string]string{}
+	for key, value := range source.Labels {
+		merged.Labels[key] = value
+	}
+	for key, value := range device.Labels {
+		merged.Labels[key] = value
+	}
+	if len(merged.Labels) == 0 {
+		merged.Labels = nil
+	}
+
+	reason := "merged with " + source.ID
+	merged.StatusHistory = append(append([]model.StatusChange{}, device.StatusHistory...), source.StatusHistory...)
+	sort.SliceStable(merged.StatusHistory, func(i, j int) bool {
+		return merged.StatusHistory[i].At.Before(merged.StatusHistory[j].At)
+	})
+	if last := len(merged.StatusHistory) - 1; last >= 0 && merged.StatusHistory[last].To != merged.Status {
+		merged.StatusHistory = append(merged.StatusHistory, model.StatusChange{
+			From: merged.StatusHistory[last].To, To: merged.Status, Reason: reason, At: now, Actor: actor(ctx),
+		})
+	}
+	merged.LocationHistory = append(append([]model.LocationChange{}, device.LocationHistory...), source.LocationHistory...)
+	sort.SliceStable(merged.LocationHistory, func(i, j int) bool {
+		return merged.LocationHistory[i].At.Before(merged.LocationHistory[j].At)
+	})
+	if last := len(merged.LocationHistory) - 1; last >= 0 && merged.LocationHistory[last].To != merged.LocationID {
+		merged.LocationHistory = append(merged.LocationHistory, model.LocationChange{
+			From: merged.LocationHistory[last].To, To: merged.LocationID, Reason: reason, At: now, Actor: actor(ctx),
+		})
+	}
+	return &merged, nil
+}
+
+// assignmentMoves hands the assignments of the source over to the device,
+// failing when both are checked out.
+func (s *DeviceService) assignmentMoves(ctx context.Context, sourceID string, id string) ([]mergeStep, error) {
+	if s.Assignments == nil {
+		return nil, nil
+	}
+	active, err := s.Assignments.FindActiveByDevice(ctx, sourceID)
+	if err != nil {
+		return nil, err
+	}
+	if active != nil {
+		current, err := s.Assignments.FindActiveByDevice(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if current != nil {
+			return nil, fmt.Errorf("%w: both devices are checked out", model.ErrInvalidMerge)
+		}
+	}
+	assignments, err := s.Assignments.FindByDevice(ctx, sourceID)
+	if err != nil {
+		return nil, err
+	}
+	steps := make([]mergeStep, len(assignments))
+	for i := range assignments {
+		assignment := assignments[i]
+		assignment.DeviceID = id
+		steps[i] = func(ctx context.Context) error { return s.Assignments.Save(ctx, &assignment) }
+	}
+	return steps, nil
+}
+
+// relationshipMoves links the device to whatever the source was linked to,
+// dropping the links between them and the links the rules of their type no
+// longer allow. Links are checked against the graph before the merge, leaving
+// out the link being moved and the links between the two devices.
+func (s *DeviceService) relationshipMoves(ctx context.Context, sourceID string, id string) ([]mergeStep, error) {
+	links, err := s.relationships(ctx, sourceID)
+	if err != nil {
+		return nil, err
+	}
+	between := []string{}
+	for _, link := range links {
+		if link.Other(sourceID) == id {
+			between = append(between, link.ID)
+		}
+	}
+
+	steps := []mergeStep{}
+	drop := func(linkID string) mergeStep {
+		return func(ctx context.Context) error { return s.Relationships.Delete(ctx, linkID) }
+	}
+	for _, linkID := range between {
+		steps = append(steps, drop(linkID))
+	}
+	for _, link := range links {
+		link := link
+		if link.Other(sourceID) == id {
+			continue
+		}
+		fromID, toID := link.FromID, link.ToID
+		if fromID == sourceID {
+			fromID = id
+		} else {
+			toID = id
+		}
+		if err := s.checkLink(ctx, fromID, toID, link.Type, append(between, link.ID)...); err != nil {
+			if !errors.Is(err, model.ErrRelationshipConflict) && !errors.Is(err, model.ErrRelationshipCycle) {
+				return nil, err
+			}
+			s.Logger.InfoContext(ctx, "Relationship dropped by merge", logging.DeviceIDKey, id, "relationship_id", link.ID, "error", err)
+			steps = append(steps, drop(link.ID))
+			continue
+		}
+		link.FromID, link.ToID = fromID, toID
+		steps = append(steps, func(ctx context.Context) error { return s.Relationships.Save(ctx, &link) })
+	}
+	return steps, nil
+}
+
+func (s *DeviceService) maintenanceMoves(ctx context.Context, sourceID string, id string) ([]mergeStep, error) {
+	if s.Maintenance == nil {
+		return nil, nil
+	}
+	plans, err := s.Maintenance.FindPlansByDevice(ctx, sourceID)
+	if err != nil {
+		return nil, err
+	}
+	records, err := s.Maintenance.FindRecordsByDevice(ctx, sourceID)
+	if err != nil {
+		return nil, err
+	}
+	steps := []mergeStep{}
+	for i := range plans {
+		plan := plans[i]
+		plan.DeviceID = id
+		steps = append(steps, func(ctx context.Context) error { return s.Maintenance.SavePlan(ctx, &plan) })
+	}
+	for i := range records {
+		record := records[i]
+		record.DeviceID = id
+		steps = append(steps, func(ctx context.Context) error { return s.Maintenance.SaveRecord(ctx, &record) })
+	}
+	return steps, nil
+}
+
+func (s *DeviceService) contractMoves(ctx context.Context, sourceID string, id string) ([]mergeStep, error) {
+	if s.Contracts == nil {
+		return nil, nil
+	}
+	contracts, err := s.Contracts.FindByDevice(ctx, sourceID)
+	if err != nil {
+		return nil, err
+	}
+	steps := make([]mergeStep, len(contracts))
+	for i := range contracts {
+		contract := contracts[i]
+		contract.DeviceID = id
+		steps[i] = func(ctx context.Context) error { return s.Contracts.Save(ctx, &contract) }
+	}
+	return steps, nil
+}
+
+// readingMoves copies the readings of the source to the device, at the
+// resolution they are still kept at. The source's are deleted with it.
+func (s *DeviceService) readingMoves(ctx context.Context, sourceID string, id string) ([]mergeStep, error) {
+	if s.Telemetry == nil {
+		return nil, nil
+	}
+	latest, err := s.Telemetry.Latest(ctx, sourceID)
+	if err != nil {
+		return nil, err
+	}
+	steps := make([]mergeStep, len(latest))
+	for i, reading := range latest {
+		readings, err := s.Telemetry.Range(ctx, sourceID, reading.Metric, time.Time{}, reading.At.Add(time.Nanosecond))
+		if err != nil {
+			return nil, err
+		}
+		if len(readings) == 0 {
+			readings = []model.Reading{reading}
+		}
+		steps[i] = func(ctx context.Context) error { return s.Telemetry.Append(ctx, id, readings) }
+	}
+	return steps, nil
+}
+
+// softwareMoves moves the software of the source to the device, keeping the
+// one installed last when both run the same.
+func (s *DeviceService) softwareMoves(ctx context.Context, sourceID string, id string) ([]mergeStep, error) {
+	if s.Software == nil {
+		return nil, nil
+	}
+	software, err := s.Software.FindSoftwareByDevice(ctx, sourceID)
+	if err != nil {
+		return nil, err
+	}
+	installed, err := s.Software.FindSoftwareByDevice(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	steps := []mergeStep{}
+	for i := range software {
+		moved := software[i]
+		replaced := false
+		for j := range installed {
+			if !installed[j].Is(moved.Kind, moved.Name) {
+				continue
+			}
+			if !moved.InstalledAt.After(installed[j].InstalledAt) {
+				replaced = true
+			} else {
+				softwareID := installed[j].ID
+				steps = append(steps, func(ctx context.Context) error { return s.Software.DeleteSoftware(ctx, softwareID) })
+			}
+			break
+		}
+		if replaced {
+			continue
+		}
+		moved.DeviceID = id
+		steps = append(steps, func(ctx context.Context) error { return s.Software.SaveSoftware(ctx, &moved) })
+	}
+	return steps, nil
+}
+
+// redirectMoves leads the IDs of devices merged into the source to the
+// device.
+func (s *DeviceService) redirectMoves(ctx context.Context, sourceID string, id string) ([]mergeStep, error) {
+	redirects, err := s.Redirects.FindByTarget(ctx, sourceID)
+	if err != nil {
+		return nil, err
+	}
+	steps := make([]mergeStep, len(redirects))
+	for i := range redirects {
+		redirect := redirects[i]
+		redirect.ToID = id
+		steps[i] = func(ctx context.Context) error { return s.Redirects.Save(ctx, &redirect) }
+	}
+	return steps, nil
+}
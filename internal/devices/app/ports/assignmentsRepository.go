@@ -0,0 +1,105 @@
+package ports
+
+import (
+	"context"
+	"devices_crud/internal/devices/model"
+	"devices_crud/internal/tenancy"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// AssignmentsRepository stores the assignments of devices to people, scoped to
+// the tenant of ctx like DevicesRepository.
+type AssignmentsRepository interface {
+	// Save creates the assignment or replaces the assignment with the same ID.
+	Save(ctx context.Context, assignment *model.Assignment) error
+	// CheckOut creates the active assignment unless its device has one
+	// already, failing with model.ErrDeviceAssigned then. The check and the
+	// write are atomic.
+	CheckOut(ctx context.Context, assignment *model.Assignment) error
+	// FindActiveByDevice returns the assignment of the device which has not been
+	// checked in yet, nil when the device is available.
+	FindActiveByDevice(ctx context.Context, deviceID string) (*model.Assignment, error)
+	FindByDevice(ctx context.Context, deviceID string) ([]model.Assignment, error)
+	FindByAssignee(ctx context.Context, assignee string) ([]model.Assignment, error)
+	FindActive(ctx context.Context) ([]model.Assignment, error)
+}
+
+var assignmentsContainer map[string]model.Assignment
+
+var assignmentsMutex sync.RWMutex
+
+type assignmentsRepositoryMock struct {
+}
+
+func NewAssignmentsRepositoryMock() AssignmentsRepository {
+	assignmentsMutex.Lock()
+	defer assignmentsMutex.Unlock()
+
+	assignmentsContainer = make(map[string]model.Assignment)
+	return &assignmentsRepositoryMock{}
+}
+
+func (r *assignmentsRepositoryMock) Save(ctx context.Context, assignment *model.Assignment) error {
+	assignmentsMutex.Lock()
+	defer assignmentsMutex.Unlock()
+
+	if !tenancy.AllTenants(ctx) || assignment.TenantID == "" {
+		assignment.TenantID = tenancy.TenantFromContext(ctx)
+	}
+	assignmentsContainer[assignment.ID] = *assignment
+	return nil
+}
+
+func (r *assignmentsRepositoryMock) CheckOut(ctx context.Context, assignment *model.Assignment) error {
+	assignmentsMutex.Lock()
+	defer assignmentsMutex.Unlock()
+
+	if !tenancy.AllTenants(ctx) || assignment.TenantID == "" {
+		assignment.TenantID = tenancy.TenantFromContext(ctx)
+	}
+	for _, existing := range assignmentsContainer {
+		if existing.DeviceID == assignment.DeviceID && existing.TenantID == assignment.TenantID && existing.Active() {
+			return fmt.Errorf("%w: checked out to %s since %s", model.ErrDeviceAssigned, existing.Assignee, existing.CheckedOutAt.Format(time.RFC3339))
+		}
+	}
+	assignmentsContainer[assignment.ID] = *assignment
+	return nil
+}
+
+func (r *assignmentsRepositoryMock) FindActiveByDevice(ctx context.Context, deviceID string) (*model.Assignment, error) {
+	for _, assignment := range r.find(ctx, func(a *model.Assignment) bool { return a.DeviceID == deviceID && a.Active() }) {
+		return &assignment, nil
+	}
+	return nil, nil
+}
+
+func (r *assignmentsRepositoryMock) FindByDevice(ctx context.Context, deviceID string) ([]model.Assignment, error) {
+	return r.find(ctx, func(a *model.Assignment) bool { return a.DeviceID == deviceID }), nil
+}
+
+func (r *assignmentsRepositoryMock) FindByAssignee(ctx context.Context, assignee string) ([]model.Assignment, error) {
+	return r.find(ctx, func(a *model.Assignment) bool { return a.Assignee == assignee }), nil
+}
+
+func (r *assignmentsRepositoryMock) FindActive(ctx context.Context) ([]model.Assignment, error) {
+	return r.find(ctx, (*model.Assignment).Active), nil
+}
+
+func (r *assignmentsRepositoryMock) find(ctx context.Context, matches func(*model.Assignment) bool) []model.Assignment {
+	assignmentsMutex.RLock()
+	defer assignmentsMutex.RUnlock()
+
+	assignments := make([]model.Assignment, 0)
+	for _, assignment := range assignmentsContainer {
+		if assignmentVisible(ctx, assignment) && matches(&assignment) {
+			assignments = append(assignments, assignment)
+		}
+	}
+	return assignments
+}
+
+func assignmentVisible(ctx context.Context, assignment model.Assignment) bool {
+	return tenancy.AllTenants(ctx) || assignment.TenantID == tenancy.TenantFromContext(ctx)
+}
@@ -0,0 +1,82 @@
+package ports
+
+import (
+	"context"
+	"devices_crud/internal/devices/model"
+	"devices_crud/internal/tenancy"
+	"sync"
+)
+
+// BrandsRepository stores the brand catalog, scoped to the tenant of ctx like
+// DevicesRepository. Brands are identified by their canonical name.
+type BrandsRepository interface {
+	// Save creates the brand or replaces the brand of the same name.
+	Save(ctx context.Context, brand *model.Brand) error
+	FindByName(ctx context.Context, name string) (*model.Brand, error)
+	FindAll(ctx context.Context) ([]model.Brand, error)
+	Delete(ctx context.Context, name string) error
+}
+
+// brandsContainer holds the brands of the mock, keyed by tenant and name.
+var brandsContainer map[brandKey]model.Brand
+
+var brandsMutex sync.RWMutex
+
+type brandKey struct {
+	tenant string
+	name   string
+}
+
+type brandsRepositoryMock struct {
+}
+
+func NewBrandsRepositoryMock() BrandsRepository {
+	brandsMutex.Lock()
+	defer brandsMutex.Unlock()
+
+	brandsContainer = make(map[brandKey]model.Brand)
+	return &brandsRepositoryMock{}
+}
+
+func (r *brandsRepositoryMock) Save(ctx context.Context, brand *model.Brand) error {
+	brandsMutex.Lock()
+	defer brandsMutex.Unlock()
+
+	if !tenancy.AllTenants(ctx) || brand.TenantID == "" {
+		brand.TenantID = tenancy.TenantFromContext(ctx)
+	}
+	brandsContainer[brandKey{brand.TenantID, brand.Name}] = *brand
+	return nil
+}
+
+func (r *brandsRepositoryMock) FindByName(ctx context.Context, name string) (*model.Brand, error) {
+	brandsMutex.RLock()
+	defer brandsMutex.RUnlock()
+
+	brand, ok := brandsContainer[brandKey{tenancy.TenantFromContext(ctx), name}]
+	if !ok {
+		return nil, nil
+	}
+	return &brand, nil
+}
+
+func (r *brandsRepositoryMock) FindAll(ctx context.Context) ([]model.Brand, error) {
+	brandsMutex.RLock()
+	defer brandsMutex.RUnlock()
+
+	brands := make([]model.Brand, 0)
+	for key, brand := range brandsContainer {
+		if tenancy.AllTenants(ctx) || key.tenant == tenancy.TenantFromContext(ctx) {
+			brands = append(brands, brand)
+		}
+	}
+	return brands, nil
+}
+
+func (r *brandsRepositoryMock) Delete(ctx context.Context, name string) error {
+	brandsMutex.Lock()
+	defer brandsMutex.Unlock()
+
+	delete(brandsContainer, brandKey{tenancy.TenantFromContext(ctx), name})
+	return nil
+}
@@ -0,0 +1,92 @@
+package ports
+
+import (
+	"context"
+	"devices_crud/internal/devices/model"
+	"devices_crud/internal/tenancy"
+	"sync"
+	"time"
+)
+
+// ContractsRepository stores the warranty and support contracts of devices,
+// scoped to the tenant of ctx like DevicesRepository.
+type ContractsRepository interface {
+	Save(ctx context.Context, contract *model.Contract) error
+	FindByID(ctx context.Context, id string) (*model.Contract, error)
+	FindByDevice(ctx context.Context, deviceID string) ([]model.Contract, error)
+	// FindEndingBetween returns the contracts ending in [from, to).
+	FindEndingBetween(ctx context.Context, from time.Time, to time.Time) ([]model.Contract, error)
+	Delete(ctx context.Context, id string) error
+}
+
+var contractsContainer map[string]model.Contract
+
+var contractsMutex sync.RWMutex
+
+type contractsRepositoryMock struct {
+}
+
+func NewContractsRepositoryMock() ContractsRepository {
+	contractsMutex.Lock()
+	defer contractsMutex.Unlock()
+
+	contractsContainer = make(map[string]model.Contract)
+	return &contractsRepositoryMock{}
+}
+
+func (r *contractsRepositoryMock) Save(ctx context.Context, contract *model.Contract) error {
+	contractsMutex.Lock()
+	defer contractsMutex.Unlock()
+
+	if !tenancy.AllTenants(ctx) || contract.TenantID == "" {
+		contract.TenantID = tenancy.TenantFromContext(ctx)
+	}
+	contractsContainer[contract.ID] = *contract
+	return nil
+}
+
+func (r *contractsRepositoryMock) FindByID(ctx context.Context, id string) (*model.Contract, error) {
+	contractsMutex.RLock()
+	defer contractsMutex.RUnlock()
+
+	contract, ok := contractsContainer[id]
+	if !ok || !contractVisible(ctx, contract) {
+		return nil, nil
+	}
+	return &contract, nil
+}
+
+func (r *contractsRepositoryMock) FindByDevice(ctx context.Context, deviceID string) ([]model.Contract, error) {
+	return r.find(ctx, func(c *model.Contract) bool { return c.DeviceID == deviceID }), nil
+}
+
+func (r *contractsRepositoryMock) FindEndingBetween(ctx context.Context, from time.Time, to time.Time) ([]model.Contract, error) {
+	return r.find(ctx, func(c *model.Contract) bool { return !c.EndsAt.Before(from) && c.EndsAt.Before(to) }), nil
+}
+
+func (r *contractsRepositoryMock) Delete(ctx context.Context, id string) error {
+	contractsMutex.Lock()
+	defer contractsMutex.Unlock()
+
+	if contract, ok := contractsContainer[id]; ok && contractVisible(ctx, contract) {
+		delete(contractsContainer, id)
+	}
+	return nil
+}
+
+func (r *contractsRepositoryMock) find(ctx context.Context, matches func(*model.Contract) bool) []model.Contract {
+	contractsMutex.RLock()
+	defer contractsMutex.RUnlock()
+
+	contracts := make([]model.Contract, 0)
+	for _, contract := range contractsContainer {
+		if contractVisible(ctx, contract) && matches(&contract) {
+			contracts = append(contracts, contract)
+		}
+	}
+	return contracts
+}
+
+func contractVisible(ctx context.Context, contract model.Contract) bool {
+	return tenancy.AllTenants(ctx) || contract.TenantID == tenancy.TenantFromContext(ctx)
+}
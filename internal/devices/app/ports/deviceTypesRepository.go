@@ -0,0 +1,83 @@
+package ports
+
+import (
+	"context"
+	"devices_crud/internal/devices/model"
+	"devices_crud/internal/tenancy"
+	"sync"
+)
+
+// DeviceTypesRepository stores device types, scoped to the tenant of ctx like
+// DevicesRepository. Types are identified by their name.
+type DeviceTypesRepository interface {
+	// Save creates the type or replaces the type of the same name.
+	Save(ctx context.Context, deviceType *model.DeviceType) error
+	FindByName(ctx context.Context, name string) (*model.DeviceType, error)
+	FindAll(ctx context.Context) ([]model.DeviceType, error)
+	Delete(ctx context.Context, name string) error
+}
+
+// deviceTypesContainer holds the device types of the mock, keyed by tenant and
+// name.
+var deviceTypesContainer map[deviceTypeKey]model.DeviceType
+
+var deviceTypesMutex sync.RWMutex
+
+type deviceTypeKey struct {
+	tenant string
+	name   string
+}
+
+type deviceTypesRepositoryMock struct {
+}
+
+func NewDeviceTypesRepositoryMock() DeviceTypesRepository {
+	deviceTypesMutex.Lock()
+	defer deviceTypesMutex.Unlock()
+
+	deviceTypesContainer = make(map[deviceTypeKey]model.DeviceType)
+	return &deviceTypesRepositoryMock{}
+}
+
+func (r *deviceTypesRepositoryMock) Save(ctx context.Context, deviceType *model.DeviceType) error {
+	deviceTypesMutex.Lock()
+	defer deviceTypesMutex.Unlock()
+
+	if !tenancy.AllTenants(ctx) || deviceType.TenantID == "" {
+		deviceType.TenantID = tenancy.TenantFromContext(ctx)
+	}
+	deviceTypesContainer[deviceTypeKey{deviceType.TenantID, deviceType.Name}] = *deviceType
+	return nil
+}
+
+func (r *deviceTypesRepositoryMock) FindByName(ctx context.Context, name string) (*model.DeviceType, error) {
+	deviceTypesMutex.RLock()
+	defer deviceTypesMutex.RUnlock()
+
+	deviceType, ok := deviceTypesContainer[deviceTypeKey{tenancy.TenantFromContext(ctx), name}]
+	if !ok {
+		return nil, nil
+	}
+	return &deviceType, nil
+}
+
+func (r *deviceTypesRepositoryMock) FindAll(ctx context.Context) ([]model.DeviceType, error) {
+	deviceTypesMutex.RLock()
+	defer deviceTypesMutex.RUnlock()
+
+	deviceTypes := make([]model.DeviceType, 0)
+	for key, deviceType := range deviceTypesContainer {
+		if tenancy.AllTenants(ctx) || key.tenant == tenancy.TenantFromContext(ctx) {
+			deviceTypes = append(deviceTypes, deviceType)
+		}
+	}
+	return deviceTypes, nil
+}
+
+func (r *deviceTypesRepositoryMock) Delete(ctx context.Context, name string) error {
+	deviceTypesMutex.Lock()
+	defer deviceTypesMutex.Unlock()
+
+	delete(deviceTypesContainer, deviceTypeKey{tenancy.TenantFromContext(ctx), name})
+	return nil
+}
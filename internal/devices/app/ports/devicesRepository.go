@@ -3,59 +3,295 @@ package ports
 import (
 	"context"
 	"devices_crud/internal/devices/model"
+	"devices_crud/internal/filter"
+	"devices_crud/internal/labels"
+	"devices_crud/internal/tenancy"
+	"fmt"
 
 	"strings"
+	"sync"
+	"time"
 )
 
+// DevicesRepository stores devices. Every method is scoped to the tenant of ctx:
+// devices of other tenants are neither visible nor writable, unless ctx was
+// created with tenancy.WithAllTenants.
 type DevicesRepository interface {
-	Save(ctx context.Context, device *model.Device) (*string, error)
+	// Save stores device. When it adds a device to the tenant of ctx while the
+	// tenant holds limit devices already, it fails with model.ErrQuotaExceeded
+	// instead; a limit of zero means unlimited. The count and the write are
+	// atomic, so concurrent creates cannot exceed the limit.
+	Save(ctx context.Context, device *model.Device, limit int) (*string, error)
 	FindByID(ctx context.Context, id *string) (*model.Device, error)
 	FindAll(ctx context.Context) ([]model.Device, error)
+	// FindByFilter returns the devices matching expr, which has been checked
+	// against model.DeviceFilterSchema. In-memory adapters evaluate it with
+	// filter.Compile, SQL adapters render it with filter.ToSQL and DeviceColumns.
+	FindByFilter(ctx context.Context, expr filter.Expr) ([]model.Device, error)
+	// FindByLabels returns the devices whose labels match selector. Adapters
+	// index labels, so that selectors with an equality, set or existence
+	// requirement do not scan every device.
+	FindByLabels(ctx context.Context, selector labels.Selector) ([]model.Device, error)
 	Replace(ctx context.Context, device *model.Device) (*model.Device, error)
 	Patch(ctx context.Context, device *model.PatchDeviceRequest) (*string, error)
+	// UpdateStatus moves the device to change.To and appends change to its
+	// status history, failing with model.ErrIllegalTransition when its status is
+	// no longer change.From. It returns the device, nil when there is no device
+	// with that ID.
+	UpdateStatus(ctx context.Context, id string, change model.StatusChange) (*model.Device, error)
+	// UpdateLocation moves the device to change.To and appends change to its
+	// location history, failing with model.ErrDeviceMoved when it is no longer
+	// in change.From. It returns the device, nil when there is no device with
+	// that ID.
+	UpdateLocation(ctx context.Context, id string, change model.LocationChange) (*model.Device, error)
+	// TouchLastSeen sets LastSeenAt of the device to at and returns the device,
+	// nil when there is no device with that ID. Unlike Replace it leaves every
+	// other field alone, so heartbeats do not undo concurrent writes.
+	TouchLastSeen(ctx context.Context, id string, at time.Time) (*model.Device, error)
 	Delete(ctx context.Context, id string) error
 	Search(ctx context.Context, query string) ([]model.Device, error)
+	Count(ctx context.Context) (int, error)
+	// Ping reports whether the storage behind the repository is reachable.
+	Ping(ctx context.Context) error
+}
+
+// Migrator is implemented by repositories whose storage has a schema. The
+// service is not ready while migrations are pending.
+type Migrator interface {
+	PendingMigrations(ctx context.Context) ([]string, error)
+}
+
+// DeviceColumns maps the fields of model.DeviceFilterSchema to the columns of
+// the devices table of SQL adapters. Attributes are stored as a JSON column.
+var DeviceColumns = map[string]string{
+	"id":           "id",
+	"name":         "name",
+	"brand":        "device_brand",
+	"deviceBrand":  "device_brand",
+	"createdAt":    "created_at",
+	"type":         "device_type",
+	"status":       "status",
+	"locationId":   "location_id",
+	"attributes.*": "attributes ->> %s",
+	"labels.*":     "labels ->> %s",
 }
 
 var DevicesContainer map[string]model.Device
 
+// labelIndex maps label keys to their values to the IDs of the devices in
+// DevicesContainer carrying them.
+var labelIndex map[string]map[string]map[string]struct{}
+
+// devicesMutex guards DevicesContainer and labelIndex against concurrent
+// requests and metric scrapes.
+var devicesMutex sync.RWMutex
+
 type devicesRepositoryMock struct {
 }
 
 func NewDevicesRepositoryMock() DevicesRepository {
+	devicesMutex.Lock()
+	defer devicesMutex.Unlock()
+
 	DevicesContainer = make(map[string]model.Device)
+	labelIndex = make(map[string]map[string]map[string]struct{})
 	return &devicesRepositoryMock{}
 }
 
-func (r *devicesRepositoryMock) Save(ctx context.Context, device *model.Device) (*string, error) {
-	DevicesContainer[device.ID] = *device
+// put stores device and indexes its labels. Callers hold devicesMutex.
+func put(device model.Device) {
+	remove(device.ID)
+	DevicesContainer[device.ID] = stored(device)
+	for key, value := range device.Labels {
+		if labelIndex[key] == nil {
+			labelIndex[key] = make(map[string]map[string]struct{})
+		}
+		if labelIndex[key][value] == nil {
+			labelIndex[key][value] = make(map[string]struct{})
+		}
+		labelIndex[key][value][device.ID] = struct{}{}
+	}
+}
+
+// remove deletes the device and its labels from the index. Callers hold
+// devicesMutex.
+func remove(id string) {
+	device, ok := DevicesContainer[id]
+	if !ok {
+		return
+	}
+	for key, value := range device.Labels {
+		delete(labelIndex[key][value], id)
+		if len(labelIndex[key][value]) == 0 {
+			delete(labelIndex[key], value)
+		}
+		if len(labelIndex[key]) == 0 {
+			delete(labelIndex, key)
+		}
+	}
+	delete(DevicesContainer, id)
+}
+
+// candidates returns the IDs of the devices which may match requirement.
+func candidates(requirement labels.Requirement) map[string]struct{} {
+	values := requirement.Values
+	if requirement.Operator == labels.Exists {
+		values = make([]string, 0, len(labelIndex[requirement.Key]))
+		for value := range labelIndex[requirement.Key] {
+			values = append(values, value)
+		}
+	}
+
+	ids := make(map[string]struct{})
+	for _, value := range values {
+		for id := range labelIndex[requirement.Key][value] {
+			ids[id] = struct{}{}
+		}
+	}
+	return ids
+}
+
+// visible reports whether device belongs to the tenant of ctx.
+func visible(ctx context.Context, device model.Device) bool {
+	return tenancy.AllTenants(ctx) || device.TenantID == tenancy.TenantFromContext(ctx)
+}
+
+// stored copies the attributes, labels and histories of device, so that callers
+// cannot modify stored devices through the map.
+func stored(device model.Device) model.Device {
+	device.Attributes = device.Attributes.Merge(nil)
+	device.Labels = model.MergeLabels(device.Labels, nil)
+	device.StatusHistory = append([]model.StatusChange(nil), device.StatusHistory...)
+	device.LocationHistory = append([]model.LocationChange(nil), device.LocationHistory...)
+	return device
+}
+
+// own assigns device to the tenant of ctx. System contexts keep the tenant the
+// device already carries.
+func own(ctx context.Context, device *model.Device) {
+	if !tenancy.AllTenants(ctx) || device.TenantID == "" {
+		device.TenantID = tenancy.TenantFromContext(ctx)
+	}
+}
+
+func (r *devicesRepositoryMock) Save(ctx context.Context, device *model.Device, limit int) (*string, error) {
+	devicesMutex.Lock()
+	defer devicesMutex.Unlock()
+
+	existing, ok := DevicesContainer[device.ID]
+	if ok && !visible(ctx, existing) {
+		return nil, model.ErrDeviceNotFound
+	}
+	own(ctx, device)
+	if !ok && limit > 0 {
+		count := 0
+		for _, stored := range DevicesContainer {
+			if stored.TenantID == device.TenantID {
+				count++
+			}
+		}
+		if count >= limit {
+			return nil, fmt.Errorf("%w: tenant %s is limited to %d devices", model.ErrQuotaExceeded, device.TenantID, limit)
+		}
+	}
+	put(*device)
 	return &device.ID, nil
 }
 
 func (r *devicesRepositoryMock) FindByID(ctx context.Context, id *string) (*model.Device, error) {
+	devicesMutex.RLock()
+	defer devicesMutex.RUnlock()
+
 	device, ok := DevicesContainer[*id]
-	if !ok {
+	if !ok || !visible(ctx, device) {
 		return nil, nil
 	}
+	device = stored(device)
 	return &device, nil
 }
 
 func (r *devicesRepositoryMock) FindAll(ctx context.Context) ([]model.Device, error) {
+	devicesMutex.RLock()
+	defer devicesMutex.RUnlock()
+
+	devices := make([]model.Device, 0)
+	for _, device := range DevicesContainer {
+		if visible(ctx, device) {
+			devices = append(devices, stored(device))
+		}
+	}
+	return devices, nil
+}
+
+func (r *devicesRepositoryMock) FindByFilter(ctx context.Context, expr filter.Expr) ([]model.Device, error) {
+	matches, err := filter.Compile(expr, model.DeviceFilterSchema)
+	if err != nil {
+		return nil, err
+	}
+
+	devicesMutex.RLock()
+	defer devicesMutex.RUnlock()
+
 	devices := make([]model.Device, 0)
 	for _, device := range DevicesContainer {
-		devices = append(devices, device)
+		if visible(ctx, device) && matches(&device) {
+			devices = append(devices, stored(device))
+		}
+	}
+	return devices, nil
+}
+
+func (r *devicesRepositoryMock) FindByLabels(ctx context.Context, selector labels.Selector) ([]model.Device, error) {
+	devicesMutex.RLock()
+	defer devicesMutex.RUnlock()
+
+	// Answer from the smallest set of candidates any requirement selects, and
+	// only scan everything for purely negative selectors.
+	var ids map[string]struct{}
+	for _, requirement := range selector {
+		if requirement.Selects() {
+			if selected := candidates(requirement); ids == nil || len(selected) < len(ids) {
+				ids = selected
+			}
+		}
+	}
+
+	devices := make([]model.Device, 0)
+	if ids == nil {
+		for _, device := range DevicesContainer {
+			if visible(ctx, device) && selector.Matches(device.Labels) {
+				devices = append(devices, stored(device))
+			}
+		}
+		return devices, nil
+	}
+	for id := range ids {
+		device := DevicesContainer[id]
+		if visible(ctx, device) && selector.Matches(device.Labels) {
+			devices = append(devices, stored(device))
+		}
 	}
 	return devices, nil
 }
 
 func (r *devicesRepositoryMock) Replace(ctx context.Context, device *model.Device) (*model.Device, error) {
-	DevicesContainer[device.ID] = *device
+	devicesMutex.Lock()
+	defer devicesMutex.Unlock()
+
+	if existing, ok := DevicesContainer[device.ID]; ok && !visible(ctx, existing) {
+		return nil, model.ErrDeviceNotFound
+	}
+	own(ctx, device)
+	put(*device)
 	return device, nil
 }
 
 func (r *devicesRepositoryMock) Patch(ctx context.Context, device *model.PatchDeviceRequest) (*string, error) {
+	devicesMutex.Lock()
+	defer devicesMutex.Unlock()
+
 	deviceToPatch, ok := DevicesContainer[device.ID]
-	if !ok {
+	if !ok || !visible(ctx, deviceToPatch) {
 		return nil, nil
 	}
 
@@ -65,22 +301,106 @@ func (r *devicesRepositoryMock) Patch(ctx context.Context, device *model.PatchDe
 	if device.DeviceBrand != nil {
 		deviceToPatch.DeviceBrand = *device.DeviceBrand
 	}
+	if device.Type != nil {
+		deviceToPatch.Type = *device.Type
+	}
+	if device.Attributes != nil {
+		deviceToPatch.Attributes = deviceToPatch.Attributes.Merge(device.Attributes)
+	}
+	if device.Labels != nil {
+		deviceToPatch.Labels = model.MergeLabels(deviceToPatch.Labels, device.Labels)
+	}
 
-	DevicesContainer[device.ID] = deviceToPatch
+	put(deviceToPatch)
 	return &device.ID, nil
 }
 
+func (r *devicesRepositoryMock) UpdateStatus(ctx context.Context, id string, change model.StatusChange) (*model.Device, error) {
+	devicesMutex.Lock()
+	defer devicesMutex.Unlock()
+
+	device, ok := DevicesContainer[id]
+	if !ok || !visible(ctx, device) {
+		return nil, nil
+	}
+	if device.Status != change.From {
+		return nil, fmt.Errorf("%w: %s to %s, the device is %s now", model.ErrIllegalTransition, change.From, change.To, device.Status)
+	}
+	device.Status = change.To
+	device.StatusHistory = append(device.StatusHistory, change)
+	put(device)
+	device = stored(device)
+	return &device, nil
+}
+
+func (r *devicesRepositoryMock) UpdateLocation(ctx context.Context, id string, change model.LocationChange) (*model.Device, error) {
+	devicesMutex.Lock()
+	defer devicesMutex.Unlock()
+
+	device, ok := DevicesContainer[id]
+	if !ok || !visible(ctx, device) {
+		return nil, nil
+	}
+	if device.LocationID != change.From {
+		return nil, fmt.Errorf("%w: expected in %q, found in %q", model.ErrDeviceMoved, change.From, device.LocationID)
+	}
+	device.LocationID = change.To
+	device.LocationHistory = append(device.LocationHistory, change)
+	put(device)
+	device = stored(device)
+	return &device, nil
+}
+
+func (r *devicesRepositoryMock) TouchLastSeen(ctx context.Context, id string, at time.Time) (*model.Device, error) {
+	devicesMutex.Lock()
+	defer devicesMutex.Unlock()
+
+	device, ok := DevicesContainer[id]
+	if !ok || !visible(ctx, device) {
+		return nil, nil
+	}
+	device.LastSeenAt = &at
+	put(device)
+	device = stored(device)
+	return &device, nil
+}
+
 func (r *devicesRepositoryMock) Delete(ctx context.Context, id string) error {
-	delete(DevicesContainer, id)
+	devicesMutex.Lock()
+	defer devicesMutex.Unlock()
+
+	if device, ok := DevicesContainer[id]; ok && visible(ctx, device) {
+		remove(id)
+	}
 	return nil
 }
 
 func (r *devicesRepositoryMock) Search(ctx context.Context, query string) ([]model.Device, error) {
+	devicesMutex.RLock()
+	defer devicesMutex.RUnlock()
+
 	var devices []model.Device
 	for _, device := range DevicesContainer {
-		if strings.Contains(strings.ToLower(device.DeviceBrand), strings.ToLower(query)) {
-			devices = append(devices, device)
+		if visible(ctx, device) && strings.Contains(strings.ToLower(device.DeviceBrand), strings.ToLower(query)) {
+			devices = append(devices, stored(device))
 		}
 	}
 	return devices, nil
 }
+
+func (r *devicesRepositoryMock) Count(ctx context.Context) (int, error) {
+	devicesMutex.RLock()
+	defer devicesMutex.RUnlock()
+
+	count := 0
+	for _, device := range DevicesContainer {
+		if visible(ctx, device) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *devicesRepositoryMock) Ping(ctx context.Context) error {
+	return ctx.Err()
+}
@@ -0,0 +1,93 @@
+package ports
+
+import (
+	"context"
+	"devices_crud/internal/devices/model"
+	"devices_crud/internal/tenancy"
+	"sync"
+)
+
+// LocationsRepository stores the location tree, scoped to the tenant of ctx
+// like DevicesRepository. The service keeps the tree consistent.
+type LocationsRepository interface {
+	// Save creates the location or replaces the location with the same ID.
+	Save(ctx context.Context, location *model.Location) error
+	FindByID(ctx context.Context, id string) (*model.Location, error)
+	FindAll(ctx context.Context) ([]model.Location, error)
+	// FindChildren returns the locations directly below parentID, the sites
+	// when parentID is empty.
+	FindChildren(ctx context.Context, parentID string) ([]model.Location, error)
+	Delete(ctx context.Context, id string) error
+}
+
+var locationsContainer map[string]model.Location
+
+var locationsMutex sync.RWMutex
+
+type locationsRepositoryMock struct {
+}
+
+func NewLocationsRepositoryMock() LocationsRepository {
+	locationsMutex.Lock()
+	defer locationsMutex.Unlock()
+
+	locationsContainer = make(map[string]model.Location)
+	return &locationsRepositoryMock{}
+}
+
+func (r *locationsRepositoryMock) Save(ctx context.Context, location *model.Location) error {
+	locationsMutex.Lock()
+	defer locationsMutex.Unlock()
+
+	if !tenancy.AllTenants(ctx) || location.TenantID == "" {
+		location.TenantID = tenancy.TenantFromContext(ctx)
+	}
+	locationsContainer[location.ID] = *location
+	return nil
+}
+
+func (r *locationsRepositoryMock) FindByID(ctx context.Context, id string) (*model.Location, error) {
+	locationsMutex.RLock()
+	defer locationsMutex.RUnlock()
+
+	location, ok := locationsContainer[id]
+	if !ok || !locationVisible(ctx, location) {
+		return nil, nil
+	}
+	return &location, nil
+}
+
+func (r *locationsRepositoryMock) FindAll(ctx context.Context) ([]model.Location, error) {
+	return r.find(ctx, func(*model.Location) bool { return true }), nil
+}
+
+func (r *locationsRepositoryMock) FindChildren(ctx context.Context, parentID string) ([]model.Location, error) {
+	return r.find(ctx, func(location *model.Location) bool { return location.ParentID == parentID }), nil
+}
+
+func (r *locationsRepositoryMock) Delete(ctx context.Context, id string) error {
+	locationsMutex.Lock()
+	defer locationsMutex.Unlock()
+
+	if location, ok := locationsContainer[id]; ok && locationVisible(ctx, location) {
+		delete(locationsContainer, id)
+	}
+	return nil
+}
+
+func (r *locationsRepositoryMock) find(ctx context.Context, matches func(*model.Location) bool) []model.Location {
+	locationsMutex.RLock()
+	defer locationsMutex.RUnlock()
+
+	locations := make([]model.Location, 0)
+	for _, location := range locationsContainer {
+		if locationVisible(ctx, location) && matches(&location) {
+			locations = append(locations, location)
+		}
+	}
+	return locations
+}
+
+func locationVisible(ctx context.Context, location model.Location) bool {
+	return tenancy.AllTenants(ctx) || location.TenantID == tenancy.TenantFromContext(ctx)
+}
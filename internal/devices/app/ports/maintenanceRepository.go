@@ -0,0 +1,120 @@
+package ports
+
+import (
+	"context"
+	"devices_crud/internal/devices/model"
+	"devices_crud/internal/tenancy"
+	"sync"
+)
+
+// MaintenanceRepository stores the maintenance plans and the service records
+// of devices, scoped to the tenant of ctx like DevicesRepository.
+type MaintenanceRepository interface {
+	SavePlan(ctx context.Context, plan *model.MaintenancePlan) error
+	FindPlan(ctx context.Context, id string) (*model.MaintenancePlan, error)
+	FindPlansByDevice(ctx context.Context, deviceID string) ([]model.MaintenancePlan, error)
+	FindPlans(ctx context.Context) ([]model.MaintenancePlan, error)
+	DeletePlan(ctx context.Context, id string) error
+	SaveRecord(ctx context.Context, record *model.ServiceRecord) error
+	FindRecordsByDevice(ctx context.Context, deviceID string) ([]model.ServiceRecord, error)
+}
+
+var (
+	plansContainer   map[string]model.MaintenancePlan
+	recordsContainer map[string]model.ServiceRecord
+)
+
+var maintenanceMutex sync.RWMutex
+
+type maintenanceRepositoryMock struct {
+}
+
+func NewMaintenanceRepositoryMock() MaintenanceRepository {
+	maintenanceMutex.Lock()
+	defer maintenanceMutex.Unlock()
+
+	plansContainer = make(map[string]model.MaintenancePlan)
+	recordsContainer = make(map[string]model.ServiceRecord)
+	return &maintenanceRepositoryMock{}
+}
+
+func (r *maintenanceRepositoryMock) SavePlan(ctx context.Context, plan *model.MaintenancePlan) error {
+	maintenanceMutex.Lock()
+	defer maintenanceMutex.Unlock()
+
+	if !tenancy.AllTenants(ctx) || plan.TenantID == "" {
+		plan.TenantID = tenancy.TenantFromContext(ctx)
+	}
+	plansContainer[plan.ID] = *plan
+	return nil
+}
+
+func (r *maintenanceRepositoryMock) FindPlan(ctx context.Context, id string) (*model.MaintenancePlan, error) {
+	maintenanceMutex.RLock()
+	defer maintenanceMutex.RUnlock()
+
+	plan, ok := plansContainer[id]
+	if !ok || !maintenanceVisible(ctx, plan.TenantID) {
+		return nil, nil
+	}
+	return &plan, nil
+}
+
+func (r *maintenanceRepositoryMock) FindPlansByDevice(ctx context.Context, deviceID string) ([]model.MaintenancePlan, error) {
+	return r.findPlans(ctx, func(plan *model.MaintenancePlan) bool { return plan.DeviceID == deviceID }), nil
+}
+
+func (r *maintenanceRepositoryMock) FindPlans(ctx context.Context) ([]model.MaintenancePlan, error) {
+	return r.findPlans(ctx, func(*model.MaintenancePlan) bool { return true }), nil
+}
+
+func (r *maintenanceRepositoryMock) DeletePlan(ctx context.Context, id string) error {
+	maintenanceMutex.Lock()
+	defer maintenanceMutex.Unlock()
+
+	if plan, ok := plansContainer[id]; ok && maintenanceVisible(ctx, plan.TenantID) {
+		delete(plansContainer, id)
+	}
+	return nil
+}
+
+func (r *maintenanceRepositoryMock) SaveRecord(ctx context.Context, record *model.ServiceRecord) error {
+	maintenanceMutex.Lock()
+	defer maintenanceMutex.Unlock()
+
+	if !tenancy.AllTenants(ctx) || record.TenantID == "" {
+		record.TenantID = tenancy.TenantFromContext(ctx)
+	}
+	recordsContainer[record.ID] = *record
+	return nil
+}
+
+func (r *maintenanceRepositoryMock) FindRecordsByDevice(ctx context.Context, deviceID string) ([]model.ServiceRecord, error) {
+	maintenanceMutex.RLock()
+	defer maintenanceMutex.RUnlock()
+
+	records := make([]model.ServiceRecord, 0)
+	for _, record := range recordsContainer {
+		if maintenanceVisible(ctx, record.TenantID) && record.DeviceID == deviceID {
+			records = append(records, record)
+		}
+	}
+	return records, nil
+}
+
+func (r *maintenanceRepositoryMock) findPlans(ctx context.Context, matches func(*model.MaintenancePlan) bool) []model.MaintenancePlan {
+	maintenanceMutex.RLock()
+	defer maintenanceMutex.RUnlock()
+
+	plans := make([]model.MaintenancePlan, 0)
+	for _, plan := range plansContainer {
+		if maintenanceVisible(ctx, plan.TenantID) && matches(&plan) {
+			plans = append(plans, plan)
+		}
+	}
+	return plans
+}
+
+func maintenanceVisible(ctx context.Context, tenantID string) bool {
+	return tenancy.AllTenants(ctx) || tenantID == tenancy.TenantFromContext(ctx)
+}
@@ -0,0 +1,84 @@
+package ports
+
+import (
+	"context"
+	"devices_crud/internal/devices/model"
+	"devices_crud/internal/tenancy"
+	"sync"
+)
+
+// RedirectsRepository stores where the IDs of merged devices lead, scoped to
+// the tenant of ctx like DevicesRepository.
+type RedirectsRepository interface {
+	// Save creates the redirect or replaces the redirect from the same ID.
+	Save(ctx context.Context, redirect *model.DeviceRedirect) error
+	// Find returns the redirect from the ID, nil when there is none.
+	Find(ctx context.Context, fromID string) (*model.DeviceRedirect, error)
+	// FindByTarget returns the redirects leading to the device.
+	FindByTarget(ctx context.Context, toID string) ([]model.DeviceRedirect, error)
+	Delete(ctx context.Context, fromID string) error
+}
+
+var redirectsContainer map[string]model.DeviceRedirect
+
+var redirectsMutex sync.RWMutex
+
+type redirectsRepositoryMock struct {
+}
+
+func NewRedirectsRepositoryMock() RedirectsRepository {
+	redirectsMutex.Lock()
+	defer redirectsMutex.Unlock()
+
+	redirectsContainer = make(map[string]model.DeviceRedirect)
+	return &redirectsRepositoryMock{}
+}
+
+func (r *redirectsRepositoryMock) Save(ctx context.Context, redirect *model.DeviceRedirect) error {
+	redirectsMutex.Lock()
+	defer redirectsMutex.Unlock()
+
+	if !tenancy.AllTenants(ctx) || redirect.TenantID == "" {
+		redirect.TenantID = tenancy.TenantFromContext(ctx)
+	}
+	redirectsContainer[redirect.FromID] = *redirect
+	return nil
+}
+
+func (r *redirectsRepositoryMock) Find(ctx context.Context, fromID string) (*model.DeviceRedirect, error) {
+	redirectsMutex.RLock()
+	defer redirectsMutex.RUnlock()
+
+	redirect, ok := redirectsContainer[fromID]
+	if !ok || !redirectVisible(ctx, redirect) {
+		return nil, nil
+	}
+	return &redirect, nil
+}
+
+func (r *redirectsRepositoryMock) FindByTarget(ctx context.Context, toID string) ([]model.DeviceRedirect, error) {
+	redirectsMutex.RLock()
+	defer redirectsMutex.RUnlock()
+
+	redirects := make([]model.DeviceRedirect, 0)
+	for _, redirect := range redirectsContainer {
+		if redirectVisible(ctx, redirect) && redirect.ToID == toID {
+			redirects = append(redirects, redirect)
+		}
+	}
+	return redirects, nil
+}
+
+func (r *redirectsRepositoryMock) Delete(ctx context.Context, fromID string) error {
+	redirectsMutex.Lock()
+	defer redirectsMutex.Unlock()
+
+	if redirect, ok := redirectsContainer[fromID]; ok && redirectVisible(ctx, redirect) {
+		delete(redirectsContainer, fromID)
+	}
+	return nil
+}
+
+func redirectVisible(ctx context.Context, redirect model.DeviceRedirect) bool {
+	return tenancy.AllTenants(ctx) || redirect.TenantID == tenancy.TenantFromContext(ctx)
+}
@@ -0,0 +1,83 @@
+package ports
+
+import (
+	"context"
+	"devices_crud/internal/devices/model"
+	"devices_crud/internal/tenancy"
+	"sync"
+)
+
+// RelationshipsRepository stores the links between devices, scoped to the
+// tenant of ctx like DevicesRepository.
+type RelationshipsRepository interface {
+	Save(ctx context.Context, relationship *model.Relationship) error
+	FindByID(ctx context.Context, id string) (*model.Relationship, error)
+	// FindByDevice returns the relationships the device is the source or the
+	// target of.
+	FindByDevice(ctx context.Context, deviceID string) ([]model.Relationship, error)
+	Delete(ctx context.Context, id string) error
+}
+
+var relationshipsContainer map[string]model.Relationship
+
+var relationshipsMutex sync.RWMutex
+
+type relationshipsRepositoryMock struct {
+}
+
+func NewRelationshipsRepositoryMock() RelationshipsRepository {
+	relationshipsMutex.Lock()
+	defer relationshipsMutex.Unlock()
+
+	relationshipsContainer = make(map[string]model.Relationship)
+	return &relationshipsRepositoryMock{}
+}
+
+func (r *relationshipsRepositoryMock) Save(ctx context.Context, relationship *model.Relationship) error {
+	relationshipsMutex.Lock()
+	defer relationshipsMutex.Unlock()
+
+	if !tenancy.AllTenants(ctx) || relationship.TenantID == "" {
+		relationship.TenantID = tenancy.TenantFromContext(ctx)
+	}
+	relationshipsContainer[relationship.ID] = *relationship
+	return nil
+}
+
+func (r *relationshipsRepositoryMock) FindByID(ctx context.Context, id string) (*model.Relationship, error) {
+	relationshipsMutex.RLock()
+	defer relationshipsMutex.RUnlock()
+
+	relationship, ok := relationshipsContainer[id]
+	if !ok || !relationshipVisible(ctx, relationship) {
+		return nil, nil
+	}
+	return &relationship, nil
+}
+
+func (r *relationshipsRepositoryMock) FindByDevice(ctx context.Context, deviceID string) ([]model.Relationship, error) {
+	relationshipsMutex.RLock()
+	defer relationshipsMutex.RUnlock()
+
+	relationships := make([]model.Relationship, 0)
+	for _, relationship := range relationshipsContainer {
+		if relationshipVisible(ctx, relationship) && (relationship.FromID == deviceID || relationship.ToID == deviceID) {
+			relationships = append(relationships, relationship)
+		}
+	}
+	return relationships, nil
+}
+
+func (r *relationshipsRepositoryMock) Delete(ctx context.Context, id string) error {
+	relationshipsMutex.Lock()
+	defer relationshipsMutex.Unlock()
+
+	if relationship, ok := relationshipsContainer[id]; ok && relationshipVisible(ctx, relationship) {
+		delete(relationshipsContainer, id)
+	}
+	return nil
+}
+
+func relationshipVisible(ctx context.Context, relationship model.Relationship) bool {
+	return tenancy.AllTenants(ctx) || relationship.TenantID == tenancy.TenantFromContext(ctx)
+}
@@ -0,0 +1,135 @@
+package ports
+
+import (
+	"context"
+	"devices_crud/internal/devices/model"
+	"devices_crud/internal/tenancy"
+	"sync"
+)
+
+// SoftwareRepository stores the software installed on devices and the
+// policies it must comply with, scoped to the tenant of ctx like
+// DevicesRepository.
+type SoftwareRepository interface {
+	SaveSoftware(ctx context.Context, software *model.InstalledSoftware) error
+	FindSoftware(ctx context.Context, id string) (*model.InstalledSoftware, error)
+	FindSoftwareByDevice(ctx context.Context, deviceID string) ([]model.InstalledSoftware, error)
+	DeleteSoftware(ctx context.Context, id string) error
+	SavePolicy(ctx context.Context, policy *model.SoftwarePolicy) error
+	FindPolicy(ctx context.Context, id string) (*model.SoftwarePolicy, error)
+	FindPolicies(ctx context.Context) ([]model.SoftwarePolicy, error)
+	DeletePolicy(ctx context.Context, id string) error
+}
+
+var (
+	softwareContainer map[string]model.InstalledSoftware
+	policiesContainer map[string]model.SoftwarePolicy
+)
+
+var softwareMutex sync.RWMutex
+
+type softwareRepositoryMock struct {
+}
+
+func NewSoftwareRepositoryMock() SoftwareRepository {
+	softwareMutex.Lock()
+	defer softwareMutex.Unlock()
+
+	softwareContainer = make(map[string]model.InstalledSoftware)
+	policiesContainer = make(map[string]model.SoftwarePolicy)
+	return &softwareRepositoryMock{}
+}
+
+func (r *softwareRepositoryMock) SaveSoftware(ctx context.Context, software *model.InstalledSoftware) error {
+	softwareMutex.Lock()
+	defer softwareMutex.Unlock()
+
+	if !tenancy.AllTenants(ctx) || software.TenantID == "" {
+		software.TenantID = tenancy.TenantFromContext(ctx)
+	}
+	softwareContainer[software.ID] = *software
+	return nil
+}
+
+func (r *softwareRepositoryMock) FindSoftware(ctx context.Context, id string) (*model.InstalledSoftware, error) {
+	softwareMutex.RLock()
+	defer softwareMutex.RUnlock()
+
+	software, ok := softwareContainer[id]
+	if !ok || !softwareVisible(ctx, software.TenantID) {
+		return nil, nil
+	}
+	return &software, nil
+}
+
+func (r *softwareRepositoryMock) FindSoftwareByDevice(ctx context.Context, deviceID string) ([]model.InstalledSoftware, error) {
+	softwareMutex.RLock()
+	defer softwareMutex.RUnlock()
+
+	found := make([]model.InstalledSoftware, 0)
+	for _, software := range softwareContainer {
+		if softwareVisible(ctx, software.TenantID) && software.DeviceID == deviceID {
+			found = append(found, software)
+		}
+	}
+	return found, nil
+}
+
+func (r *softwareRepositoryMock) DeleteSoftware(ctx context.Context, id string) error {
+	softwareMutex.Lock()
+	defer softwareMutex.Unlock()
+
+	if software, ok := softwareContainer[id]; ok && softwareVisible(ctx, software.TenantID) {
+		delete(softwareContainer, id)
+	}
+	return nil
+}
+
+func (r *softwareRepositoryMock) SavePolicy(ctx context.Context, policy *model.SoftwarePolicy) error {
+	softwareMutex.Lock()
+	defer softwareMutex.Unlock()
+
+	if !tenancy.AllTenants(ctx) || policy.TenantID == "" {
+		policy.TenantID = tenancy.TenantFromContext(ctx)
+	}
+	policiesContainer[policy.ID] = *policy
+	return nil
+}
+
+func (r *softwareRepositoryMock) FindPolicy(ctx context.Context, id string) (*model.SoftwarePolicy, error) {
+	softwareMutex.RLock()
+	defer softwareMutex.RUnlock()
+
+	policy, ok := policiesContainer[id]
+	if !ok || !softwareVisible(ctx, policy.TenantID) {
+		return nil, nil
+	}
+	return &policy, nil
+}
+
+func (r *softwareRepositoryMock) FindPolicies(ctx context.Context) ([]model.SoftwarePolicy, error) {
+	softwareMutex.RLock()
+	defer softwareMutex.RUnlock()
+
+	policies := make([]model.SoftwarePolicy, 0)
+	for _, policy := range policiesContainer {
+		if softwareVisible(ctx, policy.TenantID) {
+			policies = append(policies, policy)
+		}
+	}
+	return policies, nil
+}
+
+func (r *softwareRepositoryMock) DeletePolicy(ctx context.Context, id string) error {
+	softwareMutex.Lock()
+	defer softwareMutex.Unlock()
+
+	if policy, ok := policiesContainer[id]; ok && softwareVisible(ctx, policy.TenantID) {
+		delete(policiesContainer, id)
+	}
+	return nil
+}
+
+func softwareVisible(ctx context.Context, tenantID string) bool {
+	return tenancy.AllTenants(ctx) || tenantID == tenancy.TenantFromContext(ctx)
+}
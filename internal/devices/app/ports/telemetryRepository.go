@@ -0,0 +1,24 @@
+package ports
+
+import (
+	"context"
+	"devices_crud/internal/devices/model"
+	"time"
+)
+
+// TelemetryRepository stores the readings of devices. Device IDs are unique
+// across tenants, so readings are not scoped to the tenant of ctx: the
+// service checks the device is visible first.
+type TelemetryRepository interface {
+	Append(ctx context.Context, deviceID string, readings []model.Reading) error
+	// Range returns the readings of the metric taken in [from, to), the
+	// oldest first.
+	Range(ctx context.Context, deviceID string, metric string, from time.Time, to time.Time) ([]model.Reading, error)
+	// Aggregate summarizes the readings of the metric taken in [from, to) per
+	// step from from, or over the whole range without a step. Steps without
+	// readings are left out.
+	Aggregate(ctx context.Context, deviceID string, metric string, from time.Time, to time.Time, step time.Duration) ([]model.ReadingAggregate, error)
+	// Latest returns the most recent reading of every metric of the device.
+	Latest(ctx context.Context, deviceID string) ([]model.Reading, error)
+	Delete(ctx context.Context, deviceID string) error
+}
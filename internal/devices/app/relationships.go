@@ -0,0 +1,362 @@
+package app
+
+import (
+	"context"
+	"devices_crud/internal/auth"
+	"devices_crud/internal/devices/model"
+	"devices_crud/internal/logging"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// LinkDevices links the device to request.TargetID, enforcing the
+// model.RelationRules of the type. It returns nil when there is no device with
+// that ID.
+func (s *DeviceService) LinkDevices(ctx context.Context, id string, request *model.LinkRequest) (*model.Relationship, error) {
+	ctx, span := s.startSpan(ctx, "LinkDevices", attribute.String("device.id", id), attribute.String("relationship.type", string(request.Type)))
+	defer span.End()
+
+	if err := s.authorize(ctx, auth.PermissionDevicesWrite); err != nil {
+		return nil, err
+	}
+	if s.Relationships == nil {
+		return nil, fmt.Errorf("%w: relationships are not supported", model.ErrInvalidRelationship)
+	}
+	relationType, err := model.ParseRelationType(string(request.Type))
+	if err != nil {
+		return nil, err
+	}
+	if request.TargetID == id {
+		return nil, fmt.Errorf("%w: a device cannot be linked to itself", model.ErrInvalidRelationship)
+	}
+
+	device, err := s.DevicesRepository.FindByID(ctx, &id)
+	if err != nil || device == nil {
+		return nil, err
+	}
+	target, err := s.DevicesRepository.FindByID(ctx, &request.TargetID)
+	if err != nil {
+		return nil, err
+	}
+	if target == nil {
+		return nil, fmt.Errorf("%w: device %s not found", model.ErrInvalidRelationship, request.TargetID)
+	}
+
+	if err := s.checkLink(ctx, id, request.TargetID, relationType); err != nil {
+		return nil, err
+	}
+
+	relationship := &model.Relationship{
+		ID:        uuid.New().String(),
+		Type:      relationType,
+		FromID:    id,
+		ToID:      request.TargetID,
+		CreatedAt: time.Now(),
+		CreatedBy: actor(ctx),
+	}
+	if err := s.Relationships.Save(ctx, relationship); err != nil {
+		s.Logger.ErrorContext(ctx, "Error linking devices", logging.DeviceIDKey, id, "error", err)
+		return nil, err
+	}
+	s.Logger.InfoContext(ctx, "Devices linked", logging.DeviceIDKey, id, "type", relationType, "target", request.TargetID)
+	return relationship, nil
+}
+
+// UnlinkDevices removes a relationship of the device and returns it, nil when
+// the device has no relationship with that ID.
+func (s *DeviceService) UnlinkDevices(ctx context.Context, id string, relationshipID string) (*model.Relationship, error) {
+	ctx, span := s.startSpan(ctx, "UnlinkDevices", attribute.String("device.id", id), attribute.String("relationship.id", relationshipID))
+	defer span.End()
+
+	if err := s.authorize(ctx, auth.PermissionDevicesWrite); err != nil {
+		return nil, err
+	}
+	if s.Relationships == nil {
+		return nil, nil
+	}
+	relationship, err := s.Relationships.FindByID(ctx, relationshipID)
+	if err != nil || relationship == nil || (relationship.FromID != id && relationship.ToID != id) {
+		return nil, err
+	}
+
+	if err := s.Relationships.Delete(ctx, relationshipID); err != nil {
+		s.Logger.ErrorContext(ctx, "Error unlinking devices", logging.DeviceIDKey, id, "error", err)
+		return nil, err
+	}
+	s.Logger.InfoContext(ctx, "Devices unlinked", logging.DeviceIDKey, id, "type", relationship.Type, "other", relationship.Other(id))
+	return relationship, nil
+}
+
+// DeviceRelationships returns the relationships the device is the source or
+// the target of, oldest first.
+func (s *DeviceService) DeviceRelationships(ctx context.Context, id string) ([]model.Relationship, error) {
+	ctx, span := s.startSpan(ctx, "DeviceRelationships", attribute.String("device.id", id))
+	defer span.End()
+
+	if err := s.authorize(ctx, auth.PermissionDevicesRead); err != nil {
+		return nil, err
+	}
+	return s.relationships(ctx, id)
+}
+
+// ParentDevice returns the device containing the device, nil when it is not
+// contained in any.
+func (s *DeviceService) ParentDevice(ctx context.Context, id string) (*model.Device, error) {
+	ctx, span := s.startSpan(ctx, "ParentDevice", attribute.String("device.id", id))
+	defer span.End()
+
+	if err := s.authorize(ctx, auth.PermissionDevicesRead); err != nil {
+		return nil, err
+	}
+	links, err := s.relationships(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	for _, link := range links {
+		if link.Type == model.RelationContains && link.ToID == id {
+			return s.DevicesRepository.FindByID(ctx, &link.FromID)
+		}
+	}
+	return nil, nil
+}
+
+// ChildDevices returns the devices the device contains, in the order they
+// were put in.
+func (s *DeviceService) ChildDevices(ctx context.Context, id string) ([]model.Device, error) {
+	ctx, span := s.startSpan(ctx, "ChildDevices", attribute.String("device.id", id))
+	defer span.End()
+
+	if err := s.authorize(ctx, auth.PermissionDevicesRead); err != nil {
+		return nil, err
+	}
+	links, err := s.relationships(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	children := []model.Device{}
+	for _, link := range links {
+		if link.Type != model.RelationContains || link.FromID != id {
+			continue
+		}
+		child, err := s.DevicesRepository.FindByID(ctx, &link.ToID)
+		if err != nil {
+			return nil, err
+		}
+		if child != nil {
+			children = append(children, *child)
+		}
+	}
+	return children, nil
+}
+
+// TraverseDevices walks the relationships from the device breadth-first,
+// whatever their direction, and returns the devices and relationships it
+// reached. The device itself is the node of depth 0. It returns nil when there
+// is no device with that ID.
+func (s *DeviceService) TraverseDevices(ctx context.Context, id string, request *model.TraversalRequest) (*model.DeviceGraph, error) {
+	ctx, span := s.startSpan(ctx, "TraverseDevices", attribute.String("device.id", id), attribute.Int("depth", request.Depth))
+	defer span.End()
+
+	if err := s.authorize(ctx, auth.PermissionDevicesRead); err != nil {
+		return nil, err
+	}
+	depth := request.Depth
+	if depth == 0 {
+		depth = 1
+	}
+	if depth < 0 || depth > model.MaxTraversalDepth {
+		return nil, fmt.Errorf("%w: depth must be between 1 and %d", model.ErrInvalidRelationship, model.MaxTraversalDepth)
+	}
+	follow := map[model.RelationType]bool{}
+	for _, relationType := range request.Types {
+		if _, err := model.ParseRelationType(string(relationType)); err != nil {
+			return nil, err
+		}
+		follow[relationType] = true
+	}
+
+	root, err := s.DevicesRepository.FindByID(ctx, &id)
+	if err != nil || root == nil {
+		return nil, err
+	}
+	graph := &model.DeviceGraph{Nodes: []model.GraphNode{{Device: *root}}, Edges: []model.Relationship{}}
+	seen := map[string]bool{id: true}
+	traversed := map[string]bool{}
+	// Breadth-first, graph.Nodes grows while it is walked.
+	for i := 0; i < len(graph.Nodes) && graph.Nodes[i].Depth < depth; i++ {
+		node := graph.Nodes[i]
+		links, err := s.relationships(ctx, node.Device.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, link := range links {
+			if (len(follow) > 0 && !follow[link.Type]) || traversed[link.ID] {
+				continue
+			}
+			traversed[link.ID] = true
+			graph.Edges = append(graph.Edges, link)
+
+			other := link.Other(node.Device.ID)
+			if seen[other] {
+				continue
+			}
+			seen[other] = true
+			device, err := s.DevicesRepository.FindByID(ctx, &other)
+			if err != nil {
+				return nil, err
+			}
+			if device != nil {
+				graph.Nodes = append(graph.Nodes, model.GraphNode{Device: *device, Depth: node.Depth + 1})
+			}
+		}
+	}
+	span.SetAttributes(attribute.Int("nodes", len(graph.Nodes)), attribute.Int("edges", len(graph.Edges)))
+	return graph, nil
+}
+
+// DeleteDeviceTree deletes the device together with the devices it contains,
+// and the devices those contain, whatever the depth.
+func (s *DeviceService) DeleteDeviceTree(ctx context.Context, id string) error {
+	ctx, span := s.startSpan(ctx, "DeleteDeviceTree", attribute.String("device.id", id))
+	defer span.End()
+
+	if err := s.authorize(ctx, auth.PermissionDevicesDelete); err != nil {
+		return err
+	}
+
+	// Breadth-first, tree grows while it is walked. Containment is acyclic, so
+	// every device is reached once.
+	tree := []string{id}
+	for i := 0; i < len(tree); i++ {
+		links, err := s.relationships(ctx, tree[i])
+		if err != nil {
+			return err
+		}
+		for _, link := range links {
+			if link.FromID == tree[i] && model.RelationRules[link.Type].OnDelete == model.DeleteRestrict {
+				tree = append(tree, link.ToID)
+			}
+		}
+	}
+	span.SetAttributes(attribute.Int("devices", len(tree)))
+
+	// Innermost devices first, so that no device is deleted while it still
+	// contains others.
+	for i := len(tree) - 1; i >= 0; i-- {
+		if err := s.deleteDevice(ctx, tree[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkDeleteRules fails when the device is the source of relationships which
+// restrict its deletion.
+func (s *DeviceService) checkDeleteRules(ctx context.Context, id string) error {
+	links, err := s.relationships(ctx, id)
+	if err != nil {
+		return err
+	}
+	restricting := 0
+	for _, link := range links {
+		if link.FromID == id && model.RelationRules[link.Type].OnDelete == model.DeleteRestrict {
+			restricting++
+		}
+	}
+	if restricting > 0 {
+		return fmt.Errorf("%w: it contains %d devices", model.ErrDeviceHasChildren, restricting)
+	}
+	return nil
+}
+
+// checkLink enforces the model.RelationRules of the type on a new
+// relationship from fromID to toID, ignoring the relationships of except.
+func (s *DeviceService) checkLink(ctx context.Context, fromID string, toID string, relationType model.RelationType, except ...string) error {
+	rule := model.RelationRules[relationType]
+	links, err := s.Relationships.FindByDevice(ctx, toID)
+	if err != nil {
+		return err
+	}
+	for _, link := range links {
+		if link.Type != relationType || slices.Contains(except, link.ID) {
+			continue
+		}
+		if link.FromID == fromID || (!rule.Directed && link.ToID == fromID) {
+			return fmt.Errorf("%w: the devices are already linked by %s", model.ErrRelationshipConflict, relationType)
+		}
+		if rule.Exclusive && link.ToID == toID {
+			return fmt.Errorf("%w: device %s is already the target of %s from %s", model.ErrRelationshipConflict, toID, relationType, link.FromID)
+		}
+	}
+	if rule.Acyclic {
+		cycle, err := s.reaches(ctx, toID, fromID, relationType)
+		if err != nil {
+			return err
+		}
+		if cycle {
+			return fmt.Errorf("%w: %s from %s leads back to %s", model.ErrRelationshipCycle, relationType, toID, fromID)
+		}
+	}
+	return nil
+}
+
+// detachRelationships removes every relationship of a deleted device.
+func (s *DeviceService) detachRelationships(ctx context.Context, id string) error {
+	links, err := s.relationships(ctx, id)
+	if err != nil {
+		return err
+	}
+	for _, link := range links {
+		if err := s.Relationships.Delete(ctx, link.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *DeviceService) relationships(ctx context.Context, id string) ([]model.Relationship, error) {
+	if s.Relationships == nil {
+		return []model.Relationship{}, nil
+	}
+	links, err := s.Relationships.FindByDevice(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(links, func(i, j int) bool {
+		if links[i].CreatedAt.Equal(links[j].CreatedAt) {
+			return links[i].ID < links[j].ID
+		}
+		return links[i].CreatedAt.Before(links[j].CreatedAt)
+	})
+	return links, nil
+}
+
+// reaches reports whether to can be reached from from by following
+// relationships of the type from their source to their target.
+func (s *DeviceService) reaches(ctx context.Context, from string, to string, relationType model.RelationType) (bool, error) {
+	visited := map[string]bool{from: true}
+	queue := []string{from}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if current == to {
+			return true, nil
+		}
+		links, err := s.relationships(ctx, current)
+		if err != nil {
+			return false, err
+		}
+		for _, link := range links {
+			if link.Type == relationType && link.FromID == current && !visited[link.ToID] {
+				visited[link.ToID] = true
+				queue = append(queue, link.ToID)
+			}
+		}
+	}
+	return false, nil
+}
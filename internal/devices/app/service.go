@@ -2,66 +2,571 @@ package app
 
 import (
 	"context"
+	"devices_crud/internal/auth"
 	"devices_crud/internal/devices/app/ports"
 	"devices_crud/internal/devices/model"
-	"log"
+	"devices_crud/internal/filter"
+	"devices_crud/internal/labels"
+	"devices_crud/internal/logging"
+	"devices_crud/internal/search"
+	"devices_crud/internal/tenancy"
+	"devices_crud/internal/tracing"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type DeviceService struct {
 	DevicesRepository ports.DevicesRepository
-	Logger            *log.Logger
+	Logger            *slog.Logger
+	// Authorizer checks the permissions of the principal in the context of every
+	// call. A nil Authorizer allows everything.
+	Authorizer *auth.Authorizer
+	// Quotas limits the number of devices each tenant may create. A nil Quotas
+	// means no limits.
+	Quotas *tenancy.Quotas
+	// Index answers SearchDevices. Without an index the repository's own search
+	// is used and results carry no score.
+	Index *search.Index
+	// Tracer wraps every call in a span. NewDeviceService sets a no-op tracer.
+	Tracer trace.Tracer
+	// DeviceTypes holds the schemas of device attributes. Without it only
+	// untyped devices can be stored.
+	DeviceTypes ports.DeviceTypesRepository
+	// Assignments records who holds which device. Without it devices cannot be
+	// checked out.
+	Assignments ports.AssignmentsRepository
+	// Locations holds the tree of sites, buildings, rooms and racks devices are
+	// placed in.
+	Locations ports.LocationsRepository
+	// Brands is the catalog device brands are normalized against. Without it
+	// brands are only trimmed.
+	Brands ports.BrandsRepository
+	// Relationships links devices to each other, see model.RelationRules.
+	Relationships ports.RelationshipsRepository
+	// Maintenance holds the maintenance plans and the service history of
+	// devices.
+	Maintenance ports.MaintenanceRepository
+	// Contracts holds the warranty and support contracts of devices.
+	Contracts ports.ContractsRepository
+	// Telemetry holds the readings devices report, e.g. their battery level.
+	Telemetry ports.TelemetryRepository
+	// Software holds the software installed on devices and the policies it
+	// must comply with.
+	Software ports.SoftwareRepository
+	// Connectivity decides when devices which stopped sending heartbeats are
+	// offline.
+	Connectivity model.ConnectivityPolicy
+	// UniqueKeys, e.g. brand and serial number, are enforced whenever devices
+	// are created, replaced or patched.
+	UniqueKeys []model.UniquenessKey
+	// Redirects leads the IDs of merged devices to the device they were merged
+	// into. Without it devices cannot be merged.
+	Redirects ports.RedirectsRepository
+
+	transitionHooks   []transitionHook
+	expiryHooks       []ContractExpiryHook
+	connectivityHooks []ConnectivityHook
+	// connectivity is the last connectivity observed per device ID.
+	connectivity      map[string]model.Connectivity
+	connectivityMutex sync.Mutex
 }
 
-func NewDeviceService(devicesRepository ports.DevicesRepository, logger *log.Logger) *DeviceService {
+func NewDeviceService(devicesRepository ports.DevicesRepository, logger *slog.Logger) *DeviceService {
 	return &DeviceService{
 		DevicesRepository: devicesRepository,
 		Logger:            logger,
+		Tracer:            tracing.Tracer(nil, "service"),
+	}
+}
+
+func (s *DeviceService) startSpan(ctx context.Context, method string, attributes ...attribute.KeyValue) (context.Context, trace.Span) {
+	tracer := s.Tracer
+	if tracer == nil {
+		tracer = tracing.Tracer(nil, "service")
+	}
+	return tracer.Start(ctx, "DeviceService."+method, trace.WithAttributes(attributes...))
+}
+
+func (s *DeviceService) authorize(ctx context.Context, permission auth.Permission) error {
+	if s.Authorizer == nil {
+		return nil
+	}
+	err := s.Authorizer.Authorize(ctx, permission)
+	if err != nil {
+		s.Logger.WarnContext(ctx, "Permission denied", "permission", string(permission), "error", err)
+	}
+	return err
+}
+
+// create stores a new device within the quota of the tenant of ctx, which the
+// repository enforces.
+func (s *DeviceService) create(ctx context.Context, device *model.Device) (*string, error) {
+	tenantID := tenancy.TenantFromContext(ctx)
+	limit := s.Quotas.Limit(tenantID)
+	id, err := s.DevicesRepository.Save(ctx, device, limit)
+	if errors.Is(err, model.ErrQuotaExceeded) {
+		s.Logger.WarnContext(ctx, "Device quota exceeded", logging.TenantKey, tenantID, "limit", limit)
+		return nil, err
+	}
+	if err != nil {
+		s.Logger.ErrorContext(ctx, "Error saving device", logging.DeviceIDKey, device.ID, "error", err)
 	}
+	return id, err
 }
 
 func (s *DeviceService) AddDevice(ctx context.Context, device *model.NewDeviceRequest) (*string, error) {
+	ctx, span := s.startSpan(ctx, "AddDevice")
+	defer span.End()
+
+	if err := s.authorize(ctx, auth.PermissionDevicesWrite); err != nil {
+		return nil, err
+	}
+
+	attributes, err := s.validateAttributes(ctx, device.Type, device.Attributes)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateLabels(device.Labels); err != nil {
+		return nil, err
+	}
+	createdAt := time.Now()
+	status, history, err := initialStatus(ctx, device.Status, createdAt)
+	if err != nil {
+		return nil, err
+	}
+	locationHistory, err := s.initialLocation(ctx, device.LocationID, createdAt)
+	if err != nil {
+		return nil, err
+	}
+	brand, err := s.normalizeBrand(ctx, device.DeviceBrand)
+	if err != nil {
+		return nil, err
+	}
+
 	newDevice := &model.Device{
-		ID:          uuid.New().String(),
-		Name:        device.Name,
-		DeviceBrand: device.DeviceBrand,
-		CreatedAt:   time.Now(),
+		ID:              uuid.New().String(),
+		Name:            device.Name,
+		DeviceBrand:     brand,
+		CreatedAt:       createdAt,
+		TenantID:        tenancy.TenantFromContext(ctx),
+		Type:            device.Type,
+		Attributes:      attributes,
+		Labels:          device.Labels,
+		Status:          status,
+		StatusHistory:   history,
+		LocationID:      device.LocationID,
+		LocationHistory: locationHistory,
+	}
+	if err := s.checkUnique(ctx, newDevice); err != nil {
+		return nil, err
 	}
 
-	id, err := s.DevicesRepository.Save(ctx, newDevice)
+	id, err := s.create(ctx, newDevice)
 	if err != nil {
 		return nil, err
 	}
 
+	s.Logger.InfoContext(ctx, "Device added", logging.DeviceIDKey, newDevice.ID)
 	return id, nil
 }
 
 func (s *DeviceService) GetDevice(ctx context.Context, id string) (*model.Device, error) {
+	ctx, span := s.startSpan(ctx, "GetDevice", attribute.String("device.id", id))
+	defer span.End()
+
+	if err := s.authorize(ctx, auth.PermissionDevicesRead); err != nil {
+		return nil, err
+	}
 	return s.DevicesRepository.FindByID(ctx, &id)
 }
 
 func (s *DeviceService) GetAllDevices(ctx context.Context) ([]model.Device, error) {
+	ctx, span := s.startSpan(ctx, "GetAllDevices")
+	defer span.End()
+
+	if err := s.authorize(ctx, auth.PermissionDevicesRead); err != nil {
+		return nil, err
+	}
 	return s.DevicesRepository.FindAll(ctx)
 }
 
+// ListDevices returns one page of the devices matching the filter of the request,
+// ordered by creation time, together with the total number of matches. A zero
+// limit returns everything from the offset on.
+func (s *DeviceService) ListDevices(ctx context.Context, request *model.ListDevicesRequest) ([]model.Device, int, error) {
+	ctx, span := s.startSpan(ctx, "ListDevices")
+	defer span.End()
+
+	if err := s.authorize(ctx, auth.PermissionDevicesRead); err != nil {
+		return nil, 0, err
+	}
+
+	devices, err := s.findDevices(ctx, request.Filter, request.Selector)
+	if err != nil {
+		return nil, 0, err
+	}
+	if request.Connectivity != "" {
+		if _, err := model.ParseConnectivity(string(request.Connectivity)); err != nil {
+			return nil, 0, err
+		}
+		devices = s.filterConnectivity(devices, request.Connectivity, time.Now())
+	}
+
+	sortByCreation(devices)
+
+	total := len(devices)
+	start := min(request.Offset, total)
+	end := total
+	if request.Limit > 0 {
+		end = min(start+request.Limit, total)
+	}
+
+	return devices[start:end], total, nil
+}
+
+func (s *DeviceService) findDevices(ctx context.Context, expression string, selector string) ([]model.Device, error) {
+	if selector != "" {
+		parsed, err := ParseSelector(selector)
+		if err != nil {
+			return nil, err
+		}
+		matches := func(*model.Device) bool { return true }
+		if expression != "" {
+			expr, err := ParseDeviceFilter(expression)
+			if err != nil {
+				return nil, err
+			}
+			predicate, _ := filter.Compile(expr, model.DeviceFilterSchema)
+			matches = func(device *model.Device) bool { return predicate(device) }
+		}
+
+		devices, err := s.DevicesRepository.FindByLabels(ctx, parsed)
+		if err != nil {
+			return nil, err
+		}
+		selected := devices[:0]
+		for i := range devices {
+			if matches(&devices[i]) {
+				selected = append(selected, devices[i])
+			}
+		}
+		return selected, nil
+	}
+
+	if expression == "" {
+		return s.DevicesRepository.FindAll(ctx)
+	}
+
+	expr, err := ParseDeviceFilter(expression)
+	if err != nil {
+		return nil, err
+	}
+	return s.DevicesRepository.FindByFilter(ctx, expr)
+}
+
+// ParseSelector parses a label selector. Errors wrap model.ErrInvalidSelector
+// and *labels.SyntaxError.
+func ParseSelector(selector string) (labels.Selector, error) {
+	parsed, err := labels.Parse(selector)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", model.ErrInvalidSelector, err)
+	}
+	return parsed, nil
+}
+
+// ParseDeviceFilter parses a filter expression over devices and checks its
+// fields and values. Errors wrap model.ErrInvalidFilter and *filter.SyntaxError.
+func ParseDeviceFilter(expression string) (filter.Expr, error) {
+	expr, err := filter.Parse(expression)
+	if err == nil {
+		_, err = filter.Compile(expr, model.DeviceFilterSchema)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", model.ErrInvalidFilter, err)
+	}
+	return expr, nil
+}
+
 func (s *DeviceService) ReplaceDevice(ctx context.Context, device *model.Device) (*model.Device, error) {
-	return s.DevicesRepository.Replace(ctx, device)
+	ctx, span := s.startSpan(ctx, "ReplaceDevice", attribute.String("device.id", device.ID))
+	defer span.End()
+
+	if err := s.authorize(ctx, auth.PermissionDevicesWrite); err != nil {
+		return nil, err
+	}
+
+	// Replacing an unknown ID creates the device, which counts against the quota.
+	// The status and location of existing devices only change through
+	// TransitionDevice and MoveDevice.
+	existing, err := s.DevicesRepository.FindByID(ctx, &device.ID)
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		device.LastSeenAt = nil
+		device.Status, device.StatusHistory, err = initialStatus(ctx, device.Status, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		device.LocationHistory, err = s.initialLocation(ctx, device.LocationID, time.Now())
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		device.Status = existing.Status
+		device.StatusHistory = existing.StatusHistory
+		device.LocationID = existing.LocationID
+		device.LocationHistory = existing.LocationHistory
+		device.LastSeenAt = existing.LastSeenAt
+	}
+
+	device.Attributes, err = s.validateAttributes(ctx, device.Type, device.Attributes)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateLabels(device.Labels); err != nil {
+		return nil, err
+	}
+	if device.DeviceBrand, err = s.normalizeBrand(ctx, device.DeviceBrand); err != nil {
+		return nil, err
+	}
+
+	device.TenantID = tenancy.TenantFromContext(ctx)
+	if err := s.checkUnique(ctx, device, device.ID); err != nil {
+		return nil, err
+	}
+	replaced := device
+	if existing == nil {
+		_, err = s.create(ctx, device)
+	} else if replaced, err = s.DevicesRepository.Replace(ctx, device); err != nil {
+		s.Logger.ErrorContext(ctx, "Error replacing device", logging.DeviceIDKey, device.ID, "error", err)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s.Logger.InfoContext(ctx, "Device replaced", logging.DeviceIDKey, device.ID, "created", existing == nil)
+	return replaced, nil
 }
 
 func (s *DeviceService) PatchDevice(ctx context.Context, device *model.PatchDeviceRequest) (*string, error) {
+	ctx, span := s.startSpan(ctx, "PatchDevice", attribute.String("device.id", device.ID))
+	defer span.End()
+
+	if err := s.authorize(ctx, auth.PermissionDevicesWrite); err != nil {
+		return nil, err
+	}
+
+	for key, value := range device.Labels {
+		if err := labels.ValidateKey(key); err != nil {
+			return nil, fmt.Errorf("%w: %w", model.ErrInvalidLabels, err)
+		}
+		if value != nil {
+			if err := labels.ValidateValue(*value); err != nil {
+				return nil, fmt.Errorf("%w: %w", model.ErrInvalidLabels, err)
+			}
+		}
+	}
+	if device.DeviceBrand != nil {
+		brand, err := s.normalizeBrand(ctx, *device.DeviceBrand)
+		if err != nil {
+			return nil, err
+		}
+		device.DeviceBrand = &brand
+	}
+	if device.Type != nil || device.Attributes != nil || len(s.UniqueKeys) > 0 {
+		existing, err := s.DevicesRepository.FindByID(ctx, &device.ID)
+		if err != nil || existing == nil {
+			return nil, err
+		}
+		if device.Type != nil || device.Attributes != nil {
+			if err := s.validatePatch(ctx, existing, device); err != nil {
+				return nil, err
+			}
+		}
+		if err := s.checkUnique(ctx, patchedDevice(existing, device), device.ID); err != nil {
+			return nil, err
+		}
+	}
+
 	id, err := s.DevicesRepository.Patch(ctx, device)
 	if err != nil {
+		s.Logger.ErrorContext(ctx, "Error patching device", logging.DeviceIDKey, device.ID, "error", err)
 		return nil, err
 	}
+	if id != nil {
+		s.Logger.InfoContext(ctx, "Device patched", logging.DeviceIDKey, device.ID)
+	}
 	return id, nil
 }
 
+// SetLabels adds labels to a device, replacing the values of existing keys. It
+// returns the updated device, or nil when there is no device with that ID.
+func (s *DeviceService) SetLabels(ctx context.Context, id string, values map[string]string) (*model.Device, error) {
+	patch := make(map[string]*string, len(values))
+	for key, value := range values {
+		value := value
+		patch[key] = &value
+	}
+	return s.patchLabels(ctx, id, patch)
+}
+
+// RemoveLabels removes labels from a device, keys it does not carry are
+// ignored. It returns the updated device, or nil when there is no device with
+// that ID.
+func (s *DeviceService) RemoveLabels(ctx context.Context, id string, keys []string) (*model.Device, error) {
+	patch := make(map[string]*string, len(keys))
+	for _, key := range keys {
+		patch[key] = nil
+	}
+	return s.patchLabels(ctx, id, patch)
+}
+
+func (s *DeviceService) patchLabels(ctx context.Context, id string, patch map[string]*string) (*model.Device, error) {
+	if len(patch) == 0 {
+		return nil, fmt.Errorf("%w: no labels given", model.ErrInvalidLabels)
+	}
+	patched, err := s.PatchDevice(ctx, &model.PatchDeviceRequest{ID: id, Labels: patch})
+	if err != nil || patched == nil {
+		return nil, err
+	}
+	return s.DevicesRepository.FindByID(ctx, &id)
+}
+
+func validateLabels(values map[string]string) error {
+	if err := labels.Validate(values); err != nil {
+		return fmt.Errorf("%w: %w", model.ErrInvalidLabels, err)
+	}
+	return nil
+}
+
+// patchedDevice returns the device as it would be after the patch.
+func patchedDevice(existing *model.Device, patch *model.PatchDeviceRequest) *model.Device {
+	patched := *existing
+	if patch.Name != nil {
+		patched.Name = *patch.Name
+	}
+	if patch.DeviceBrand != nil {
+		patched.DeviceBrand = *patch.DeviceBrand
+	}
+	if patch.Type != nil {
+		patched.Type = *patch.Type
+	}
+	patched.Attributes = existing.Attributes.Merge(patch.Attributes)
+	patched.Labels = model.MergeLabels(existing.Labels, patch.Labels)
+	return &patched
+}
+
+// validatePatch checks the attributes the device would have after the patch.
+func (s *DeviceService) validatePatch(ctx context.Context, existing *model.Device, patch *model.PatchDeviceRequest) error {
+	normalized, err := patch.Attributes.Normalize()
+	if err != nil {
+		return err
+	}
+	patch.Attributes = normalized
+
+	typeName := existing.Type
+	if patch.Type != nil {
+		typeName = *patch.Type
+	}
+	_, err = s.validateAttributes(ctx, typeName, existing.Attributes.Merge(patch.Attributes))
+	return err
+}
+
 func (s *DeviceService) DeleteDevice(ctx context.Context, id string) error {
-	return s.DevicesRepository.Delete(ctx, id)
+	ctx, span := s.startSpan(ctx, "DeleteDevice", attribute.String("device.id", id))
+	defer span.End()
+
+	if err := s.authorize(ctx, auth.PermissionDevicesDelete); err != nil {
+		return err
+	}
+	if err := s.checkDeleteRules(ctx, id); err != nil {
+		return err
+	}
+	return s.deleteDevice(ctx, id)
 }
 
-func (s *DeviceService) SearchDevices(ctx context.Context, query string) ([]model.Device, error) {
-	return s.DevicesRepository.Search(ctx, query)
+// deleteDevice deletes the device and the relationships it is part of.
+func (s *DeviceService) deleteDevice(ctx context.Context, id string) error {
+	if err := s.DevicesRepository.Delete(ctx, id); err != nil {
+		s.Logger.ErrorContext(ctx, "Error deleting device", logging.DeviceIDKey, id, "error", err)
+		return err
+	}
+	if err := s.detachRelationships(ctx, id); err != nil {
+		s.Logger.ErrorContext(ctx, "Error detaching device", logging.DeviceIDKey, id, "error", err)
+		return err
+	}
+	if err := s.dropMaintenancePlans(ctx, id); err != nil {
+		s.Logger.ErrorContext(ctx, "Error deleting maintenance plans", logging.DeviceIDKey, id, "error", err)
+		return err
+	}
+	if err := s.dropContracts(ctx, id); err != nil {
+		s.Logger.ErrorContext(ctx, "Error deleting contracts", logging.DeviceIDKey, id, "error", err)
+		return err
+	}
+	if err := s.dropReadings(ctx, id); err != nil {
+		s.Logger.ErrorContext(ctx, "Error deleting readings", logging.DeviceIDKey, id, "error", err)
+		return err
+	}
+	if err := s.dropSoftware(ctx, id); err != nil {
+		s.Logger.ErrorContext(ctx, "Error deleting software", logging.DeviceIDKey, id, "error", err)
+		return err
+	}
+	if err := s.dropRedirects(ctx, id); err != nil {
+		s.Logger.ErrorContext(ctx, "Error deleting redirects", logging.DeviceIDKey, id, "error", err)
+		return err
+	}
+	s.forgetConnectivity(id)
+
+	s.Logger.InfoContext(ctx, "Device deleted", logging.DeviceIDKey, id)
+	return nil
+}
+
+func (s *DeviceService) SearchDevices(ctx context.Context, query string) ([]model.SearchResult, error) {
+	ctx, span := s.startSpan(ctx, "SearchDevices")
+	defer span.End()
+
+	if err := s.authorize(ctx, auth.PermissionDevicesRead); err != nil {
+		return nil, err
+	}
+
+	if s.Index == nil {
+		devices, err := s.DevicesRepository.Search(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+		results := make([]model.SearchResult, len(devices))
+		for i := range devices {
+			results[i] = model.SearchResult{Device: devices[i]}
+		}
+		return results, nil
+	}
+
+	tenant := tenancy.TenantFromContext(ctx)
+	if tenancy.AllTenants(ctx) {
+		tenant = ""
+	}
+
+	hits := s.Index.Search(query, tenant)
+	results := make([]model.SearchResult, 0, len(hits))
+	for _, hit := range hits {
+		// The repository stays the source of truth, the index may briefly lag
+		// behind a concurrent delete.
+		device, err := s.DevicesRepository.FindByID(ctx, &hit.ID)
+		if err != nil {
+			return nil, err
+		}
+		if device != nil {
+			results = append(results, model.SearchResult{Device: *device, Score: hit.Score})
+		}
+	}
+	span.SetAttributes(attribute.Int("search.hits", len(results)))
+	return results, nil
 }
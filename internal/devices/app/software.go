@@ -0,0 +1,336 @@
+package app
+
+import (
+	"context"
+	"devices_crud/internal/auth"
+	"devices_crud/internal/devices/model"
+	"devices_crud/internal/logging"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// RecordSoftware records the version of a software running on a device,
+// replacing the version recorded before for the same kind and name. It returns
+// nil when there is no device with that ID.
+func (s *DeviceService) RecordSoftware(ctx context.Context, deviceID string, request *model.SoftwareRequest) (*model.InstalledSoftware, error) {
+	ctx, span := s.startSpan(ctx, "RecordSoftware", attribute.String("device.id", deviceID), attribute.String("software.kind", string(request.Kind)))
+	defer span.End()
+
+	if err := s.authorize(ctx, auth.PermissionDevicesWrite); err != nil {
+		return nil, err
+	}
+	if s.Software == nil {
+		return nil, fmt.Errorf("%w: software is not supported", model.ErrInvalidSoftware)
+	}
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+
+	device, err := s.DevicesRepository.FindByID(ctx, &deviceID)
+	if err != nil || device == nil {
+		return nil, err
+	}
+	installed, err := s.Software.FindSoftwareByDevice(ctx, deviceID)
+	if err != nil {
+		return nil, err
+	}
+	software := &model.InstalledSoftware{ID: uuid.New().String(), DeviceID: deviceID, Kind: request.Kind}
+	previous := ""
+	for i := range installed {
+		if installed[i].Is(request.Kind, request.Name) {
+			software = &installed[i]
+			previous = software.Version
+			break
+		}
+	}
+	software.Name = request.Name
+	software.Version = request.Version
+	software.InstalledAt = time.Now()
+	if request.InstalledAt != nil {
+		software.InstalledAt = *request.InstalledAt
+	}
+	software.CreatedBy = actor(ctx)
+
+	if err := s.Software.SaveSoftware(ctx, software); err != nil {
+		s.Logger.ErrorContext(ctx, "Error recording software", logging.DeviceIDKey, deviceID, "error", err)
+		return nil, err
+	}
+	s.Logger.InfoContext(ctx, "Software recorded", logging.DeviceIDKey, deviceID, "kind", software.Kind, "software", software.Name,
+		"version", software.Version, "previous_version", previous)
+	return software, nil
+}
+
+// DeleteSoftware removes a software of the device and returns it, nil when
+// the device has no software with that ID.
+func (s *DeviceService) DeleteSoftware(ctx context.Context, deviceID string, softwareID string) (*model.InstalledSoftware, error) {
+	ctx, span := s.startSpan(ctx, "DeleteSoftware", attribute.String("device.id", deviceID), attribute.String("software.id", softwareID))
+	defer span.End()
+
+	if err := s.authorize(ctx, auth.PermissionDevicesWrite); err != nil {
+		return nil, err
+	}
+	if s.Software == nil {
+		return nil, nil
+	}
+	software, err := s.Software.FindSoftware(ctx, softwareID)
+	if err != nil || software == nil || software.DeviceID != deviceID {
+		return nil, err
+	}
+
+	if err := s.Software.DeleteSoftware(ctx, softwareID); err != nil {
+		s.Logger.ErrorContext(ctx, "Error deleting software", logging.DeviceIDKey, deviceID, "error", err)
+		return nil, err
+	}
+	s.Logger.InfoContext(ctx, "Software deleted", logging.DeviceIDKey, deviceID, "software_id", softwareID)
+	return software, nil
+}
+
+// DeviceSoftware returns the software installed on a device, sorted by kind
+// and name.
+func (s *DeviceService) DeviceSoftware(ctx context.Context, deviceID string) ([]model.InstalledSoftware, error) {
+	ctx, span := s.startSpan(ctx, "DeviceSoftware", attribute.String("device.id", deviceID))
+	defer span.End()
+
+	if err := s.authorize(ctx, auth.PermissionDevicesRead); err != nil {
+		return nil, err
+	}
+	if s.Software == nil {
+		return []model.InstalledSoftware{}, nil
+	}
+	software, err := s.Software.FindSoftwareByDevice(ctx, deviceID)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(software, func(i, j int) bool {
+		if software[i].Kind != software[j].Kind {
+			return software[i].Kind < software[j].Kind
+		}
+		return software[i].Name < software[j].Name
+	})
+	return software, nil
+}
+
+// CreateSoftwarePolicy adds a policy devices must comply with. Its brand is
+// stored under its catalog name.
+func (s *DeviceService) CreateSoftwarePolicy(ctx context.Context, request *model.SoftwarePolicyRequest) (*model.SoftwarePolicy, error) {
+	ctx, span := s.startSpan(ctx, "CreateSoftwarePolicy", attribute.String("software.kind", string(request.Kind)))
+	defer span.End()
+
+	if err := s.authorize(ctx, auth.PermissionDevicesAdmin); err != nil {
+		return nil, err
+	}
+	if s.Software == nil {
+		return nil, fmt.Errorf("%w: software is not supported", model.ErrInvalidSoftwarePolicy)
+	}
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+	brand := request.Brand
+	if brand != "" {
+		var err error
+		if brand, err = s.normalizeBrand(ctx, brand); err != nil {
+			return nil, err
+		}
+	}
+
+	policy := &model.SoftwarePolicy{
+		ID:          uuid.New().String(),
+		Description: request.Description,
+		Brand:       brand,
+		Type:        request.Type,
+		Kind:        request.Kind,
+		Software:    request.Software,
+		MinVersion:  request.MinVersion,
+		CreatedAt:   time.Now(),
+		CreatedBy:   actor(ctx),
+	}
+	if err := s.Software.SavePolicy(ctx, policy); err != nil {
+		s.Logger.ErrorContext(ctx, "Error creating software policy", "error", err)
+		return nil, err
+	}
+	s.Logger.InfoContext(ctx, "Software policy created", "policy_id", policy.ID, "brand", policy.Brand, "kind", policy.Kind,
+		"software", policy.Software, "min_version", policy.MinVersion)
+	return policy, nil
+}
+
+// SoftwarePolicies returns the policies, the oldest first.
+func (s *DeviceService) SoftwarePolicies(ctx context.Context) ([]model.SoftwarePolicy, error) {
+	ctx, span := s.startSpan(ctx, "SoftwarePolicies")
+	defer span.End()
+
+	if err := s.authorize(ctx, auth.PermissionDevicesRead); err != nil {
+		return nil, err
+	}
+	if s.Software == nil {
+		return []model.SoftwarePolicy{}, nil
+	}
+	return s.softwarePolicies(ctx)
+}
+
+// DeleteSoftwarePolicy removes a policy and returns it, nil when there is no
+// policy with that ID.
+func (s *DeviceService) DeleteSoftwarePolicy(ctx context.Context, id string) (*model.SoftwarePolicy, error) {
+	ctx, span := s.startSpan(ctx, "DeleteSoftwarePolicy", attribute.String("policy.id", id))
+	defer span.End()
+
+	if err := s.authorize(ctx, auth.PermissionDevicesAdmin); err != nil {
+		return nil, err
+	}
+	if s.Software == nil {
+		return nil, nil
+	}
+	policy, err := s.Software.FindPolicy(ctx, id)
+	if err != nil || policy == nil {
+		return nil, err
+	}
+
+	if err := s.Software.DeletePolicy(ctx, id); err != nil {
+		s.Logger.ErrorContext(ctx, "Error deleting software policy", "policy_id", id, "error", err)
+		return nil, err
+	}
+	s.Logger.InfoContext(ctx, "Software policy deleted", "policy_id", id)
+	return policy, nil
+}
+
+// DeviceCompliance checks the software of a device against the policies which
+// apply to it. It returns nil when there is no device with that ID.
+func (s *DeviceService) DeviceCompliance(ctx context.Context, deviceID string) (*model.DeviceCompliance, error) {
+	ctx, span := s.startSpan(ctx, "DeviceCompliance", attribute.String("device.id", deviceID))
+	defer span.End()
+
+	if err := s.authorize(ctx, auth.PermissionDevicesRead); err != nil {
+		return nil, err
+	}
+	device, err := s.DevicesRepository.FindByID(ctx, &deviceID)
+	if err != nil || device == nil {
+		return nil, err
+	}
+	if s.Software == nil {
+		return &model.DeviceCompliance{Device: *device, Compliant: true, Violations: []model.PolicyViolation{}}, nil
+	}
+	policies, err := s.softwarePolicies(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.deviceCompliance(ctx, device, policies)
+}
+
+// ComplianceReport checks every device against the policies, or against the
+// one with policyID if given, and lists the ones violating them by name. It
+// returns nil when there is no policy with policyID.
+func (s *DeviceService) ComplianceReport(ctx context.Context, policyID string) (*model.ComplianceReport, error) {
+	ctx, span := s.startSpan(ctx, "ComplianceReport", attribute.String("policy.id", policyID))
+	defer span.End()
+
+	if err := s.authorize(ctx, auth.PermissionDevicesRead); err != nil {
+		return nil, err
+	}
+	report := &model.ComplianceReport{CheckedAt: time.Now(), Violators: []model.DeviceCompliance{}}
+	if s.Software == nil {
+		if policyID != "" {
+			return nil, nil
+		}
+		return report, nil
+	}
+
+	var policies []model.SoftwarePolicy
+	if policyID != "" {
+		policy, err := s.Software.FindPolicy(ctx, policyID)
+		if err != nil || policy == nil {
+			return nil, err
+		}
+		policies = []model.SoftwarePolicy{*policy}
+	} else {
+		var err error
+		if policies, err = s.softwarePolicies(ctx); err != nil {
+			return nil, err
+		}
+	}
+	report.Policies = len(policies)
+	if len(policies) == 0 {
+		return report, nil
+	}
+
+	devices, err := s.DevicesRepository.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range devices {
+		compliance, err := s.deviceCompliance(ctx, &devices[i], policies)
+		if err != nil {
+			return nil, err
+		}
+		if compliance.Policies == 0 {
+			continue
+		}
+		report.Devices++
+		if compliance.Compliant {
+			report.Compliant++
+		} else {
+			report.Violators = append(report.Violators, *compliance)
+		}
+	}
+	sort.Slice(report.Violators, func(i, j int) bool {
+		if report.Violators[i].Device.Name != report.Violators[j].Device.Name {
+			return report.Violators[i].Device.Name < report.Violators[j].Device.Name
+		}
+		return report.Violators[i].Device.ID < report.Violators[j].Device.ID
+	})
+	span.SetAttributes(attribute.Int("devices.violators", len(report.Violators)))
+	return report, nil
+}
+
+func (s *DeviceService) softwarePolicies(ctx context.Context) ([]model.SoftwarePolicy, error) {
+	policies, err := s.Software.FindPolicies(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(policies, func(i, j int) bool {
+		if policies[i].CreatedAt.Equal(policies[j].CreatedAt) {
+			return policies[i].ID < policies[j].ID
+		}
+		return policies[i].CreatedAt.Before(policies[j].CreatedAt)
+	})
+	return policies, nil
+}
+
+func (s *DeviceService) deviceCompliance(ctx context.Context, device *model.Device, policies []model.SoftwarePolicy) (*model.DeviceCompliance, error) {
+	compliance := &model.DeviceCompliance{Device: *device, Compliant: true, Violations: []model.PolicyViolation{}}
+	var software []model.InstalledSoftware
+	for i := range policies {
+		if !policies[i].Applies(device) {
+			continue
+		}
+		if software == nil {
+			var err error
+			if software, err = s.Software.FindSoftwareByDevice(ctx, device.ID); err != nil {
+				return nil, err
+			}
+		}
+		compliance.Policies++
+		compliance.Violations = append(compliance.Violations, policies[i].Check(software)...)
+	}
+	compliance.Compliant = len(compliance.Violations) == 0
+	return compliance, nil
+}
+
+// dropSoftware removes the software records of a deleted device.
+func (s *DeviceService) dropSoftware(ctx context.Context, deviceID string) error {
+	if s.Software == nil {
+		return nil
+	}
+	software, err := s.Software.FindSoftwareByDevice(ctx, deviceID)
+	if err != nil {
+		return err
+	}
+	for _, installed := range software {
+		if err := s.Software.DeleteSoftware(ctx, installed.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
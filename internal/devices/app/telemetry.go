@@ -0,0 +1,138 @@
+package app
+
+import (
+	"context"
+	"devices_crud/internal/auth"
+	"devices_crud/internal/devices/model"
+	"devices_crud/internal/logging"
+	"fmt"
+	"sort"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// IngestReadings stores a batch of readings of a device and returns it with
+// the readings cleaned. It returns nil when there is no device with that ID.
+func (s *DeviceService) IngestReadings(ctx context.Context, deviceID string, batch *model.ReadingBatch) (*model.ReadingBatch, error) {
+	ctx, span := s.startSpan(ctx, "IngestReadings", attribute.String("device.id", deviceID), attribute.Int("readings", len(batch.Readings)))
+	defer span.End()
+
+	if err := s.authorize(ctx, auth.PermissionDevicesWrite); err != nil {
+		return nil, err
+	}
+	if s.Telemetry == nil {
+		return nil, fmt.Errorf("%w: telemetry is not supported", model.ErrInvalidReading)
+	}
+	if err := batch.Validate(time.Now()); err != nil {
+		return nil, err
+	}
+	if found, err := s.telemetryDevice(ctx, deviceID); err != nil || !found {
+		return nil, err
+	}
+
+	if err := s.Telemetry.Append(ctx, deviceID, batch.Readings); err != nil {
+		s.Logger.ErrorContext(ctx, "Error ingesting readings", logging.DeviceIDKey, deviceID, "error", err)
+		return nil, err
+	}
+	s.Logger.DebugContext(ctx, "Readings ingested", logging.DeviceIDKey, deviceID, "readings", len(batch.Readings))
+	return batch, nil
+}
+
+// LatestReadings returns the most recent reading of every metric of a device,
+// sorted by metric, nil when there is no device with that ID.
+func (s *DeviceService) LatestReadings(ctx context.Context, deviceID string) ([]model.Reading, error) {
+	ctx, span := s.startSpan(ctx, "LatestReadings", attribute.String("device.id", deviceID))
+	defer span.End()
+
+	if err := s.authorize(ctx, auth.PermissionDevicesRead); err != nil {
+		return nil, err
+	}
+	if found, err := s.telemetryDevice(ctx, deviceID); err != nil || !found {
+		return nil, err
+	}
+	if s.Telemetry == nil {
+		return []model.Reading{}, nil
+	}
+
+	readings, err := s.Telemetry.Latest(ctx, deviceID)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(readings, func(i, j int) bool { return readings[i].Metric < readings[j].Metric })
+	return readings, nil
+}
+
+// Readings returns the readings of a metric of a device taken in [from, to),
+// the oldest first, nil when there is no device with that ID.
+func (s *DeviceService) Readings(ctx context.Context, deviceID string, metric string, from time.Time, to time.Time) ([]model.Reading, error) {
+	ctx, span := s.startSpan(ctx, "Readings", attribute.String("device.id", deviceID), attribute.String("metric", metric))
+	defer span.End()
+
+	if err := s.authorize(ctx, auth.PermissionDevicesRead); err != nil {
+		return nil, err
+	}
+	if err := validateReadingsRange(metric, from, to); err != nil {
+		return nil, err
+	}
+	if found, err := s.telemetryDevice(ctx, deviceID); err != nil || !found {
+		return nil, err
+	}
+	if s.Telemetry == nil {
+		return []model.Reading{}, nil
+	}
+	return s.Telemetry.Range(ctx, deviceID, metric, from, to)
+}
+
+// ReadingAggregates summarizes the readings of a metric of a device taken in
+// [from, to) per step, or over the whole range with a zero step. It returns
+// nil when there is no device with that ID.
+func (s *DeviceService) ReadingAggregates(ctx context.Context, deviceID string, metric string, from time.Time, to time.Time, step time.Duration) ([]model.ReadingAggregate, error) {
+	ctx, span := s.startSpan(ctx, "ReadingAggregates", attribute.String("device.id", deviceID), attribute.String("metric", metric), attribute.String("step", step.String()))
+	defer span.End()
+
+	if err := s.authorize(ctx, auth.PermissionDevicesRead); err != nil {
+		return nil, err
+	}
+	if err := validateReadingsRange(metric, from, to); err != nil {
+		return nil, err
+	}
+	if step < 0 {
+		return nil, fmt.Errorf("%w: step cannot be negative", model.ErrInvalidReading)
+	}
+	if step > 0 && to.Sub(from)/step >= model.MaxAggregateSteps {
+		return nil, fmt.Errorf("%w: at most %d steps per query", model.ErrInvalidReading, model.MaxAggregateSteps)
+	}
+	if found, err := s.telemetryDevice(ctx, deviceID); err != nil || !found {
+		return nil, err
+	}
+	if s.Telemetry == nil {
+		return []model.ReadingAggregate{}, nil
+	}
+	return s.Telemetry.Aggregate(ctx, deviceID, metric, from, to, step)
+}
+
+// telemetryDevice reports whether the device is visible, readings are not
+// scoped to tenants themselves.
+func (s *DeviceService) telemetryDevice(ctx context.Context, deviceID string) (bool, error) {
+	device, err := s.DevicesRepository.FindByID(ctx, &deviceID)
+	return device != nil, err
+}
+
+// dropReadings removes the readings of a deleted device.
+func (s *DeviceService) dropReadings(ctx context.Context, deviceID string) error {
+	if s.Telemetry == nil {
+		return nil
+	}
+	return s.Telemetry.Delete(ctx, deviceID)
+}
+
+func validateReadingsRange(metric string, from time.Time, to time.Time) error {
+	if !model.ValidMetric(metric) {
+		return fmt.Errorf("%w: invalid metric %q", model.ErrInvalidReading, metric)
+	}
+	if !from.Before(to) {
+		return fmt.Errorf("%w: from must be before to", model.ErrInvalidReading)
+	}
+	return nil
+}
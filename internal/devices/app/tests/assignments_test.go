@@ -0,0 +1,152 @@
+package tests
+
+import (
+	"context"
+	"devices_crud/internal/devices/app"
+	"devices_crud/internal/devices/app/ports"
+	"devices_crud/internal/devices/model"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getAssignmentService(t *testing.T) (*app.DeviceService, string) {
+	deviceService := getDeviceService()
+	deviceService.Assignments = ports.NewAssignmentsRepositoryMock()
+	id, err := deviceService.AddDevice(context.Background(), &model.NewDeviceRequest{Name: "pixel", DeviceBrand: "Google", Status: model.StatusInService})
+	assert.Nil(t, err)
+	return deviceService, *id
+}
+
+func TestShouldCheckOutAndInDevice(t *testing.T) {
+	deviceService, id := getAssignmentService(t)
+	ctx := context.Background()
+	due := time.Now().Add(24 * time.Hour)
+
+	assignment, err := deviceService.CheckOutDevice(ctx, id, &model.CheckOutRequest{Assignee: " alice ", DueAt: &due})
+	assert.Nil(t, err)
+	assert.Equal(t, "alice", assignment.Assignee)
+	assert.True(t, assignment.Active())
+
+	_, err = deviceService.CheckOutDevice(ctx, id, &model.CheckOutRequest{Assignee: "bob"})
+	assert.True(t, errors.Is(err, model.ErrDeviceAssigned))
+
+	current, _ := deviceService.CurrentAssignment(ctx, id)
+	assert.Equal(t, assignment.ID, current.ID)
+
+	returned, err := deviceService.CheckInDevice(ctx, id, &model.CheckInRequest{Note: "scratched"})
+	assert.Nil(t, err)
+	assert.False(t, returned.Active())
+	assert.Equal(t, "scratched", returned.Note)
+
+	_, err = deviceService.CheckInDevice(ctx, id, &model.CheckInRequest{})
+	assert.True(t, errors.Is(err, model.ErrDeviceNotAssigned))
+	current, _ = deviceService.CurrentAssignment(ctx, id)
+	assert.Nil(t, current)
+}
+
+func TestShouldCheckOutDeviceOnceConcurrently(t *testing.T) {
+	deviceService, id := getAssignmentService(t)
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	errs := make([]error, 20)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = deviceService.CheckOutDevice(ctx, id, &model.CheckOutRequest{Assignee: fmt.Sprintf("user_%d", i)})
+		}(i)
+	}
+	wg.Wait()
+
+	succeeded := 0
+	for _, err := range errs {
+		if err == nil {
+			succeeded++
+		} else {
+			assert.True(t, errors.Is(err, model.ErrDeviceAssigned), err)
+		}
+	}
+	assert.Equal(t, 1, succeeded)
+	assignments, _ := deviceService.DeviceAssignments(ctx, id)
+	assert.Len(t, assignments, 1)
+}
+
+func TestShouldValidateCheckOut(t *testing.T) {
+	deviceService, id := getAssignmentService(t)
+	ctx := context.Background()
+	past := time.Now().Add(-time.Hour)
+
+	_, err := deviceService.CheckOutDevice(ctx, id, &model.CheckOutRequest{Assignee: " "})
+	assert.True(t, errors.Is(err, model.ErrInvalidAssignment))
+	_, err = deviceService.CheckOutDevice(ctx, id, &model.CheckOutRequest{Assignee: "alice", DueAt: &past})
+	assert.True(t, errors.Is(err, model.ErrInvalidAssignment))
+
+	deviceService.TransitionDevice(ctx, id, &model.TransitionRequest{Status: model.StatusInRepair, Reason: "broken"})
+	_, err = deviceService.CheckOutDevice(ctx, id, &model.CheckOutRequest{Assignee: "alice"})
+	assert.True(t, errors.Is(err, model.ErrDeviceUnavailable))
+
+	missing, err := deviceService.CheckOutDevice(ctx, "missing", &model.CheckOutRequest{Assignee: "alice"})
+	assert.Nil(t, err)
+	assert.Nil(t, missing)
+}
+
+func TestShouldTransferDeviceAndKeepHistory(t *testing.T) {
+	deviceService, id := getAssignmentService(t)
+	ctx := context.Background()
+
+	_, err := deviceService.TransferDevice(ctx, id, &model.CheckOutRequest{Assignee: "bob"})
+	assert.True(t, errors.Is(err, model.ErrDeviceNotAssigned))
+
+	deviceService.CheckOutDevice(ctx, id, &model.CheckOutRequest{Assignee: "alice"})
+	_, err = deviceService.TransferDevice(ctx, id, &model.CheckOutRequest{Assignee: "alice"})
+	assert.True(t, errors.Is(err, model.ErrInvalidAssignment))
+	transferred, err := deviceService.TransferDevice(ctx, id, &model.CheckOutRequest{Assignee: "bob"})
+	assert.Nil(t, err)
+	assert.Equal(t, "bob", transferred.Assignee)
+
+	history, err := deviceService.DeviceAssignments(ctx, id)
+	assert.Nil(t, err)
+	if assert.Equal(t, 2, len(history)) {
+		assert.Equal(t, "alice", history[0].Assignee)
+		assert.Equal(t, "transferred to bob", history[0].Note)
+		assert.False(t, history[0].Active())
+		assert.True(t, history[1].Active())
+	}
+
+	held, _ := deviceService.AssigneeAssignments(ctx, "alice")
+	assert.Equal(t, 1, len(held))
+	held, _ = deviceService.AssigneeAssignments(ctx, "carol")
+	assert.Empty(t, held)
+}
+
+func TestShouldFindOverdueAssignments(t *testing.T) {
+	deviceService, first := getAssignmentService(t)
+	ctx := context.Background()
+	second, _ := deviceService.AddDevice(ctx, &model.NewDeviceRequest{Name: "iphone", DeviceBrand: "Apple", Status: model.StatusInService})
+	third, _ := deviceService.AddDevice(ctx, &model.NewDeviceRequest{Name: "galaxy", DeviceBrand: "Samsung", Status: model.StatusInService})
+	soon, later := time.Now().Add(time.Hour), time.Now().Add(48*time.Hour)
+
+	deviceService.CheckOutDevice(ctx, first, &model.CheckOutRequest{Assignee: "alice", DueAt: &later})
+	deviceService.CheckOutDevice(ctx, *second, &model.CheckOutRequest{Assignee: "bob", DueAt: &soon})
+	deviceService.CheckOutDevice(ctx, *third, &model.CheckOutRequest{Assignee: "carol"})
+
+	overdue, err := deviceService.OverdueAssignments(ctx, time.Now())
+	assert.Nil(t, err)
+	assert.Empty(t, overdue)
+
+	overdue, _ = deviceService.OverdueAssignments(ctx, time.Now().Add(72*time.Hour))
+	if assert.Equal(t, 2, len(overdue)) {
+		assert.Equal(t, "bob", overdue[0].Assignee)
+		assert.Equal(t, "alice", overdue[1].Assignee)
+	}
+
+	deviceService.CheckInDevice(ctx, *second, &model.CheckInRequest{})
+	overdue, _ = deviceService.OverdueAssignments(ctx, time.Now().Add(72*time.Hour))
+	assert.Equal(t, 1, len(overdue))
+}
@@ -0,0 +1,127 @@
+package tests
+
+import (
+	"context"
+	"devices_crud/internal/devices/app"
+	"devices_crud/internal/devices/app/ports"
+	"devices_crud/internal/devices/model"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getBrandService(t *testing.T) *app.DeviceService {
+	deviceService := getDeviceService()
+	deviceService.Brands = ports.NewBrandsRepositoryMock()
+	_, err := deviceService.SaveBrand(context.Background(), &model.Brand{
+		Name:    "HP",
+		Aliases: []string{"Hewlett-Packard", "hewlett packard", "HP Inc."},
+		Models:  []model.DeviceModel{{Name: "LaserJet Pro", Aliases: []string{"LJ Pro"}}},
+	})
+	assert.Nil(t, err)
+	return deviceService
+}
+
+func TestShouldKeyBrandSpellings(t *testing.T) {
+	assert.Equal(t, "apple", model.BrandKey("APPLE Inc."))
+	assert.Equal(t, "apple", model.BrandKey(" apple "))
+	assert.Equal(t, "hewlett packard", model.BrandKey("Hewlett-Packard Co"))
+	assert.Equal(t, "co", model.BrandKey("Co"))
+	assert.Equal(t, "", model.BrandKey(" - "))
+}
+
+func TestShouldSaveBrands(t *testing.T) {
+	deviceService := getBrandService(t)
+	ctx := context.Background()
+
+	brand, _ := deviceService.GetBrand(ctx, "HP")
+	assert.Equal(t, []string{"Hewlett-Packard"}, brand.Aliases)
+	assert.Equal(t, "LaserJet Pro", brand.Model("laserjet-pro").Name)
+	assert.Equal(t, "LaserJet Pro", brand.Model("LJ PRO").Name)
+	assert.Nil(t, brand.Model("OfficeJet"))
+
+	_, err := deviceService.SaveBrand(ctx, &model.Brand{Name: "Compaq", Aliases: []string{"Hewlett Packard"}})
+	assert.True(t, errors.Is(err, model.ErrInvalidBrand))
+	_, err = deviceService.SaveBrand(ctx, &model.Brand{Name: " ? "})
+	assert.True(t, errors.Is(err, model.ErrInvalidBrand))
+	_, err = deviceService.SaveBrand(ctx, &model.Brand{Name: "Apple", Models: []model.DeviceModel{{Name: "iPhone"}, {Name: "Mac", Aliases: []string{"IPHONE"}}}})
+	assert.True(t, errors.Is(err, model.ErrInvalidBrand))
+
+	replaced, err := deviceService.SaveBrand(ctx, &model.Brand{Name: "HP", Aliases: []string{"HPE"}})
+	assert.Nil(t, err)
+	assert.Equal(t, brand.CreatedAt, replaced.CreatedAt)
+	brands, _ := deviceService.ListBrands(ctx)
+	assert.Equal(t, 1, len(brands))
+
+	resolved, _ := deviceService.ResolveBrand(ctx, "hpe")
+	assert.Equal(t, "HP", resolved.Name)
+	assert.Nil(t, deviceService.DeleteBrand(ctx, "HP"))
+	resolved, _ = deviceService.ResolveBrand(ctx, "hpe")
+	assert.Nil(t, resolved)
+}
+
+func TestShouldNormalizeDeviceBrands(t *testing.T) {
+	deviceService := getBrandService(t)
+	ctx := context.Background()
+
+	id, err := deviceService.AddDevice(ctx, &model.NewDeviceRequest{Name: "printer", DeviceBrand: "hewlett packard inc"})
+	assert.Nil(t, err)
+	device, _ := deviceService.GetDevice(ctx, *id)
+	assert.Equal(t, "HP", device.DeviceBrand)
+
+	unknown, _ := deviceService.AddDevice(ctx, &model.NewDeviceRequest{Name: "pixel", DeviceBrand: "  Google   LLC "})
+	device, _ = deviceService.GetDevice(ctx, *unknown)
+	assert.Equal(t, "Google LLC", device.DeviceBrand)
+
+	brand := "HP Inc."
+	_, err = deviceService.PatchDevice(ctx, &model.PatchDeviceRequest{ID: *unknown, DeviceBrand: &brand})
+	assert.Nil(t, err)
+	device, _ = deviceService.GetDevice(ctx, *unknown)
+	assert.Equal(t, "HP", device.DeviceBrand)
+
+	device.DeviceBrand = "HEWLETT-PACKARD"
+	replaced, _ := deviceService.ReplaceDevice(ctx, device)
+	assert.Equal(t, "HP", replaced.DeviceBrand)
+
+	results, _ := deviceService.SearchDevices(ctx, "hp")
+	assert.Equal(t, 2, len(results))
+}
+
+func TestShouldMigrateBrands(t *testing.T) {
+	deviceService := getDeviceService()
+	ctx := context.Background()
+	ids := map[string]string{}
+	for _, brand := range []string{"Apple", "apple ", "APPLE Inc", "Dell", "HP", "Hewlett-Packard"} {
+		id, _ := deviceService.AddDevice(ctx, &model.NewDeviceRequest{Name: brand, DeviceBrand: brand})
+		ids[brand] = *id
+	}
+	// Devices stored before the catalog existed.
+	deviceService.Brands = ports.NewBrandsRepositoryMock()
+	deviceService.SaveBrand(ctx, &model.Brand{Name: "HP", Aliases: []string{"Hewlett Packard"}})
+
+	migration, err := deviceService.MigrateBrands(ctx, &model.MigrateBrandsRequest{DryRun: true, AddMissing: true})
+	assert.Nil(t, err)
+	assert.True(t, migration.DryRun)
+	assert.ElementsMatch(t, []model.BrandChange{
+		{DeviceID: ids["apple "], From: "apple", To: "Apple"},
+		{DeviceID: ids["APPLE Inc"], From: "APPLE Inc", To: "Apple"},
+		{DeviceID: ids["Hewlett-Packard"], From: "Hewlett-Packard", To: "HP"},
+	}, migration.Changes)
+	if assert.Equal(t, 2, len(migration.Added)) {
+		assert.Equal(t, []string{"Apple", "Dell"}, []string{migration.Added[0].Name, migration.Added[1].Name})
+	}
+	brands, _ := deviceService.ListBrands(ctx)
+	assert.Equal(t, 1, len(brands))
+
+	migration, err = deviceService.MigrateBrands(ctx, &model.MigrateBrandsRequest{AddMissing: true})
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(migration.Changes))
+	brands, _ = deviceService.ListBrands(ctx)
+	assert.Equal(t, 3, len(brands))
+	device, _ := deviceService.GetDevice(ctx, ids["APPLE Inc"])
+	assert.Equal(t, "Apple", device.DeviceBrand)
+
+	migration, _ = deviceService.MigrateBrands(ctx, &model.MigrateBrandsRequest{})
+	assert.Empty(t, migration.Changes)
+}
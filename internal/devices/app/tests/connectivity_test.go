@@ -0,0 +1,171 @@
+package tests
+
+import (
+	"context"
+	"devices_crud/internal/devices/app"
+	"devices_crud/internal/devices/app/ports"
+	"devices_crud/internal/devices/model"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShouldComputeConnectivity(t *testing.T) {
+	now := time.Now()
+	lastSeen := now.Add(-10 * time.Minute)
+	policy := model.ConnectivityPolicy{TypeTimeouts: map[string]time.Duration{"sensor": time.Hour}}
+
+	assert.Equal(t, model.ConnectivityUnknown, policy.Of(&model.Device{}, now))
+	assert.Equal(t, model.ConnectivityOffline, policy.Of(&model.Device{LastSeenAt: &lastSeen}, now))
+	assert.Equal(t, model.ConnectivityOnline, policy.Of(&model.Device{Type: "sensor", LastSeenAt: &lastSeen}, now))
+
+	policy.Timeout = 15 * time.Minute
+	assert.Equal(t, model.ConnectivityOnline, policy.Of(&model.Device{LastSeenAt: &lastSeen}, now))
+
+	_, err := model.ParseConnectivity("away")
+	assert.True(t, errors.Is(err, model.ErrInvalidConnectivity))
+}
+
+func TestShouldRecordHeartbeats(t *testing.T) {
+	deviceService := getDeviceService()
+	ctx := context.Background()
+	id, _ := deviceService.AddDevice(ctx, &model.NewDeviceRequest{Name: "gateway", DeviceBrand: "Cisco"})
+
+	changes := []model.ConnectivityChange{}
+	deviceService.OnConnectivityChange(func(ctx context.Context, change model.ConnectivityChange) {
+		changes = append(changes, change)
+	})
+
+	status, err := deviceService.DeviceConnectivity(ctx, *id)
+	assert.Nil(t, err)
+	assert.Equal(t, model.ConnectivityUnknown, status.Status)
+	assert.Nil(t, status.LastSeenAt)
+
+	status, err = deviceService.Heartbeat(ctx, *id)
+	assert.Nil(t, err)
+	assert.Equal(t, model.ConnectivityOnline, status.Status)
+	assert.NotNil(t, status.LastSeenAt)
+	assert.Equal(t, "5m0s", status.Timeout)
+	assert.Len(t, changes, 1)
+	assert.Equal(t, model.ConnectivityUnknown, changes[0].From)
+	assert.Equal(t, model.ConnectivityOnline, changes[0].To)
+
+	deviceService.Heartbeat(ctx, *id)
+	assert.Len(t, changes, 1)
+
+	device, _ := deviceService.GetDevice(ctx, *id)
+	assert.NotNil(t, device.LastSeenAt)
+
+	status, err = deviceService.Heartbeat(ctx, "missing")
+	assert.Nil(t, err)
+	assert.Nil(t, status)
+}
+
+func TestShouldDetectOfflineDevices(t *testing.T) {
+	deviceService := getDeviceService()
+	deviceService.DeviceTypes = ports.NewDeviceTypesRepositoryMock()
+	ctx := context.Background()
+	_, err := deviceService.SaveDeviceType(ctx, &model.DeviceType{Name: "sensor", Schema: json.RawMessage(`{"type": "object"}`)})
+	assert.Nil(t, err)
+	id, _ := deviceService.AddDevice(ctx, &model.NewDeviceRequest{Name: "gateway", DeviceBrand: "Cisco"})
+	sensor, _ := deviceService.AddDevice(ctx, &model.NewDeviceRequest{Name: "probe", DeviceBrand: "Bosch", Type: "sensor"})
+	deviceService.AddDevice(ctx, &model.NewDeviceRequest{Name: "spare", DeviceBrand: "Cisco"})
+	deviceService.Connectivity.TypeTimeouts = map[string]time.Duration{"sensor": time.Hour}
+
+	changes := []model.ConnectivityChange{}
+	deviceService.OnConnectivityChange(func(ctx context.Context, change model.ConnectivityChange) {
+		changes = append(changes, change)
+	})
+	deviceService.Heartbeat(ctx, *id)
+	deviceService.Heartbeat(ctx, *sensor)
+	assert.Len(t, changes, 2)
+
+	monitor := app.NewConnectivityMonitor(deviceService, time.Minute, nil)
+	offline, err := monitor.Check(ctx, time.Now().Add(10*time.Minute))
+	assert.Nil(t, err)
+	assert.Len(t, offline, 1)
+	assert.Equal(t, *id, offline[0].Device.ID)
+	assert.Equal(t, model.ConnectivityOnline, offline[0].From)
+	assert.Equal(t, model.ConnectivityOffline, offline[0].To)
+	assert.Len(t, changes, 3)
+
+	offline, _ = monitor.Check(ctx, time.Now().Add(11*time.Minute))
+	assert.Empty(t, offline)
+
+	devices, _, err := deviceService.ListDevices(ctx, &model.ListDevicesRequest{Connectivity: model.ConnectivityOnline})
+	assert.Nil(t, err)
+	assert.Len(t, devices, 2)
+	devices, _, _ = deviceService.ListDevices(ctx, &model.ListDevicesRequest{Connectivity: model.ConnectivityUnknown})
+	assert.Len(t, devices, 1)
+	assert.Equal(t, "spare", devices[0].Name)
+
+	_, _, err = deviceService.ListDevices(ctx, &model.ListDevicesRequest{Connectivity: "away"})
+	assert.True(t, errors.Is(err, model.ErrInvalidConnectivity))
+}
+
+// interleavedRepository runs write after every read of a device, like a request
+// racing the one being tested.
+type interleavedRepository struct {
+	ports.DevicesRepository
+	write func(ctx context.Context, id string)
+}
+
+func (r *interleavedRepository) FindByID(ctx context.Context, id *string) (*model.Device, error) {
+	device, err := r.DevicesRepository.FindByID(ctx, id)
+	if r.write != nil {
+		write := r.write
+		r.write = nil
+		write(ctx, *id)
+	}
+	return device, err
+}
+
+func TestShouldNotUndoConcurrentWritesWithHeartbeats(t *testing.T) {
+	deviceService := getDeviceService()
+	ctx := context.Background()
+	id, _ := deviceService.AddDevice(ctx, &model.NewDeviceRequest{Name: "gateway", DeviceBrand: "Cisco"})
+
+	repository := &interleavedRepository{DevicesRepository: deviceService.DevicesRepository}
+	repository.write = func(ctx context.Context, id string) {
+		name := "edge-gateway"
+		repository.DevicesRepository.Patch(ctx, &model.PatchDeviceRequest{ID: id, Name: &name})
+	}
+	deviceService.DevicesRepository = repository
+
+	status, err := deviceService.Heartbeat(ctx, *id)
+	assert.Nil(t, err)
+	assert.Equal(t, model.ConnectivityOnline, status.Status)
+	device, _ := deviceService.GetDevice(ctx, *id)
+	assert.Equal(t, "edge-gateway", device.Name)
+	assert.NotNil(t, device.LastSeenAt)
+
+	repository.write = func(ctx context.Context, id string) {
+		repository.DevicesRepository.Delete(ctx, id)
+	}
+	status, err = deviceService.Heartbeat(ctx, *id)
+	assert.Nil(t, err)
+	assert.Nil(t, status)
+}
+
+func TestShouldSeedConnectivityOnFirstCheck(t *testing.T) {
+	deviceService := getDeviceService()
+	ctx := context.Background()
+	id, _ := deviceService.AddDevice(ctx, &model.NewDeviceRequest{Name: "gateway", DeviceBrand: "Cisco"})
+	device, _ := deviceService.GetDevice(ctx, *id)
+	lastSeen := time.Now().Add(-time.Hour)
+	device.LastSeenAt = &lastSeen
+	deviceService.DevicesRepository.Replace(ctx, device)
+
+	monitor := app.NewConnectivityMonitor(deviceService, time.Minute, nil)
+	changes, err := monitor.Check(ctx, time.Now())
+	assert.Nil(t, err)
+	assert.Empty(t, changes)
+
+	status, _ := deviceService.Heartbeat(ctx, *id)
+	assert.Equal(t, model.ConnectivityOnline, status.Status)
+	changes, _ = monitor.Check(ctx, time.Now().Add(time.Hour))
+	assert.Len(t, changes, 1)
+}
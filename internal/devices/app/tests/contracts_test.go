@@ -0,0 +1,143 @@
+package tests
+
+import (
+	"context"
+	"devices_crud/internal/devices/app"
+	"devices_crud/internal/devices/app/ports"
+	"devices_crud/internal/devices/model"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getContractsService(t *testing.T) (*app.DeviceService, string) {
+	deviceService := getDeviceService()
+	deviceService.Contracts = ports.NewContractsRepositoryMock()
+	id, err := deviceService.AddDevice(context.Background(), &model.NewDeviceRequest{Name: "server", DeviceBrand: "Dell"})
+	assert.Nil(t, err)
+	return deviceService, *id
+}
+
+func addContract(t *testing.T, deviceService *app.DeviceService, id string, kind model.ContractKind, startsAt time.Time, endsAt time.Time) *model.Contract {
+	contract, err := deviceService.AddContract(context.Background(), id, &model.ContractRequest{Kind: kind, Provider: "Dell", StartsAt: startsAt, EndsAt: endsAt})
+	assert.Nil(t, err)
+	return contract
+}
+
+func TestShouldValidateContracts(t *testing.T) {
+	deviceService, id := getContractsService(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	for _, request := range []model.ContractRequest{
+		{Kind: "lease", Provider: "Dell", StartsAt: now, EndsAt: now.AddDate(1, 0, 0)},
+		{Kind: model.ContractWarranty, Provider: " ", StartsAt: now, EndsAt: now.AddDate(1, 0, 0)},
+		{Kind: model.ContractWarranty, Provider: "Dell", EndsAt: now.AddDate(1, 0, 0)},
+		{Kind: model.ContractWarranty, Provider: "Dell", StartsAt: now, EndsAt: now},
+	} {
+		_, err := deviceService.AddContract(ctx, id, &request)
+		assert.True(t, errors.Is(err, model.ErrInvalidContract), request)
+	}
+
+	contract, err := deviceService.AddContract(ctx, "missing", &model.ContractRequest{Kind: model.ContractWarranty, Provider: "Dell", StartsAt: now, EndsAt: now.AddDate(1, 0, 0)})
+	assert.Nil(t, err)
+	assert.Nil(t, contract)
+
+	contract, err = deviceService.AddContract(ctx, id, &model.ContractRequest{
+		Kind: model.ContractSupport, Provider: " Dell ", StartsAt: now, EndsAt: now.AddDate(1, 0, 0), Coverage: []string{"Parts", " parts", "onsite", ""},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "Dell", contract.Provider)
+	assert.Equal(t, []string{"parts", "onsite"}, contract.Coverage)
+	assert.True(t, contract.Active(now))
+	assert.Equal(t, 364, contract.DaysLeft(now.Add(time.Hour)))
+
+	deleted, _ := deviceService.DeleteContract(ctx, "other", contract.ID)
+	assert.Nil(t, deleted)
+	deleted, _ = deviceService.DeleteContract(ctx, id, contract.ID)
+	assert.Equal(t, contract.ID, deleted.ID)
+	contracts, _ := deviceService.DeviceContracts(ctx, id)
+	assert.Empty(t, contracts)
+}
+
+func TestShouldListExpiringContracts(t *testing.T) {
+	deviceService, id := getContractsService(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	warranty := addContract(t, deviceService, id, model.ContractWarranty, now.AddDate(-1, 0, 0), now.AddDate(0, 0, 20))
+	support := addContract(t, deviceService, id, model.ContractSupport, now.AddDate(-1, 0, 0), now.AddDate(0, 0, 10))
+	addContract(t, deviceService, id, model.ContractSupport, now.AddDate(0, 0, 10), now.AddDate(1, 0, 10))
+	addContract(t, deviceService, id, model.ContractWarranty, now.AddDate(-2, 0, 0), now.AddDate(0, 0, -1))
+
+	expiring, err := deviceService.ExpiringContracts(ctx, now, 30, "")
+	assert.Nil(t, err)
+	assert.Len(t, expiring, 2)
+	assert.Equal(t, support.ID, expiring[0].Contract.ID)
+	assert.Equal(t, 10, expiring[0].DaysLeft)
+	assert.True(t, expiring[0].Renewed)
+	assert.Equal(t, warranty.ID, expiring[1].Contract.ID)
+	assert.False(t, expiring[1].Renewed)
+
+	expiring, _ = deviceService.ExpiringContracts(ctx, now, 30, model.ContractWarranty)
+	assert.Len(t, expiring, 1)
+	expiring, _ = deviceService.ExpiringContracts(ctx, now, 5, "")
+	assert.Empty(t, expiring)
+
+	_, err = deviceService.ExpiringContracts(ctx, now, -1, "")
+	assert.True(t, errors.Is(err, model.ErrInvalidContract))
+	_, err = deviceService.ExpiringContracts(ctx, now, 30, "lease")
+	assert.True(t, errors.Is(err, model.ErrInvalidContract))
+}
+
+func TestShouldEmitContractExpiryEvents(t *testing.T) {
+	deviceService, id := getContractsService(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	hooked := []model.ContractExpiryEvent{}
+	deviceService.OnContractExpiry(func(ctx context.Context, event model.ContractExpiryEvent) {
+		hooked = append(hooked, event)
+	})
+	contract := addContract(t, deviceService, id, model.ContractWarranty, now.AddDate(-1, 0, 0), now.AddDate(0, 0, 40))
+	renewed := addContract(t, deviceService, id, model.ContractSupport, now.AddDate(-1, 0, 0), now.AddDate(0, 0, 20))
+	addContract(t, deviceService, id, model.ContractSupport, renewed.EndsAt, renewed.EndsAt.AddDate(1, 0, 0))
+
+	job := app.NewContractExpiryJob(deviceService, time.Hour, []int{7, 30, 90}, nil)
+	events, err := job.Check(ctx, now)
+	assert.Nil(t, err)
+	assert.Len(t, events, 1)
+	assert.Equal(t, contract.ID, events[0].Contract.ID)
+	assert.Equal(t, 90, events[0].Window)
+	assert.Equal(t, 40, events[0].DaysLeft)
+
+	events, _ = job.Check(ctx, now.Add(time.Hour))
+	assert.Empty(t, events)
+
+	events, _ = job.Check(ctx, now.AddDate(0, 0, 35))
+	assert.Len(t, events, 1)
+	assert.Equal(t, 7, events[0].Window)
+
+	events, _ = job.Check(ctx, now.AddDate(0, 0, 41))
+	assert.Len(t, events, 1)
+	assert.Equal(t, 0, events[0].Window)
+
+	events, _ = job.Check(ctx, now.AddDate(0, 0, 42))
+	assert.Empty(t, events)
+	assert.Len(t, hooked, 3)
+}
+
+func TestShouldDropContractsOfDeletedDevices(t *testing.T) {
+	deviceService, id := getContractsService(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	addContract(t, deviceService, id, model.ContractWarranty, now, now.AddDate(0, 0, 10))
+	err := deviceService.DeleteDevice(ctx, id)
+	assert.Nil(t, err)
+
+	expiring, _ := deviceService.ExpiringContracts(ctx, now, 30, "")
+	assert.Empty(t, expiring)
+}
@@ -0,0 +1,80 @@
+package tests
+
+import (
+	"context"
+	"devices_crud/internal/devices/app"
+	"devices_crud/internal/devices/app/ports"
+	"devices_crud/internal/devices/model"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const laptopSchema = `{
+	"type": "object",
+	"properties": {
+		"serial": {"type": "string", "minLength": 4},
+		"mac": {"type": "string", "format": "mac"},
+		"os": {"enum": ["linux", "macos", "windows"]},
+		"ramGb": {"type": "integer", "minimum": 1},
+		"managed": {"type": "boolean"}
+	},
+	"required": ["serial"],
+	"additionalProperties": false
+}`
+
+func getDeviceTypeService(t *testing.T) *app.DeviceService {
+	service := getDeviceService()
+	service.DeviceTypes = ports.NewDeviceTypesRepositoryMock()
+	_, err := service.SaveDeviceType(context.Background(), &model.DeviceType{Name: "laptop", Schema: []byte(laptopSchema)})
+	assert.Nil(t, err)
+	return service
+}
+
+func TestShouldValidateAttributesOnWrites(t *testing.T) {
+	service := getDeviceTypeService(t)
+	ctx := context.Background()
+
+	_, err := service.AddDevice(ctx, &model.NewDeviceRequest{Name: "mbp", Type: "laptop", Attributes: model.Attributes{"os": "macos"}})
+	assert.True(t, errors.Is(err, model.ErrInvalidAttributes))
+	_, err = service.AddDevice(ctx, &model.NewDeviceRequest{Name: "mbp", Type: "phone"})
+	assert.True(t, errors.Is(err, model.ErrUnknownDeviceType))
+
+	id, err := service.AddDevice(ctx, &model.NewDeviceRequest{Name: "mbp", Type: "laptop", Attributes: model.Attributes{"serial": "C02X1234", "ramGb": 16}})
+	assert.Nil(t, err)
+	device, _ := service.GetDevice(ctx, *id)
+	assert.Equal(t, model.Attributes{"serial": "C02X1234", "ramGb": 16.0}, device.Attributes)
+
+	_, err = service.PatchDevice(ctx, &model.PatchDeviceRequest{ID: *id, Attributes: model.Attributes{"serial": nil}})
+	assert.True(t, errors.Is(err, model.ErrInvalidAttributes))
+	_, err = service.PatchDevice(ctx, &model.PatchDeviceRequest{ID: *id, Attributes: model.Attributes{"ramGb": nil, "os": "linux"}})
+	assert.Nil(t, err)
+	device, _ = service.GetDevice(ctx, *id)
+	assert.Equal(t, model.Attributes{"serial": "C02X1234", "os": "linux"}, device.Attributes)
+
+	device.Attributes = model.Attributes{"serial": "C02X1234", "os": "linux", "color": "grey"}
+	_, err = service.ReplaceDevice(ctx, device)
+	assert.True(t, errors.Is(err, model.ErrInvalidAttributes))
+
+	untyped := ""
+	_, err = service.PatchDevice(ctx, &model.PatchDeviceRequest{ID: *id, Type: &untyped, Attributes: model.Attributes{"color": "grey"}})
+	assert.Nil(t, err)
+
+	assert.Nil(t, service.DeleteDeviceType(ctx, "laptop"))
+}
+
+func TestShouldNotDeleteDeviceTypesInUse(t *testing.T) {
+	service := getDeviceTypeService(t)
+	ctx := context.Background()
+
+	id, err := service.AddDevice(ctx, &model.NewDeviceRequest{Name: "mbp", Type: "laptop", Attributes: model.Attributes{"serial": "C02X1234"}})
+	assert.Nil(t, err)
+	assert.True(t, errors.Is(service.DeleteDeviceType(ctx, "laptop"), model.ErrDeviceTypeInUse))
+
+	assert.Nil(t, service.DeleteDevice(ctx, *id))
+	assert.Nil(t, service.DeleteDeviceType(ctx, "laptop"))
+	deviceType, err := service.GetDeviceType(ctx, "laptop")
+	assert.Nil(t, err)
+	assert.Nil(t, deviceType)
+}
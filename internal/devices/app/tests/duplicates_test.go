@@ -0,0 +1,206 @@
+package tests
+
+import (
+	"context"
+	"devices_crud/internal/devices/app"
+	"devices_crud/internal/devices/app/adapters"
+	"devices_crud/internal/devices/app/ports"
+	"devices_crud/internal/devices/model"
+	"devices_crud/internal/timeseries"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getMergeService() *app.DeviceService {
+	deviceService := getDeviceService()
+	deviceService.Relationships = ports.NewRelationshipsRepositoryMock()
+	deviceService.Contracts = ports.NewContractsRepositoryMock()
+	deviceService.Software = ports.NewSoftwareRepositoryMock()
+	deviceService.Telemetry = adapters.NewTelemetryRepository(timeseries.NewStore(nil))
+	deviceService.Redirects = ports.NewRedirectsRepositoryMock()
+	return deviceService
+}
+
+func TestShouldParseUniquenessKeys(t *testing.T) {
+	keys, err := model.ParseUniquenessKeys([]string{"deviceBrand+attributes.serial", "labels.asset"})
+	assert.Nil(t, err)
+	assert.Equal(t, []model.UniquenessKey{{"brand", "attributes.serial"}, {"labels.asset"}}, keys)
+	assert.Equal(t, "brand+attributes.serial", keys[0].String())
+
+	for _, text := range []string{"", "brand+", "serial", "attributes."} {
+		_, err := model.ParseUniquenessKey(text)
+		assert.NotNil(t, err, text)
+	}
+}
+
+func TestShouldEnforceUniqueKeys(t *testing.T) {
+	deviceService := getDeviceService()
+	deviceService.UniqueKeys = []model.UniquenessKey{{"brand", "attributes.serial"}}
+	ctx := context.Background()
+
+	_, err := deviceService.AddDevice(ctx, &model.NewDeviceRequest{Name: "laptop", DeviceBrand: "Acme Inc", Attributes: model.Attributes{"serial": "SN-1"}})
+	assert.Nil(t, err)
+	other, err := deviceService.AddDevice(ctx, &model.NewDeviceRequest{Name: "laptop", DeviceBrand: "Acme", Attributes: model.Attributes{"serial": "SN-3"}})
+	assert.Nil(t, err)
+
+	_, err = deviceService.AddDevice(ctx, &model.NewDeviceRequest{Name: "laptop 2", DeviceBrand: "ACME", Attributes: model.Attributes{"serial": " sn-1"}})
+	assert.True(t, errors.Is(err, model.ErrDuplicateDevice), err)
+	_, err = deviceService.ReplaceDevice(ctx, &model.Device{ID: "imported", Name: "laptop", DeviceBrand: "Acme", Attributes: model.Attributes{"serial": "SN-1"}})
+	assert.True(t, errors.Is(err, model.ErrDuplicateDevice), err)
+
+	// Updates cannot take the key of another device either, but keep their own.
+	_, err = deviceService.PatchDevice(ctx, &model.PatchDeviceRequest{ID: *other, Attributes: model.Attributes{"serial": "sn-1"}})
+	assert.True(t, errors.Is(err, model.ErrDuplicateDevice), err)
+	_, err = deviceService.ReplaceDevice(ctx, &model.Device{ID: *other, Name: "laptop", DeviceBrand: "Acme", Attributes: model.Attributes{"serial": "SN-1"}})
+	assert.True(t, errors.Is(err, model.ErrDuplicateDevice), err)
+	name := "work laptop"
+	_, err = deviceService.PatchDevice(ctx, &model.PatchDeviceRequest{ID: *other, Name: &name})
+	assert.Nil(t, err)
+	_, err = deviceService.ReplaceDevice(ctx, &model.Device{ID: *other, Name: "laptop", DeviceBrand: "Acme", Attributes: model.Attributes{"serial": "SN-3"}})
+	assert.Nil(t, err)
+
+	// Devices lacking a field of the key, or of another brand, are not duplicates.
+	for _, request := range []model.NewDeviceRequest{
+		{Name: "laptop", DeviceBrand: "Acme"},
+		{Name: "laptop", DeviceBrand: "Globex", Attributes: model.Attributes{"serial": "SN-1"}},
+		{Name: "laptop", DeviceBrand: "Acme", Attributes: model.Attributes{"serial": "SN-2"}},
+	} {
+		_, err := deviceService.AddDevice(ctx, &request)
+		assert.Nil(t, err, request)
+	}
+}
+
+func TestShouldFindDuplicateCandidates(t *testing.T) {
+	deviceService := getDeviceService()
+	ctx := context.Background()
+
+	printer, _ := deviceService.AddDevice(ctx, &model.NewDeviceRequest{Name: "Lab Printer 2", DeviceBrand: "Brother"})
+	duplicate, _ := deviceService.AddDevice(ctx, &model.NewDeviceRequest{Name: "printer-lab-2", DeviceBrand: "Brother Ltd"})
+	typo, _ := deviceService.AddDevice(ctx, &model.NewDeviceRequest{Name: "Lab Printr 2", DeviceBrand: "Brother"})
+	laptop, _ := deviceService.AddDevice(ctx, &model.NewDeviceRequest{Name: "MacBook Pro", DeviceBrand: "Apple", Attributes: model.Attributes{"serial": "C02"}})
+	other, _ := deviceService.AddDevice(ctx, &model.NewDeviceRequest{Name: "Work laptop", DeviceBrand: "Apple", Attributes: model.Attributes{"serial": "C02"}})
+
+	candidates, err := deviceService.DuplicateCandidates(ctx, 0)
+	assert.Nil(t, err)
+	if assert.Len(t, candidates, 3) {
+		assert.Equal(t, *printer, candidates[0].Device.ID)
+		assert.Equal(t, *duplicate, candidates[0].Duplicate.ID)
+		assert.Equal(t, 1.0, candidates[0].Score)
+		assert.Greater(t, candidates[1].Score, 0.9)
+	}
+
+	// Devices sharing a uniqueness key are duplicates whatever their names.
+	deviceService.UniqueKeys = []model.UniquenessKey{{"brand", "attributes.serial"}}
+	duplicates, err := deviceService.DeviceDuplicates(ctx, *laptop, 0)
+	assert.Nil(t, err)
+	if assert.Len(t, duplicates, 1) {
+		assert.Equal(t, *other, duplicates[0].Duplicate.ID)
+		assert.Equal(t, "brand+attributes.serial", duplicates[0].Key)
+	}
+
+	duplicates, _ = deviceService.DeviceDuplicates(ctx, *typo, 0.99)
+	assert.Empty(t, duplicates)
+	duplicates, _ = deviceService.DeviceDuplicates(ctx, "missing", 0)
+	assert.Nil(t, duplicates)
+	_, err = deviceService.DuplicateCandidates(ctx, 1.5)
+	assert.True(t, errors.Is(err, model.ErrInvalidThreshold))
+}
+
+func TestShouldMergeDevices(t *testing.T) {
+	deviceService := getMergeService()
+	ctx := context.Background()
+
+	source, _ := deviceService.AddDevice(ctx, &model.NewDeviceRequest{
+		Name: "printer", DeviceBrand: "Brother", Labels: map[string]string{"team": "b", "site": "x"}, Attributes: model.Attributes{"serial": "SN"},
+	})
+	target, _ := deviceService.AddDevice(ctx, &model.NewDeviceRequest{
+		Name: "Printer", DeviceBrand: "Brother", Labels: map[string]string{"team": "a"}, Attributes: model.Attributes{"floor": float64(2)},
+	})
+	older, _ := deviceService.AddDevice(ctx, &model.NewDeviceRequest{Name: "printer (old)", DeviceBrand: "Brother"})
+	chassis, _ := deviceService.AddDevice(ctx, &model.NewDeviceRequest{Name: "rack", DeviceBrand: "Dell"})
+	tray, _ := deviceService.AddDevice(ctx, &model.NewDeviceRequest{Name: "paper tray", DeviceBrand: "Brother"})
+	_, err := deviceService.TransitionDevice(ctx, *target, &model.TransitionRequest{Status: model.StatusReceived, Reason: "delivered"})
+	assert.Nil(t, err)
+
+	deviceService.LinkDevices(ctx, *chassis, &model.LinkRequest{Type: model.RelationContains, TargetID: *source})
+	deviceService.LinkDevices(ctx, *source, &model.LinkRequest{Type: model.RelationConnectedTo, TargetID: *target})
+	deviceService.LinkDevices(ctx, *source, &model.LinkRequest{Type: model.RelationContains, TargetID: *tray})
+	earlier := time.Now().Add(-48 * time.Hour)
+	deviceService.RecordSoftware(ctx, *target, &model.SoftwareRequest{Kind: model.SoftwareFirmware, Name: "core", Version: "1.0.0", InstalledAt: &earlier})
+	deviceService.RecordSoftware(ctx, *source, &model.SoftwareRequest{Kind: model.SoftwareFirmware, Name: "core", Version: "2.0.0"})
+	deviceService.IngestReadings(ctx, *source, &model.ReadingBatch{Readings: []model.Reading{{Metric: "toner", Value: 40}}})
+
+	_, err = deviceService.MergeDevices(ctx, *target, &model.MergeRequest{SourceID: *target})
+	assert.True(t, errors.Is(err, model.ErrInvalidMerge))
+	_, err = deviceService.MergeDevices(ctx, *target, &model.MergeRequest{SourceID: "missing"})
+	assert.True(t, errors.Is(err, model.ErrInvalidMerge))
+	merged, err := deviceService.MergeDevices(ctx, "missing", &model.MergeRequest{SourceID: *source})
+	assert.Nil(t, err)
+	assert.Nil(t, merged)
+
+	// A device merged before its target leads to the final device.
+	_, err = deviceService.MergeDevices(ctx, *source, &model.MergeRequest{SourceID: *older})
+	assert.Nil(t, err)
+	merged, err = deviceService.MergeDevices(ctx, *target, &model.MergeRequest{SourceID: *source})
+	assert.Nil(t, err)
+	assert.Equal(t, *target, merged.ID)
+	assert.Equal(t, "Printer", merged.Name)
+	assert.Equal(t, model.StatusReceived, merged.Status)
+	assert.Equal(t, map[string]string{"team": "a", "site": "x"}, merged.Labels)
+	assert.Equal(t, model.Attributes{"serial": "SN", "floor": float64(2)}, merged.Attributes)
+	assert.Len(t, merged.StatusHistory, 4)
+
+	found, _ := deviceService.GetDevice(ctx, *source)
+	assert.Nil(t, found)
+	for _, id := range []string{*source, *older} {
+		redirect, err := deviceService.DeviceRedirect(ctx, id)
+		assert.Nil(t, err)
+		assert.Equal(t, *target, redirect.ToID, id)
+	}
+
+	parent, _ := deviceService.ParentDevice(ctx, *target)
+	assert.Equal(t, *chassis, parent.ID)
+	children, _ := deviceService.ChildDevices(ctx, *target)
+	if assert.Len(t, children, 1) {
+		assert.Equal(t, *tray, children[0].ID)
+	}
+	parent, _ = deviceService.ParentDevice(ctx, *tray)
+	assert.Equal(t, *target, parent.ID)
+	relationships, _ := deviceService.DeviceRelationships(ctx, *target)
+	assert.Len(t, relationships, 2)
+
+	software, _ := deviceService.DeviceSoftware(ctx, *target)
+	if assert.Len(t, software, 1) {
+		assert.Equal(t, "2.0.0", software[0].Version)
+	}
+	readings, _ := deviceService.LatestReadings(ctx, *target)
+	if assert.Len(t, readings, 1) {
+		assert.Equal(t, 40.0, readings[0].Value)
+	}
+
+	assert.Nil(t, deviceService.DeleteDeviceTree(ctx, *chassis))
+	redirect, _ := deviceService.DeviceRedirect(ctx, *source)
+	assert.Nil(t, redirect)
+}
+
+func TestShouldNotMergeDevicesCheckedOutTwice(t *testing.T) {
+	deviceService := getMergeService()
+	deviceService.Assignments = ports.NewAssignmentsRepositoryMock()
+	ctx := context.Background()
+
+	ids := make([]string, 2)
+	for i := range ids {
+		id, _ := deviceService.AddDevice(ctx, &model.NewDeviceRequest{Name: "laptop", DeviceBrand: "Apple", Status: model.StatusInService})
+		ids[i] = *id
+		_, err := deviceService.CheckOutDevice(ctx, *id, &model.CheckOutRequest{Assignee: "ada@example.com"})
+		assert.Nil(t, err)
+	}
+
+	_, err := deviceService.MergeDevices(ctx, ids[0], &model.MergeRequest{SourceID: ids[1]})
+	assert.True(t, errors.Is(err, model.ErrInvalidMerge))
+	found, _ := deviceService.GetDevice(ctx, ids[1])
+	assert.NotNil(t, found)
+}
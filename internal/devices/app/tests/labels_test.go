@@ -0,0 +1,59 @@
+package tests
+
+import (
+	"context"
+	"devices_crud/internal/devices/model"
+	"errors"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func names(devices []model.Device) []string {
+	names := make([]string, len(devices))
+	for i, device := range devices {
+		names[i] = device.Name
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestShouldListDevicesBySelector(t *testing.T) {
+	service := getDeviceService()
+	ctx := context.Background()
+
+	web, _ := service.AddDevice(ctx, &model.NewDeviceRequest{Name: "web-1", DeviceBrand: "Dell", Labels: map[string]string{"env": "prod", "team": "web"}})
+	service.AddDevice(ctx, &model.NewDeviceRequest{Name: "db-1", DeviceBrand: "HP", Labels: map[string]string{"env": "prod", "team": "data"}})
+	service.AddDevice(ctx, &model.NewDeviceRequest{Name: "ci-1", DeviceBrand: "Dell", Labels: map[string]string{"env": "dev"}})
+	service.AddDevice(ctx, &model.NewDeviceRequest{Name: "spare", DeviceBrand: "Dell"})
+
+	list := func(selector string, filter string) []string {
+		devices, _, err := service.ListDevices(ctx, &model.ListDevicesRequest{Selector: selector, Filter: filter})
+		assert.Nil(t, err, selector)
+		return names(devices)
+	}
+	assert.Equal(t, []string{"db-1", "web-1"}, list("env=prod", ""))
+	assert.Equal(t, []string{"ci-1", "spare"}, list("env!=prod", ""))
+	assert.Equal(t, []string{"spare"}, list("!env", ""))
+	assert.Equal(t, []string{"web-1"}, list("env=prod", `brand = "Dell"`))
+	assert.Equal(t, []string{"db-1"}, list("", `labels.team = "data"`))
+
+	_, err := service.SetLabels(ctx, *web, map[string]string{"env": "staging", "retired": ""})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"db-1"}, list("env=prod", ""))
+	assert.Equal(t, []string{"web-1"}, list("env in (staging),retired", ""))
+
+	device, err := service.RemoveLabels(ctx, *web, []string{"retired", "missing"})
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"env": "staging", "team": "web"}, device.Labels)
+	assert.Empty(t, list("retired", ""))
+
+	assert.Nil(t, service.DeleteDevice(ctx, *web))
+	assert.Empty(t, list("env=staging", ""))
+
+	_, _, err = service.ListDevices(ctx, &model.ListDevicesRequest{Selector: "env in prod"})
+	assert.True(t, errors.Is(err, model.ErrInvalidSelector))
+	_, err = service.AddDevice(ctx, &model.NewDeviceRequest{Name: "bad", Labels: map[string]string{"env": "not valid"}})
+	assert.True(t, errors.Is(err, model.ErrInvalidLabels))
+}
@@ -0,0 +1,141 @@
+package tests
+
+import (
+	"context"
+	"devices_crud/internal/auth"
+	"devices_crud/internal/devices/model"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShouldWalkThroughLifecycle(t *testing.T) {
+	deviceService := getDeviceService()
+	ctx := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "alice"})
+
+	id, err := deviceService.AddDevice(ctx, &model.NewDeviceRequest{Name: "pixel", DeviceBrand: "Google"})
+	assert.Nil(t, err)
+	device, _ := deviceService.GetDevice(ctx, *id)
+	assert.Equal(t, model.StatusOrdered, device.Status)
+	assert.Equal(t, device.CreatedAt, device.StatusSince())
+
+	for _, status := range []model.Status{model.StatusReceived, model.StatusProvisioned, model.StatusInService, model.StatusInRepair} {
+		device, err = deviceService.TransitionDevice(ctx, *id, &model.TransitionRequest{Status: status, Reason: "step"})
+		assert.Nil(t, err)
+		assert.Equal(t, status, device.Status)
+	}
+
+	device, _ = deviceService.GetDevice(ctx, *id)
+	assert.Equal(t, 5, len(device.StatusHistory))
+	last := device.StatusHistory[4]
+	assert.Equal(t, model.StatusInService, last.From)
+	assert.Equal(t, model.StatusInRepair, last.To)
+	assert.Equal(t, "step", last.Reason)
+	assert.Equal(t, "alice", last.Actor)
+	assert.Equal(t, last.At, device.StatusSince())
+}
+
+func TestShouldRejectIllegalTransitions(t *testing.T) {
+	deviceService := getDeviceService()
+	ctx := context.Background()
+
+	id, _ := deviceService.AddDevice(ctx, &model.NewDeviceRequest{Name: "pixel", DeviceBrand: "Google", Status: model.StatusInService})
+	_, err := deviceService.TransitionDevice(ctx, *id, &model.TransitionRequest{Status: model.StatusRetired, Reason: "old"})
+	assert.Nil(t, err)
+
+	_, err = deviceService.TransitionDevice(ctx, *id, &model.TransitionRequest{Status: model.StatusInService, Reason: "found"})
+	assert.True(t, errors.Is(err, model.ErrIllegalTransition))
+	_, err = deviceService.TransitionDevice(ctx, *id, &model.TransitionRequest{Status: "lost", Reason: "lost"})
+	assert.True(t, errors.Is(err, model.ErrInvalidStatus))
+	_, err = deviceService.TransitionDevice(ctx, *id, &model.TransitionRequest{Status: model.StatusDisposed, Reason: " "})
+	assert.True(t, errors.Is(err, model.ErrInvalidTransition))
+	_, err = deviceService.AddDevice(ctx, &model.NewDeviceRequest{Name: "pixel", DeviceBrand: "Google", Status: "lost"})
+	assert.True(t, errors.Is(err, model.ErrInvalidStatus))
+
+	// Later statuses are only reached through transitions.
+	for _, status := range []model.Status{model.StatusInRepair, model.StatusRetired, model.StatusDisposed} {
+		_, err = deviceService.AddDevice(ctx, &model.NewDeviceRequest{Name: "pixel", DeviceBrand: "Google", Status: status})
+		assert.True(t, errors.Is(err, model.ErrInvalidStatus), status)
+		_, err = deviceService.ReplaceDevice(ctx, &model.Device{ID: "imported", Name: "pixel", DeviceBrand: "Google", Status: status})
+		assert.True(t, errors.Is(err, model.ErrInvalidStatus), status)
+	}
+
+	_, err = deviceService.TransitionDevice(ctx, *id, &model.TransitionRequest{Status: model.StatusDisposed, Reason: "shredded"})
+	assert.Nil(t, err)
+	for _, status := range model.Statuses {
+		_, err = deviceService.TransitionDevice(ctx, *id, &model.TransitionRequest{Status: status, Reason: "again"})
+		assert.True(t, errors.Is(err, model.ErrIllegalTransition), status)
+	}
+
+	missing, err := deviceService.TransitionDevice(ctx, "missing", &model.TransitionRequest{Status: model.StatusReceived, Reason: "delivered"})
+	assert.Nil(t, err)
+	assert.Nil(t, missing)
+}
+
+func TestShouldKeepStatusOnReplace(t *testing.T) {
+	deviceService := getDeviceService()
+	ctx := context.Background()
+
+	id, _ := deviceService.AddDevice(ctx, &model.NewDeviceRequest{Name: "pixel", DeviceBrand: "Google"})
+	device, _ := deviceService.GetDevice(ctx, *id)
+	device.Status = model.StatusDisposed
+	device.StatusHistory = nil
+
+	replaced, err := deviceService.ReplaceDevice(ctx, device)
+	assert.Nil(t, err)
+	assert.Equal(t, model.StatusOrdered, replaced.Status)
+	assert.Equal(t, 1, len(replaced.StatusHistory))
+}
+
+func TestShouldRunTransitionHooks(t *testing.T) {
+	deviceService := getDeviceService()
+	ctx := context.Background()
+
+	var repaired, changes []model.StatusChange
+	deviceService.OnTransition("", model.StatusInRepair, func(ctx context.Context, device *model.Device, change model.StatusChange) {
+		repaired = append(repaired, change)
+	})
+	deviceService.OnTransition("", "", func(ctx context.Context, device *model.Device, change model.StatusChange) {
+		assert.Equal(t, change.To, device.Status)
+		changes = append(changes, change)
+	})
+
+	id, _ := deviceService.AddDevice(ctx, &model.NewDeviceRequest{Name: "pixel", DeviceBrand: "Google", Status: model.StatusInService})
+	deviceService.TransitionDevice(ctx, *id, &model.TransitionRequest{Status: model.StatusInRepair, Reason: "cracked screen"})
+	deviceService.TransitionDevice(ctx, *id, &model.TransitionRequest{Status: model.StatusInService, Reason: "repaired"})
+	deviceService.TransitionDevice(ctx, *id, &model.TransitionRequest{Status: model.StatusDisposed, Reason: "shredded"})
+
+	assert.Equal(t, 1, len(repaired))
+	assert.Equal(t, "cracked screen", repaired[0].Reason)
+	assert.Equal(t, 2, len(changes))
+}
+
+func TestShouldNotOverwriteConcurrentTransitions(t *testing.T) {
+	deviceService := getDeviceService()
+	ctx := context.Background()
+	id, _ := deviceService.AddDevice(ctx, &model.NewDeviceRequest{Name: "pixel", DeviceBrand: "Google", Status: model.StatusInService})
+
+	repository := &interleavedRepository{DevicesRepository: deviceService.DevicesRepository}
+	deviceService.DevicesRepository = repository
+	repository.write = func(ctx context.Context, id string) {
+		name := "pixel-8"
+		repository.DevicesRepository.Patch(ctx, &model.PatchDeviceRequest{ID: id, Name: &name})
+	}
+	device, err := deviceService.TransitionDevice(ctx, *id, &model.TransitionRequest{Status: model.StatusInRepair, Reason: "cracked screen"})
+	assert.Nil(t, err)
+	assert.Equal(t, "pixel-8", device.Name)
+	assert.Equal(t, model.StatusInRepair, device.Status)
+
+	repository.write = func(ctx context.Context, id string) {
+		deviceService.TransitionDevice(ctx, id, &model.TransitionRequest{Status: model.StatusRetired, Reason: "beyond repair"})
+	}
+	_, err = deviceService.TransitionDevice(ctx, *id, &model.TransitionRequest{Status: model.StatusInService, Reason: "repaired"})
+	assert.True(t, errors.Is(err, model.ErrIllegalTransition))
+
+	device, _ = deviceService.GetDevice(ctx, *id)
+	assert.Equal(t, model.StatusRetired, device.Status)
+	if assert.Equal(t, 3, len(device.StatusHistory)) {
+		assert.Equal(t, "beyond repair", device.StatusHistory[2].Reason)
+	}
+}
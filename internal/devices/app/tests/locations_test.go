@@ -0,0 +1,151 @@
+package tests
+
+import (
+	"context"
+	"devices_crud/internal/devices/app"
+	"devices_crud/internal/devices/app/ports"
+	"devices_crud/internal/devices/model"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getLocationService(t *testing.T) (*app.DeviceService, map[string]string) {
+	deviceService := getDeviceService()
+	deviceService.Locations = ports.NewLocationsRepositoryMock()
+	ctx := context.Background()
+
+	ids := map[string]string{}
+	for _, location := range []struct {
+		path, name, parent string
+		kind               model.LocationKind
+	}{
+		{"HQ", "HQ", "", model.LocationSite},
+		{"HQ/A", "A", "HQ", model.LocationBuilding},
+		{"HQ/A/101", "101", "HQ/A", model.LocationRoom},
+		{"HQ/A/101/R1", "R1", "HQ/A/101", model.LocationRack},
+		{"HQ/B", "B", "HQ", model.LocationBuilding},
+	} {
+		created, err := deviceService.CreateLocation(ctx, &model.NewLocationRequest{Name: location.name, Kind: location.kind, ParentID: ids[location.parent]})
+		assert.Nil(t, err)
+		ids[location.path] = created.ID
+	}
+	return deviceService, ids
+}
+
+func TestShouldBuildLocationTree(t *testing.T) {
+	deviceService, ids := getLocationService(t)
+	ctx := context.Background()
+
+	_, err := deviceService.CreateLocation(ctx, &model.NewLocationRequest{Name: "A", Kind: model.LocationBuilding, ParentID: ids["HQ"]})
+	assert.True(t, errors.Is(err, model.ErrInvalidLocation))
+	_, err = deviceService.CreateLocation(ctx, &model.NewLocationRequest{Name: "Annex", Kind: model.LocationSite, ParentID: ids["HQ"]})
+	assert.True(t, errors.Is(err, model.ErrInvalidLocation))
+	_, err = deviceService.CreateLocation(ctx, &model.NewLocationRequest{Name: "Annex", Kind: "floor"})
+	assert.True(t, errors.Is(err, model.ErrInvalidLocation))
+	_, err = deviceService.CreateLocation(ctx, &model.NewLocationRequest{Name: "Annex", Kind: model.LocationBuilding, ParentID: "missing"})
+	assert.True(t, errors.Is(err, model.ErrUnknownLocation))
+
+	children, _ := deviceService.ListLocations(ctx, ids["HQ"])
+	assert.Equal(t, []string{"A", "B"}, []string{children[0].Name, children[1].Name})
+
+	path, err := deviceService.LocationPath(ctx, ids["HQ/A/101/R1"])
+	assert.Nil(t, err)
+	assert.Equal(t, "HQ/A/101/R1", path.String())
+
+	found, _ := deviceService.FindLocationByPath(ctx, "/HQ/A/101/")
+	assert.Equal(t, ids["HQ/A/101"], found.ID)
+	found, _ = deviceService.FindLocationByPath(ctx, "HQ/C")
+	assert.Nil(t, found)
+}
+
+func TestShouldMoveAndDeleteLocations(t *testing.T) {
+	deviceService, ids := getLocationService(t)
+	ctx := context.Background()
+
+	_, err := deviceService.MoveLocation(ctx, ids["HQ/A"], &model.MoveLocationRequest{ParentID: ids["HQ/A/101"]})
+	assert.True(t, errors.Is(err, model.ErrLocationCycle))
+
+	moved, err := deviceService.MoveLocation(ctx, ids["HQ/A/101"], &model.MoveLocationRequest{ParentID: ids["HQ/B"]})
+	assert.Nil(t, err)
+	assert.Equal(t, ids["HQ/B"], moved.ParentID)
+	path, _ := deviceService.LocationPath(ctx, ids["HQ/A/101/R1"])
+	assert.Equal(t, "HQ/B/101/R1", path.String())
+
+	missing, err := deviceService.MoveLocation(ctx, "missing", &model.MoveLocationRequest{})
+	assert.Nil(t, err)
+	assert.Nil(t, missing)
+
+	assert.True(t, errors.Is(deviceService.DeleteLocation(ctx, ids["HQ/B"]), model.ErrLocationNotEmpty))
+	id, _ := deviceService.AddDevice(ctx, &model.NewDeviceRequest{Name: "switch", DeviceBrand: "Cisco", LocationID: ids["HQ/A"]})
+	assert.True(t, errors.Is(deviceService.DeleteLocation(ctx, ids["HQ/A"]), model.ErrLocationNotEmpty))
+
+	deviceService.MoveDevice(ctx, *id, &model.MoveDeviceRequest{})
+	assert.Nil(t, deviceService.DeleteLocation(ctx, ids["HQ/A"]))
+	deleted, _ := deviceService.GetLocation(ctx, ids["HQ/A"])
+	assert.Nil(t, deleted)
+}
+
+func TestShouldPlaceDevicesInLocations(t *testing.T) {
+	deviceService, ids := getLocationService(t)
+	ctx := context.Background()
+
+	_, err := deviceService.AddDevice(ctx, &model.NewDeviceRequest{Name: "switch", DeviceBrand: "Cisco", LocationID: "missing"})
+	assert.True(t, errors.Is(err, model.ErrUnknownLocation))
+
+	rackID, _ := deviceService.AddDevice(ctx, &model.NewDeviceRequest{Name: "switch", DeviceBrand: "Cisco", LocationID: ids["HQ/A/101/R1"]})
+	roomID, _ := deviceService.AddDevice(ctx, &model.NewDeviceRequest{Name: "printer", DeviceBrand: "HP", LocationID: ids["HQ/A/101"]})
+	deviceService.AddDevice(ctx, &model.NewDeviceRequest{Name: "pixel", DeviceBrand: "Google"})
+
+	devices, err := deviceService.LocationDevices(ctx, ids["HQ"], true)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(devices))
+	devices, _ = deviceService.LocationDevices(ctx, ids["HQ/A/101"], false)
+	if assert.Equal(t, 1, len(devices)) {
+		assert.Equal(t, *roomID, devices[0].ID)
+	}
+
+	_, err = deviceService.MoveDevice(ctx, *rackID, &model.MoveDeviceRequest{LocationID: "missing"})
+	assert.True(t, errors.Is(err, model.ErrUnknownLocation))
+	moved, err := deviceService.MoveDevice(ctx, *rackID, &model.MoveDeviceRequest{LocationID: ids["HQ/B"], Reason: "rack decommissioned"})
+	assert.Nil(t, err)
+	assert.Equal(t, ids["HQ/B"], moved.LocationID)
+	if assert.Equal(t, 2, len(moved.LocationHistory)) {
+		assert.Equal(t, model.LocationChange{From: ids["HQ/A/101/R1"], To: ids["HQ/B"], Reason: "rack decommissioned", At: moved.LocationHistory[1].At}, moved.LocationHistory[1])
+	}
+
+	// Replacing a device keeps its placement, which only MoveDevice changes.
+	moved.Name = "core switch"
+	moved.LocationID = ""
+	replaced, err := deviceService.ReplaceDevice(ctx, moved)
+	assert.Nil(t, err)
+	assert.Equal(t, ids["HQ/B"], replaced.LocationID)
+	assert.Equal(t, 2, len(replaced.LocationHistory))
+}
+
+func TestShouldNotOverwriteConcurrentMoves(t *testing.T) {
+	deviceService, ids := getLocationService(t)
+	ctx := context.Background()
+	id, _ := deviceService.AddDevice(ctx, &model.NewDeviceRequest{Name: "switch", DeviceBrand: "Cisco", LocationID: ids["HQ/A/101"]})
+
+	repository := &interleavedRepository{DevicesRepository: deviceService.DevicesRepository}
+	deviceService.DevicesRepository = repository
+	repository.write = func(ctx context.Context, id string) {
+		deviceService.SetLabels(ctx, id, map[string]string{"role": "core"})
+	}
+	device, err := deviceService.MoveDevice(ctx, *id, &model.MoveDeviceRequest{LocationID: ids["HQ/A/101/R1"]})
+	assert.Nil(t, err)
+	assert.Equal(t, ids["HQ/A/101/R1"], device.LocationID)
+	assert.Equal(t, map[string]string{"role": "core"}, device.Labels)
+
+	repository.write = func(ctx context.Context, id string) {
+		deviceService.MoveDevice(ctx, id, &model.MoveDeviceRequest{LocationID: ids["HQ/B"]})
+	}
+	_, err = deviceService.MoveDevice(ctx, *id, &model.MoveDeviceRequest{LocationID: ids["HQ"]})
+	assert.True(t, errors.Is(err, model.ErrDeviceMoved))
+
+	device, _ = deviceService.GetDevice(ctx, *id)
+	assert.Equal(t, ids["HQ/B"], device.LocationID)
+	assert.Equal(t, 3, len(device.LocationHistory))
+}
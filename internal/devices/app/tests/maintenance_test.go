@@ -0,0 +1,169 @@
+package tests
+
+import (
+	"context"
+	"devices_crud/internal/devices/app"
+	"devices_crud/internal/devices/app/ports"
+	"devices_crud/internal/devices/model"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getMaintenanceService(t *testing.T) (*app.DeviceService, string) {
+	deviceService := getDeviceService()
+	deviceService.Maintenance = ports.NewMaintenanceRepositoryMock()
+	id, err := deviceService.AddDevice(context.Background(), &model.NewDeviceRequest{Name: "printer", DeviceBrand: "HP"})
+	assert.Nil(t, err)
+	return deviceService, *id
+}
+
+func TestShouldValidateMaintenancePlans(t *testing.T) {
+	deviceService, id := getMaintenanceService(t)
+	ctx := context.Background()
+
+	for _, request := range []model.NewMaintenancePlanRequest{
+		{Kind: model.MaintenanceInterval, Every: 1, Unit: model.UnitMonth},
+		{Name: "toner", Kind: "weekly", Every: 1, Unit: model.UnitMonth},
+		{Name: "toner", Kind: model.MaintenanceInterval, Every: 0, Unit: model.UnitMonth},
+		{Name: "toner", Kind: model.MaintenanceInterval, Every: 1, Unit: "fortnight"},
+	} {
+		_, err := deviceService.CreateMaintenancePlan(ctx, id, &request)
+		assert.True(t, errors.Is(err, model.ErrInvalidMaintenancePlan), request)
+	}
+
+	plan, err := deviceService.CreateMaintenancePlan(ctx, "missing", &model.NewMaintenancePlanRequest{Name: "toner", Kind: model.MaintenanceInterval, Every: 1, Unit: model.UnitMonth})
+	assert.Nil(t, err)
+	assert.Nil(t, plan)
+
+	plan, err = deviceService.CreateMaintenancePlan(ctx, id, &model.NewMaintenancePlanRequest{Name: "toner", Kind: model.MaintenanceInterval, Every: 2, Unit: model.UnitWeek})
+	assert.Nil(t, err)
+	assert.WithinDuration(t, time.Now().AddDate(0, 0, 14), plan.StartsAt, time.Minute)
+
+	deleted, _ := deviceService.DeleteMaintenancePlan(ctx, "other", plan.ID)
+	assert.Nil(t, deleted)
+	deleted, _ = deviceService.DeleteMaintenancePlan(ctx, id, plan.ID)
+	assert.Equal(t, plan.ID, deleted.ID)
+	plans, _ := deviceService.DeviceMaintenancePlans(ctx, id)
+	assert.Empty(t, plans)
+}
+
+func TestShouldScheduleIntervalMaintenance(t *testing.T) {
+	deviceService, id := getMaintenanceService(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	startsAt := now.AddDate(0, 0, -10)
+	plan, err := deviceService.CreateMaintenancePlan(ctx, id, &model.NewMaintenancePlanRequest{Name: "clean", Kind: model.MaintenanceInterval, Every: 7, Unit: model.UnitDay, StartsAt: &startsAt})
+	assert.Nil(t, err)
+
+	tasks, err := deviceService.DeviceMaintenanceTasks(ctx, id, now.AddDate(0, 0, 14))
+	assert.Nil(t, err)
+	if assert.Equal(t, 4, len(tasks)) {
+		assert.Equal(t, startsAt, tasks[0].DueAt)
+		assert.True(t, tasks[0].Overdue)
+		assert.True(t, tasks[1].Overdue)
+		assert.False(t, tasks[2].Overdue)
+		assert.Equal(t, "clean", tasks[0].PlanName)
+	}
+
+	performedAt := now.Add(-time.Hour)
+	record, err := deviceService.RecordService(ctx, id, &model.ServiceRecordRequest{PlanID: plan.ID, PerformedAt: &performedAt, Cost: 25.5, Currency: "eur", Notes: "dusty"})
+	assert.Nil(t, err)
+	assert.Equal(t, model.ServiceMaintenance, record.Kind)
+	assert.Equal(t, startsAt, *record.DueAt)
+	assert.Equal(t, "EUR", record.Currency)
+
+	tasks, _ = deviceService.DeviceMaintenanceTasks(ctx, id, now.AddDate(0, 0, 14))
+	if assert.Equal(t, 2, len(tasks)) {
+		assert.Equal(t, performedAt.AddDate(0, 0, 7), tasks[0].DueAt)
+		assert.False(t, tasks[0].Overdue)
+	}
+	plans, _ := deviceService.DeviceMaintenancePlans(ctx, id)
+	assert.Equal(t, performedAt.AddDate(0, 0, 7), *plans[0].NextDueAt)
+	overdue, _ := deviceService.OverdueMaintenance(ctx, now)
+	assert.Empty(t, overdue)
+}
+
+func TestShouldScheduleCalendarMaintenance(t *testing.T) {
+	deviceService, id := getMaintenanceService(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	startsAt := now.AddDate(0, 0, -40)
+	plan, err := deviceService.CreateMaintenancePlan(ctx, id, &model.NewMaintenancePlanRequest{Name: "inspection", Kind: model.MaintenanceCalendar, Every: 1, Unit: model.UnitMonth, StartsAt: &startsAt})
+	assert.Nil(t, err)
+
+	overdue, _ := deviceService.OverdueMaintenance(ctx, now)
+	assert.Equal(t, 2, len(overdue))
+
+	// Servicing the device today completes the oldest task only, the calendar
+	// does not move.
+	record, err := deviceService.RecordService(ctx, id, &model.ServiceRecordRequest{PlanID: plan.ID})
+	assert.Nil(t, err)
+	assert.Equal(t, startsAt, *record.DueAt)
+	overdue, _ = deviceService.OverdueMaintenance(ctx, now)
+	if assert.Equal(t, 1, len(overdue)) {
+		assert.Equal(t, startsAt.AddDate(0, 1, 0), overdue[0].DueAt)
+	}
+	_, err = deviceService.RecordService(ctx, id, &model.ServiceRecordRequest{PlanID: plan.ID})
+	assert.Nil(t, err)
+
+	tasks, _ := deviceService.UpcomingMaintenance(ctx, now.AddDate(0, 0, 30))
+	if assert.Equal(t, 1, len(tasks)) {
+		assert.Equal(t, startsAt.AddDate(0, 2, 0), tasks[0].DueAt)
+	}
+	records, _ := deviceService.ServiceRecords(ctx, id)
+	assert.Equal(t, 2, len(records))
+}
+
+func TestShouldFlagMaintenanceOfDevicesInRepair(t *testing.T) {
+	deviceService, id := getMaintenanceService(t)
+	ctx := context.Background()
+
+	startsAt := time.Now().AddDate(0, 0, -1)
+	_, err := deviceService.CreateMaintenancePlan(ctx, id, &model.NewMaintenancePlanRequest{Name: "clean", Kind: model.MaintenanceInterval, Every: 1, Unit: model.UnitMonth, StartsAt: &startsAt})
+	assert.Nil(t, err)
+	for _, status := range []model.Status{model.StatusReceived, model.StatusInRepair} {
+		_, err := deviceService.TransitionDevice(ctx, id, &model.TransitionRequest{Status: status, Reason: "inspection"})
+		assert.Nil(t, err)
+	}
+
+	overdue, _ := deviceService.OverdueMaintenance(ctx, time.Now())
+	if assert.Equal(t, 1, len(overdue)) {
+		assert.True(t, overdue[0].DeviceInRepair)
+	}
+
+	_, err = deviceService.RecordService(ctx, id, &model.ServiceRecordRequest{Kind: model.ServiceRepair, Cost: 120, Notes: "new fuser"})
+	assert.Nil(t, err)
+	records, _ := deviceService.ServiceRecords(ctx, id)
+	assert.Equal(t, model.ServiceRepair, records[0].Kind)
+
+	assert.Nil(t, deviceService.DeleteDevice(ctx, id))
+	overdue, _ = deviceService.OverdueMaintenance(ctx, time.Now())
+	assert.Empty(t, overdue)
+}
+
+func TestShouldValidateServiceRecords(t *testing.T) {
+	deviceService, id := getMaintenanceService(t)
+	ctx := context.Background()
+	other, _ := deviceService.AddDevice(ctx, &model.NewDeviceRequest{Name: "scanner", DeviceBrand: "HP"})
+	plan, _ := deviceService.CreateMaintenancePlan(ctx, *other, &model.NewMaintenancePlanRequest{Name: "clean", Kind: model.MaintenanceInterval, Every: 1, Unit: model.UnitMonth})
+
+	future := time.Now().Add(time.Hour)
+	for _, request := range []model.ServiceRecordRequest{
+		{Kind: "upgrade"},
+		{Cost: -1},
+		{PerformedAt: &future},
+		{PlanID: plan.ID},
+	} {
+		_, err := deviceService.RecordService(ctx, id, &request)
+		assert.True(t, errors.Is(err, model.ErrInvalidServiceRecord), request)
+	}
+
+	record, err := deviceService.RecordService(ctx, "missing", &model.ServiceRecordRequest{})
+	assert.Nil(t, err)
+	assert.Nil(t, record)
+}
@@ -0,0 +1,146 @@
+package tests
+
+import (
+	"context"
+	"devices_crud/internal/devices/app"
+	"devices_crud/internal/devices/app/ports"
+	"devices_crud/internal/devices/model"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getRelationshipService(t *testing.T, names ...string) (*app.DeviceService, map[string]string) {
+	deviceService := getDeviceService()
+	deviceService.Relationships = ports.NewRelationshipsRepositoryMock()
+	ctx := context.Background()
+
+	ids := map[string]string{}
+	for _, name := range names {
+		id, err := deviceService.AddDevice(ctx, &model.NewDeviceRequest{Name: name, DeviceBrand: "Acme"})
+		assert.Nil(t, err)
+		ids[name] = *id
+	}
+	return deviceService, ids
+}
+
+func link(t *testing.T, deviceService *app.DeviceService, from string, relationType model.RelationType, to string) *model.Relationship {
+	relationship, err := deviceService.LinkDevices(context.Background(), from, &model.LinkRequest{Type: relationType, TargetID: to})
+	assert.Nil(t, err)
+	return relationship
+}
+
+func TestShouldEnforceRelationshipRules(t *testing.T) {
+	deviceService, ids := getRelationshipService(t, "chassis", "spare", "blade", "switch")
+	ctx := context.Background()
+	linkDevices := func(from string, relationType model.RelationType, to string) error {
+		_, err := deviceService.LinkDevices(ctx, ids[from], &model.LinkRequest{Type: relationType, TargetID: ids[to]})
+		return err
+	}
+
+	contains := link(t, deviceService, ids["chassis"], model.RelationContains, ids["blade"])
+	assert.Equal(t, ids["chassis"], contains.FromID)
+	assert.Equal(t, ids["blade"], contains.ToID)
+
+	assert.True(t, errors.Is(linkDevices("chassis", "powers", "blade"), model.ErrInvalidRelationship))
+	assert.True(t, errors.Is(linkDevices("chassis", model.RelationContains, "chassis"), model.ErrInvalidRelationship))
+	assert.True(t, errors.Is(linkDevices("chassis", model.RelationContains, "missing"), model.ErrInvalidRelationship))
+	assert.True(t, errors.Is(linkDevices("chassis", model.RelationContains, "blade"), model.ErrRelationshipConflict))
+	assert.True(t, errors.Is(linkDevices("spare", model.RelationContains, "blade"), model.ErrRelationshipConflict))
+	assert.True(t, errors.Is(linkDevices("blade", model.RelationContains, "chassis"), model.ErrRelationshipCycle))
+
+	link(t, deviceService, ids["blade"], model.RelationConnectedTo, ids["switch"])
+	assert.True(t, errors.Is(linkDevices("switch", model.RelationConnectedTo, "blade"), model.ErrRelationshipConflict))
+	link(t, deviceService, ids["chassis"], model.RelationConnectedTo, ids["switch"])
+
+	link(t, deviceService, ids["spare"], model.RelationReplaces, ids["chassis"])
+	assert.True(t, errors.Is(linkDevices("chassis", model.RelationReplaces, "spare"), model.ErrRelationshipCycle))
+
+	relationship, err := deviceService.LinkDevices(ctx, "missing", &model.LinkRequest{Type: model.RelationContains, TargetID: ids["blade"]})
+	assert.Nil(t, err)
+	assert.Nil(t, relationship)
+
+	parent, _ := deviceService.ParentDevice(ctx, ids["blade"])
+	assert.Equal(t, "chassis", parent.Name)
+	parent, _ = deviceService.ParentDevice(ctx, ids["chassis"])
+	assert.Nil(t, parent)
+	children, _ := deviceService.ChildDevices(ctx, ids["chassis"])
+	if assert.Equal(t, 1, len(children)) {
+		assert.Equal(t, "blade", children[0].Name)
+	}
+	relationships, _ := deviceService.DeviceRelationships(ctx, ids["switch"])
+	assert.Equal(t, 2, len(relationships))
+
+	unlinked, err := deviceService.UnlinkDevices(ctx, ids["spare"], contains.ID)
+	assert.Nil(t, err)
+	assert.Nil(t, unlinked)
+	unlinked, err = deviceService.UnlinkDevices(ctx, ids["blade"], contains.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, contains.ID, unlinked.ID)
+	link(t, deviceService, ids["spare"], model.RelationContains, ids["blade"])
+}
+
+func TestShouldTraverseDeviceGraph(t *testing.T) {
+	deviceService, ids := getRelationshipService(t, "rack", "server", "disk", "switch", "router")
+	ctx := context.Background()
+
+	link(t, deviceService, ids["rack"], model.RelationContains, ids["server"])
+	link(t, deviceService, ids["server"], model.RelationContains, ids["disk"])
+	link(t, deviceService, ids["server"], model.RelationConnectedTo, ids["switch"])
+	link(t, deviceService, ids["switch"], model.RelationConnectedTo, ids["router"])
+
+	depths := func(graph *model.DeviceGraph) map[string]int {
+		nodes := map[string]int{}
+		for _, node := range graph.Nodes {
+			nodes[node.Device.Name] = node.Depth
+		}
+		return nodes
+	}
+
+	graph, err := deviceService.TraverseDevices(ctx, ids["disk"], &model.TraversalRequest{})
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]int{"disk": 0, "server": 1}, depths(graph))
+	assert.Equal(t, 1, len(graph.Edges))
+
+	graph, _ = deviceService.TraverseDevices(ctx, ids["disk"], &model.TraversalRequest{Depth: 3})
+	assert.Equal(t, map[string]int{"disk": 0, "server": 1, "rack": 2, "switch": 2, "router": 3}, depths(graph))
+	assert.Equal(t, 4, len(graph.Edges))
+
+	graph, _ = deviceService.TraverseDevices(ctx, ids["disk"], &model.TraversalRequest{Depth: 3, Types: []model.RelationType{model.RelationContains}})
+	assert.Equal(t, map[string]int{"disk": 0, "server": 1, "rack": 2}, depths(graph))
+
+	_, err = deviceService.TraverseDevices(ctx, ids["disk"], &model.TraversalRequest{Depth: model.MaxTraversalDepth + 1})
+	assert.True(t, errors.Is(err, model.ErrInvalidRelationship))
+	_, err = deviceService.TraverseDevices(ctx, ids["disk"], &model.TraversalRequest{Types: []model.RelationType{"powers"}})
+	assert.True(t, errors.Is(err, model.ErrInvalidRelationship))
+	graph, err = deviceService.TraverseDevices(ctx, "missing", &model.TraversalRequest{})
+	assert.Nil(t, err)
+	assert.Nil(t, graph)
+}
+
+func TestShouldApplyDeleteRules(t *testing.T) {
+	deviceService, ids := getRelationshipService(t, "rack", "server", "disk", "switch", "old")
+	ctx := context.Background()
+
+	link(t, deviceService, ids["rack"], model.RelationContains, ids["server"])
+	link(t, deviceService, ids["server"], model.RelationContains, ids["disk"])
+	link(t, deviceService, ids["server"], model.RelationConnectedTo, ids["switch"])
+	link(t, deviceService, ids["switch"], model.RelationReplaces, ids["old"])
+
+	assert.True(t, errors.Is(deviceService.DeleteDevice(ctx, ids["rack"]), model.ErrDeviceHasChildren))
+
+	assert.Nil(t, deviceService.DeleteDevice(ctx, ids["switch"]))
+	relationships, _ := deviceService.DeviceRelationships(ctx, ids["old"])
+	assert.Empty(t, relationships)
+	relationships, _ = deviceService.DeviceRelationships(ctx, ids["server"])
+	assert.Equal(t, 2, len(relationships))
+
+	assert.Nil(t, deviceService.DeleteDeviceTree(ctx, ids["rack"]))
+	for _, name := range []string{"rack", "server", "disk"} {
+		device, _ := deviceService.GetDevice(ctx, ids[name])
+		assert.Nil(t, device, name)
+	}
+	device, _ := deviceService.GetDevice(ctx, ids["old"])
+	assert.NotNil(t, device)
+}
@@ -5,7 +5,7 @@ import (
 	"devices_crud/internal/devices/app"
 	"devices_crud/internal/devices/app/ports"
 	"devices_crud/internal/devices/model"
-	"log"
+	"log/slog"
 	"os"
 	"testing"
 
@@ -14,7 +14,7 @@ import (
 
 func getDeviceService() *app.DeviceService {
 	deviceRepositoryMock := ports.NewDevicesRepositoryMock()
-	logger := log.New(os.Stdout, "TEST: ", log.Ltime)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
 
 	return app.NewDeviceService(deviceRepositoryMock, logger)
 }
@@ -95,6 +95,29 @@ func TestShouldGetAllDevices(t *testing.T) {
 	assert.Contains(t, foundDevicesBrands, "Test Brand 2")
 }
 
+func TestShouldNotModifyStoredDevicesThroughResults(t *testing.T) {
+	deviceService := getDeviceService()
+	ctx := context.Background()
+
+	id, _ := deviceService.AddDevice(ctx, &model.NewDeviceRequest{
+		Name: "Test Device", DeviceBrand: "Test Brand", Labels: map[string]string{"team": "a"}, Attributes: model.Attributes{"serial": "SN"},
+	})
+
+	all, _ := deviceService.GetAllDevices(ctx)
+	listed, _, _ := deviceService.ListDevices(ctx, &model.ListDevicesRequest{Filter: `name = "Test Device"`})
+	found, _ := deviceService.DevicesRepository.Search(ctx, "test")
+	for _, devices := range [][]model.Device{all, listed, found} {
+		if assert.Len(t, devices, 1) {
+			devices[0].Labels["team"] = "b"
+			devices[0].Attributes["serial"] = "changed"
+		}
+	}
+
+	device, _ := deviceService.GetDevice(ctx, *id)
+	assert.Equal(t, map[string]string{"team": "a"}, device.Labels)
+	assert.Equal(t, model.Attributes{"serial": "SN"}, device.Attributes)
+}
+
 func TestShouldReplaceDevice(t *testing.T) {
 	deviceService := getDeviceService()
 	ctx := context.Background()
@@ -0,0 +1,152 @@
+package tests
+
+import (
+	"context"
+	"devices_crud/internal/devices/app"
+	"devices_crud/internal/devices/app/ports"
+	"devices_crud/internal/devices/model"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getSoftwareService() *app.DeviceService {
+	deviceService := getDeviceService()
+	deviceService.Software = ports.NewSoftwareRepositoryMock()
+	return deviceService
+}
+
+func TestShouldRecordSoftware(t *testing.T) {
+	deviceService := getSoftwareService()
+	ctx := context.Background()
+	id, _ := deviceService.AddDevice(ctx, &model.NewDeviceRequest{Name: "router", DeviceBrand: "Acme"})
+
+	for _, request := range []model.SoftwareRequest{
+		{Kind: "driver", Name: "core", Version: "1.0.0"},
+		{Kind: model.SoftwareFirmware, Name: " ", Version: "1.0.0"},
+		{Kind: model.SoftwareFirmware, Name: "core", Version: "latest"},
+	} {
+		_, err := deviceService.RecordSoftware(ctx, *id, &request)
+		assert.True(t, errors.Is(err, model.ErrInvalidSoftware), request)
+	}
+
+	software, err := deviceService.RecordSoftware(ctx, "missing", &model.SoftwareRequest{Kind: model.SoftwareFirmware, Name: "core", Version: "1.0.0"})
+	assert.Nil(t, err)
+	assert.Nil(t, software)
+
+	installedAt := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	firmware, err := deviceService.RecordSoftware(ctx, *id, &model.SoftwareRequest{Kind: model.SoftwareFirmware, Name: " core ", Version: "v2.3", InstalledAt: &installedAt})
+	assert.Nil(t, err)
+	assert.Equal(t, "core", firmware.Name)
+	assert.Equal(t, installedAt, firmware.InstalledAt)
+	deviceService.RecordSoftware(ctx, *id, &model.SoftwareRequest{Kind: model.SoftwareApplication, Name: "agent", Version: "1.4.0"})
+
+	upgraded, err := deviceService.RecordSoftware(ctx, *id, &model.SoftwareRequest{Kind: model.SoftwareFirmware, Name: "CORE", Version: "2.3.1"})
+	assert.Nil(t, err)
+	assert.Equal(t, firmware.ID, upgraded.ID)
+	assert.Equal(t, "2.3.1", upgraded.Version)
+
+	installed, _ := deviceService.DeviceSoftware(ctx, *id)
+	if assert.Len(t, installed, 2) {
+		assert.Equal(t, model.SoftwareApplication, installed[0].Kind)
+		assert.Equal(t, "CORE", installed[1].Name)
+	}
+
+	deleted, _ := deviceService.DeleteSoftware(ctx, "other", firmware.ID)
+	assert.Nil(t, deleted)
+	deleted, _ = deviceService.DeleteSoftware(ctx, *id, firmware.ID)
+	assert.Equal(t, firmware.ID, deleted.ID)
+
+	assert.Nil(t, deviceService.DeleteDevice(ctx, *id))
+	installed, _ = deviceService.DeviceSoftware(ctx, *id)
+	assert.Empty(t, installed)
+}
+
+func TestShouldValidateSoftwarePolicies(t *testing.T) {
+	deviceService := getSoftwareService()
+	ctx := context.Background()
+
+	for _, request := range []model.SoftwarePolicyRequest{
+		{Kind: "driver", MinVersion: "1.0.0"},
+		{MinVersion: ""},
+		{MinVersion: "2.x"},
+	} {
+		_, err := deviceService.CreateSoftwarePolicy(ctx, &request)
+		assert.True(t, errors.Is(err, model.ErrInvalidSoftwarePolicy), request)
+	}
+
+	policy, err := deviceService.CreateSoftwarePolicy(ctx, &model.SoftwarePolicyRequest{Brand: "  Acme   Corp ", MinVersion: " 2.3.1 "})
+	assert.Nil(t, err)
+	assert.Equal(t, model.SoftwareFirmware, policy.Kind)
+	assert.Equal(t, "Acme Corp", policy.Brand)
+	assert.Equal(t, "2.3.1", policy.MinVersion)
+
+	policies, _ := deviceService.SoftwarePolicies(ctx)
+	assert.Len(t, policies, 1)
+	deleted, _ := deviceService.DeleteSoftwarePolicy(ctx, policy.ID)
+	assert.Equal(t, policy.ID, deleted.ID)
+	deleted, _ = deviceService.DeleteSoftwarePolicy(ctx, policy.ID)
+	assert.Nil(t, deleted)
+}
+
+func TestShouldReportNonCompliantDevices(t *testing.T) {
+	deviceService := getSoftwareService()
+	ctx := context.Background()
+	outdated, _ := deviceService.AddDevice(ctx, &model.NewDeviceRequest{Name: "router-b", DeviceBrand: "Acme"})
+	current, _ := deviceService.AddDevice(ctx, &model.NewDeviceRequest{Name: "router-c", DeviceBrand: "ACME Inc"})
+	missing, _ := deviceService.AddDevice(ctx, &model.NewDeviceRequest{Name: "router-a", DeviceBrand: "Acme"})
+	other, _ := deviceService.AddDevice(ctx, &model.NewDeviceRequest{Name: "laptop", DeviceBrand: "Dell"})
+
+	record := func(id string, name string, version string) {
+		_, err := deviceService.RecordSoftware(ctx, id, &model.SoftwareRequest{Kind: model.SoftwareFirmware, Name: name, Version: version})
+		assert.Nil(t, err)
+	}
+	record(*outdated, "core", "2.3.0")
+	record(*outdated, "radio", "2.3.1-rc.1")
+	record(*current, "core", "2.10.0")
+	record(*other, "bios", "1.0.0")
+
+	policy, err := deviceService.CreateSoftwarePolicy(ctx, &model.SoftwarePolicyRequest{Brand: "acme", MinVersion: "2.3.1"})
+	assert.Nil(t, err)
+
+	report, err := deviceService.ComplianceReport(ctx, "")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, report.Policies)
+	assert.Equal(t, 3, report.Devices)
+	assert.Equal(t, 1, report.Compliant)
+	if assert.Len(t, report.Violators, 2) {
+		assert.Equal(t, *missing, report.Violators[0].Device.ID)
+		assert.Equal(t, []model.PolicyViolation{{Policy: *policy, Reason: model.ViolationMissing, Required: "2.3.1"}}, report.Violators[0].Violations)
+		assert.Equal(t, *outdated, report.Violators[1].Device.ID)
+		if assert.Len(t, report.Violators[1].Violations, 2) {
+			for _, violation := range report.Violators[1].Violations {
+				assert.Equal(t, model.ViolationOutdated, violation.Reason)
+			}
+		}
+	}
+
+	compliance, err := deviceService.DeviceCompliance(ctx, *current)
+	assert.Nil(t, err)
+	assert.True(t, compliance.Compliant)
+	assert.Equal(t, 1, compliance.Policies)
+	compliance, _ = deviceService.DeviceCompliance(ctx, *other)
+	assert.True(t, compliance.Compliant)
+	assert.Equal(t, 0, compliance.Policies)
+	compliance, _ = deviceService.DeviceCompliance(ctx, "missing")
+	assert.Nil(t, compliance)
+
+	deviceService.CreateSoftwarePolicy(ctx, &model.SoftwarePolicyRequest{Kind: model.SoftwareFirmware, Software: "bios", MinVersion: "1.2"})
+	report, _ = deviceService.ComplianceReport(ctx, "")
+	assert.Equal(t, 2, report.Policies)
+	assert.Equal(t, 4, report.Devices)
+	assert.Equal(t, 0, report.Compliant)
+
+	report, _ = deviceService.ComplianceReport(ctx, policy.ID)
+	assert.Equal(t, 1, report.Policies)
+	assert.Len(t, report.Violators, 2)
+	report, err = deviceService.ComplianceReport(ctx, "missing")
+	assert.Nil(t, err)
+	assert.Nil(t, report)
+}
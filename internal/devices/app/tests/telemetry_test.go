@@ -0,0 +1,116 @@
+package tests
+
+import (
+	"context"
+	"devices_crud/internal/devices/app"
+	"devices_crud/internal/devices/app/adapters"
+	"devices_crud/internal/devices/model"
+	"devices_crud/internal/timeseries"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getTelemetryService(t *testing.T) (*app.DeviceService, string) {
+	deviceService := getDeviceService()
+	deviceService.Telemetry = adapters.NewTelemetryRepository(timeseries.NewStore(nil))
+	id, err := deviceService.AddDevice(context.Background(), &model.NewDeviceRequest{Name: "sensor", DeviceBrand: "Bosch"})
+	assert.Nil(t, err)
+	return deviceService, *id
+}
+
+func TestShouldValidateReadings(t *testing.T) {
+	deviceService, id := getTelemetryService(t)
+	ctx := context.Background()
+
+	for _, batch := range []model.ReadingBatch{
+		{},
+		{Readings: make([]model.Reading, model.MaxReadingsPerBatch+1)},
+		{Readings: []model.Reading{{Metric: "battery level", Value: 1}}},
+		{Readings: []model.Reading{{Metric: "battery", Value: math.NaN()}}},
+		{Readings: []model.Reading{{Metric: "battery", Value: 1, At: time.Now().Add(time.Hour)}}},
+	} {
+		_, err := deviceService.IngestReadings(ctx, id, &batch)
+		assert.True(t, errors.Is(err, model.ErrInvalidReading), batch)
+	}
+
+	ingested, err := deviceService.IngestReadings(ctx, "missing", &model.ReadingBatch{Readings: []model.Reading{{Metric: "battery", Value: 1}}})
+	assert.Nil(t, err)
+	assert.Nil(t, ingested)
+
+	_, err = deviceService.Readings(ctx, id, "Battery", time.Now().Add(-time.Hour), time.Now())
+	assert.True(t, errors.Is(err, model.ErrInvalidReading))
+	_, err = deviceService.Readings(ctx, id, "battery", time.Now(), time.Now().Add(-time.Hour))
+	assert.True(t, errors.Is(err, model.ErrInvalidReading))
+	_, err = deviceService.ReadingAggregates(ctx, id, "battery", time.Now().Add(-24*time.Hour), time.Now(), time.Second)
+	assert.True(t, errors.Is(err, model.ErrInvalidReading))
+}
+
+func TestShouldIngestAndQueryReadings(t *testing.T) {
+	deviceService, id := getTelemetryService(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	ingested, err := deviceService.IngestReadings(ctx, id, &model.ReadingBatch{Readings: []model.Reading{
+		{Metric: " Battery ", Value: 90, At: now.Add(-30 * time.Minute)},
+		{Metric: "battery", Value: 80, At: now.Add(-20 * time.Minute)},
+		{Metric: "temperature", Value: 41.5},
+	}})
+	assert.Nil(t, err)
+	assert.Equal(t, "battery", ingested.Readings[0].Metric)
+	assert.WithinDuration(t, now, ingested.Readings[2].At, time.Minute)
+
+	latest, err := deviceService.LatestReadings(ctx, id)
+	assert.Nil(t, err)
+	if assert.Len(t, latest, 2) {
+		assert.Equal(t, model.Reading{Metric: "battery", Value: 80, At: now.Add(-20 * time.Minute)}, latest[0])
+		assert.Equal(t, "temperature", latest[1].Metric)
+	}
+
+	readings, err := deviceService.Readings(ctx, id, "battery", now.Add(-time.Hour), now)
+	assert.Nil(t, err)
+	assert.Len(t, readings, 2)
+	readings, _ = deviceService.Readings(ctx, id, "battery", now.Add(-25*time.Minute), now)
+	assert.Len(t, readings, 1)
+
+	aggregates, err := deviceService.ReadingAggregates(ctx, id, "battery", now.Add(-time.Hour), now, 0)
+	assert.Nil(t, err)
+	if assert.Len(t, aggregates, 1) {
+		assert.Equal(t, model.ReadingAggregate{Metric: "battery", Start: now.Add(-time.Hour), End: now, Count: 2, Min: 80, Max: 90, Mean: 85, Last: 80}, aggregates[0])
+	}
+	aggregates, _ = deviceService.ReadingAggregates(ctx, id, "battery", now.Add(-time.Hour), now, 10*time.Minute)
+	assert.Len(t, aggregates, 2)
+
+	missing, err := deviceService.LatestReadings(ctx, "missing")
+	assert.Nil(t, err)
+	assert.Nil(t, missing)
+
+	assert.Nil(t, deviceService.DeleteDevice(ctx, id))
+	latest, _ = deviceService.Telemetry.Latest(ctx, id)
+	assert.Empty(t, latest)
+}
+
+func TestShouldKeepSeriesOfDevicesWithSharedPrefixesApart(t *testing.T) {
+	telemetry := adapters.NewTelemetryRepository(timeseries.NewStore(nil))
+	ctx := context.Background()
+	now := time.Now()
+	assert.Nil(t, telemetry.Append(ctx, "a", []model.Reading{{Metric: "battery", Value: 80, At: now}}))
+	assert.Nil(t, telemetry.Append(ctx, "a/b", []model.Reading{{Metric: "temperature", Value: 21, At: now}}))
+
+	latest, err := telemetry.Latest(ctx, "a")
+	assert.Nil(t, err)
+	if assert.Len(t, latest, 1) {
+		assert.Equal(t, "battery", latest[0].Metric)
+	}
+	readings, _ := telemetry.Range(ctx, "a", "b/temperature", now.Add(-time.Hour), now.Add(time.Hour))
+	assert.Empty(t, readings)
+
+	assert.Nil(t, telemetry.Delete(ctx, "a"))
+	latest, _ = telemetry.Latest(ctx, "a/b")
+	if assert.Len(t, latest, 1) {
+		assert.Equal(t, "temperature", latest[0].Metric)
+	}
+}
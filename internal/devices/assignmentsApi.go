@@ -0,0 +1,146 @@
+package devices
+
+import (
+	"devices_crud/internal/devices/model"
+	"devices_crud/internal/logging"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func BuildAssignmentsRoutes(router *gin.RouterGroup, devicesDeps *DependencyTree) {
+	devicesRouter := &DevicesRouter{
+		devicesService: devicesDeps.DeviceSerivce,
+		logger:         logging.Component(devicesDeps.Logger, "rest"),
+	}
+
+	router.GET("", devicesRouter.listAssigneeAssignments)
+	router.GET("/overdue", devicesRouter.listOverdueAssignments)
+}
+
+func (dr *DevicesRouter) checkOutDevice(c *gin.Context) {
+	request := &model.CheckOutRequest{}
+	if !dr.bindAssignmentRequest(c, request) {
+		return
+	}
+
+	assignment, err := dr.devicesService.CheckOutDevice(c.Request.Context(), c.Param("id"), request)
+	dr.respondWithAssignment(c, 201, assignment, err, "Error checking out device")
+}
+
+func (dr *DevicesRouter) checkInDevice(c *gin.Context) {
+	request := &model.CheckInRequest{}
+	if c.Request.ContentLength != 0 && !dr.bindAssignmentRequest(c, request) {
+		return
+	}
+
+	assignment, err := dr.devicesService.CheckInDevice(c.Request.Context(), c.Param("id"), request)
+	dr.respondWithAssignment(c, 200, assignment, err, "Error checking in device")
+}
+
+func (dr *DevicesRouter) transferDevice(c *gin.Context) {
+	request := &model.CheckOutRequest{}
+	if !dr.bindAssignmentRequest(c, request) {
+		return
+	}
+
+	assignment, err := dr.devicesService.TransferDevice(c.Request.Context(), c.Param("id"), request)
+	dr.respondWithAssignment(c, 201, assignment, err, "Error transferring device")
+}
+
+func (dr *DevicesRouter) listDeviceAssignments(c *gin.Context) {
+	device, err := dr.devicesService.GetDevice(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		dr.respondWithError(c, err, "Error getting device")
+		return
+	}
+	if device == nil {
+		dr.logger.InfoContext(c.Request.Context(), "Device not found")
+		c.JSON(404, gin.H{
+			"message": "Device not found",
+			"code":    "NOT_FOUND",
+		})
+		return
+	}
+
+	assignments, err := dr.devicesService.DeviceAssignments(c.Request.Context(), device.ID)
+	if err != nil {
+		dr.respondWithError(c, err, "Error getting assignments")
+		return
+	}
+
+	c.JSON(200, assignments)
+}
+
+func (dr *DevicesRouter) listAssigneeAssignments(c *gin.Context) {
+	assignee := c.Query("assignee")
+	if assignee == "" {
+		dr.logger.InfoContext(c.Request.Context(), "Missing assignee")
+		c.JSON(400, gin.H{
+			"message": "assignee param is required",
+		})
+		return
+	}
+
+	assignments, err := dr.devicesService.AssigneeAssignments(c.Request.Context(), assignee)
+	if err != nil {
+		dr.respondWithError(c, err, "Error getting assignments")
+		return
+	}
+
+	c.JSON(200, assignments)
+}
+
+// listOverdueAssignments lists the assignments overdue now, or at the RFC 3339
+// time of the at param.
+func (dr *DevicesRouter) listOverdueAssignments(c *gin.Context) {
+	at := time.Now()
+	if param := c.Query("at"); param != "" {
+		parsed, err := time.Parse(time.RFC3339, param)
+		if err != nil {
+			dr.logger.InfoContext(c.Request.Context(), "Invalid overdue time", "error", err)
+			c.JSON(400, gin.H{
+				"message": "at must be an RFC 3339 timestamp",
+			})
+			return
+		}
+		at = parsed
+	}
+
+	assignments, err := dr.devicesService.OverdueAssignments(c.Request.Context(), at)
+	if err != nil {
+		dr.respondWithError(c, err, "Error getting overdue assignments")
+		return
+	}
+
+	c.JSON(200, assignments)
+}
+
+func (dr *DevicesRouter) bindAssignmentRequest(c *gin.Context, request interface{}) bool {
+	if err := c.BindJSON(request); err != nil {
+		dr.logger.InfoContext(c.Request.Context(), "Invalid assignment", "error", err)
+		c.JSON(400, gin.H{
+			"message": "Error binding assignment",
+		})
+		return false
+	}
+	return true
+}
+
+func (dr *DevicesRouter) respondWithAssignment(c *gin.Context, status int, assignment *model.Assignment, err error, message string) {
+	if err != nil {
+		dr.respondWithError(c, err, message)
+		return
+	}
+
+	if assignment == nil {
+		dr.logger.InfoContext(c.Request.Context(), "Device not found")
+		c.JSON(404, gin.H{
+			"message": "Device not found",
+			"code":    "NOT_FOUND",
+		})
+		return
+	}
+
+	c.JSON(status, assignment)
+}
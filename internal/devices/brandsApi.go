@@ -0,0 +1,140 @@
+package devices
+
+import (
+	"devices_crud/internal/devices/model"
+	"devices_crud/internal/logging"
+
+	"github.com/gin-gonic/gin"
+)
+
+func BuildBrandsRoutes(router *gin.RouterGroup, devicesDeps *DependencyTree) {
+	devicesRouter := &DevicesRouter{
+		devicesService: devicesDeps.DeviceSerivce,
+		logger:         logging.Component(devicesDeps.Logger, "rest"),
+	}
+
+	router.GET("", devicesRouter.listBrands)
+	router.GET("/resolve", devicesRouter.resolveBrand)
+	router.POST("/migrate", devicesRouter.migrateBrands)
+	router.GET("/:name", devicesRouter.getBrand)
+	router.PUT("/:name", devicesRouter.saveBrand)
+	router.DELETE("/:name", devicesRouter.deleteBrand)
+}
+
+func (dr *DevicesRouter) listBrands(c *gin.Context) {
+	brands, err := dr.devicesService.ListBrands(c.Request.Context())
+	if err != nil {
+		dr.respondWithError(c, err, "Error getting brands")
+		return
+	}
+
+	c.JSON(200, brands)
+}
+
+func (dr *DevicesRouter) getBrand(c *gin.Context) {
+	brand, err := dr.devicesService.GetBrand(c.Request.Context(), c.Param("name"))
+	if err != nil {
+		dr.respondWithError(c, err, "Error getting brand")
+		return
+	}
+	if brand == nil {
+		dr.brandNotFound(c, c.Param("name"))
+		return
+	}
+
+	c.JSON(200, brand)
+}
+
+// resolveBrand finds the catalog brand the name param is a spelling of and,
+// with the model param, the canonical name of the model.
+func (dr *DevicesRouter) resolveBrand(c *gin.Context) {
+	name := c.Query("name")
+	if name == "" {
+		dr.logger.InfoContext(c.Request.Context(), "Missing brand name")
+		c.JSON(400, gin.H{
+			"message": "name param is required",
+		})
+		return
+	}
+
+	brand, err := dr.devicesService.ResolveBrand(c.Request.Context(), name)
+	if err != nil {
+		dr.respondWithError(c, err, "Error resolving brand")
+		return
+	}
+	if brand == nil {
+		dr.brandNotFound(c, name)
+		return
+	}
+
+	response := gin.H{
+		"name":  brand.Name,
+		"brand": brand,
+	}
+	if deviceModel := brand.Model(c.Query("model")); deviceModel != nil {
+		response["model"] = deviceModel.Name
+	}
+	c.JSON(200, response)
+}
+
+func (dr *DevicesRouter) saveBrand(c *gin.Context) {
+	var brand *model.Brand
+	err := c.BindJSON(&brand)
+	if err != nil {
+		dr.logger.InfoContext(c.Request.Context(), "Invalid brand", "error", err)
+		c.JSON(400, gin.H{
+			"message": "Error binding brand",
+		})
+		return
+	}
+
+	brand.Name = c.Param("name")
+	saved, err := dr.devicesService.SaveBrand(c.Request.Context(), brand)
+	if err != nil {
+		dr.respondWithError(c, err, "Error saving brand")
+		return
+	}
+
+	c.JSON(200, saved)
+}
+
+func (dr *DevicesRouter) deleteBrand(c *gin.Context) {
+	err := dr.devicesService.DeleteBrand(c.Request.Context(), c.Param("name"))
+	if err != nil {
+		dr.respondWithError(c, err, "Error deleting brand")
+		return
+	}
+
+	c.JSON(204, gin.H{})
+}
+
+// migrateBrands renames the brands of stored devices to their catalog names.
+// It is a dry run unless the body says otherwise.
+func (dr *DevicesRouter) migrateBrands(c *gin.Context) {
+	request := &model.MigrateBrandsRequest{DryRun: true}
+	if c.Request.ContentLength != 0 {
+		if err := c.BindJSON(request); err != nil {
+			dr.logger.InfoContext(c.Request.Context(), "Invalid brand migration", "error", err)
+			c.JSON(400, gin.H{
+				"message": "Error binding brand migration",
+			})
+			return
+		}
+	}
+
+	migration, err := dr.devicesService.MigrateBrands(c.Request.Context(), request)
+	if err != nil {
+		dr.respondWithError(c, err, "Error migrating brands")
+		return
+	}
+
+	c.JSON(200, migration)
+}
+
+func (dr *DevicesRouter) brandNotFound(c *gin.Context, name string) {
+	dr.logger.InfoContext(c.Request.Context(), "Brand not found", "brand", name)
+	c.JSON(404, gin.H{
+		"message": "Brand not found",
+		"code":    "NOT_FOUND",
+	})
+}
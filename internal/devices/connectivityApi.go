@@ -0,0 +1,33 @@
+package devices
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+func (dr *DevicesRouter) heartbeat(c *gin.Context) {
+	status, err := dr.devicesService.Heartbeat(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		dr.respondWithError(c, err, "Error recording heartbeat")
+		return
+	}
+	if status == nil {
+		dr.deviceNotFound(c)
+		return
+	}
+
+	c.JSON(200, status)
+}
+
+func (dr *DevicesRouter) getConnectivity(c *gin.Context) {
+	status, err := dr.devicesService.DeviceConnectivity(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		dr.respondWithError(c, err, "Error getting connectivity")
+		return
+	}
+	if status == nil {
+		dr.deviceNotFound(c)
+		return
+	}
+
+	c.JSON(200, status)
+}
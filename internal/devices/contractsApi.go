@@ -0,0 +1,99 @@
+package devices
+
+import (
+	"devices_crud/internal/devices/model"
+	"devices_crud/internal/logging"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func BuildContractsRoutes(router *gin.RouterGroup, devicesDeps *DependencyTree) {
+	devicesRouter := &DevicesRouter{
+		devicesService: devicesDeps.DeviceSerivce,
+		logger:         logging.Component(devicesDeps.Logger, "rest"),
+	}
+
+	router.GET("/expiring", devicesRouter.listExpiringContracts)
+}
+
+func (dr *DevicesRouter) listContracts(c *gin.Context) {
+	if _, ok := dr.findDevice(c); !ok {
+		return
+	}
+
+	contracts, err := dr.devicesService.DeviceContracts(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		dr.respondWithError(c, err, "Error getting contracts")
+		return
+	}
+
+	c.JSON(200, contracts)
+}
+
+func (dr *DevicesRouter) addContract(c *gin.Context) {
+	request := &model.ContractRequest{}
+	if err := c.BindJSON(request); err != nil {
+		dr.logger.InfoContext(c.Request.Context(), "Invalid contract", "error", err)
+		c.JSON(400, gin.H{
+			"message": "Error binding contract",
+		})
+		return
+	}
+
+	contract, err := dr.devicesService.AddContract(c.Request.Context(), c.Param("id"), request)
+	if err != nil {
+		dr.respondWithError(c, err, "Error adding contract")
+		return
+	}
+	if contract == nil {
+		dr.deviceNotFound(c)
+		return
+	}
+
+	c.JSON(201, contract)
+}
+
+func (dr *DevicesRouter) deleteContract(c *gin.Context) {
+	contract, err := dr.devicesService.DeleteContract(c.Request.Context(), c.Param("id"), c.Param("contractId"))
+	if err != nil {
+		dr.respondWithError(c, err, "Error deleting contract")
+		return
+	}
+	if contract == nil {
+		dr.logger.InfoContext(c.Request.Context(), "Contract not found", "contract_id", c.Param("contractId"))
+		c.JSON(404, gin.H{
+			"message": "Contract not found",
+			"code":    "NOT_FOUND",
+		})
+		return
+	}
+
+	c.JSON(204, gin.H{})
+}
+
+// listExpiringContracts lists the contracts ending within the days param, 90
+// by default, of the kind param if given.
+func (dr *DevicesRouter) listExpiringContracts(c *gin.Context) {
+	days := 90
+	if param := c.Query("days"); param != "" {
+		parsed, err := strconv.Atoi(param)
+		if err != nil {
+			dr.logger.InfoContext(c.Request.Context(), "Invalid expiry horizon", "error", err)
+			c.JSON(400, gin.H{
+				"message": "days must be a number",
+			})
+			return
+		}
+		days = parsed
+	}
+
+	contracts, err := dr.devicesService.ExpiringContracts(c.Request.Context(), time.Now(), days, model.ContractKind(c.Query("kind")))
+	if err != nil {
+		dr.respondWithError(c, err, "Error getting expiring contracts")
+		return
+	}
+
+	c.JSON(200, contracts)
+}
@@ -1,19 +1,81 @@
 package devices
 
 import (
+	"context"
+	"devices_crud/internal/auth"
 	"devices_crud/internal/devices/app"
+	"devices_crud/internal/devices/app/adapters"
 	"devices_crud/internal/devices/app/ports"
-	"log"
+	"devices_crud/internal/devices/model"
+	"devices_crud/internal/health"
+	"devices_crud/internal/logging"
+	"devices_crud/internal/metrics"
+	"devices_crud/internal/ratelimit"
+	"devices_crud/internal/search"
+	"devices_crud/internal/tenancy"
+	"devices_crud/internal/timeseries"
+	"devices_crud/internal/tracing"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
 )
 
 type DeviceDependencies struct {
 	UseMocks bool
-	Logger   *log.Logger
+	Logger   *slog.Logger
+	// Authenticator is enforced on every device endpoint of both drivers. A nil
+	// Authenticator leaves the API unauthenticated.
+	Authenticator auth.Authenticator
+	// Authorizer checks the permissions of authenticated principals. A nil
+	// Authorizer allows every operation.
+	Authorizer *auth.Authorizer
+	// Quotas limits the number of devices per tenant, nil means unlimited.
+	Quotas *tenancy.Quotas
+	// RateLimiter limits requests per client on both drivers, nil disables it.
+	RateLimiter *ratelimit.Limiter
+	// Metrics instruments the drivers and the repository, nil disables it.
+	Metrics *metrics.Metrics
+	// TracerProvider traces the drivers, the service and the repository, nil
+	// disables tracing.
+	TracerProvider trace.TracerProvider
+	// ReadinessTimeout bounds each readiness check, zero means two seconds.
+	ReadinessTimeout time.Duration
+	// ContractExpiryInterval is how often the contract expiry job runs, zero
+	// disables it.
+	ContractExpiryInterval time.Duration
+	// ContractExpiryWindows are the days before the end of a contract its
+	// expiry is notified at, app.DefaultExpiryWindows when empty.
+	ContractExpiryWindows []int
+	// Telemetry stores device readings. Nil keeps them in memory, downsampled
+	// according to TelemetryTiers.
+	Telemetry ports.TelemetryRepository
+	// Connectivity decides when devices which stopped sending heartbeats are
+	// offline.
+	Connectivity model.ConnectivityPolicy
+	// ConnectivityCheckInterval is how often devices missing their heartbeat
+	// are looked for, zero disables the check.
+	ConnectivityCheckInterval time.Duration
+	// TelemetryTiers are the resolutions and retentions of the in-memory
+	// readings, timeseries.DefaultTiers when empty.
+	TelemetryTiers []timeseries.Tier
+	// UniqueKeys are the fields no two devices of a tenant may share, e.g.
+	// brand and serial number.
+	UniqueKeys []model.UniquenessKey
 }
 
 type DependencyTree struct {
-	DeviceSerivce *app.DeviceService
-	Logger        *log.Logger
+	DeviceSerivce  *app.DeviceService
+	Logger         *slog.Logger
+	Authenticator  auth.Authenticator
+	Authorizer     *auth.Authorizer
+	RateLimiter    *ratelimit.Limiter
+	Metrics        *metrics.Metrics
+	TracerProvider trace.TracerProvider
+	// Health runs the readiness checks. Background workers register with it.
+	Health *health.Checker
 }
 
 func NewDevicesDependencies(deps *DeviceDependencies) *DependencyTree {
@@ -23,15 +85,114 @@ func NewDevicesDependencies(deps *DeviceDependencies) *DependencyTree {
 
 	var service *app.DeviceService
 
+	var repository ports.DevicesRepository
+	var deviceTypes ports.DeviceTypesRepository
+	var assignments ports.AssignmentsRepository
+	var locations ports.LocationsRepository
+	var brands ports.BrandsRepository
+	var relationships ports.RelationshipsRepository
+	var maintenance ports.MaintenanceRepository
+	var contracts ports.ContractsRepository
+	var software ports.SoftwareRepository
+	var redirects ports.RedirectsRepository
 	if deps.UseMocks {
-		service = app.NewDeviceService(ports.NewDevicesRepositoryMock(), deps.Logger)
+		repository = ports.NewDevicesRepositoryMock()
+		deviceTypes = ports.NewDeviceTypesRepositoryMock()
+		assignments = ports.NewAssignmentsRepositoryMock()
+		locations = ports.NewLocationsRepositoryMock()
+		brands = ports.NewBrandsRepositoryMock()
+		relationships = ports.NewRelationshipsRepositoryMock()
+		maintenance = ports.NewMaintenanceRepositoryMock()
+		contracts = ports.NewContractsRepositoryMock()
+		software = ports.NewSoftwareRepositoryMock()
+		redirects = ports.NewRedirectsRepositoryMock()
 	} else {
 		panic("We don't have a real implementation yet")
 	}
 
+	readinessTimeout := deps.ReadinessTimeout
+	if readinessTimeout == 0 {
+		readinessTimeout = 2 * time.Second
+	}
+	checker := health.NewChecker(readinessTimeout)
+	if migrator, ok := repository.(ports.Migrator); ok {
+		checker.Register("migrations", pendingMigrations(migrator))
+	}
+
+	index := search.NewIndex(search.DefaultBoosts)
+	repository, err := adapters.NewIndexedDevicesRepository(repository, index)
+	if err != nil {
+		panic(err)
+	}
+
+	if deps.Metrics != nil {
+		if err := deps.Metrics.Register(metrics.NewDevicesCollector(repository)); err != nil {
+			panic(err)
+		}
+		repository = adapters.NewInstrumentedDevicesRepository(repository, deps.Metrics)
+	}
+	if deps.TracerProvider != nil {
+		repository = adapters.NewTracedDevicesRepository(repository, deps.TracerProvider)
+	}
+
+	checker.Register("repository", repository.Ping)
+
+	service = app.NewDeviceService(repository, logging.Component(deps.Logger, "service"))
+	service.Authorizer = deps.Authorizer
+	service.Quotas = deps.Quotas
+	service.Index = index
+	service.DeviceTypes = deviceTypes
+	service.Assignments = assignments
+	service.Locations = locations
+	service.Brands = brands
+	service.Relationships = relationships
+	service.Maintenance = maintenance
+	service.Contracts = contracts
+	service.Telemetry = deps.Telemetry
+	if service.Telemetry == nil {
+		service.Telemetry = adapters.NewTelemetryRepository(timeseries.NewStore(deps.TelemetryTiers))
+	}
+	service.Connectivity = deps.Connectivity
+	service.Software = software
+	service.UniqueKeys = deps.UniqueKeys
+	service.Redirects = redirects
+	service.Tracer = tracing.Tracer(deps.TracerProvider, "service")
+
+	if deps.ContractExpiryInterval > 0 {
+		// The job runs as long as the process, a stalled job fails readiness.
+		worker := checker.RegisterWorker("contract-expiry", 2*deps.ContractExpiryInterval)
+		job := app.NewContractExpiryJob(service, deps.ContractExpiryInterval, deps.ContractExpiryWindows, worker)
+		go job.Run(context.Background())
+	}
+	if deps.ConnectivityCheckInterval > 0 {
+		worker := checker.RegisterWorker("connectivity", 2*deps.ConnectivityCheckInterval)
+		go app.NewConnectivityMonitor(service, deps.ConnectivityCheckInterval, worker).Run(context.Background())
+	}
+
 	return &DependencyTree{
-		DeviceSerivce: service,
-		Logger:        deps.Logger,
+		DeviceSerivce:  service,
+		Logger:         deps.Logger,
+		Authenticator:  deps.Authenticator,
+		Authorizer:     deps.Authorizer,
+		RateLimiter:    deps.RateLimiter,
+		Metrics:        deps.Metrics,
+		TracerProvider: deps.TracerProvider,
+		Health:         checker,
 	}
 
 }
+
+// pendingMigrations fails the readiness check until the storage schema is up
+// to date.
+func pendingMigrations(migrator ports.Migrator) health.CheckFunc {
+	return func(ctx context.Context) error {
+		pending, err := migrator.PendingMigrations(ctx)
+		if err != nil {
+			return err
+		}
+		if len(pending) > 0 {
+			return fmt.Errorf("%d pending migrations: %s", len(pending), strings.Join(pending, ", "))
+		}
+		return nil
+	}
+}
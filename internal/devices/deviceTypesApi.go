@@ -0,0 +1,80 @@
+package devices
+
+import (
+	"devices_crud/internal/devices/model"
+	"devices_crud/internal/logging"
+
+	"github.com/gin-gonic/gin"
+)
+
+func BuildDeviceTypesRoutes(router *gin.RouterGroup, devicesDeps *DependencyTree) {
+	devicesRouter := &DevicesRouter{
+		devicesService: devicesDeps.DeviceSerivce,
+		logger:         logging.Component(devicesDeps.Logger, "rest"),
+	}
+
+	router.GET("", devicesRouter.listDeviceTypes)
+	router.GET("/:name", devicesRouter.getDeviceType)
+	router.PUT("/:name", devicesRouter.saveDeviceType)
+	router.DELETE("/:name", devicesRouter.deleteDeviceType)
+}
+
+func (dr *DevicesRouter) listDeviceTypes(c *gin.Context) {
+	deviceTypes, err := dr.devicesService.ListDeviceTypes(c.Request.Context())
+	if err != nil {
+		dr.respondWithError(c, err, "Error getting device types")
+		return
+	}
+
+	c.JSON(200, deviceTypes)
+}
+
+func (dr *DevicesRouter) getDeviceType(c *gin.Context) {
+	deviceType, err := dr.devicesService.GetDeviceType(c.Request.Context(), c.Param("name"))
+	if err != nil {
+		dr.respondWithError(c, err, "Error getting device type")
+		return
+	}
+
+	if deviceType == nil {
+		dr.logger.InfoContext(c.Request.Context(), "Device type not found", "device_type", c.Param("name"))
+		c.JSON(404, gin.H{
+			"message": "Device type not found",
+			"code":    "NOT_FOUND",
+		})
+		return
+	}
+
+	c.JSON(200, deviceType)
+}
+
+func (dr *DevicesRouter) saveDeviceType(c *gin.Context) {
+	var deviceType *model.DeviceType
+	err := c.BindJSON(&deviceType)
+	if err != nil {
+		dr.logger.InfoContext(c.Request.Context(), "Invalid device type", "error", err)
+		c.JSON(400, gin.H{
+			"message": "Error binding device type",
+		})
+		return
+	}
+
+	deviceType.Name = c.Param("name")
+	saved, err := dr.devicesService.SaveDeviceType(c.Request.Context(), deviceType)
+	if err != nil {
+		dr.respondWithError(c, err, "Error saving device type")
+		return
+	}
+
+	c.JSON(200, saved)
+}
+
+func (dr *DevicesRouter) deleteDeviceType(c *gin.Context) {
+	err := dr.devicesService.DeleteDeviceType(c.Request.Context(), c.Param("name"))
+	if err != nil {
+		dr.respondWithError(c, err, "Error deleting device type")
+		return
+	}
+
+	c.JSON(204, gin.H{})
+}
@@ -0,0 +1,109 @@
+package devices
+
+import (
+	"devices_crud/internal/devices/model"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// listDuplicates pairs the devices which may be the same one, scoring at
+// least the threshold param.
+func (dr *DevicesRouter) listDuplicates(c *gin.Context) {
+	threshold, ok := dr.duplicateThreshold(c)
+	if !ok {
+		return
+	}
+
+	candidates, err := dr.devicesService.DuplicateCandidates(c.Request.Context(), threshold)
+	if err != nil {
+		dr.respondWithError(c, err, "Error finding duplicates")
+		return
+	}
+
+	c.JSON(200, candidates)
+}
+
+func (dr *DevicesRouter) listDeviceDuplicates(c *gin.Context) {
+	threshold, ok := dr.duplicateThreshold(c)
+	if !ok {
+		return
+	}
+
+	candidates, err := dr.devicesService.DeviceDuplicates(c.Request.Context(), c.Param("id"), threshold)
+	if err != nil {
+		dr.respondWithError(c, err, "Error finding duplicates")
+		return
+	}
+	if candidates == nil {
+		dr.deviceNotFound(c)
+		return
+	}
+
+	c.JSON(200, candidates)
+}
+
+// mergeDevices merges the device of the sourceId into the device and
+// responds with the merged device.
+func (dr *DevicesRouter) mergeDevices(c *gin.Context) {
+	request := &model.MergeRequest{}
+	if err := c.BindJSON(request); err != nil {
+		dr.logger.InfoContext(c.Request.Context(), "Invalid merge", "error", err)
+		c.JSON(400, gin.H{
+			"message": "Error binding merge",
+		})
+		return
+	}
+
+	device, err := dr.devicesService.MergeDevices(c.Request.Context(), c.Param("id"), request)
+	if err != nil {
+		dr.respondWithError(c, err, "Error merging devices")
+		return
+	}
+	if device == nil {
+		dr.deviceNotFound(c)
+		return
+	}
+
+	c.JSON(200, device)
+}
+
+// redirectMerged redirects to the device a device was merged into, and
+// reports whether the ID of the route is the one of a merged device.
+func (dr *DevicesRouter) redirectMerged(c *gin.Context) bool {
+	id := c.Param("id")
+	redirect, err := dr.devicesService.DeviceRedirect(c.Request.Context(), id)
+	if err != nil {
+		dr.respondWithError(c, err, "Error getting device")
+		return true
+	}
+	if redirect == nil {
+		return false
+	}
+
+	dr.logger.InfoContext(c.Request.Context(), "Device merged", "merged_into", redirect.ToID)
+	c.Header("Location", strings.TrimSuffix(c.Request.URL.Path, id)+redirect.ToID)
+	c.JSON(301, gin.H{
+		"message": "Device merged into " + redirect.ToID,
+		"code":    "MERGED",
+		"id":      redirect.ToID,
+	})
+	return true
+}
+
+func (dr *DevicesRouter) duplicateThreshold(c *gin.Context) (float64, bool) {
+	param := c.Query("threshold")
+	if param == "" {
+		return 0, true
+	}
+	threshold, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		dr.logger.InfoContext(c.Request.Context(), "Invalid duplicate threshold", "error", err)
+		c.JSON(400, gin.H{
+			"message": "threshold must be a number",
+		})
+		return 0, false
+	}
+	return threshold, true
+}
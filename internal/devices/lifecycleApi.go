@@ -0,0 +1,53 @@
+package devices
+
+import (
+	"devices_crud/internal/devices/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// getLifecycle lists the statuses in lifecycle order and the transitions
+// allowed from each of them.
+func (dr *DevicesRouter) getLifecycle(c *gin.Context) {
+	c.JSON(200, gin.H{
+		"statuses":    model.Statuses,
+		"transitions": model.Transitions,
+	})
+}
+
+func (dr *DevicesRouter) getTransitions(c *gin.Context) {
+	device, err := dr.devicesService.GetDevice(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		dr.respondWithError(c, err, "Error getting device")
+		return
+	}
+	if device == nil {
+		dr.logger.InfoContext(c.Request.Context(), "Device not found")
+		c.JSON(404, gin.H{
+			"message": "Device not found",
+			"code":    "NOT_FOUND",
+		})
+		return
+	}
+
+	history := device.StatusHistory
+	if history == nil {
+		history = []model.StatusChange{}
+	}
+	c.JSON(200, history)
+}
+
+func (dr *DevicesRouter) transitionDevice(c *gin.Context) {
+	request := &model.TransitionRequest{}
+	err := c.BindJSON(request)
+	if err != nil {
+		dr.logger.InfoContext(c.Request.Context(), "Invalid transition", "error", err)
+		c.JSON(400, gin.H{
+			"message": "Error binding transition",
+		})
+		return
+	}
+
+	device, err := dr.devicesService.TransitionDevice(c.Request.Context(), c.Param("id"), request)
+	dr.respondWithUpdatedDevice(c, device, err, "Error changing device status")
+}
@@ -0,0 +1,195 @@
+package devices
+
+import (
+	"devices_crud/internal/devices/model"
+	"devices_crud/internal/logging"
+
+	"github.com/gin-gonic/gin"
+)
+
+func BuildLocationsRoutes(router *gin.RouterGroup, devicesDeps *DependencyTree) {
+	devicesRouter := &DevicesRouter{
+		devicesService: devicesDeps.DeviceSerivce,
+		logger:         logging.Component(devicesDeps.Logger, "rest"),
+	}
+
+	router.GET("", devicesRouter.listLocations)
+	router.GET("/resolve", devicesRouter.resolveLocation)
+	router.POST("", devicesRouter.createLocation)
+	router.GET("/:id", devicesRouter.getLocation)
+	router.GET("/:id/path", devicesRouter.getLocationPath)
+	router.GET("/:id/children", devicesRouter.listChildLocations)
+	router.GET("/:id/devices", devicesRouter.listLocationDevices)
+	router.POST("/:id/move", devicesRouter.moveLocation)
+	router.DELETE("/:id", devicesRouter.deleteLocation)
+}
+
+// listLocations lists the children of the parentId param, the sites without
+// it.
+func (dr *DevicesRouter) listLocations(c *gin.Context) {
+	locations, err := dr.devicesService.ListLocations(c.Request.Context(), c.Query("parentId"))
+	if err != nil {
+		dr.respondWithError(c, err, "Error getting locations")
+		return
+	}
+
+	c.JSON(200, locations)
+}
+
+func (dr *DevicesRouter) listChildLocations(c *gin.Context) {
+	location, ok := dr.findLocation(c)
+	if !ok {
+		return
+	}
+
+	locations, err := dr.devicesService.ListLocations(c.Request.Context(), location.ID)
+	if err != nil {
+		dr.respondWithError(c, err, "Error getting locations")
+		return
+	}
+
+	c.JSON(200, locations)
+}
+
+// resolveLocation finds a location by a path of names such as
+// "HQ/Building A/Room 101".
+func (dr *DevicesRouter) resolveLocation(c *gin.Context) {
+	path := c.Query("path")
+	if path == "" {
+		dr.logger.InfoContext(c.Request.Context(), "Missing location path")
+		c.JSON(400, gin.H{
+			"message": "path param is required",
+		})
+		return
+	}
+
+	location, err := dr.devicesService.FindLocationByPath(c.Request.Context(), path)
+	dr.respondWithLocation(c, 200, location, err, "Error resolving location")
+}
+
+func (dr *DevicesRouter) createLocation(c *gin.Context) {
+	request := &model.NewLocationRequest{}
+	if !dr.bindLocationRequest(c, request) {
+		return
+	}
+
+	location, err := dr.devicesService.CreateLocation(c.Request.Context(), request)
+	dr.respondWithLocation(c, 201, location, err, "Error creating location")
+}
+
+func (dr *DevicesRouter) getLocation(c *gin.Context) {
+	location, err := dr.devicesService.GetLocation(c.Request.Context(), c.Param("id"))
+	dr.respondWithLocation(c, 200, location, err, "Error getting location")
+}
+
+func (dr *DevicesRouter) getLocationPath(c *gin.Context) {
+	path, err := dr.devicesService.LocationPath(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		dr.respondWithError(c, err, "Error getting location path")
+		return
+	}
+	if path == nil {
+		dr.locationNotFound(c)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"path":      path.String(),
+		"locations": path,
+	})
+}
+
+// listLocationDevices lists the devices in the whole subtree of the location,
+// or only those directly in it with recursive=false.
+func (dr *DevicesRouter) listLocationDevices(c *gin.Context) {
+	location, ok := dr.findLocation(c)
+	if !ok {
+		return
+	}
+
+	devices, err := dr.devicesService.LocationDevices(c.Request.Context(), location.ID, c.Query("recursive") != "false")
+	if err != nil {
+		dr.respondWithError(c, err, "Error getting location devices")
+		return
+	}
+
+	c.JSON(200, devices)
+}
+
+func (dr *DevicesRouter) moveLocation(c *gin.Context) {
+	request := &model.MoveLocationRequest{}
+	if !dr.bindLocationRequest(c, request) {
+		return
+	}
+
+	location, err := dr.devicesService.MoveLocation(c.Request.Context(), c.Param("id"), request)
+	dr.respondWithLocation(c, 200, location, err, "Error moving location")
+}
+
+func (dr *DevicesRouter) deleteLocation(c *gin.Context) {
+	err := dr.devicesService.DeleteLocation(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		dr.respondWithError(c, err, "Error deleting location")
+		return
+	}
+
+	c.JSON(204, gin.H{})
+}
+
+func (dr *DevicesRouter) moveDevice(c *gin.Context) {
+	request := &model.MoveDeviceRequest{}
+	if !dr.bindLocationRequest(c, request) {
+		return
+	}
+
+	device, err := dr.devicesService.MoveDevice(c.Request.Context(), c.Param("id"), request)
+	dr.respondWithUpdatedDevice(c, device, err, "Error moving device")
+}
+
+// findLocation responds with 404 and returns false when the location of the
+// route does not exist.
+func (dr *DevicesRouter) findLocation(c *gin.Context) (*model.Location, bool) {
+	location, err := dr.devicesService.GetLocation(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		dr.respondWithError(c, err, "Error getting location")
+		return nil, false
+	}
+	if location == nil {
+		dr.locationNotFound(c)
+		return nil, false
+	}
+	return location, true
+}
+
+func (dr *DevicesRouter) bindLocationRequest(c *gin.Context, request interface{}) bool {
+	if err := c.BindJSON(request); err != nil {
+		dr.logger.InfoContext(c.Request.Context(), "Invalid location", "error", err)
+		c.JSON(400, gin.H{
+			"message": "Error binding location",
+		})
+		return false
+	}
+	return true
+}
+
+func (dr *DevicesRouter) respondWithLocation(c *gin.Context, status int, location *model.Location, err error, message string) {
+	if err != nil {
+		dr.respondWithError(c, err, message)
+		return
+	}
+
+	if location == nil {
+		dr.locationNotFound(c)
+		return
+	}
+
+	c.JSON(status, location)
+}
+
+func (dr *DevicesRouter) locationNotFound(c *gin.Context) {
+	dr.logger.InfoContext(c.Request.Context(), "Location not found")
+	c.JSON(404, gin.H{
+		"message": "Location not found",
+		"code":    "NOT_FOUND",
+	})
+}
@@ -0,0 +1,197 @@
+package devices
+
+import (
+	"devices_crud/internal/devices/model"
+	"devices_crud/internal/logging"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxMaintenanceDays bounds the days param of the maintenance task lists.
+const maxMaintenanceDays = 366
+
+func BuildMaintenanceRoutes(router *gin.RouterGroup, devicesDeps *DependencyTree) {
+	devicesRouter := &DevicesRouter{
+		devicesService: devicesDeps.DeviceSerivce,
+		logger:         logging.Component(devicesDeps.Logger, "rest"),
+	}
+
+	router.GET("/upcoming", devicesRouter.listUpcomingMaintenance)
+	router.GET("/overdue", devicesRouter.listOverdueMaintenance)
+}
+
+func (dr *DevicesRouter) listMaintenancePlans(c *gin.Context) {
+	if _, ok := dr.findDevice(c); !ok {
+		return
+	}
+
+	plans, err := dr.devicesService.DeviceMaintenancePlans(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		dr.respondWithError(c, err, "Error getting maintenance plans")
+		return
+	}
+
+	c.JSON(200, plans)
+}
+
+func (dr *DevicesRouter) createMaintenancePlan(c *gin.Context) {
+	request := &model.NewMaintenancePlanRequest{}
+	if !dr.bindMaintenanceRequest(c, request) {
+		return
+	}
+
+	plan, err := dr.devicesService.CreateMaintenancePlan(c.Request.Context(), c.Param("id"), request)
+	if err != nil {
+		dr.respondWithError(c, err, "Error creating maintenance plan")
+		return
+	}
+	if plan == nil {
+		dr.deviceNotFound(c)
+		return
+	}
+
+	c.JSON(201, plan)
+}
+
+func (dr *DevicesRouter) deleteMaintenancePlan(c *gin.Context) {
+	plan, err := dr.devicesService.DeleteMaintenancePlan(c.Request.Context(), c.Param("id"), c.Param("planId"))
+	if err != nil {
+		dr.respondWithError(c, err, "Error deleting maintenance plan")
+		return
+	}
+	if plan == nil {
+		dr.logger.InfoContext(c.Request.Context(), "Maintenance plan not found", "plan_id", c.Param("planId"))
+		c.JSON(404, gin.H{
+			"message": "Maintenance plan not found",
+			"code":    "NOT_FOUND",
+		})
+		return
+	}
+
+	c.JSON(204, gin.H{})
+}
+
+func (dr *DevicesRouter) listServiceRecords(c *gin.Context) {
+	if _, ok := dr.findDevice(c); !ok {
+		return
+	}
+
+	records, err := dr.devicesService.ServiceRecords(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		dr.respondWithError(c, err, "Error getting service records")
+		return
+	}
+
+	c.JSON(200, records)
+}
+
+func (dr *DevicesRouter) recordService(c *gin.Context) {
+	request := &model.ServiceRecordRequest{}
+	if !dr.bindMaintenanceRequest(c, request) {
+		return
+	}
+
+	record, err := dr.devicesService.RecordService(c.Request.Context(), c.Param("id"), request)
+	if err != nil {
+		dr.respondWithError(c, err, "Error recording service")
+		return
+	}
+	if record == nil {
+		dr.deviceNotFound(c)
+		return
+	}
+
+	c.JSON(201, record)
+}
+
+// listDeviceMaintenanceTasks lists the tasks of the device due within the
+// number of days of the days param, 30 by default, overdue ones included.
+func (dr *DevicesRouter) listDeviceMaintenanceTasks(c *gin.Context) {
+	until, ok := dr.maintenanceHorizon(c)
+	if !ok {
+		return
+	}
+	if _, ok := dr.findDevice(c); !ok {
+		return
+	}
+
+	tasks, err := dr.devicesService.DeviceMaintenanceTasks(c.Request.Context(), c.Param("id"), until)
+	if err != nil {
+		dr.respondWithError(c, err, "Error getting maintenance tasks")
+		return
+	}
+
+	c.JSON(200, tasks)
+}
+
+// listUpcomingMaintenance lists the tasks of every device due within the
+// number of days of the days param, 30 by default, overdue ones included.
+func (dr *DevicesRouter) listUpcomingMaintenance(c *gin.Context) {
+	until, ok := dr.maintenanceHorizon(c)
+	if !ok {
+		return
+	}
+
+	tasks, err := dr.devicesService.UpcomingMaintenance(c.Request.Context(), until)
+	if err != nil {
+		dr.respondWithError(c, err, "Error getting upcoming maintenance")
+		return
+	}
+
+	c.JSON(200, tasks)
+}
+
+// listOverdueMaintenance lists the tasks overdue now, or at the RFC 3339 time
+// of the at param.
+func (dr *DevicesRouter) listOverdueMaintenance(c *gin.Context) {
+	at := time.Now()
+	if param := c.Query("at"); param != "" {
+		parsed, err := time.Parse(time.RFC3339, param)
+		if err != nil {
+			dr.logger.InfoContext(c.Request.Context(), "Invalid overdue time", "error", err)
+			c.JSON(400, gin.H{
+				"message": "at must be an RFC 3339 timestamp",
+			})
+			return
+		}
+		at = parsed
+	}
+
+	tasks, err := dr.devicesService.OverdueMaintenance(c.Request.Context(), at)
+	if err != nil {
+		dr.respondWithError(c, err, "Error getting overdue maintenance")
+		return
+	}
+
+	c.JSON(200, tasks)
+}
+
+// maintenanceHorizon returns the end of the days param from now.
+func (dr *DevicesRouter) maintenanceHorizon(c *gin.Context) (time.Time, bool) {
+	days := 30
+	if param := c.Query("days"); param != "" {
+		parsed, err := strconv.Atoi(param)
+		if err != nil || parsed < 0 || parsed > maxMaintenanceDays {
+			dr.logger.InfoContext(c.Request.Context(), "Invalid maintenance horizon", "days", param)
+			c.JSON(400, gin.H{
+				"message": "days must be a number between 0 and " + strconv.Itoa(maxMaintenanceDays),
+			})
+			return time.Time{}, false
+		}
+		days = parsed
+	}
+	return time.Now().AddDate(0, 0, days), true
+}
+
+func (dr *DevicesRouter) bindMaintenanceRequest(c *gin.Context, request interface{}) bool {
+	if err := c.BindJSON(request); err != nil {
+		dr.logger.InfoContext(c.Request.Context(), "Invalid maintenance request", "error", err)
+		c.JSON(400, gin.H{
+			"message": "Error binding maintenance request",
+		})
+		return false
+	}
+	return true
+}
@@ -0,0 +1,40 @@
+package model
+
+import "time"
+
+// Assignment records that a device was lent to a person. It is active until
+// the device is checked in or transferred to someone else.
+type Assignment struct {
+	ID       string `json:"id"`
+	DeviceID string `json:"deviceId"`
+	// Assignee identifies the person holding the device, e.g. an email address.
+	Assignee     string     `json:"assignee"`
+	CheckedOutAt time.Time  `json:"checkedOutAt"`
+	DueAt        *time.Time `json:"dueAt,omitempty"`
+	CheckedInAt  *time.Time `json:"checkedInAt,omitempty"`
+	Note         string     `json:"note,omitempty"`
+	// CheckedOutBy is the subject of the principal who lent the device, if any.
+	CheckedOutBy string `json:"checkedOutBy,omitempty"`
+	TenantID     string `json:"tenantId"`
+}
+
+func (a *Assignment) Active() bool {
+	return a.CheckedInAt == nil
+}
+
+// Overdue reports whether the device should have been returned before at.
+func (a *Assignment) Overdue(at time.Time) bool {
+	return a.Active() && a.DueAt != nil && at.After(*a.DueAt)
+}
+
+// CheckOutRequest lends a device, and hands it over to another person when
+// transferring it.
+type CheckOutRequest struct {
+	Assignee string     `json:"assignee"`
+	DueAt    *time.Time `json:"dueAt,omitempty"`
+	Note     string     `json:"note,omitempty"`
+}
+
+type CheckInRequest struct {
+	Note string `json:"note,omitempty"`
+}
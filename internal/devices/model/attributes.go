@@ -0,0 +1,76 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Attributes are the free-form properties of a device, such as serial numbers
+// or MAC addresses. Values are JSON values as decoded by encoding/json.
+type Attributes map[string]interface{}
+
+// Normalize returns a deep copy of a holding only the value types produced by
+// encoding/json, so that values decoded by other drivers (json.Number, int64,
+// typed slices) validate and compare alike.
+func (a Attributes) Normalize() (Attributes, error) {
+	if a == nil {
+		return nil, nil
+	}
+	encoded, err := json.Marshal(a)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidAttributes, err)
+	}
+	var normalized Attributes
+	if err := json.Unmarshal(encoded, &normalized); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidAttributes, err)
+	}
+	return normalized, nil
+}
+
+// Merge returns a copy of a with patch applied: null values remove attributes,
+// other values replace them.
+func (a Attributes) Merge(patch Attributes) Attributes {
+	merged := make(Attributes, len(a)+len(patch))
+	for key, value := range a {
+		merged[key] = value
+	}
+	for key, value := range patch {
+		if value == nil {
+			delete(merged, key)
+		} else {
+			merged[key] = value
+		}
+	}
+	if len(merged) == 0 {
+		return nil
+	}
+	return merged
+}
+
+// Text joins the scalar attribute values, ordered by key, for full-text search.
+func (a Attributes) Text() string {
+	keys := make([]string, 0, len(a))
+	for key := range a {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	values := make([]string, 0, len(keys))
+	for _, key := range keys {
+		switch value := a[key].(type) {
+		case string:
+			values = append(values, value)
+		case float64, bool:
+			values = append(values, fmt.Sprint(value))
+		case []interface{}:
+			for _, item := range value {
+				if text, ok := item.(string); ok {
+					values = append(values, text)
+				}
+			}
+		}
+	}
+	return strings.Join(values, " ")
+}
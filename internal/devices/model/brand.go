@@ -0,0 +1,103 @@
+package model
+
+import (
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Brand is a catalog entry naming a brand canonically. Device brands matching
+// the name or one of the aliases are stored under the canonical name, so that
+// "apple ", "APPLE Inc" and "Apple" are the same brand.
+type Brand struct {
+	Name    string        `json:"name"`
+	Aliases []string      `json:"aliases,omitempty"`
+	Models  []DeviceModel `json:"models,omitempty"`
+	// CreatedAt is set by DeviceService.SaveBrand.
+	CreatedAt time.Time `json:"createdAt"`
+	TenantID  string    `json:"tenantId"`
+}
+
+// DeviceModel is a model of a brand, such as "iPhone 15", with its other
+// spellings.
+type DeviceModel struct {
+	Name    string   `json:"name"`
+	Aliases []string `json:"aliases,omitempty"`
+}
+
+// Matches reports whether name is a spelling of the brand.
+func (b *Brand) Matches(name string) bool {
+	return matches(b.Name, b.Aliases, name)
+}
+
+// Model returns the model of the brand name is a spelling of, nil when there
+// is none.
+func (b *Brand) Model(name string) *DeviceModel {
+	for i := range b.Models {
+		if matches(b.Models[i].Name, b.Models[i].Aliases, name) {
+			return &b.Models[i]
+		}
+	}
+	return nil
+}
+
+func matches(canonical string, aliases []string, name string) bool {
+	key := BrandKey(name)
+	if key == BrandKey(canonical) {
+		return true
+	}
+	for _, alias := range aliases {
+		if key == BrandKey(alias) {
+			return true
+		}
+	}
+	return false
+}
+
+// legalSuffixes are dropped from the end of brand names when comparing them.
+var legalSuffixes = map[string]bool{
+	"inc": true, "incorporated": true, "corp": true, "corporation": true, "co": true, "company": true,
+	"ltd": true, "limited": true, "llc": true, "gmbh": true, "ag": true, "sa": true, "plc": true,
+}
+
+// BrandKey is the form brand and model names are compared in: lower case
+// words of letters and digits without trailing legal suffixes, so that
+// "APPLE Inc." and " apple" both have the key "apple".
+func BrandKey(name string) string {
+	words := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for len(words) > 1 && legalSuffixes[words[len(words)-1]] {
+		words = words[:len(words)-1]
+	}
+	return strings.Join(words, " ")
+}
+
+// CleanBrand trims and collapses the spaces of a brand name which is not in
+// the catalog.
+func CleanBrand(name string) string {
+	return strings.Join(strings.Fields(name), " ")
+}
+
+type MigrateBrandsRequest struct {
+	// DryRun reports the changes without applying them.
+	DryRun bool `json:"dryRun"`
+	// AddMissing merges the spellings of brands which are not in the catalog
+	// into their most common one and adds it to the catalog with the others as
+	// aliases.
+	AddMissing bool `json:"addMissing"`
+}
+
+// BrandMigration reports the devices whose brand a migration renamed, or would
+// rename in a dry run, and the brands it added to the catalog.
+type BrandMigration struct {
+	DryRun  bool          `json:"dryRun"`
+	Changes []BrandChange `json:"changes"`
+	Added   []Brand       `json:"added"`
+}
+
+type BrandChange struct {
+	DeviceID string `json:"deviceId"`
+	From     string `json:"from"`
+	To       string `json:"to"`
+}
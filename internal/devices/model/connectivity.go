@@ -0,0 +1,83 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+// Connectivity is whether a device sends heartbeats, computed from its
+// LastSeenAt and a ConnectivityPolicy.
+type Connectivity string
+
+const (
+	ConnectivityOnline  Connectivity = "online"
+	ConnectivityOffline Connectivity = "offline"
+	// ConnectivityUnknown is the state of devices which never sent a heartbeat.
+	ConnectivityUnknown Connectivity = "unknown"
+)
+
+var Connectivities = []Connectivity{ConnectivityOnline, ConnectivityOffline, ConnectivityUnknown}
+
+// DefaultHeartbeatTimeout is how long after its last heartbeat a device goes
+// offline when the policy does not say otherwise.
+const DefaultHeartbeatTimeout = 5 * time.Minute
+
+// ParseConnectivity checks that connectivity is one of Connectivities.
+func ParseConnectivity(connectivity string) (Connectivity, error) {
+	for _, known := range Connectivities {
+		if string(known) == connectivity {
+			return known, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %q, expected one of %v", ErrInvalidConnectivity, connectivity, Connectivities)
+}
+
+// ConnectivityPolicy decides how long devices stay online after a heartbeat.
+type ConnectivityPolicy struct {
+	// Timeout applies to devices without a type timeout, zero means
+	// DefaultHeartbeatTimeout.
+	Timeout time.Duration
+	// TypeTimeouts override Timeout for the devices of a type, e.g. sensors
+	// reporting hourly.
+	TypeTimeouts map[string]time.Duration
+}
+
+// TimeoutOf returns how long the device stays online after a heartbeat.
+func (p *ConnectivityPolicy) TimeoutOf(device *Device) time.Duration {
+	if timeout, ok := p.TypeTimeouts[device.Type]; ok && device.Type != "" && timeout > 0 {
+		return timeout
+	}
+	if p.Timeout > 0 {
+		return p.Timeout
+	}
+	return DefaultHeartbeatTimeout
+}
+
+// Of returns the connectivity of the device at the given time.
+func (p *ConnectivityPolicy) Of(device *Device, at time.Time) Connectivity {
+	if device.LastSeenAt == nil {
+		return ConnectivityUnknown
+	}
+	if at.Sub(*device.LastSeenAt) > p.TimeoutOf(device) {
+		return ConnectivityOffline
+	}
+	return ConnectivityOnline
+}
+
+// ConnectivityStatus is the connectivity of a device as of At.
+type ConnectivityStatus struct {
+	DeviceID   string       `json:"deviceId"`
+	Status     Connectivity `json:"status"`
+	LastSeenAt *time.Time   `json:"lastSeenAt,omitempty"`
+	// Timeout is how long after LastSeenAt the device goes offline, e.g. "5m0s".
+	Timeout string    `json:"timeout"`
+	At      time.Time `json:"at"`
+}
+
+// ConnectivityChange is emitted when a device goes online or offline.
+type ConnectivityChange struct {
+	Device Device       `json:"device"`
+	From   Connectivity `json:"from"`
+	To     Connectivity `json:"to"`
+	At     time.Time    `json:"at"`
+}
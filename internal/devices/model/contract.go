@@ -0,0 +1,108 @@
+package model
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+type ContractKind string
+
+const (
+	ContractWarranty ContractKind = "warranty"
+	ContractSupport  ContractKind = "support"
+)
+
+var ContractKinds = []ContractKind{ContractWarranty, ContractSupport}
+
+// Contract is a warranty or a support contract covering a device from
+// StartsAt until EndsAt.
+type Contract struct {
+	ID       string       `json:"id"`
+	DeviceID string       `json:"deviceId"`
+	Kind     ContractKind `json:"kind"`
+	Provider string       `json:"provider"`
+	// Reference is the number of the contract at the provider, if any.
+	Reference string    `json:"reference,omitempty"`
+	StartsAt  time.Time `json:"startsAt"`
+	EndsAt    time.Time `json:"endsAt"`
+	// Coverage lists what the contract covers, e.g. parts, labor or onsite.
+	Coverage  []string  `json:"coverage"`
+	CreatedAt time.Time `json:"createdAt"`
+	// CreatedBy is the subject of the principal who added the contract, if any.
+	CreatedBy string `json:"createdBy,omitempty"`
+	TenantID  string `json:"tenantId"`
+}
+
+// Active reports whether the contract covers the device at the given time.
+func (c *Contract) Active(at time.Time) bool {
+	return !at.Before(c.StartsAt) && at.Before(c.EndsAt)
+}
+
+// DaysLeft returns the number of whole days from at until the contract ends,
+// negative once it ended.
+func (c *Contract) DaysLeft(at time.Time) int {
+	return int(math.Floor(c.EndsAt.Sub(at).Hours() / 24))
+}
+
+type ContractRequest struct {
+	Kind      ContractKind `json:"kind"`
+	Provider  string       `json:"provider"`
+	Reference string       `json:"reference,omitempty"`
+	StartsAt  time.Time    `json:"startsAt"`
+	EndsAt    time.Time    `json:"endsAt"`
+	Coverage  []string     `json:"coverage,omitempty"`
+}
+
+// Validate checks the kind, the provider and the period of the request and
+// cleans its coverage.
+func (r *ContractRequest) Validate() error {
+	known := false
+	for _, kind := range ContractKinds {
+		known = known || kind == r.Kind
+	}
+	if !known {
+		return fmt.Errorf("%w: unknown kind %q, expected one of %v", ErrInvalidContract, r.Kind, ContractKinds)
+	}
+	r.Provider = strings.TrimSpace(r.Provider)
+	if r.Provider == "" {
+		return fmt.Errorf("%w: provider is required", ErrInvalidContract)
+	}
+	if r.StartsAt.IsZero() || r.EndsAt.IsZero() {
+		return fmt.Errorf("%w: startsAt and endsAt are required", ErrInvalidContract)
+	}
+	if !r.EndsAt.After(r.StartsAt) {
+		return fmt.Errorf("%w: endsAt must be after startsAt", ErrInvalidContract)
+	}
+
+	coverage := make([]string, 0, len(r.Coverage))
+	seen := map[string]bool{}
+	for _, item := range r.Coverage {
+		item = strings.ToLower(strings.TrimSpace(item))
+		if item != "" && !seen[item] {
+			seen[item] = true
+			coverage = append(coverage, item)
+		}
+	}
+	r.Coverage = coverage
+	return nil
+}
+
+// ExpiringContract is a contract ending soon.
+type ExpiringContract struct {
+	Contract Contract `json:"contract"`
+	DaysLeft int      `json:"daysLeft"`
+	// Renewed is true when another contract of the same kind covers the device
+	// from the end of this one, so that the device does not lose coverage.
+	Renewed bool `json:"renewed"`
+}
+
+// ContractExpiryEvent is emitted when a contract which was not renewed comes
+// within Window days of its end, and with a Window of 0 once it ended.
+type ContractExpiryEvent struct {
+	Contract Contract  `json:"contract"`
+	Window   int       `json:"window"`
+	DaysLeft int       `json:"daysLeft"`
+	At       time.Time `json:"at"`
+}
@@ -7,11 +7,37 @@ type Device struct {
 	Name        string    `json:"name"`
 	DeviceBrand string    `json:"deviceBrand"`
 	CreatedAt   time.Time `json:"createdAt"`
+	TenantID    string    `json:"tenantId"`
+	// Type names the DeviceType whose schema Attributes must satisfy. Untyped
+	// devices accept any attributes.
+	Type       string     `json:"type,omitempty"`
+	Attributes Attributes `json:"attributes,omitempty"`
+	// Labels group devices by team, site, project and the like, see package
+	// labels.
+	Labels map[string]string `json:"labels,omitempty"`
+	// Status only changes through DeviceService.TransitionDevice, which appends
+	// to StatusHistory.
+	Status        Status         `json:"status"`
+	StatusHistory []StatusChange `json:"statusHistory,omitempty"`
+	// LocationID only changes through DeviceService.MoveDevice, which appends
+	// to LocationHistory.
+	LocationID      string           `json:"locationId,omitempty"`
+	LocationHistory []LocationChange `json:"locationHistory,omitempty"`
+	// LastSeenAt is when the device last sent a heartbeat, it only changes
+	// through DeviceService.Heartbeat.
+	LastSeenAt *time.Time `json:"lastSeenAt,omitempty"`
 }
 
 type NewDeviceRequest struct {
-	Name        string `json:"name"`
-	DeviceBrand string `json:"deviceBrand"`
+	Name        string            `json:"name"`
+	DeviceBrand string            `json:"deviceBrand"`
+	Type        string            `json:"type,omitempty"`
+	Attributes  Attributes        `json:"attributes,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	// Status is the status the device enters the fleet with, ordered when
+	// empty.
+	Status     Status `json:"status,omitempty"`
+	LocationID string `json:"locationId,omitempty"`
 }
 
 type NewDeviceResponse struct {
@@ -22,4 +48,30 @@ type PatchDeviceRequest struct {
 	ID          string  `json:"id"`
 	Name        *string `json:"name"`
 	DeviceBrand *string `json:"deviceBrand"`
+	Type        *string `json:"type,omitempty"`
+	// Attributes are merged into the attributes of the device, null values
+	// remove attributes.
+	Attributes Attributes `json:"attributes,omitempty"`
+	// Labels are merged into the labels of the device, null values remove
+	// labels.
+	Labels map[string]*string `json:"labels,omitempty"`
+}
+
+type ListDevicesRequest struct {
+	Limit  int
+	Offset int
+	// Filter is a filter expression, see package filter. Empty lists all devices.
+	Filter string
+	// Selector is a label selector, see package labels. Empty selects all
+	// devices.
+	Selector string
+	// Connectivity keeps only the devices in that state, empty keeps all.
+	Connectivity Connectivity
+}
+
+// SearchResult is a device matching a search query. Higher scores are more
+// relevant.
+type SearchResult struct {
+	Device
+	Score float64 `json:"score"`
 }
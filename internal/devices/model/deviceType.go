@@ -0,0 +1,16 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// DeviceType describes a kind of device, such as "laptop" or "printer", and
+// the attributes its devices carry as a JSON Schema.
+type DeviceType struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	Schema      json.RawMessage `json:"schema"`
+	CreatedAt   time.Time       `json:"createdAt"`
+	TenantID    string          `json:"tenantId"`
+}
@@ -0,0 +1,149 @@
+package model
+
+import (
+	"devices_crud/internal/search"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultDuplicateThreshold is the score from which devices are duplicate
+// candidates when no threshold is given.
+const DefaultDuplicateThreshold = 0.8
+
+// UniquenessKey names device fields, such as brand and attributes.serial, no
+// two devices of a tenant may share the values of. Devices lacking one of the
+// fields are not constrained by the key.
+type UniquenessKey []string
+
+// ParseUniquenessKey reads fields joined by "+", e.g. "brand+attributes.serial".
+// The fields are name, brand, type, attributes.<key> and labels.<key>.
+func ParseUniquenessKey(text string) (UniquenessKey, error) {
+	key := UniquenessKey{}
+	for _, field := range strings.Split(text, "+") {
+		field = strings.TrimSpace(field)
+		if field == "deviceBrand" {
+			field = "brand"
+		}
+		switch {
+		case field == "name", field == "brand", field == "type":
+		case strings.HasPrefix(field, "attributes.") && len(field) > len("attributes."):
+		case strings.HasPrefix(field, "labels.") && len(field) > len("labels."):
+		default:
+			return nil, fmt.Errorf("invalid uniqueness key %q: unknown field %q", text, field)
+		}
+		key = append(key, field)
+	}
+	return key, nil
+}
+
+// ParseUniquenessKeys parses every key, see ParseUniquenessKey.
+func ParseUniquenessKeys(texts []string) ([]UniquenessKey, error) {
+	keys := make([]UniquenessKey, 0, len(texts))
+	for _, text := range texts {
+		key, err := ParseUniquenessKey(text)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+func (k UniquenessKey) String() string {
+	return strings.Join(k, "+")
+}
+
+// Values returns the values of the fields of the key on the device, brands
+// as BrandKey and the others trimmed and lowercased, and false when the
+// device lacks one of them.
+func (k UniquenessKey) Values(device *Device) ([]string, bool) {
+	values := make([]string, len(k))
+	for i, field := range k {
+		var value string
+		switch {
+		case field == "name":
+			value = device.Name
+		case field == "brand":
+			value = BrandKey(device.DeviceBrand)
+		case field == "type":
+			value = device.Type
+		case strings.HasPrefix(field, "labels."):
+			value = device.Labels[strings.TrimPrefix(field, "labels.")]
+		case strings.HasPrefix(field, "attributes."):
+			value = attributeValue(device.Attributes[strings.TrimPrefix(field, "attributes.")])
+		}
+		value = strings.ToLower(strings.TrimSpace(value))
+		if value == "" {
+			return nil, false
+		}
+		values[i] = value
+	}
+	return values, true
+}
+
+// Shared reports whether both devices have the fields of the key with the
+// same values.
+func (k UniquenessKey) Shared(a *Device, b *Device) bool {
+	valuesA, ok := k.Values(a)
+	if !ok {
+		return false
+	}
+	valuesB, ok := k.Values(b)
+	if !ok {
+		return false
+	}
+	for i := range valuesA {
+		if valuesA[i] != valuesB[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func attributeValue(value any) string {
+	switch value := value.(type) {
+	case string:
+		return value
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(value)
+	}
+	return ""
+}
+
+// DuplicateScore scores from 0 to 1 how likely two devices are the same one,
+// from the similarity of their names and, less, of their brands.
+func DuplicateScore(a *Device, b *Device) float64 {
+	return 0.7*search.Similarity(a.Name, b.Name) + 0.3*search.Similarity(BrandKey(a.DeviceBrand), BrandKey(b.DeviceBrand))
+}
+
+// DuplicateCandidate is a pair of devices which may be the same one. Listing
+// the whole inventory, Device is the older of the two, which Duplicate would
+// be merged into.
+type DuplicateCandidate struct {
+	Device    Device  `json:"device"`
+	Duplicate Device  `json:"duplicate"`
+	Score     float64 `json:"score"`
+	// Key is the uniqueness key the devices share, if any. Devices sharing a
+	// key score 1 whatever their names.
+	Key string `json:"key,omitempty"`
+}
+
+type MergeRequest struct {
+	// SourceID is the device merged into the other one and removed.
+	SourceID string `json:"sourceId"`
+}
+
+// DeviceRedirect leads from the ID of a device merged into another one to
+// that device.
+type DeviceRedirect struct {
+	FromID   string    `json:"fromId"`
+	ToID     string    `json:"toId"`
+	MergedAt time.Time `json:"mergedAt"`
+	// MergedBy is the subject of the principal who merged the devices, if any.
+	MergedBy string `json:"mergedBy,omitempty"`
+	TenantID string `json:"tenantId"`
+}
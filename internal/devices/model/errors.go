@@ -0,0 +1,73 @@
+package model
+
+import "errors"
+
+var (
+	ErrDeviceNotFound = errors.New("device not found")
+	ErrQuotaExceeded  = errors.New("device quota exceeded")
+	// ErrInvalidFilter wraps the *filter.SyntaxError of a malformed filter.
+	ErrInvalidFilter = errors.New("invalid filter")
+	// ErrInvalidAttributes wraps the jsonschema.Errors of attributes violating
+	// the schema of their device type.
+	ErrInvalidAttributes = errors.New("invalid attributes")
+	ErrUnknownDeviceType = errors.New("unknown device type")
+	ErrInvalidDeviceType = errors.New("invalid device type")
+	ErrDeviceTypeInUse   = errors.New("device type in use")
+	ErrInvalidLabels     = errors.New("invalid labels")
+	// ErrInvalidSelector wraps the *labels.SyntaxError of a malformed selector.
+	ErrInvalidSelector = errors.New("invalid label selector")
+	ErrInvalidStatus   = errors.New("invalid status")
+	// ErrIllegalTransition is returned for transitions model.Transitions does not
+	// allow.
+	ErrIllegalTransition = errors.New("illegal status transition")
+	// ErrInvalidTransition is returned for transition requests without a
+	// reason.
+	ErrInvalidTransition = errors.New("invalid transition")
+	ErrInvalidAssignment = errors.New("invalid assignment")
+	// ErrDeviceUnavailable is returned when checking out a device which is not
+	// in service.
+	ErrDeviceUnavailable = errors.New("device unavailable")
+	ErrDeviceAssigned    = errors.New("device already checked out")
+	ErrDeviceNotAssigned = errors.New("device not checked out")
+	ErrInvalidLocation   = errors.New("invalid location")
+	ErrUnknownLocation   = errors.New("unknown location")
+	// ErrLocationCycle is returned when moving a location below itself.
+	ErrLocationCycle    = errors.New("location cycle")
+	ErrLocationNotEmpty = errors.New("location not empty")
+	// ErrDeviceMoved is returned when a device is moved by another request
+	// while it is being moved.
+	ErrDeviceMoved  = errors.New("device moved concurrently")
+	ErrInvalidBrand = errors.New("invalid brand")
+	// ErrInvalidRelationship is returned for unknown relationship types and
+	// targets.
+	ErrInvalidRelationship = errors.New("invalid relationship")
+	// ErrRelationshipConflict is returned for links which exist already or
+	// would give a target a second source of an exclusive type.
+	ErrRelationshipConflict = errors.New("relationship conflict")
+	// ErrRelationshipCycle is returned for links leading back to their source
+	// through an acyclic type.
+	ErrRelationshipCycle = errors.New("relationship cycle")
+	// ErrDeviceHasChildren is returned when deleting a device which contains
+	// other devices without deleting them too.
+	ErrDeviceHasChildren      = errors.New("device contains other devices")
+	ErrInvalidMaintenancePlan = errors.New("invalid maintenance plan")
+	// ErrInvalidServiceRecord is returned for unknown kinds, negative costs,
+	// services in the future and plans of other devices.
+	ErrInvalidServiceRecord = errors.New("invalid service record")
+	ErrInvalidContract      = errors.New("invalid contract")
+	// ErrInvalidReading is returned for malformed telemetry batches and
+	// queries.
+	ErrInvalidReading      = errors.New("invalid reading")
+	ErrInvalidConnectivity = errors.New("invalid connectivity")
+	// ErrInvalidSoftware is returned for unknown kinds, missing names and
+	// versions which are not semantic versions.
+	ErrInvalidSoftware       = errors.New("invalid software")
+	ErrInvalidSoftwarePolicy = errors.New("invalid software policy")
+	// ErrDuplicateDevice is returned when creating a device sharing a
+	// uniqueness key with another one.
+	ErrDuplicateDevice = errors.New("duplicate device")
+	// ErrInvalidMerge is returned for merges of a device into itself or of
+	// devices which are both checked out.
+	ErrInvalidMerge     = errors.New("invalid merge")
+	ErrInvalidThreshold = errors.New("invalid duplicate threshold")
+)
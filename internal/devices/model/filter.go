@@ -0,0 +1,64 @@
+package model
+
+import (
+	"devices_crud/internal/filter"
+	"strings"
+)
+
+// DeviceFilterSchema lists the fields filter expressions over devices may use.
+// brand and deviceBrand are the same field, attributes and labels are addressed
+// as attributes.<key> and labels.<key>.
+var DeviceFilterSchema = filter.Schema{
+	"id":           filter.KindString,
+	"name":         filter.KindString,
+	"brand":        filter.KindString,
+	"deviceBrand":  filter.KindString,
+	"createdAt":    filter.KindTime,
+	"type":         filter.KindString,
+	"status":       filter.KindString,
+	"locationId":   filter.KindString,
+	"lastSeenAt":   filter.KindTime,
+	"attributes.*": filter.KindAny,
+	"labels.*":     filter.KindString,
+}
+
+// Field implements filter.Record.
+func (d *Device) Field(name string) (filter.Value, bool) {
+	switch name {
+	case "id":
+		return filter.StringValue(d.ID), true
+	case "name":
+		return filter.StringValue(d.Name), true
+	case "brand", "deviceBrand":
+		return filter.StringValue(d.DeviceBrand), true
+	case "createdAt":
+		return filter.TimeValue(d.CreatedAt), true
+	case "type":
+		return filter.StringValue(d.Type), true
+	case "status":
+		return filter.StringValue(string(d.Status)), true
+	case "locationId":
+		return filter.StringValue(d.LocationID), true
+	case "lastSeenAt":
+		if d.LastSeenAt == nil {
+			return filter.Value{}, false
+		}
+		return filter.TimeValue(*d.LastSeenAt), true
+	}
+
+	if key, ok := strings.CutPrefix(name, "labels."); ok {
+		value, ok := d.Labels[key]
+		return filter.StringValue(value), ok
+	}
+	if key, ok := strings.CutPrefix(name, "attributes."); ok {
+		switch value := d.Attributes[key].(type) {
+		case string:
+			return filter.StringValue(value), true
+		case float64:
+			return filter.NumberValue(value), true
+		case bool:
+			return filter.BoolValue(value), true
+		}
+	}
+	return filter.Value{}, false
+}
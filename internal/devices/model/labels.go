@@ -0,0 +1,21 @@
+package model
+
+// MergeLabels returns a copy of current with patch applied: nil values remove
+// labels, others set them.
+func MergeLabels(current map[string]string, patch map[string]*string) map[string]string {
+	merged := make(map[string]string, len(current)+len(patch))
+	for key, value := range current {
+		merged[key] = value
+	}
+	for key, value := range patch {
+		if value == nil {
+			delete(merged, key)
+		} else {
+			merged[key] = *value
+		}
+	}
+	if len(merged) == 0 {
+		return nil
+	}
+	return merged
+}
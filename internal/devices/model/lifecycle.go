@@ -0,0 +1,93 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+// Status is the lifecycle stage of a device.
+type Status string
+
+const (
+	StatusOrdered     Status = "ordered"
+	StatusReceived    Status = "received"
+	StatusProvisioned Status = "provisioned"
+	StatusInService   Status = "in_service"
+	StatusInRepair    Status = "in_repair"
+	StatusRetired     Status = "retired"
+	StatusDisposed    Status = "disposed"
+)
+
+// Statuses lists every status in lifecycle order.
+var Statuses = []Status{
+	StatusOrdered,
+	StatusReceived,
+	StatusProvisioned,
+	StatusInService,
+	StatusInRepair,
+	StatusRetired,
+	StatusDisposed,
+}
+
+// Transitions maps each status to the statuses a device may move to from it.
+// Disposed devices are final.
+var Transitions = map[Status][]Status{
+	StatusOrdered:     {StatusReceived},
+	StatusReceived:    {StatusProvisioned, StatusInRepair, StatusRetired},
+	StatusProvisioned: {StatusInService, StatusInRepair, StatusRetired},
+	StatusInService:   {StatusProvisioned, StatusInRepair, StatusRetired},
+	StatusInRepair:    {StatusProvisioned, StatusInService, StatusRetired},
+	StatusRetired:     {StatusProvisioned, StatusDisposed},
+	StatusDisposed:    {},
+}
+
+// InitialStatuses lists the statuses devices may enter the fleet with: newly
+// ordered, or already in hand and working. Any later status is only reached
+// through Transitions.
+var InitialStatuses = []Status{StatusOrdered, StatusReceived, StatusProvisioned, StatusInService}
+
+// ParseStatus checks that status is one of Statuses.
+func ParseStatus(status string) (Status, error) {
+	for _, known := range Statuses {
+		if string(known) == status {
+			return known, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %q, expected one of %v", ErrInvalidStatus, status, Statuses)
+}
+
+// CanTransition reports whether the transition table allows moving from one
+// status to the other.
+func CanTransition(from Status, to Status) bool {
+	for _, allowed := range Transitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// StatusChange records a transition. The first change of a device has no From
+// and records the status it was created with.
+type StatusChange struct {
+	From   Status    `json:"from,omitempty"`
+	To     Status    `json:"to"`
+	Reason string    `json:"reason,omitempty"`
+	At     time.Time `json:"at"`
+	// Actor is the subject of the principal who made the change, if any.
+	Actor string `json:"actor,omitempty"`
+}
+
+type TransitionRequest struct {
+	Status Status `json:"status"`
+	Reason string `json:"reason"`
+}
+
+// StatusSince returns when the device entered its current status, the zero
+// time for devices without history.
+func (d *Device) StatusSince() time.Time {
+	if len(d.StatusHistory) == 0 {
+		return time.Time{}
+	}
+	return d.StatusHistory[len(d.StatusHistory)-1].At
+}
@@ -0,0 +1,98 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// LocationKind is the level of a location in the tree. Children are always of
+// a deeper kind than their parent, so a room may sit directly in a site but a
+// building never in a room.
+type LocationKind string
+
+const (
+	LocationSite     LocationKind = "site"
+	LocationBuilding LocationKind = "building"
+	LocationRoom     LocationKind = "room"
+	LocationRack     LocationKind = "rack"
+)
+
+// LocationKinds lists the kinds from the top of the tree down.
+var LocationKinds = []LocationKind{LocationSite, LocationBuilding, LocationRoom, LocationRack}
+
+// Depth returns the position of the kind in LocationKinds, -1 for unknown
+// kinds.
+func (k LocationKind) Depth() int {
+	for i, kind := range LocationKinds {
+		if kind == k {
+			return i
+		}
+	}
+	return -1
+}
+
+// PathSeparator separates the names of the locations in a path such as
+// "HQ/Building A/Room 101".
+const PathSeparator = "/"
+
+type Location struct {
+	ID   string       `json:"id"`
+	Name string       `json:"name"`
+	Kind LocationKind `json:"kind"`
+	// ParentID is empty for the sites at the roots of the tree.
+	ParentID  string    `json:"parentId,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	TenantID  string    `json:"tenantId"`
+}
+
+type NewLocationRequest struct {
+	Name     string       `json:"name"`
+	Kind     LocationKind `json:"kind"`
+	ParentID string       `json:"parentId,omitempty"`
+}
+
+// MoveLocationRequest moves a location with its subtree under another parent,
+// or to the root of the tree when ParentID is empty.
+type MoveLocationRequest struct {
+	ParentID string `json:"parentId"`
+}
+
+// MoveDeviceRequest places a device in a location, or takes it out of any
+// location when LocationID is empty.
+type MoveDeviceRequest struct {
+	LocationID string `json:"locationId"`
+	Reason     string `json:"reason,omitempty"`
+}
+
+// LocationChange records a move of a device. The first change of a device
+// placed on creation has no From.
+type LocationChange struct {
+	From   string    `json:"from,omitempty"`
+	To     string    `json:"to,omitempty"`
+	Reason string    `json:"reason,omitempty"`
+	At     time.Time `json:"at"`
+	Actor  string    `json:"actor,omitempty"`
+}
+
+// LocationPath is a chain of locations from a site down.
+type LocationPath []Location
+
+func (p LocationPath) String() string {
+	names := make([]string, len(p))
+	for i, location := range p {
+		names[i] = location.Name
+	}
+	return strings.Join(names, PathSeparator)
+}
+
+// ValidateLocationName rejects names which cannot be addressed in a path.
+func ValidateLocationName(name string) error {
+	if strings.TrimSpace(name) == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidLocation)
+	}
+	if strings.Contains(name, PathSeparator) {
+		return fmt.Errorf("%w: name must not contain %q", ErrInvalidLocation, PathSeparator)
+	}
+	return nil
+}
@@ -0,0 +1,212 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+// MaintenanceKind tells how the due dates of a maintenance plan are computed.
+type MaintenanceKind string
+
+const (
+	// MaintenanceInterval plans are due a recurrence after the device was last
+	// serviced, e.g. a filter changed every 90 days.
+	MaintenanceInterval MaintenanceKind = "interval"
+	// MaintenanceCalendar plans are due on fixed dates whenever the device was
+	// serviced, e.g. an inspection on the first of every quarter.
+	MaintenanceCalendar MaintenanceKind = "calendar"
+)
+
+var MaintenanceKinds = []MaintenanceKind{MaintenanceInterval, MaintenanceCalendar}
+
+type RecurrenceUnit string
+
+const (
+	UnitDay   RecurrenceUnit = "day"
+	UnitWeek  RecurrenceUnit = "week"
+	UnitMonth RecurrenceUnit = "month"
+	UnitYear  RecurrenceUnit = "year"
+)
+
+var RecurrenceUnits = []RecurrenceUnit{UnitDay, UnitWeek, UnitMonth, UnitYear}
+
+// MaxMaintenanceTasks bounds the tasks a plan generates at once, so that a
+// daily plan over a long horizon stays readable.
+const MaxMaintenanceTasks = 100
+
+// MaintenancePlan schedules periodic maintenance of a device every Every
+// Units, starting at StartsAt.
+type MaintenancePlan struct {
+	ID          string          `json:"id"`
+	DeviceID    string          `json:"deviceId"`
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	Kind        MaintenanceKind `json:"kind"`
+	Every       int             `json:"every"`
+	Unit        RecurrenceUnit  `json:"unit"`
+	// StartsAt is when the plan is first due.
+	StartsAt time.Time `json:"startsAt"`
+	// NextDueAt is when the plan is due next, set when the plan is read.
+	NextDueAt *time.Time `json:"nextDueAt,omitempty"`
+	CreatedAt time.Time  `json:"createdAt"`
+	// CreatedBy is the subject of the principal who created the plan, if any.
+	CreatedBy string `json:"createdBy,omitempty"`
+	TenantID  string `json:"tenantId"`
+}
+
+// Validate checks the kind and the recurrence of the plan.
+func (p *MaintenancePlan) Validate() error {
+	if p.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidMaintenancePlan)
+	}
+	if !knownKind(p.Kind) {
+		return fmt.Errorf("%w: unknown kind %q, expected one of %v", ErrInvalidMaintenancePlan, p.Kind, MaintenanceKinds)
+	}
+	if !knownUnit(p.Unit) {
+		return fmt.Errorf("%w: unknown unit %q, expected one of %v", ErrInvalidMaintenancePlan, p.Unit, RecurrenceUnits)
+	}
+	if p.Every < 1 {
+		return fmt.Errorf("%w: every must be at least 1", ErrInvalidMaintenancePlan)
+	}
+	return nil
+}
+
+// After returns the time n recurrences after t. Months and years are added to
+// the calendar date, so a plan starting on January 31 is next due on March 3
+// or 2.
+func (p *MaintenancePlan) After(t time.Time, n int) time.Time {
+	switch p.Unit {
+	case UnitDay:
+		return t.AddDate(0, 0, n*p.Every)
+	case UnitWeek:
+		return t.AddDate(0, 0, 7*n*p.Every)
+	case UnitMonth:
+		return t.AddDate(0, n*p.Every, 0)
+	default:
+		return t.AddDate(n*p.Every, 0, 0)
+	}
+}
+
+// Schedule returns the due dates of the plan up to until, at most
+// MaxMaintenanceTasks, given the last service completing a task of the plan,
+// nil if there is none. Later due dates of interval plans assume every task is
+// done on time.
+func (p *MaintenancePlan) Schedule(last *ServiceRecord, until time.Time) []time.Time {
+	base, n := p.next(last)
+	due := make([]time.Time, 0)
+	for ; len(due) < MaxMaintenanceTasks; n++ {
+		next := p.After(base, n)
+		if next.After(until) {
+			break
+		}
+		due = append(due, next)
+	}
+	return due
+}
+
+// NextDue returns when the plan is due next, see Schedule.
+func (p *MaintenancePlan) NextDue(last *ServiceRecord) time.Time {
+	base, n := p.next(last)
+	return p.After(base, n)
+}
+
+// next returns the time and the number of recurrences after it the plan is
+// due next.
+func (p *MaintenancePlan) next(last *ServiceRecord) (time.Time, int) {
+	switch {
+	case last == nil:
+		return p.StartsAt, 0
+	case p.Kind == MaintenanceInterval || last.DueAt == nil:
+		return last.PerformedAt, 1
+	}
+	// Occurrences are counted from StartsAt rather than added one to the other,
+	// so that month ends do not drift.
+	n := 0
+	for !p.After(p.StartsAt, n).After(*last.DueAt) {
+		n++
+	}
+	return p.StartsAt, n
+}
+
+func knownKind(kind MaintenanceKind) bool {
+	for _, known := range MaintenanceKinds {
+		if known == kind {
+			return true
+		}
+	}
+	return false
+}
+
+func knownUnit(unit RecurrenceUnit) bool {
+	for _, known := range RecurrenceUnits {
+		if known == unit {
+			return true
+		}
+	}
+	return false
+}
+
+type NewMaintenancePlanRequest struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	Kind        MaintenanceKind `json:"kind"`
+	Every       int             `json:"every"`
+	Unit        RecurrenceUnit  `json:"unit"`
+	// StartsAt defaults to one recurrence from now.
+	StartsAt *time.Time `json:"startsAt,omitempty"`
+}
+
+// ServiceKind tells planned maintenance from repairs.
+type ServiceKind string
+
+const (
+	ServiceMaintenance ServiceKind = "maintenance"
+	ServiceRepair      ServiceKind = "repair"
+)
+
+var ServiceKinds = []ServiceKind{ServiceMaintenance, ServiceRepair}
+
+// ServiceRecord records maintenance or a repair done on a device.
+type ServiceRecord struct {
+	ID       string      `json:"id"`
+	DeviceID string      `json:"deviceId"`
+	Kind     ServiceKind `json:"kind"`
+	// PlanID is the plan whose task the service completed, if any.
+	PlanID string `json:"planId,omitempty"`
+	// DueAt is when the completed task of the plan was due.
+	DueAt       *time.Time `json:"dueAt,omitempty"`
+	PerformedAt time.Time  `json:"performedAt"`
+	// PerformedBy names who did the work, e.g. a technician or a vendor.
+	PerformedBy string  `json:"performedBy,omitempty"`
+	Cost        float64 `json:"cost"`
+	Currency    string  `json:"currency,omitempty"`
+	Notes       string  `json:"notes,omitempty"`
+	// RecordedBy is the subject of the principal who recorded the service, if
+	// any.
+	RecordedBy string `json:"recordedBy,omitempty"`
+	TenantID   string `json:"tenantId"`
+}
+
+type ServiceRecordRequest struct {
+	// Kind defaults to maintenance.
+	Kind   ServiceKind `json:"kind,omitempty"`
+	PlanID string      `json:"planId,omitempty"`
+	// PerformedAt defaults to now.
+	PerformedAt *time.Time `json:"performedAt,omitempty"`
+	PerformedBy string     `json:"performedBy,omitempty"`
+	Cost        float64    `json:"cost"`
+	Currency    string     `json:"currency,omitempty"`
+	Notes       string     `json:"notes,omitempty"`
+}
+
+// MaintenanceTask is a due date of a maintenance plan.
+type MaintenanceTask struct {
+	PlanID   string    `json:"planId"`
+	PlanName string    `json:"planName"`
+	DeviceID string    `json:"deviceId"`
+	DueAt    time.Time `json:"dueAt"`
+	Overdue  bool      `json:"overdue"`
+	// DeviceInRepair flags tasks of devices in StatusInRepair, which cannot be
+	// serviced until they are back.
+	DeviceInRepair bool `json:"deviceInRepair"`
+}
@@ -0,0 +1,115 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+// RelationType is the kind of a link between two devices.
+type RelationType string
+
+const (
+	// RelationContains links a device, such as a chassis, to a device inside
+	// it, such as a blade.
+	RelationContains RelationType = "contains"
+	// RelationConnectedTo links two devices plugged into each other. It has no
+	// direction.
+	RelationConnectedTo RelationType = "connected_to"
+	// RelationReplaces links a device to the device it took over from.
+	RelationReplaces RelationType = "replaces"
+)
+
+var RelationTypes = []RelationType{RelationContains, RelationConnectedTo, RelationReplaces}
+
+// DeleteRule is what deleting a device does to the relationships it is the
+// source of. Relationships it is the target of are always removed.
+type DeleteRule string
+
+const (
+	// DeleteRestrict refuses to delete the source unless the targets are
+	// deleted with it.
+	DeleteRestrict DeleteRule = "restrict"
+	// DeleteDetach removes the relationship and keeps the target.
+	DeleteDetach DeleteRule = "detach"
+)
+
+// RelationRule constrains the relationships of a type.
+type RelationRule struct {
+	// Directed relationships lead from a source to a target. Undirected ones
+	// are the same whichever device is the source.
+	Directed bool
+	// Acyclic types may not lead back to a device through relationships of
+	// the same type.
+	Acyclic bool
+	// Exclusive types allow at most one relationship per target, e.g. a device
+	// is contained in a single other device.
+	Exclusive bool
+	OnDelete  DeleteRule
+}
+
+var RelationRules = map[RelationType]RelationRule{
+	RelationContains:    {Directed: true, Acyclic: true, Exclusive: true, OnDelete: DeleteRestrict},
+	RelationConnectedTo: {OnDelete: DeleteDetach},
+	RelationReplaces:    {Directed: true, Acyclic: true, Exclusive: true, OnDelete: DeleteDetach},
+}
+
+// ParseRelationType checks that relationType is one of RelationTypes.
+func ParseRelationType(relationType string) (RelationType, error) {
+	for _, known := range RelationTypes {
+		if string(known) == relationType {
+			return known, nil
+		}
+	}
+	return "", fmt.Errorf("%w: unknown type %q, expected one of %v", ErrInvalidRelationship, relationType, RelationTypes)
+}
+
+// Relationship links the device FromID to the device ToID.
+type Relationship struct {
+	ID        string       `json:"id"`
+	Type      RelationType `json:"type"`
+	FromID    string       `json:"fromId"`
+	ToID      string       `json:"toId"`
+	CreatedAt time.Time    `json:"createdAt"`
+	// CreatedBy is the subject of the principal who linked the devices, if any.
+	CreatedBy string `json:"createdBy,omitempty"`
+	TenantID  string `json:"tenantId"`
+}
+
+// Other returns the device at the other end of the relationship from id.
+func (r *Relationship) Other(id string) string {
+	if r.FromID == id {
+		return r.ToID
+	}
+	return r.FromID
+}
+
+type LinkRequest struct {
+	Type RelationType `json:"type"`
+	// TargetID is the device the device of the request is linked to.
+	TargetID string `json:"targetId"`
+}
+
+// MaxTraversalDepth bounds the depth of TraversalRequest.
+const MaxTraversalDepth = 10
+
+// TraversalRequest walks the relationships from a device, whatever their
+// direction, up to Depth relationships away.
+type TraversalRequest struct {
+	// Types restricts the relationships followed, empty follows all.
+	Types []RelationType
+	// Depth is between 1 and MaxTraversalDepth, 1 when zero.
+	Depth int
+}
+
+// DeviceGraph is the part of the device graph a traversal reached.
+type DeviceGraph struct {
+	Nodes []GraphNode    `json:"nodes"`
+	Edges []Relationship `json:"edges"`
+}
+
+// GraphNode is a device and its distance from the device the traversal
+// started at.
+type GraphNode struct {
+	Device Device `json:"device"`
+	Depth  int    `json:"depth"`
+}
@@ -0,0 +1,197 @@
+package model
+
+import (
+	"devices_crud/internal/semver"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type SoftwareKind string
+
+const (
+	SoftwareFirmware    SoftwareKind = "firmware"
+	SoftwareOS          SoftwareKind = "os"
+	SoftwareApplication SoftwareKind = "application"
+)
+
+var SoftwareKinds = []SoftwareKind{SoftwareFirmware, SoftwareOS, SoftwareApplication}
+
+func validSoftwareKind(kind SoftwareKind) bool {
+	for _, known := range SoftwareKinds {
+		if kind == known {
+			return true
+		}
+	}
+	return false
+}
+
+// InstalledSoftware is a firmware, an operating system or an application
+// running on a device. A device runs a single version of every kind and name,
+// names being compared case-insensitively.
+type InstalledSoftware struct {
+	ID       string       `json:"id"`
+	DeviceID string       `json:"deviceId"`
+	Kind     SoftwareKind `json:"kind"`
+	Name     string       `json:"name"`
+	// Version is a semantic version such as 2.3.1, see package semver.
+	Version     string    `json:"version"`
+	InstalledAt time.Time `json:"installedAt"`
+	// CreatedBy is the subject of the principal who recorded the software, if
+	// any.
+	CreatedBy string `json:"createdBy,omitempty"`
+	TenantID  string `json:"tenantId"`
+}
+
+// Is reports whether the software is the one of the kind and name.
+func (s *InstalledSoftware) Is(kind SoftwareKind, name string) bool {
+	return s.Kind == kind && strings.EqualFold(s.Name, name)
+}
+
+type SoftwareRequest struct {
+	Kind    SoftwareKind `json:"kind"`
+	Name    string       `json:"name"`
+	Version string       `json:"version"`
+	// InstalledAt defaults to when the software is recorded.
+	InstalledAt *time.Time `json:"installedAt,omitempty"`
+}
+
+// Validate checks the kind, the name and the version of the request.
+func (r *SoftwareRequest) Validate() error {
+	if !validSoftwareKind(r.Kind) {
+		return fmt.Errorf("%w: unknown kind %q, expected one of %v", ErrInvalidSoftware, r.Kind, SoftwareKinds)
+	}
+	r.Name = strings.TrimSpace(r.Name)
+	if r.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidSoftware)
+	}
+	r.Version = strings.TrimSpace(r.Version)
+	if _, err := semver.Parse(r.Version); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSoftware, err)
+	}
+	return nil
+}
+
+// SoftwarePolicy requires the devices of a brand and of a type, or of any
+// when they are empty, to run at least MinVersion of a software.
+type SoftwarePolicy struct {
+	ID          string `json:"id"`
+	Description string `json:"description,omitempty"`
+	// Brand is compared like catalog brands, so "APPLE Inc" matches Apple.
+	Brand string       `json:"brand,omitempty"`
+	Type  string       `json:"type,omitempty"`
+	Kind  SoftwareKind `json:"kind"`
+	// Software is the name of the software, empty for every software of the
+	// kind, e.g. whatever firmware the devices run.
+	Software   string    `json:"software,omitempty"`
+	MinVersion string    `json:"minVersion"`
+	CreatedAt  time.Time `json:"createdAt"`
+	CreatedBy  string    `json:"createdBy,omitempty"`
+	TenantID   string    `json:"tenantId"`
+}
+
+// Applies reports whether the policy covers the device.
+func (p *SoftwarePolicy) Applies(device *Device) bool {
+	if p.Brand != "" && BrandKey(p.Brand) != BrandKey(device.DeviceBrand) {
+		return false
+	}
+	return p.Type == "" || p.Type == device.Type
+}
+
+// Check returns how the software of a device the policy applies to violates
+// it: every outdated software it covers, or a missing one when the device
+// runs none.
+func (p *SoftwarePolicy) Check(software []InstalledSoftware) []PolicyViolation {
+	required, err := semver.Parse(p.MinVersion)
+	if err != nil {
+		// Policies are validated when created.
+		return nil
+	}
+	violations := make([]PolicyViolation, 0)
+	covered := false
+	for _, installed := range software {
+		if installed.Kind != p.Kind || (p.Software != "" && !strings.EqualFold(installed.Name, p.Software)) {
+			continue
+		}
+		covered = true
+		if version, err := semver.Parse(installed.Version); err != nil || version.Less(required) {
+			violations = append(violations, PolicyViolation{
+				Policy: *p, Reason: ViolationOutdated, Software: installed.Name, Installed: installed.Version, Required: p.MinVersion,
+			})
+		}
+	}
+	if !covered {
+		violations = append(violations, PolicyViolation{Policy: *p, Reason: ViolationMissing, Software: p.Software, Required: p.MinVersion})
+	}
+	return violations
+}
+
+type SoftwarePolicyRequest struct {
+	Description string `json:"description,omitempty"`
+	Brand       string `json:"brand,omitempty"`
+	Type        string `json:"type,omitempty"`
+	// Kind defaults to firmware.
+	Kind       SoftwareKind `json:"kind,omitempty"`
+	Software   string       `json:"software,omitempty"`
+	MinVersion string       `json:"minVersion"`
+}
+
+// Validate checks the kind and the minimum version of the request and cleans
+// its brand and names.
+func (r *SoftwarePolicyRequest) Validate() error {
+	if r.Kind == "" {
+		r.Kind = SoftwareFirmware
+	}
+	if !validSoftwareKind(r.Kind) {
+		return fmt.Errorf("%w: unknown kind %q, expected one of %v", ErrInvalidSoftwarePolicy, r.Kind, SoftwareKinds)
+	}
+	r.MinVersion = strings.TrimSpace(r.MinVersion)
+	if _, err := semver.Parse(r.MinVersion); err != nil {
+		return fmt.Errorf("%w: minVersion: %v", ErrInvalidSoftwarePolicy, err)
+	}
+	r.Description = strings.TrimSpace(r.Description)
+	r.Brand = CleanBrand(r.Brand)
+	r.Type = strings.TrimSpace(r.Type)
+	r.Software = strings.TrimSpace(r.Software)
+	return nil
+}
+
+type ViolationReason string
+
+const (
+	// ViolationOutdated is a software older than the policy requires.
+	ViolationOutdated ViolationReason = "outdated"
+	// ViolationMissing is a device running no software the policy covers.
+	ViolationMissing ViolationReason = "missing"
+)
+
+type PolicyViolation struct {
+	Policy SoftwarePolicy  `json:"policy"`
+	Reason ViolationReason `json:"reason"`
+	// Software is the name of the outdated software, or the required one if
+	// the policy names it.
+	Software string `json:"software,omitempty"`
+	// Installed is the version of the outdated software.
+	Installed string `json:"installed,omitempty"`
+	Required  string `json:"required"`
+}
+
+// DeviceCompliance tells whether a device complies with the policies which
+// apply to it.
+type DeviceCompliance struct {
+	Device     Device            `json:"device"`
+	Compliant  bool              `json:"compliant"`
+	Violations []PolicyViolation `json:"violations"`
+	// Policies is the number of policies applying to the device.
+	Policies int `json:"policies"`
+}
+
+// ComplianceReport lists the devices violating a policy.
+type ComplianceReport struct {
+	CheckedAt time.Time `json:"checkedAt"`
+	Policies  int       `json:"policies"`
+	// Devices is the number of devices at least one policy applies to.
+	Devices   int                `json:"devices"`
+	Compliant int                `json:"compliant"`
+	Violators []DeviceCompliance `json:"violators"`
+}
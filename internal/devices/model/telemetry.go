@@ -0,0 +1,81 @@
+package model
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// MaxReadingsPerBatch bounds the number of readings ingested at once.
+const MaxReadingsPerBatch = 1000
+
+// MaxAggregateSteps bounds the number of steps of an aggregate query.
+const MaxAggregateSteps = 1000
+
+// MaxClockSkew is how far in the future of the server clock readings may be
+// taken.
+const MaxClockSkew = 5 * time.Minute
+
+var metricPattern = regexp.MustCompile(`^[a-z][a-z0-9_.]{0,63}$`)
+
+// Reading is a value of a metric of a device, e.g. its battery level. Readings
+// older than a day are averaged over the resolution they were downsampled to
+// and carry the start of their bucket.
+type Reading struct {
+	Metric string    `json:"metric"`
+	Value  float64   `json:"value"`
+	At     time.Time `json:"at"`
+}
+
+type ReadingBatch struct {
+	Readings []Reading `json:"readings"`
+}
+
+// Validate checks the size of the batch and its readings, lowercases their
+// metrics and takes the readings without a time at now.
+func (b *ReadingBatch) Validate(now time.Time) error {
+	if len(b.Readings) == 0 {
+		return fmt.Errorf("%w: readings are required", ErrInvalidReading)
+	}
+	if len(b.Readings) > MaxReadingsPerBatch {
+		return fmt.Errorf("%w: at most %d readings per batch", ErrInvalidReading, MaxReadingsPerBatch)
+	}
+	for i := range b.Readings {
+		reading := &b.Readings[i]
+		reading.Metric = strings.ToLower(strings.TrimSpace(reading.Metric))
+		if !metricPattern.MatchString(reading.Metric) {
+			return fmt.Errorf("%w: invalid metric %q, expected a letter followed by letters, digits, _ or .", ErrInvalidReading, reading.Metric)
+		}
+		if math.IsNaN(reading.Value) || math.IsInf(reading.Value, 0) {
+			return fmt.Errorf("%w: value of %s is not a number", ErrInvalidReading, reading.Metric)
+		}
+		if reading.At.IsZero() {
+			reading.At = now
+		}
+		if reading.At.After(now.Add(MaxClockSkew)) {
+			return fmt.Errorf("%w: reading of %s is in the future", ErrInvalidReading, reading.Metric)
+		}
+	}
+	return nil
+}
+
+// ValidMetric reports whether the name is a valid metric, ingested metrics are
+// lowercase.
+func ValidMetric(metric string) bool {
+	return metricPattern.MatchString(metric)
+}
+
+// ReadingAggregate summarizes the readings of a metric from Start until End.
+type ReadingAggregate struct {
+	Metric string    `json:"metric"`
+	Start  time.Time `json:"start"`
+	End    time.Time `json:"end"`
+	Count  int       `json:"count"`
+	Min    float64   `json:"min"`
+	Max    float64   `json:"max"`
+	Mean   float64   `json:"mean"`
+	// Last is the value of the latest reading of the period.
+	Last float64 `json:"last"`
+}
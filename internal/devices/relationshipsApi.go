@@ -0,0 +1,161 @@
+package devices
+
+import (
+	"devices_crud/internal/devices/model"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (dr *DevicesRouter) listRelationships(c *gin.Context) {
+	if _, ok := dr.findDevice(c); !ok {
+		return
+	}
+
+	relationships, err := dr.devicesService.DeviceRelationships(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		dr.respondWithError(c, err, "Error getting relationships")
+		return
+	}
+
+	c.JSON(200, relationships)
+}
+
+func (dr *DevicesRouter) linkDevices(c *gin.Context) {
+	request := &model.LinkRequest{}
+	if err := c.BindJSON(request); err != nil {
+		dr.logger.InfoContext(c.Request.Context(), "Invalid relationship", "error", err)
+		c.JSON(400, gin.H{
+			"message": "Error binding relationship",
+		})
+		return
+	}
+
+	relationship, err := dr.devicesService.LinkDevices(c.Request.Context(), c.Param("id"), request)
+	dr.respondWithRelationship(c, 201, relationship, err, "Error linking devices")
+}
+
+func (dr *DevicesRouter) unlinkDevices(c *gin.Context) {
+	relationship, err := dr.devicesService.UnlinkDevices(c.Request.Context(), c.Param("id"), c.Param("relationshipId"))
+	if err != nil {
+		dr.respondWithError(c, err, "Error unlinking devices")
+		return
+	}
+	if relationship == nil {
+		dr.logger.InfoContext(c.Request.Context(), "Relationship not found", "relationship_id", c.Param("relationshipId"))
+		c.JSON(404, gin.H{
+			"message": "Relationship not found",
+			"code":    "NOT_FOUND",
+		})
+		return
+	}
+
+	c.JSON(204, gin.H{})
+}
+
+func (dr *DevicesRouter) getParentDevice(c *gin.Context) {
+	if _, ok := dr.findDevice(c); !ok {
+		return
+	}
+
+	parent, err := dr.devicesService.ParentDevice(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		dr.respondWithError(c, err, "Error getting parent device")
+		return
+	}
+	if parent == nil {
+		dr.logger.InfoContext(c.Request.Context(), "Device has no parent")
+		c.JSON(404, gin.H{
+			"message": "Device is not contained in another device",
+			"code":    "NOT_FOUND",
+		})
+		return
+	}
+
+	c.JSON(200, parent)
+}
+
+func (dr *DevicesRouter) listChildDevices(c *gin.Context) {
+	if _, ok := dr.findDevice(c); !ok {
+		return
+	}
+
+	children, err := dr.devicesService.ChildDevices(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		dr.respondWithError(c, err, "Error getting child devices")
+		return
+	}
+
+	c.JSON(200, children)
+}
+
+// getDeviceGraph returns the devices and relationships up to the depth param
+// away, following only the comma-separated types of the type param if given.
+func (dr *DevicesRouter) getDeviceGraph(c *gin.Context) {
+	request := &model.TraversalRequest{}
+	if param := c.Query("depth"); param != "" {
+		depth, err := strconv.Atoi(param)
+		if err != nil {
+			dr.logger.InfoContext(c.Request.Context(), "Invalid depth", "error", err)
+			c.JSON(400, gin.H{
+				"message": "depth must be a number",
+			})
+			return
+		}
+		request.Depth = depth
+	}
+	if param := c.Query("type"); param != "" {
+		for _, relationType := range strings.Split(param, ",") {
+			request.Types = append(request.Types, model.RelationType(strings.TrimSpace(relationType)))
+		}
+	}
+
+	graph, err := dr.devicesService.TraverseDevices(c.Request.Context(), c.Param("id"), request)
+	if err != nil {
+		dr.respondWithError(c, err, "Error traversing devices")
+		return
+	}
+	if graph == nil {
+		dr.deviceNotFound(c)
+		return
+	}
+
+	c.JSON(200, graph)
+}
+
+// findDevice responds with 404 and returns false when the device of the route
+// does not exist.
+func (dr *DevicesRouter) findDevice(c *gin.Context) (*model.Device, bool) {
+	device, err := dr.devicesService.GetDevice(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		dr.respondWithError(c, err, "Error getting device")
+		return nil, false
+	}
+	if device == nil {
+		dr.deviceNotFound(c)
+		return nil, false
+	}
+	return device, true
+}
+
+func (dr *DevicesRouter) deviceNotFound(c *gin.Context) {
+	dr.logger.InfoContext(c.Request.Context(), "Device not found")
+	c.JSON(404, gin.H{
+		"message": "Device not found",
+		"code":    "NOT_FOUND",
+	})
+}
+
+func (dr *DevicesRouter) respondWithRelationship(c *gin.Context, status int, relationship *model.Relationship, err error, message string) {
+	if err != nil {
+		dr.respondWithError(c, err, message)
+		return
+	}
+	if relationship == nil {
+		dr.deviceNotFound(c)
+		return
+	}
+
+	c.JSON(status, relationship)
+}
@@ -0,0 +1,158 @@
+package devices
+
+import (
+	"devices_crud/internal/devices/model"
+	"devices_crud/internal/logging"
+
+	"github.com/gin-gonic/gin"
+)
+
+func BuildComplianceRoutes(router *gin.RouterGroup, devicesDeps *DependencyTree) {
+	devicesRouter := &DevicesRouter{
+		devicesService: devicesDeps.DeviceSerivce,
+		logger:         logging.Component(devicesDeps.Logger, "rest"),
+	}
+
+	router.GET("", devicesRouter.getComplianceReport)
+	router.GET("/policies", devicesRouter.listSoftwarePolicies)
+	router.POST("/policies", devicesRouter.createSoftwarePolicy)
+	router.DELETE("/policies/:policyId", devicesRouter.deleteSoftwarePolicy)
+}
+
+func (dr *DevicesRouter) listSoftware(c *gin.Context) {
+	if _, ok := dr.findDevice(c); !ok {
+		return
+	}
+
+	software, err := dr.devicesService.DeviceSoftware(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		dr.respondWithError(c, err, "Error getting software")
+		return
+	}
+
+	c.JSON(200, software)
+}
+
+// recordSoftware records the version of a software running on the device,
+// replacing the one recorded for the same kind and name.
+func (dr *DevicesRouter) recordSoftware(c *gin.Context) {
+	request := &model.SoftwareRequest{}
+	if err := c.BindJSON(request); err != nil {
+		dr.logger.InfoContext(c.Request.Context(), "Invalid software", "error", err)
+		c.JSON(400, gin.H{
+			"message": "Error binding software",
+		})
+		return
+	}
+
+	software, err := dr.devicesService.RecordSoftware(c.Request.Context(), c.Param("id"), request)
+	if err != nil {
+		dr.respondWithError(c, err, "Error recording software")
+		return
+	}
+	if software == nil {
+		dr.deviceNotFound(c)
+		return
+	}
+
+	c.JSON(200, software)
+}
+
+func (dr *DevicesRouter) deleteSoftware(c *gin.Context) {
+	software, err := dr.devicesService.DeleteSoftware(c.Request.Context(), c.Param("id"), c.Param("softwareId"))
+	if err != nil {
+		dr.respondWithError(c, err, "Error deleting software")
+		return
+	}
+	if software == nil {
+		dr.logger.InfoContext(c.Request.Context(), "Software not found", "software_id", c.Param("softwareId"))
+		c.JSON(404, gin.H{
+			"message": "Software not found",
+			"code":    "NOT_FOUND",
+		})
+		return
+	}
+
+	c.JSON(204, gin.H{})
+}
+
+func (dr *DevicesRouter) getDeviceCompliance(c *gin.Context) {
+	compliance, err := dr.devicesService.DeviceCompliance(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		dr.respondWithError(c, err, "Error checking compliance")
+		return
+	}
+	if compliance == nil {
+		dr.deviceNotFound(c)
+		return
+	}
+
+	c.JSON(200, compliance)
+}
+
+// getComplianceReport lists the devices violating the policies, or the policy
+// param if given.
+func (dr *DevicesRouter) getComplianceReport(c *gin.Context) {
+	report, err := dr.devicesService.ComplianceReport(c.Request.Context(), c.Query("policy"))
+	if err != nil {
+		dr.respondWithError(c, err, "Error building compliance report")
+		return
+	}
+	if report == nil {
+		dr.policyNotFound(c, c.Query("policy"))
+		return
+	}
+
+	c.JSON(200, report)
+}
+
+func (dr *DevicesRouter) listSoftwarePolicies(c *gin.Context) {
+	policies, err := dr.devicesService.SoftwarePolicies(c.Request.Context())
+	if err != nil {
+		dr.respondWithError(c, err, "Error getting software policies")
+		return
+	}
+
+	c.JSON(200, policies)
+}
+
+func (dr *DevicesRouter) createSoftwarePolicy(c *gin.Context) {
+	request := &model.SoftwarePolicyRequest{}
+	if err := c.BindJSON(request); err != nil {
+		dr.logger.InfoContext(c.Request.Context(), "Invalid software policy", "error", err)
+		c.JSON(400, gin.H{
+			"message": "Error binding software policy",
+		})
+		return
+	}
+
+	policy, err := dr.devicesService.CreateSoftwarePolicy(c.Request.Context(), request)
+	if err != nil {
+		dr.respondWithError(c, err, "Error creating software policy")
+		return
+	}
+
+	c.JSON(201, policy)
+}
+
+func (dr *DevicesRouter) deleteSoftwarePolicy(c *gin.Context) {
+	policy, err := dr.devicesService.DeleteSoftwarePolicy(c.Request.Context(), c.Param("policyId"))
+	if err != nil {
+		dr.respondWithError(c, err, "Error deleting software policy")
+		return
+	}
+	if policy == nil {
+		dr.policyNotFound(c, c.Param("policyId"))
+		return
+	}
+
+	c.JSON(204, gin.H{})
+}
+
+func (dr *DevicesRouter) policyNotFound(c *gin.Context, id string) {
+	dr.logger.InfoContext(c.Request.Context(), "Software policy not found", "policy_id", id)
+	c.JSON(404, gin.H{
+		"message": "Software policy not found",
+		"code":    "NOT_FOUND",
+	})
+}
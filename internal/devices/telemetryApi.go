@@ -0,0 +1,136 @@
+package devices
+
+import (
+	"devices_crud/internal/devices/model"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultReadingsRange is how far back readings are queried without a from
+// param.
+const defaultReadingsRange = 24 * time.Hour
+
+func (dr *DevicesRouter) ingestReadings(c *gin.Context) {
+	batch := &model.ReadingBatch{}
+	if err := c.BindJSON(batch); err != nil {
+		dr.logger.InfoContext(c.Request.Context(), "Invalid readings", "error", err)
+		c.JSON(400, gin.H{
+			"message": "Error binding readings",
+		})
+		return
+	}
+
+	ingested, err := dr.devicesService.IngestReadings(c.Request.Context(), c.Param("id"), batch)
+	if err != nil {
+		dr.respondWithError(c, err, "Error ingesting readings")
+		return
+	}
+	if ingested == nil {
+		dr.deviceNotFound(c)
+		return
+	}
+
+	c.JSON(202, gin.H{
+		"accepted": len(ingested.Readings),
+	})
+}
+
+func (dr *DevicesRouter) listLatestReadings(c *gin.Context) {
+	readings, err := dr.devicesService.LatestReadings(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		dr.respondWithError(c, err, "Error getting readings")
+		return
+	}
+	if readings == nil {
+		dr.deviceNotFound(c)
+		return
+	}
+
+	c.JSON(200, readings)
+}
+
+// listReadings lists the readings of the metric between the RFC 3339 from and
+// to params, the last day by default.
+func (dr *DevicesRouter) listReadings(c *gin.Context) {
+	from, to, ok := dr.readingsRange(c)
+	if !ok {
+		return
+	}
+
+	readings, err := dr.devicesService.Readings(c.Request.Context(), c.Param("id"), c.Param("metric"), from, to)
+	if err != nil {
+		dr.respondWithError(c, err, "Error getting readings")
+		return
+	}
+	if readings == nil {
+		dr.deviceNotFound(c)
+		return
+	}
+
+	c.JSON(200, readings)
+}
+
+// aggregateReadings summarizes the readings of the metric between the from
+// and to params per step param, e.g. 1h, or over the whole range without it.
+func (dr *DevicesRouter) aggregateReadings(c *gin.Context) {
+	from, to, ok := dr.readingsRange(c)
+	if !ok {
+		return
+	}
+	var step time.Duration
+	if param := c.Query("step"); param != "" {
+		parsed, err := time.ParseDuration(param)
+		if err != nil {
+			dr.logger.InfoContext(c.Request.Context(), "Invalid aggregate step", "error", err)
+			c.JSON(400, gin.H{
+				"message": "step must be a duration, e.g. 15m or 1h",
+			})
+			return
+		}
+		step = parsed
+	}
+
+	aggregates, err := dr.devicesService.ReadingAggregates(c.Request.Context(), c.Param("id"), c.Param("metric"), from, to, step)
+	if err != nil {
+		dr.respondWithError(c, err, "Error aggregating readings")
+		return
+	}
+	if aggregates == nil {
+		dr.deviceNotFound(c)
+		return
+	}
+
+	c.JSON(200, aggregates)
+}
+
+// readingsRange returns the from and to params, to defaulting to now and
+// from to defaultReadingsRange before to.
+func (dr *DevicesRouter) readingsRange(c *gin.Context) (time.Time, time.Time, bool) {
+	to := time.Now()
+	if param := c.Query("to"); param != "" {
+		parsed, err := time.Parse(time.RFC3339, param)
+		if err != nil {
+			dr.invalidReadingsTime(c, "to", err)
+			return time.Time{}, time.Time{}, false
+		}
+		to = parsed
+	}
+	from := to.Add(-defaultReadingsRange)
+	if param := c.Query("from"); param != "" {
+		parsed, err := time.Parse(time.RFC3339, param)
+		if err != nil {
+			dr.invalidReadingsTime(c, "from", err)
+			return time.Time{}, time.Time{}, false
+		}
+		from = parsed
+	}
+	return from, to, true
+}
+
+func (dr *DevicesRouter) invalidReadingsTime(c *gin.Context, param string, err error) {
+	dr.logger.InfoContext(c.Request.Context(), "Invalid readings range", "error", err)
+	c.JSON(400, gin.H{
+		"message": param + " must be an RFC 3339 timestamp",
+	})
+}
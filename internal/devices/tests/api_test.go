@@ -4,10 +4,11 @@ import (
 	"bytes"
 	"devices_crud/internal/devices"
 	"devices_crud/internal/devices/model"
+	"devices_crud/internal/drivers/graph"
 	"devices_crud/internal/drivers/rest"
 	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -17,14 +18,32 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
+// setupRouter serves the REST and GraphQL APIs of a fresh repository.
 func setupRouter() *gin.Engine {
 	gin.SetMode(gin.TestMode)
 	router := gin.Default()
-	rest.BuildRoutes(router, devices.NewDevicesDependencies(
-		&devices.DeviceDependencies{UseMocks: true, Logger: log.New(os.Stdout, "TEST: ", log.Ltime)}))
+	deps := devices.NewDevicesDependencies(
+		&devices.DeviceDependencies{UseMocks: true, Logger: slog.New(slog.NewTextHandler(os.Stdout, nil))})
+	rest.BuildRoutes(router, deps)
+	graph.BuildRoutes(router, deps)
 	return router
 }
 
+func serve(router *gin.Engine, method string, path string, body string) *httptest.ResponseRecorder {
+	httpReq, _ := http.NewRequest(method, path, bytes.NewReader([]byte(body)))
+	httpReq.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httpReq)
+	return w
+}
+
+func createDevice(router *gin.Engine, name string, brand string) string {
+	w := serve(router, "POST", "/v1/devices", fmt.Sprintf(`{"name":%q,"deviceBrand":%q}`, name, brand))
+	response := model.NewDeviceResponse{}
+	_ = json.Unmarshal(w.Body.Bytes(), &response)
+	return response.UUID
+}
+
 func TestShouldReturnEmptyListWhenThereIsNoDevices(t *testing.T) {
 	router := setupRouter()
 	expected := "[]"
@@ -0,0 +1,100 @@
+package tests
+
+import (
+	"devices_crud/internal/devices/model"
+	"encoding/json"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShouldCheckOutDeviceWithRest(t *testing.T) {
+	router := setupRouter()
+	created, _ := addTwoDevices(router)
+	path := "/v1/devices/" + created.UUID
+
+	w := serve(router, "POST", path+"/checkout", `{"assignee":"alice"}`)
+	assert.Equal(t, 409, w.Code)
+	assert.Contains(t, w.Body.String(), `"code":"DEVICE_UNAVAILABLE"`)
+
+	for _, status := range []string{"received", "provisioned", "in_service"} {
+		serve(router, "POST", path+"/transitions", `{"status":"`+status+`","reason":"onboarding"}`)
+	}
+	due := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	w = serve(router, "POST", path+"/checkout", `{"assignee":"alice","dueAt":"`+due+`"}`)
+	assignment := model.Assignment{}
+	json.Unmarshal(w.Body.Bytes(), &assignment)
+	assert.Equal(t, 201, w.Code)
+	assert.Equal(t, "alice", assignment.Assignee)
+
+	w = serve(router, "POST", path+"/checkout", `{"assignee":"bob"}`)
+	assert.Equal(t, 409, w.Code)
+	assert.Contains(t, w.Body.String(), `"code":"DEVICE_ASSIGNED"`)
+
+	w = serve(router, "POST", path+"/transfer", `{"assignee":"bob"}`)
+	assert.Equal(t, 201, w.Code)
+
+	overdue := []model.Assignment{}
+	at := url.QueryEscape(time.Now().Add(2 * time.Hour).Format(time.RFC3339))
+	w = serve(router, "GET", "/v1/assignments/overdue?at="+at, "")
+	json.Unmarshal(w.Body.Bytes(), &overdue)
+	assert.Empty(t, overdue)
+
+	w = serve(router, "POST", path+"/checkin", "")
+	assert.Equal(t, 200, w.Code)
+	w = serve(router, "POST", path+"/checkin", "")
+	assert.Equal(t, 409, w.Code)
+	assert.Contains(t, w.Body.String(), `"code":"DEVICE_NOT_ASSIGNED"`)
+
+	history := []model.Assignment{}
+	w = serve(router, "GET", path+"/assignments", "")
+	json.Unmarshal(w.Body.Bytes(), &history)
+	assert.Equal(t, 2, len(history))
+
+	held := []model.Assignment{}
+	w = serve(router, "GET", "/v1/assignments?assignee=bob", "")
+	json.Unmarshal(w.Body.Bytes(), &held)
+	if assert.Equal(t, 1, len(held)) {
+		assert.NotNil(t, held[0].CheckedInAt)
+	}
+
+	assert.Equal(t, 400, serve(router, "GET", "/v1/assignments", "").Code)
+	assert.Equal(t, 404, serve(router, "POST", "/v1/devices/missing/checkout", `{"assignee":"bob"}`).Code)
+	assert.Equal(t, 404, serve(router, "GET", "/v1/devices/missing/assignments", "").Code)
+}
+
+func TestShouldCheckOutDeviceWithGraphQL(t *testing.T) {
+	query := setupGraphQL()
+
+	w := query(`mutation { createDevice(input: {name: "pixel", deviceBrand: "Google", status: IN_SERVICE}) { id } }`)
+	response := struct {
+		Data struct {
+			CreateDevice struct{ ID string }
+		}
+	}{}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	id := response.Data.CreateDevice.ID
+
+	w = query(`mutation { checkOutDevice(deviceId: "` + id + `", assignee: "alice", dueAt: "tomorrow") { id } }`)
+	assert.Contains(t, w.Body.String(), `"code":"INVALID_ASSIGNMENT"`)
+
+	due := time.Now().Add(time.Hour).Format(time.RFC3339)
+	w = query(`mutation { checkOutDevice(deviceId: "` + id + `", assignee: "alice", dueAt: "` + due + `", note: "on call") {
+		assignee note overdue device { name }
+	} }`)
+	assert.JSONEq(t, `{"data":{"checkOutDevice":{"assignee":"alice","note":"on call","overdue":false,"device":{"name":"pixel"}}}}`, w.Body.String())
+
+	w = query(`mutation { transferDevice(deviceId: "` + id + `", assignee: "bob") { assignee } }`)
+	assert.JSONEq(t, `{"data":{"transferDevice":{"assignee":"bob"}}}`, w.Body.String())
+
+	w = query(`{ device(id: "` + id + `") { currentAssignment { assignee } assignments { assignee } } }`)
+	assert.JSONEq(t, `{"data":{"device":{"currentAssignment":{"assignee":"bob"},"assignments":[{"assignee":"alice"},{"assignee":"bob"}]}}}`, w.Body.String())
+
+	w = query(`mutation { checkInDevice(deviceId: "` + id + `") { assignee } }`)
+	assert.JSONEq(t, `{"data":{"checkInDevice":{"assignee":"bob"}}}`, w.Body.String())
+
+	w = query(`{ assignments(assignee: "alice") { note } overdueAssignments { id } }`)
+	assert.JSONEq(t, `{"data":{"assignments":[{"note":"on call\ntransferred to bob"}],"overdueAssignments":[]}}`, w.Body.String())
+}
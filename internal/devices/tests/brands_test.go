@@ -0,0 +1,85 @@
+package tests
+
+import (
+	"devices_crud/internal/devices/model"
+	"encoding/json"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShouldManageBrandsWithRest(t *testing.T) {
+	router := setupRouter()
+
+	w := serve(router, "PUT", "/v1/brands/Apple", `{"aliases":["Apple Computer"],"models":[{"name":"iPhone 15","aliases":["iphone15"]}]}`)
+	assert.Equal(t, 200, w.Code, w.Body.String())
+	w = serve(router, "PUT", "/v1/brands/Pear", `{"aliases":["apple computer inc"]}`)
+	assert.Equal(t, 400, w.Code)
+	assert.Contains(t, w.Body.String(), `"code":"INVALID_BRAND"`)
+
+	w = serve(router, "GET", "/v1/brands/resolve?name="+url.QueryEscape("APPLE Inc")+"&model=IPHONE-15", "")
+	assert.Equal(t, 200, w.Code)
+	assert.Contains(t, w.Body.String(), `"model":"iPhone 15"`)
+	assert.Contains(t, w.Body.String(), `"name":"Apple"`)
+	assert.Equal(t, 404, serve(router, "GET", "/v1/brands/resolve?name=Dell", "").Code)
+	assert.Equal(t, 400, serve(router, "GET", "/v1/brands/resolve", "").Code)
+
+	w = serve(router, "POST", "/v1/devices", `{"name":"mbp","deviceBrand":"apple computer"}`)
+	created := model.NewDeviceResponse{}
+	json.Unmarshal(w.Body.Bytes(), &created)
+	serve(router, "PATCH", "/v1/devices/"+created.UUID, `{"deviceBrand":"APPLE"}`)
+	device := model.Device{}
+	w = serve(router, "GET", "/v1/devices/"+created.UUID, "")
+	json.Unmarshal(w.Body.Bytes(), &device)
+	assert.Equal(t, "Apple", device.DeviceBrand)
+
+	serve(router, "POST", "/v1/devices", `{"name":"xps","deviceBrand":"dell inc"}`)
+	serve(router, "POST", "/v1/devices", `{"name":"latitude","deviceBrand":"Dell"}`)
+	serve(router, "POST", "/v1/devices", `{"name":"optiplex","deviceBrand":"Dell"}`)
+	migration := model.BrandMigration{}
+	w = serve(router, "POST", "/v1/brands/migrate", `{"addMissing":true}`)
+	json.Unmarshal(w.Body.Bytes(), &migration)
+	assert.True(t, migration.DryRun)
+	if assert.Equal(t, 1, len(migration.Changes), w.Body.String()) {
+		assert.Equal(t, "Dell", migration.Changes[0].To)
+	}
+	w = serve(router, "POST", "/v1/brands/migrate", `{"dryRun":false,"addMissing":true}`)
+	json.Unmarshal(w.Body.Bytes(), &migration)
+	assert.False(t, migration.DryRun)
+
+	brands := []model.Brand{}
+	w = serve(router, "GET", "/v1/brands", "")
+	json.Unmarshal(w.Body.Bytes(), &brands)
+	assert.Equal(t, 2, len(brands))
+	w = serve(router, "GET", "/v1/devices?filter="+url.QueryEscape(`brand = "Dell"`), "")
+	devices := []model.Device{}
+	json.Unmarshal(w.Body.Bytes(), &devices)
+	assert.Equal(t, 3, len(devices))
+
+	assert.Equal(t, 204, serve(router, "DELETE", "/v1/brands/Dell", "").Code)
+	assert.Equal(t, 404, serve(router, "GET", "/v1/brands/Dell", "").Code)
+}
+
+func TestShouldManageBrandsWithGraphQL(t *testing.T) {
+	query := setupGraphQL()
+
+	w := query(`mutation { saveBrand(input: {name: "HP", aliases: ["Hewlett-Packard"], models: [{name: "LaserJet"}]}) { name aliases models { name aliases } } }`)
+	assert.Contains(t, w.Body.String(), `{"name":"HP","aliases":["Hewlett-Packard"],"models":[{"name":"LaserJet","aliases":[]}]}`)
+
+	w = query(`mutation { createDevice(input: {name: "printer", deviceBrand: "hewlett packard co"}) { DeviceBrand brand { name } } }`)
+	assert.Contains(t, w.Body.String(), `"DeviceBrand":"HP","brand":{"name":"HP"}`)
+	w = query(`mutation { createDevice(input: {name: "pixel", deviceBrand: "Google"}) { brand { name } } }`)
+	assert.Contains(t, w.Body.String(), `"brand":null`)
+
+	w = query(`{ resolveBrand(name: "HP Inc.") { name } brands { name } }`)
+	assert.Contains(t, w.Body.String(), `"resolveBrand":{"name":"HP"},"brands":[{"name":"HP"}]`)
+
+	w = query(`mutation { migrateBrands(addMissing: true) { dryRun changes { from to } added { name } } }`)
+	assert.Contains(t, w.Body.String(), `"dryRun":true,"changes":[],"added":[{"name":"Google"}]`)
+
+	w = query(`mutation { saveBrand(input: {name: "Compaq", aliases: ["HP"]}) { name } }`)
+	assert.Contains(t, w.Body.String(), `"code":"INVALID_BRAND"`)
+	w = query(`mutation { deleteBrand(name: "HP") }`)
+	assert.Contains(t, w.Body.String(), `"deleteBrand":true`)
+}
@@ -0,0 +1,97 @@
+package tests
+
+import (
+	"bytes"
+	"devices_crud/internal/devices"
+	"devices_crud/internal/devices/model"
+	"devices_crud/internal/drivers/graph"
+	"devices_crud/internal/drivers/rest"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func deviceIDs(devices []model.Device) []string {
+	ids := make([]string, len(devices))
+	for i := range devices {
+		ids[i] = devices[i].ID
+	}
+	return ids
+}
+
+func TestShouldRecordHeartbeatsWithRest(t *testing.T) {
+	router := setupRouter()
+
+	online, offline := addTwoDevices(router)
+	path := "/v1/devices/" + online.UUID
+
+	status := model.ConnectivityStatus{}
+	w := serve(router, "GET", path+"/connectivity", "")
+	assert.Equal(t, 200, w.Code)
+	json.Unmarshal(w.Body.Bytes(), &status)
+	assert.Equal(t, model.ConnectivityUnknown, status.Status)
+	assert.Nil(t, status.LastSeenAt)
+
+	w = serve(router, "POST", path+"/heartbeat", "")
+	assert.Equal(t, 200, w.Code)
+	json.Unmarshal(w.Body.Bytes(), &status)
+	assert.Equal(t, online.UUID, status.DeviceID)
+	assert.Equal(t, model.ConnectivityOnline, status.Status)
+	assert.NotNil(t, status.LastSeenAt)
+	assert.Equal(t, 404, serve(router, "POST", "/v1/devices/missing/heartbeat", "").Code)
+	assert.Equal(t, 404, serve(router, "GET", "/v1/devices/missing/connectivity", "").Code)
+
+	device := model.Device{}
+	json.Unmarshal(serve(router, "GET", path, "").Body.Bytes(), &device)
+	assert.NotNil(t, device.LastSeenAt)
+
+	devices := []model.Device{}
+	w = serve(router, "GET", "/v1/devices?connectivity=online", "")
+	assert.Equal(t, 200, w.Code)
+	json.Unmarshal(w.Body.Bytes(), &devices)
+	assert.Contains(t, deviceIDs(devices), online.UUID)
+	assert.NotContains(t, deviceIDs(devices), offline.UUID)
+
+	json.Unmarshal(serve(router, "GET", "/v1/devices?connectivity=unknown", "").Body.Bytes(), &devices)
+	assert.Contains(t, deviceIDs(devices), offline.UUID)
+	assert.NotContains(t, deviceIDs(devices), online.UUID)
+
+	w = serve(router, "GET", "/v1/devices?connectivity=away", "")
+	assert.Equal(t, 400, w.Code)
+	assert.Contains(t, w.Body.String(), `"code":"INVALID_CONNECTIVITY"`)
+}
+
+func TestShouldResolveConnectivityWithGraphQL(t *testing.T) {
+	// Heartbeats are sent over REST, both drivers need the same service.
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	deps := devices.NewDevicesDependencies(&devices.DeviceDependencies{UseMocks: true, Logger: slog.New(slog.NewTextHandler(os.Stdout, nil))})
+	rest.BuildRoutes(router, deps)
+	graph.BuildRoutes(router, deps)
+	query := func(query string) string {
+		body, _ := json.Marshal(map[string]interface{}{"query": query})
+		httpReq, _ := http.NewRequest("POST", "/query", bytes.NewReader(body))
+		httpReq.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httpReq)
+		return w.Body.String()
+	}
+
+	online, offline := addTwoDevices(router)
+	assert.Contains(t, query(`{ device(id: "`+online.UUID+`") { lastSeenAt connectivity } }`), `"lastSeenAt":null,"connectivity":"UNKNOWN"`)
+
+	serve(router, "POST", "/v1/devices/"+online.UUID+"/heartbeat", "")
+	body := query(`{ device(id: "` + online.UUID + `") { lastSeenAt connectivity } }`)
+	assert.Contains(t, body, `"connectivity":"ONLINE"`)
+	assert.NotContains(t, body, `"lastSeenAt":null`)
+
+	body = query(`{ devices(connectivity: ONLINE) { id connectivity } }`)
+	assert.Contains(t, body, `{"id":"`+online.UUID+`","connectivity":"ONLINE"}`)
+	assert.NotContains(t, body, offline.UUID)
+}
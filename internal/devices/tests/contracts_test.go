@@ -0,0 +1,83 @@
+package tests
+
+import (
+	"devices_crud/internal/devices/model"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShouldManageContractsWithRest(t *testing.T) {
+	router := setupRouter()
+
+	created, _ := addTwoDevices(router)
+	path := "/v1/devices/" + created.UUID
+	startsAt := time.Now().AddDate(-1, 0, 0).UTC().Format(time.RFC3339)
+	endsAt := time.Now().AddDate(0, 0, 20).UTC().Format(time.RFC3339)
+
+	w := serve(router, "POST", path+"/contracts", `{"kind":"lease","provider":"Dell","startsAt":"`+startsAt+`","endsAt":"`+endsAt+`"}`)
+	assert.Equal(t, 400, w.Code)
+	assert.Contains(t, w.Body.String(), `"code":"INVALID_CONTRACT"`)
+	assert.Equal(t, 404, serve(router, "POST", "/v1/devices/missing/contracts", `{"kind":"warranty","provider":"Dell","startsAt":"`+startsAt+`","endsAt":"`+endsAt+`"}`).Code)
+
+	contract := model.Contract{}
+	w = serve(router, "POST", path+"/contracts", `{"kind":"warranty","provider":"Dell","reference":"W-1","startsAt":"`+startsAt+`","endsAt":"`+endsAt+`","coverage":["Parts","onsite"]}`)
+	assert.Equal(t, 201, w.Code)
+	json.Unmarshal(w.Body.Bytes(), &contract)
+	assert.Equal(t, []string{"parts", "onsite"}, contract.Coverage)
+
+	contracts := []model.Contract{}
+	w = serve(router, "GET", path+"/contracts", "")
+	json.Unmarshal(w.Body.Bytes(), &contracts)
+	assert.Equal(t, 1, len(contracts))
+	assert.Equal(t, 404, serve(router, "GET", "/v1/devices/missing/contracts", "").Code)
+
+	expiring := []model.ExpiringContract{}
+	w = serve(router, "GET", "/v1/contracts/expiring?days=30&kind=warranty", "")
+	assert.Equal(t, 200, w.Code)
+	json.Unmarshal(w.Body.Bytes(), &expiring)
+	if assert.Equal(t, 1, len(expiring)) {
+		assert.Equal(t, contract.ID, expiring[0].Contract.ID)
+		assert.False(t, expiring[0].Renewed)
+	}
+	assert.Equal(t, "[]", serve(router, "GET", "/v1/contracts/expiring?days=10", "").Body.String())
+	assert.Equal(t, "[]", serve(router, "GET", "/v1/contracts/expiring?kind=support", "").Body.String())
+	assert.Equal(t, 400, serve(router, "GET", "/v1/contracts/expiring?days=soon", "").Code)
+	assert.Equal(t, 400, serve(router, "GET", "/v1/contracts/expiring?days=-1", "").Code)
+
+	assert.Equal(t, 204, serve(router, "DELETE", path+"/contracts/"+contract.ID, "").Code)
+	assert.Equal(t, 404, serve(router, "DELETE", path+"/contracts/"+contract.ID, "").Code)
+	assert.Equal(t, "[]", serve(router, "GET", path+"/contracts", "").Body.String())
+}
+
+func TestShouldResolveContractsWithGraphQL(t *testing.T) {
+	query := setupGraphQL()
+
+	w := query(`mutation { createDevice(input: {name: "server", deviceBrand: "Dell"}) { id underWarranty } }`)
+	assert.Contains(t, w.Body.String(), `"underWarranty":false`)
+	created := struct {
+		Data struct {
+			CreateDevice struct{ ID string }
+		}
+	}{}
+	json.Unmarshal(w.Body.Bytes(), &created)
+	id := created.Data.CreateDevice.ID
+
+	startsAt := time.Now().AddDate(-1, 0, 0).UTC().Format(time.RFC3339)
+	endsAt := time.Now().AddDate(0, 0, 20).Add(time.Hour).UTC().Format(time.RFC3339)
+	w = query(`mutation { addContract(input: {deviceId: "` + id + `", kind: WARRANTY, provider: "Dell", startsAt: "` + startsAt + `", endsAt: "` + endsAt + `", coverage: ["parts"]}) { kind provider coverage active daysLeft } }`)
+	assert.Contains(t, w.Body.String(), `"kind":"WARRANTY","provider":"Dell","coverage":["parts"],"active":true,"daysLeft":20`)
+	w = query(`mutation { addContract(input: {deviceId: "` + id + `", kind: SUPPORT, provider: "Dell", startsAt: "` + startsAt + `", endsAt: "tomorrow"}) { id } }`)
+	assert.Contains(t, w.Body.String(), `"code":"INVALID_CONTRACT"`)
+
+	w = query(`{ device(id: "` + id + `") { underWarranty contracts { provider } } }`)
+	assert.Contains(t, w.Body.String(), `"underWarranty":true,"contracts":[{"provider":"Dell"}]`)
+	w = query(`{ expiringContracts(days: 30) { daysLeft renewed contract { kind device { name } } } }`)
+	assert.Contains(t, w.Body.String(), `"expiringContracts":[{"daysLeft":20,"renewed":false,"contract":{"kind":"WARRANTY","device":{"name":"server"}}}]`)
+	w = query(`{ expiringContracts(kind: SUPPORT) { daysLeft } }`)
+	assert.Contains(t, w.Body.String(), `"expiringContracts":[]`)
+	w = query(`{ expiringContracts(days: -1) { daysLeft } }`)
+	assert.Contains(t, w.Body.String(), `"code":"INVALID_CONTRACT"`)
+}
@@ -0,0 +1,115 @@
+package tests
+
+import (
+	"devices_crud/internal/devices/model"
+	"devices_crud/internal/jsonschema"
+	"encoding/json"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const laptopSchema = `{
+	"type": "object",
+	"properties": {
+		"serial": {"type": "string", "minLength": 4},
+		"mac": {"type": "string", "format": "mac"},
+		"os": {"enum": ["linux", "macos", "windows"]},
+		"ramGb": {"type": "integer", "minimum": 1},
+		"managed": {"type": "boolean"}
+	},
+	"required": ["serial"],
+	"additionalProperties": false
+}`
+
+func decode(t *testing.T, document string) interface{} {
+	var value interface{}
+	assert.Nil(t, json.Unmarshal([]byte(document), &value))
+	return value
+}
+
+func TestShouldManageTypedDevicesWithRest(t *testing.T) {
+	router := setupRouter()
+
+	w := serve(router, "PUT", "/v1/device-types/laptop", `{"description":"Laptops","schema":`+laptopSchema+`}`)
+	assert.Equal(t, 200, w.Code, w.Body.String())
+	w = serve(router, "PUT", "/v1/device-types/broken", `{"schema":{"type":"text"}}`)
+	assert.Equal(t, 400, w.Code)
+	assert.Contains(t, w.Body.String(), "INVALID_DEVICE_TYPE")
+
+	w = serve(router, "POST", "/v1/devices", `{"name":"mbp","type":"laptop","attributes":{"serial":"1"}}`)
+	response := struct {
+		Code   string
+		Errors jsonschema.Errors
+	}{}
+	_ = json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(t, 400, w.Code)
+	assert.Equal(t, "INVALID_ATTRIBUTES", response.Code)
+	assert.Equal(t, jsonschema.Errors{{Path: "/serial", Message: "must be at least 4 characters long"}}, response.Errors)
+
+	serve(router, "POST", "/v1/devices", `{"name":"mbp","type":"laptop","attributes":{"serial":"C02X1234","ramGb":32,"managed":true}}`)
+	serve(router, "POST", "/v1/devices", `{"name":"xps","type":"laptop","attributes":{"serial":"DL-99","ramGb":8}}`)
+
+	found := []model.Device{}
+	w = serve(router, "GET", "/v1/devices?filter="+url.QueryEscape(`attributes.ramGb >= 16 AND attributes.managed = true`), "")
+	_ = json.Unmarshal(w.Body.Bytes(), &found)
+	if assert.Equal(t, 1, len(found), w.Body.String()) {
+		assert.Equal(t, "mbp", found[0].Name)
+		assert.Equal(t, "C02X1234", found[0].Attributes["serial"])
+	}
+
+	w = serve(router, "GET", "/v1/devices/search?q=dl-99", "")
+	assert.Contains(t, w.Body.String(), `"name":"xps"`)
+
+	w = serve(router, "DELETE", "/v1/device-types/laptop", "")
+	assert.Equal(t, 409, w.Code)
+	assert.Contains(t, w.Body.String(), "DEVICE_TYPE_IN_USE")
+}
+
+func TestShouldResolveAttributesWithGraphQL(t *testing.T) {
+	router := setupRouter()
+
+	query := func(query string, variables map[string]interface{}) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(map[string]interface{}{"query": query, "variables": variables})
+		return serve(router, "POST", "/query", string(body))
+	}
+
+	w := query(`mutation($schema: JSON!) { saveDeviceType(input: {name: "laptop", schema: $schema}) { name } }`,
+		map[string]interface{}{"schema": decode(t, laptopSchema)})
+	assert.Contains(t, w.Body.String(), `"name":"laptop"`)
+
+	w = query(`mutation { createDevice(input: {name: "mbp", deviceBrand: "Apple", type: "laptop", attributes: {serial: "C02X1234", ramGb: 16}}) {
+		type
+		attributes
+		serial: stringAttribute(name: "serial")
+		ram: numberAttribute(name: "ramGb")
+		managed: booleanAttribute(name: "managed")
+		deviceType { name }
+	} }`, nil)
+	response := struct {
+		Data struct {
+			CreateDevice struct {
+				Type       string
+				Attributes map[string]interface{}
+				Serial     string
+				Ram        float64
+				Managed    *bool
+				DeviceType struct{ Name string }
+			}
+		}
+	}{}
+	_ = json.Unmarshal(w.Body.Bytes(), &response)
+	created := response.Data.CreateDevice
+	assert.Equal(t, "laptop", created.Type, w.Body.String())
+	assert.Equal(t, map[string]interface{}{"serial": "C02X1234", "ramGb": 16.0}, created.Attributes)
+	assert.Equal(t, "C02X1234", created.Serial)
+	assert.Equal(t, 16.0, created.Ram)
+	assert.Nil(t, created.Managed)
+	assert.Equal(t, "laptop", created.DeviceType.Name)
+
+	w = query(`mutation { createDevice(input: {name: "xps", deviceBrand: "Dell", type: "laptop", attributes: {ramGb: 8}}) { id } }`, nil)
+	assert.Contains(t, w.Body.String(), `"code":"INVALID_ATTRIBUTES"`)
+	assert.Contains(t, w.Body.String(), `"path":"/serial"`)
+}
@@ -0,0 +1,107 @@
+package tests
+
+import (
+	"devices_crud/internal/devices"
+	"devices_crud/internal/devices/model"
+	"devices_crud/internal/drivers/rest"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShouldMergeDuplicatesWithRest(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	rest.BuildRoutes(router, devices.NewDevicesDependencies(&devices.DeviceDependencies{
+		UseMocks:   true,
+		Logger:     slog.New(slog.NewTextHandler(os.Stdout, nil)),
+		UniqueKeys: []model.UniquenessKey{{"brand", "attributes.serial"}},
+	}))
+
+	created := model.NewDeviceResponse{}
+	w := serve(router, "POST", "/v1/devices", `{"name":"Lab Printer","deviceBrand":"Brother","attributes":{"serial":"X1"}}`)
+	assert.Equal(t, 201, w.Code)
+	json.Unmarshal(w.Body.Bytes(), &created)
+	target := created.UUID
+
+	w = serve(router, "POST", "/v1/devices", `{"name":"printer","deviceBrand":"Brother Ltd","attributes":{"serial":"x1"}}`)
+	assert.Equal(t, 409, w.Code)
+	assert.Contains(t, w.Body.String(), `"code":"DUPLICATE_DEVICE"`)
+	w = serve(router, "POST", "/v1/devices", `{"name":"lab-printer","deviceBrand":"Brother","labels":{"team":"it"}}`)
+	assert.Equal(t, 201, w.Code)
+	json.Unmarshal(w.Body.Bytes(), &created)
+	source := created.UUID
+
+	candidates := []model.DuplicateCandidate{}
+	w = serve(router, "GET", "/v1/devices/duplicates", "")
+	assert.Equal(t, 200, w.Code)
+	json.Unmarshal(w.Body.Bytes(), &candidates)
+	if assert.Equal(t, 1, len(candidates)) {
+		assert.Equal(t, target, candidates[0].Device.ID)
+		assert.Equal(t, source, candidates[0].Duplicate.ID)
+		assert.Equal(t, 1.0, candidates[0].Score)
+	}
+	assert.Equal(t, 400, serve(router, "GET", "/v1/devices/duplicates?threshold=high", "").Code)
+	w = serve(router, "GET", "/v1/devices/duplicates?threshold=2", "")
+	assert.Equal(t, 400, w.Code)
+	assert.Contains(t, w.Body.String(), `"code":"INVALID_THRESHOLD"`)
+
+	json.Unmarshal(serve(router, "GET", "/v1/devices/"+source+"/duplicates", "").Body.Bytes(), &candidates)
+	assert.Equal(t, 1, len(candidates))
+	assert.Equal(t, 404, serve(router, "GET", "/v1/devices/missing/duplicates", "").Code)
+
+	w = serve(router, "POST", "/v1/devices/"+target+"/merge", fmt.Sprintf(`{"sourceId":%q}`, target))
+	assert.Equal(t, 400, w.Code)
+	assert.Contains(t, w.Body.String(), `"code":"INVALID_MERGE"`)
+	assert.Equal(t, 404, serve(router, "POST", "/v1/devices/missing/merge", fmt.Sprintf(`{"sourceId":%q}`, source)).Code)
+
+	merged := model.Device{}
+	w = serve(router, "POST", "/v1/devices/"+target+"/merge", fmt.Sprintf(`{"sourceId":%q}`, source))
+	assert.Equal(t, 200, w.Code)
+	json.Unmarshal(w.Body.Bytes(), &merged)
+	assert.Equal(t, target, merged.ID)
+	assert.Equal(t, "it", merged.Labels["team"])
+
+	w = serve(router, "GET", "/v1/devices/"+source, "")
+	assert.Equal(t, 301, w.Code)
+	assert.Equal(t, "/v1/devices/"+target, w.Header().Get("Location"))
+	assert.Equal(t, 404, serve(router, "GET", "/v1/devices/missing", "").Code)
+	assert.Equal(t, "[]", serve(router, "GET", "/v1/devices/duplicates", "").Body.String())
+}
+
+func TestShouldMergeDuplicatesWithGraphQL(t *testing.T) {
+	query := setupGraphQL()
+
+	ids := make([]string, 2)
+	for i, name := range []string{"Reception iPad", "reception-ipad"} {
+		w := query(fmt.Sprintf(`mutation { createDevice(input: {name: %q, deviceBrand: "Apple"}) { id } }`, name))
+		created := struct {
+			Data struct {
+				CreateDevice struct{ ID string }
+			}
+		}{}
+		json.Unmarshal(w.Body.Bytes(), &created)
+		ids[i] = created.Data.CreateDevice.ID
+	}
+
+	w := query(`{ duplicateCandidates { score device { id } duplicate { id } } }`)
+	assert.Contains(t, w.Body.String(), fmt.Sprintf(`{"score":1,"device":{"id":%q},"duplicate":{"id":%q}}`, ids[0], ids[1]))
+	w = query(`{ duplicateCandidates(threshold: 3) { score } }`)
+	assert.Contains(t, w.Body.String(), `"code":"INVALID_THRESHOLD"`)
+	w = query(fmt.Sprintf(`{ device(id: %q) { duplicates { duplicate { id } } } }`, ids[1]))
+	assert.Contains(t, w.Body.String(), fmt.Sprintf(`"duplicates":[{"duplicate":{"id":%q}}]`, ids[0]))
+
+	w = query(fmt.Sprintf(`mutation { mergeDevices(id: %q, sourceId: %q) { id } }`, ids[0], ids[1]))
+	assert.Contains(t, w.Body.String(), fmt.Sprintf(`"mergeDevices":{"id":%q}`, ids[0]))
+	w = query(fmt.Sprintf(`mutation { mergeDevices(id: %q, sourceId: %q) { id } }`, ids[0], ids[1]))
+	assert.Contains(t, w.Body.String(), `"code":"INVALID_MERGE"`)
+
+	// The ID of the merged device leads to the device it was merged into.
+	w = query(fmt.Sprintf(`{ device(id: %q) { id duplicates { score } } }`, ids[1]))
+	assert.Contains(t, w.Body.String(), fmt.Sprintf(`"device":{"id":%q,"duplicates":[]}`, ids[0]))
+}
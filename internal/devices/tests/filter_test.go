@@ -0,0 +1,65 @@
+package tests
+
+import (
+	"devices_crud/internal/devices/model"
+	"encoding/json"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShouldFilterDevicesWithRest(t *testing.T) {
+	router := setupRouter()
+	printer := createDevice(router, "lab-printer", "Brother")
+	createDevice(router, "lab-macbook", "Apple")
+	createDevice(router, "front-desk-printer", "Brother")
+
+	w := serve(router, "GET", "/v1/devices?filter="+url.QueryEscape(`brand = "Brother" AND name ~ "lab-*"`), "")
+	found := []model.Device{}
+	_ = json.Unmarshal(w.Body.Bytes(), &found)
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "1", w.Header().Get("X-Total-Count"))
+	if assert.Equal(t, 1, len(found)) {
+		assert.Equal(t, printer, found[0].ID)
+	}
+
+	w = serve(router, "GET", "/v1/devices?filter="+url.QueryEscape(`brand = "Brother" AND`), "")
+	response := struct {
+		Message  string
+		Code     string
+		Position int
+	}{}
+	_ = json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(t, 400, w.Code)
+	assert.Equal(t, "INVALID_FILTER", response.Code)
+	assert.Equal(t, 22, response.Position)
+	assert.Contains(t, response.Message, "position 22")
+}
+
+func TestShouldFilterDevicesWithGraphQL(t *testing.T) {
+	router := setupRouter()
+	macbook := createDevice(router, "MacBook Pro", "Apple")
+	createDevice(router, "lab-printer", "Brother")
+
+	w := serve(router, "POST", "/query", `{"query":"{ devices(filter: \"brand = \\\"Apple\\\"\") { id } }"}`)
+	response := struct {
+		Data struct {
+			Devices []struct{ ID string }
+		}
+		Errors []struct {
+			Extensions map[string]interface{}
+		}
+	}{}
+	_ = json.Unmarshal(w.Body.Bytes(), &response)
+	if assert.Equal(t, 1, len(response.Data.Devices), w.Body.String()) {
+		assert.Equal(t, macbook, response.Data.Devices[0].ID)
+	}
+
+	w = serve(router, "POST", "/query", `{"query":"{ devices(filter: \"color = 1\") { id } }"}`)
+	_ = json.Unmarshal(w.Body.Bytes(), &response)
+	if assert.Equal(t, 1, len(response.Errors), w.Body.String()) {
+		assert.Equal(t, "INVALID_FILTER", response.Errors[0].Extensions["code"])
+		assert.Equal(t, 1.0, response.Errors[0].Extensions["position"])
+	}
+}
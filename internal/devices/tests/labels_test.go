@@ -0,0 +1,96 @@
+package tests
+
+import (
+	"devices_crud/internal/devices/model"
+	"encoding/json"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func names(devices []model.Device) []string {
+	names := make([]string, len(devices))
+	for i, device := range devices {
+		names[i] = device.Name
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestShouldManageLabelsWithRest(t *testing.T) {
+	router := setupRouter()
+
+	w := serve(router, "POST", "/v1/devices", `{"name":"web-1","deviceBrand":"Dell","labels":{"env":"prod"}}`)
+	created := model.NewDeviceResponse{}
+	_ = json.Unmarshal(w.Body.Bytes(), &created)
+	serve(router, "POST", "/v1/devices", `{"name":"ci-1","deviceBrand":"Dell","labels":{"env":"dev"}}`)
+
+	w = serve(router, "PUT", "/v1/devices/"+created.UUID+"/labels", `{"team":"web","example.com/owner":"alice"}`)
+	device := model.Device{}
+	_ = json.Unmarshal(w.Body.Bytes(), &device)
+	assert.Equal(t, 200, w.Code, w.Body.String())
+	assert.Equal(t, map[string]string{"env": "prod", "team": "web", "example.com/owner": "alice"}, device.Labels)
+
+	w = serve(router, "DELETE", "/v1/devices/"+created.UUID+"/labels/example.com/owner", "")
+	device = model.Device{}
+	_ = json.Unmarshal(w.Body.Bytes(), &device)
+	assert.Equal(t, map[string]string{"env": "prod", "team": "web"}, device.Labels)
+
+	found := []model.Device{}
+	w = serve(router, "GET", "/v1/devices?selector="+url.QueryEscape("env in (prod,staging),team"), "")
+	_ = json.Unmarshal(w.Body.Bytes(), &found)
+	assert.Equal(t, []string{"web-1"}, names(found))
+	assert.Equal(t, "1", w.Header().Get("X-Total-Count"))
+
+	w = serve(router, "GET", "/v1/devices?selector="+url.QueryEscape("env in prod"), "")
+	assert.Equal(t, 400, w.Code)
+	assert.JSONEq(t, `{"message":"invalid label selector: syntax error at position 8: expected '(' after in","code":"INVALID_SELECTOR","position":8}`, w.Body.String())
+
+	w = serve(router, "PUT", "/v1/devices/"+created.UUID+"/labels", `{"env":"not valid"}`)
+	assert.Equal(t, 400, w.Code)
+	assert.Contains(t, w.Body.String(), "INVALID_LABELS")
+
+	w = serve(router, "PUT", "/v1/devices/missing/labels", `{"env":"prod"}`)
+	assert.Equal(t, 404, w.Code)
+}
+
+func TestShouldManageLabelsWithGraphQL(t *testing.T) {
+	router := setupRouter()
+
+	query := func(query string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(map[string]interface{}{"query": query})
+		return serve(router, "POST", "/query", string(body))
+	}
+
+	w := query(`mutation { createDevice(input: {name: "web-1", deviceBrand: "Dell"}) { id } }`)
+	response := struct {
+		Data struct {
+			CreateDevice struct{ ID string }
+		}
+	}{}
+	_ = json.Unmarshal(w.Body.Bytes(), &response)
+	id := response.Data.CreateDevice.ID
+
+	w = query(`mutation { setLabels(deviceId: "` + id + `", labels: [{key: "team", value: "web"}, {key: "env", value: "prod"}]) {
+		labels { key value }
+		env: label(key: "env")
+		zone: label(key: "zone")
+	} }`)
+	assert.JSONEq(t, `{"data":{"setLabels":{"labels":[{"key":"env","value":"prod"},{"key":"team","value":"web"}],"env":"prod","zone":null}}}`, w.Body.String())
+
+	w = query(`{ devices(selector: "env=prod,!retired") { name } }`)
+	assert.JSONEq(t, `{"data":{"devices":[{"name":"web-1"}]}}`, w.Body.String())
+
+	w = query(`mutation { removeLabels(deviceId: "` + id + `", keys: ["env"]) { labels { key } } }`)
+	assert.JSONEq(t, `{"data":{"removeLabels":{"labels":[{"key":"team"}]}}}`, w.Body.String())
+
+	w = query(`{ devices(selector: "env=") { name } }`)
+	assert.JSONEq(t, `{"data":{"devices":[]}}`, w.Body.String())
+
+	w = query(`{ devices(selector: "env in prod") { name } }`)
+	assert.Contains(t, w.Body.String(), `"code":"INVALID_SELECTOR"`)
+	assert.Contains(t, w.Body.String(), `"position":8`)
+}
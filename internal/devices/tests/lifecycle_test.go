@@ -0,0 +1,106 @@
+package tests
+
+import (
+	"bytes"
+	"devices_crud/internal/devices"
+	"devices_crud/internal/devices/model"
+	"devices_crud/internal/drivers/graph"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShouldTransitionDevice(t *testing.T) {
+	router := setupRouter()
+	created, _ := addTwoDevices(router)
+	path := "/v1/devices/" + created.UUID + "/transitions"
+
+	w := serve(router, "POST", path, `{"status":"received","reason":"delivered by courier"}`)
+	device := model.Device{}
+	json.Unmarshal(w.Body.Bytes(), &device)
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, model.StatusReceived, device.Status)
+
+	w = serve(router, "POST", path, `{"status":"disposed","reason":"broken"}`)
+	assert.Equal(t, 409, w.Code)
+	assert.Contains(t, w.Body.String(), `"code":"ILLEGAL_TRANSITION"`)
+
+	w = serve(router, "POST", path, `{"status":"provisioned"}`)
+	assert.Equal(t, 400, w.Code)
+	assert.Contains(t, w.Body.String(), `"code":"INVALID_TRANSITION"`)
+
+	w = serve(router, "POST", path, `{"status":"lost","reason":"lost"}`)
+	assert.Equal(t, 400, w.Code)
+	assert.Contains(t, w.Body.String(), `"code":"INVALID_STATUS"`)
+
+	w = serve(router, "GET", path, "")
+	history := []model.StatusChange{}
+	json.Unmarshal(w.Body.Bytes(), &history)
+	if assert.Equal(t, 2, len(history)) {
+		assert.Equal(t, model.StatusOrdered, history[0].To)
+		assert.Equal(t, "delivered by courier", history[1].Reason)
+	}
+
+	w = serve(router, "GET", "/v1/devices?filter=status%20%3D%20%22received%22", "")
+	found := []model.Device{}
+	json.Unmarshal(w.Body.Bytes(), &found)
+	if assert.Equal(t, 1, len(found)) {
+		assert.Equal(t, created.UUID, found[0].ID)
+	}
+
+	w = serve(router, "POST", "/v1/devices/missing/transitions", `{"status":"received","reason":"delivered"}`)
+	assert.Equal(t, 404, w.Code)
+	w = serve(router, "GET", "/v1/devices/lifecycle", "")
+	assert.Contains(t, w.Body.String(), `"disposed":[]`)
+}
+
+// setupGraphQL returns a function posting GraphQL queries to a fresh server.
+func setupGraphQL() func(query string) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	graph.BuildRoutes(router, devices.NewDevicesDependencies(
+		&devices.DeviceDependencies{UseMocks: true, Logger: slog.New(slog.NewTextHandler(os.Stdout, nil))}))
+	return func(query string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(map[string]interface{}{"query": query})
+		httpReq, _ := http.NewRequest("POST", "/query", bytes.NewReader(body))
+		httpReq.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httpReq)
+		return w
+	}
+}
+
+func TestShouldTransitionDeviceWithGraphQL(t *testing.T) {
+	query := setupGraphQL()
+
+	w := query(`mutation { createDevice(input: {name: "pixel", deviceBrand: "Google", status: IN_SERVICE}) { id status } }`)
+	response := struct {
+		Data struct {
+			CreateDevice struct {
+				ID     string
+				Status string
+			}
+		}
+	}{}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(t, "IN_SERVICE", response.Data.CreateDevice.Status)
+	id := response.Data.CreateDevice.ID
+
+	w = query(`mutation { transitionDevice(id: "` + id + `", status: IN_REPAIR, reason: "battery") {
+		status
+		statusHistory { from to reason }
+	} }`)
+	assert.JSONEq(t, `{"data":{"transitionDevice":{"status":"IN_REPAIR","statusHistory":[
+		{"from":null,"to":"IN_SERVICE","reason":null},
+		{"from":"IN_SERVICE","to":"IN_REPAIR","reason":"battery"}
+	]}}}`, w.Body.String())
+
+	w = query(`mutation { transitionDevice(id: "` + id + `", status: ORDERED, reason: "mistake") { status } }`)
+	assert.Contains(t, w.Body.String(), `"code":"ILLEGAL_TRANSITION"`)
+}
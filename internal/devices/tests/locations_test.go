@@ -0,0 +1,111 @@
+package tests
+
+import (
+	"devices_crud/internal/devices/model"
+	"encoding/json"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShouldManageLocationsWithRest(t *testing.T) {
+	router := setupRouter()
+
+	site, building := model.Location{}, model.Location{}
+	w := serve(router, "POST", "/v1/locations", `{"name":"HQ","kind":"site"}`)
+	assert.Equal(t, 201, w.Code)
+	json.Unmarshal(w.Body.Bytes(), &site)
+	w = serve(router, "POST", "/v1/locations", `{"name":"Building A","kind":"building","parentId":"`+site.ID+`"}`)
+	json.Unmarshal(w.Body.Bytes(), &building)
+	assert.Equal(t, site.ID, building.ParentID)
+
+	w = serve(router, "POST", "/v1/locations", `{"name":"Annex","kind":"site","parentId":"`+building.ID+`"}`)
+	assert.Equal(t, 400, w.Code)
+	assert.Contains(t, w.Body.String(), `"code":"INVALID_LOCATION"`)
+	w = serve(router, "POST", "/v1/locations/"+site.ID+"/move", `{"parentId":"`+building.ID+`"}`)
+	assert.Equal(t, 409, w.Code)
+	assert.Contains(t, w.Body.String(), `"code":"LOCATION_CYCLE"`)
+
+	w = serve(router, "GET", "/v1/locations/resolve?path="+url.QueryEscape("HQ/Building A"), "")
+	assert.Contains(t, w.Body.String(), building.ID)
+	w = serve(router, "GET", "/v1/locations/"+building.ID+"/path", "")
+	assert.Contains(t, w.Body.String(), `"path":"HQ/Building A"`)
+	assert.Equal(t, 404, serve(router, "GET", "/v1/locations/resolve?path=HQ/C", "").Code)
+
+	created, _ := addTwoDevices(router)
+	path := "/v1/devices/" + created.UUID
+	w = serve(router, "POST", path+"/move", `{"locationId":"missing"}`)
+	assert.Equal(t, 400, w.Code)
+	assert.Contains(t, w.Body.String(), `"code":"UNKNOWN_LOCATION"`)
+	w = serve(router, "POST", path+"/move", `{"locationId":"`+building.ID+`","reason":"installed"}`)
+	device := model.Device{}
+	json.Unmarshal(w.Body.Bytes(), &device)
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, building.ID, device.LocationID)
+
+	devices := []model.Device{}
+	w = serve(router, "GET", "/v1/locations/"+site.ID+"/devices", "")
+	json.Unmarshal(w.Body.Bytes(), &devices)
+	assert.Equal(t, 1, len(devices))
+	w = serve(router, "GET", "/v1/locations/"+site.ID+"/devices?recursive=false", "")
+	json.Unmarshal(w.Body.Bytes(), &devices)
+	assert.Empty(t, devices)
+	w = serve(router, "GET", "/v1/devices?filter="+url.QueryEscape(`locationId = "`+building.ID+`"`), "")
+	json.Unmarshal(w.Body.Bytes(), &devices)
+	assert.Equal(t, 1, len(devices))
+
+	w = serve(router, "DELETE", "/v1/locations/"+building.ID, "")
+	assert.Equal(t, 409, w.Code)
+	assert.Contains(t, w.Body.String(), `"code":"LOCATION_NOT_EMPTY"`)
+	serve(router, "POST", path+"/move", `{}`)
+	assert.Equal(t, 204, serve(router, "DELETE", "/v1/locations/"+building.ID, "").Code)
+	assert.Equal(t, 404, serve(router, "GET", "/v1/locations/"+building.ID, "").Code)
+}
+
+func TestShouldResolveLocationsWithGraphQL(t *testing.T) {
+	query := setupGraphQL()
+
+	w := query(`mutation { createLocation(input: {name: "HQ", kind: SITE}) { id } }`)
+	created := struct {
+		Data struct {
+			CreateLocation struct{ ID string }
+		}
+	}{}
+	json.Unmarshal(w.Body.Bytes(), &created)
+	siteID := created.Data.CreateLocation.ID
+
+	w = query(`mutation { createLocation(input: {name: "Room 101", kind: ROOM, parentId: "` + siteID + `"}) { id kind parent { name } } }`)
+	assert.Contains(t, w.Body.String(), `"kind":"ROOM","parent":{"name":"HQ"}`)
+	json.Unmarshal(w.Body.Bytes(), &created)
+	roomID := created.Data.CreateLocation.ID
+
+	query(`mutation { createDevice(input: {name: "printer", deviceBrand: "HP", locationId: "` + roomID + `"}) { id } }`)
+	w = query(`mutation { createDevice(input: {name: "pixel", deviceBrand: "Google"}) { id } }`)
+	device := struct {
+		Data struct {
+			CreateDevice struct{ ID string }
+		}
+	}{}
+	json.Unmarshal(w.Body.Bytes(), &device)
+	w = query(`mutation { moveDevice(id: "` + device.Data.CreateDevice.ID + `", locationId: "` + siteID + `", reason: "desk") {
+		location { name }
+		locationHistory { from to reason }
+	} }`)
+	assert.Contains(t, w.Body.String(), `"location":{"name":"HQ"},"locationHistory":[{"from":null,"to":"`+siteID+`","reason":"desk"}]`)
+
+	w = query(`{ locationByPath(path: "HQ/Room 101") {
+		pathName
+		path { name }
+		devices { name }
+	} }`)
+	assert.Contains(t, w.Body.String(), `"pathName":"HQ/Room 101","path":[{"name":"HQ"},{"name":"Room 101"}],"devices":[{"name":"printer"}]`)
+
+	w = query(`{ locations { name children { name } devices { name } direct: devices(recursive: false) { name } } }`)
+	assert.Contains(t, w.Body.String(), `"children":[{"name":"Room 101"}],"devices":[{"name":"printer"},{"name":"pixel"}],"direct":[{"name":"pixel"}]`)
+
+	w = query(`mutation { deleteLocation(id: "` + siteID + `") }`)
+	assert.Contains(t, w.Body.String(), `"code":"LOCATION_NOT_EMPTY"`)
+	w = query(`mutation { moveLocation(id: "missing") { id } }`)
+	assert.Contains(t, w.Body.String(), `"code":"NOT_FOUND"`)
+}
@@ -0,0 +1,103 @@
+package tests
+
+import (
+	"devices_crud/internal/devices/model"
+	"encoding/json"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShouldManageMaintenanceWithRest(t *testing.T) {
+	router := setupRouter()
+
+	created, _ := addTwoDevices(router)
+	path := "/v1/devices/" + created.UUID
+	startsAt := time.Now().AddDate(0, 0, -3).UTC().Format(time.RFC3339)
+
+	w := serve(router, "POST", path+"/maintenance-plans", `{"name":"clean","kind":"hourly","every":1,"unit":"month"}`)
+	assert.Equal(t, 400, w.Code)
+	assert.Contains(t, w.Body.String(), `"code":"INVALID_MAINTENANCE_PLAN"`)
+	assert.Equal(t, 404, serve(router, "POST", "/v1/devices/missing/maintenance-plans", `{"name":"clean","kind":"interval","every":1,"unit":"month"}`).Code)
+
+	plan := model.MaintenancePlan{}
+	w = serve(router, "POST", path+"/maintenance-plans", `{"name":"clean","kind":"interval","every":1,"unit":"month","startsAt":"`+startsAt+`"}`)
+	assert.Equal(t, 201, w.Code)
+	json.Unmarshal(w.Body.Bytes(), &plan)
+
+	tasks := []model.MaintenanceTask{}
+	w = serve(router, "GET", "/v1/maintenance/overdue", "")
+	json.Unmarshal(w.Body.Bytes(), &tasks)
+	if assert.Equal(t, 1, len(tasks)) {
+		assert.Equal(t, plan.ID, tasks[0].PlanID)
+		assert.True(t, tasks[0].Overdue)
+	}
+	w = serve(router, "GET", "/v1/maintenance/overdue?at="+url.QueryEscape(time.Now().AddDate(0, 0, -5).Format(time.RFC3339)), "")
+	json.Unmarshal(w.Body.Bytes(), &tasks)
+	assert.Empty(t, tasks)
+	assert.Equal(t, 400, serve(router, "GET", "/v1/maintenance/overdue?at=yesterday", "").Code)
+	assert.Equal(t, 400, serve(router, "GET", "/v1/maintenance/upcoming?days=1000", "").Code)
+
+	w = serve(router, "POST", path+"/service-records", `{"cost":-5}`)
+	assert.Equal(t, 400, w.Code)
+	assert.Contains(t, w.Body.String(), `"code":"INVALID_SERVICE_RECORD"`)
+	w = serve(router, "POST", path+"/service-records", `{"planId":"`+plan.ID+`","cost":40,"currency":"EUR","notes":"filters replaced"}`)
+	assert.Equal(t, 201, w.Code)
+
+	w = serve(router, "GET", path+"/maintenance?days=60", "")
+	json.Unmarshal(w.Body.Bytes(), &tasks)
+	if assert.Equal(t, 1, len(tasks)) {
+		assert.False(t, tasks[0].Overdue)
+	}
+	w = serve(router, "GET", "/v1/maintenance/upcoming?days=60", "")
+	json.Unmarshal(w.Body.Bytes(), &tasks)
+	assert.Equal(t, 1, len(tasks))
+
+	records := []model.ServiceRecord{}
+	w = serve(router, "GET", path+"/service-records", "")
+	json.Unmarshal(w.Body.Bytes(), &records)
+	if assert.Equal(t, 1, len(records)) {
+		assert.Equal(t, 40.0, records[0].Cost)
+		assert.Equal(t, "filters replaced", records[0].Notes)
+	}
+
+	assert.Equal(t, 204, serve(router, "DELETE", path+"/maintenance-plans/"+plan.ID, "").Code)
+	assert.Equal(t, 404, serve(router, "DELETE", path+"/maintenance-plans/"+plan.ID, "").Code)
+	assert.Equal(t, "[]", serve(router, "GET", path+"/maintenance-plans", "").Body.String())
+}
+
+func TestShouldResolveMaintenanceWithGraphQL(t *testing.T) {
+	query := setupGraphQL()
+
+	w := query(`mutation { createDevice(input: {name: "printer", deviceBrand: "HP", status: IN_SERVICE}) { id } }`)
+	created := struct {
+		Data struct {
+			CreateDevice struct{ ID string }
+		}
+	}{}
+	json.Unmarshal(w.Body.Bytes(), &created)
+	id := created.Data.CreateDevice.ID
+	w = query(`mutation { transitionDevice(id: "` + id + `", status: IN_REPAIR, reason: "jammed") { inRepair } }`)
+	assert.Contains(t, w.Body.String(), `"inRepair":true`)
+
+	startsAt := time.Now().AddDate(0, 0, -1).UTC().Format(time.RFC3339)
+	w = query(`mutation { createMaintenancePlan(input: {deviceId: "` + id + `", name: "toner", kind: CALENDAR, every: 1, unit: WEEK, startsAt: "` + startsAt + `"}) { id kind unit nextDueAt } }`)
+	assert.Contains(t, w.Body.String(), `"kind":"CALENDAR","unit":"WEEK"`)
+	w = query(`mutation { createMaintenancePlan(input: {deviceId: "` + id + `", name: "toner", kind: CALENDAR, every: 0, unit: WEEK}) { id } }`)
+	assert.Contains(t, w.Body.String(), `"code":"INVALID_MAINTENANCE_PLAN"`)
+
+	w = query(`{ overdueMaintenance { planName overdue deviceInRepair device { name } } }`)
+	assert.Contains(t, w.Body.String(), `"overdueMaintenance":[{"planName":"toner","overdue":true,"deviceInRepair":true,"device":{"name":"printer"}}]`)
+
+	w = query(`mutation { recordService(input: {deviceId: "` + id + `", kind: REPAIR, cost: 80, notes: "new drum"}) { kind cost notes planId } }`)
+	assert.Contains(t, w.Body.String(), `"kind":"REPAIR","cost":80,"notes":"new drum","planId":null`)
+	w = query(`mutation { recordService(input: {deviceId: "` + id + `", cost: -1}) { id } }`)
+	assert.Contains(t, w.Body.String(), `"code":"INVALID_SERVICE_RECORD"`)
+
+	w = query(`{ device(id: "` + id + `") { maintenancePlans { name } serviceRecords { kind } maintenanceTasks(days: 10) { overdue } } }`)
+	assert.Contains(t, w.Body.String(), `"maintenancePlans":[{"name":"toner"}],"serviceRecords":[{"kind":"REPAIR"}],"maintenanceTasks":[{"overdue":true},{"overdue":false}]`)
+	w = query(`{ upcomingMaintenance(days: 400) { dueAt } }`)
+	assert.Contains(t, w.Body.String(), `"code":"INVALID_MAINTENANCE_PLAN"`)
+}
@@ -0,0 +1,100 @@
+package tests
+
+import (
+	"devices_crud/internal/devices/model"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShouldManageRelationshipsWithRest(t *testing.T) {
+	router := setupRouter()
+
+	chassis, blade := addTwoDevices(router)
+	path := "/v1/devices/" + chassis.UUID
+
+	w := serve(router, "POST", path+"/relationships", `{"type":"powers","targetId":"`+blade.UUID+`"}`)
+	assert.Equal(t, 400, w.Code)
+	assert.Contains(t, w.Body.String(), `"code":"INVALID_RELATIONSHIP"`)
+	assert.Equal(t, 404, serve(router, "POST", "/v1/devices/missing/relationships", `{"type":"contains","targetId":"`+blade.UUID+`"}`).Code)
+
+	relationship := model.Relationship{}
+	w = serve(router, "POST", path+"/relationships", `{"type":"contains","targetId":"`+blade.UUID+`"}`)
+	assert.Equal(t, 201, w.Code)
+	json.Unmarshal(w.Body.Bytes(), &relationship)
+	assert.Equal(t, model.RelationContains, relationship.Type)
+
+	w = serve(router, "POST", "/v1/devices/"+blade.UUID+"/relationships", `{"type":"contains","targetId":"`+chassis.UUID+`"}`)
+	assert.Equal(t, 409, w.Code)
+	assert.Contains(t, w.Body.String(), `"code":"RELATIONSHIP_CYCLE"`)
+	w = serve(router, "POST", path+"/relationships", `{"type":"contains","targetId":"`+blade.UUID+`"}`)
+	assert.Equal(t, 409, w.Code)
+	assert.Contains(t, w.Body.String(), `"code":"RELATIONSHIP_CONFLICT"`)
+
+	w = serve(router, "GET", "/v1/devices/"+blade.UUID+"/parent", "")
+	assert.Contains(t, w.Body.String(), chassis.UUID)
+	assert.Equal(t, 404, serve(router, "GET", path+"/parent", "").Code)
+	devices := []model.Device{}
+	w = serve(router, "GET", path+"/children", "")
+	json.Unmarshal(w.Body.Bytes(), &devices)
+	if assert.Equal(t, 1, len(devices)) {
+		assert.Equal(t, blade.UUID, devices[0].ID)
+	}
+
+	graph := model.DeviceGraph{}
+	w = serve(router, "GET", path+"/graph?depth=2&type=contains,connected_to", "")
+	json.Unmarshal(w.Body.Bytes(), &graph)
+	assert.Equal(t, 2, len(graph.Nodes), w.Body.String())
+	assert.Equal(t, 1, len(graph.Edges))
+	assert.Equal(t, 400, serve(router, "GET", path+"/graph?depth=two", "").Code)
+	assert.Equal(t, 400, serve(router, "GET", path+"/graph?depth=11", "").Code)
+
+	w = serve(router, "DELETE", path, "")
+	assert.Equal(t, 409, w.Code)
+	assert.Contains(t, w.Body.String(), `"code":"DEVICE_HAS_CHILDREN"`)
+
+	assert.Equal(t, 204, serve(router, "DELETE", path+"/relationships/"+relationship.ID, "").Code)
+	assert.Equal(t, 404, serve(router, "DELETE", path+"/relationships/"+relationship.ID, "").Code)
+	serve(router, "POST", path+"/relationships", `{"type":"contains","targetId":"`+blade.UUID+`"}`)
+
+	assert.Equal(t, 204, serve(router, "DELETE", path+"?cascade=true", "").Code)
+	assert.Equal(t, 404, serve(router, "GET", "/v1/devices/"+blade.UUID, "").Code)
+}
+
+func TestShouldResolveRelationshipsWithGraphQL(t *testing.T) {
+	query := setupGraphQL()
+
+	ids := []string{}
+	for _, name := range []string{"rack", "server", "switch"} {
+		w := query(`mutation { createDevice(input: {name: "` + name + `", deviceBrand: "Acme"}) { id } }`)
+		created := struct {
+			Data struct {
+				CreateDevice struct{ ID string }
+			}
+		}{}
+		json.Unmarshal(w.Body.Bytes(), &created)
+		ids = append(ids, created.Data.CreateDevice.ID)
+	}
+	rack, server, networkSwitch := ids[0], ids[1], ids[2]
+
+	w := query(`mutation { linkDevices(id: "` + rack + `", type: CONTAINS, targetId: "` + server + `") { type from { name } to { name } } }`)
+	assert.Contains(t, w.Body.String(), `"type":"CONTAINS","from":{"name":"rack"},"to":{"name":"server"}`)
+	query(`mutation { linkDevices(id: "` + server + `", type: CONNECTED_TO, targetId: "` + networkSwitch + `") { id } }`)
+	w = query(`mutation { linkDevices(id: "` + server + `", type: CONTAINS, targetId: "` + rack + `") { id } }`)
+	assert.Contains(t, w.Body.String(), `"code":"RELATIONSHIP_CYCLE"`)
+
+	w = query(`{ device(id: "` + server + `") { parent { name } children { name } relationships { type } related(type: CONNECTED_TO) { name } } }`)
+	assert.Contains(t, w.Body.String(), `"parent":{"name":"rack"},"children":[],"relationships":[{"type":"CONTAINS"},{"type":"CONNECTED_TO"}],"related":[{"name":"switch"}]`)
+
+	w = query(`{ deviceGraph(id: "` + rack + `") { nodes { device { name } depth } edges { type } } }`)
+	assert.Contains(t, w.Body.String(), `{"device":{"name":"switch"},"depth":2}`)
+	assert.Contains(t, w.Body.String(), `"edges":[{"type":"CONTAINS"},{"type":"CONNECTED_TO"}]`)
+
+	w = query(`mutation { deleteDevice(id: "` + rack + `") }`)
+	assert.Contains(t, w.Body.String(), `"code":"DEVICE_HAS_CHILDREN"`)
+	w = query(`mutation { deleteDevice(id: "` + rack + `", cascade: true) }`)
+	assert.Contains(t, w.Body.String(), `"deleteDevice":true`)
+	w = query(`{ device(id: "` + networkSwitch + `") { relationships { type } } }`)
+	assert.Contains(t, w.Body.String(), `"relationships":[]`)
+}
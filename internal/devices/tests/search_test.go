@@ -0,0 +1,59 @@
+package tests
+
+import (
+	"devices_crud/internal/devices/model"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShouldKeepIndexInSyncWithRepositoryWrites(t *testing.T) {
+	router := setupRouter()
+	printer := createDevice(router, "lab-printer", "Brother")
+	createDevice(router, "MacBook Pro", "Apple")
+
+	w := serve(router, "GET", "/v1/devices/search?q=printr", "")
+	results := []model.SearchResult{}
+	_ = json.Unmarshal(w.Body.Bytes(), &results)
+	assert.Equal(t, 200, w.Code)
+	if assert.Equal(t, 1, len(results)) {
+		assert.Equal(t, printer, results[0].ID)
+		assert.Greater(t, results[0].Score, 0.0)
+	}
+
+	serve(router, "PATCH", "/v1/devices/"+printer, `{"name":"front-desk-scanner"}`)
+	w = serve(router, "GET", "/v1/devices/search?q=scanner", "")
+	_ = json.Unmarshal(w.Body.Bytes(), &results)
+	assert.Equal(t, 1, len(results))
+	w = serve(router, "GET", "/v1/devices/search?q=printer", "")
+	_ = json.Unmarshal(w.Body.Bytes(), &results)
+	assert.Equal(t, 0, len(results))
+
+	serve(router, "DELETE", "/v1/devices/"+printer, "")
+	w = serve(router, "GET", "/v1/devices/search?q=scanner", "")
+	_ = json.Unmarshal(w.Body.Bytes(), &results)
+	assert.Equal(t, 0, len(results))
+}
+
+func TestShouldSearchDevicesWithGraphQL(t *testing.T) {
+	router := setupRouter()
+	macbook := createDevice(router, "MacBook Pro", "Apple")
+	createDevice(router, "apple tv remote", "Logitech")
+
+	w := serve(router, "POST", "/query", `{"query":"{ searchDevices(query: \"macbook appl\") { score device { id name } } }"}`)
+	response := struct {
+		Data struct {
+			SearchDevices []struct {
+				Score  float64
+				Device struct{ ID, Name string }
+			}
+		}
+	}{}
+	_ = json.Unmarshal(w.Body.Bytes(), &response)
+
+	if assert.Equal(t, 1, len(response.Data.SearchDevices), w.Body.String()) {
+		assert.Equal(t, macbook, response.Data.SearchDevices[0].Device.ID)
+		assert.Greater(t, response.Data.SearchDevices[0].Score, 0.0)
+	}
+}
@@ -0,0 +1,107 @@
+package tests
+
+import (
+	"devices_crud/internal/devices/model"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShouldCheckComplianceWithRest(t *testing.T) {
+	router := setupRouter()
+
+	outdated, current := addTwoDevices(router)
+	path := "/v1/devices/" + outdated.UUID
+
+	w := serve(router, "PUT", path+"/software", `{"kind":"firmware","name":"core","version":"latest"}`)
+	assert.Equal(t, 400, w.Code)
+	assert.Contains(t, w.Body.String(), `"code":"INVALID_SOFTWARE"`)
+	assert.Equal(t, 404, serve(router, "PUT", "/v1/devices/missing/software", `{"kind":"firmware","name":"core","version":"1.0.0"}`).Code)
+
+	software := model.InstalledSoftware{}
+	w = serve(router, "PUT", path+"/software", `{"kind":"firmware","name":"core","version":"2.3.0"}`)
+	assert.Equal(t, 200, w.Code)
+	json.Unmarshal(w.Body.Bytes(), &software)
+	assert.Equal(t, "2.3.0", software.Version)
+	serve(router, "PUT", "/v1/devices/"+current.UUID+"/software", `{"kind":"firmware","name":"core","version":"2.4.0"}`)
+
+	installed := []model.InstalledSoftware{}
+	json.Unmarshal(serve(router, "GET", path+"/software", "").Body.Bytes(), &installed)
+	assert.Equal(t, 1, len(installed))
+	assert.Equal(t, 404, serve(router, "GET", "/v1/devices/missing/software", "").Code)
+
+	w = serve(router, "POST", "/v1/compliance/policies", `{"minVersion":"two"}`)
+	assert.Equal(t, 400, w.Code)
+	assert.Contains(t, w.Body.String(), `"code":"INVALID_SOFTWARE_POLICY"`)
+
+	policy := model.SoftwarePolicy{}
+	w = serve(router, "POST", "/v1/compliance/policies", `{"brand":"brand_1","software":"core","minVersion":"2.3.1"}`)
+	assert.Equal(t, 201, w.Code)
+	json.Unmarshal(w.Body.Bytes(), &policy)
+	assert.Equal(t, model.SoftwareFirmware, policy.Kind)
+	policies := []model.SoftwarePolicy{}
+	json.Unmarshal(serve(router, "GET", "/v1/compliance/policies", "").Body.Bytes(), &policies)
+	assert.Equal(t, 1, len(policies))
+
+	report := model.ComplianceReport{}
+	w = serve(router, "GET", "/v1/compliance?policy="+policy.ID, "")
+	assert.Equal(t, 200, w.Code)
+	json.Unmarshal(w.Body.Bytes(), &report)
+	if assert.Equal(t, 1, len(report.Violators)) {
+		assert.Equal(t, outdated.UUID, report.Violators[0].Device.ID)
+		assert.Equal(t, model.ViolationOutdated, report.Violators[0].Violations[0].Reason)
+		assert.Equal(t, "2.3.0", report.Violators[0].Violations[0].Installed)
+	}
+	assert.Equal(t, 404, serve(router, "GET", "/v1/compliance?policy=missing", "").Code)
+
+	compliance := model.DeviceCompliance{}
+	json.Unmarshal(serve(router, "GET", "/v1/devices/"+current.UUID+"/compliance", "").Body.Bytes(), &compliance)
+	assert.True(t, compliance.Compliant)
+	assert.Equal(t, 404, serve(router, "GET", "/v1/devices/missing/compliance", "").Code)
+
+	serve(router, "PUT", path+"/software", `{"kind":"firmware","name":"core","version":"2.3.1"}`)
+	json.Unmarshal(serve(router, "GET", path+"/compliance", "").Body.Bytes(), &compliance)
+	assert.True(t, compliance.Compliant)
+
+	assert.Equal(t, 204, serve(router, "DELETE", path+"/software/"+software.ID, "").Code)
+	assert.Equal(t, 404, serve(router, "DELETE", path+"/software/"+software.ID, "").Code)
+	assert.Equal(t, 204, serve(router, "DELETE", "/v1/compliance/policies/"+policy.ID, "").Code)
+	assert.Equal(t, 404, serve(router, "DELETE", "/v1/compliance/policies/"+policy.ID, "").Code)
+}
+
+func TestShouldResolveComplianceWithGraphQL(t *testing.T) {
+	query := setupGraphQL()
+
+	w := query(`mutation { createDevice(input: {name: "camera", deviceBrand: "Axis"}) { id compliance { compliant policies } } }`)
+	assert.Contains(t, w.Body.String(), `"compliance":{"compliant":true,"policies":0}`)
+	created := struct {
+		Data struct {
+			CreateDevice struct{ ID string }
+		}
+	}{}
+	json.Unmarshal(w.Body.Bytes(), &created)
+	id := created.Data.CreateDevice.ID
+
+	w = query(`mutation { recordSoftware(input: {deviceId: "` + id + `", kind: FIRMWARE, name: "core", version: "1.9.9"}) { kind name version } }`)
+	assert.Contains(t, w.Body.String(), `"recordSoftware":{"kind":"FIRMWARE","name":"core","version":"1.9.9"}`)
+	w = query(`mutation { recordSoftware(input: {deviceId: "` + id + `", kind: OS, name: "linux", version: "6"}) { name } }`)
+	assert.NotContains(t, w.Body.String(), `"errors"`)
+	w = query(`mutation { recordSoftware(input: {deviceId: "` + id + `", kind: FIRMWARE, name: "core", version: "next"}) { name } }`)
+	assert.Contains(t, w.Body.String(), `"code":"INVALID_SOFTWARE"`)
+
+	w = query(`mutation { createSoftwarePolicy(input: {brand: "axis", minVersion: "2.0.0"}) { id brand kind minVersion } }`)
+	assert.Contains(t, w.Body.String(), `"brand":"axis","kind":"FIRMWARE","minVersion":"2.0.0"`)
+	w = query(`mutation { createSoftwarePolicy(input: {kind: APPLICATION, software: "agent", type: "camera", minVersion: "1.0"}) { id } }`)
+	assert.NotContains(t, w.Body.String(), `"errors"`)
+
+	w = query(`{ device(id: "` + id + `") { software { kind name } compliance { compliant violations { reason software installed required } } } }`)
+	assert.Contains(t, w.Body.String(), `"software":[{"kind":"FIRMWARE","name":"core"},{"kind":"OS","name":"linux"}]`)
+	assert.Contains(t, w.Body.String(), `"compliance":{"compliant":false,"violations":[{"reason":"OUTDATED","software":"core","installed":"1.9.9","required":"2.0.0"}]}`)
+
+	w = query(`{ complianceReport { policies violators { device { name } } } softwarePolicies { minVersion } }`)
+	assert.Contains(t, w.Body.String(), `"violators":[{"device":{"name":"camera"}}]`)
+	assert.Contains(t, w.Body.String(), `"softwarePolicies":[{"minVersion":"2.0.0"},{"minVersion":"1.0"}]`)
+	w = query(`{ complianceReport(policyId: "missing") { policies } }`)
+	assert.Contains(t, w.Body.String(), `"code":"NOT_FOUND"`)
+}
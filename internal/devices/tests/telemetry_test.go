@@ -0,0 +1,95 @@
+package tests
+
+import (
+	"bytes"
+	"devices_crud/internal/devices"
+	"devices_crud/internal/devices/model"
+	"devices_crud/internal/drivers/graph"
+	"devices_crud/internal/drivers/rest"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShouldIngestAndQueryReadingsWithRest(t *testing.T) {
+	router := setupRouter()
+
+	created, _ := addTwoDevices(router)
+	path := "/v1/devices/" + created.UUID
+	at := time.Now().Add(-10 * time.Minute).UTC().Format(time.RFC3339)
+
+	w := serve(router, "POST", path+"/telemetry", `{"readings":[{"metric":"battery level","value":1}]}`)
+	assert.Equal(t, 400, w.Code)
+	assert.Contains(t, w.Body.String(), `"code":"INVALID_READING"`)
+	assert.Equal(t, 404, serve(router, "POST", "/v1/devices/missing/telemetry", `{"readings":[{"metric":"battery","value":1}]}`).Code)
+
+	w = serve(router, "POST", path+"/telemetry", `{"readings":[{"metric":"battery","value":90,"at":"`+at+`"},{"metric":"battery","value":70},{"metric":"temperature","value":40}]}`)
+	assert.Equal(t, 202, w.Code)
+	assert.Equal(t, `{"accepted":3}`, w.Body.String())
+
+	readings := []model.Reading{}
+	w = serve(router, "GET", path+"/telemetry", "")
+	json.Unmarshal(w.Body.Bytes(), &readings)
+	if assert.Equal(t, 2, len(readings)) {
+		assert.Equal(t, "battery", readings[0].Metric)
+		assert.Equal(t, 70.0, readings[0].Value)
+	}
+	assert.Equal(t, 404, serve(router, "GET", "/v1/devices/missing/telemetry", "").Code)
+
+	w = serve(router, "GET", path+"/telemetry/battery", "")
+	json.Unmarshal(w.Body.Bytes(), &readings)
+	assert.Equal(t, 2, len(readings))
+	w = serve(router, "GET", path+"/telemetry/battery?to="+url.QueryEscape(at), "")
+	assert.Equal(t, "[]", w.Body.String())
+	assert.Equal(t, 400, serve(router, "GET", path+"/telemetry/battery?from=yesterday", "").Code)
+
+	aggregates := []model.ReadingAggregate{}
+	w = serve(router, "GET", path+"/telemetry/battery/aggregate", "")
+	json.Unmarshal(w.Body.Bytes(), &aggregates)
+	if assert.Equal(t, 1, len(aggregates)) {
+		assert.Equal(t, 2, aggregates[0].Count)
+		assert.Equal(t, 80.0, aggregates[0].Mean)
+		assert.Equal(t, 70.0, aggregates[0].Last)
+	}
+	w = serve(router, "GET", path+"/telemetry/battery/aggregate?step=1s", "")
+	assert.Equal(t, 400, w.Code)
+	assert.Contains(t, w.Body.String(), `"code":"INVALID_READING"`)
+	assert.Equal(t, 400, serve(router, "GET", path+"/telemetry/battery/aggregate?step=hourly", "").Code)
+}
+
+func TestShouldResolveLatestReadingWithGraphQL(t *testing.T) {
+	// Readings are ingested over REST, both drivers need the same store.
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	deps := devices.NewDevicesDependencies(&devices.DeviceDependencies{UseMocks: true, Logger: slog.New(slog.NewTextHandler(os.Stdout, nil))})
+	rest.BuildRoutes(router, deps)
+	graph.BuildRoutes(router, deps)
+	query := func(query string) string {
+		body, _ := json.Marshal(map[string]interface{}{"query": query})
+		httpReq, _ := http.NewRequest("POST", "/query", bytes.NewReader(body))
+		httpReq.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httpReq)
+		return w.Body.String()
+	}
+
+	created, _ := addTwoDevices(router)
+	assert.Contains(t, query(`{ device(id: "`+created.UUID+`") { latestReading { value } latestReadings { metric } } }`), `"latestReading":null,"latestReadings":[]`)
+
+	at := time.Now().Add(-time.Minute).UTC().Truncate(time.Second)
+	serve(router, "POST", "/v1/devices/"+created.UUID+"/telemetry", `{"readings":[{"metric":"temperature","value":40},{"metric":"battery","value":75,"at":"`+at.Format(time.RFC3339)+`"}]}`)
+
+	body := query(`{ device(id: "` + created.UUID + `") { latest: latestReading { metric value } battery: latestReading(metric: "battery") { value at } uptime: latestReading(metric: "uptime") { value } latestReadings { metric } } }`)
+	assert.Contains(t, body, `"latest":{"metric":"temperature","value":40}`)
+	assert.Contains(t, body, `"battery":{"value":75,"at":"`+at.String()+`"}`)
+	assert.Contains(t, body, `"uptime":null`)
+	assert.Contains(t, body, `"latestReadings":[{"metric":"battery"},{"metric":"temperature"}]`)
+}
@@ -0,0 +1,61 @@
+package graph
+
+import (
+	"context"
+	"devices_crud/internal/auth"
+	"devices_crud/internal/logging"
+	"devices_crud/internal/tenancy"
+	"log/slog"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// authenticateOperations is the GraphQL counterpart of the REST authentication
+// middleware: every operation must carry valid credentials in its HTTP headers.
+func authenticateOperations(authenticator auth.Authenticator, logger *slog.Logger) graphql.OperationMiddleware {
+	return func(ctx context.Context, next graphql.OperationHandler) graphql.ResponseHandler {
+		principal, err := authenticator.Authenticate(ctx, graphql.GetOperationContext(ctx).Headers)
+		if err != nil {
+			logger.InfoContext(ctx, "Authentication failed", "error", err)
+			return graphql.OneShot(&graphql.Response{
+				Errors: gqlerror.List{{
+					Message:    "unauthenticated",
+					Extensions: map[string]interface{}{"code": "UNAUTHENTICATED"},
+				}},
+			})
+		}
+
+		ctx = auth.WithPrincipal(ctx, principal)
+		ctx = logging.WithAttrs(ctx, slog.String(logging.PrincipalKey, principal.Method+":"+principal.Subject))
+		return next(ctx)
+	}
+}
+
+// resolveOperationTenant scopes every operation to a tenant, see tenancy.Resolve.
+func resolveOperationTenant(authorizer *auth.Authorizer, logger *slog.Logger) graphql.OperationMiddleware {
+	return func(ctx context.Context, next graphql.OperationHandler) graphql.ResponseHandler {
+		header := graphql.GetOperationContext(ctx).Headers.Get(tenancy.Header)
+		tenantCtx, err := tenancy.Resolve(ctx, header, authorizer)
+		if err != nil {
+			logger.WarnContext(ctx, "Tenant rejected", "error", err)
+			return graphql.OneShot(&graphql.Response{
+				Errors: gqlerror.List{{
+					Message:    "forbidden",
+					Extensions: map[string]interface{}{"code": "FORBIDDEN"},
+				}},
+			})
+		}
+
+		tenantCtx = logging.WithAttrs(tenantCtx, slog.String(logging.TenantKey, tenancy.TenantFromContext(tenantCtx)))
+		return next(tenantCtx)
+	}
+}
+
+// logOperations adds the operation name to the log records of the operation.
+func logOperations() graphql.OperationMiddleware {
+	return func(ctx context.Context, next graphql.OperationHandler) graphql.ResponseHandler {
+		name := operationName(graphql.GetOperationContext(ctx))
+		return next(logging.WithAttrs(ctx, slog.String(logging.OperationKey, name)))
+	}
+}
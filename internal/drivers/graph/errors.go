@@ -0,0 +1,64 @@
+package graph
+
+import (
+	"context"
+	"devices_crud/internal/apierrors"
+	"devices_crud/internal/auth"
+	"devices_crud/internal/filter"
+	"devices_crud/internal/jsonschema"
+	"devices_crud/internal/labels"
+	"errors"
+	"log/slog"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// hasPermission implements the @hasPermission schema directive. Without an
+// authorizer the directive lets every request through, like the device service.
+func hasPermission(authorizer *auth.Authorizer) func(ctx context.Context, obj interface{}, next graphql.Resolver, permission string) (interface{}, error) {
+	return func(ctx context.Context, obj interface{}, next graphql.Resolver, permission string) (interface{}, error) {
+		if authorizer != nil {
+			if err := authorizer.Authorize(ctx, auth.Permission(permission)); err != nil {
+				return nil, err
+			}
+		}
+		return next(ctx)
+	}
+}
+
+// presentError adds the error code of apierrors.Codes to domain errors, like
+// the REST driver.
+func presentError(logger *slog.Logger) graphql.ErrorPresenterFunc {
+	return func(ctx context.Context, err error) *gqlerror.Error {
+		presented := graphql.DefaultErrorPresenter(ctx, err)
+
+		code, ok := apierrors.Lookup(err)
+		if !ok {
+			logger.ErrorContext(ctx, "Error resolving field", "path", presented.Path.String(), "error", err)
+			return presented
+		}
+
+		logger.Log(ctx, code.Level, "Error resolving field", "path", presented.Path.String(), "code", code.Code, "error", err)
+		if code.Message != "" {
+			presented.Message = code.Message
+		}
+		if presented.Extensions == nil {
+			presented.Extensions = map[string]interface{}{}
+		}
+		presented.Extensions["code"] = code.Code
+		var syntaxErr *filter.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			presented.Extensions["position"] = syntaxErr.Position
+		}
+		var selectorErr *labels.SyntaxError
+		if errors.As(err, &selectorErr) {
+			presented.Extensions["position"] = selectorErr.Position
+		}
+		var violations jsonschema.Errors
+		if errors.As(err, &violations) {
+			presented.Extensions["errors"] = violations
+		}
+		return presented
+	}
+}
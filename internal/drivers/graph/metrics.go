@@ -0,0 +1,28 @@
+package graph
+
+import (
+	"context"
+	"devices_crud/internal/metrics"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+)
+
+// observeOperations records the count, latency and errors of operations by
+// operation name, see operationName. It is registered first so that
+// operations rejected by the other middlewares are recorded as well.
+func observeOperations(m *metrics.Metrics) graphql.OperationMiddleware {
+	return func(ctx context.Context, next graphql.OperationHandler) graphql.ResponseHandler {
+		start := time.Now()
+		name := operationName(graphql.GetOperationContext(ctx))
+		handler := next(ctx)
+
+		return func(ctx context.Context) *graphql.Response {
+			response := handler(ctx)
+			if response != nil {
+				m.ObserveGraphQLOperation(name, len(response.Errors), time.Since(start))
+			}
+			return response
+		}
+	}
+}
@@ -2,25 +2,852 @@
 
 package model
 
+import (
+	"fmt"
+	"io"
+	"strconv"
+)
+
+type Assignment struct {
+	ID           string  `json:"id"`
+	DeviceID     string  `json:"deviceId"`
+	Device       *Device `json:"device,omitempty"`
+	Assignee     string  `json:"assignee"`
+	CheckedOutAt string  `json:"checkedOutAt"`
+	DueAt        *string `json:"dueAt,omitempty"`
+	CheckedInAt  *string `json:"checkedInAt,omitempty"`
+	Note         *string `json:"note,omitempty"`
+	CheckedOutBy *string `json:"checkedOutBy,omitempty"`
+	Overdue      bool    `json:"overdue"`
+}
+
+type Brand struct {
+	Name      string         `json:"name"`
+	Aliases   []string       `json:"aliases"`
+	Models    []*DeviceModel `json:"models"`
+	CreatedAt string         `json:"createdAt"`
+}
+
+type BrandChange struct {
+	DeviceID string `json:"deviceId"`
+	From     string `json:"from"`
+	To       string `json:"to"`
+}
+
+type BrandInput struct {
+	Name    string              `json:"name"`
+	Aliases []string            `json:"aliases,omitempty"`
+	Models  []*DeviceModelInput `json:"models,omitempty"`
+}
+
+type BrandMigration struct {
+	DryRun  bool           `json:"dryRun"`
+	Changes []*BrandChange `json:"changes"`
+	// Brands added to the catalog for spellings it did not know.
+	Added []*Brand `json:"added"`
+}
+
+type ComplianceReport struct {
+	CheckedAt string `json:"checkedAt"`
+	Policies  int    `json:"policies"`
+	// The number of devices at least one policy applies to.
+	Devices   int                 `json:"devices"`
+	Compliant int                 `json:"compliant"`
+	Violators []*DeviceCompliance `json:"violators"`
+}
+
+type Contract struct {
+	ID        string       `json:"id"`
+	DeviceID  string       `json:"deviceId"`
+	Device    *Device      `json:"device,omitempty"`
+	Kind      ContractKind `json:"kind"`
+	Provider  string       `json:"provider"`
+	Reference *string      `json:"reference,omitempty"`
+	StartsAt  string       `json:"startsAt"`
+	EndsAt    string       `json:"endsAt"`
+	// What the contract covers, e.g. parts, labor or onsite.
+	Coverage []string `json:"coverage"`
+	Active   bool     `json:"active"`
+	// Whole days until the contract ends, negative once it ended.
+	DaysLeft  int     `json:"daysLeft"`
+	CreatedAt string  `json:"createdAt"`
+	CreatedBy *string `json:"createdBy,omitempty"`
+}
+
 type Device struct {
-	ID          string `json:"id"`
-	Name        string `json:"name"`
-	DeviceBrand string `json:"DeviceBrand"`
-	CreatedAt   string `json:"CreatedAt"`
+	ID          string      `json:"id"`
+	Name        string      `json:"name"`
+	DeviceBrand string      `json:"DeviceBrand"`
+	CreatedAt   string      `json:"CreatedAt"`
+	TenantID    string      `json:"tenantId"`
+	Type        *string     `json:"type,omitempty"`
+	Attributes  interface{} `json:"attributes,omitempty"`
+	DeviceType  *DeviceType `json:"deviceType,omitempty"`
+	// Value of the attribute, null when the device does not have it.
+	Attribute interface{} `json:"attribute,omitempty"`
+	// Value of a string attribute, null when it is missing or not a string.
+	StringAttribute  *string  `json:"stringAttribute,omitempty"`
+	NumberAttribute  *float64 `json:"numberAttribute,omitempty"`
+	BooleanAttribute *bool    `json:"booleanAttribute,omitempty"`
+	Labels           []*Label `json:"labels"`
+	// Value of the label, null when the device does not carry it.
+	Label  *string      `json:"label,omitempty"`
+	Status DeviceStatus `json:"status"`
+	// When the device entered its current status.
+	StatusSince   *string         `json:"statusSince,omitempty"`
+	StatusHistory []*StatusChange `json:"statusHistory"`
+	// The active assignment, null when the device is available.
+	CurrentAssignment *Assignment       `json:"currentAssignment,omitempty"`
+	Assignments       []*Assignment     `json:"assignments"`
+	LocationID        *string           `json:"locationId,omitempty"`
+	Location          *Location         `json:"location,omitempty"`
+	LocationHistory   []*LocationChange `json:"locationHistory"`
+	// The catalog entry of the brand, null when the brand is not in the catalog.
+	Brand *Brand `json:"brand,omitempty"`
+	// The device containing this one, null when it is not contained in any.
+	Parent        *Device         `json:"parent,omitempty"`
+	Children      []*Device       `json:"children"`
+	Relationships []*Relationship `json:"relationships"`
+	// Devices up to depth relationships away, following only relationships of the type when given.
+	Related []*Device `json:"related"`
+	// True while the device is IN_REPAIR, its maintenance tasks are flagged until it is back.
+	InRepair         bool               `json:"inRepair"`
+	MaintenancePlans []*MaintenancePlan `json:"maintenancePlans"`
+	ServiceRecords   []*ServiceRecord   `json:"serviceRecords"`
+	// Tasks of the plans of the device due within days, overdue ones included.
+	MaintenanceTasks []*MaintenanceTask `json:"maintenanceTasks"`
+	Contracts        []*Contract        `json:"contracts"`
+	// True while a warranty of the device is active.
+	UnderWarranty bool `json:"underWarranty"`
+	// The most recent reading of the metric, or of any metric without it, null when the device reported none.
+	LatestReading *Reading `json:"latestReading,omitempty"`
+	// The most recent reading of every metric of the device, sorted by metric.
+	LatestReadings []*Reading `json:"latestReadings"`
+	// When the device last sent a heartbeat, null when it never did.
+	LastSeenAt *string `json:"lastSeenAt,omitempty"`
+	// Whether the device sent a heartbeat within its timeout.
+	Connectivity Connectivity `json:"connectivity"`
+	// The firmware, operating systems and applications the device runs, sorted by kind and name.
+	Software []*Software `json:"software"`
+	// Whether the software of the device complies with the policies which apply to it.
+	Compliance *DeviceCompliance `json:"compliance"`
+	// Devices which may be the same as this one, scoring at least threshold, 0.8 without it.
+	Duplicates []*DuplicateCandidate `json:"duplicates"`
+}
+
+type DeviceCompliance struct {
+	Device    *Device `json:"device"`
+	Compliant bool    `json:"compliant"`
+	// The number of policies applying to the device.
+	Policies   int                `json:"policies"`
+	Violations []*PolicyViolation `json:"violations"`
+}
+
+type DeviceGraph struct {
+	Nodes []*GraphNode    `json:"nodes"`
+	Edges []*Relationship `json:"edges"`
+}
+
+type DeviceModel struct {
+	Name    string   `json:"name"`
+	Aliases []string `json:"aliases"`
+}
+
+type DeviceModelInput struct {
+	Name    string   `json:"name"`
+	Aliases []string `json:"aliases,omitempty"`
+}
+
+type DeviceSearchResult struct {
+	Device *Device `json:"device"`
+	Score  float64 `json:"score"`
+}
+
+type DeviceType struct {
+	Name        string  `json:"name"`
+	Description *string `json:"description,omitempty"`
+	// JSON Schema the attributes of devices of this type must satisfy.
+	Schema    interface{} `json:"schema"`
+	CreatedAt string      `json:"createdAt"`
+}
+
+type DeviceTypeInput struct {
+	Name        string      `json:"name"`
+	Description *string     `json:"description,omitempty"`
+	Schema      interface{} `json:"schema"`
+}
+
+type DuplicateCandidate struct {
+	Device    *Device `json:"device"`
+	Duplicate *Device `json:"duplicate"`
+	// How likely the devices are the same one from 0 to 1, from the similarity of their names and brands.
+	Score float64 `json:"score"`
+	// The uniqueness key the devices share, e.g. brand+attributes.serial, if any.
+	Key *string `json:"key,omitempty"`
+}
+
+type ExpiringContract struct {
+	Contract *Contract `json:"contract"`
+	DaysLeft int       `json:"daysLeft"`
+	// True when another contract of the same kind covers the device once this one ends.
+	Renewed bool `json:"renewed"`
+}
+
+type GraphNode struct {
+	Device *Device `json:"device"`
+	// Number of relationships between this device and the device the traversal started at.
+	Depth int `json:"depth"`
+}
+
+type Label struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+type LabelInput struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+type Location struct {
+	ID       string       `json:"id"`
+	Name     string       `json:"name"`
+	Kind     LocationKind `json:"kind"`
+	ParentID *string      `json:"parentId,omitempty"`
+	Parent   *Location    `json:"parent,omitempty"`
+	Children []*Location  `json:"children"`
+	// The locations from the site down to this one.
+	Path []*Location `json:"path"`
+	// The names of path joined with slashes, e.g. HQ/Building A/Room 101.
+	PathName string `json:"pathName"`
+	// Devices in this location and, unless recursive is false, anywhere below it.
+	Devices   []*Device `json:"devices"`
+	CreatedAt string    `json:"createdAt"`
+}
+
+type LocationChange struct {
+	// ID of the previous location, null when the device was placed on creation or not placed before.
+	From *string `json:"from,omitempty"`
+	// ID of the new location, null when the device was taken out of any location.
+	To     *string `json:"to,omitempty"`
+	Reason *string `json:"reason,omitempty"`
+	At     string  `json:"at"`
+	Actor  *string `json:"actor,omitempty"`
+}
+
+type MaintenancePlan struct {
+	ID          string          `json:"id"`
+	DeviceID    string          `json:"deviceId"`
+	Name        string          `json:"name"`
+	Description *string         `json:"description,omitempty"`
+	Kind        MaintenanceKind `json:"kind"`
+	Every       int             `json:"every"`
+	Unit        RecurrenceUnit  `json:"unit"`
+	StartsAt    string          `json:"startsAt"`
+	NextDueAt   *string         `json:"nextDueAt,omitempty"`
+	CreatedAt   string          `json:"createdAt"`
+	CreatedBy   *string         `json:"createdBy,omitempty"`
+}
+
+type MaintenanceTask struct {
+	PlanID         string  `json:"planId"`
+	PlanName       string  `json:"planName"`
+	DeviceID       string  `json:"deviceId"`
+	Device         *Device `json:"device,omitempty"`
+	DueAt          string  `json:"dueAt"`
+	Overdue        bool    `json:"overdue"`
+	DeviceInRepair bool    `json:"deviceInRepair"`
 }
 
 type Mutation struct {
 }
 
+type NewContract struct {
+	DeviceID  string       `json:"deviceId"`
+	Kind      ContractKind `json:"kind"`
+	Provider  string       `json:"provider"`
+	Reference *string      `json:"reference,omitempty"`
+	// RFC 3339 timestamps of the period of the contract.
+	StartsAt string   `json:"startsAt"`
+	EndsAt   string   `json:"endsAt"`
+	Coverage []string `json:"coverage,omitempty"`
+}
+
 type NewDevice struct {
-	Name        string `json:"name"`
-	DeviceBrand string `json:"deviceBrand"`
+	Name        string      `json:"name"`
+	DeviceBrand string      `json:"deviceBrand"`
+	Type        *string     `json:"type,omitempty"`
+	Attributes  interface{} `json:"attributes,omitempty"`
+	// Defaults to ORDERED.
+	Status     *DeviceStatus `json:"status,omitempty"`
+	LocationID *string       `json:"locationId,omitempty"`
+}
+
+type NewLocation struct {
+	Name     string       `json:"name"`
+	Kind     LocationKind `json:"kind"`
+	ParentID *string      `json:"parentId,omitempty"`
+}
+
+type NewMaintenancePlan struct {
+	DeviceID    string          `json:"deviceId"`
+	Name        string          `json:"name"`
+	Description *string         `json:"description,omitempty"`
+	Kind        MaintenanceKind `json:"kind"`
+	Every       int             `json:"every"`
+	Unit        RecurrenceUnit  `json:"unit"`
+	// RFC 3339 timestamp of the first due date, one recurrence from now by default.
+	StartsAt *string `json:"startsAt,omitempty"`
+}
+
+type NewServiceRecord struct {
+	DeviceID string `json:"deviceId"`
+	// Defaults to MAINTENANCE.
+	Kind *ServiceKind `json:"kind,omitempty"`
+	// Completes the task of the plan due next.
+	PlanID *string `json:"planId,omitempty"`
+	// RFC 3339 timestamp, now by default.
+	PerformedAt *string  `json:"performedAt,omitempty"`
+	PerformedBy *string  `json:"performedBy,omitempty"`
+	Cost        *float64 `json:"cost,omitempty"`
+	Currency    *string  `json:"currency,omitempty"`
+	Notes       *string  `json:"notes,omitempty"`
+}
+
+type NewSoftware struct {
+	DeviceID string       `json:"deviceId"`
+	Kind     SoftwareKind `json:"kind"`
+	Name     string       `json:"name"`
+	Version  string       `json:"version"`
+	// RFC 3339 timestamp, now by default.
+	InstalledAt *string `json:"installedAt,omitempty"`
+}
+
+type NewSoftwarePolicy struct {
+	Description *string       `json:"description,omitempty"`
+	Brand       *string       `json:"brand,omitempty"`
+	Type        *string       `json:"type,omitempty"`
+	Kind        *SoftwareKind `json:"kind,omitempty"`
+	Software    *string       `json:"software,omitempty"`
+	MinVersion  string        `json:"minVersion"`
+}
+
+type PolicyViolation struct {
+	Policy   *SoftwarePolicy `json:"policy"`
+	Reason   ViolationReason `json:"reason"`
+	Software *string         `json:"software,omitempty"`
+	// The version of the outdated software.
+	Installed *string `json:"installed,omitempty"`
+	Required  string  `json:"required"`
 }
 
 type Query struct {
 }
 
+type Reading struct {
+	Metric string  `json:"metric"`
+	Value  float64 `json:"value"`
+	At     string  `json:"at"`
+}
+
+type Relationship struct {
+	ID        string       `json:"id"`
+	Type      RelationType `json:"type"`
+	FromID    string       `json:"fromId"`
+	ToID      string       `json:"toId"`
+	From      *Device      `json:"from,omitempty"`
+	To        *Device      `json:"to,omitempty"`
+	CreatedAt string       `json:"createdAt"`
+	CreatedBy *string      `json:"createdBy,omitempty"`
+}
+
+type ServiceRecord struct {
+	ID       string      `json:"id"`
+	DeviceID string      `json:"deviceId"`
+	Kind     ServiceKind `json:"kind"`
+	// The plan whose task the service completed, and when that task was due.
+	PlanID      *string `json:"planId,omitempty"`
+	DueAt       *string `json:"dueAt,omitempty"`
+	PerformedAt string  `json:"performedAt"`
+	PerformedBy *string `json:"performedBy,omitempty"`
+	Cost        float64 `json:"cost"`
+	Currency    *string `json:"currency,omitempty"`
+	Notes       *string `json:"notes,omitempty"`
+	RecordedBy  *string `json:"recordedBy,omitempty"`
+}
+
+type Software struct {
+	ID          string       `json:"id"`
+	Kind        SoftwareKind `json:"kind"`
+	Name        string       `json:"name"`
+	Version     string       `json:"version"`
+	InstalledAt string       `json:"installedAt"`
+}
+
+// Requires the devices of a brand and of a type, or of any when they are null, to run at least minVersion of a software.
+type SoftwarePolicy struct {
+	ID          string       `json:"id"`
+	Description *string      `json:"description,omitempty"`
+	Brand       *string      `json:"brand,omitempty"`
+	Type        *string      `json:"type,omitempty"`
+	Kind        SoftwareKind `json:"kind"`
+	// Null for every software of the kind.
+	Software   *string `json:"software,omitempty"`
+	MinVersion string  `json:"minVersion"`
+	CreatedAt  string  `json:"createdAt"`
+}
+
+type StatusChange struct {
+	// Null for the status the device was created with.
+	From   *DeviceStatus `json:"from,omitempty"`
+	To     DeviceStatus  `json:"to"`
+	Reason *string       `json:"reason,omitempty"`
+	At     string        `json:"at"`
+	Actor  *string       `json:"actor,omitempty"`
+}
+
 type UpdateDevice struct {
 	Name        *string `json:"name,omitempty"`
 	DeviceBrand *string `json:"deviceBrand,omitempty"`
+	Type        *string `json:"type,omitempty"`
+	// Merged into the attributes of the device, null values remove attributes.
+	Attributes interface{} `json:"attributes,omitempty"`
+}
+
+type Connectivity string
+
+const (
+	ConnectivityOnline  Connectivity = "ONLINE"
+	ConnectivityOffline Connectivity = "OFFLINE"
+	// The device never sent a heartbeat.
+	ConnectivityUnknown Connectivity = "UNKNOWN"
+)
+
+var AllConnectivity = []Connectivity{
+	ConnectivityOnline,
+	ConnectivityOffline,
+	ConnectivityUnknown,
+}
+
+func (e Connectivity) IsValid() bool {
+	switch e {
+	case ConnectivityOnline, ConnectivityOffline, ConnectivityUnknown:
+		return true
+	}
+	return false
+}
+
+func (e Connectivity) String() string {
+	return string(e)
+}
+
+func (e *Connectivity) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = Connectivity(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid Connectivity", str)
+	}
+	return nil
+}
+
+func (e Connectivity) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type ContractKind string
+
+const (
+	ContractKindWarranty ContractKind = "WARRANTY"
+	ContractKindSupport  ContractKind = "SUPPORT"
+)
+
+var AllContractKind = []ContractKind{
+	ContractKindWarranty,
+	ContractKindSupport,
+}
+
+func (e ContractKind) IsValid() bool {
+	switch e {
+	case ContractKindWarranty, ContractKindSupport:
+		return true
+	}
+	return false
+}
+
+func (e ContractKind) String() string {
+	return string(e)
+}
+
+func (e *ContractKind) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ContractKind(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ContractKind", str)
+	}
+	return nil
+}
+
+func (e ContractKind) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type DeviceStatus string
+
+const (
+	DeviceStatusOrdered     DeviceStatus = "ORDERED"
+	DeviceStatusReceived    DeviceStatus = "RECEIVED"
+	DeviceStatusProvisioned DeviceStatus = "PROVISIONED"
+	DeviceStatusInService   DeviceStatus = "IN_SERVICE"
+	DeviceStatusInRepair    DeviceStatus = "IN_REPAIR"
+	DeviceStatusRetired     DeviceStatus = "RETIRED"
+	DeviceStatusDisposed    DeviceStatus = "DISPOSED"
+)
+
+var AllDeviceStatus = []DeviceStatus{
+	DeviceStatusOrdered,
+	DeviceStatusReceived,
+	DeviceStatusProvisioned,
+	DeviceStatusInService,
+	DeviceStatusInRepair,
+	DeviceStatusRetired,
+	DeviceStatusDisposed,
+}
+
+func (e DeviceStatus) IsValid() bool {
+	switch e {
+	case DeviceStatusOrdered, DeviceStatusReceived, DeviceStatusProvisioned, DeviceStatusInService, DeviceStatusInRepair, DeviceStatusRetired, DeviceStatusDisposed:
+		return true
+	}
+	return false
+}
+
+func (e DeviceStatus) String() string {
+	return string(e)
+}
+
+func (e *DeviceStatus) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = DeviceStatus(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid DeviceStatus", str)
+	}
+	return nil
+}
+
+func (e DeviceStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type LocationKind string
+
+const (
+	LocationKindSite     LocationKind = "SITE"
+	LocationKindBuilding LocationKind = "BUILDING"
+	LocationKindRoom     LocationKind = "ROOM"
+	LocationKindRack     LocationKind = "RACK"
+)
+
+var AllLocationKind = []LocationKind{
+	LocationKindSite,
+	LocationKindBuilding,
+	LocationKindRoom,
+	LocationKindRack,
+}
+
+func (e LocationKind) IsValid() bool {
+	switch e {
+	case LocationKindSite, LocationKindBuilding, LocationKindRoom, LocationKindRack:
+		return true
+	}
+	return false
+}
+
+func (e LocationKind) String() string {
+	return string(e)
+}
+
+func (e *LocationKind) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = LocationKind(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid LocationKind", str)
+	}
+	return nil
+}
+
+func (e LocationKind) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type MaintenanceKind string
+
+const (
+	// Due a recurrence after the device was last serviced.
+	MaintenanceKindInterval MaintenanceKind = "INTERVAL"
+	// Due on fixed dates from startsAt, whenever the device was serviced.
+	MaintenanceKindCalendar MaintenanceKind = "CALENDAR"
+)
+
+var AllMaintenanceKind = []MaintenanceKind{
+	MaintenanceKindInterval,
+	MaintenanceKindCalendar,
+}
+
+func (e MaintenanceKind) IsValid() bool {
+	switch e {
+	case MaintenanceKindInterval, MaintenanceKindCalendar:
+		return true
+	}
+	return false
+}
+
+func (e MaintenanceKind) String() string {
+	return string(e)
+}
+
+func (e *MaintenanceKind) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = MaintenanceKind(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid MaintenanceKind", str)
+	}
+	return nil
+}
+
+func (e MaintenanceKind) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type RecurrenceUnit string
+
+const (
+	RecurrenceUnitDay   RecurrenceUnit = "DAY"
+	RecurrenceUnitWeek  RecurrenceUnit = "WEEK"
+	RecurrenceUnitMonth RecurrenceUnit = "MONTH"
+	RecurrenceUnitYear  RecurrenceUnit = "YEAR"
+)
+
+var AllRecurrenceUnit = []RecurrenceUnit{
+	RecurrenceUnitDay,
+	RecurrenceUnitWeek,
+	RecurrenceUnitMonth,
+	RecurrenceUnitYear,
+}
+
+func (e RecurrenceUnit) IsValid() bool {
+	switch e {
+	case RecurrenceUnitDay, RecurrenceUnitWeek, RecurrenceUnitMonth, RecurrenceUnitYear:
+		return true
+	}
+	return false
+}
+
+func (e RecurrenceUnit) String() string {
+	return string(e)
+}
+
+func (e *RecurrenceUnit) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = RecurrenceUnit(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid RecurrenceUnit", str)
+	}
+	return nil
+}
+
+func (e RecurrenceUnit) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type RelationType string
+
+const (
+	RelationTypeContains    RelationType = "CONTAINS"
+	RelationTypeConnectedTo RelationType = "CONNECTED_TO"
+	RelationTypeReplaces    RelationType = "REPLACES"
+)
+
+var AllRelationType = []RelationType{
+	RelationTypeContains,
+	RelationTypeConnectedTo,
+	RelationTypeReplaces,
+}
+
+func (e RelationType) IsValid() bool {
+	switch e {
+	case RelationTypeContains, RelationTypeConnectedTo, RelationTypeReplaces:
+		return true
+	}
+	return false
+}
+
+func (e RelationType) String() string {
+	return string(e)
+}
+
+func (e *RelationType) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = RelationType(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid RelationType", str)
+	}
+	return nil
+}
+
+func (e RelationType) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type ServiceKind string
+
+const (
+	ServiceKindMaintenance ServiceKind = "MAINTENANCE"
+	ServiceKindRepair      ServiceKind = "REPAIR"
+)
+
+var AllServiceKind = []ServiceKind{
+	ServiceKindMaintenance,
+	ServiceKindRepair,
+}
+
+func (e ServiceKind) IsValid() bool {
+	switch e {
+	case ServiceKindMaintenance, ServiceKindRepair:
+		return true
+	}
+	return false
+}
+
+func (e ServiceKind) String() string {
+	return string(e)
+}
+
+func (e *ServiceKind) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ServiceKind(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ServiceKind", str)
+	}
+	return nil
+}
+
+func (e ServiceKind) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type SoftwareKind string
+
+const (
+	SoftwareKindFirmware    SoftwareKind = "FIRMWARE"
+	SoftwareKindOs          SoftwareKind = "OS"
+	SoftwareKindApplication SoftwareKind = "APPLICATION"
+)
+
+var AllSoftwareKind = []SoftwareKind{
+	SoftwareKindFirmware,
+	SoftwareKindOs,
+	SoftwareKindApplication,
+}
+
+func (e SoftwareKind) IsValid() bool {
+	switch e {
+	case SoftwareKindFirmware, SoftwareKindOs, SoftwareKindApplication:
+		return true
+	}
+	return false
+}
+
+func (e SoftwareKind) String() string {
+	return string(e)
+}
+
+func (e *SoftwareKind) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = SoftwareKind(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid SoftwareKind", str)
+	}
+	return nil
+}
+
+func (e SoftwareKind) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type ViolationReason string
+
+const (
+	ViolationReasonOutdated ViolationReason = "OUTDATED"
+	// The device runs no software the policy covers.
+	ViolationReasonMissing ViolationReason = "MISSING"
+)
+
+var AllViolationReason = []ViolationReason{
+	ViolationReasonOutdated,
+	ViolationReasonMissing,
+}
+
+func (e ViolationReason) IsValid() bool {
+	switch e {
+	case ViolationReasonOutdated, ViolationReasonMissing:
+		return true
+	}
+	return false
+}
+
+func (e ViolationReason) String() string {
+	return string(e)
+}
+
+func (e *ViolationReason) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ViolationReason(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ViolationReason", str)
+	}
+	return nil
+}
+
+func (e ViolationReason) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
 }
@@ -0,0 +1,129 @@
+package graph
+
+import (
+	"context"
+	"devices_crud/internal/ratelimit"
+	"log/slog"
+	"net/http"
+	"slices"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/gin-gonic/gin"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+const rateLimitGroup = "graphql"
+
+type httpRequestKey struct{}
+
+// httpRequest is what operation middlewares need from the HTTP exchange that
+// gqlgen does not expose to them.
+type httpRequest struct {
+	clientIP string
+	response http.ResponseWriter
+}
+
+// serve hands the client IP and the response to the operation middlewares
+// through the request context.
+func serve(srv http.Handler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := context.WithValue(c.Request.Context(), httpRequestKey{}, &httpRequest{
+			clientIP: c.ClientIP(),
+			response: c.Writer,
+		})
+		srv.ServeHTTP(c.Writer, c.Request.WithContext(ctx))
+	}
+}
+
+// rateLimitOperations limits operations per client and top-level field, see
+// ratelimit.Limiter.AllowField. Rejected operations get a 429 like REST
+// requests, with the RATE_LIMITED error code.
+func rateLimitOperations(limiter *ratelimit.Limiter, logger *slog.Logger) graphql.OperationMiddleware {
+	return func(ctx context.Context, next graphql.OperationHandler) graphql.ResponseHandler {
+		request, _ := ctx.Value(httpRequestKey{}).(*httpRequest)
+		if request == nil {
+			request = &httpRequest{}
+		}
+		client := ratelimit.ClientKey(ctx, request.clientIP)
+
+		// Every field takes a token from its bucket; the headers describe the
+		// bucket closest to its limit.
+		var result ratelimit.Result
+		for i, field := range rootFields(graphql.GetOperationContext(ctx)) {
+			taken, err := limiter.AllowField(ctx, rateLimitGroup, field, client)
+			if err != nil {
+				logger.ErrorContext(ctx, "Error rate limiting operation", "error", err)
+				return next(ctx)
+			}
+			if i == 0 || !taken.Allowed || taken.Remaining < result.Remaining {
+				result = taken
+			}
+			if !taken.Allowed {
+				break
+			}
+		}
+
+		if request.response != nil {
+			ratelimit.SetHeaders(request.response.Header(), result)
+		}
+		if !result.Allowed {
+			logger.InfoContext(ctx, "Rate limit exceeded")
+			if request.response != nil {
+				request.response.WriteHeader(http.StatusTooManyRequests)
+			}
+			return graphql.OneShot(&graphql.Response{
+				Errors: gqlerror.List{{
+					Message: "too many requests",
+					Extensions: map[string]interface{}{
+						"code":       "RATE_LIMITED",
+						"retryAfter": ratelimit.Seconds(result.RetryAfter),
+					},
+				}},
+			})
+		}
+
+		return next(ctx)
+	}
+}
+
+// rootFields returns the names of the distinct top-level fields of the
+// operation, whatever their aliases, or "" when it has none.
+func rootFields(oc *graphql.OperationContext) []string {
+	if oc.Operation == nil {
+		return []string{""}
+	}
+	root := "Query"
+	if oc.Operation.Operation == ast.Mutation {
+		root = "Mutation"
+	}
+	names := []string{}
+	for _, field := range graphql.CollectFields(oc, oc.Operation.SelectionSet, []string{root}) {
+		if !slices.Contains(names, field.Name) {
+			names = append(names, field.Name)
+		}
+	}
+	if len(names) == 0 {
+		return []string{""}
+	}
+	return names
+}
+
+// operationName is the name of the operation or, for anonymous operations, the
+// name of its first top-level field.
+func operationName(oc *graphql.OperationContext) string {
+	if oc.OperationName != "" {
+		return oc.OperationName
+	}
+	if oc.Operation != nil {
+		if oc.Operation.Name != "" {
+			return oc.Operation.Name
+		}
+		for _, selection := range oc.Operation.SelectionSet {
+			if field, ok := selection.(*ast.Field); ok {
+				return field.Name
+			}
+		}
+	}
+	return "anonymous"
+}
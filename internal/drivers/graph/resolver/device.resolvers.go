@@ -9,68 +9,1093 @@ import (
 	domain_model "devices_crud/internal/devices/model"
 	"devices_crud/internal/drivers/graph/generated"
 	"devices_crud/internal/drivers/graph/model"
+	"encoding/json"
 	"fmt"
+	"time"
 )
 
+// Device is the resolver for the device field.
+func (r *assignmentResolver) Device(ctx context.Context, obj *model.Assignment) (*model.Device, error) {
+	res, err := r.DeviceService.GetDevice(ctx, obj.DeviceID)
+	if err != nil || res == nil {
+		return nil, err
+	}
+	return toDevice(res), nil
+}
+
+// Device is the resolver for the device field.
+func (r *contractResolver) Device(ctx context.Context, obj *model.Contract) (*model.Device, error) {
+	res, err := r.DeviceService.GetDevice(ctx, obj.DeviceID)
+	if err != nil || res == nil {
+		return nil, err
+	}
+	return toDevice(res), nil
+}
+
+// DeviceType is the resolver for the deviceType field.
+func (r *deviceResolver) DeviceType(ctx context.Context, obj *model.Device) (*model.DeviceType, error) {
+	if obj.Type == nil {
+		return nil, nil
+	}
+	res, err := r.DeviceService.GetDeviceType(ctx, *obj.Type)
+	if err != nil || res == nil {
+		return nil, err
+	}
+	return toDeviceType(res)
+}
+
+// Attribute is the resolver for the attribute field.
+func (r *deviceResolver) Attribute(ctx context.Context, obj *model.Device, name string) (interface{}, error) {
+	return attribute(obj, name), nil
+}
+
+// StringAttribute is the resolver for the stringAttribute field.
+func (r *deviceResolver) StringAttribute(ctx context.Context, obj *model.Device, name string) (*string, error) {
+	if value, ok := attribute(obj, name).(string); ok {
+		return &value, nil
+	}
+	return nil, nil
+}
+
+// NumberAttribute is the resolver for the numberAttribute field.
+func (r *deviceResolver) NumberAttribute(ctx context.Context, obj *model.Device, name string) (*float64, error) {
+	if value, ok := attribute(obj, name).(float64); ok {
+		return &value, nil
+	}
+	return nil, nil
+}
+
+// BooleanAttribute is the resolver for the booleanAttribute field.
+func (r *deviceResolver) BooleanAttribute(ctx context.Context, obj *model.Device, name string) (*bool, error) {
+	if value, ok := attribute(obj, name).(bool); ok {
+		return &value, nil
+	}
+	return nil, nil
+}
+
+// Label is the resolver for the label field.
+func (r *deviceResolver) Label(ctx context.Context, obj *model.Device, key string) (*string, error) {
+	for _, label := range obj.Labels {
+		if label.Key == key {
+			return &label.Value, nil
+		}
+	}
+	return nil, nil
+}
+
+// CurrentAssignment is the resolver for the currentAssignment field.
+func (r *deviceResolver) CurrentAssignment(ctx context.Context, obj *model.Device) (*model.Assignment, error) {
+	res, err := r.DeviceService.CurrentAssignment(ctx, obj.ID)
+	if err != nil || res == nil {
+		return nil, err
+	}
+	return toAssignment(res), nil
+}
+
+// Assignments is the resolver for the assignments field.
+func (r *deviceResolver) Assignments(ctx context.Context, obj *model.Device) ([]*model.Assignment, error) {
+	res, err := r.DeviceService.DeviceAssignments(ctx, obj.ID)
+	if err != nil {
+		return nil, err
+	}
+	return toAssignments(res), nil
+}
+
+// Location is the resolver for the location field.
+func (r *deviceResolver) Location(ctx context.Context, obj *model.Device) (*model.Location, error) {
+	if obj.LocationID == nil {
+		return nil, nil
+	}
+	return r.findLocation(ctx, *obj.LocationID)
+}
+
+// Brand is the resolver for the brand field.
+func (r *deviceResolver) Brand(ctx context.Context, obj *model.Device) (*model.Brand, error) {
+	res, err := r.DeviceService.GetBrand(ctx, obj.DeviceBrand)
+	if err != nil || res == nil {
+		return nil, err
+	}
+	return toBrand(res), nil
+}
+
+// Parent is the resolver for the parent field.
+func (r *deviceResolver) Parent(ctx context.Context, obj *model.Device) (*model.Device, error) {
+	res, err := r.DeviceService.ParentDevice(ctx, obj.ID)
+	if err != nil || res == nil {
+		return nil, err
+	}
+	return toDevice(res), nil
+}
+
+// Children is the resolver for the children field.
+func (r *deviceResolver) Children(ctx context.Context, obj *model.Device) ([]*model.Device, error) {
+	res, err := r.DeviceService.ChildDevices(ctx, obj.ID)
+	if err != nil {
+		return nil, err
+	}
+	return toDevices(res), nil
+}
+
+// Relationships is the resolver for the relationships field.
+func (r *deviceResolver) Relationships(ctx context.Context, obj *model.Device) ([]*model.Relationship, error) {
+	res, err := r.DeviceService.DeviceRelationships(ctx, obj.ID)
+	if err != nil {
+		return nil, err
+	}
+	return toRelationships(res), nil
+}
+
+// Related is the resolver for the related field.
+func (r *deviceResolver) Related(ctx context.Context, obj *model.Device, typeArg *model.RelationType, depth *int) ([]*model.Device, error) {
+	request := &domain_model.TraversalRequest{}
+	if typeArg != nil {
+		request.Types = []domain_model.RelationType{fromRelationType(*typeArg)}
+	}
+	if depth != nil {
+		request.Depth = *depth
+	}
+	res, err := r.DeviceService.TraverseDevices(ctx, obj.ID, request)
+	if err != nil {
+		return nil, err
+	}
+	related := []*model.Device{}
+	if res != nil {
+		for i := 1; i < len(res.Nodes); i++ {
+			related = append(related, toDevice(&res.Nodes[i].Device))
+		}
+	}
+	return related, nil
+}
+
+// MaintenancePlans is the resolver for the maintenancePlans field.
+func (r *deviceResolver) MaintenancePlans(ctx context.Context, obj *model.Device) ([]*model.MaintenancePlan, error) {
+	res, err := r.DeviceService.DeviceMaintenancePlans(ctx, obj.ID)
+	if err != nil {
+		return nil, err
+	}
+	return toMaintenancePlans(res), nil
+}
+
+// ServiceRecords is the resolver for the serviceRecords field.
+func (r *deviceResolver) ServiceRecords(ctx context.Context, obj *model.Device) ([]*model.ServiceRecord, error) {
+	res, err := r.DeviceService.ServiceRecords(ctx, obj.ID)
+	if err != nil {
+		return nil, err
+	}
+	return toServiceRecords(res), nil
+}
+
+// MaintenanceTasks is the resolver for the maintenanceTasks field.
+func (r *deviceResolver) MaintenanceTasks(ctx context.Context, obj *model.Device, days *int) ([]*model.MaintenanceTask, error) {
+	until, err := maintenanceHorizon(days)
+	if err != nil {
+		return nil, err
+	}
+	res, err := r.DeviceService.DeviceMaintenanceTasks(ctx, obj.ID, until)
+	if err != nil {
+		return nil, err
+	}
+	return toMaintenanceTasks(res), nil
+}
+
+// Contracts is the resolver for the contracts field.
+func (r *deviceResolver) Contracts(ctx context.Context, obj *model.Device) ([]*model.Contract, error) {
+	res, err := r.DeviceService.DeviceContracts(ctx, obj.ID)
+	if err != nil {
+		return nil, err
+	}
+	return toContracts(res), nil
+}
+
+// UnderWarranty is the resolver for the underWarranty field.
+func (r *deviceResolver) UnderWarranty(ctx context.Context, obj *model.Device) (bool, error) {
+	res, err := r.DeviceService.DeviceContracts(ctx, obj.ID)
+	if err != nil {
+		return false, err
+	}
+	now := time.Now()
+	for _, contract := range res {
+		if contract.Kind == domain_model.ContractWarranty && contract.Active(now) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// LatestReading is the resolver for the latestReading field.
+func (r *deviceResolver) LatestReading(ctx context.Context, obj *model.Device, metric *string) (*model.Reading, error) {
+	res, err := r.DeviceService.LatestReadings(ctx, obj.ID)
+	if err != nil {
+		return nil, err
+	}
+	var latest *domain_model.Reading
+	for i := range res {
+		if metric != nil && res[i].Metric != *metric {
+			continue
+		}
+		if latest == nil || res[i].At.After(latest.At) {
+			latest = &res[i]
+		}
+	}
+	if latest == nil {
+		return nil, nil
+	}
+	return toReading(latest), nil
+}
+
+// LatestReadings is the resolver for the latestReadings field.
+func (r *deviceResolver) LatestReadings(ctx context.Context, obj *model.Device) ([]*model.Reading, error) {
+	res, err := r.DeviceService.LatestReadings(ctx, obj.ID)
+	if err != nil {
+		return nil, err
+	}
+	return toReadings(res), nil
+}
+
+// Connectivity is the resolver for the connectivity field.
+func (r *deviceResolver) Connectivity(ctx context.Context, obj *model.Device) (model.Connectivity, error) {
+	res, err := r.DeviceService.DeviceConnectivity(ctx, obj.ID)
+	if err != nil {
+		return "", err
+	}
+	if res == nil {
+		return "", r.deviceNotFound(ctx, obj.ID)
+	}
+	return toConnectivity(res.Status), nil
+}
+
+// Software is the resolver for the software field.
+func (r *deviceResolver) Software(ctx context.Context, obj *model.Device) ([]*model.Software, error) {
+	res, err := r.DeviceService.DeviceSoftware(ctx, obj.ID)
+	if err != nil {
+		return nil, err
+	}
+	return toSoftwareList(res), nil
+}
+
+// Compliance is the resolver for the compliance field.
+func (r *deviceResolver) Compliance(ctx context.Context, obj *model.Device) (*model.DeviceCompliance, error) {
+	res, err := r.DeviceService.DeviceCompliance(ctx, obj.ID)
+	if err != nil {
+		return nil, err
+	}
+	if res == nil {
+		return nil, r.deviceNotFound(ctx, obj.ID)
+	}
+	return toDeviceCompliance(res), nil
+}
+
+// Duplicates is the resolver for the duplicates field.
+func (r *deviceResolver) Duplicates(ctx context.Context, obj *model.Device, threshold *float64) ([]*model.DuplicateCandidate, error) {
+	res, err := r.DeviceService.DeviceDuplicates(ctx, obj.ID, duplicateThreshold(threshold))
+	if err != nil {
+		return nil, err
+	}
+	if res == nil {
+		return nil, r.deviceNotFound(ctx, obj.ID)
+	}
+	return toDuplicateCandidates(res), nil
+}
+
+// Parent is the resolver for the parent field.
+func (r *locationResolver) Parent(ctx context.Context, obj *model.Location) (*model.Location, error) {
+	if obj.ParentID == nil {
+		return nil, nil
+	}
+	return r.findLocation(ctx, *obj.ParentID)
+}
+
+// Children is the resolver for the children field.
+func (r *locationResolver) Children(ctx context.Context, obj *model.Location) ([]*model.Location, error) {
+	res, err := r.DeviceService.ListLocations(ctx, obj.ID)
+	if err != nil {
+		return nil, err
+	}
+	return toLocations(res), nil
+}
+
+// Path is the resolver for the path field.
+func (r *locationResolver) Path(ctx context.Context, obj *model.Location) ([]*model.Location, error) {
+	res, err := r.DeviceService.LocationPath(ctx, obj.ID)
+	if err != nil {
+		return nil, err
+	}
+	return toLocations(res), nil
+}
+
+// PathName is the resolver for the pathName field.
+func (r *locationResolver) PathName(ctx context.Context, obj *model.Location) (string, error) {
+	res, err := r.DeviceService.LocationPath(ctx, obj.ID)
+	if err != nil {
+		return "", err
+	}
+	return res.String(), nil
+}
+
+// Devices is the resolver for the devices field.
+func (r *locationResolver) Devices(ctx context.Context, obj *model.Location, recursive *bool) ([]*model.Device, error) {
+	res, err := r.DeviceService.LocationDevices(ctx, obj.ID, recursive == nil || *recursive)
+	if err != nil {
+		return nil, err
+	}
+	respones := make([]*model.Device, len(res))
+	for i := range res {
+		respones[i] = toDevice(&res[i])
+	}
+	return respones, nil
+}
+
+// Device is the resolver for the device field.
+func (r *maintenanceTaskResolver) Device(ctx context.Context, obj *model.MaintenanceTask) (*model.Device, error) {
+	res, err := r.DeviceService.GetDevice(ctx, obj.DeviceID)
+	if err != nil || res == nil {
+		return nil, err
+	}
+	return toDevice(res), nil
+}
+
 // CreateDevice is the resolver for the createDevice field.
 func (r *mutationResolver) CreateDevice(ctx context.Context, input model.NewDevice) (*model.Device, error) {
+	attributes, err := toAttributes(input.Attributes)
+	if err != nil {
+		return nil, err
+	}
 	newDevice := &domain_model.NewDeviceRequest{
 		Name:        input.Name,
 		DeviceBrand: input.DeviceBrand,
+		Attributes:  attributes,
+	}
+	if input.Type != nil {
+		newDevice.Type = *input.Type
+	}
+	if input.Status != nil {
+		newDevice.Status = fromStatus(*input.Status)
+	}
+	if input.LocationID != nil {
+		newDevice.LocationID = *input.LocationID
 	}
 	res, err := r.DeviceService.AddDevice(ctx, newDevice)
 	if err != nil {
 		return nil, err
 	}
 
-	return &model.Device{
-		ID:          *res,
-		Name:        input.Name,
-		DeviceBrand: input.DeviceBrand,
-	}, nil
+	created, err := r.DeviceService.GetDevice(ctx, *res)
+	if err != nil {
+		return nil, err
+	}
+	if created == nil {
+		return nil, r.deviceNotFound(ctx, *res)
+	}
+
+	return toDevice(created), nil
 }
 
 // UpdateDevice is the resolver for the updateDevice field.
 func (r *mutationResolver) UpdateDevice(ctx context.Context, deviceID string, input model.UpdateDevice) (*model.Device, error) {
-	r.DeviceService.PatchDevice(ctx, &domain_model.PatchDeviceRequest{
+	attributes, err := toAttributes(input.Attributes)
+	if err != nil {
+		return nil, err
+	}
+	patched, err := r.DeviceService.PatchDevice(ctx, &domain_model.PatchDeviceRequest{
 		ID:          deviceID,
 		Name:        input.Name,
 		DeviceBrand: input.DeviceBrand,
+		Type:        input.Type,
+		Attributes:  attributes,
 	})
+	if err != nil {
+		return nil, err
+	}
+	if patched == nil {
+		return nil, r.deviceNotFound(ctx, deviceID)
+	}
 
 	res, err := r.DeviceService.GetDevice(ctx, deviceID)
 	if err != nil {
 		return nil, err
 	}
+	if res == nil {
+		return nil, r.deviceNotFound(ctx, deviceID)
+	}
 
-	return &model.Device{
-		ID:          deviceID,
-		Name:        res.Name,
-		DeviceBrand: res.DeviceBrand,
-	}, nil
+	return toDevice(res), nil
+}
+
+// DeleteDevice is the resolver for the deleteDevice field.
+func (r *mutationResolver) DeleteDevice(ctx context.Context, id string, cascade *bool) (bool, error) {
+	deleteDevice := r.DeviceService.DeleteDevice
+	if cascade != nil && *cascade {
+		deleteDevice = r.DeviceService.DeleteDeviceTree
+	}
+	if err := deleteDevice(ctx, id); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// SetLabels is the resolver for the setLabels field.
+func (r *mutationResolver) SetLabels(ctx context.Context, deviceID string, labels []*model.LabelInput) (*model.Device, error) {
+	values := make(map[string]string, len(labels))
+	for _, label := range labels {
+		values[label.Key] = label.Value
+	}
+	res, err := r.DeviceService.SetLabels(ctx, deviceID, values)
+	if err != nil {
+		return nil, err
+	}
+	if res == nil {
+		return nil, r.deviceNotFound(ctx, deviceID)
+	}
+	return toDevice(res), nil
+}
+
+// RemoveLabels is the resolver for the removeLabels field.
+func (r *mutationResolver) RemoveLabels(ctx context.Context, deviceID string, keys []string) (*model.Device, error) {
+	res, err := r.DeviceService.RemoveLabels(ctx, deviceID, keys)
+	if err != nil {
+		return nil, err
+	}
+	if res == nil {
+		return nil, r.deviceNotFound(ctx, deviceID)
+	}
+	return toDevice(res), nil
+}
+
+// TransitionDevice is the resolver for the transitionDevice field.
+func (r *mutationResolver) TransitionDevice(ctx context.Context, id string, status model.DeviceStatus, reason string) (*model.Device, error) {
+	res, err := r.DeviceService.TransitionDevice(ctx, id, &domain_model.TransitionRequest{
+		Status: fromStatus(status),
+		Reason: reason,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if res == nil {
+		return nil, r.deviceNotFound(ctx, id)
+	}
+	return toDevice(res), nil
+}
+
+// CheckOutDevice is the resolver for the checkOutDevice field.
+func (r *mutationResolver) CheckOutDevice(ctx context.Context, deviceID string, assignee string, dueAt *string, note *string) (*model.Assignment, error) {
+	request, err := toCheckOutRequest(assignee, dueAt, note)
+	if err != nil {
+		return nil, err
+	}
+	res, err := r.DeviceService.CheckOutDevice(ctx, deviceID, request)
+	if err != nil {
+		return nil, err
+	}
+	if res == nil {
+		return nil, r.deviceNotFound(ctx, deviceID)
+	}
+	return toAssignment(res), nil
+}
+
+// CheckInDevice is the resolver for the checkInDevice field.
+func (r *mutationResolver) CheckInDevice(ctx context.Context, deviceID string, note *string) (*model.Assignment, error) {
+	request := &domain_model.CheckInRequest{}
+	if note != nil {
+		request.Note = *note
+	}
+	res, err := r.DeviceService.CheckInDevice(ctx, deviceID, request)
+	if err != nil {
+		return nil, err
+	}
+	if res == nil {
+		return nil, r.deviceNotFound(ctx, deviceID)
+	}
+	return toAssignment(res), nil
+}
+
+// TransferDevice is the resolver for the transferDevice field.
+func (r *mutationResolver) TransferDevice(ctx context.Context, deviceID string, assignee string, dueAt *string, note *string) (*model.Assignment, error) {
+	request, err := toCheckOutRequest(assignee, dueAt, note)
+	if err != nil {
+		return nil, err
+	}
+	res, err := r.DeviceService.TransferDevice(ctx, deviceID, request)
+	if err != nil {
+		return nil, err
+	}
+	if res == nil {
+		return nil, r.deviceNotFound(ctx, deviceID)
+	}
+	return toAssignment(res), nil
+}
+
+// CreateLocation is the resolver for the createLocation field.
+func (r *mutationResolver) CreateLocation(ctx context.Context, input model.NewLocation) (*model.Location, error) {
+	request := &domain_model.NewLocationRequest{
+		Name: input.Name,
+		Kind: fromLocationKind(input.Kind),
+	}
+	if input.ParentID != nil {
+		request.ParentID = *input.ParentID
+	}
+	res, err := r.DeviceService.CreateLocation(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	return toLocation(res), nil
+}
+
+// MoveLocation is the resolver for the moveLocation field.
+func (r *mutationResolver) MoveLocation(ctx context.Context, id string, parentID *string) (*model.Location, error) {
+	request := &domain_model.MoveLocationRequest{}
+	if parentID != nil {
+		request.ParentID = *parentID
+	}
+	res, err := r.DeviceService.MoveLocation(ctx, id, request)
+	if err != nil {
+		return nil, err
+	}
+	if res == nil {
+		return nil, r.locationNotFound(ctx, id)
+	}
+	return toLocation(res), nil
+}
+
+// DeleteLocation is the resolver for the deleteLocation field.
+func (r *mutationResolver) DeleteLocation(ctx context.Context, id string) (bool, error) {
+	if err := r.DeviceService.DeleteLocation(ctx, id); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// MoveDevice is the resolver for the moveDevice field.
+func (r *mutationResolver) MoveDevice(ctx context.Context, id string, locationID *string, reason *string) (*model.Device, error) {
+	request := &domain_model.MoveDeviceRequest{}
+	if locationID != nil {
+		request.LocationID = *locationID
+	}
+	if reason != nil {
+		request.Reason = *reason
+	}
+	res, err := r.DeviceService.MoveDevice(ctx, id, request)
+	if err != nil {
+		return nil, err
+	}
+	if res == nil {
+		return nil, r.deviceNotFound(ctx, id)
+	}
+	return toDevice(res), nil
+}
+
+// LinkDevices is the resolver for the linkDevices field.
+func (r *mutationResolver) LinkDevices(ctx context.Context, id string, typeArg model.RelationType, targetID string) (*model.Relationship, error) {
+	request := &domain_model.LinkRequest{Type: fromRelationType(typeArg), TargetID: targetID}
+	res, err := r.DeviceService.LinkDevices(ctx, id, request)
+	if err != nil {
+		return nil, err
+	}
+	if res == nil {
+		return nil, r.deviceNotFound(ctx, id)
+	}
+	return toRelationship(res), nil
+}
+
+// UnlinkDevices is the resolver for the unlinkDevices field.
+func (r *mutationResolver) UnlinkDevices(ctx context.Context, id string, relationshipID string) (bool, error) {
+	res, err := r.DeviceService.UnlinkDevices(ctx, id, relationshipID)
+	if err != nil {
+		return false, err
+	}
+	return res != nil, nil
+}
+
+// CreateMaintenancePlan is the resolver for the createMaintenancePlan field.
+func (r *mutationResolver) CreateMaintenancePlan(ctx context.Context, input model.NewMaintenancePlan) (*model.MaintenancePlan, error) {
+	request, err := toMaintenancePlanRequest(input)
+	if err != nil {
+		return nil, err
+	}
+	res, err := r.DeviceService.CreateMaintenancePlan(ctx, input.DeviceID, request)
+	if err != nil {
+		return nil, err
+	}
+	if res == nil {
+		return nil, r.deviceNotFound(ctx, input.DeviceID)
+	}
+	return toMaintenancePlan(res), nil
+}
+
+// DeleteMaintenancePlan is the resolver for the deleteMaintenancePlan field.
+func (r *mutationResolver) DeleteMaintenancePlan(ctx context.Context, deviceID string, planID string) (bool, error) {
+	res, err := r.DeviceService.DeleteMaintenancePlan(ctx, deviceID, planID)
+	if err != nil {
+		return false, err
+	}
+	return res != nil, nil
+}
+
+// RecordService is the resolver for the recordService field.
+func (r *mutationResolver) RecordService(ctx context.Context, input model.NewServiceRecord) (*model.ServiceRecord, error) {
+	request, err := toServiceRecordRequest(input)
+	if err != nil {
+		return nil, err
+	}
+	res, err := r.DeviceService.RecordService(ctx, input.DeviceID, request)
+	if err != nil {
+		return nil, err
+	}
+	if res == nil {
+		return nil, r.deviceNotFound(ctx, input.DeviceID)
+	}
+	return toServiceRecord(res), nil
+}
+
+// AddContract is the resolver for the addContract field.
+func (r *mutationResolver) AddContract(ctx context.Context, input model.NewContract) (*model.Contract, error) {
+	request, err := toContractRequest(input)
+	if err != nil {
+		return nil, err
+	}
+	res, err := r.DeviceService.AddContract(ctx, input.DeviceID, request)
+	if err != nil {
+		return nil, err
+	}
+	if res == nil {
+		return nil, r.deviceNotFound(ctx, input.DeviceID)
+	}
+	return toContract(res), nil
+}
+
+// DeleteContract is the resolver for the deleteContract field.
+func (r *mutationResolver) DeleteContract(ctx context.Context, deviceID string, contractID string) (bool, error) {
+	res, err := r.DeviceService.DeleteContract(ctx, deviceID, contractID)
+	if err != nil {
+		return false, err
+	}
+	return res != nil, nil
+}
+
+// RecordSoftware is the resolver for the recordSoftware field.
+func (r *mutationResolver) RecordSoftware(ctx context.Context, input model.NewSoftware) (*model.Software, error) {
+	request, err := toSoftwareRequest(input)
+	if err != nil {
+		return nil, err
+	}
+	res, err := r.DeviceService.RecordSoftware(ctx, input.DeviceID, request)
+	if err != nil {
+		return nil, err
+	}
+	if res == nil {
+		return nil, r.deviceNotFound(ctx, input.DeviceID)
+	}
+	return toSoftware(res), nil
+}
+
+// DeleteSoftware is the resolver for the deleteSoftware field.
+func (r *mutationResolver) DeleteSoftware(ctx context.Context, deviceID string, softwareID string) (bool, error) {
+	res, err := r.DeviceService.DeleteSoftware(ctx, deviceID, softwareID)
+	if err != nil {
+		return false, err
+	}
+	return res != nil, nil
+}
+
+// CreateSoftwarePolicy is the resolver for the createSoftwarePolicy field.
+func (r *mutationResolver) CreateSoftwarePolicy(ctx context.Context, input model.NewSoftwarePolicy) (*model.SoftwarePolicy, error) {
+	res, err := r.DeviceService.CreateSoftwarePolicy(ctx, toSoftwarePolicyRequest(input))
+	if err != nil {
+		return nil, err
+	}
+	return toSoftwarePolicy(res), nil
+}
+
+// DeleteSoftwarePolicy is the resolver for the deleteSoftwarePolicy field.
+func (r *mutationResolver) DeleteSoftwarePolicy(ctx context.Context, id string) (bool, error) {
+	res, err := r.DeviceService.DeleteSoftwarePolicy(ctx, id)
+	if err != nil {
+		return false, err
+	}
+	return res != nil, nil
+}
+
+// MergeDevices is the resolver for the mergeDevices field.
+func (r *mutationResolver) MergeDevices(ctx context.Context, id string, sourceID string) (*model.Device, error) {
+	res, err := r.DeviceService.MergeDevices(ctx, id, &domain_model.MergeRequest{SourceID: sourceID})
+	if err != nil {
+		return nil, err
+	}
+	if res == nil {
+		return nil, r.deviceNotFound(ctx, id)
+	}
+	return toDevice(res), nil
+}
+
+// SaveDeviceType is the resolver for the saveDeviceType field.
+func (r *mutationResolver) SaveDeviceType(ctx context.Context, input model.DeviceTypeInput) (*model.DeviceType, error) {
+	schema, err := json.Marshal(input.Schema)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", domain_model.ErrInvalidDeviceType, err)
+	}
+	deviceType := &domain_model.DeviceType{
+		Name:   input.Name,
+		Schema: schema,
+	}
+	if input.Description != nil {
+		deviceType.Description = *input.Description
+	}
+
+	saved, err := r.DeviceService.SaveDeviceType(ctx, deviceType)
+	if err != nil {
+		return nil, err
+	}
+	return toDeviceType(saved)
+}
+
+// DeleteDeviceType is the resolver for the deleteDeviceType field.
+func (r *mutationResolver) DeleteDeviceType(ctx context.Context, name string) (bool, error) {
+	if err := r.DeviceService.DeleteDeviceType(ctx, name); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// SaveBrand is the resolver for the saveBrand field.
+func (r *mutationResolver) SaveBrand(ctx context.Context, input model.BrandInput) (*model.Brand, error) {
+	brand := &domain_model.Brand{
+		Name:    input.Name,
+		Aliases: input.Aliases,
+	}
+	for _, deviceModel := range input.Models {
+		brand.Models = append(brand.Models, domain_model.DeviceModel{Name: deviceModel.Name, Aliases: deviceModel.Aliases})
+	}
+	res, err := r.DeviceService.SaveBrand(ctx, brand)
+	if err != nil {
+		return nil, err
+	}
+	return toBrand(res), nil
+}
+
+// DeleteBrand is the resolver for the deleteBrand field.
+func (r *mutationResolver) DeleteBrand(ctx context.Context, name string) (bool, error) {
+	if err := r.DeviceService.DeleteBrand(ctx, name); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// MigrateBrands is the resolver for the migrateBrands field.
+func (r *mutationResolver) MigrateBrands(ctx context.Context, dryRun *bool, addMissing *bool) (*model.BrandMigration, error) {
+	request := &domain_model.MigrateBrandsRequest{
+		DryRun:     dryRun == nil || *dryRun,
+		AddMissing: addMissing != nil && *addMissing,
+	}
+	res, err := r.DeviceService.MigrateBrands(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	migration := &model.BrandMigration{
+		DryRun:  res.DryRun,
+		Changes: make([]*model.BrandChange, len(res.Changes)),
+		Added:   toBrands(res.Added),
+	}
+	for i, change := range res.Changes {
+		migration.Changes[i] = &model.BrandChange{DeviceID: change.DeviceID, From: change.From, To: change.To}
+	}
+	return migration, nil
 }
 
 // Devices is the resolver for the devices field.
-func (r *queryResolver) Devices(ctx context.Context) ([]*model.Device, error) {
-	res, err := r.DeviceService.GetAllDevices(ctx)
+func (r *queryResolver) Devices(ctx context.Context, filter *string, selector *string, connectivity *model.Connectivity) ([]*model.Device, error) {
+	var res []domain_model.Device
+	var err error
+	if filter != nil || selector != nil || connectivity != nil {
+		request := &domain_model.ListDevicesRequest{}
+		if connectivity != nil {
+			request.Connectivity = fromConnectivity(*connectivity)
+		}
+		if filter != nil {
+			request.Filter = *filter
+		}
+		if selector != nil {
+			request.Selector = *selector
+		}
+		res, _, err = r.DeviceService.ListDevices(ctx, request)
+	} else {
+		res, err = r.DeviceService.GetAllDevices(ctx)
+	}
 	if err != nil {
 		return nil, err
 	}
 	respones := make([]*model.Device, len(res))
-	for i, v := range res {
-		respones[i] = &model.Device{
-			ID:          v.ID,
-			Name:        v.Name,
-			DeviceBrand: v.DeviceBrand,
-			CreatedAt:   v.CreatedAt.String(),
-		}
+	for i := range res {
+		respones[i] = toDevice(&res[i])
 	}
 	return respones, nil
 }
 
 // Device is the resolver for the device field.
 func (r *queryResolver) Device(ctx context.Context, id string) (*model.Device, error) {
-	panic(fmt.Errorf("not implemented: Device - device"))
+	res, err := r.DeviceService.GetDevice(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if res == nil {
+		// The IDs of merged devices lead to the device they were merged into.
+		redirect, err := r.DeviceService.DeviceRedirect(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if redirect != nil {
+			res, err = r.DeviceService.GetDevice(ctx, redirect.ToID)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	if res == nil {
+		return nil, r.deviceNotFound(ctx, id)
+	}
+
+	return toDevice(res), nil
+}
+
+// SearchDevices is the resolver for the searchDevices field.
+func (r *queryResolver) SearchDevices(ctx context.Context, query string) ([]*model.DeviceSearchResult, error) {
+	res, err := r.DeviceService.SearchDevices(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]*model.DeviceSearchResult, len(res))
+	for i := range res {
+		results[i] = &model.DeviceSearchResult{
+			Device: toDevice(&res[i].Device),
+			Score:  res[i].Score,
+		}
+	}
+	return results, nil
+}
+
+// DeviceTypes is the resolver for the deviceTypes field.
+func (r *queryResolver) DeviceTypes(ctx context.Context) ([]*model.DeviceType, error) {
+	res, err := r.DeviceService.ListDeviceTypes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	deviceTypes := make([]*model.DeviceType, len(res))
+	for i := range res {
+		if deviceTypes[i], err = toDeviceType(&res[i]); err != nil {
+			return nil, err
+		}
+	}
+	return deviceTypes, nil
+}
+
+// DeviceType is the resolver for the deviceType field.
+func (r *queryResolver) DeviceType(ctx context.Context, name string) (*model.DeviceType, error) {
+	res, err := r.DeviceService.GetDeviceType(ctx, name)
+	if err != nil || res == nil {
+		return nil, err
+	}
+	return toDeviceType(res)
+}
+
+// Assignments is the resolver for the assignments field.
+func (r *queryResolver) Assignments(ctx context.Context, assignee string) ([]*model.Assignment, error) {
+	res, err := r.DeviceService.AssigneeAssignments(ctx, assignee)
+	if err != nil {
+		return nil, err
+	}
+	return toAssignments(res), nil
+}
+
+// OverdueAssignments is the resolver for the overdueAssignments field.
+func (r *queryResolver) OverdueAssignments(ctx context.Context) ([]*model.Assignment, error) {
+	res, err := r.DeviceService.OverdueAssignments(ctx, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return toAssignments(res), nil
+}
+
+// Locations is the resolver for the locations field.
+func (r *queryResolver) Locations(ctx context.Context, parentID *string) ([]*model.Location, error) {
+	parent := ""
+	if parentID != nil {
+		parent = *parentID
+	}
+	res, err := r.DeviceService.ListLocations(ctx, parent)
+	if err != nil {
+		return nil, err
+	}
+	return toLocations(res), nil
+}
+
+// Location is the resolver for the location field.
+func (r *queryResolver) Location(ctx context.Context, id string) (*model.Location, error) {
+	return r.findLocation(ctx, id)
+}
+
+// LocationByPath is the resolver for the locationByPath field.
+func (r *queryResolver) LocationByPath(ctx context.Context, path string) (*model.Location, error) {
+	res, err := r.DeviceService.FindLocationByPath(ctx, path)
+	if err != nil || res == nil {
+		return nil, err
+	}
+	return toLocation(res), nil
+}
+
+// DeviceGraph is the resolver for the deviceGraph field.
+func (r *queryResolver) DeviceGraph(ctx context.Context, id string, types []model.RelationType, depth *int) (*model.DeviceGraph, error) {
+	request := &domain_model.TraversalRequest{}
+	for _, relationType := range types {
+		request.Types = append(request.Types, fromRelationType(relationType))
+	}
+	if depth != nil {
+		request.Depth = *depth
+	}
+	res, err := r.DeviceService.TraverseDevices(ctx, id, request)
+	if err != nil || res == nil {
+		return nil, err
+	}
+	graph := &model.DeviceGraph{
+		Nodes: make([]*model.GraphNode, len(res.Nodes)),
+		Edges: toRelationships(res.Edges),
+	}
+	for i := range res.Nodes {
+		graph.Nodes[i] = &model.GraphNode{Device: toDevice(&res.Nodes[i].Device), Depth: res.Nodes[i].Depth}
+	}
+	return graph, nil
+}
+
+// Brands is the resolver for the brands field.
+func (r *queryResolver) Brands(ctx context.Context) ([]*model.Brand, error) {
+	res, err := r.DeviceService.ListBrands(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return toBrands(res), nil
+}
+
+// Brand is the resolver for the brand field.
+func (r *queryResolver) Brand(ctx context.Context, name string) (*model.Brand, error) {
+	res, err := r.DeviceService.GetBrand(ctx, name)
+	if err != nil || res == nil {
+		return nil, err
+	}
+	return toBrand(res), nil
+}
+
+// ResolveBrand is the resolver for the resolveBrand field.
+func (r *queryResolver) ResolveBrand(ctx context.Context, name string) (*model.Brand, error) {
+	res, err := r.DeviceService.ResolveBrand(ctx, name)
+	if err != nil || res == nil {
+		return nil, err
+	}
+	return toBrand(res), nil
+}
+
+// UpcomingMaintenance is the resolver for the upcomingMaintenance field.
+func (r *queryResolver) UpcomingMaintenance(ctx context.Context, days *int) ([]*model.MaintenanceTask, error) {
+	until, err := maintenanceHorizon(days)
+	if err != nil {
+		return nil, err
+	}
+	res, err := r.DeviceService.UpcomingMaintenance(ctx, until)
+	if err != nil {
+		return nil, err
+	}
+	return toMaintenanceTasks(res), nil
+}
+
+// OverdueMaintenance is the resolver for the overdueMaintenance field.
+func (r *queryResolver) OverdueMaintenance(ctx context.Context) ([]*model.MaintenanceTask, error) {
+	res, err := r.DeviceService.OverdueMaintenance(ctx, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return toMaintenanceTasks(res), nil
+}
+
+// ExpiringContracts is the resolver for the expiringContracts field.
+func (r *queryResolver) ExpiringContracts(ctx context.Context, days *int, kind *model.ContractKind) ([]*model.ExpiringContract, error) {
+	horizon := 90
+	if days != nil {
+		horizon = *days
+	}
+	var contractKind domain_model.ContractKind
+	if kind != nil {
+		contractKind = fromContractKind(*kind)
+	}
+	res, err := r.DeviceService.ExpiringContracts(ctx, time.Now(), horizon, contractKind)
+	if err != nil {
+		return nil, err
+	}
+	return toExpiringContracts(res), nil
+}
+
+// SoftwarePolicies is the resolver for the softwarePolicies field.
+func (r *queryResolver) SoftwarePolicies(ctx context.Context) ([]*model.SoftwarePolicy, error) {
+	res, err := r.DeviceService.SoftwarePolicies(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return toSoftwarePolicies(res), nil
+}
+
+// ComplianceReport is the resolver for the complianceReport field.
+func (r *queryResolver) ComplianceReport(ctx context.Context, policyID *string) (*model.ComplianceReport, error) {
+	id := ""
+	if policyID != nil {
+		id = *policyID
+	}
+	res, err := r.DeviceService.ComplianceReport(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if res == nil {
+		return nil, r.policyNotFound(ctx, id)
+	}
+	return toComplianceReport(res), nil
+}
+
+// DuplicateCandidates is the resolver for the duplicateCandidates field.
+func (r *queryResolver) DuplicateCandidates(ctx context.Context, threshold *float64) ([]*model.DuplicateCandidate, error) {
+	res, err := r.DeviceService.DuplicateCandidates(ctx, duplicateThreshold(threshold))
+	if err != nil {
+		return nil, err
+	}
+	return toDuplicateCandidates(res), nil
+}
+
+// From is the resolver for the from field.
+func (r *relationshipResolver) From(ctx context.Context, obj *model.Relationship) (*model.Device, error) {
+	res, err := r.DeviceService.GetDevice(ctx, obj.FromID)
+	if err != nil || res == nil {
+		return nil, err
+	}
+	return toDevice(res), nil
+}
+
+// To is the resolver for the to field.
+func (r *relationshipResolver) To(ctx context.Context, obj *model.Relationship) (*model.Device, error) {
+	res, err := r.DeviceService.GetDevice(ctx, obj.ToID)
+	if err != nil || res == nil {
+		return nil, err
+	}
+	return toDevice(res), nil
+}
+
+// Assignment returns generated.AssignmentResolver implementation.
+func (r *Resolver) Assignment() generated.AssignmentResolver { return &assignmentResolver{r} }
+
+// Contract returns generated.ContractResolver implementation.
+func (r *Resolver) Contract() generated.ContractResolver { return &contractResolver{r} }
+
+// Device returns generated.DeviceResolver implementation.
+func (r *Resolver) Device() generated.DeviceResolver { return &deviceResolver{r} }
+
+// Location returns generated.LocationResolver implementation.
+func (r *Resolver) Location() generated.LocationResolver { return &locationResolver{r} }
+
+// MaintenanceTask returns generated.MaintenanceTaskResolver implementation.
+func (r *Resolver) MaintenanceTask() generated.MaintenanceTaskResolver {
+	return &maintenanceTaskResolver{r}
 }
 
 // Mutation returns generated.MutationResolver implementation.
@@ -79,5 +1104,14 @@ func (r *Resolver) Mutation() generated.MutationResolver { return &mutationResol
 // Query returns generated.QueryResolver implementation.
 func (r *Resolver) Query() generated.QueryResolver { return &queryResolver{r} }
 
+// Relationship returns generated.RelationshipResolver implementation.
+func (r *Resolver) Relationship() generated.RelationshipResolver { return &relationshipResolver{r} }
+
+type assignmentResolver struct{ *Resolver }
+type contractResolver struct{ *Resolver }
+type deviceResolver struct{ *Resolver }
+type locationResolver struct{ *Resolver }
+type maintenanceTaskResolver struct{ *Resolver }
 type mutationResolver struct{ *Resolver }
 type queryResolver struct{ *Resolver }
+type relationshipResolver struct{ *Resolver }
@@ -1,8 +1,19 @@
 package resolver
 
 import (
+	"context"
 	"devices_crud/internal/devices/app"
-	"log"
+	domain_model "devices_crud/internal/devices/model"
+	"devices_crud/internal/drivers/graph/model"
+	"devices_crud/internal/logging"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/vektah/gqlparser/v2/gqlerror"
 )
 
 // This file will not be regenerated automatically.
@@ -11,5 +22,684 @@ import (
 
 type Resolver struct{
 	DeviceService *app.DeviceService
-	Logger        *log.Logger
+	Logger        *slog.Logger
+}
+
+func (r *Resolver) deviceNotFound(ctx context.Context, id string) error {
+	r.Logger.InfoContext(ctx, "Device not found", logging.DeviceIDKey, id)
+	return &gqlerror.Error{
+		Message:    fmt.Sprintf("device %s not found", id),
+		Extensions: map[string]interface{}{"code": "NOT_FOUND"},
+	}
+}
+
+func (r *Resolver) locationNotFound(ctx context.Context, id string) error {
+	r.Logger.InfoContext(ctx, "Location not found", "location_id", id)
+	return &gqlerror.Error{
+		Message:    fmt.Sprintf("location %s not found", id),
+		Extensions: map[string]interface{}{"code": "NOT_FOUND"},
+	}
+}
+
+func (r *Resolver) policyNotFound(ctx context.Context, id string) error {
+	r.Logger.InfoContext(ctx, "Software policy not found", "policy_id", id)
+	return &gqlerror.Error{
+		Message:    fmt.Sprintf("software policy %s not found", id),
+		Extensions: map[string]interface{}{"code": "NOT_FOUND"},
+	}
+}
+
+// findLocation resolves a location by ID, null when it does not exist.
+func (r *Resolver) findLocation(ctx context.Context, id string) (*model.Location, error) {
+	location, err := r.DeviceService.GetLocation(ctx, id)
+	if err != nil || location == nil {
+		return nil, err
+	}
+	return toLocation(location), nil
+}
+
+func toDevice(device *domain_model.Device) *model.Device {
+	res := &model.Device{
+		ID:              device.ID,
+		Name:            device.Name,
+		DeviceBrand:     device.DeviceBrand,
+		CreatedAt:       device.CreatedAt.String(),
+		TenantID:        device.TenantID,
+		Labels:          []*model.Label{},
+		Status:          toStatus(device.Status),
+		StatusHistory:   make([]*model.StatusChange, len(device.StatusHistory)),
+		LocationHistory: make([]*model.LocationChange, len(device.LocationHistory)),
+		InRepair:        device.Status == domain_model.StatusInRepair,
+	}
+	for key, value := range device.Labels {
+		res.Labels = append(res.Labels, &model.Label{Key: key, Value: value})
+	}
+	sort.Slice(res.Labels, func(i, j int) bool { return res.Labels[i].Key < res.Labels[j].Key })
+	if device.Type != "" {
+		res.Type = &device.Type
+	}
+	if device.Attributes != nil {
+		res.Attributes = map[string]interface{}(device.Attributes)
+	}
+	for i, change := range device.StatusHistory {
+		res.StatusHistory[i] = toStatusChange(change)
+	}
+	if device.LocationID != "" {
+		res.LocationID = &device.LocationID
+	}
+	for i, change := range device.LocationHistory {
+		res.LocationHistory[i] = toLocationChange(change)
+	}
+	if device.LastSeenAt != nil {
+		at := device.LastSeenAt.String()
+		res.LastSeenAt = &at
+	}
+	if since := device.StatusSince(); !since.IsZero() {
+		at := since.String()
+		res.StatusSince = &at
+	}
+	return res
+}
+
+// toStatus maps statuses such as in_service to the enum value IN_SERVICE.
+func toStatus(status domain_model.Status) model.DeviceStatus {
+	return model.DeviceStatus(strings.ToUpper(string(status)))
+}
+
+func fromStatus(status model.DeviceStatus) domain_model.Status {
+	return domain_model.Status(strings.ToLower(string(status)))
+}
+
+func toStatusChange(change domain_model.StatusChange) *model.StatusChange {
+	res := &model.StatusChange{
+		To: toStatus(change.To),
+		At: change.At.String(),
+	}
+	if change.From != "" {
+		from := toStatus(change.From)
+		res.From = &from
+	}
+	if change.Reason != "" {
+		res.Reason = &change.Reason
+	}
+	if change.Actor != "" {
+		res.Actor = &change.Actor
+	}
+	return res
+}
+
+func toLocation(location *domain_model.Location) *model.Location {
+	res := &model.Location{
+		ID:        location.ID,
+		Name:      location.Name,
+		Kind:      model.LocationKind(strings.ToUpper(string(location.Kind))),
+		CreatedAt: location.CreatedAt.String(),
+	}
+	if location.ParentID != "" {
+		res.ParentID = &location.ParentID
+	}
+	return res
+}
+
+func toLocations(locations []domain_model.Location) []*model.Location {
+	res := make([]*model.Location, len(locations))
+	for i := range locations {
+		res[i] = toLocation(&locations[i])
+	}
+	return res
+}
+
+func fromLocationKind(kind model.LocationKind) domain_model.LocationKind {
+	return domain_model.LocationKind(strings.ToLower(string(kind)))
+}
+
+func toLocationChange(change domain_model.LocationChange) *model.LocationChange {
+	res := &model.LocationChange{At: change.At.String()}
+	if change.From != "" {
+		res.From = &change.From
+	}
+	if change.To != "" {
+		res.To = &change.To
+	}
+	if change.Reason != "" {
+		res.Reason = &change.Reason
+	}
+	if change.Actor != "" {
+		res.Actor = &change.Actor
+	}
+	return res
+}
+
+func toDevices(devices []domain_model.Device) []*model.Device {
+	res := make([]*model.Device, len(devices))
+	for i := range devices {
+		res[i] = toDevice(&devices[i])
+	}
+	return res
+}
+
+func toRelationship(relationship *domain_model.Relationship) *model.Relationship {
+	res := &model.Relationship{
+		ID:        relationship.ID,
+		Type:      model.RelationType(strings.ToUpper(string(relationship.Type))),
+		FromID:    relationship.FromID,
+		ToID:      relationship.ToID,
+		CreatedAt: relationship.CreatedAt.String(),
+	}
+	if relationship.CreatedBy != "" {
+		res.CreatedBy = &relationship.CreatedBy
+	}
+	return res
+}
+
+func toRelationships(relationships []domain_model.Relationship) []*model.Relationship {
+	res := make([]*model.Relationship, len(relationships))
+	for i := range relationships {
+		res[i] = toRelationship(&relationships[i])
+	}
+	return res
+}
+
+func fromRelationType(relationType model.RelationType) domain_model.RelationType {
+	return domain_model.RelationType(strings.ToLower(string(relationType)))
+}
+
+func toBrand(brand *domain_model.Brand) *model.Brand {
+	res := &model.Brand{
+		Name:      brand.Name,
+		Aliases:   brand.Aliases,
+		Models:    make([]*model.DeviceModel, len(brand.Models)),
+		CreatedAt: brand.CreatedAt.String(),
+	}
+	if res.Aliases == nil {
+		res.Aliases = []string{}
+	}
+	for i, deviceModel := range brand.Models {
+		res.Models[i] = &model.DeviceModel{Name: deviceModel.Name, Aliases: deviceModel.Aliases}
+		if res.Models[i].Aliases == nil {
+			res.Models[i].Aliases = []string{}
+		}
+	}
+	return res
+}
+
+func toBrands(brands []domain_model.Brand) []*model.Brand {
+	res := make([]*model.Brand, len(brands))
+	for i := range brands {
+		res[i] = toBrand(&brands[i])
+	}
+	return res
+}
+
+func toDeviceType(deviceType *domain_model.DeviceType) (*model.DeviceType, error) {
+	var schema interface{}
+	if err := json.Unmarshal(deviceType.Schema, &schema); err != nil {
+		return nil, err
+	}
+	res := &model.DeviceType{
+		Name:      deviceType.Name,
+		Schema:    schema,
+		CreatedAt: deviceType.CreatedAt.String(),
+	}
+	if deviceType.Description != "" {
+		res.Description = &deviceType.Description
+	}
+	return res, nil
+}
+
+func toAssignment(assignment *domain_model.Assignment) *model.Assignment {
+	res := &model.Assignment{
+		ID:           assignment.ID,
+		DeviceID:     assignment.DeviceID,
+		Assignee:     assignment.Assignee,
+		CheckedOutAt: assignment.CheckedOutAt.String(),
+		Overdue:      assignment.Overdue(time.Now()),
+	}
+	if assignment.DueAt != nil {
+		dueAt := assignment.DueAt.String()
+		res.DueAt = &dueAt
+	}
+	if assignment.CheckedInAt != nil {
+		checkedInAt := assignment.CheckedInAt.String()
+		res.CheckedInAt = &checkedInAt
+	}
+	if assignment.Note != "" {
+		res.Note = &assignment.Note
+	}
+	if assignment.CheckedOutBy != "" {
+		res.CheckedOutBy = &assignment.CheckedOutBy
+	}
+	return res
+}
+
+func toAssignments(assignments []domain_model.Assignment) []*model.Assignment {
+	res := make([]*model.Assignment, len(assignments))
+	for i := range assignments {
+		res[i] = toAssignment(&assignments[i])
+	}
+	return res
+}
+
+func toCheckOutRequest(assignee string, dueAt *string, note *string) (*domain_model.CheckOutRequest, error) {
+	request := &domain_model.CheckOutRequest{Assignee: assignee}
+	if dueAt != nil {
+		parsed, err := time.Parse(time.RFC3339, *dueAt)
+		if err != nil {
+			return nil, fmt.Errorf("%w: dueAt must be an RFC 3339 timestamp", domain_model.ErrInvalidAssignment)
+		}
+		request.DueAt = &parsed
+	}
+	if note != nil {
+		request.Note = *note
+	}
+	return request, nil
+}
+
+// maxMaintenanceDays bounds the days argument of the maintenance task lists.
+const maxMaintenanceDays = 366
+
+func toMaintenancePlan(plan *domain_model.MaintenancePlan) *model.MaintenancePlan {
+	res := &model.MaintenancePlan{
+		ID:        plan.ID,
+		DeviceID:  plan.DeviceID,
+		Name:      plan.Name,
+		Kind:      model.MaintenanceKind(strings.ToUpper(string(plan.Kind))),
+		Every:     plan.Every,
+		Unit:      model.RecurrenceUnit(strings.ToUpper(string(plan.Unit))),
+		StartsAt:  plan.StartsAt.String(),
+		CreatedAt: plan.CreatedAt.String(),
+	}
+	if plan.Description != "" {
+		res.Description = &plan.Description
+	}
+	if plan.NextDueAt != nil {
+		nextDueAt := plan.NextDueAt.String()
+		res.NextDueAt = &nextDueAt
+	}
+	if plan.CreatedBy != "" {
+		res.CreatedBy = &plan.CreatedBy
+	}
+	return res
+}
+
+func toMaintenancePlans(plans []domain_model.MaintenancePlan) []*model.MaintenancePlan {
+	res := make([]*model.MaintenancePlan, len(plans))
+	for i := range plans {
+		res[i] = toMaintenancePlan(&plans[i])
+	}
+	return res
+}
+
+func toServiceRecord(record *domain_model.ServiceRecord) *model.ServiceRecord {
+	res := &model.ServiceRecord{
+		ID:          record.ID,
+		DeviceID:    record.DeviceID,
+		Kind:        model.ServiceKind(strings.ToUpper(string(record.Kind))),
+		PerformedAt: record.PerformedAt.String(),
+		Cost:        record.Cost,
+	}
+	if record.PlanID != "" {
+		res.PlanID = &record.PlanID
+	}
+	if record.DueAt != nil {
+		dueAt := record.DueAt.String()
+		res.DueAt = &dueAt
+	}
+	if record.PerformedBy != "" {
+		res.PerformedBy = &record.PerformedBy
+	}
+	if record.Currency != "" {
+		res.Currency = &record.Currency
+	}
+	if record.Notes != "" {
+		res.Notes = &record.Notes
+	}
+	if record.RecordedBy != "" {
+		res.RecordedBy = &record.RecordedBy
+	}
+	return res
+}
+
+func toServiceRecords(records []domain_model.ServiceRecord) []*model.ServiceRecord {
+	res := make([]*model.ServiceRecord, len(records))
+	for i := range records {
+		res[i] = toServiceRecord(&records[i])
+	}
+	return res
+}
+
+func toMaintenanceTasks(tasks []domain_model.MaintenanceTask) []*model.MaintenanceTask {
+	res := make([]*model.MaintenanceTask, len(tasks))
+	for i, task := range tasks {
+		res[i] = &model.MaintenanceTask{
+			PlanID:         task.PlanID,
+			PlanName:       task.PlanName,
+			DeviceID:       task.DeviceID,
+			DueAt:          task.DueAt.String(),
+			Overdue:        task.Overdue,
+			DeviceInRepair: task.DeviceInRepair,
+		}
+	}
+	return res
+}
+
+func toMaintenancePlanRequest(input model.NewMaintenancePlan) (*domain_model.NewMaintenancePlanRequest, error) {
+	request := &domain_model.NewMaintenancePlanRequest{
+		Name:  input.Name,
+		Kind:  domain_model.MaintenanceKind(strings.ToLower(string(input.Kind))),
+		Every: input.Every,
+		Unit:  domain_model.RecurrenceUnit(strings.ToLower(string(input.Unit))),
+	}
+	if input.Description != nil {
+		request.Description = *input.Description
+	}
+	if input.StartsAt != nil {
+		parsed, err := time.Parse(time.RFC3339, *input.StartsAt)
+		if err != nil {
+			return nil, fmt.Errorf("%w: startsAt must be an RFC 3339 timestamp", domain_model.ErrInvalidMaintenancePlan)
+		}
+		request.StartsAt = &parsed
+	}
+	return request, nil
+}
+
+func toServiceRecordRequest(input model.NewServiceRecord) (*domain_model.ServiceRecordRequest, error) {
+	request := &domain_model.ServiceRecordRequest{}
+	if input.Kind != nil {
+		request.Kind = domain_model.ServiceKind(strings.ToLower(string(*input.Kind)))
+	}
+	if input.PlanID != nil {
+		request.PlanID = *input.PlanID
+	}
+	if input.PerformedAt != nil {
+		parsed, err := time.Parse(time.RFC3339, *input.PerformedAt)
+		if err != nil {
+			return nil, fmt.Errorf("%w: performedAt must be an RFC 3339 timestamp", domain_model.ErrInvalidServiceRecord)
+		}
+		request.PerformedAt = &parsed
+	}
+	if input.PerformedBy != nil {
+		request.PerformedBy = *input.PerformedBy
+	}
+	if input.Cost != nil {
+		request.Cost = *input.Cost
+	}
+	if input.Currency != nil {
+		request.Currency = *input.Currency
+	}
+	if input.Notes != nil {
+		request.Notes = *input.Notes
+	}
+	return request, nil
+}
+
+// toContract converts a contract, active and daysLeft are as of now.
+func toContract(contract *domain_model.Contract) *model.Contract {
+	now := time.Now()
+	res := &model.Contract{
+		ID:        contract.ID,
+		DeviceID:  contract.DeviceID,
+		Kind:      model.ContractKind(strings.ToUpper(string(contract.Kind))),
+		Provider:  contract.Provider,
+		StartsAt:  contract.StartsAt.String(),
+		EndsAt:    contract.EndsAt.String(),
+		Coverage:  contract.Coverage,
+		Active:    contract.Active(now),
+		DaysLeft:  contract.DaysLeft(now),
+		CreatedAt: contract.CreatedAt.String(),
+	}
+	if res.Coverage == nil {
+		res.Coverage = []string{}
+	}
+	if contract.Reference != "" {
+		res.Reference = &contract.Reference
+	}
+	if contract.CreatedBy != "" {
+		res.CreatedBy = &contract.CreatedBy
+	}
+	return res
+}
+
+func fromContractKind(kind model.ContractKind) domain_model.ContractKind {
+	return domain_model.ContractKind(strings.ToLower(string(kind)))
+}
+
+func toContracts(contracts []domain_model.Contract) []*model.Contract {
+	res := make([]*model.Contract, len(contracts))
+	for i := range contracts {
+		res[i] = toContract(&contracts[i])
+	}
+	return res
+}
+
+func toExpiringContracts(contracts []domain_model.ExpiringContract) []*model.ExpiringContract {
+	res := make([]*model.ExpiringContract, len(contracts))
+	for i := range contracts {
+		res[i] = &model.ExpiringContract{
+			Contract: toContract(&contracts[i].Contract),
+			DaysLeft: contracts[i].DaysLeft,
+			Renewed:  contracts[i].Renewed,
+		}
+	}
+	return res
+}
+
+func toContractRequest(input model.NewContract) (*domain_model.ContractRequest, error) {
+	request := &domain_model.ContractRequest{
+		Kind:     fromContractKind(input.Kind),
+		Provider: input.Provider,
+		Coverage: input.Coverage,
+	}
+	if input.Reference != nil {
+		request.Reference = *input.Reference
+	}
+	var err error
+	if request.StartsAt, err = time.Parse(time.RFC3339, input.StartsAt); err != nil {
+		return nil, fmt.Errorf("%w: startsAt must be an RFC 3339 timestamp", domain_model.ErrInvalidContract)
+	}
+	if request.EndsAt, err = time.Parse(time.RFC3339, input.EndsAt); err != nil {
+		return nil, fmt.Errorf("%w: endsAt must be an RFC 3339 timestamp", domain_model.ErrInvalidContract)
+	}
+	return request, nil
+}
+
+func toReading(reading *domain_model.Reading) *model.Reading {
+	return &model.Reading{
+		Metric: reading.Metric,
+		Value:  reading.Value,
+		At:     reading.At.String(),
+	}
+}
+
+func toReadings(readings []domain_model.Reading) []*model.Reading {
+	res := make([]*model.Reading, len(readings))
+	for i := range readings {
+		res[i] = toReading(&readings[i])
+	}
+	return res
+}
+
+// maintenanceHorizon returns the end of the days argument from now.
+func toConnectivity(connectivity domain_model.Connectivity) model.Connectivity {
+	return model.Connectivity(strings.ToUpper(string(connectivity)))
+}
+
+func fromConnectivity(connectivity model.Connectivity) domain_model.Connectivity {
+	return domain_model.Connectivity(strings.ToLower(string(connectivity)))
+}
+
+func toSoftware(software *domain_model.InstalledSoftware) *model.Software {
+	return &model.Software{
+		ID:          software.ID,
+		Kind:        model.SoftwareKind(strings.ToUpper(string(software.Kind))),
+		Name:        software.Name,
+		Version:     software.Version,
+		InstalledAt: software.InstalledAt.String(),
+	}
+}
+
+func toSoftwareList(software []domain_model.InstalledSoftware) []*model.Software {
+	res := make([]*model.Software, len(software))
+	for i := range software {
+		res[i] = toSoftware(&software[i])
+	}
+	return res
+}
+
+func fromSoftwareKind(kind model.SoftwareKind) domain_model.SoftwareKind {
+	return domain_model.SoftwareKind(strings.ToLower(string(kind)))
+}
+
+func toSoftwareRequest(input model.NewSoftware) (*domain_model.SoftwareRequest, error) {
+	request := &domain_model.SoftwareRequest{
+		Kind:    fromSoftwareKind(input.Kind),
+		Name:    input.Name,
+		Version: input.Version,
+	}
+	if input.InstalledAt != nil {
+		installedAt, err := time.Parse(time.RFC3339, *input.InstalledAt)
+		if err != nil {
+			return nil, fmt.Errorf("%w: installedAt must be an RFC 3339 timestamp", domain_model.ErrInvalidSoftware)
+		}
+		request.InstalledAt = &installedAt
+	}
+	return request, nil
+}
+
+func toSoftwarePolicy(policy *domain_model.SoftwarePolicy) *model.SoftwarePolicy {
+	res := &model.SoftwarePolicy{
+		ID:         policy.ID,
+		Kind:       model.SoftwareKind(strings.ToUpper(string(policy.Kind))),
+		MinVersion: policy.MinVersion,
+		CreatedAt:  policy.CreatedAt.String(),
+	}
+	if policy.Description != "" {
+		res.Description = &policy.Description
+	}
+	if policy.Brand != "" {
+		res.Brand = &policy.Brand
+	}
+	if policy.Type != "" {
+		res.Type = &policy.Type
+	}
+	if policy.Software != "" {
+		res.Software = &policy.Software
+	}
+	return res
+}
+
+func toSoftwarePolicies(policies []domain_model.SoftwarePolicy) []*model.SoftwarePolicy {
+	res := make([]*model.SoftwarePolicy, len(policies))
+	for i := range policies {
+		res[i] = toSoftwarePolicy(&policies[i])
+	}
+	return res
+}
+
+func toSoftwarePolicyRequest(input model.NewSoftwarePolicy) *domain_model.SoftwarePolicyRequest {
+	request := &domain_model.SoftwarePolicyRequest{MinVersion: input.MinVersion}
+	if input.Description != nil {
+		request.Description = *input.Description
+	}
+	if input.Brand != nil {
+		request.Brand = *input.Brand
+	}
+	if input.Type != nil {
+		request.Type = *input.Type
+	}
+	if input.Kind != nil {
+		request.Kind = fromSoftwareKind(*input.Kind)
+	}
+	if input.Software != nil {
+		request.Software = *input.Software
+	}
+	return request
+}
+
+func toDeviceCompliance(compliance *domain_model.DeviceCompliance) *model.DeviceCompliance {
+	res := &model.DeviceCompliance{
+		Device:     toDevice(&compliance.Device),
+		Compliant:  compliance.Compliant,
+		Policies:   compliance.Policies,
+		Violations: make([]*model.PolicyViolation, len(compliance.Violations)),
+	}
+	for i, violation := range compliance.Violations {
+		res.Violations[i] = &model.PolicyViolation{
+			Policy:   toSoftwarePolicy(&compliance.Violations[i].Policy),
+			Reason:   model.ViolationReason(strings.ToUpper(string(violation.Reason))),
+			Required: violation.Required,
+		}
+		if violation.Software != "" {
+			res.Violations[i].Software = &compliance.Violations[i].Software
+		}
+		if violation.Installed != "" {
+			res.Violations[i].Installed = &compliance.Violations[i].Installed
+		}
+	}
+	return res
+}
+
+func toComplianceReport(report *domain_model.ComplianceReport) *model.ComplianceReport {
+	res := &model.ComplianceReport{
+		CheckedAt: report.CheckedAt.String(),
+		Policies:  report.Policies,
+		Devices:   report.Devices,
+		Compliant: report.Compliant,
+		Violators: make([]*model.DeviceCompliance, len(report.Violators)),
+	}
+	for i := range report.Violators {
+		res.Violators[i] = toDeviceCompliance(&report.Violators[i])
+	}
+	return res
+}
+
+func toDuplicateCandidates(candidates []domain_model.DuplicateCandidate) []*model.DuplicateCandidate {
+	res := make([]*model.DuplicateCandidate, len(candidates))
+	for i := range candidates {
+		res[i] = &model.DuplicateCandidate{
+			Device:    toDevice(&candidates[i].Device),
+			Duplicate: toDevice(&candidates[i].Duplicate),
+			Score:     candidates[i].Score,
+		}
+		if candidates[i].Key != "" {
+			res[i].Key = &candidates[i].Key
+		}
+	}
+	return res
+}
+
+// duplicateThreshold defaults a missing threshold to the one of the service.
+func duplicateThreshold(threshold *float64) float64 {
+	if threshold == nil {
+		return 0
+	}
+	return *threshold
+}
+
+func maintenanceHorizon(days *int) (time.Time, error) {
+	if days == nil {
+		return time.Now().AddDate(0, 0, 30), nil
+	}
+	if *days < 0 || *days > maxMaintenanceDays {
+		return time.Time{}, fmt.Errorf("%w: days must be between 0 and %d", domain_model.ErrInvalidMaintenancePlan, maxMaintenanceDays)
+	}
+	return time.Now().AddDate(0, 0, *days), nil
+}
+
+// toAttributes converts a JSON input to device attributes, which must be an
+// object.
+func toAttributes(input interface{}) (domain_model.Attributes, error) {
+	if input == nil {
+		return nil, nil
+	}
+	attributes, ok := input.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%w: attributes must be an object", domain_model.ErrInvalidAttributes)
+	}
+	return attributes, nil
+}
+
+func attribute(device *model.Device, name string) interface{} {
+	attributes, _ := device.Attributes.(map[string]interface{})
+	return attributes[name]
 }
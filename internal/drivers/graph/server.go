@@ -4,34 +4,52 @@ import (
 	"devices_crud/internal/devices"
 	"devices_crud/internal/drivers/graph/generated"
 	"devices_crud/internal/drivers/graph/resolver"
-	"log"
-	"net/http"
+	"devices_crud/internal/logging"
+	"devices_crud/internal/tracing"
 
 	"github.com/99designs/gqlgen/graphql/handler"
 	"github.com/99designs/gqlgen/graphql/playground"
+	"github.com/gin-gonic/gin"
 )
 
-type GraphQLServer struct {
-	server *handler.Server
-	port   string
-}
+func NewServer(deviceDeps *devices.DependencyTree) *handler.Server {
+	logger := logging.Component(deviceDeps.Logger, "graphql")
+	srv := handler.NewDefaultServer(generated.NewExecutableSchema(generated.Config{
+		Resolvers: &resolver.Resolver{
+			DeviceService: deviceDeps.DeviceSerivce,
+			Logger:        logger,
+		},
+		Directives: generated.DirectiveRoot{
+			HasPermission: hasPermission(deviceDeps.Authorizer),
+		},
+	}))
+	srv.SetErrorPresenter(presentError(logger))
+
+	if deviceDeps.Metrics != nil {
+		srv.AroundOperations(observeOperations(deviceDeps.Metrics))
+	}
+	if deviceDeps.TracerProvider != nil {
+		tracer := tracing.Tracer(deviceDeps.TracerProvider, "graphql")
+		srv.AroundOperations(traceOperations(tracer))
+		srv.AroundRootFields(traceRootFields(tracer))
+	}
+	srv.AroundOperations(logOperations())
 
-func RunServer(deviceDeps *devices.DependencyTree) *GraphQLServer {
-	port := "8080"
-	srv := handler.NewDefaultServer(generated.NewExecutableSchema(generated.Config{Resolvers: &resolver.Resolver{
-		DeviceService: deviceDeps.DeviceSerivce,
-		Logger:        deviceDeps.Logger,
-	}}))
-
-	http.Handle("/", playground.Handler("GraphQL playground", "/query"))
-	http.Handle("/query", srv)
-	log.Fatal(http.ListenAndServe(":"+port, nil))
-	return &GraphQLServer{
-		server: srv,
-		port:   port,
+	if deviceDeps.Authenticator != nil {
+		srv.AroundOperations(authenticateOperations(deviceDeps.Authenticator, logger))
 	}
+	srv.AroundOperations(resolveOperationTenant(deviceDeps.Authorizer, logger))
+	if deviceDeps.RateLimiter != nil {
+		srv.AroundOperations(rateLimitOperations(deviceDeps.RateLimiter, logger))
+	}
+
+	return srv
 }
 
-func (gqls *GraphQLServer) Run() {
-	log.Fatal(http.ListenAndServe(":"+gqls.port, nil))
+func BuildRoutes(router *gin.Engine, deviceDeps *devices.DependencyTree) {
+	srv := NewServer(deviceDeps)
+
+	router.GET("/", gin.WrapH(playground.Handler("GraphQL playground", "/query")))
+	router.GET("/query", serve(srv))
+	router.POST("/query", serve(srv))
 }
@@ -0,0 +1,56 @@
+package graph
+
+import (
+	"context"
+
+	"github.com/99designs/gqlgen/graphql"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// traceOperations wraps every operation in a span. The span is a child of the
+// HTTP request span, which already carries the caller's traceparent.
+func traceOperations(tracer trace.Tracer) graphql.OperationMiddleware {
+	return func(ctx context.Context, next graphql.OperationHandler) graphql.ResponseHandler {
+		oc := graphql.GetOperationContext(ctx)
+		name := operationName(oc)
+		operationType := "query"
+		if oc.Operation != nil {
+			operationType = string(oc.Operation.Operation)
+		}
+
+		ctx, span := tracer.Start(ctx, operationType+" "+name, trace.WithAttributes(
+			semconv.GraphqlOperationName(name),
+			semconv.GraphqlOperationTypeKey.String(operationType),
+		))
+		handler := next(ctx)
+
+		return func(_ context.Context) *graphql.Response {
+			response := handler(ctx)
+			if response == nil {
+				return nil
+			}
+			if len(response.Errors) > 0 {
+				span.SetStatus(codes.Error, response.Errors.Error())
+			}
+			span.End()
+			return response
+		}
+	}
+}
+
+// traceRootFields wraps every top-level resolver in a span, separating the
+// resolver from the rest of the operation.
+func traceRootFields(tracer trace.Tracer) graphql.RootFieldMiddleware {
+	return func(ctx context.Context, next graphql.RootResolver) graphql.Marshaler {
+		fc := graphql.GetRootFieldContext(ctx)
+		object := fc.Object
+		if fc.Field.ObjectDefinition != nil {
+			object = fc.Field.ObjectDefinition.Name
+		}
+		ctx, span := tracer.Start(ctx, object+"."+fc.Field.Name)
+		defer span.End()
+		return next(ctx)
+	}
+}
@@ -0,0 +1,31 @@
+package rest
+
+import (
+	"devices_crud/internal/health"
+	"log/slog"
+
+	"github.com/gin-gonic/gin"
+)
+
+// healthz reports that the process is alive and serving requests. It checks
+// nothing else, so that a failing dependency does not get the pod restarted.
+func healthz(c *gin.Context) {
+	c.JSON(200, gin.H{
+		"status": health.StatusOK,
+	})
+}
+
+// readyz reports whether every component is ready, with the outcome of each
+// check, so traffic is only routed to instances that can serve it.
+func readyz(checker *health.Checker, logger *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		report := checker.Check(c.Request.Context())
+		if report.Status != health.StatusOK {
+			logger.WarnContext(c.Request.Context(), "Not ready", "components", report.Components)
+			c.JSON(503, report)
+			return
+		}
+
+		c.JSON(200, report)
+	}
+}
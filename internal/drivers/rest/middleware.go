@@ -0,0 +1,197 @@
+package rest
+
+import (
+	"devices_crud/internal/auth"
+	"devices_crud/internal/logging"
+	"devices_crud/internal/metrics"
+	"devices_crud/internal/ratelimit"
+	"devices_crud/internal/tenancy"
+	"devices_crud/internal/tracing"
+	"log/slog"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const requestIDHeader = "X-Request-ID"
+
+// authenticate rejects requests without valid credentials and stores the
+// principal in the request context, from where it reaches the device service.
+func authenticate(authenticator auth.Authenticator, logger *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, err := authenticator.Authenticate(c.Request.Context(), c.Request.Header)
+		if err != nil {
+			logger.InfoContext(c.Request.Context(), "Authentication failed", "error", err)
+			c.Header("WWW-Authenticate", `Bearer realm="devices"`)
+			c.AbortWithStatusJSON(401, gin.H{
+				"message": "Unauthorized",
+				"code":    "UNAUTHENTICATED",
+			})
+			return
+		}
+
+		ctx := auth.WithPrincipal(c.Request.Context(), principal)
+		ctx = logging.WithAttrs(ctx, slog.String(logging.PrincipalKey, principal.Method+":"+principal.Subject))
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// authorize rejects requests of principals lacking permission with a 403. A nil
+// authorizer allows every request.
+func authorize(authorizer *auth.Authorizer, permission auth.Permission, logger *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if authorizer == nil {
+			c.Next()
+			return
+		}
+		if err := authorizer.Authorize(c.Request.Context(), permission); err != nil {
+			logger.WarnContext(c.Request.Context(), "Permission denied", "permission", string(permission), "error", err)
+			c.AbortWithStatusJSON(403, gin.H{
+				"message": "Forbidden",
+				"code":    "FORBIDDEN",
+			})
+			return
+		}
+		c.Next()
+	}
+}
+
+// resolveTenant scopes the request to the tenant of its principal or, for
+// principals not bound to a tenant, to the one selected by the tenant header.
+func resolveTenant(authorizer *auth.Authorizer, logger *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, err := tenancy.Resolve(c.Request.Context(), c.GetHeader(tenancy.Header), authorizer)
+		if err != nil {
+			logger.WarnContext(c.Request.Context(), "Tenant rejected", "error", err)
+			c.AbortWithStatusJSON(403, gin.H{
+				"message": "Forbidden",
+				"code":    "FORBIDDEN",
+			})
+			return
+		}
+
+		ctx = logging.WithAttrs(ctx, slog.String(logging.TenantKey, tenancy.TenantFromContext(ctx)))
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// rateLimit rejects requests of clients that exceeded the limit of the route
+// with a 429. Store failures let the request through.
+func rateLimit(limiter *ratelimit.Limiter, group string, logger *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		route := c.Request.Method + " " + c.FullPath()
+
+		result, err := limiter.AllowRoute(ctx, group, route, ratelimit.ClientKey(ctx, c.ClientIP()))
+		if err != nil {
+			logger.ErrorContext(ctx, "Error rate limiting request", "error", err)
+			c.Next()
+			return
+		}
+
+		ratelimit.SetHeaders(c.Writer.Header(), result)
+		if !result.Allowed {
+			logger.InfoContext(ctx, "Rate limit exceeded")
+			c.AbortWithStatusJSON(429, gin.H{
+				"message": "Too many requests",
+				"code":    "RATE_LIMITED",
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// traceRequests starts a server span for every request, continuing the trace
+// of the caller's traceparent header, and hands it down through the request
+// context.
+func traceRequests(provider trace.TracerProvider) gin.HandlerFunc {
+	tracer := tracing.Tracer(provider, "rest")
+	return func(c *gin.Context) {
+		ctx := tracing.Propagator.Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		ctx, span := tracer.Start(ctx, c.Request.Method+" "+route,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(c.Request.Method),
+				semconv.HTTPRoute(route),
+			),
+		)
+		defer span.End()
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+
+		status := c.Writer.Status()
+		span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+		if status >= 500 {
+			span.SetStatus(codes.Error, "")
+		}
+	}
+}
+
+// observeRequests records the count and latency of every request labelled by
+// its route template, so that device IDs do not end up in label values.
+func observeRequests(m *metrics.Metrics) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		m.ObserveHTTPRequest(c.Request.Method, route, c.Writer.Status(), time.Since(start))
+	}
+}
+
+// logRequests gives every request an ID, taken from the X-Request-ID header
+// or generated, adds it and the route to the log records of the request and
+// logs the request once it is served.
+func logRequests(logger *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		requestID := c.GetHeader(requestIDHeader)
+		if requestID == "" {
+			requestID = uuid.New().String()
+		}
+		c.Header(requestIDHeader, requestID)
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		ctx := logging.WithAttrs(c.Request.Context(),
+			slog.String(logging.RequestIDKey, requestID),
+			slog.String(logging.RouteKey, c.Request.Method+" "+route),
+		)
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+
+		status := c.Writer.Status()
+		level := slog.LevelInfo
+		if status >= 500 {
+			level = slog.LevelError
+		} else if route == "/healthz" || route == "/readyz" {
+			// Probes hit these every few seconds.
+			level = slog.LevelDebug
+		}
+		logger.Log(c.Request.Context(), level, "Request served",
+			"status", status,
+			"duration", time.Since(start),
+			"client_ip", c.ClientIP(),
+		)
+	}
+}
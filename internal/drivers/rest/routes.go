@@ -1,16 +1,56 @@
 package rest
 
 import (
+	"devices_crud/internal/auth"
 	"devices_crud/internal/devices"
+	"devices_crud/internal/logging"
 
 	"github.com/gin-gonic/gin"
 )
 
 func BuildRoutes(router *gin.Engine, devicesDeps *devices.DependencyTree) {
+	logger := logging.Component(devicesDeps.Logger, "rest")
+	router.Use(logRequests(logger))
+	if devicesDeps.TracerProvider != nil {
+		router.Use(traceRequests(devicesDeps.TracerProvider))
+	}
+	if devicesDeps.Metrics != nil {
+		router.Use(observeRequests(devicesDeps.Metrics))
+		// Metrics count the devices of every tenant, so only administrators
+		// may scrape them once the API is authenticated.
+		handlers := []gin.HandlerFunc{}
+		if devicesDeps.Authenticator != nil {
+			handlers = append(handlers,
+				authenticate(devicesDeps.Authenticator, logger),
+				authorize(devicesDeps.Authorizer, auth.PermissionDevicesAdmin, logger),
+			)
+		}
+		router.GET("/metrics", append(handlers, gin.WrapH(devicesDeps.Metrics.Handler()))...)
+	}
+
 	router.GET("/ping", ping)
-	devicesPath := router.Group("/v1/devices")
+	router.GET("/healthz", healthz)
+	router.GET("/readyz", readyz(devicesDeps.Health, logger))
+	api := func(path string) *gin.RouterGroup {
+		group := router.Group(path)
+		if devicesDeps.Authenticator != nil {
+			group.Use(authenticate(devicesDeps.Authenticator, logger))
+		}
+		group.Use(resolveTenant(devicesDeps.Authorizer, logger))
+		if devicesDeps.RateLimiter != nil {
+			group.Use(rateLimit(devicesDeps.RateLimiter, "devices", logger))
+		}
+		return group
+	}
 
-	devices.BuildRoutes(devicesPath, devicesDeps)
+	devices.BuildRoutes(api("/v1/devices"), devicesDeps)
+	devices.BuildDeviceTypesRoutes(api("/v1/device-types"), devicesDeps)
+	devices.BuildAssignmentsRoutes(api("/v1/assignments"), devicesDeps)
+	devices.BuildLocationsRoutes(api("/v1/locations"), devicesDeps)
+	devices.BuildBrandsRoutes(api("/v1/brands"), devicesDeps)
+	devices.BuildMaintenanceRoutes(api("/v1/maintenance"), devicesDeps)
+	devices.BuildContractsRoutes(api("/v1/contracts"), devicesDeps)
+	devices.BuildComplianceRoutes(api("/v1/compliance"), devicesDeps)
 }
 
 func ping(c *gin.Context) {
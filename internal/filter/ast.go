@@ -0,0 +1,141 @@
+// Package filter parses filter expressions such as
+//
+//	brand = "Apple" AND createdAt > 2024-01-01 AND name ~ "lab-*"
+//
+// into an AST, and compiles them to predicates over in-memory records or to
+// parameterized SQL.
+//
+// Comparisons are combined with AND, OR and NOT (case-insensitive, NOT binds
+// tightest, then AND, then OR) and grouped with parentheses. The operators are
+// =, !=, <, <=, >, >=, ~ and !~, where ~ matches a case-insensitive glob
+// pattern with * and ? wildcards. Values are double-quoted strings, numbers,
+// true and false, and dates (2024-01-01) or timestamps (2024-01-01T10:00:00Z).
+package filter
+
+import (
+	"fmt"
+	"time"
+)
+
+// Expr is a node of the AST: *Binary, *Not or *Comparison.
+type Expr interface {
+	// Pos is the 1-based position of the node in the input.
+	Pos() int
+	String() string
+}
+
+type LogicalOp string
+
+const (
+	And LogicalOp = "AND"
+	Or  LogicalOp = "OR"
+)
+
+type Binary struct {
+	Op          LogicalOp
+	Left, Right Expr
+	Position    int
+}
+
+func (b *Binary) Pos() int { return b.Position }
+
+func (b *Binary) String() string {
+	return fmt.Sprintf("(%s %s %s)", b.Left, b.Op, b.Right)
+}
+
+type Not struct {
+	Expr     Expr
+	Position int
+}
+
+func (n *Not) Pos() int { return n.Position }
+
+func (n *Not) String() string {
+	return fmt.Sprintf("NOT %s", n.Expr)
+}
+
+type Operator string
+
+const (
+	Equal        Operator = "="
+	NotEqual     Operator = "!="
+	Less         Operator = "<"
+	LessEqual    Operator = "<="
+	Greater      Operator = ">"
+	GreaterEqual Operator = ">="
+	Match        Operator = "~"
+	NotMatch     Operator = "!~"
+)
+
+type Comparison struct {
+	Field    string
+	Op       Operator
+	Value    Value
+	Position int
+	// ValuePosition is where the value starts, for errors about the value.
+	ValuePosition int
+}
+
+func (c *Comparison) Pos() int { return c.Position }
+
+func (c *Comparison) String() string {
+	return fmt.Sprintf("%s %s %s", c.Field, c.Op, c.Value)
+}
+
+type Kind int
+
+const (
+	KindString Kind = iota
+	KindNumber
+	KindTime
+	KindBool
+	// KindAny is the kind of schema-less fields, such as free-form attributes.
+	// Comparisons take the kind of their value and fail on records holding a
+	// value of another kind.
+	KindAny
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindNumber:
+		return "number"
+	case KindTime:
+		return "date"
+	case KindBool:
+		return "boolean"
+	case KindAny:
+		return "value"
+	default:
+		return "string"
+	}
+}
+
+// Value is a literal of the expression. Only the field of its kind is set.
+type Value struct {
+	Kind   Kind
+	Text   string
+	Number float64
+	Time   time.Time
+	Bool   bool
+}
+
+func (v Value) String() string {
+	switch v.Kind {
+	case KindNumber:
+		return fmt.Sprint(v.Number)
+	case KindTime:
+		return v.Time.Format(time.RFC3339)
+	case KindBool:
+		return fmt.Sprint(v.Bool)
+	default:
+		return fmt.Sprintf("%q", v.Text)
+	}
+}
+
+func StringValue(s string) Value { return Value{Kind: KindString, Text: s} }
+
+func NumberValue(n float64) Value { return Value{Kind: KindNumber, Number: n} }
+
+func TimeValue(t time.Time) Value { return Value{Kind: KindTime, Time: t} }
+
+func BoolValue(b bool) Value { return Value{Kind: KindBool, Bool: b} }
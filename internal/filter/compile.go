@@ -0,0 +1,241 @@
+package filter
+
+import (
+	"sort"
+	"strings"
+)
+
+// Schema declares the fields an expression may use and their kinds. Field
+// names are matched case-insensitively. A name ending in ".*" declares a family
+// of dynamic fields, e.g. "attributes.*" accepts "attributes.serial".
+type Schema map[string]Kind
+
+// resolve returns the declared name and kind of a field. Dynamic fields keep
+// the key given in the expression. Declared names win over dynamic ones, and
+// longer prefixes over shorter ones, whatever the order of the map.
+func (s Schema) resolve(name string) (string, Kind, bool) {
+	if kind, ok := s[name]; ok && !strings.HasSuffix(name, ".*") {
+		return name, kind, true
+	}
+	for _, declared := range s.declarations() {
+		kind := s[declared]
+		if prefix, dynamic := strings.CutSuffix(declared, "*"); dynamic {
+			if len(name) > len(prefix) && strings.EqualFold(name[:len(prefix)], prefix) {
+				return prefix + name[len(prefix):], kind, true
+			}
+		} else if strings.EqualFold(declared, name) {
+			return declared, kind, true
+		}
+	}
+	return "", 0, false
+}
+
+// dynamicField splits a field resolved from a dynamic declaration into the
+// declaration and the key.
+func (s Schema) dynamicField(field string) (string, string, bool) {
+	for _, declared := range s.declarations() {
+		if prefix, dynamic := strings.CutSuffix(declared, "*"); dynamic && strings.HasPrefix(field, prefix) {
+			return declared, field[len(prefix):], true
+		}
+	}
+	return "", "", false
+}
+
+// declarations lists the names of the schema in the order fields are resolved
+// against them: plain names alphabetically, then dynamic ones by descending
+// prefix length.
+func (s Schema) declarations() []string {
+	names := make([]string, 0, len(s))
+	for name := range s {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		iDynamic, jDynamic := strings.HasSuffix(names[i], "*"), strings.HasSuffix(names[j], "*")
+		if iDynamic != jDynamic {
+			return jDynamic
+		}
+		if iDynamic && len(names[i]) != len(names[j]) {
+			return len(names[i]) > len(names[j])
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
+
+// Record exposes the fields of one record to a Predicate, by their names in
+// the Schema.
+type Record interface {
+	Field(name string) (Value, bool)
+}
+
+type Predicate func(Record) bool
+
+// Compile checks expr against schema and turns it into a predicate. Errors are
+// *SyntaxError pointing at the offending field or value.
+func Compile(expr Expr, schema Schema) (Predicate, error) {
+	switch e := expr.(type) {
+	case *Binary:
+		left, err := Compile(e.Left, schema)
+		if err != nil {
+			return nil, err
+		}
+		right, err := Compile(e.Right, schema)
+		if err != nil {
+			return nil, err
+		}
+		if e.Op == And {
+			return func(r Record) bool { return left(r) && right(r) }, nil
+		}
+		return func(r Record) bool { return left(r) || right(r) }, nil
+	case *Not:
+		inner, err := Compile(e.Expr, schema)
+		if err != nil {
+			return nil, err
+		}
+		return func(r Record) bool { return !inner(r) }, nil
+	case *Comparison:
+		field, value, err := check(e, schema)
+		if err != nil {
+			return nil, err
+		}
+		return func(r Record) bool {
+			actual, ok := r.Field(field)
+			if !ok {
+				return false
+			}
+			return compare(actual, e.Op, value)
+		}, nil
+	default:
+		return nil, errorAt(expr.Pos(), "unsupported expression %s", expr)
+	}
+}
+
+// check resolves the field of a comparison and converts its value to the kind
+// of the field.
+func check(c *Comparison, schema Schema) (string, Value, error) {
+	field, kind, ok := schema.resolve(c.Field)
+	if !ok {
+		return "", Value{}, errorAt(c.Position, "unknown field %q", c.Field)
+	}
+
+	value := c.Value
+	if kind == KindAny {
+		kind = value.Kind
+	}
+	if kind == KindTime && value.Kind == KindString {
+		// Quoted timestamps are accepted as well.
+		parsed, ok := parseTime(value.Text)
+		if !ok {
+			return "", Value{}, errorAt(c.ValuePosition, "invalid date %q", value.Text)
+		}
+		value = Value{Kind: KindTime, Time: parsed}
+	}
+	if value.Kind != kind {
+		return "", Value{}, errorAt(c.ValuePosition, "field %q expects a %s, found %s", field, kind, value.Kind)
+	}
+	if (c.Op == Match || c.Op == NotMatch) && kind != KindString {
+		return "", Value{}, errorAt(c.ValuePosition, "operator %s only applies to strings", c.Op)
+	}
+	if kind == KindBool && c.Op != Equal && c.Op != NotEqual {
+		return "", Value{}, errorAt(c.ValuePosition, "operator %s does not apply to booleans", c.Op)
+	}
+	return field, value, nil
+}
+
+// coerce converts actual to the kind of the expected value. Dynamic fields may
+// hold dates as strings.
+func coerce(actual Value, kind Kind) (Value, bool) {
+	if actual.Kind == kind {
+		return actual, true
+	}
+	if kind == KindTime && actual.Kind == KindString {
+		if parsed, ok := parseTime(actual.Text); ok {
+			return TimeValue(parsed), true
+		}
+	}
+	return Value{}, false
+}
+
+func compare(actual Value, op Operator, expected Value) bool {
+	actual, ok := coerce(actual, expected.Kind)
+	if !ok {
+		// Values of different kinds are never equal nor ordered.
+		return op == NotEqual || op == NotMatch
+	}
+
+	switch op {
+	case Match:
+		return matchGlob(strings.ToLower(expected.Text), strings.ToLower(actual.Text))
+	case NotMatch:
+		return !matchGlob(strings.ToLower(expected.Text), strings.ToLower(actual.Text))
+	}
+
+	var order int
+	switch expected.Kind {
+	case KindNumber:
+		order = compareOrdered(actual.Number, expected.Number)
+	case KindTime:
+		order = actual.Time.Compare(expected.Time)
+	case KindBool:
+		if actual.Bool != expected.Bool {
+			order = 1
+		}
+	default:
+		order = strings.Compare(actual.Text, expected.Text)
+	}
+
+	switch op {
+	case Equal:
+		return order == 0
+	case NotEqual:
+		return order != 0
+	case Less:
+		return order < 0
+	case LessEqual:
+		return order <= 0
+	case Greater:
+		return order > 0
+	default:
+		return order >= 0
+	}
+}
+
+func compareOrdered(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// matchGlob reports whether s matches pattern, where * matches any run of
+// characters and ? any single character.
+func matchGlob(pattern, s string) bool {
+	p, t := []rune(pattern), []rune(s)
+	pi, ti := 0, 0
+	star, mark := -1, 0
+
+	for ti < len(t) {
+		switch {
+		case pi < len(p) && (p[pi] == '?' || p[pi] == t[ti]):
+			pi++
+			ti++
+		case pi < len(p) && p[pi] == '*':
+			star, mark = pi, ti
+			pi++
+		case star >= 0:
+			pi = star + 1
+			mark++
+			ti = mark
+		default:
+			return false
+		}
+	}
+	for pi < len(p) && p[pi] == '*' {
+		pi++
+	}
+	return pi == len(p)
+}
@@ -0,0 +1,157 @@
+package filter
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenString
+	tokenLiteral // numbers, dates and timestamps
+	tokenOperator
+	tokenAnd
+	tokenOr
+	tokenNot
+	tokenLeftParen
+	tokenRightParen
+)
+
+func (k tokenKind) String() string {
+	switch k {
+	case tokenEOF:
+		return "end of input"
+	case tokenIdent:
+		return "field"
+	case tokenString:
+		return "string"
+	case tokenLiteral:
+		return "value"
+	case tokenOperator:
+		return "operator"
+	case tokenAnd:
+		return "AND"
+	case tokenOr:
+		return "OR"
+	case tokenNot:
+		return "NOT"
+	case tokenLeftParen:
+		return "'('"
+	default:
+		return "')'"
+	}
+}
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// SyntaxError reports the 1-based position in the input where parsing or
+// compiling failed.
+type SyntaxError struct {
+	Position int
+	Message  string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("syntax error at position %d: %s", e.Position, e.Message)
+}
+
+func errorAt(pos int, format string, args ...interface{}) *SyntaxError {
+	return &SyntaxError{Position: pos, Message: fmt.Sprintf(format, args...)}
+}
+
+func lex(input string) ([]token, error) {
+	runes := []rune(input)
+	var tokens []token
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		pos := i + 1
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, token{kind: tokenLeftParen, text: "(", pos: pos})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{kind: tokenRightParen, text: ")", pos: pos})
+			i++
+		case r == '"':
+			text, next, err := lexString(runes, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: tokenString, text: text, pos: pos})
+			i = next
+		case strings.ContainsRune("=!<>~", r):
+			op := string(r)
+			if i+1 < len(runes) && (runes[i+1] == '=' || (r == '!' && runes[i+1] == '~')) {
+				op += string(runes[i+1])
+			}
+			if op == "!" {
+				return nil, errorAt(pos, "unexpected '!', expected != or !~")
+			}
+			tokens = append(tokens, token{kind: tokenOperator, text: op, pos: pos})
+			i += len([]rune(op))
+		case unicode.IsDigit(r) || r == '-':
+			start := i
+			for i < len(runes) && isLiteralRune(runes[i]) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenLiteral, text: string(runes[start:i]), pos: pos})
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_' || runes[i] == '.' || runes[i] == '-') {
+				i++
+			}
+			text := string(runes[start:i])
+			kind := tokenIdent
+			switch strings.ToUpper(text) {
+			case "AND":
+				kind = tokenAnd
+			case "OR":
+				kind = tokenOr
+			case "NOT":
+				kind = tokenNot
+			}
+			tokens = append(tokens, token{kind: kind, text: text, pos: pos})
+		default:
+			return nil, errorAt(pos, "unexpected character %q", r)
+		}
+	}
+
+	return append(tokens, token{kind: tokenEOF, pos: len(runes) + 1}), nil
+}
+
+// isLiteralRune accepts the characters of numbers and RFC 3339 timestamps.
+func isLiteralRune(r rune) bool {
+	return unicode.IsDigit(r) || strings.ContainsRune("-+.:TZtz", r)
+}
+
+// lexString reads a double-quoted string starting at runes[start], with \" and
+// \\ escapes, and returns its content and the index after the closing quote.
+func lexString(runes []rune, start int) (string, int, error) {
+	var text strings.Builder
+	for i := start + 1; i < len(runes); i++ {
+		switch runes[i] {
+		case '\\':
+			if i+1 < len(runes) {
+				i++
+				text.WriteRune(runes[i])
+			}
+		case '"':
+			return text.String(), i + 1, nil
+		default:
+			text.WriteRune(runes[i])
+		}
+	}
+	return "", 0, errorAt(start+1, "unterminated string")
+}
@@ -0,0 +1,171 @@
+package filter
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Parse parses a filter expression. Errors are *SyntaxError.
+func Parse(input string) (Expr, error) {
+	tokens, err := lex(input)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	if p.peek().kind == tokenEOF {
+		return nil, errorAt(p.peek().pos, "empty filter")
+	}
+
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if next := p.peek(); next.kind != tokenEOF {
+		return nil, errorAt(next.pos, "unexpected %s %q, expected AND or OR", next.kind, next.text)
+	}
+	return expr, nil
+}
+
+type parser struct {
+	tokens []token
+	next   int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.next]
+}
+
+func (p *parser) advance() token {
+	t := p.tokens[p.next]
+	if t.kind != tokenEOF {
+		p.next++
+	}
+	return t
+}
+
+func (p *parser) parseOr() (Expr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokenOr {
+		op := p.advance()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &Binary{Op: Or, Left: left, Right: right, Position: op.pos}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (Expr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokenAnd {
+		op := p.advance()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &Binary{Op: And, Left: left, Right: right, Position: op.pos}
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary() (Expr, error) {
+	switch t := p.peek(); t.kind {
+	case tokenNot:
+		p.advance()
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &Not{Expr: expr, Position: t.pos}, nil
+	case tokenLeftParen:
+		p.advance()
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.peek(); closing.kind != tokenRightParen {
+			return nil, errorAt(closing.pos, "expected ')' to close '(' at position %d", t.pos)
+		}
+		p.advance()
+		return expr, nil
+	default:
+		return p.parseComparison()
+	}
+}
+
+func (p *parser) parseComparison() (Expr, error) {
+	field := p.advance()
+	if field.kind != tokenIdent {
+		return nil, errorAt(field.pos, "expected field, found %s", describe(field))
+	}
+
+	op := p.advance()
+	if op.kind != tokenOperator {
+		return nil, errorAt(op.pos, "expected operator after %q, found %s", field.text, describe(op))
+	}
+
+	literal := p.advance()
+	value, err := parseValue(literal)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Comparison{
+		Field:         field.text,
+		Op:            Operator(op.text),
+		Value:         value,
+		Position:      field.pos,
+		ValuePosition: literal.pos,
+	}, nil
+}
+
+func parseValue(t token) (Value, error) {
+	switch t.kind {
+	case tokenString:
+		return Value{Kind: KindString, Text: t.text}, nil
+	case tokenLiteral:
+		if number, err := strconv.ParseFloat(t.text, 64); err == nil {
+			return Value{Kind: KindNumber, Number: number}, nil
+		}
+		if parsed, ok := parseTime(t.text); ok {
+			return Value{Kind: KindTime, Time: parsed}, nil
+		}
+		return Value{}, errorAt(t.pos, "invalid value %q, expected a number, a date such as 2024-01-01 or an RFC 3339 timestamp", t.text)
+	case tokenIdent:
+		switch strings.ToLower(t.text) {
+		case "true":
+			return BoolValue(true), nil
+		case "false":
+			return BoolValue(false), nil
+		}
+		return Value{}, errorAt(t.pos, "expected value, found %s, strings must be double-quoted", describe(t))
+	default:
+		return Value{}, errorAt(t.pos, "expected value, found %s", describe(t))
+	}
+}
+
+func parseTime(text string) (time.Time, bool) {
+	if parsed, err := time.Parse(time.RFC3339Nano, strings.ToUpper(text)); err == nil {
+		return parsed, true
+	}
+	if parsed, err := time.Parse(time.DateOnly, text); err == nil {
+		return parsed, true
+	}
+	return time.Time{}, false
+}
+
+func describe(t token) string {
+	if t.kind == tokenEOF {
+		return t.kind.String()
+	}
+	return t.kind.String() + " " + strconv.Quote(t.text)
+}
@@ -0,0 +1,152 @@
+package filter
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SQLOptions maps an expression to the tables of a SQL adapter.
+type SQLOptions struct {
+	// Columns maps schema fields to column expressions. Fields without an
+	// entry use their name. Dynamic fields such as "attributes.*" map to a
+	// format with one %s, which receives the key as a quoted SQL string, e.g.
+	// "attributes ->> %s".
+	Columns map[string]string
+	// Placeholder renders the n-th (1-based) parameter, "?" when nil. Use
+	// DollarPlaceholder for PostgreSQL.
+	Placeholder func(n int) string
+}
+
+func DollarPlaceholder(n int) string {
+	return fmt.Sprintf("$%d", n)
+}
+
+// ToSQL checks expr against schema and renders it as a WHERE clause whose
+// values are all passed as parameters.
+func ToSQL(expr Expr, schema Schema, options *SQLOptions) (string, []interface{}, error) {
+	if options == nil {
+		options = &SQLOptions{}
+	}
+	w := &sqlWriter{schema: schema, options: options}
+	if err := w.write(expr); err != nil {
+		return "", nil, err
+	}
+	return w.sql.String(), w.args, nil
+}
+
+type sqlWriter struct {
+	schema  Schema
+	options *SQLOptions
+	sql     strings.Builder
+	args    []interface{}
+}
+
+func (w *sqlWriter) param(value interface{}) string {
+	w.args = append(w.args, value)
+	if w.options.Placeholder == nil {
+		return "?"
+	}
+	return w.options.Placeholder(len(w.args))
+}
+
+func (w *sqlWriter) write(expr Expr) error {
+	switch e := expr.(type) {
+	case *Binary:
+		w.sql.WriteString("(")
+		if err := w.write(e.Left); err != nil {
+			return err
+		}
+		w.sql.WriteString(" " + string(e.Op) + " ")
+		if err := w.write(e.Right); err != nil {
+			return err
+		}
+		w.sql.WriteString(")")
+	case *Not:
+		w.sql.WriteString("NOT (")
+		if err := w.write(e.Expr); err != nil {
+			return err
+		}
+		w.sql.WriteString(")")
+	case *Comparison:
+		field, value, err := check(e, w.schema)
+		if err != nil {
+			return err
+		}
+		column := w.column(field, value.Kind)
+
+		switch e.Op {
+		case Match, NotMatch:
+			like := "LIKE"
+			if e.Op == NotMatch {
+				like = "NOT LIKE"
+			}
+			fmt.Fprintf(&w.sql, `LOWER(%s) %s %s ESCAPE '\'`, column, like, w.param(globToLike(strings.ToLower(value.Text))))
+		default:
+			var arg interface{}
+			switch value.Kind {
+			case KindNumber:
+				arg = value.Number
+			case KindTime:
+				arg = value.Time
+			case KindBool:
+				arg = value.Bool
+			default:
+				arg = value.Text
+			}
+			op := string(e.Op)
+			if e.Op == NotEqual {
+				op = "<>"
+			}
+			fmt.Fprintf(&w.sql, "%s %s %s", column, op, w.param(arg))
+		}
+	default:
+		return errorAt(expr.Pos(), "unsupported expression %s", expr)
+	}
+	return nil
+}
+
+func (w *sqlWriter) column(field string, kind Kind) string {
+	if mapped, ok := w.options.Columns[field]; ok {
+		return mapped
+	}
+	declared, key, ok := w.schema.dynamicField(field)
+	if !ok {
+		return field
+	}
+	format, ok := w.options.Columns[declared]
+	if !ok {
+		return field
+	}
+	column := fmt.Sprintf(format, "'"+strings.ReplaceAll(key, "'", "''")+"'")
+	// Dynamic values are stored as text, compare them as the kind of the value.
+	switch kind {
+	case KindNumber:
+		return "CAST(" + column + " AS NUMERIC)"
+	case KindTime:
+		return "CAST(" + column + " AS TIMESTAMP)"
+	case KindBool:
+		return "CAST(" + column + " AS BOOLEAN)"
+	default:
+		return column
+	}
+}
+
+// globToLike escapes the LIKE wildcards of pattern and turns its glob
+// wildcards into LIKE ones.
+func globToLike(pattern string) string {
+	var like strings.Builder
+	for _, r := range pattern {
+		switch r {
+		case '%', '_', '\\':
+			like.WriteRune('\\')
+			like.WriteRune(r)
+		case '*':
+			like.WriteRune('%')
+		case '?':
+			like.WriteRune('_')
+		default:
+			like.WriteRune(r)
+		}
+	}
+	return like.String()
+}
@@ -0,0 +1,141 @@
+package tests
+
+import (
+	"devices_crud/internal/devices/app/ports"
+	"devices_crud/internal/devices/model"
+	"devices_crud/internal/filter"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func matches(t *testing.T, expression string, device *model.Device) bool {
+	expr, err := filter.Parse(expression)
+	if !assert.Nil(t, err) {
+		return false
+	}
+	predicate, err := filter.Compile(expr, model.DeviceFilterSchema)
+	if !assert.Nil(t, err) {
+		return false
+	}
+	return predicate(device)
+}
+
+func TestShouldParseWithPrecedence(t *testing.T) {
+	expr, err := filter.Parse(`brand = "Apple" or not name ~ "lab-*" and createdAt >= 2024-01-01`)
+	assert.Nil(t, err)
+	assert.Equal(t, `(brand = "Apple" OR (NOT name ~ "lab-*" AND createdAt >= 2024-01-01T00:00:00Z))`, expr.String())
+
+	expr, err = filter.Parse(`(brand = "Apple" OR brand = "Dell") AND name != "x"`)
+	assert.Nil(t, err)
+	assert.Equal(t, `((brand = "Apple" OR brand = "Dell") AND name != "x")`, expr.String())
+}
+
+func TestShouldReportSyntaxErrorPositions(t *testing.T) {
+	cases := map[string]int{
+		``:                           1,
+		`brand = "Apple`:             9,
+		`brand =`:                    8,
+		`brand = "Apple" AND`:        20,
+		`(brand = "Apple"`:           17,
+		`brand = "Apple" name = "x"`: 17,
+		`createdAt > 2024-13-01`:     13,
+		`brand @ "Apple"`:            7,
+	}
+	for expression, position := range cases {
+		_, err := filter.Parse(expression)
+		var syntaxErr *filter.SyntaxError
+		if assert.True(t, errors.As(err, &syntaxErr), expression) {
+			assert.Equal(t, position, syntaxErr.Position, expression)
+		}
+	}
+}
+
+func TestShouldRejectUnknownFieldsAndMismatchedValues(t *testing.T) {
+	for expression, position := range map[string]int{
+		`color = "red"`:       1,
+		`createdAt > "soon"`:  13,
+		`createdAt ~ "2024*"`: 13,
+	} {
+		expr, err := filter.Parse(expression)
+		assert.Nil(t, err)
+		_, err = filter.Compile(expr, model.DeviceFilterSchema)
+		var syntaxErr *filter.SyntaxError
+		if assert.True(t, errors.As(err, &syntaxErr), expression) {
+			assert.Equal(t, position, syntaxErr.Position, expression)
+		}
+	}
+}
+
+func TestShouldEvaluatePredicates(t *testing.T) {
+	device := &model.Device{
+		ID:          "1",
+		Name:        "Lab-Printer-2",
+		DeviceBrand: "Brother",
+		CreatedAt:   time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC),
+	}
+
+	assert.True(t, matches(t, `brand = "Brother" AND createdAt > 2024-01-01 AND name ~ "lab-*"`, device))
+	assert.True(t, matches(t, `name ~ "lab-printer-?"`, device))
+	assert.True(t, matches(t, `NOT brand = "Apple"`, device))
+	assert.True(t, matches(t, `createdAt <= "2024-03-01T12:00:00Z"`, device))
+	assert.False(t, matches(t, `brand = "brother"`, device))
+	assert.False(t, matches(t, `createdAt < 2024-03-01 OR name !~ "*printer*"`, device))
+}
+
+func TestShouldRenderParameterizedSQL(t *testing.T) {
+	expr, err := filter.Parse(`brand = "Apple" AND (createdAt > 2024-01-01 OR NOT name ~ "100%_lab*")`)
+	assert.Nil(t, err)
+
+	sql, args, err := filter.ToSQL(expr, model.DeviceFilterSchema, &filter.SQLOptions{
+		Columns:     ports.DeviceColumns,
+		Placeholder: filter.DollarPlaceholder,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, `(device_brand = $1 AND (created_at > $2 OR NOT (LOWER(name) LIKE $3 ESCAPE '\')))`, sql)
+	assert.Equal(t, []interface{}{"Apple", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), `100\%\_lab%`}, args)
+}
+
+func TestShouldFilterOnDynamicFields(t *testing.T) {
+	device := &model.Device{Type: "laptop", Attributes: model.Attributes{
+		"os": "linux", "ramGb": 16.0, "managed": true, "purchased": "2024-02-01",
+	}}
+
+	assert.True(t, matches(t, `type = "laptop" AND attributes.ramGb > 8 AND attributes.managed = true`, device))
+	assert.True(t, matches(t, `attributes.purchased > 2024-01-01 AND attributes.os ~ "lin*"`, device))
+	assert.True(t, matches(t, `attributes.os != 3`, device))
+	assert.False(t, matches(t, `attributes.ramGb = "16"`, device))
+	assert.False(t, matches(t, `attributes.missing = "x"`, device))
+
+	expr, _ := filter.Parse(`attributes.managed > true`)
+	_, err := filter.Compile(expr, model.DeviceFilterSchema)
+	assert.NotNil(t, err)
+
+	expr, _ = filter.Parse(`attributes.ramGb >= 16 AND attributes.os = "linux"`)
+	sql, args, err := filter.ToSQL(expr, model.DeviceFilterSchema, &filter.SQLOptions{Columns: ports.DeviceColumns})
+	assert.Nil(t, err)
+	assert.Equal(t, `(CAST(attributes ->> 'ramGb' AS NUMERIC) >= ? AND attributes ->> 'os' = ?)`, sql)
+	assert.Equal(t, []interface{}{16.0, "linux"}, args)
+}
+
+func TestShouldResolveOverlappingDynamicFieldsByLongestPrefix(t *testing.T) {
+	schema := filter.Schema{"attributes.*": filter.KindAny, "attributes.net.*": filter.KindString}
+	options := &filter.SQLOptions{Columns: map[string]string{
+		"attributes.*":     "attributes ->> %s",
+		"attributes.net.*": "network ->> %s",
+	}}
+
+	// Map order changes between iterations, the resolution must not.
+	for i := 0; i < 20; i++ {
+		expr, _ := filter.Parse(`attributes.net.mask = "255.0.0.0" AND attributes.os = "linux"`)
+		sql, _, err := filter.ToSQL(expr, schema, options)
+		assert.Nil(t, err)
+		assert.Equal(t, `(network ->> 'mask' = ? AND attributes ->> 'os' = ?)`, sql)
+
+		expr, _ = filter.Parse(`attributes.net.mask > 8`)
+		_, err = filter.Compile(expr, schema)
+		assert.NotNil(t, err)
+	}
+}
@@ -0,0 +1,165 @@
+// Package health runs the readiness checks of the service: its dependencies,
+// such as the devices repository, and its background workers.
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	StatusOK   = "ok"
+	StatusFail = "fail"
+)
+
+// CheckFunc reports why a component is not ready, or nil when it is.
+type CheckFunc func(ctx context.Context) error
+
+// ComponentReport is the outcome of the check of one component.
+type ComponentReport struct {
+	Status   string `json:"status"`
+	Error    string `json:"error,omitempty"`
+	Duration string `json:"duration"`
+}
+
+// Report is ready only when every component is.
+type Report struct {
+	Status     string                     `json:"status"`
+	Components map[string]ComponentReport `json:"components"`
+}
+
+type check struct {
+	name  string
+	check CheckFunc
+}
+
+// Checker runs the registered checks concurrently, each bounded by the
+// timeout.
+type Checker struct {
+	timeout time.Duration
+
+	mutex  sync.RWMutex
+	checks []check
+}
+
+func NewChecker(timeout time.Duration) *Checker {
+	return &Checker{timeout: timeout}
+}
+
+// Register adds a check. Registering a name twice replaces the check.
+func (c *Checker) Register(name string, checkFunc CheckFunc) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for i := range c.checks {
+		if c.checks[i].name == name {
+			c.checks[i].check = checkFunc
+			return
+		}
+	}
+	c.checks = append(c.checks, check{name: name, check: checkFunc})
+	sort.Slice(c.checks, func(i, j int) bool { return c.checks[i].name < c.checks[j].name })
+}
+
+// RegisterWorker adds a background worker that must call Beat at least every
+// maxSilence to be considered alive.
+func (c *Checker) RegisterWorker(name string, maxSilence time.Duration) *Worker {
+	worker := &Worker{maxSilence: maxSilence, lastBeat: time.Now()}
+	c.Register("worker:"+name, worker.check)
+	return worker
+}
+
+func (c *Checker) Check(ctx context.Context) *Report {
+	c.mutex.RLock()
+	checks := append([]check(nil), c.checks...)
+	c.mutex.RUnlock()
+
+	report := &Report{Status: StatusOK, Components: make(map[string]ComponentReport, len(checks))}
+	results := make([]ComponentReport, len(checks))
+
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func(i int, checkFunc CheckFunc) {
+			defer wg.Done()
+			results[i] = c.run(ctx, checkFunc)
+		}(i, check.check)
+	}
+	wg.Wait()
+
+	for i, check := range checks {
+		report.Components[check.name] = results[i]
+		if results[i].Status != StatusOK {
+			report.Status = StatusFail
+		}
+	}
+	return report
+}
+
+func (c *Checker) run(ctx context.Context, checkFunc CheckFunc) ComponentReport {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		done <- checkFunc(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = fmt.Errorf("timed out after %s", c.timeout)
+	}
+
+	report := ComponentReport{Status: StatusOK, Duration: time.Since(start).String()}
+	if err != nil {
+		report.Status = StatusFail
+		report.Error = err.Error()
+	}
+	return report
+}
+
+// ErrWorkerStalled is reported for workers that stopped beating.
+var ErrWorkerStalled = errors.New("worker stalled")
+
+// Worker tracks the liveness of a background worker.
+type Worker struct {
+	maxSilence time.Duration
+
+	mutex    sync.Mutex
+	lastBeat time.Time
+	stopped  bool
+}
+
+// Beat records that the worker is alive.
+func (w *Worker) Beat() {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.lastBeat = time.Now()
+}
+
+// Stop marks the worker as no longer running, which fails the check.
+func (w *Worker) Stop() {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.stopped = true
+}
+
+func (w *Worker) check(context.Context) error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.stopped {
+		return fmt.Errorf("%w: stopped", ErrWorkerStalled)
+	}
+	if silence := time.Since(w.lastBeat); silence > w.maxSilence {
+		return fmt.Errorf("%w: no beat for %s", ErrWorkerStalled, silence.Truncate(time.Second))
+	}
+	return nil
+}
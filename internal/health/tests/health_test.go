@@ -0,0 +1,92 @@
+package tests
+
+import (
+	"context"
+	"devices_crud/internal/devices"
+	"devices_crud/internal/drivers/rest"
+	"devices_crud/internal/health"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupRouter() (*gin.Engine, *devices.DependencyTree) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	deps := devices.NewDevicesDependencies(&devices.DeviceDependencies{
+		UseMocks:         true,
+		Logger:           slog.New(slog.NewTextHandler(os.Stdout, nil)),
+		ReadinessTimeout: 50 * time.Millisecond,
+	})
+	rest.BuildRoutes(router, deps)
+	return router, deps
+}
+
+func probe(router *gin.Engine, path string) (int, *health.Report) {
+	httpReq, _ := http.NewRequest("GET", path, nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httpReq)
+
+	report := &health.Report{}
+	_ = json.Unmarshal(w.Body.Bytes(), report)
+	return w.Code, report
+}
+
+func TestShouldBeReadyWithReachableRepository(t *testing.T) {
+	router, _ := setupRouter()
+
+	code, report := probe(router, "/readyz")
+	assert.Equal(t, 200, code)
+	assert.Equal(t, health.StatusOK, report.Status)
+	assert.Equal(t, health.StatusOK, report.Components["repository"].Status)
+}
+
+func TestShouldReportFailingComponents(t *testing.T) {
+	router, deps := setupRouter()
+	deps.Health.Register("migrations", func(ctx context.Context) error {
+		return errors.New("2 pending migrations: 0003_labels, 0004_locations")
+	})
+	deps.Health.Register("slow", func(ctx context.Context) error {
+		time.Sleep(time.Second)
+		return nil
+	})
+
+	code, report := probe(router, "/readyz")
+	assert.Equal(t, 503, code)
+	assert.Equal(t, health.StatusFail, report.Status)
+	assert.Equal(t, health.StatusOK, report.Components["repository"].Status)
+	assert.Equal(t, "2 pending migrations: 0003_labels, 0004_locations", report.Components["migrations"].Error)
+	assert.Contains(t, report.Components["slow"].Error, "timed out")
+
+	code, report = probe(router, "/healthz")
+	assert.Equal(t, 200, code)
+	assert.Equal(t, health.StatusOK, report.Status)
+}
+
+func TestShouldFailStalledWorkers(t *testing.T) {
+	checker := health.NewChecker(time.Second)
+	alive := checker.RegisterWorker("alive", time.Minute)
+	stalled := checker.RegisterWorker("stalled", 20*time.Millisecond)
+	stopped := checker.RegisterWorker("stopped", time.Minute)
+
+	time.Sleep(50 * time.Millisecond)
+	alive.Beat()
+	stopped.Stop()
+
+	report := checker.Check(context.Background())
+	assert.Equal(t, health.StatusFail, report.Status)
+	assert.Equal(t, health.StatusOK, report.Components["worker:alive"].Status)
+	assert.Contains(t, report.Components["worker:stalled"].Error, "no beat")
+	assert.Contains(t, report.Components["worker:stopped"].Error, "stopped")
+
+	stalled.Beat()
+	assert.Equal(t, health.StatusOK, checker.Check(context.Background()).Components["worker:stalled"].Status)
+}
@@ -0,0 +1,204 @@
+// Package jsonschema validates JSON values against the subset of JSON Schema
+// (draft 2020-12) device types use to describe their attributes:
+//
+//	type, enum, const
+//	properties, required, additionalProperties
+//	items, minItems, maxItems
+//	minLength, maxLength, pattern, format (date, date-time, ipv4, ipv6, mac)
+//	minimum, maximum, exclusiveMinimum, exclusiveMaximum
+//
+// Other keywords, such as title and description, are accepted and ignored.
+// Values are those produced by encoding/json: map[string]interface{},
+// []interface{}, string, float64, bool and nil.
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+)
+
+// Schema is a compiled schema. The zero value accepts every value.
+type Schema struct {
+	types                []string
+	enum                 []interface{}
+	constant             *interface{}
+	properties           map[string]*Schema
+	required             []string
+	additionalProperties *Schema
+	items                *Schema
+	minItems, maxItems   *int
+	minLength, maxLength *int
+	pattern              *regexp.Regexp
+	format               string
+	minimum, maximum     *float64
+	exclusiveMinimum     *float64
+	exclusiveMaximum     *float64
+	// never is set by the schema false, which rejects every value.
+	never bool
+}
+
+var simpleTypes = map[string]bool{
+	"object": true, "array": true, "string": true, "number": true,
+	"integer": true, "boolean": true, "null": true,
+}
+
+var formats = map[string]bool{"date": true, "date-time": true, "ipv4": true, "ipv6": true, "mac": true}
+
+// Compile parses a schema document. Errors describe the first invalid keyword.
+func Compile(document json.RawMessage) (*Schema, error) {
+	var raw interface{}
+	if err := json.Unmarshal(document, &raw); err != nil {
+		return nil, fmt.Errorf("invalid schema: %w", err)
+	}
+	return compile(raw, "")
+}
+
+func compile(raw interface{}, path string) (*Schema, error) {
+	switch value := raw.(type) {
+	case bool:
+		return &Schema{never: !value}, nil
+	case map[string]interface{}:
+		return compileObject(value, path)
+	default:
+		return nil, schemaError(path, "a schema must be an object or a boolean")
+	}
+}
+
+func compileObject(raw map[string]interface{}, path string) (*Schema, error) {
+	s := &Schema{}
+	var err error
+
+	// Keywords are compiled in a fixed order so errors are deterministic.
+	keywords := make([]string, 0, len(raw))
+	for keyword := range raw {
+		keywords = append(keywords, keyword)
+	}
+	sort.Strings(keywords)
+
+	for _, keyword := range keywords {
+		value := raw[keyword]
+		at := path + "/" + keyword
+
+		switch keyword {
+		case "type":
+			s.types, err = compileTypes(value, at)
+		case "enum":
+			values, ok := value.([]interface{})
+			if !ok || len(values) == 0 {
+				return nil, schemaError(at, "must be a non-empty array")
+			}
+			s.enum = values
+		case "const":
+			constant := value
+			s.constant = &constant
+		case "properties":
+			properties, ok := value.(map[string]interface{})
+			if !ok {
+				return nil, schemaError(at, "must be an object")
+			}
+			s.properties = make(map[string]*Schema, len(properties))
+			for name, property := range properties {
+				if s.properties[name], err = compile(property, at+"/"+name); err != nil {
+					return nil, err
+				}
+			}
+		case "required":
+			s.required, err = compileStrings(value, at)
+		case "additionalProperties":
+			s.additionalProperties, err = compile(value, at)
+		case "items":
+			s.items, err = compile(value, at)
+		case "minItems":
+			s.minItems, err = compileCount(value, at)
+		case "maxItems":
+			s.maxItems, err = compileCount(value, at)
+		case "minLength":
+			s.minLength, err = compileCount(value, at)
+		case "maxLength":
+			s.maxLength, err = compileCount(value, at)
+		case "pattern":
+			pattern, ok := value.(string)
+			if !ok {
+				return nil, schemaError(at, "must be a string")
+			}
+			if s.pattern, err = regexp.Compile(pattern); err != nil {
+				return nil, schemaError(at, "invalid regular expression: %v", err)
+			}
+		case "format":
+			format, ok := value.(string)
+			if !ok {
+				return nil, schemaError(at, "must be a string")
+			}
+			if !formats[format] {
+				return nil, schemaError(at, "unsupported format %q", format)
+			}
+			s.format = format
+		case "minimum":
+			s.minimum, err = compileNumber(value, at)
+		case "maximum":
+			s.maximum, err = compileNumber(value, at)
+		case "exclusiveMinimum":
+			s.exclusiveMinimum, err = compileNumber(value, at)
+		case "exclusiveMaximum":
+			s.exclusiveMaximum, err = compileNumber(value, at)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
+
+func compileTypes(value interface{}, path string) ([]string, error) {
+	if name, ok := value.(string); ok {
+		value = []interface{}{name}
+	}
+	types, err := compileStrings(value, path)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range types {
+		if !simpleTypes[name] {
+			return nil, schemaError(path, "unknown type %q", name)
+		}
+	}
+	return types, nil
+}
+
+func compileStrings(value interface{}, path string) ([]string, error) {
+	values, ok := value.([]interface{})
+	if !ok {
+		return nil, schemaError(path, "must be an array of strings")
+	}
+	names := make([]string, len(values))
+	for i, value := range values {
+		if names[i], ok = value.(string); !ok {
+			return nil, schemaError(path, "must be an array of strings")
+		}
+	}
+	return names, nil
+}
+
+func compileCount(value interface{}, path string) (*int, error) {
+	number, ok := value.(float64)
+	if !ok || number < 0 || number != float64(int(number)) {
+		return nil, schemaError(path, "must be a non-negative integer")
+	}
+	count := int(number)
+	return &count, nil
+}
+
+func compileNumber(value interface{}, path string) (*float64, error) {
+	number, ok := value.(float64)
+	if !ok {
+		return nil, schemaError(path, "must be a number")
+	}
+	return &number, nil
+}
+
+func schemaError(path string, format string, args ...interface{}) error {
+	return fmt.Errorf("invalid schema at %s: %s", path, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,62 @@
+package tests
+
+import (
+	"devices_crud/internal/jsonschema"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const laptopSchema = `{
+	"type": "object",
+	"properties": {
+		"serial": {"type": "string", "minLength": 4},
+		"mac": {"type": "string", "format": "mac"},
+		"os": {"enum": ["linux", "macos", "windows"]},
+		"ramGb": {"type": "integer", "minimum": 1},
+		"managed": {"type": "boolean"}
+	},
+	"required": ["serial"],
+	"additionalProperties": false
+}`
+
+func decode(t *testing.T, document string) interface{} {
+	var value interface{}
+	assert.Nil(t, json.Unmarshal([]byte(document), &value))
+	return value
+}
+
+func TestShouldValidateAgainstSchema(t *testing.T) {
+	schema, err := jsonschema.Compile([]byte(laptopSchema))
+	assert.Nil(t, err)
+
+	assert.Nil(t, schema.Validate(decode(t, `{"serial":"C02X1234","mac":"a4:83:e7:00:11:22","os":"macos","ramGb":16}`)))
+
+	err = schema.Validate(decode(t, `{"mac":"a4:83:e7","os":"beos","ramGb":1.5,"color":"red"}`))
+	var violations jsonschema.Errors
+	if assert.True(t, errors.As(err, &violations)) {
+		assert.Equal(t, jsonschema.Errors{
+			{Path: "/color", Message: "is not a known property"},
+			{Path: "/mac", Message: "must be a valid mac"},
+			{Path: "/os", Message: `must be one of "linux", "macos", "windows"`},
+			{Path: "/ramGb", Message: "must be of type integer"},
+			{Path: "/serial", Message: "is required"},
+		}, violations)
+	}
+}
+
+func TestShouldRejectInvalidSchemas(t *testing.T) {
+	for document, message := range map[string]string{
+		`{"type": "text"}`:                        `invalid schema at /type: unknown type "text"`,
+		`{"properties": {"a": {"pattern": "("}}}`: "invalid schema at /properties/a/pattern: invalid regular expression",
+		`{"minLength": -1}`:                       "invalid schema at /minLength: must be a non-negative integer",
+		`[]`:                                      "invalid schema at : a schema must be an object or a boolean",
+	} {
+		_, err := jsonschema.Compile([]byte(document))
+		if assert.NotNil(t, err, document) {
+			assert.Contains(t, err.Error(), message)
+		}
+	}
+}
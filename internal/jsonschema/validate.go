@@ -0,0 +1,208 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ValidationError is one violation of a schema. Path is a JSON pointer to the
+// offending value, "" for the validated value itself.
+type ValidationError struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+func (e ValidationError) Error() string {
+	if e.Path == "" {
+		return e.Message
+	}
+	return e.Path + ": " + e.Message
+}
+
+// Errors lists every violation found by Validate, ordered by path.
+type Errors []ValidationError
+
+func (e Errors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+var macPattern = regexp.MustCompile(`^[0-9A-Fa-f]{2}([:-][0-9A-Fa-f]{2}){5}$`)
+
+// Validate checks value against the schema and returns Errors, or nil when the
+// value is valid.
+func (s *Schema) Validate(value interface{}) error {
+	var errs Errors
+	s.validate(value, "", &errs)
+	if len(errs) == 0 {
+		return nil
+	}
+	sort.SliceStable(errs, func(i, j int) bool { return errs[i].Path < errs[j].Path })
+	return errs
+}
+
+func (s *Schema) validate(value interface{}, path string, errs *Errors) {
+	report := func(format string, args ...interface{}) {
+		*errs = append(*errs, ValidationError{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if s.never {
+		report("is not allowed")
+		return
+	}
+	if len(s.types) > 0 && !s.matchesType(value) {
+		report("must be of type %s", strings.Join(s.types, " or "))
+		return
+	}
+	if s.constant != nil && !equal(value, *s.constant) {
+		report("must be %s", encode(*s.constant))
+	}
+	if s.enum != nil && !s.inEnum(value) {
+		encoded := make([]string, len(s.enum))
+		for i, allowed := range s.enum {
+			encoded[i] = encode(allowed)
+		}
+		report("must be one of %s", strings.Join(encoded, ", "))
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for _, name := range s.required {
+			if _, ok := v[name]; !ok {
+				*errs = append(*errs, ValidationError{Path: path + "/" + name, Message: "is required"})
+			}
+		}
+		for name, property := range v {
+			if schema, ok := s.properties[name]; ok {
+				schema.validate(property, path+"/"+name, errs)
+			} else if s.additionalProperties != nil {
+				if s.additionalProperties.never {
+					*errs = append(*errs, ValidationError{Path: path + "/" + name, Message: "is not a known property"})
+				} else {
+					s.additionalProperties.validate(property, path+"/"+name, errs)
+				}
+			}
+		}
+	case []interface{}:
+		if s.minItems != nil && len(v) < *s.minItems {
+			report("must have at least %d items", *s.minItems)
+		}
+		if s.maxItems != nil && len(v) > *s.maxItems {
+			report("must have at most %d items", *s.maxItems)
+		}
+		if s.items != nil {
+			for i, item := range v {
+				s.items.validate(item, fmt.Sprintf("%s/%d", path, i), errs)
+			}
+		}
+	case string:
+		length := utf8.RuneCountInString(v)
+		if s.minLength != nil && length < *s.minLength {
+			report("must be at least %d characters long", *s.minLength)
+		}
+		if s.maxLength != nil && length > *s.maxLength {
+			report("must be at most %d characters long", *s.maxLength)
+		}
+		if s.pattern != nil && !s.pattern.MatchString(v) {
+			report("must match %s", s.pattern)
+		}
+		if s.format != "" && !validFormat(s.format, v) {
+			report("must be a valid %s", s.format)
+		}
+	case float64:
+		if s.minimum != nil && v < *s.minimum {
+			report("must be at least %v", *s.minimum)
+		}
+		if s.maximum != nil && v > *s.maximum {
+			report("must be at most %v", *s.maximum)
+		}
+		if s.exclusiveMinimum != nil && v <= *s.exclusiveMinimum {
+			report("must be greater than %v", *s.exclusiveMinimum)
+		}
+		if s.exclusiveMaximum != nil && v >= *s.exclusiveMaximum {
+			report("must be less than %v", *s.exclusiveMaximum)
+		}
+	}
+}
+
+func (s *Schema) matchesType(value interface{}) bool {
+	for _, name := range s.types {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			if name == "object" {
+				return true
+			}
+		case []interface{}:
+			if name == "array" {
+				return true
+			}
+		case string:
+			if name == "string" {
+				return true
+			}
+		case float64:
+			if name == "number" || (name == "integer" && v == math.Trunc(v)) {
+				return true
+			}
+		case bool:
+			if name == "boolean" {
+				return true
+			}
+		case nil:
+			if name == "null" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (s *Schema) inEnum(value interface{}) bool {
+	for _, allowed := range s.enum {
+		if equal(value, allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+func validFormat(format string, value string) bool {
+	switch format {
+	case "date":
+		_, err := time.Parse(time.DateOnly, value)
+		return err == nil
+	case "date-time":
+		_, err := time.Parse(time.RFC3339, value)
+		return err == nil
+	case "ipv4":
+		ip := net.ParseIP(value)
+		return ip != nil && ip.To4() != nil && !strings.Contains(value, ":")
+	case "ipv6":
+		ip := net.ParseIP(value)
+		return ip != nil && strings.Contains(value, ":")
+	case "mac":
+		return macPattern.MatchString(value)
+	default:
+		return true
+	}
+}
+
+func equal(a, b interface{}) bool {
+	return reflect.DeepEqual(a, b)
+}
+
+func encode(value interface{}) string {
+	encoded, _ := json.Marshal(value)
+	return string(encoded)
+}
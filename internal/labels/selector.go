@@ -0,0 +1,279 @@
+// Package labels validates key/value labels and parses Kubernetes-style label
+// selectors such as
+//
+//	env=prod,team in (a,b),!retired
+//
+// A selector is a comma-separated list of requirements that must all hold:
+//
+//	key=value, key==value   the label is set to value
+//	key!=value              the label is not set to value, or not set at all
+//	key in (v1,v2)          the label is set to one of the values
+//	key notin (v1,v2)       the label is not set to any of the values, or not set
+//	key                     the label is set
+//	!key                    the label is not set
+package labels
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type Operator string
+
+const (
+	Equals       Operator = "="
+	NotEquals    Operator = "!="
+	In           Operator = "in"
+	NotIn        Operator = "notin"
+	Exists       Operator = "exists"
+	DoesNotExist Operator = "!"
+)
+
+type Requirement struct {
+	Key      string
+	Operator Operator
+	// Values holds one value for Equals and NotEquals and the sorted set for In
+	// and NotIn.
+	Values []string
+}
+
+// Matches reports whether labels satisfy the requirement.
+func (r Requirement) Matches(labels map[string]string) bool {
+	value, ok := labels[r.Key]
+	switch r.Operator {
+	case Equals, In:
+		return ok && r.has(value)
+	case NotEquals, NotIn:
+		return !ok || !r.has(value)
+	case Exists:
+		return ok
+	default:
+		return !ok
+	}
+}
+
+// Selects reports whether the requirement can only match labelled devices, so
+// that an index of label values can answer it.
+func (r Requirement) Selects() bool {
+	return r.Operator == Equals || r.Operator == In || r.Operator == Exists
+}
+
+func (r Requirement) has(value string) bool {
+	for _, v := range r.Values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func (r Requirement) String() string {
+	switch r.Operator {
+	case Equals, NotEquals:
+		return r.Key + string(r.Operator) + r.Values[0]
+	case In, NotIn:
+		return fmt.Sprintf("%s %s (%s)", r.Key, r.Operator, strings.Join(r.Values, ","))
+	case Exists:
+		return r.Key
+	default:
+		return "!" + r.Key
+	}
+}
+
+// Selector is a conjunction of requirements. The empty selector matches
+// everything.
+type Selector []Requirement
+
+func (s Selector) Matches(labels map[string]string) bool {
+	for _, requirement := range s {
+		if !requirement.Matches(labels) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s Selector) String() string {
+	requirements := make([]string, len(s))
+	for i, requirement := range s {
+		requirements[i] = requirement.String()
+	}
+	return strings.Join(requirements, ",")
+}
+
+// SyntaxError reports the 1-based position in the selector where parsing
+// failed.
+type SyntaxError struct {
+	Position int
+	Message  string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("syntax error at position %d: %s", e.Position, e.Message)
+}
+
+// Parse parses a selector. Errors are *SyntaxError.
+func Parse(input string) (Selector, error) {
+	p := &parser{input: input}
+	selector := Selector{}
+	if strings.TrimSpace(input) == "" {
+		return selector, nil
+	}
+
+	for {
+		requirement, err := p.requirement()
+		if err != nil {
+			return nil, err
+		}
+		selector = append(selector, requirement)
+
+		p.skipSpaces()
+		if p.done() {
+			return selector, nil
+		}
+		if p.input[p.pos] != ',' {
+			return nil, p.errorf("expected ',' or end of selector, found %q", p.input[p.pos])
+		}
+		p.pos++
+	}
+}
+
+type parser struct {
+	input string
+	pos   int
+}
+
+func (p *parser) done() bool {
+	return p.pos >= len(p.input)
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return &SyntaxError{Position: p.pos + 1, Message: fmt.Sprintf(format, args...)}
+}
+
+func (p *parser) skipSpaces() {
+	for !p.done() && p.input[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+// word reads a key or value: everything up to a separator.
+func (p *parser) word() string {
+	start := p.pos
+	for !p.done() && !strings.ContainsRune(" ,=!()", rune(p.input[p.pos])) {
+		p.pos++
+	}
+	return p.input[start:p.pos]
+}
+
+func (p *parser) requirement() (Requirement, error) {
+	p.skipSpaces()
+	if !p.done() && p.input[p.pos] == '!' {
+		p.pos++
+		p.skipSpaces()
+		key, err := p.key()
+		if err != nil {
+			return Requirement{}, err
+		}
+		return Requirement{Key: key, Operator: DoesNotExist}, nil
+	}
+
+	key, err := p.key()
+	if err != nil {
+		return Requirement{}, err
+	}
+
+	p.skipSpaces()
+	switch {
+	case p.done() || p.input[p.pos] == ',':
+		return Requirement{Key: key, Operator: Exists}, nil
+	case strings.HasPrefix(p.input[p.pos:], "!="):
+		p.pos += 2
+		return p.single(key, NotEquals)
+	case strings.HasPrefix(p.input[p.pos:], "=="):
+		p.pos += 2
+		return p.single(key, Equals)
+	case p.input[p.pos] == '=':
+		p.pos++
+		return p.single(key, Equals)
+	}
+
+	operatorPos := p.pos
+	switch operator := p.word(); operator {
+	case "in":
+		return p.set(key, In)
+	case "notin":
+		return p.set(key, NotIn)
+	default:
+		p.pos = operatorPos
+		return Requirement{}, p.errorf("expected =, ==, !=, in or notin after %q", key)
+	}
+}
+
+func (p *parser) key() (string, error) {
+	start := p.pos
+	key := p.word()
+	if key == "" {
+		return "", p.errorf("expected label key")
+	}
+	if err := ValidateKey(key); err != nil {
+		return "", &SyntaxError{Position: start + 1, Message: err.Error()}
+	}
+	return key, nil
+}
+
+func (p *parser) value() (string, error) {
+	p.skipSpaces()
+	start := p.pos
+	value := p.word()
+	if err := ValidateValue(value); err != nil {
+		return "", &SyntaxError{Position: start + 1, Message: err.Error()}
+	}
+	return value, nil
+}
+
+func (p *parser) single(key string, operator Operator) (Requirement, error) {
+	value, err := p.value()
+	if err != nil {
+		return Requirement{}, err
+	}
+	return Requirement{Key: key, Operator: operator, Values: []string{value}}, nil
+}
+
+func (p *parser) set(key string, operator Operator) (Requirement, error) {
+	p.skipSpaces()
+	if p.done() || p.input[p.pos] != '(' {
+		return Requirement{}, p.errorf("expected '(' after %s", operator)
+	}
+	p.pos++
+
+	values := map[string]bool{}
+	for {
+		value, err := p.value()
+		if err != nil {
+			return Requirement{}, err
+		}
+		values[value] = true
+
+		p.skipSpaces()
+		if p.done() {
+			return Requirement{}, p.errorf("expected ')'")
+		}
+		if p.input[p.pos] == ')' {
+			p.pos++
+			break
+		}
+		if p.input[p.pos] != ',' {
+			return Requirement{}, p.errorf("expected ',' or ')', found %q", p.input[p.pos])
+		}
+		p.pos++
+	}
+
+	requirement := Requirement{Key: key, Operator: operator}
+	for value := range values {
+		requirement.Values = append(requirement.Values, value)
+	}
+	sort.Strings(requirement.Values)
+	return requirement, nil
+}
@@ -0,0 +1,77 @@
+package tests
+
+import (
+	"bytes"
+	"devices_crud/internal/labels"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShouldParseSelectors(t *testing.T) {
+	selector, err := labels.Parse("env=prod, team in (b,a), tier notin (web), owner!=bob, !retired, managed, zone==eu")
+	assert.Nil(t, err)
+	assert.Equal(t, labels.Selector{
+		{Key: "env", Operator: labels.Equals, Values: []string{"prod"}},
+		{Key: "team", Operator: labels.In, Values: []string{"a", "b"}},
+		{Key: "tier", Operator: labels.NotIn, Values: []string{"web"}},
+		{Key: "owner", Operator: labels.NotEquals, Values: []string{"bob"}},
+		{Key: "retired", Operator: labels.DoesNotExist},
+		{Key: "managed", Operator: labels.Exists},
+		{Key: "zone", Operator: labels.Equals, Values: []string{"eu"}},
+	}, selector)
+	assert.Equal(t, "env=prod,team in (a,b),tier notin (web),owner!=bob,!retired,managed,zone=eu", selector.String())
+
+	selector, err = labels.Parse("  ")
+	assert.Nil(t, err)
+	assert.Empty(t, selector)
+}
+
+func TestShouldReportSelectorSyntaxErrors(t *testing.T) {
+	for input, position := range map[string]int{
+		"env=prod,":         10,
+		"env=prod team":     10,
+		"team in a,b":       9,
+		"team in (a,b":      13,
+		"team within (a)":   6,
+		"-env=prod":         1,
+		"env=pr*d":          5,
+		"example.com/=prod": 1,
+		"!":                 2,
+	} {
+		_, err := labels.Parse(input)
+		var syntaxErr *labels.SyntaxError
+		if assert.True(t, errors.As(err, &syntaxErr), input) {
+			assert.Equal(t, position, syntaxErr.Position, input)
+		}
+	}
+}
+
+func TestShouldMatchLabels(t *testing.T) {
+	device := map[string]string{"env": "prod", "team": "infra", "example.com/owner": "alice"}
+	for input, matches := range map[string]bool{
+		"env=prod":                        true,
+		"env=dev":                         false,
+		"env!=dev,team in (infra,web)":    true,
+		"team notin (infra)":              false,
+		"zone notin (eu),zone!=us":        true,
+		"example.com/owner,!retired":      true,
+		"retired":                         false,
+		"env in (dev,staging),team=infra": false,
+		"":                                true,
+	} {
+		selector, err := labels.Parse(input)
+		assert.Nil(t, err, input)
+		assert.Equal(t, matches, selector.Matches(device), input)
+	}
+}
+
+func TestShouldValidateLabels(t *testing.T) {
+	assert.Nil(t, labels.Validate(map[string]string{"env": "prod", "example.com/team": "", "a.b_c-d": "x.Y_z-1"}))
+	assert.NotNil(t, labels.ValidateKey(""))
+	assert.NotNil(t, labels.ValidateKey("Example.com/team"))
+	assert.NotNil(t, labels.ValidateKey("team-"))
+	assert.NotNil(t, labels.ValidateValue("a b"))
+	assert.NotNil(t, labels.ValidateValue(string(bytes.Repeat([]byte("a"), 64))))
+}
@@ -0,0 +1,49 @@
+package labels
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Labels follow the Kubernetes syntax: keys are names of at most 63
+// alphanumerics, '-', '_' and '.', starting and ending with an alphanumeric,
+// with an optional DNS subdomain prefix such as "example.com/". Values are
+// empty or names.
+var (
+	name   = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9_.-]{0,61}[A-Za-z0-9])?$`)
+	prefix = regexp.MustCompile(`^[a-z0-9]([a-z0-9.-]{0,251}[a-z0-9])?$`)
+)
+
+func ValidateKey(key string) error {
+	if domain, rest, ok := strings.Cut(key, "/"); ok {
+		if !prefix.MatchString(domain) {
+			return fmt.Errorf("invalid label key %q: prefix must be a DNS subdomain", key)
+		}
+		key = rest
+	}
+	if !name.MatchString(key) {
+		return fmt.Errorf("invalid label key %q: must be at most 63 alphanumerics, '-', '_' or '.', starting and ending with an alphanumeric", key)
+	}
+	return nil
+}
+
+func ValidateValue(value string) error {
+	if value != "" && !name.MatchString(value) {
+		return fmt.Errorf("invalid label value %q: must be empty or at most 63 alphanumerics, '-', '_' or '.', starting and ending with an alphanumeric", value)
+	}
+	return nil
+}
+
+// Validate checks every key and value of labels.
+func Validate(labels map[string]string) error {
+	for key, value := range labels {
+		if err := ValidateKey(key); err != nil {
+			return err
+		}
+		if err := ValidateValue(value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
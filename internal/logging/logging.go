@@ -0,0 +1,186 @@
+// Package logging builds the structured loggers of the service. Loggers are
+// derived per component with Component, each component may log at its own
+// level, and attributes stored in the context with WithAttrs are added to
+// every record logged with that context.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+)
+
+// Keys of the request-scoped attributes.
+const (
+	ComponentKey = "component"
+	RequestIDKey = "request_id"
+	RouteKey     = "route"
+	OperationKey = "operation"
+	PrincipalKey = "principal"
+	TenantKey    = "tenant"
+	DeviceIDKey  = "device_id"
+	TraceIDKey   = "trace_id"
+)
+
+const redacted = "[REDACTED]"
+
+// DefaultRedactedKeys are always redacted, whatever Options.Redact says.
+var DefaultRedactedKeys = []string{"authorization", "x-api-key", "api_key", "token", "password", "secret", "cookie"}
+
+type Options struct {
+	// Format is FormatJSON or FormatText.
+	Format string
+	// Output defaults to os.Stdout.
+	Output io.Writer
+	Level  slog.Level
+	// Levels overrides Level for single components, see Component.
+	Levels map[string]slog.Level
+	// Redact lists further attribute keys whose values never reach the output.
+	// Keys are matched case-insensitively.
+	Redact []string
+}
+
+func New(options *Options) (*slog.Logger, error) {
+	output := options.Output
+	if output == nil {
+		output = os.Stdout
+	}
+
+	minimum := options.Level
+	for _, level := range options.Levels {
+		minimum = min(minimum, level)
+	}
+
+	sensitive := make(map[string]bool)
+	for _, key := range append(DefaultRedactedKeys, options.Redact...) {
+		sensitive[strings.ToLower(key)] = true
+	}
+
+	handlerOptions := &slog.HandlerOptions{
+		Level: minimum,
+		ReplaceAttr: func(_ []string, attr slog.Attr) slog.Attr {
+			if sensitive[strings.ToLower(attr.Key)] {
+				return slog.String(attr.Key, redacted)
+			}
+			return attr
+		},
+	}
+
+	var next slog.Handler
+	switch options.Format {
+	case FormatJSON:
+		next = slog.NewJSONHandler(output, handlerOptions)
+	case FormatText, "":
+		next = slog.NewTextHandler(output, handlerOptions)
+	default:
+		return nil, fmt.Errorf("logging: unknown format %q", options.Format)
+	}
+
+	return slog.New(&handler{next: next, level: options.Level, levels: options.Levels}), nil
+}
+
+// ParseLevel accepts the names of the slog levels, case-insensitively.
+func ParseLevel(name string) (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(name)); err != nil {
+		return 0, fmt.Errorf("logging: %w", err)
+	}
+	return level, nil
+}
+
+// Component returns the logger of a component. Records carry the component
+// name and are filtered by the level configured for it.
+func Component(logger *slog.Logger, name string) *slog.Logger {
+	return logger.With(ComponentKey, name)
+}
+
+type attrsKey struct{}
+
+// WithAttrs returns a context whose log records carry attrs in addition to the
+// attributes already stored in ctx.
+func WithAttrs(ctx context.Context, attrs ...slog.Attr) context.Context {
+	existing := attrsFromContext(ctx)
+	combined := make([]slog.Attr, 0, len(existing)+len(attrs))
+	combined = append(combined, existing...)
+	combined = append(combined, attrs...)
+	return context.WithValue(ctx, attrsKey{}, combined)
+}
+
+func attrsFromContext(ctx context.Context) []slog.Attr {
+	if ctx == nil {
+		return nil
+	}
+	attrs, _ := ctx.Value(attrsKey{}).([]slog.Attr)
+	return attrs
+}
+
+// handler applies the level of the current component and adds the attributes
+// of the context to every record.
+type handler struct {
+	next      slog.Handler
+	level     slog.Level
+	levels    map[string]slog.Level
+	component string
+}
+
+func (h *handler) Enabled(ctx context.Context, level slog.Level) bool {
+	minimum, ok := h.levels[h.component]
+	if !ok {
+		minimum = h.level
+	}
+	return level >= minimum && h.next.Enabled(ctx, level)
+}
+
+func (h *handler) Handle(ctx context.Context, record slog.Record) error {
+	contextAttrs := attrsFromContext(ctx)
+	if span := trace.SpanContextFromContext(ctx); span.HasTraceID() {
+		contextAttrs = append(contextAttrs[:len(contextAttrs):len(contextAttrs)], slog.String(TraceIDKey, span.TraceID().String()))
+	}
+	if len(contextAttrs) > 0 {
+		// Attributes passed to the log call win over the ones of the context, and
+		// later context attributes win over earlier ones with the same key.
+		logged := make(map[string]bool, record.NumAttrs())
+		record.Attrs(func(attr slog.Attr) bool {
+			logged[attr.Key] = true
+			return true
+		})
+		added := make([]slog.Attr, 0, len(contextAttrs))
+		for i := len(contextAttrs) - 1; i >= 0; i-- {
+			if attr := contextAttrs[i]; !logged[attr.Key] {
+				added = append(added, attr)
+				logged[attr.Key] = true
+			}
+		}
+		for i := len(added) - 1; i >= 0; i-- {
+			record.AddAttrs(added[i])
+		}
+	}
+	return h.next.Handle(ctx, record)
+}
+
+func (h *handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clone := *h
+	for _, attr := range attrs {
+		if attr.Key == ComponentKey {
+			clone.component = attr.Value.String()
+		}
+	}
+	clone.next = h.next.WithAttrs(attrs)
+	return &clone
+}
+
+func (h *handler) WithGroup(name string) slog.Handler {
+	clone := *h
+	clone.next = h.next.WithGroup(name)
+	return &clone
+}
@@ -0,0 +1,140 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"devices_crud/internal/devices"
+	"devices_crud/internal/drivers/graph"
+	"devices_crud/internal/drivers/rest"
+	"devices_crud/internal/logging"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func records(t *testing.T, output *bytes.Buffer) []map[string]interface{} {
+	var parsed []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(output.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		record := map[string]interface{}{}
+		assert.NoError(t, json.Unmarshal([]byte(line), &record))
+		parsed = append(parsed, record)
+	}
+	return parsed
+}
+
+func findRecord(records []map[string]interface{}, message string) map[string]interface{} {
+	for _, record := range records {
+		if record["msg"] == message {
+			return record
+		}
+	}
+	return nil
+}
+
+func TestShouldApplyLevelsPerComponent(t *testing.T) {
+	output := &bytes.Buffer{}
+	logger, err := logging.New(&logging.Options{
+		Format: logging.FormatJSON,
+		Output: output,
+		Level:  slog.LevelInfo,
+		Levels: map[string]slog.Level{"service": slog.LevelDebug, "rest": slog.LevelWarn},
+	})
+	assert.NoError(t, err)
+
+	logging.Component(logger, "service").Debug("service debug")
+	logging.Component(logger, "rest").Info("rest info")
+	logging.Component(logger, "rest").Warn("rest warn")
+	logging.Component(logger, "graphql").Debug("graphql debug")
+	logging.Component(logger, "graphql").Info("graphql info")
+
+	parsed := records(t, output)
+	assert.NotNil(t, findRecord(parsed, "service debug"))
+	assert.Nil(t, findRecord(parsed, "rest info"))
+	assert.Equal(t, "rest", findRecord(parsed, "rest warn")["component"])
+	assert.Nil(t, findRecord(parsed, "graphql debug"))
+	assert.NotNil(t, findRecord(parsed, "graphql info"))
+}
+
+func TestShouldRedactSensitiveAttributes(t *testing.T) {
+	output := &bytes.Buffer{}
+	logger, _ := logging.New(&logging.Options{Format: logging.FormatText, Output: output, Redact: []string{"serial"}})
+
+	logger.Info("credentials", "Authorization", "Bearer abc", "password", "hunter2", "serial", "SN-1", "name", "lab-1")
+
+	assert.NotContains(t, output.String(), "Bearer abc")
+	assert.NotContains(t, output.String(), "hunter2")
+	assert.NotContains(t, output.String(), "SN-1")
+	assert.Contains(t, output.String(), "Authorization=[REDACTED]")
+	assert.Contains(t, output.String(), "name=lab-1")
+}
+
+func TestShouldAddContextAttributesToRecords(t *testing.T) {
+	output := &bytes.Buffer{}
+	logger, _ := logging.New(&logging.Options{Format: logging.FormatJSON, Output: output})
+
+	ctx := logging.WithAttrs(context.Background(), slog.String(logging.RequestIDKey, "r-1"), slog.String(logging.DeviceIDKey, "d-1"))
+	ctx = logging.WithAttrs(ctx, slog.String(logging.DeviceIDKey, "d-2"))
+	logger.InfoContext(ctx, "with context")
+	logger.InfoContext(ctx, "explicit", logging.RequestIDKey, "r-2")
+
+	parsed := records(t, output)
+	assert.Equal(t, "r-1", findRecord(parsed, "with context")[logging.RequestIDKey])
+	assert.Equal(t, "d-2", findRecord(parsed, "with context")[logging.DeviceIDKey])
+	assert.Equal(t, "r-2", findRecord(parsed, "explicit")[logging.RequestIDKey])
+}
+
+func TestShouldRejectUnknownFormat(t *testing.T) {
+	_, err := logging.New(&logging.Options{Format: "xml"})
+	assert.Error(t, err)
+}
+
+func TestShouldLogRequestScopedAttributes(t *testing.T) {
+	output := &bytes.Buffer{}
+	logger, _ := logging.New(&logging.Options{Format: logging.FormatJSON, Output: output, Level: slog.LevelDebug})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	deps := devices.NewDevicesDependencies(&devices.DeviceDependencies{UseMocks: true, Logger: logger})
+	rest.BuildRoutes(router, deps)
+	graph.BuildRoutes(router, deps)
+
+	httpReq, _ := http.NewRequest("DELETE", "/v1/devices/d-42", nil)
+	httpReq.Header.Set("X-Request-ID", "req-1")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httpReq)
+	assert.Equal(t, "req-1", w.Header().Get("X-Request-ID"))
+
+	deleted := findRecord(records(t, output), "Device deleted")
+	if assert.NotNil(t, deleted) {
+		assert.Equal(t, "service", deleted["component"])
+		assert.Equal(t, "req-1", deleted[logging.RequestIDKey])
+		assert.Equal(t, "DELETE /v1/devices/:id", deleted[logging.RouteKey])
+		assert.Equal(t, "d-42", deleted[logging.DeviceIDKey])
+		assert.Equal(t, "default", deleted[logging.TenantKey])
+	}
+
+	output.Reset()
+	body := `{"query":"query GetDevice { device(id: \"missing\") { id } }"}`
+	httpReq, _ = http.NewRequest("POST", "/query", bytes.NewReader([]byte(body)))
+	httpReq.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httpReq)
+	assert.NotEmpty(t, w.Header().Get("X-Request-ID"))
+
+	notFound := findRecord(records(t, output), "Device not found")
+	if assert.NotNil(t, notFound) {
+		assert.Equal(t, "graphql", notFound["component"])
+		assert.Equal(t, w.Header().Get("X-Request-ID"), notFound[logging.RequestIDKey])
+		assert.Equal(t, "GetDevice", notFound[logging.OperationKey])
+		assert.Equal(t, "missing", notFound[logging.DeviceIDKey])
+	}
+}
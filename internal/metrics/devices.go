@@ -0,0 +1,66 @@
+package metrics
+
+import (
+	"context"
+	"devices_crud/internal/devices/model"
+	"devices_crud/internal/tenancy"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const scrapeTimeout = 5 * time.Second
+
+// DeviceLister is the part of the devices repository the collector needs.
+type DeviceLister interface {
+	FindAll(ctx context.Context) ([]model.Device, error)
+}
+
+// DevicesCollector reports the stored devices per tenant and brand. It reads
+// the repository on every scrape, across all tenants.
+type DevicesCollector struct {
+	repository   DeviceLister
+	devices      *prometheus.Desc
+	scrapeErrors prometheus.Counter
+}
+
+func NewDevicesCollector(repository DeviceLister) *DevicesCollector {
+	return &DevicesCollector{
+		repository: repository,
+		devices: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "devices"),
+			"Stored devices by tenant and brand.",
+			[]string{"tenant", "brand"}, nil),
+		scrapeErrors: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "devices_scrape_errors_total",
+			Help:      "Failed reads of the devices repository while collecting metrics.",
+		}),
+	}
+}
+
+func (c *DevicesCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.devices
+	c.scrapeErrors.Describe(ch)
+}
+
+func (c *DevicesCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(tenancy.WithAllTenants(context.Background()), scrapeTimeout)
+	defer cancel()
+
+	devices, err := c.repository.FindAll(ctx)
+	if err != nil {
+		c.scrapeErrors.Inc()
+	}
+
+	type key struct{ tenant, brand string }
+	counts := make(map[key]int)
+	for _, device := range devices {
+		counts[key{device.TenantID, device.DeviceBrand}]++
+	}
+	for k, count := range counts {
+		ch <- prometheus.MustNewConstMetric(c.devices, prometheus.GaugeValue, float64(count), k.tenant, k.brand)
+	}
+
+	c.scrapeErrors.Collect(ch)
+}
@@ -0,0 +1,110 @@
+// Package metrics collects Prometheus metrics of the drivers, the repositories
+// and the stored devices.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "devices"
+
+type Metrics struct {
+	registry *prometheus.Registry
+
+	httpRequests        *prometheus.CounterVec
+	httpRequestDuration *prometheus.HistogramVec
+	graphqlOperations   *prometheus.CounterVec
+	graphqlDuration     *prometheus.HistogramVec
+	graphqlErrors       *prometheus.CounterVec
+	repositoryDuration  *prometheus.HistogramVec
+}
+
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "HTTP requests by method, route template and status code.",
+		}, []string{"method", "route", "status"}),
+		httpRequestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "HTTP request latency by method, route template and status code.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		graphqlOperations: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "graphql_operations_total",
+			Help:      "GraphQL operations by operation name.",
+		}, []string{"operation"}),
+		graphqlDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "graphql_operation_duration_seconds",
+			Help:      "GraphQL operation latency by operation name.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"operation"}),
+		graphqlErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "graphql_errors_total",
+			Help:      "Errors returned by GraphQL operations by operation name.",
+		}, []string{"operation"}),
+		repositoryDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "repository_call_duration_seconds",
+			Help:      "Latency of repository calls by repository, method and outcome.",
+			Buckets:   []float64{.0001, .0005, .001, .005, .01, .05, .1, .5, 1},
+		}, []string{"repository", "method", "outcome"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpRequests,
+		m.httpRequestDuration,
+		m.graphqlOperations,
+		m.graphqlDuration,
+		m.graphqlErrors,
+		m.repositoryDuration,
+	)
+
+	return m
+}
+
+// Handler serves the metrics in the Prometheus exposition format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// Register adds further collectors, such as a DevicesCollector.
+func (m *Metrics) Register(collector prometheus.Collector) error {
+	return m.registry.Register(collector)
+}
+
+func (m *Metrics) ObserveHTTPRequest(method string, route string, status int, duration time.Duration) {
+	labels := prometheus.Labels{"method": method, "route": route, "status": strconv.Itoa(status)}
+	m.httpRequests.With(labels).Inc()
+	m.httpRequestDuration.With(labels).Observe(duration.Seconds())
+}
+
+func (m *Metrics) ObserveGraphQLOperation(operation string, errors int, duration time.Duration) {
+	m.graphqlOperations.WithLabelValues(operation).Inc()
+	m.graphqlDuration.WithLabelValues(operation).Observe(duration.Seconds())
+	if errors > 0 {
+		m.graphqlErrors.WithLabelValues(operation).Add(float64(errors))
+	}
+}
+
+func (m *Metrics) ObserveRepositoryCall(repository string, method string, err error, duration time.Duration) {
+	outcome := "success"
+	if err != nil {
+		outcome = "error"
+	}
+	m.repositoryDuration.WithLabelValues(repository, method, outcome).Observe(duration.Seconds())
+}
@@ -0,0 +1,120 @@
+package tests
+
+import (
+	"bytes"
+	"devices_crud/internal/auth"
+	"devices_crud/internal/devices"
+	"devices_crud/internal/drivers/graph"
+	"devices_crud/internal/drivers/rest"
+	"devices_crud/internal/metrics"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	deps := devices.NewDevicesDependencies(&devices.DeviceDependencies{
+		UseMocks: true,
+		Logger:   slog.New(slog.NewTextHandler(os.Stdout, nil)),
+		Metrics:  metrics.New(),
+	})
+	rest.BuildRoutes(router, deps)
+	graph.BuildRoutes(router, deps)
+	return router
+}
+
+func serve(router *gin.Engine, method string, path string, body string) *httptest.ResponseRecorder {
+	httpReq, _ := http.NewRequest(method, path, bytes.NewReader([]byte(body)))
+	httpReq.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httpReq)
+	return w
+}
+
+func scrape(t *testing.T, router *gin.Engine) string {
+	w := serve(router, "GET", "/metrics", "")
+	assert.Equal(t, 200, w.Code)
+	return w.Body.String()
+}
+
+func TestShouldLabelHttpRequestsByRouteTemplate(t *testing.T) {
+	router := setupRouter()
+
+	w := serve(router, "POST", "/v1/devices", `{"name":"lab-1","deviceBrand":"Apple"}`)
+	assert.Equal(t, 201, w.Code)
+	serve(router, "GET", "/v1/devices/unknown", "")
+	serve(router, "GET", "/nowhere", "")
+
+	body := scrape(t, router)
+	assert.Contains(t, body, `devices_http_requests_total{method="POST",route="/v1/devices",status="201"} 1`)
+	assert.Contains(t, body, `devices_http_requests_total{method="GET",route="/v1/devices/:id",status="404"} 1`)
+	assert.Contains(t, body, `devices_http_requests_total{method="GET",route="unmatched",status="404"} 1`)
+	assert.Contains(t, body, `devices_http_request_duration_seconds_count{method="GET",route="/v1/devices/:id",status="404"} 1`)
+	assert.NotContains(t, body, "unknown")
+}
+
+func TestShouldCountGraphQLOperationsAndErrors(t *testing.T) {
+	router := setupRouter()
+
+	serve(router, "POST", "/query", `{"query":"query ListDevices { devices { id } }"}`)
+	serve(router, "POST", "/query", `{"query":"query GetDevice { device(id: \"missing\") { id } }"}`)
+
+	body := scrape(t, router)
+	assert.Contains(t, body, `devices_graphql_operations_total{operation="ListDevices"} 1`)
+	assert.Contains(t, body, `devices_graphql_operations_total{operation="GetDevice"} 1`)
+	assert.Contains(t, body, `devices_graphql_errors_total{operation="GetDevice"} 1`)
+	assert.NotContains(t, body, `devices_graphql_errors_total{operation="ListDevices"}`)
+}
+
+func TestShouldObserveRepositoryCallsAndDevicesPerBrand(t *testing.T) {
+	router := setupRouter()
+
+	serve(router, "POST", "/v1/devices", `{"name":"lab-1","deviceBrand":"Apple"}`)
+	serve(router, "POST", "/v1/devices", `{"name":"lab-2","deviceBrand":"Apple"}`)
+	serve(router, "POST", "/v1/devices", `{"name":"lab-3","deviceBrand":"Dell"}`)
+	serve(router, "GET", "/v1/devices/unknown", "")
+
+	body := scrape(t, router)
+	assert.Contains(t, body, `devices_repository_call_duration_seconds_count{method="Save",outcome="success",repository="devices"} 3`)
+	assert.Contains(t, body, `devices_repository_call_duration_seconds_count{method="FindByID",outcome="success",repository="devices"}`)
+	assert.Contains(t, body, `devices_devices{brand="Apple",tenant="default"} 2`)
+	assert.Contains(t, body, `devices_devices{brand="Dell",tenant="default"} 1`)
+}
+
+func TestShouldRequireAdministratorsToScrapeWhenAuthenticated(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	keysFile := filepath.Join(t.TempDir(), "keys.json")
+	keys, _ := json.Marshal([]auth.APIKey{
+		{Name: "ops", Hash: auth.HashAPIKey("ops-key"), Roles: []string{"operator"}},
+		{Name: "root", Hash: auth.HashAPIKey("root-key"), Roles: []string{"admin"}},
+	})
+	os.WriteFile(keysFile, keys, 0o600)
+	authenticator, err := auth.NewAuthenticator(&auth.Options{APIKeysFile: keysFile})
+	assert.Nil(t, err)
+
+	router := gin.New()
+	rest.BuildRoutes(router, devices.NewDevicesDependencies(&devices.DeviceDependencies{
+		UseMocks:      true,
+		Logger:        slog.New(slog.NewTextHandler(os.Stdout, nil)),
+		Metrics:       metrics.New(),
+		Authenticator: authenticator,
+		Authorizer:    auth.NewAuthorizer(auth.DefaultRoles),
+	}))
+
+	for key, code := range map[string]int{"": 401, "ops-key": 403, "root-key": 200} {
+		httpReq, _ := http.NewRequest("GET", "/metrics", nil)
+		httpReq.Header.Set("X-API-Key", key)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httpReq)
+		assert.Equal(t, code, w.Code, key)
+	}
+}
@@ -0,0 +1,89 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+const sweepInterval = time.Minute
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+	limit  Limit
+}
+
+func (b *bucket) burst() float64 {
+	return float64(max(b.limit.Burst, 1))
+}
+
+// refill adds the tokens accumulated since the bucket was last used.
+func (b *bucket) refill(now time.Time) float64 {
+	return math.Min(b.burst(), b.tokens+now.Sub(b.last).Seconds()*b.limit.Rate)
+}
+
+// MemoryStore keeps token buckets in process memory. Buckets that have refilled
+// completely are dropped periodically.
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets: make(map[string]*bucket),
+	}
+}
+
+func (s *MemoryStore) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.sweep(now)
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(max(limit.Burst, 1)), last: now}
+		s.buckets[key] = b
+	}
+	b.limit = limit
+	burst := b.burst()
+
+	b.tokens = b.refill(now)
+	b.last = now
+
+	result := Result{Limit: int(burst)}
+	if b.tokens >= 1 {
+		b.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = secondsToDuration((1 - b.tokens) / limit.Rate)
+	}
+	result.Remaining = int(b.tokens)
+	result.Reset = secondsToDuration((burst - b.tokens) / limit.Rate)
+
+	return result, nil
+}
+
+// sweep drops buckets that would be full by now, as they hold no state a new
+// bucket would not have.
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+
+	for key, b := range s.buckets {
+		if b.refill(now) >= b.burst() {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
@@ -0,0 +1,116 @@
+// Package ratelimit implements token bucket rate limiting of API clients.
+package ratelimit
+
+import (
+	"context"
+	"devices_crud/internal/auth"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Limit allows Rate requests per second on average with bursts of up to Burst
+// requests. A zero Rate disables limiting.
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// Result describes the state of a bucket after taking a token from it.
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset is the time until the bucket is full again.
+	Reset time.Duration
+	// RetryAfter is the time until the next token is available, set when the
+	// request was not allowed.
+	RetryAfter time.Duration
+}
+
+// Store keeps the buckets. MemoryStore keeps them per process; a shared store
+// can implement this interface to limit across replicas.
+type Store interface {
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// Policy selects the limit of a scope, which is a REST route such as
+// "GET /v1/devices/search", a route group such as "devices" or a top-level
+// GraphQL field such as "createDevice".
+type Policy struct {
+	Default Limit
+	Groups  map[string]Limit
+	Routes  map[string]Limit
+	// Operations is keyed by the name of top-level GraphQL fields. Fields
+	// without a limit share the bucket of their group.
+	Operations map[string]Limit
+}
+
+type Limiter struct {
+	store  Store
+	policy *Policy
+}
+
+func NewLimiter(store Store, policy *Policy) *Limiter {
+	return &Limiter{store: store, policy: policy}
+}
+
+// AllowRoute takes a token for a REST request of client to route in group.
+func (l *Limiter) AllowRoute(ctx context.Context, group string, route string, client string) (Result, error) {
+	limit, ok := l.policy.Routes[route]
+	if !ok {
+		limit, ok = l.policy.Groups[group]
+	}
+	if !ok {
+		limit = l.policy.Default
+	}
+	return l.take(ctx, route, client, limit)
+}
+
+// AllowField takes a token for a top-level GraphQL field resolved for client.
+// Fields without a limit of their own take from the bucket of the group, so
+// that unknown names do not get a bucket each.
+func (l *Limiter) AllowField(ctx context.Context, group string, field string, client string) (Result, error) {
+	if limit, ok := l.policy.Operations[field]; ok {
+		return l.take(ctx, group+":"+field, client, limit)
+	}
+	limit, ok := l.policy.Groups[group]
+	if !ok {
+		limit = l.policy.Default
+	}
+	return l.take(ctx, group, client, limit)
+}
+
+func (l *Limiter) take(ctx context.Context, scope string, client string, limit Limit) (Result, error) {
+	if limit.Rate <= 0 {
+		return Result{Allowed: true, Limit: limit.Burst, Remaining: limit.Burst}, nil
+	}
+	return l.store.Take(ctx, scope+"|"+client, limit)
+}
+
+// ClientKey identifies the caller: the authenticated principal (an API key or
+// JWT subject) when there is one, the client IP otherwise.
+func ClientKey(ctx context.Context, clientIP string) string {
+	if principal, ok := auth.PrincipalFromContext(ctx); ok {
+		return principal.Method + ":" + principal.Subject
+	}
+	return "ip:" + clientIP
+}
+
+// SetHeaders writes the RateLimit-* headers describing result, and Retry-After
+// when the request was rejected.
+func SetHeaders(header http.Header, result Result) {
+	header.Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+	header.Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	header.Set("RateLimit-Reset", strconv.Itoa(Seconds(result.Reset)))
+	if !result.Allowed {
+		header.Set("Retry-After", strconv.Itoa(Seconds(result.RetryAfter)))
+	}
+}
+
+// Seconds rounds d up to whole seconds as used by the Retry-After and
+// RateLimit-Reset headers.
+func Seconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,149 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"devices_crud/internal/auth"
+	"devices_crud/internal/devices"
+	"devices_crud/internal/drivers/graph"
+	"devices_crud/internal/drivers/rest"
+	"devices_crud/internal/ratelimit"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShouldLimitBurstPerKey(t *testing.T) {
+	store := ratelimit.NewMemoryStore()
+	ctx := context.Background()
+	limit := ratelimit.Limit{Rate: 1, Burst: 2}
+
+	result, _ := store.Take(ctx, "a", limit)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 2, result.Limit)
+	assert.Equal(t, 1, result.Remaining)
+
+	result, _ = store.Take(ctx, "a", limit)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 0, result.Remaining)
+
+	result, _ = store.Take(ctx, "a", limit)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, 1, ratelimit.Seconds(result.RetryAfter))
+	assert.Equal(t, 2, ratelimit.Seconds(result.Reset))
+
+	result, _ = store.Take(ctx, "b", limit)
+	assert.True(t, result.Allowed)
+}
+
+func TestShouldSelectLimitByScope(t *testing.T) {
+	limiter := ratelimit.NewLimiter(ratelimit.NewMemoryStore(), &ratelimit.Policy{
+		Default:    ratelimit.Limit{Rate: 1, Burst: 5},
+		Groups:     map[string]ratelimit.Limit{"graphql": {Rate: 1, Burst: 3}},
+		Routes:     map[string]ratelimit.Limit{"GET /v1/devices/search": {Rate: 1, Burst: 1}},
+		Operations: map[string]ratelimit.Limit{"unlimited": {}},
+	})
+	ctx := context.Background()
+
+	result, _ := limiter.AllowRoute(ctx, "devices", "GET /v1/devices/search", "ip:1")
+	assert.Equal(t, 1, result.Limit)
+	result, _ = limiter.AllowRoute(ctx, "devices", "GET /v1/devices", "ip:1")
+	assert.Equal(t, 5, result.Limit)
+	result, _ = limiter.AllowField(ctx, "graphql", "devices", "ip:1")
+	assert.Equal(t, 3, result.Limit)
+
+	for i := 0; i < 10; i++ {
+		result, _ = limiter.AllowField(ctx, "graphql", "unlimited", "ip:1")
+		assert.True(t, result.Allowed)
+	}
+
+	// Fields without a limit of their own share the bucket of the group.
+	result, _ = limiter.AllowField(ctx, "graphql", "brands", "ip:1")
+	assert.Equal(t, 1, result.Remaining)
+	result, _ = limiter.AllowField(ctx, "graphql", "unknown", "ip:1")
+	assert.Equal(t, 0, result.Remaining)
+	result, _ = limiter.AllowField(ctx, "graphql", "", "ip:1")
+	assert.False(t, result.Allowed)
+}
+
+func TestShouldKeyClientsByPrincipalOrIP(t *testing.T) {
+	principal := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "ops", Method: auth.MethodAPIKey})
+
+	assert.Equal(t, "api_key:ops", ratelimit.ClientKey(principal, "10.0.0.1"))
+	assert.Equal(t, "ip:10.0.0.1", ratelimit.ClientKey(context.Background(), "10.0.0.1"))
+}
+
+func setupRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	deps := devices.NewDevicesDependencies(&devices.DeviceDependencies{
+		UseMocks: true,
+		Logger:   slog.New(slog.NewTextHandler(os.Stdout, nil)),
+		RateLimiter: ratelimit.NewLimiter(ratelimit.NewMemoryStore(), &ratelimit.Policy{
+			Default: ratelimit.Limit{Rate: 100, Burst: 100},
+			Routes:  map[string]ratelimit.Limit{"GET /v1/devices/search": {Rate: 0.1, Burst: 2}},
+			Operations: map[string]ratelimit.Limit{
+				"devices": {Rate: 0.1, Burst: 1},
+			},
+		}),
+	})
+	rest.BuildRoutes(router, deps)
+	graph.BuildRoutes(router, deps)
+	return router
+}
+
+func TestShouldReturnTooManyRequestsOnRestEndpoints(t *testing.T) {
+	router := setupRouter()
+
+	var w *httptest.ResponseRecorder
+	for i := 0; i < 3; i++ {
+		httpReq, _ := http.NewRequest("GET", "/v1/devices/search?q=test", nil)
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, httpReq)
+	}
+
+	assert.Equal(t, 429, w.Code)
+	assert.Equal(t, "2", w.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "0", w.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "10", w.Header().Get("Retry-After"))
+	assert.Contains(t, w.Body.String(), "RATE_LIMITED")
+
+	httpReq, _ := http.NewRequest("GET", "/v1/devices", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httpReq)
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "99", w.Header().Get("RateLimit-Remaining"))
+}
+
+func TestShouldRateLimitGraphQLOperations(t *testing.T) {
+	router := setupRouter()
+
+	// Renaming the operation or aliasing the field does not get a new bucket.
+	var w *httptest.ResponseRecorder
+	for _, body := range []string{
+		`{"query":"query ListDevices { devices { id } }","operationName":"ListDevices"}`,
+		`{"query":"query Other { all: devices { id } }","operationName":"Other"}`,
+	} {
+		httpReq, _ := http.NewRequest("POST", "/query", bytes.NewReader([]byte(body)))
+		httpReq.Header.Set("Content-Type", "application/json")
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, httpReq)
+	}
+
+	assert.Equal(t, 429, w.Code)
+	assert.Contains(t, w.Body.String(), `"code":"RATE_LIMITED"`)
+	assert.Equal(t, "10", w.Header().Get("Retry-After"))
+	assert.Equal(t, "1", w.Header().Get("RateLimit-Limit"))
+
+	httpReq, _ := http.NewRequest("POST", "/query", bytes.NewReader([]byte(`{"query":"{ brands { name } }"}`)))
+	httpReq.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httpReq)
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "100", w.Header().Get("RateLimit-Limit"))
+}
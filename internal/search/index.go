@@ -0,0 +1,221 @@
+// Package search is an in-memory inverted index over device fields with
+// prefix and typo-tolerant matching and relevance scoring.
+package search
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Fields of device documents.
+const (
+	FieldName       = "name"
+	FieldBrand      = "brand"
+	FieldAttributes = "attributes"
+)
+
+// DefaultBoosts weigh matches in the name above the brand, and both above
+// custom attributes.
+var DefaultBoosts = map[string]float64{
+	FieldName:       3,
+	FieldBrand:      2,
+	FieldAttributes: 1,
+}
+
+// Weights of the ways a query term can match an indexed token.
+const (
+	exactWeight  = 1.0
+	prefixWeight = 0.7
+	fuzzyWeight  = 0.4
+)
+
+// Document is what gets indexed for one device.
+type Document struct {
+	ID     string
+	Tenant string
+	Fields map[string]string
+}
+
+// Hit is a document matching every term of a query.
+type Hit struct {
+	ID    string
+	Score float64
+}
+
+type posting struct {
+	// frequencies counts the occurrences of the token per field.
+	frequencies map[string]int
+}
+
+type document struct {
+	tenant string
+	tokens []string
+}
+
+// Index is safe for concurrent use.
+type Index struct {
+	boosts map[string]float64
+
+	mutex     sync.RWMutex
+	postings  map[string]map[string]*posting
+	documents map[string]document
+}
+
+// NewIndex creates an empty index. Fields without a boost weigh 1.
+func NewIndex(boosts map[string]float64) *Index {
+	return &Index{
+		boosts:    boosts,
+		postings:  make(map[string]map[string]*posting),
+		documents: make(map[string]document),
+	}
+}
+
+// Index adds the document, replacing an earlier version with the same ID.
+func (i *Index) Index(doc Document) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	i.remove(doc.ID)
+
+	indexed := document{tenant: doc.Tenant}
+	for field, text := range doc.Fields {
+		for _, token := range Tokenize(text) {
+			documents, ok := i.postings[token]
+			if !ok {
+				documents = make(map[string]*posting)
+				i.postings[token] = documents
+			}
+			p, ok := documents[doc.ID]
+			if !ok {
+				p = &posting{frequencies: make(map[string]int)}
+				documents[doc.ID] = p
+				indexed.tokens = append(indexed.tokens, token)
+			}
+			p.frequencies[field]++
+		}
+	}
+	i.documents[doc.ID] = indexed
+}
+
+func (i *Index) Remove(id string) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	i.remove(id)
+}
+
+func (i *Index) remove(id string) {
+	doc, ok := i.documents[id]
+	if !ok {
+		return
+	}
+	for _, token := range doc.tokens {
+		delete(i.postings[token], id)
+		if len(i.postings[token]) == 0 {
+			delete(i.postings, token)
+		}
+	}
+	delete(i.documents, id)
+}
+
+// Len returns the number of indexed documents.
+func (i *Index) Len() int {
+	i.mutex.RLock()
+	defer i.mutex.RUnlock()
+	return len(i.documents)
+}
+
+// Search returns the documents of tenant matching every term of the query,
+// best first. An empty tenant searches all tenants. Terms match tokens
+// exactly, as a prefix or, from four characters on, with typos.
+func (i *Index) Search(query string, tenant string) []Hit {
+	terms := Tokenize(query)
+	if len(terms) == 0 {
+		return nil
+	}
+
+	i.mutex.RLock()
+	defer i.mutex.RUnlock()
+
+	var scores map[string]float64
+	for _, term := range terms {
+		termScores := i.scoreTerm(term, tenant)
+		if scores == nil {
+			scores = termScores
+			continue
+		}
+		for id, score := range scores {
+			if termScore, ok := termScores[id]; ok {
+				scores[id] = score + termScore
+			} else {
+				delete(scores, id)
+			}
+		}
+	}
+
+	hits := make([]Hit, 0, len(scores))
+	for id, score := range scores {
+		hits = append(hits, Hit{ID: id, Score: math.Round(score*1000) / 1000})
+	}
+	sort.Slice(hits, func(a, b int) bool {
+		if hits[a].Score == hits[b].Score {
+			return hits[a].ID < hits[b].ID
+		}
+		return hits[a].Score > hits[b].Score
+	})
+	return hits
+}
+
+// scoreTerm scores the documents matching a single term by the best matching
+// token and field of each document. Terms matching few documents weigh more
+// than terms matching many.
+func (i *Index) scoreTerm(term string, tenant string) map[string]float64 {
+	termRunes := []rune(term)
+	edits := maxEdits(termRunes)
+
+	scores := make(map[string]float64)
+	for token, documents := range i.postings {
+		weight := matchWeight(term, termRunes, edits, token)
+		if weight == 0 {
+			continue
+		}
+
+		for id, p := range documents {
+			if tenant != "" && i.documents[id].tenant != tenant {
+				continue
+			}
+			for field, frequency := range p.frequencies {
+				score := weight * i.boost(field) * (1 + math.Log(float64(frequency)))
+				scores[id] = max(scores[id], score)
+			}
+		}
+	}
+
+	idf := 1 + math.Log(float64(len(i.documents))/float64(max(len(scores), 1)))
+	for id := range scores {
+		scores[id] *= idf
+	}
+	return scores
+}
+
+func matchWeight(term string, termRunes []rune, edits int, token string) float64 {
+	switch {
+	case token == term:
+		return exactWeight
+	case strings.HasPrefix(token, term):
+		return prefixWeight
+	case edits > 0:
+		if distance := levenshtein(termRunes, []rune(token), edits); distance <= edits {
+			return fuzzyWeight / float64(distance)
+		}
+	}
+	return 0
+}
+
+func (i *Index) boost(field string) float64 {
+	if boost, ok := i.boosts[field]; ok {
+		return boost
+	}
+	return 1
+}
@@ -0,0 +1,98 @@
+package tests
+
+import (
+	"devices_crud/internal/search"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newIndex() *search.Index {
+	index := search.NewIndex(search.DefaultBoosts)
+	index.Index(search.Document{ID: "1", Tenant: "acme", Fields: map[string]string{
+		search.FieldName: "lab-printer-1", search.FieldBrand: "Brother",
+	}})
+	index.Index(search.Document{ID: "2", Tenant: "acme", Fields: map[string]string{
+		search.FieldName: "MacBook Pro", search.FieldBrand: "Apple",
+	}})
+	index.Index(search.Document{ID: "3", Tenant: "acme", Fields: map[string]string{
+		search.FieldName: "apple tv remote", search.FieldBrand: "Logitech",
+	}})
+	index.Index(search.Document{ID: "4", Tenant: "globex", Fields: map[string]string{
+		search.FieldName: "MacBook Air", search.FieldBrand: "Apple",
+	}})
+	return index
+}
+
+func ids(hits []search.Hit) []string {
+	found := make([]string, len(hits))
+	for i, hit := range hits {
+		found[i] = hit.ID
+	}
+	return found
+}
+
+func TestShouldTokenizeOnNonAlphanumerics(t *testing.T) {
+	assert.Equal(t, []string{"lab", "printer", "2"}, search.Tokenize("Lab-Printer_2"))
+	assert.Empty(t, search.Tokenize(" -_ "))
+}
+
+func TestShouldScoreSimilarityOfTokens(t *testing.T) {
+	assert.Equal(t, 1.0, search.Similarity("Lab Printer 2", "printer-lab-2"))
+	assert.InDelta(t, 0.9, search.Similarity("Lab Printer", "Lab Printr"), 0.01)
+	assert.Less(t, search.Similarity("MacBook Pro", "apple tv remote"), 0.5)
+	assert.Less(t, search.Similarity("Lab Printer 1", "Lab Printer 2"), 0.5)
+	assert.Greater(t, search.Similarity("Dell XPS 13", "XPS 13 9310"), 0.5)
+	assert.Zero(t, search.Similarity("", "anything"))
+}
+
+func TestShouldRequireEveryTerm(t *testing.T) {
+	index := newIndex()
+
+	assert.Equal(t, []string{"2"}, ids(index.Search("macbook apple", "acme")))
+	assert.Empty(t, index.Search("macbook brother", "acme"))
+	assert.Empty(t, index.Search("   ", "acme"))
+}
+
+func TestShouldMatchPrefixesAndTypos(t *testing.T) {
+	index := newIndex()
+
+	assert.Equal(t, []string{"1"}, ids(index.Search("print", "acme")))
+	assert.Equal(t, []string{"1"}, ids(index.Search("brothr", "acme")))
+	assert.Equal(t, []string{"2"}, ids(index.Search("macbok", "acme")))
+	// Short terms tolerate no typos.
+	assert.Empty(t, index.Search("tx", "acme"))
+}
+
+func TestShouldRankExactMatchesAndBoostedFieldsFirst(t *testing.T) {
+	index := newIndex()
+
+	hits := index.Search("apple", "acme")
+	assert.Equal(t, []string{"3", "2"}, ids(hits))
+	assert.Greater(t, hits[0].Score, hits[1].Score)
+
+	// A prefix match ranks below an exact match in the same field.
+	index.Index(search.Document{ID: "5", Tenant: "acme", Fields: map[string]string{search.FieldName: "applesauce"}})
+	hits = index.Search("apple", "acme")
+	assert.Equal(t, []string{"3", "5", "2"}, ids(hits))
+}
+
+func TestShouldScopeSearchesToTenant(t *testing.T) {
+	index := newIndex()
+
+	assert.Equal(t, []string{"4"}, ids(index.Search("air", "globex")))
+	assert.Empty(t, index.Search("air", "acme"))
+	assert.ElementsMatch(t, []string{"2", "4"}, ids(index.Search("macbook", "")))
+}
+
+func TestShouldReindexAndRemoveDocuments(t *testing.T) {
+	index := newIndex()
+
+	index.Index(search.Document{ID: "1", Tenant: "acme", Fields: map[string]string{search.FieldName: "scanner"}})
+	assert.Empty(t, index.Search("printer", "acme"))
+	assert.Equal(t, []string{"1"}, ids(index.Search("scanner", "acme")))
+
+	index.Remove("1")
+	assert.Empty(t, index.Search("scanner", "acme"))
+	assert.Equal(t, 3, index.Len())
+}
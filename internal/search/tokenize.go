@@ -0,0 +1,120 @@
+package search
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Tokenize lowercases text and splits it into runs of letters and digits, so
+// "Lab-Printer_2" becomes "lab", "printer" and "2".
+func Tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// Similarity scores how alike two texts are from 0 to 1, comparing their
+// sorted tokens by edit distance, so "Lab Printer 2" and "printer-lab-2" are
+// identical and "Lab Printer" and "Lab Printr" nearly. Numbers tell things
+// apart, so the score of texts with conflicting numbers, such as
+// "Lab Printer 1" and "Lab Printer 2", is halved. Texts without tokens are
+// like nothing.
+func Similarity(a string, b string) float64 {
+	tokensA, tokensB := Tokenize(a), Tokenize(b)
+	if len(tokensA) == 0 || len(tokensB) == 0 {
+		return 0
+	}
+	sort.Strings(tokensA)
+	sort.Strings(tokensB)
+	runesA := []rune(strings.Join(tokensA, " "))
+	runesB := []rune(strings.Join(tokensB, " "))
+	longest := max(len(runesA), len(runesB))
+	score := 1 - float64(levenshtein(runesA, runesB, longest))/float64(longest)
+	if numbersConflict(tokensA, tokensB) {
+		score /= 2
+	}
+	return score
+}
+
+// numbersConflict reports whether both token lists have numbers and neither
+// has all the numbers of the other, e.g. "XPS 13" and "XPS 13 9310" do not
+// conflict.
+func numbersConflict(a []string, b []string) bool {
+	numbersA, numbersB := numbers(a), numbers(b)
+	if len(numbersA) == 0 || len(numbersB) == 0 {
+		return false
+	}
+	return !contains(numbersA, numbersB) && !contains(numbersB, numbersA)
+}
+
+func numbers(tokens []string) map[string]bool {
+	found := map[string]bool{}
+	for _, token := range tokens {
+		if strings.IndexFunc(token, func(r rune) bool { return !unicode.IsDigit(r) }) < 0 {
+			found[token] = true
+		}
+	}
+	return found
+}
+
+func contains(set map[string]bool, subset map[string]bool) bool {
+	for value := range subset {
+		if !set[value] {
+			return false
+		}
+	}
+	return true
+}
+
+// maxEdits is the typo tolerance of a query term: short terms must match
+// exactly, as a single edit changes their meaning too much.
+func maxEdits(term []rune) int {
+	switch {
+	case len(term) >= 8:
+		return 2
+	case len(term) >= 4:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// levenshtein returns the edit distance between a and b, or limit+1 as soon as
+// it is known to exceed limit.
+func levenshtein(a, b []rune, limit int) int {
+	if abs(len(a)-len(b)) > limit {
+		return limit + 1
+	}
+
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		rowMin := current[0]
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+			rowMin = min(rowMin, current[j])
+		}
+		if rowMin > limit {
+			return limit + 1
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+func abs(value int) int {
+	if value < 0 {
+		return -value
+	}
+	return value
+}
@@ -0,0 +1,51 @@
+package tests
+
+import (
+	"devices_crud/internal/semver"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShouldParseVersions(t *testing.T) {
+	version, err := semver.Parse("v2.3.1-rc.1+build.5")
+	assert.Nil(t, err)
+	assert.Equal(t, semver.Version{Major: 2, Minor: 3, Patch: 1, Prerelease: []string{"rc", "1"}, Build: "build.5"}, version)
+	assert.Equal(t, "2.3.1-rc.1+build.5", version.String())
+
+	version, err = semver.Parse(" 2.3 ")
+	assert.Nil(t, err)
+	assert.Equal(t, "2.3.0", version.String())
+
+	for _, invalid := range []string{"", "v", "2.x", "1.2.3.4", "01.2.3", "1..2", "1.2.3-", "1.2.3-rc..1", "1.2.3-01", "1.2.3+", "1.2.3-rc_1"} {
+		_, err := semver.Parse(invalid)
+		assert.NotNil(t, err, invalid)
+	}
+}
+
+func TestShouldOrderVersions(t *testing.T) {
+	// The example ordering of https://semver.org, plus the lenient forms.
+	ordered := []string{
+		"1.0.0-alpha", "1.0.0-alpha.1", "1.0.0-alpha.beta", "1.0.0-beta", "1.0.0-beta.2", "1.0.0-beta.11",
+		"1.0.0-rc.1", "1.0.0", "1.2", "1.10.0", "2.3.1", "10.0.0",
+	}
+	shuffled := []string{"10.0.0", "1.0.0-beta.11", "1.2", "1.0.0", "1.0.0-alpha", "2.3.1", "1.0.0-rc.1",
+		"1.0.0-beta.2", "1.10.0", "1.0.0-alpha.beta", "1.0.0-beta", "1.0.0-alpha.1"}
+	sort.Slice(shuffled, func(i, j int) bool {
+		a, _ := semver.Parse(shuffled[i])
+		b, _ := semver.Parse(shuffled[j])
+		return a.Less(b)
+	})
+	assert.Equal(t, ordered, shuffled)
+
+	for _, equal := range [][2]string{{"2.3", "2.3.0"}, {"v2.3.1", "2.3.1"}, {"1.0.0+a", "1.0.0+b"}} {
+		c, err := semver.Compare(equal[0], equal[1])
+		assert.Nil(t, err)
+		assert.Equal(t, 0, c, equal)
+	}
+	c, _ := semver.Compare("2.3.0", "2.3.1")
+	assert.Equal(t, -1, c)
+	_, err := semver.Compare("2.3.1", "latest")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,185 @@
+// Package semver parses and orders semantic versions such as
+//
+//	2.3.1, v2.3.1, 2.3, 1.0.0-rc.1, 1.0.0+build.5
+//
+// following https://semver.org, except that a leading "v" and a missing minor
+// or patch number are accepted, since firmware versions are often written so.
+// Build metadata is kept but ignored when ordering.
+package semver
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type Version struct {
+	Major int
+	Minor int
+	Patch int
+	// Prerelease holds the dot-separated identifiers after "-", e.g. rc and 1.
+	Prerelease []string
+	Build      string
+}
+
+// Parse reads a version, see the package documentation for the accepted
+// forms.
+func Parse(version string) (Version, error) {
+	text := strings.TrimPrefix(strings.TrimSpace(version), "v")
+	parsed := Version{}
+	if i := strings.IndexByte(text, '+'); i >= 0 {
+		parsed.Build = text[i+1:]
+		text = text[:i]
+		if !validIdentifiers(parsed.Build, false) {
+			return Version{}, fmt.Errorf("invalid version %q: invalid build metadata", version)
+		}
+	}
+	if i := strings.IndexByte(text, '-'); i >= 0 {
+		prerelease := text[i+1:]
+		text = text[:i]
+		if !validIdentifiers(prerelease, true) {
+			return Version{}, fmt.Errorf("invalid version %q: invalid prerelease", version)
+		}
+		parsed.Prerelease = strings.Split(prerelease, ".")
+	}
+
+	numbers := strings.Split(text, ".")
+	if len(numbers) > 3 {
+		return Version{}, fmt.Errorf("invalid version %q: expected at most major.minor.patch", version)
+	}
+	for i, number := range numbers {
+		value, err := parseNumber(number)
+		if err != nil {
+			return Version{}, fmt.Errorf("invalid version %q: %w", version, err)
+		}
+		switch i {
+		case 0:
+			parsed.Major = value
+		case 1:
+			parsed.Minor = value
+		case 2:
+			parsed.Patch = value
+		}
+	}
+	return parsed, nil
+}
+
+// Compare returns -1 when v precedes other, 1 when it follows it and 0 when
+// they have the same precedence.
+func (v Version) Compare(other Version) int {
+	for _, pair := range [][2]int{{v.Major, other.Major}, {v.Minor, other.Minor}, {v.Patch, other.Patch}} {
+		if pair[0] != pair[1] {
+			return sign(pair[0] - pair[1])
+		}
+	}
+	// A prerelease precedes the release.
+	switch {
+	case len(v.Prerelease) == 0 && len(other.Prerelease) == 0:
+		return 0
+	case len(v.Prerelease) == 0:
+		return 1
+	case len(other.Prerelease) == 0:
+		return -1
+	}
+	for i := 0; i < len(v.Prerelease) && i < len(other.Prerelease); i++ {
+		if c := compareIdentifiers(v.Prerelease[i], other.Prerelease[i]); c != 0 {
+			return c
+		}
+	}
+	return sign(len(v.Prerelease) - len(other.Prerelease))
+}
+
+func (v Version) Less(other Version) bool {
+	return v.Compare(other) < 0
+}
+
+// String formats the version canonically, e.g. "v2.3" as "2.3.0".
+func (v Version) String() string {
+	text := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if len(v.Prerelease) > 0 {
+		text += "-" + strings.Join(v.Prerelease, ".")
+	}
+	if v.Build != "" {
+		text += "+" + v.Build
+	}
+	return text
+}
+
+// Compare parses both versions and compares them, see Version.Compare.
+func Compare(a string, b string) (int, error) {
+	parsedA, err := Parse(a)
+	if err != nil {
+		return 0, err
+	}
+	parsedB, err := Parse(b)
+	if err != nil {
+		return 0, err
+	}
+	return parsedA.Compare(parsedB), nil
+}
+
+func parseNumber(number string) (int, error) {
+	if number == "" || !isNumeric(number) {
+		return 0, fmt.Errorf("%q is not a number", number)
+	}
+	if len(number) > 1 && number[0] == '0' {
+		return 0, fmt.Errorf("%q has a leading zero", number)
+	}
+	return strconv.Atoi(number)
+}
+
+// compareIdentifiers orders numeric identifiers numerically and before
+// alphanumeric ones, which are ordered lexically.
+func compareIdentifiers(a string, b string) int {
+	numericA, numericB := isNumeric(a), isNumeric(b)
+	switch {
+	case numericA && numericB:
+		if len(a) != len(b) {
+			return sign(len(a) - len(b))
+		}
+		return strings.Compare(a, b)
+	case numericA:
+		return -1
+	case numericB:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+// validIdentifiers checks dot-separated identifiers of alphanumerics and "-",
+// numeric prerelease identifiers cannot have leading zeros.
+func validIdentifiers(identifiers string, prerelease bool) bool {
+	for _, identifier := range strings.Split(identifiers, ".") {
+		if identifier == "" {
+			return false
+		}
+		for _, r := range identifier {
+			if !(r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r == '-') {
+				return false
+			}
+		}
+		if prerelease && isNumeric(identifier) && len(identifier) > 1 && identifier[0] == '0' {
+			return false
+		}
+	}
+	return true
+}
+
+func isNumeric(text string) bool {
+	for _, r := range text {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return text != ""
+}
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,17 @@
+package tenancy
+
+// Quotas limits the number of devices per tenant. Zero means unlimited.
+type Quotas struct {
+	Default   int
+	PerTenant map[string]int
+}
+
+func (q *Quotas) Limit(tenantID string) int {
+	if q == nil {
+		return 0
+	}
+	if limit, ok := q.PerTenant[tenantID]; ok {
+		return limit
+	}
+	return q.Default
+}
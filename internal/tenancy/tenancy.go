@@ -0,0 +1,74 @@
+// Package tenancy carries the tenant of a request through context.Context so
+// that every layer down to the repositories can scope data to it.
+package tenancy
+
+import (
+	"context"
+	"devices_crud/internal/auth"
+	"fmt"
+)
+
+const (
+	// Header selects the tenant of principals not bound to one, and of
+	// administrators acting on behalf of another tenant.
+	Header = "X-Tenant-ID"
+	// DefaultTenant owns the data of requests that resolve no tenant at all.
+	DefaultTenant = "default"
+)
+
+type tenantKey struct{}
+type allTenantsKey struct{}
+
+func WithTenant(ctx context.Context, tenantID string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenantID)
+}
+
+// TenantFromContext returns the tenant of ctx, or DefaultTenant.
+func TenantFromContext(ctx context.Context) string {
+	tenantID, ok := ctx.Value(tenantKey{}).(string)
+	if !ok || tenantID == "" {
+		return DefaultTenant
+	}
+	return tenantID
+}
+
+// WithAllTenants marks ctx as a system context that may read across tenants.
+// It is meant for background jobs and metrics, never for request contexts.
+func WithAllTenants(ctx context.Context) context.Context {
+	return context.WithValue(ctx, allTenantsKey{}, true)
+}
+
+func AllTenants(ctx context.Context) bool {
+	all, _ := ctx.Value(allTenantsKey{}).(bool)
+	return all
+}
+
+// Resolve determines the tenant of a request from its principal and the tenant
+// header and returns ctx carrying it. The header may only select a tenant the
+// principal is not bound to when the principal is a devices administrator.
+// Requests without a principal may only use it when authorization is disabled
+// altogether, that is without an authorizer.
+func Resolve(ctx context.Context, header string, authorizer *auth.Authorizer) (context.Context, error) {
+	principal, authenticated := auth.PrincipalFromContext(ctx)
+	tenantID := ""
+	if authenticated {
+		tenantID = principal.TenantID
+	}
+	if header == "" || header == tenantID {
+		return WithTenant(ctx, tenantID), nil
+	}
+
+	if !authenticated {
+		if authorizer != nil {
+			return nil, fmt.Errorf("%w: selecting tenant %s requires a principal", auth.ErrForbidden, header)
+		}
+		return WithTenant(ctx, header), nil
+	}
+	if authorizer == nil || !authorizer.HasPermission(principal, auth.PermissionDevicesAdmin) {
+		if principal.TenantID == "" {
+			return nil, fmt.Errorf("%w: %s may not select tenant %s", auth.ErrForbidden, principal.Subject, header)
+		}
+		return nil, fmt.Errorf("%w: %s is bound to tenant %s", auth.ErrForbidden, principal.Subject, principal.TenantID)
+	}
+	return WithTenant(ctx, header), nil
+}
@@ -0,0 +1,225 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"devices_crud/internal/auth"
+	"devices_crud/internal/devices"
+	"devices_crud/internal/devices/app"
+	"devices_crud/internal/devices/app/ports"
+	"devices_crud/internal/devices/model"
+	"devices_crud/internal/drivers/rest"
+	"devices_crud/internal/tenancy"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func getDeviceService() *app.DeviceService {
+	return app.NewDeviceService(ports.NewDevicesRepositoryMock(), slog.New(slog.NewTextHandler(os.Stdout, nil)))
+}
+
+func TestShouldIsolateDevicesBetweenTenants(t *testing.T) {
+	service := getDeviceService()
+	acme := tenancy.WithTenant(context.Background(), "acme")
+	globex := tenancy.WithTenant(context.Background(), "globex")
+
+	id, err := service.AddDevice(acme, &model.NewDeviceRequest{Name: "phone", DeviceBrand: "Apple"})
+	assert.Nil(t, err)
+	_, err = service.AddDevice(globex, &model.NewDeviceRequest{Name: "tablet", DeviceBrand: "Apple"})
+	assert.Nil(t, err)
+
+	device, err := service.GetDevice(acme, *id)
+	assert.Nil(t, err)
+	assert.Equal(t, "acme", device.TenantID)
+
+	device, err = service.GetDevice(globex, *id)
+	assert.Nil(t, err)
+	assert.Nil(t, device)
+
+	found, _ := service.GetAllDevices(globex)
+	assert.Equal(t, 1, len(found))
+	assert.Equal(t, "tablet", found[0].Name)
+
+	results, _ := service.SearchDevices(globex, "apple")
+	assert.Equal(t, 1, len(results))
+	assert.Equal(t, "globex", results[0].TenantID)
+
+	name := "stolen"
+	patched, err := service.PatchDevice(globex, &model.PatchDeviceRequest{ID: *id, Name: &name})
+	assert.Nil(t, err)
+	assert.Nil(t, patched)
+
+	_, err = service.ReplaceDevice(globex, &model.Device{ID: *id, Name: "stolen"})
+	assert.True(t, errors.Is(err, model.ErrDeviceNotFound))
+
+	assert.Nil(t, service.DeleteDevice(globex, *id))
+	device, _ = service.GetDevice(acme, *id)
+	assert.Equal(t, "phone", device.Name)
+
+	all, _ := service.GetAllDevices(tenancy.WithAllTenants(context.Background()))
+	assert.Equal(t, 2, len(all))
+}
+
+func TestShouldEnforceQuotaPerTenant(t *testing.T) {
+	service := getDeviceService()
+	service.Quotas = &tenancy.Quotas{Default: 1, PerTenant: map[string]int{"acme": 2}}
+	acme := tenancy.WithTenant(context.Background(), "acme")
+	globex := tenancy.WithTenant(context.Background(), "globex")
+
+	for i := 0; i < 2; i++ {
+		_, err := service.AddDevice(acme, &model.NewDeviceRequest{Name: fmt.Sprintf("device_%d", i)})
+		assert.Nil(t, err)
+	}
+	_, err := service.AddDevice(acme, &model.NewDeviceRequest{Name: "device_2"})
+	assert.True(t, errors.Is(err, model.ErrQuotaExceeded))
+
+	_, err = service.AddDevice(globex, &model.NewDeviceRequest{Name: "device_0"})
+	assert.Nil(t, err)
+	_, err = service.ReplaceDevice(globex, &model.Device{ID: "new", Name: "device_1"})
+	assert.True(t, errors.Is(err, model.ErrQuotaExceeded))
+}
+
+// slowRepository pauses before every save, so that concurrent creates overlap.
+type slowRepository struct {
+	ports.DevicesRepository
+}
+
+func (r slowRepository) Save(ctx context.Context, device *model.Device, limit int) (*string, error) {
+	time.Sleep(10 * time.Millisecond)
+	return r.DevicesRepository.Save(ctx, device, limit)
+}
+
+func TestShouldEnforceQuotaOnConcurrentCreates(t *testing.T) {
+	service := getDeviceService()
+	service.DevicesRepository = slowRepository{service.DevicesRepository}
+	service.Quotas = &tenancy.Quotas{Default: 5}
+	acme := tenancy.WithTenant(context.Background(), "acme")
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	for i := 0; i < 20; i++ {
+		i := i
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var err error
+			if i%2 == 0 {
+				_, err = service.AddDevice(acme, &model.NewDeviceRequest{Name: fmt.Sprintf("device_%d", i)})
+			} else {
+				_, err = service.ReplaceDevice(acme, &model.Device{ID: fmt.Sprintf("new_%d", i), Name: fmt.Sprintf("device_%d", i)})
+			}
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	created := 0
+	for err := range errs {
+		if err == nil {
+			created++
+		} else {
+			assert.True(t, errors.Is(err, model.ErrQuotaExceeded))
+		}
+	}
+	assert.Equal(t, 5, created)
+	found, _ := service.GetAllDevices(acme)
+	assert.Equal(t, 5, len(found))
+}
+
+func TestShouldResolveTenantFromPrincipalOrHeader(t *testing.T) {
+	authorizer := auth.NewAuthorizer(auth.DefaultRoles)
+	bound := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "jane", Roles: []string{"editor"}, TenantID: "acme"})
+	admin := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "root", Roles: []string{"admin"}, TenantID: "acme"})
+	unbound := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "ops", Roles: []string{"operator"}})
+	unboundAdmin := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "sre", Roles: []string{"admin"}})
+
+	ctx, err := tenancy.Resolve(context.Background(), "", authorizer)
+	assert.Nil(t, err)
+	assert.Equal(t, tenancy.DefaultTenant, tenancy.TenantFromContext(ctx))
+
+	// Without a principal the header is only honoured when authorization is
+	// disabled.
+	_, err = tenancy.Resolve(context.Background(), "globex", authorizer)
+	assert.True(t, errors.Is(err, auth.ErrForbidden))
+	ctx, err = tenancy.Resolve(context.Background(), "globex", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "globex", tenancy.TenantFromContext(ctx))
+
+	// Principals not bound to a tenant need devices:admin to select one.
+	ctx, err = tenancy.Resolve(unbound, "", authorizer)
+	assert.Nil(t, err)
+	assert.Equal(t, tenancy.DefaultTenant, tenancy.TenantFromContext(ctx))
+	_, err = tenancy.Resolve(unbound, "globex", authorizer)
+	assert.True(t, errors.Is(err, auth.ErrForbidden))
+	ctx, err = tenancy.Resolve(unboundAdmin, "globex", authorizer)
+	assert.Nil(t, err)
+	assert.Equal(t, "globex", tenancy.TenantFromContext(ctx))
+
+	ctx, err = tenancy.Resolve(bound, "", authorizer)
+	assert.Nil(t, err)
+	assert.Equal(t, "acme", tenancy.TenantFromContext(ctx))
+
+	_, err = tenancy.Resolve(bound, "globex", authorizer)
+	assert.True(t, errors.Is(err, auth.ErrForbidden))
+
+	ctx, err = tenancy.Resolve(admin, "globex", authorizer)
+	assert.Nil(t, err)
+	assert.Equal(t, "globex", tenancy.TenantFromContext(ctx))
+}
+
+func TestShouldScopeRestRequestsToTenant(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	keysFile := filepath.Join(t.TempDir(), "keys.json")
+	keys, _ := json.Marshal([]auth.APIKey{
+		{Name: "acme-ops", Hash: auth.HashAPIKey("acme-key"), Roles: []string{"editor"}, Tenant: "acme"},
+		{Name: "globex-ops", Hash: auth.HashAPIKey("globex-key"), Roles: []string{"editor"}, Tenant: "globex"},
+	})
+	os.WriteFile(keysFile, keys, 0o600)
+	authenticator, err := auth.NewAuthenticator(&auth.Options{APIKeysFile: keysFile})
+	assert.Nil(t, err)
+
+	router := gin.New()
+	rest.BuildRoutes(router, devices.NewDevicesDependencies(&devices.DeviceDependencies{
+		UseMocks:      true,
+		Logger:        slog.New(slog.NewTextHandler(os.Stdout, nil)),
+		Authenticator: authenticator,
+		Authorizer:    auth.NewAuthorizer(auth.DefaultRoles),
+	}))
+
+	send := func(method string, path string, key string, body string) *httptest.ResponseRecorder {
+		httpReq, _ := http.NewRequest(method, path, bytes.NewReader([]byte(body)))
+		httpReq.Header.Set("X-API-Key", key)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httpReq)
+		return w
+	}
+
+	w := send("POST", "/v1/devices", "acme-key", `{"name":"phone","deviceBrand":"Apple"}`)
+	assert.Equal(t, 201, w.Code)
+	created := model.NewDeviceResponse{}
+	json.Unmarshal(w.Body.Bytes(), &created)
+
+	assert.Equal(t, 200, send("GET", "/v1/devices/"+created.UUID, "acme-key", "").Code)
+	assert.Equal(t, 404, send("GET", "/v1/devices/"+created.UUID, "globex-key", "").Code)
+	assert.Equal(t, "[]", send("GET", "/v1/devices", "globex-key", "").Body.String())
+
+	httpReq, _ := http.NewRequest("GET", "/v1/devices", nil)
+	httpReq.Header.Set("X-API-Key", "globex-key")
+	httpReq.Header.Set(tenancy.Header, "acme")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httpReq)
+	assert.Equal(t, 403, w.Code)
+}
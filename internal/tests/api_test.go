@@ -3,7 +3,7 @@ package tests
 import (
 	"devices_crud/internal/devices"
 	"devices_crud/internal/drivers/rest"
-	"log"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -16,7 +16,7 @@ import (
 func setupRouter() *gin.Engine {
 	router := gin.Default()
 	rest.BuildRoutes(router, devices.NewDevicesDependencies(
-		&devices.DeviceDependencies{UseMocks: true, Logger: log.New(os.Stdout, "TEST: ", log.Ltime)}))
+		&devices.DeviceDependencies{UseMocks: true, Logger: slog.New(slog.NewTextHandler(os.Stdout, nil))}))
 	return router
 }
 
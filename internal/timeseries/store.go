@@ -0,0 +1,253 @@
+// Package timeseries is an in-memory time-series store which keeps recent
+// points as they are and downsamples older ones into coarser buckets.
+package timeseries
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Tier keeps the points up to Retention old, at Resolution. A zero Resolution
+// keeps the raw points.
+type Tier struct {
+	Resolution time.Duration
+	Retention  time.Duration
+}
+
+// DefaultTiers keep raw points for a day, 5 minute buckets for a week and
+// hourly buckets for a year.
+var DefaultTiers = []Tier{
+	{Resolution: 0, Retention: 24 * time.Hour},
+	{Resolution: 5 * time.Minute, Retention: 7 * 24 * time.Hour},
+	{Resolution: time.Hour, Retention: 365 * 24 * time.Hour},
+}
+
+// compactionInterval bounds how often Append compacts every series.
+const compactionInterval = time.Minute
+
+type Point struct {
+	At    time.Time
+	Value float64
+}
+
+// Bucket summarizes the points from Start for the resolution of its tier, a
+// raw point is a bucket of one.
+type Bucket struct {
+	Start  time.Time
+	Count  int
+	Min    float64
+	Max    float64
+	Sum    float64
+	Last   float64
+	LastAt time.Time
+}
+
+func (b *Bucket) Mean() float64 {
+	if b.Count == 0 {
+		return 0
+	}
+	return b.Sum / float64(b.Count)
+}
+
+func (b *Bucket) merge(other Bucket) {
+	if b.Count == 0 {
+		start := b.Start
+		*b = other
+		b.Start = start
+		return
+	}
+	b.Count += other.Count
+	b.Min = math.Min(b.Min, other.Min)
+	b.Max = math.Max(b.Max, other.Max)
+	b.Sum += other.Sum
+	if !other.LastAt.Before(b.LastAt) {
+		b.Last = other.Last
+		b.LastAt = other.LastAt
+	}
+}
+
+func pointBucket(point Point) Bucket {
+	return Bucket{Start: point.At, Count: 1, Min: point.Value, Max: point.Value, Sum: point.Value, Last: point.Value, LastAt: point.At}
+}
+
+type series struct {
+	// tiers holds the buckets of every tier, sorted by start.
+	tiers  [][]Bucket
+	latest Point
+}
+
+// Store is safe for concurrent use.
+type Store struct {
+	mutex  sync.RWMutex
+	tiers  []Tier
+	series map[string]*series
+	// compacted is when every series was last compacted.
+	compacted time.Time
+}
+
+// NewStore keeps points in the tiers, which must be ordered by increasing
+// retention. Points older than the retention of the last tier are dropped.
+func NewStore(tiers []Tier) *Store {
+	if len(tiers) == 0 {
+		tiers = DefaultTiers
+	}
+	return &Store{tiers: append([]Tier(nil), tiers...), series: map[string]*series{}}
+}
+
+// Append adds the points to the series of the key, in any order, and
+// downsamples the points of the series which aged out of their tier as of now.
+// The other series are compacted at most once a minute.
+func (s *Store) Append(key string, points []Point, now time.Time) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	current, ok := s.series[key]
+	if !ok {
+		current = &series{tiers: make([][]Bucket, len(s.tiers))}
+		s.series[key] = current
+	}
+	for _, point := range points {
+		if point.At.After(current.latest.At) {
+			current.latest = point
+		}
+		if tier := s.tierOf(now.Sub(point.At)); tier >= 0 {
+			current.tiers[tier] = s.insert(current.tiers[tier], tier, pointBucket(point))
+		}
+	}
+	s.compact(current, now)
+	if now.Sub(s.compacted) >= compactionInterval {
+		for _, other := range s.series {
+			s.compact(other, now)
+		}
+		s.compacted = now
+	}
+}
+
+// Latest returns the most recent point ever appended to the series of the
+// key, however old.
+func (s *Store) Latest(key string) (Point, bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	current, ok := s.series[key]
+	if !ok {
+		return Point{}, false
+	}
+	return current.latest, true
+}
+
+// Range returns the buckets of the series of the key starting in [from, to),
+// the oldest first. Their resolution is the one of the tier they are in.
+func (s *Store) Range(key string, from time.Time, to time.Time) []Bucket {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	buckets := make([]Bucket, 0)
+	current, ok := s.series[key]
+	if !ok {
+		return buckets
+	}
+	for tier := len(current.tiers) - 1; tier >= 0; tier-- {
+		stored := current.tiers[tier]
+		first := sort.Search(len(stored), func(i int) bool { return !stored[i].Start.Before(from) })
+		for _, bucket := range stored[first:] {
+			if !bucket.Start.Before(to) {
+				break
+			}
+			buckets = append(buckets, bucket)
+		}
+	}
+	// Late points can leave a coarser bucket after a finer one.
+	sort.SliceStable(buckets, func(i, j int) bool { return buckets[i].Start.Before(buckets[j].Start) })
+	return buckets
+}
+
+// Aggregate merges the buckets of the series of the key starting in
+// [from, to) into buckets of step from from, or into a single bucket without
+// a step. Empty steps are left out.
+func (s *Store) Aggregate(key string, from time.Time, to time.Time, step time.Duration) []Bucket {
+	aggregates := make([]Bucket, 0)
+	for _, bucket := range s.Range(key, from, to) {
+		start := from
+		if step > 0 {
+			start = from.Add(bucket.Start.Sub(from) / step * step)
+		}
+		if len(aggregates) == 0 || !aggregates[len(aggregates)-1].Start.Equal(start) {
+			aggregates = append(aggregates, Bucket{Start: start})
+		}
+		aggregates[len(aggregates)-1].merge(bucket)
+	}
+	return aggregates
+}
+
+// Keys returns the keys of the stored series starting with prefix, sorted.
+func (s *Store) Keys(prefix string) []string {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	keys := make([]string, 0)
+	for key := range s.series {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (s *Store) Delete(key string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delete(s.series, key)
+}
+
+// tierOf returns the tier of points of the given age, -1 when they are too
+// old to keep.
+func (s *Store) tierOf(age time.Duration) int {
+	for i, tier := range s.tiers {
+		if age < tier.Retention {
+			return i
+		}
+	}
+	return -1
+}
+
+// insert adds the bucket to the buckets of the tier, merging it into the
+// bucket it falls into on downsampled tiers.
+func (s *Store) insert(buckets []Bucket, tier int, bucket Bucket) []Bucket {
+	if resolution := s.tiers[tier].Resolution; resolution > 0 {
+		bucket.Start = bucket.Start.Truncate(resolution)
+	}
+	i := sort.Search(len(buckets), func(i int) bool { return !buckets[i].Start.Before(bucket.Start) })
+	if s.tiers[tier].Resolution > 0 && i < len(buckets) && buckets[i].Start.Equal(bucket.Start) {
+		buckets[i].merge(bucket)
+		return buckets
+	}
+	buckets = append(buckets, Bucket{})
+	copy(buckets[i+1:], buckets[i:])
+	buckets[i] = bucket
+	return buckets
+}
+
+// compact moves the buckets of the series which aged out of their tier to the
+// next one, or drops them from the last one.
+func (s *Store) compact(current *series, now time.Time) {
+	for tier := range s.tiers {
+		cutoff := now.Add(-s.tiers[tier].Retention)
+		stored := current.tiers[tier]
+		expired := sort.Search(len(stored), func(i int) bool { return !stored[i].Start.Before(cutoff) })
+		if expired == 0 {
+			continue
+		}
+		if tier+1 < len(s.tiers) {
+			for _, bucket := range stored[:expired] {
+				current.tiers[tier+1] = s.insert(current.tiers[tier+1], tier+1, bucket)
+			}
+		}
+		current.tiers[tier] = append(stored[:0], stored[expired:]...)
+	}
+}
@@ -0,0 +1,123 @@
+package tests
+
+import (
+	"devices_crud/internal/timeseries"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var tiers = []timeseries.Tier{
+	{Resolution: 0, Retention: time.Hour},
+	{Resolution: 10 * time.Minute, Retention: 24 * time.Hour},
+	{Resolution: time.Hour, Retention: 7 * 24 * time.Hour},
+}
+
+func TestShouldKeepRecentPointsAsTheyAre(t *testing.T) {
+	store := timeseries.NewStore(tiers)
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	store.Append("1/battery", []timeseries.Point{
+		{At: now.Add(-time.Minute), Value: 80},
+		{At: now.Add(-3 * time.Minute), Value: 90},
+		{At: now.Add(-2 * time.Minute), Value: 85},
+	}, now)
+
+	buckets := store.Range("1/battery", now.Add(-time.Hour), now)
+	if assert.Len(t, buckets, 3) {
+		assert.Equal(t, 90.0, buckets[0].Mean())
+		assert.Equal(t, 85.0, buckets[1].Mean())
+		assert.Equal(t, 80.0, buckets[2].Mean())
+	}
+	assert.Len(t, store.Range("1/battery", now.Add(-2*time.Minute), now.Add(-time.Minute)), 1)
+
+	latest, ok := store.Latest("1/battery")
+	assert.True(t, ok)
+	assert.Equal(t, 80.0, latest.Value)
+	_, ok = store.Latest("1/temperature")
+	assert.False(t, ok)
+}
+
+func TestShouldDownsampleAgingPoints(t *testing.T) {
+	store := timeseries.NewStore(tiers)
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	for minute := 0; minute < 20; minute++ {
+		store.Append("1/temperature", []timeseries.Point{{At: start.Add(time.Duration(minute) * time.Minute), Value: float64(minute)}}, start.Add(time.Duration(minute)*time.Minute))
+	}
+	assert.Len(t, store.Range("1/temperature", start, start.Add(time.Hour)), 20)
+
+	// Two hours later the points are older than the raw tier and summarized
+	// per 10 minutes.
+	later := start.Add(2 * time.Hour)
+	store.Append("1/temperature", []timeseries.Point{{At: later, Value: 100}}, later)
+	buckets := store.Range("1/temperature", start, later.Add(time.Minute))
+	if assert.Len(t, buckets, 3) {
+		assert.Equal(t, start, buckets[0].Start)
+		assert.Equal(t, 10, buckets[0].Count)
+		assert.Equal(t, 0.0, buckets[0].Min)
+		assert.Equal(t, 9.0, buckets[0].Max)
+		assert.Equal(t, 4.5, buckets[0].Mean())
+		assert.Equal(t, 9.0, buckets[0].Last)
+		assert.Equal(t, 14.5, buckets[1].Mean())
+		assert.Equal(t, 100.0, buckets[2].Mean())
+	}
+
+	// Two days later they are hourly, and after a week they are gone.
+	later = start.Add(48 * time.Hour)
+	store.Append("1/temperature", []timeseries.Point{{At: later, Value: 50}}, later)
+	buckets = store.Range("1/temperature", start, later)
+	if assert.Len(t, buckets, 2) {
+		assert.Equal(t, 20, buckets[0].Count)
+		assert.Equal(t, 9.5, buckets[0].Mean())
+	}
+	later = start.Add(8 * 24 * time.Hour)
+	store.Append("1/temperature", []timeseries.Point{{At: later.Add(-30 * 24 * time.Hour), Value: 1}}, later)
+	assert.Empty(t, store.Range("1/temperature", start.Add(-30*24*time.Hour), start.Add(24*time.Hour)))
+	assert.Len(t, store.Range("1/temperature", start, later), 1)
+
+	latest, _ := store.Latest("1/temperature")
+	assert.Equal(t, 50.0, latest.Value)
+}
+
+func TestShouldAggregatePerStep(t *testing.T) {
+	store := timeseries.NewStore(tiers)
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	points := []timeseries.Point{}
+	for minute := 1; minute <= 30; minute++ {
+		points = append(points, timeseries.Point{At: now.Add(-time.Duration(minute) * time.Minute), Value: float64(minute)})
+	}
+	store.Append("1/uptime", points, now)
+
+	aggregates := store.Aggregate("1/uptime", now.Add(-30*time.Minute), now, 0)
+	if assert.Len(t, aggregates, 1) {
+		assert.Equal(t, 30, aggregates[0].Count)
+		assert.Equal(t, 1.0, aggregates[0].Min)
+		assert.Equal(t, 30.0, aggregates[0].Max)
+		assert.Equal(t, 1.0, aggregates[0].Last)
+	}
+
+	aggregates = store.Aggregate("1/uptime", now.Add(-30*time.Minute), now, 10*time.Minute)
+	if assert.Len(t, aggregates, 3) {
+		assert.Equal(t, now.Add(-30*time.Minute), aggregates[0].Start)
+		assert.Equal(t, 10, aggregates[0].Count)
+		assert.Equal(t, 25.5, aggregates[0].Mean())
+		assert.Equal(t, now.Add(-10*time.Minute), aggregates[2].Start)
+	}
+	assert.Empty(t, store.Aggregate("1/uptime", now.Add(-2*time.Hour), now.Add(-time.Hour), time.Minute))
+}
+
+func TestShouldListAndDeleteSeries(t *testing.T) {
+	store := timeseries.NewStore(nil)
+	now := time.Now()
+
+	store.Append("1/battery", []timeseries.Point{{At: now, Value: 1}}, now)
+	store.Append("1/temperature", []timeseries.Point{{At: now, Value: 1}}, now)
+	store.Append("12/battery", []timeseries.Point{{At: now, Value: 1}}, now)
+
+	assert.Equal(t, []string{"1/battery", "1/temperature"}, store.Keys("1/"))
+	store.Delete("1/battery")
+	assert.Equal(t, []string{"1/temperature"}, store.Keys("1/"))
+}
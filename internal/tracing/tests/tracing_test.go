@@ -0,0 +1,120 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"devices_crud/internal/devices"
+	"devices_crud/internal/drivers/graph"
+	"devices_crud/internal/drivers/rest"
+	"devices_crud/internal/tracing"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+const (
+	traceID     = "4bf92f3577b34da6a3ce929d0e0e4736"
+	traceparent = "00-" + traceID + "-00f067aa0ba902b7-01"
+)
+
+func setupRouter() (*gin.Engine, *tracetest.SpanRecorder) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	deps := devices.NewDevicesDependencies(&devices.DeviceDependencies{
+		UseMocks:       true,
+		Logger:         slog.New(slog.NewTextHandler(os.Stdout, nil)),
+		TracerProvider: provider,
+	})
+	rest.BuildRoutes(router, deps)
+	graph.BuildRoutes(router, deps)
+	return router, recorder
+}
+
+func spansByName(recorder *tracetest.SpanRecorder) map[string]sdktrace.ReadOnlySpan {
+	spans := make(map[string]sdktrace.ReadOnlySpan)
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = span
+	}
+	return spans
+}
+
+func TestShouldContinueTraceparentIntoServiceAndRepository(t *testing.T) {
+	router, recorder := setupRouter()
+
+	httpReq, _ := http.NewRequest("GET", "/v1/devices/unknown", nil)
+	httpReq.Header.Set("traceparent", traceparent)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httpReq)
+	assert.Equal(t, 404, w.Code)
+
+	spans := spansByName(recorder)
+	request, service, repository := spans["GET /v1/devices/:id"], spans["DeviceService.GetDevice"], spans["DevicesRepository.FindByID"]
+	if !assert.NotNil(t, request) || !assert.NotNil(t, service) || !assert.NotNil(t, repository) {
+		return
+	}
+
+	assert.Equal(t, traceID, request.SpanContext().TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", request.Parent().SpanID().String())
+	assert.Equal(t, request.SpanContext().SpanID(), service.Parent().SpanID())
+	assert.Equal(t, service.SpanContext().SpanID(), repository.Parent().SpanID())
+	assert.Equal(t, traceID, repository.SpanContext().TraceID().String())
+}
+
+func TestShouldTraceGraphQLMutationsDownToTheRepository(t *testing.T) {
+	router, recorder := setupRouter()
+
+	body := `{"query":"mutation CreateDevice { createDevice(input: {name: \"lab-1\", deviceBrand: \"Apple\"}) { id } }"}`
+	httpReq, _ := http.NewRequest("POST", "/query", bytes.NewReader([]byte(body)))
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("traceparent", traceparent)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httpReq)
+	assert.Equal(t, 200, w.Code)
+	assert.NotContains(t, w.Body.String(), "errors")
+
+	spans := spansByName(recorder)
+	request, operation, field := spans["POST /query"], spans["mutation CreateDevice"], spans["Mutation.createDevice"]
+	service, repository := spans["DeviceService.AddDevice"], spans["DevicesRepository.Save"]
+	if !assert.NotNil(t, request) || !assert.NotNil(t, operation) || !assert.NotNil(t, field) ||
+		!assert.NotNil(t, service) || !assert.NotNil(t, repository) {
+		return
+	}
+
+	assert.Equal(t, traceID, repository.SpanContext().TraceID().String())
+	assert.Equal(t, request.SpanContext().SpanID(), operation.Parent().SpanID())
+	assert.Equal(t, operation.SpanContext().SpanID(), field.Parent().SpanID())
+	assert.Equal(t, field.SpanContext().SpanID(), service.Parent().SpanID())
+	assert.Equal(t, service.SpanContext().SpanID(), repository.Parent().SpanID())
+}
+
+func TestShouldExportSpansToFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "spans.json")
+	provider, shutdown, err := tracing.NewTracerProvider(&tracing.Options{Exporter: tracing.ExporterFile, File: file})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	_, span := provider.Tracer("test").Start(context.Background(), "exported")
+	span.End()
+	assert.NoError(t, shutdown(context.Background()))
+
+	content, err := os.ReadFile(file)
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), `"Name":"exported"`)
+}
+
+func TestShouldRejectUnknownExporter(t *testing.T) {
+	_, _, err := tracing.NewTracerProvider(&tracing.Options{Exporter: "jaeger"})
+	assert.Error(t, err)
+}
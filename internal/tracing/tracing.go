@@ -0,0 +1,102 @@
+// Package tracing sets up OpenTelemetry tracing for the drivers, the device
+// service and the repositories.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	// ExporterFile appends spans as JSON lines to a file, which keeps traces
+	// available without a collector.
+	ExporterFile = "file"
+
+	serviceName = "devices_crud"
+)
+
+// Propagator reads and writes W3C traceparent and tracestate headers.
+var Propagator propagation.TextMapPropagator = propagation.TraceContext{}
+
+type Options struct {
+	Exporter string
+	// File is the destination of ExporterFile.
+	File string
+}
+
+// NewTracerProvider builds a provider exporting spans with the configured
+// exporter. The returned function flushes and closes the exporter.
+func NewTracerProvider(options *Options) (*sdktrace.TracerProvider, func(context.Context) error, error) {
+	var writer io.Writer
+	var file *os.File
+	switch options.Exporter {
+	case ExporterStdout:
+		writer = os.Stdout
+	case ExporterFile:
+		if options.File == "" {
+			return nil, nil, fmt.Errorf("tracing: the %s exporter requires a file", ExporterFile)
+		}
+		var err error
+		file, err = os.OpenFile(options.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, nil, fmt.Errorf("tracing: %w", err)
+		}
+		writer = file
+	default:
+		return nil, nil, fmt.Errorf("tracing: unknown exporter %q", options.Exporter)
+	}
+
+	exporter, err := stdouttrace.New(stdouttrace.WithWriter(writer))
+	if err != nil {
+		return nil, nil, fmt.Errorf("tracing: %w", err)
+	}
+
+	// Both exporters write locally, so spans are exported as they end and
+	// nothing is lost when the process exits.
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithSyncer(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(serviceName))),
+	)
+
+	shutdown := func(ctx context.Context) error {
+		err := provider.Shutdown(ctx)
+		if file != nil {
+			if closeErr := file.Close(); err == nil {
+				err = closeErr
+			}
+		}
+		return err
+	}
+	return provider, shutdown, nil
+}
+
+// Tracer returns the tracer of a component, falling back to a no-op tracer
+// when tracing is disabled.
+func Tracer(provider trace.TracerProvider, component string) trace.Tracer {
+	if provider == nil {
+		provider = noop.NewTracerProvider()
+	}
+	return provider.Tracer("devices_crud/" + component)
+}
+
+// End marks the span as failed when err is set and ends it.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -3,22 +3,147 @@ package main
 import (
 	"devices_crud/config"
 
+	"devices_crud/internal/auth"
 	"devices_crud/internal/devices"
+	"devices_crud/internal/devices/model"
 	"devices_crud/internal/drivers/graph"
+	"devices_crud/internal/drivers/rest"
+	"devices_crud/internal/logging"
+	"devices_crud/internal/metrics"
+	"devices_crud/internal/ratelimit"
+	"devices_crud/internal/tenancy"
+	"devices_crud/internal/tracing"
 	"log"
+	"log/slog"
 	"os"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/trace"
 )
 
 func main() {
 	config := config.NewConfig()
 
-	logger := log.New(os.Stdout, "devices_crud: ", log.Ldate|log.Ltime|log.Lshortfile)
+	logger, err := newLogger(&config.Logging)
+	if err != nil {
+		log.Fatalf("Error configuring logging: %s", err)
+	}
+
+	var authenticator auth.Authenticator
+	var authorizer *auth.Authorizer
+	if config.Auth.Enabled {
+		authenticator, err = auth.NewAuthenticator(&auth.Options{
+			APIKeysFile: config.Auth.APIKeysFile,
+			JWKSFile:    config.Auth.JWKSFile,
+			HMACSecret:  config.Auth.HMACSecret,
+			Issuer:      config.Auth.Issuer,
+			Audience:    config.Auth.Audience,
+		})
+		if err != nil {
+			fatal(logger, "Error configuring authentication", err)
+		}
+
+		roles := auth.DefaultRoles
+		if config.Auth.RolesFile != "" {
+			roles, err = auth.LoadRoles(config.Auth.RolesFile)
+			if err != nil {
+				fatal(logger, "Error configuring roles", err)
+			}
+		}
+		authorizer = auth.NewAuthorizer(roles)
+	}
+
+	var rateLimiter *ratelimit.Limiter
+	if config.RateLimit.Enabled {
+		rateLimiter = ratelimit.NewLimiter(ratelimit.NewMemoryStore(), &ratelimit.Policy{
+			Default:    ratelimit.Limit(config.RateLimit.Default),
+			Groups:     rateLimits(config.RateLimit.Groups),
+			Routes:     rateLimits(config.RateLimit.Routes),
+			Operations: rateLimits(config.RateLimit.Operations),
+		})
+	}
+
+	var devicesMetrics *metrics.Metrics
+	if config.Metrics.Enabled {
+		devicesMetrics = metrics.New()
+	}
+
+	var tracerProvider trace.TracerProvider
+	if config.Tracing.Exporter != tracing.ExporterNone {
+		provider, _, err := tracing.NewTracerProvider(&tracing.Options{
+			Exporter: config.Tracing.Exporter,
+			File:     config.Tracing.File,
+		})
+		if err != nil {
+			fatal(logger, "Error configuring tracing", err)
+		}
+		tracerProvider = provider
+	}
+
+	uniqueKeys, err := model.ParseUniquenessKeys(config.DevicesService.UniqueKeys)
+	if err != nil {
+		fatal(logger, "Error configuring unique keys", err)
+	}
+
 	devicesDependencies := devices.NewDevicesDependencies(
-		&devices.DeviceDependencies{UseMocks: config.DevicesService.UseMocks, Logger: logger})
+		&devices.DeviceDependencies{
+			UseMocks:      config.DevicesService.UseMocks,
+			Logger:        logger,
+			Authenticator: authenticator,
+			Authorizer:    authorizer,
+			Quotas: &tenancy.Quotas{
+				Default:   config.Tenancy.DefaultQuota,
+				PerTenant: config.Tenancy.Quotas,
+			},
+			RateLimiter:            rateLimiter,
+			Metrics:                devicesMetrics,
+			TracerProvider:         tracerProvider,
+			ContractExpiryInterval: config.Contracts.ExpiryInterval,
+			ContractExpiryWindows:  config.Contracts.ExpiryWindows,
+			Connectivity: model.ConnectivityPolicy{
+				Timeout:      config.Connectivity.HeartbeatTimeout,
+				TypeTimeouts: config.Connectivity.TypeTimeouts,
+			},
+			ConnectivityCheckInterval: config.Connectivity.CheckInterval,
+			UniqueKeys:                uniqueKeys,
+		})
+
+	router := gin.New()
+	router.Use(gin.Recovery())
+	rest.BuildRoutes(router, devicesDependencies)
+	graph.BuildRoutes(router, devicesDependencies)
+	fatal(logger, "Server stopped", router.Run(":"+config.Router.Port))
+}
+
+func newLogger(config *config.LoggingConfig) (*slog.Logger, error) {
+	level, err := logging.ParseLevel(config.Level)
+	if err != nil {
+		return nil, err
+	}
+	levels := make(map[string]slog.Level, len(config.Levels))
+	for component, name := range config.Levels {
+		if levels[component], err = logging.ParseLevel(name); err != nil {
+			return nil, err
+		}
+	}
+
+	return logging.New(&logging.Options{
+		Format: config.Format,
+		Level:  level,
+		Levels: levels,
+		Redact: config.Redact,
+	})
+}
+
+func fatal(logger *slog.Logger, message string, err error) {
+	logger.Error(message, "error", err)
+	os.Exit(1)
+}
 
-	// router := gin.Default()
-	// rest.BuildRoutes(router, devicesDependencies)
-	// router.Run(":" + config.Router.Port)
-	gqlServer := graph.RunServer(devicesDependencies)
-	gqlServer.Run()
+func rateLimits(limits map[string]config.RateLimit) map[string]ratelimit.Limit {
+	converted := make(map[string]ratelimit.Limit, len(limits))
+	for name, limit := range limits {
+		converted[name] = ratelimit.Limit(limit)
+	}
+	return converted
 }
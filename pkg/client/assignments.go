@@ -0,0 +1,85 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Assignment records who holds a device, active until it is checked in.
+type Assignment struct {
+	ID       string `json:"id"`
+	DeviceID string `json:"deviceId"`
+	// Assignee identifies the person holding the device, e.g. an email address.
+	Assignee     string     `json:"assignee"`
+	CheckedOutAt time.Time  `json:"checkedOutAt"`
+	DueAt        *time.Time `json:"dueAt,omitempty"`
+	CheckedInAt  *time.Time `json:"checkedInAt,omitempty"`
+	Note         string     `json:"note,omitempty"`
+	// CheckedOutBy is the subject of the principal who lent the device, if any.
+	CheckedOutBy string `json:"checkedOutBy,omitempty"`
+	TenantID     string `json:"tenantId"`
+}
+
+// CheckOutRequest lends a device, and hands it over to another person when
+// transferring it.
+type CheckOutRequest struct {
+	Assignee string     `json:"assignee"`
+	DueAt    *time.Time `json:"dueAt,omitempty"`
+	Note     string     `json:"note,omitempty"`
+}
+
+type checkInRequest struct {
+	Note string `json:"note,omitempty"`
+}
+
+// CheckOutDevice lends an in-service device to request.Assignee.
+func (c *Client) CheckOutDevice(ctx context.Context, id string, request *CheckOutRequest) (*Assignment, error) {
+	assignment := &Assignment{}
+	if _, err := c.do(ctx, http.MethodPost, devicePath(id)+"/checkout", request, assignment); err != nil {
+		return nil, err
+	}
+	return assignment, nil
+}
+
+// CheckInDevice ends the active assignment of the device and returns it.
+func (c *Client) CheckInDevice(ctx context.Context, id string, note string) (*Assignment, error) {
+	assignment := &Assignment{}
+	request := &checkInRequest{Note: note}
+	if _, err := c.do(ctx, http.MethodPost, devicePath(id)+"/checkin", request, assignment); err != nil {
+		return nil, err
+	}
+	return assignment, nil
+}
+
+// TransferDevice hands a checked out device over to request.Assignee and
+// returns the new assignment.
+func (c *Client) TransferDevice(ctx context.Context, id string, request *CheckOutRequest) (*Assignment, error) {
+	assignment := &Assignment{}
+	if _, err := c.do(ctx, http.MethodPost, devicePath(id)+"/transfer", request, assignment); err != nil {
+		return nil, err
+	}
+	return assignment, nil
+}
+
+func (c *Client) DeviceAssignments(ctx context.Context, id string) ([]Assignment, error) {
+	assignments := make([]Assignment, 0)
+	_, err := c.do(ctx, http.MethodGet, devicePath(id)+"/assignments", nil, &assignments)
+	return assignments, err
+}
+
+func (c *Client) AssigneeAssignments(ctx context.Context, assignee string) ([]Assignment, error) {
+	assignments := make([]Assignment, 0)
+	_, err := c.do(ctx, http.MethodGet, "/v1/assignments?assignee="+url.QueryEscape(assignee), nil, &assignments)
+	return assignments, err
+}
+
+// OverdueAssignments returns the assignments overdue at the given time, the
+// longest overdue first.
+func (c *Client) OverdueAssignments(ctx context.Context, at time.Time) ([]Assignment, error) {
+	assignments := make([]Assignment, 0)
+	path := "/v1/assignments/overdue?at=" + url.QueryEscape(at.Format(time.RFC3339))
+	_, err := c.do(ctx, http.MethodGet, path, nil, &assignments)
+	return assignments, err
+}
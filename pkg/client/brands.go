@@ -0,0 +1,101 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Brand is a catalog entry naming a brand canonically. The server stores
+// device brands matching the name or one of the aliases under the name.
+type Brand struct {
+	Name      string        `json:"name"`
+	Aliases   []string      `json:"aliases,omitempty"`
+	Models    []DeviceModel `json:"models,omitempty"`
+	CreatedAt time.Time     `json:"createdAt"`
+	TenantID  string        `json:"tenantId"`
+}
+
+// DeviceModel is a model of a brand, such as "iPhone 15", with its other
+// spellings.
+type DeviceModel struct {
+	Name    string   `json:"name"`
+	Aliases []string `json:"aliases,omitempty"`
+}
+
+type MigrateBrandsRequest struct {
+	// DryRun reports the changes without applying them.
+	DryRun bool `json:"dryRun"`
+	// AddMissing merges the spellings of brands which are not in the catalog
+	// into their most common one and adds it to the catalog with the others as
+	// aliases.
+	AddMissing bool `json:"addMissing"`
+}
+
+// BrandMigration reports the devices whose brand a migration renamed, or would
+// rename in a dry run, and the brands it added to the catalog.
+type BrandMigration struct {
+	DryRun  bool          `json:"dryRun"`
+	Changes []BrandChange `json:"changes"`
+	Added   []Brand       `json:"added"`
+}
+
+type BrandChange struct {
+	DeviceID string `json:"deviceId"`
+	From     string `json:"from"`
+	To       string `json:"to"`
+}
+
+func (c *Client) ListBrands(ctx context.Context) ([]Brand, error) {
+	brands := make([]Brand, 0)
+	_, err := c.do(ctx, http.MethodGet, "/v1/brands", nil, &brands)
+	return brands, err
+}
+
+func (c *Client) GetBrand(ctx context.Context, name string) (*Brand, error) {
+	brand := &Brand{}
+	if _, err := c.do(ctx, http.MethodGet, brandPath(name), nil, brand); err != nil {
+		return nil, err
+	}
+	return brand, nil
+}
+
+// ResolveBrand returns the catalog brand name is a spelling of.
+func (c *Client) ResolveBrand(ctx context.Context, name string) (*Brand, error) {
+	response := &struct {
+		Brand *Brand `json:"brand"`
+	}{}
+	if _, err := c.do(ctx, http.MethodGet, "/v1/brands/resolve?name="+url.QueryEscape(name), nil, response); err != nil {
+		return nil, err
+	}
+	return response.Brand, nil
+}
+
+// SaveBrand creates the brand or replaces the brand of the same name.
+func (c *Client) SaveBrand(ctx context.Context, brand *Brand) (*Brand, error) {
+	saved := &Brand{}
+	if _, err := c.do(ctx, http.MethodPut, brandPath(brand.Name), brand, saved); err != nil {
+		return nil, err
+	}
+	return saved, nil
+}
+
+func (c *Client) DeleteBrand(ctx context.Context, name string) error {
+	_, err := c.do(ctx, http.MethodDelete, brandPath(name), nil, nil)
+	return err
+}
+
+// MigrateBrands renames the brands of stored devices to their catalog names,
+// or only reports the renames with request.DryRun.
+func (c *Client) MigrateBrands(ctx context.Context, request *MigrateBrandsRequest) (*BrandMigration, error) {
+	migration := &BrandMigration{}
+	if _, err := c.do(ctx, http.MethodPost, "/v1/brands/migrate", request, migration); err != nil {
+		return nil, err
+	}
+	return migration, nil
+}
+
+func brandPath(name string) string {
+	return "/v1/brands/" + url.PathEscape(name)
+}
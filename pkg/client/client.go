@@ -0,0 +1,186 @@
+// Package client is a Go SDK for the devices REST and GraphQL APIs.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultMaxRetries = 3
+	defaultMinBackoff = 100 * time.Millisecond
+	defaultMaxBackoff = 5 * time.Second
+)
+
+// Client talks to a devices server. It is safe for concurrent use.
+type Client struct {
+	baseURL    string
+	token      string
+	httpClient *http.Client
+	maxRetries int
+	minBackoff time.Duration
+	maxBackoff time.Duration
+}
+
+type Option func(*Client)
+
+// WithToken sends token as a bearer token with every request.
+func WithToken(token string) Option {
+	return func(c *Client) {
+		c.token = token
+	}
+}
+
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithRetries sets how many times a request is retried after a 429 or 5xx
+// response. Zero disables retries.
+func WithRetries(maxRetries int) Option {
+	return func(c *Client) {
+		c.maxRetries = maxRetries
+	}
+}
+
+// WithBackoff bounds the randomized exponential backoff between retries.
+func WithBackoff(min time.Duration, max time.Duration) Option {
+	return func(c *Client) {
+		c.minBackoff = min
+		c.maxBackoff = max
+	}
+}
+
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		maxRetries: defaultMaxRetries,
+		minBackoff: defaultMinBackoff,
+		maxBackoff: defaultMaxBackoff,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// do sends a JSON request and decodes the JSON response into out. It returns the
+// response headers so callers can read metadata such as X-Total-Count.
+func (c *Client) do(ctx context.Context, method string, path string, body any, out any) (http.Header, error) {
+	var payload []byte
+	if body != nil {
+		var err error
+		payload, err = json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for attempt := 0; ; attempt++ {
+		resp, err := c.send(ctx, method, path, payload)
+		if err != nil {
+			return nil, err
+		}
+
+		if resp.StatusCode < 400 {
+			defer resp.Body.Close()
+			if out != nil && resp.StatusCode != http.StatusNoContent {
+				if err := json.NewDecoder(resp.Body).Decode(out); err != nil && err != io.EOF {
+					return nil, err
+				}
+			}
+			return resp.Header, nil
+		}
+
+		apiErr := newAPIError(method, path, resp)
+		resp.Body.Close()
+
+		if attempt >= c.maxRetries || !retryable(method, resp.StatusCode) {
+			return nil, apiErr
+		}
+		if err := c.wait(ctx, attempt, apiErr.RetryAfter); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (c *Client) send(ctx context.Context, method string, path string, payload []byte) (*http.Response, error) {
+	var reader io.Reader
+	if payload != nil {
+		reader = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	return c.httpClient.Do(req)
+}
+
+// retryable reports whether a failed request may be sent again. Rate limited and
+// unavailable responses were not processed, so they are retried for any method;
+// other server errors only for methods that are safe to repeat.
+func retryable(method string, status int) bool {
+	switch status {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		return true
+	}
+	return status >= 500 && method != http.MethodPost
+}
+
+// wait sleeps for the server provided Retry-After, or otherwise for a random
+// duration up to an exponentially growing cap ("full jitter").
+func (c *Client) wait(ctx context.Context, attempt int, retryAfter time.Duration) error {
+	delay := retryAfter
+	if delay <= 0 {
+		ceiling := c.minBackoff << attempt
+		if ceiling <= 0 || ceiling > c.maxBackoff {
+			ceiling = c.maxBackoff
+		}
+		delay = c.minBackoff
+		if ceiling > c.minBackoff {
+			delay += time.Duration(rand.Int63n(int64(ceiling - c.minBackoff)))
+		}
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return time.Until(at)
+	}
+	return 0
+}
@@ -0,0 +1,46 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// Connectivity is whether a device sends heartbeats.
+type Connectivity string
+
+const (
+	ConnectivityOnline  Connectivity = "online"
+	ConnectivityOffline Connectivity = "offline"
+	// ConnectivityUnknown is the state of devices which never sent a heartbeat.
+	ConnectivityUnknown Connectivity = "unknown"
+)
+
+// ConnectivityStatus is the connectivity of a device as of At.
+type ConnectivityStatus struct {
+	DeviceID   string       `json:"deviceId"`
+	Status     Connectivity `json:"status"`
+	LastSeenAt *time.Time   `json:"lastSeenAt,omitempty"`
+	// Timeout is how long after LastSeenAt the device goes offline, e.g. "5m0s".
+	Timeout string    `json:"timeout"`
+	At      time.Time `json:"at"`
+}
+
+// Heartbeat records that the device is alive and returns its connectivity.
+func (c *Client) Heartbeat(ctx context.Context, id string) (*ConnectivityStatus, error) {
+	status := &ConnectivityStatus{}
+	if _, err := c.do(ctx, http.MethodPost, devicePath(id)+"/heartbeat", nil, status); err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+// DeviceConnectivity returns whether the device is online, offline or never
+// sent a heartbeat.
+func (c *Client) DeviceConnectivity(ctx context.Context, id string) (*ConnectivityStatus, error) {
+	status := &ConnectivityStatus{}
+	if _, err := c.do(ctx, http.MethodGet, devicePath(id)+"/connectivity", nil, status); err != nil {
+		return nil, err
+	}
+	return status, nil
+}
@@ -0,0 +1,86 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+type ContractKind string
+
+const (
+	ContractWarranty ContractKind = "warranty"
+	ContractSupport  ContractKind = "support"
+)
+
+// Contract is a warranty or a support contract covering a device from
+// StartsAt until EndsAt.
+type Contract struct {
+	ID       string       `json:"id"`
+	DeviceID string       `json:"deviceId"`
+	Kind     ContractKind `json:"kind"`
+	Provider string       `json:"provider"`
+	// Reference is the number of the contract at the provider, if any.
+	Reference string    `json:"reference,omitempty"`
+	StartsAt  time.Time `json:"startsAt"`
+	EndsAt    time.Time `json:"endsAt"`
+	// Coverage lists what the contract covers, e.g. parts, labor or onsite.
+	Coverage  []string  `json:"coverage"`
+	CreatedAt time.Time `json:"createdAt"`
+	// CreatedBy is the subject of the principal who added the contract, if any.
+	CreatedBy string `json:"createdBy,omitempty"`
+	TenantID  string `json:"tenantId"`
+}
+
+type ContractRequest struct {
+	Kind      ContractKind `json:"kind"`
+	Provider  string       `json:"provider"`
+	Reference string       `json:"reference,omitempty"`
+	StartsAt  time.Time    `json:"startsAt"`
+	EndsAt    time.Time    `json:"endsAt"`
+	Coverage  []string     `json:"coverage,omitempty"`
+}
+
+// ExpiringContract is a contract ending soon.
+type ExpiringContract struct {
+	Contract Contract `json:"contract"`
+	DaysLeft int      `json:"daysLeft"`
+	// Renewed is true when another contract of the same kind covers the device
+	// from the end of this one, so that the device does not lose coverage.
+	Renewed bool `json:"renewed"`
+}
+
+func (c *Client) AddContract(ctx context.Context, id string, request *ContractRequest) (*Contract, error) {
+	contract := &Contract{}
+	if _, err := c.do(ctx, http.MethodPost, devicePath(id)+"/contracts", request, contract); err != nil {
+		return nil, err
+	}
+	return contract, nil
+}
+
+func (c *Client) DeleteContract(ctx context.Context, id string, contractID string) error {
+	_, err := c.do(ctx, http.MethodDelete, devicePath(id)+"/contracts/"+url.PathEscape(contractID), nil, nil)
+	return err
+}
+
+// Contracts returns the warranty and support contracts of the device, the
+// earliest start first.
+func (c *Client) Contracts(ctx context.Context, id string) ([]Contract, error) {
+	contracts := make([]Contract, 0)
+	_, err := c.do(ctx, http.MethodGet, devicePath(id)+"/contracts", nil, &contracts)
+	return contracts, err
+}
+
+// ExpiringContracts returns the contracts of every device ending within days,
+// only of the kind unless it is empty, the soonest first.
+func (c *Client) ExpiringContracts(ctx context.Context, days int, kind string) ([]ExpiringContract, error) {
+	query := url.Values{"days": {strconv.Itoa(days)}}
+	if kind != "" {
+		query.Set("kind", kind)
+	}
+	contracts := make([]ExpiringContract, 0)
+	_, err := c.do(ctx, http.MethodGet, "/v1/contracts/expiring?"+query.Encode(), nil, &contracts)
+	return contracts, err
+}
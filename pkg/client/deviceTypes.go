@@ -0,0 +1,51 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// DeviceType constrains the attributes of the devices of that type with a JSON
+// Schema.
+type DeviceType struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	Schema      json.RawMessage `json:"schema"`
+	CreatedAt   time.Time       `json:"createdAt"`
+	TenantID    string          `json:"tenantId"`
+}
+
+func (c *Client) ListDeviceTypes(ctx context.Context) ([]DeviceType, error) {
+	deviceTypes := make([]DeviceType, 0)
+	_, err := c.do(ctx, http.MethodGet, "/v1/device-types", nil, &deviceTypes)
+	return deviceTypes, err
+}
+
+func (c *Client) GetDeviceType(ctx context.Context, name string) (*DeviceType, error) {
+	deviceType := &DeviceType{}
+	if _, err := c.do(ctx, http.MethodGet, deviceTypePath(name), nil, deviceType); err != nil {
+		return nil, err
+	}
+	return deviceType, nil
+}
+
+// SaveDeviceType creates the device type or replaces the type of the same name.
+func (c *Client) SaveDeviceType(ctx context.Context, deviceType *DeviceType) (*DeviceType, error) {
+	saved := &DeviceType{}
+	if _, err := c.do(ctx, http.MethodPut, deviceTypePath(deviceType.Name), deviceType, saved); err != nil {
+		return nil, err
+	}
+	return saved, nil
+}
+
+func (c *Client) DeleteDeviceType(ctx context.Context, name string) error {
+	_, err := c.do(ctx, http.MethodDelete, deviceTypePath(name), nil, nil)
+	return err
+}
+
+func deviceTypePath(name string) string {
+	return "/v1/device-types/" + url.PathEscape(name)
+}
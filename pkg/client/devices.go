@@ -0,0 +1,225 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Device is a device as the API returns it.
+type Device struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	DeviceBrand string    `json:"deviceBrand"`
+	CreatedAt   time.Time `json:"createdAt"`
+	TenantID    string    `json:"tenantId"`
+	// Type names the DeviceType whose schema Attributes satisfy.
+	Type       string                 `json:"type,omitempty"`
+	Attributes map[string]interface{} `json:"attributes,omitempty"`
+	Labels     map[string]string      `json:"labels,omitempty"`
+	// Status only changes through TransitionDevice, and LocationID through
+	// MoveDevice. ReplaceDevice leaves both alone.
+	Status          Status           `json:"status"`
+	StatusHistory   []StatusChange   `json:"statusHistory,omitempty"`
+	LocationID      string           `json:"locationId,omitempty"`
+	LocationHistory []LocationChange `json:"locationHistory,omitempty"`
+	// LastSeenAt is when the device last sent a heartbeat.
+	LastSeenAt *time.Time `json:"lastSeenAt,omitempty"`
+}
+
+type NewDeviceRequest struct {
+	Name        string                 `json:"name"`
+	DeviceBrand string                 `json:"deviceBrand"`
+	Type        string                 `json:"type,omitempty"`
+	Attributes  map[string]interface{} `json:"attributes,omitempty"`
+	Labels      map[string]string      `json:"labels,omitempty"`
+	// Status is the status the device enters the fleet with, ordered when
+	// empty.
+	Status     Status `json:"status,omitempty"`
+	LocationID string `json:"locationId,omitempty"`
+}
+
+type newDeviceResponse struct {
+	UUID string `json:"uuid"`
+}
+
+// PatchDeviceRequest updates the fields which are not nil.
+type PatchDeviceRequest struct {
+	ID          string  `json:"id"`
+	Name        *string `json:"name"`
+	DeviceBrand *string `json:"deviceBrand"`
+	Type        *string `json:"type,omitempty"`
+	// Attributes are merged into the attributes of the device, nil values
+	// remove attributes.
+	Attributes map[string]interface{} `json:"attributes,omitempty"`
+	// Labels are merged into the labels of the device, nil values remove
+	// labels.
+	Labels map[string]*string `json:"labels,omitempty"`
+}
+
+type SearchResult struct {
+	Device
+	Score float64 `json:"score"`
+}
+
+// Status is a step of the device lifecycle.
+type Status string
+
+const (
+	StatusOrdered     Status = "ordered"
+	StatusReceived    Status = "received"
+	StatusProvisioned Status = "provisioned"
+	StatusInService   Status = "in_service"
+	StatusInRepair    Status = "in_repair"
+	StatusRetired     Status = "retired"
+	StatusDisposed    Status = "disposed"
+)
+
+type StatusChange struct {
+	From   Status    `json:"from,omitempty"`
+	To     Status    `json:"to"`
+	Reason string    `json:"reason,omitempty"`
+	At     time.Time `json:"at"`
+	// Actor is the subject of the principal who made the change, if any.
+	Actor string `json:"actor,omitempty"`
+}
+
+type transitionRequest struct {
+	Status Status `json:"status"`
+	Reason string `json:"reason"`
+}
+
+type ListOptions struct {
+	Limit  int
+	Offset int
+	// Filter is a filter expression such as `brand = "Apple" AND name ~ "lab-*"`.
+	Filter string
+	// Selector is a label selector such as `env=prod,team in (a,b),!retired`.
+	Selector string
+	// Connectivity keeps the devices which are online, offline or never sent
+	// a heartbeat.
+	Connectivity Connectivity
+}
+
+// DevicePage is one page of ListDevices results. Total is the number of devices
+// across all pages.
+type DevicePage struct {
+	Devices []Device
+	Total   int
+}
+
+func (c *Client) ListDevices(ctx context.Context, opts *ListOptions) (*DevicePage, error) {
+	query := url.Values{}
+	if opts != nil {
+		if opts.Limit > 0 {
+			query.Set("limit", strconv.Itoa(opts.Limit))
+		}
+		if opts.Offset > 0 {
+			query.Set("offset", strconv.Itoa(opts.Offset))
+		}
+		if opts.Filter != "" {
+			query.Set("filter", opts.Filter)
+		}
+		if opts.Selector != "" {
+			query.Set("selector", opts.Selector)
+		}
+		if opts.Connectivity != "" {
+			query.Set("connectivity", string(opts.Connectivity))
+		}
+	}
+
+	path := "/v1/devices"
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+
+	page := &DevicePage{}
+	header, err := c.do(ctx, http.MethodGet, path, nil, &page.Devices)
+	if err != nil {
+		return nil, err
+	}
+
+	page.Total, err = strconv.Atoi(header.Get("X-Total-Count"))
+	if err != nil {
+		page.Total = len(page.Devices)
+	}
+	return page, nil
+}
+
+func (c *Client) GetDevice(ctx context.Context, id string) (*Device, error) {
+	device := &Device{}
+	if _, err := c.do(ctx, http.MethodGet, devicePath(id), nil, device); err != nil {
+		return nil, err
+	}
+	return device, nil
+}
+
+// CreateDevice creates a device and returns its ID.
+func (c *Client) CreateDevice(ctx context.Context, device *NewDeviceRequest) (string, error) {
+	response := &newDeviceResponse{}
+	if _, err := c.do(ctx, http.MethodPost, "/v1/devices", device, response); err != nil {
+		return "", err
+	}
+	return response.UUID, nil
+}
+
+func (c *Client) ReplaceDevice(ctx context.Context, device *Device) (*Device, error) {
+	replaced := &Device{}
+	if _, err := c.do(ctx, http.MethodPut, devicePath(device.ID), device, replaced); err != nil {
+		return nil, err
+	}
+	return replaced, nil
+}
+
+func (c *Client) PatchDevice(ctx context.Context, device *PatchDeviceRequest) error {
+	_, err := c.do(ctx, http.MethodPatch, devicePath(device.ID), device, nil)
+	return err
+}
+
+func (c *Client) DeleteDevice(ctx context.Context, id string) error {
+	_, err := c.do(ctx, http.MethodDelete, devicePath(id), nil, nil)
+	return err
+}
+
+// SetLabels adds labels to the device, replacing the values of existing keys,
+// and returns the updated device.
+func (c *Client) SetLabels(ctx context.Context, id string, labels map[string]string) (*Device, error) {
+	device := &Device{}
+	if _, err := c.do(ctx, http.MethodPut, devicePath(id)+"/labels", labels, device); err != nil {
+		return nil, err
+	}
+	return device, nil
+}
+
+func (c *Client) RemoveLabel(ctx context.Context, id string, key string) (*Device, error) {
+	device := &Device{}
+	if _, err := c.do(ctx, http.MethodDelete, devicePath(id)+"/labels/"+url.PathEscape(key), nil, device); err != nil {
+		return nil, err
+	}
+	return device, nil
+}
+
+// TransitionDevice moves the device to another status of its lifecycle and
+// returns the updated device.
+func (c *Client) TransitionDevice(ctx context.Context, id string, status Status, reason string) (*Device, error) {
+	device := &Device{}
+	request := &transitionRequest{Status: status, Reason: reason}
+	if _, err := c.do(ctx, http.MethodPost, devicePath(id)+"/transitions", request, device); err != nil {
+		return nil, err
+	}
+	return device, nil
+}
+
+// SearchDevices returns the devices matching every term of query by name, brand
+// or attributes, most relevant first.
+func (c *Client) SearchDevices(ctx context.Context, query string) ([]SearchResult, error) {
+	results := make([]SearchResult, 0)
+	_, err := c.do(ctx, http.MethodGet, "/v1/devices/search?q="+url.QueryEscape(query), nil, &results)
+	return results, err
+}
+
+func devicePath(id string) string {
+	return "/v1/devices/" + url.PathEscape(id)
+}
@@ -0,0 +1,58 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// DuplicateCandidate is a pair of devices which may be the same one. Listing
+// the whole inventory, Device is the older of the two, which Duplicate would
+// be merged into.
+type DuplicateCandidate struct {
+	Device    Device  `json:"device"`
+	Duplicate Device  `json:"duplicate"`
+	Score     float64 `json:"score"`
+	// Key is the uniqueness key the devices share, if any. Devices sharing a
+	// key score 1 whatever their names.
+	Key string `json:"key,omitempty"`
+}
+
+type MergeRequest struct {
+	// SourceID is the device merged into the other one and removed.
+	SourceID string `json:"sourceId"`
+}
+
+// DuplicateCandidates pairs the devices which may be the same one, scoring at
+// least threshold, or the server's default when zero, the most likely first.
+func (c *Client) DuplicateCandidates(ctx context.Context, threshold float64) ([]DuplicateCandidate, error) {
+	candidates := make([]DuplicateCandidate, 0)
+	_, err := c.do(ctx, http.MethodGet, "/v1/devices/duplicates"+thresholdQuery(threshold), nil, &candidates)
+	return candidates, err
+}
+
+// DeviceDuplicates returns the devices which may be the same as the device,
+// see DuplicateCandidates.
+func (c *Client) DeviceDuplicates(ctx context.Context, id string, threshold float64) ([]DuplicateCandidate, error) {
+	candidates := make([]DuplicateCandidate, 0)
+	_, err := c.do(ctx, http.MethodGet, devicePath(id)+"/duplicates"+thresholdQuery(threshold), nil, &candidates)
+	return candidates, err
+}
+
+// MergeDevices merges the device sourceID into the device and returns the
+// merged device. Getting sourceID afterwards returns the merged device too.
+func (c *Client) MergeDevices(ctx context.Context, id string, sourceID string) (*Device, error) {
+	device := &Device{}
+	if _, err := c.do(ctx, http.MethodPost, devicePath(id)+"/merge", &MergeRequest{SourceID: sourceID}, device); err != nil {
+		return nil, err
+	}
+	return device, nil
+}
+
+func thresholdQuery(threshold float64) string {
+	if threshold == 0 {
+		return ""
+	}
+	return "?" + url.Values{"threshold": {strconv.FormatFloat(threshold, 'f', -1, 64)}}.Encode()
+}
@@ -0,0 +1,202 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Sentinel errors mirroring the errors reported by the server. Use errors.Is to
+// check an error returned by the client against them.
+var (
+	ErrBadRequest      = errors.New("bad request")
+	ErrUnauthenticated = errors.New("unauthenticated")
+	ErrForbidden       = errors.New("forbidden")
+	ErrNotFound        = errors.New("not found")
+	ErrConflict        = errors.New("conflict")
+	ErrRateLimited     = errors.New("rate limited")
+	ErrQuotaExceeded   = errors.New("quota exceeded")
+	ErrServer          = errors.New("server error")
+)
+
+// Sentinel errors for the error codes of the server. Each wraps ErrBadRequest or
+// ErrConflict, so that callers can check for the kind of error or the precise
+// one.
+var (
+	ErrInvalidFilter          = fmt.Errorf("%w: invalid filter", ErrBadRequest)
+	ErrInvalidSelector        = fmt.Errorf("%w: invalid label selector", ErrBadRequest)
+	ErrInvalidLabels          = fmt.Errorf("%w: invalid labels", ErrBadRequest)
+	ErrInvalidAttributes      = fmt.Errorf("%w: invalid attributes", ErrBadRequest)
+	ErrUnknownDeviceType      = fmt.Errorf("%w: unknown device type", ErrBadRequest)
+	ErrInvalidDeviceType      = fmt.Errorf("%w: invalid device type", ErrBadRequest)
+	ErrInvalidStatus          = fmt.Errorf("%w: invalid status", ErrBadRequest)
+	ErrInvalidTransition      = fmt.Errorf("%w: invalid transition", ErrBadRequest)
+	ErrInvalidAssignment      = fmt.Errorf("%w: invalid assignment", ErrBadRequest)
+	ErrInvalidLocation        = fmt.Errorf("%w: invalid location", ErrBadRequest)
+	ErrUnknownLocation        = fmt.Errorf("%w: unknown location", ErrBadRequest)
+	ErrInvalidRelationship    = fmt.Errorf("%w: invalid relationship", ErrBadRequest)
+	ErrInvalidMaintenancePlan = fmt.Errorf("%w: invalid maintenance plan", ErrBadRequest)
+	ErrInvalidServiceRecord   = fmt.Errorf("%w: invalid service record", ErrBadRequest)
+	ErrInvalidContract        = fmt.Errorf("%w: invalid contract", ErrBadRequest)
+	ErrInvalidReading         = fmt.Errorf("%w: invalid reading", ErrBadRequest)
+	ErrInvalidConnectivity    = fmt.Errorf("%w: invalid connectivity", ErrBadRequest)
+	ErrInvalidSoftware        = fmt.Errorf("%w: invalid software", ErrBadRequest)
+	ErrInvalidSoftwarePolicy  = fmt.Errorf("%w: invalid software policy", ErrBadRequest)
+	ErrInvalidMerge           = fmt.Errorf("%w: invalid merge", ErrBadRequest)
+	ErrInvalidThreshold       = fmt.Errorf("%w: invalid duplicate threshold", ErrBadRequest)
+	ErrInvalidBrand           = fmt.Errorf("%w: invalid brand", ErrBadRequest)
+
+	ErrDeviceTypeInUse      = fmt.Errorf("%w: device type in use", ErrConflict)
+	ErrIllegalTransition    = fmt.Errorf("%w: illegal status transition", ErrConflict)
+	ErrDeviceUnavailable    = fmt.Errorf("%w: device unavailable", ErrConflict)
+	ErrDeviceAssigned       = fmt.Errorf("%w: device already checked out", ErrConflict)
+	ErrDeviceNotAssigned    = fmt.Errorf("%w: device not checked out", ErrConflict)
+	ErrLocationCycle        = fmt.Errorf("%w: location cycle", ErrConflict)
+	ErrLocationNotEmpty     = fmt.Errorf("%w: location not empty", ErrConflict)
+	ErrDeviceMoved          = fmt.Errorf("%w: device moved concurrently", ErrConflict)
+	ErrRelationshipConflict = fmt.Errorf("%w: relationship conflict", ErrConflict)
+	ErrRelationshipCycle    = fmt.Errorf("%w: relationship cycle", ErrConflict)
+	ErrDeviceHasChildren    = fmt.Errorf("%w: device contains other devices", ErrConflict)
+	ErrDuplicateDevice      = fmt.Errorf("%w: duplicate device", ErrConflict)
+)
+
+// codeErrors maps the error codes of the server to the sentinel errors above.
+var codeErrors = map[string]error{
+	"INVALID_FILTER":           ErrInvalidFilter,
+	"INVALID_SELECTOR":         ErrInvalidSelector,
+	"INVALID_LABELS":           ErrInvalidLabels,
+	"INVALID_ATTRIBUTES":       ErrInvalidAttributes,
+	"UNKNOWN_DEVICE_TYPE":      ErrUnknownDeviceType,
+	"INVALID_DEVICE_TYPE":      ErrInvalidDeviceType,
+	"INVALID_STATUS":           ErrInvalidStatus,
+	"INVALID_TRANSITION":       ErrInvalidTransition,
+	"INVALID_ASSIGNMENT":       ErrInvalidAssignment,
+	"INVALID_LOCATION":         ErrInvalidLocation,
+	"UNKNOWN_LOCATION":         ErrUnknownLocation,
+	"INVALID_RELATIONSHIP":     ErrInvalidRelationship,
+	"INVALID_MAINTENANCE_PLAN": ErrInvalidMaintenancePlan,
+	"INVALID_SERVICE_RECORD":   ErrInvalidServiceRecord,
+	"INVALID_CONTRACT":         ErrInvalidContract,
+	"INVALID_READING":          ErrInvalidReading,
+	"INVALID_CONNECTIVITY":     ErrInvalidConnectivity,
+	"INVALID_SOFTWARE":         ErrInvalidSoftware,
+	"INVALID_SOFTWARE_POLICY":  ErrInvalidSoftwarePolicy,
+	"INVALID_MERGE":            ErrInvalidMerge,
+	"INVALID_THRESHOLD":        ErrInvalidThreshold,
+	"INVALID_BRAND":            ErrInvalidBrand,
+	"DEVICE_TYPE_IN_USE":       ErrDeviceTypeInUse,
+	"ILLEGAL_TRANSITION":       ErrIllegalTransition,
+	"DEVICE_UNAVAILABLE":       ErrDeviceUnavailable,
+	"DEVICE_ASSIGNED":          ErrDeviceAssigned,
+	"DEVICE_NOT_ASSIGNED":      ErrDeviceNotAssigned,
+	"LOCATION_CYCLE":           ErrLocationCycle,
+	"LOCATION_NOT_EMPTY":       ErrLocationNotEmpty,
+	"DEVICE_MOVED":             ErrDeviceMoved,
+	"RELATIONSHIP_CONFLICT":    ErrRelationshipConflict,
+	"RELATIONSHIP_CYCLE":       ErrRelationshipCycle,
+	"DEVICE_HAS_CHILDREN":      ErrDeviceHasChildren,
+	"DUPLICATE_DEVICE":         ErrDuplicateDevice,
+}
+
+// APIError is returned for REST responses with a 4xx or 5xx status.
+type APIError struct {
+	Method     string
+	Path       string
+	StatusCode int
+	// Code is the error code reported by the server, e.g. "FORBIDDEN". It is the
+	// same code the GraphQL API reports in its error extensions.
+	Code       string
+	Message    string
+	RetryAfter time.Duration
+}
+
+func newAPIError(method string, path string, resp *http.Response) *APIError {
+	var body struct {
+		Message string `json:"message"`
+		Code    string `json:"code"`
+	}
+	json.NewDecoder(resp.Body).Decode(&body)
+	if body.Message == "" {
+		body.Message = http.StatusText(resp.StatusCode)
+	}
+
+	return &APIError{
+		Method:     method,
+		Path:       path,
+		StatusCode: resp.StatusCode,
+		Code:       body.Code,
+		Message:    body.Message,
+		RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+	}
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s %s: %d %s", e.Method, e.Path, e.StatusCode, e.Message)
+}
+
+func (e *APIError) Unwrap() error {
+	if err := errorForCode(e.Code); err != nil {
+		return err
+	}
+
+	switch {
+	case e.StatusCode == http.StatusBadRequest:
+		return ErrBadRequest
+	case e.StatusCode == http.StatusUnauthorized:
+		return ErrUnauthenticated
+	case e.StatusCode == http.StatusForbidden:
+		return ErrForbidden
+	case e.StatusCode == http.StatusNotFound:
+		return ErrNotFound
+	case e.StatusCode == http.StatusConflict:
+		return ErrConflict
+	case e.StatusCode == http.StatusTooManyRequests:
+		return ErrRateLimited
+	case e.StatusCode >= 500:
+		return ErrServer
+	}
+	return nil
+}
+
+// GraphQLError is a single entry of the "errors" list of a GraphQL response.
+type GraphQLError struct {
+	Message    string         `json:"message"`
+	Path       []any          `json:"path,omitempty"`
+	Extensions map[string]any `json:"extensions,omitempty"`
+}
+
+func (e *GraphQLError) Error() string {
+	return "graphql: " + e.Message
+}
+
+// Code returns the "code" extension set by the server, if any.
+func (e *GraphQLError) Code() string {
+	code, _ := e.Extensions["code"].(string)
+	return code
+}
+
+func (e *GraphQLError) Unwrap() error {
+	return errorForCode(e.Code())
+}
+
+func errorForCode(code string) error {
+	switch code {
+	case "BAD_USER_INPUT", "GRAPHQL_VALIDATION_FAILED", "GRAPHQL_PARSE_FAILED":
+		return ErrBadRequest
+	case "UNAUTHENTICATED":
+		return ErrUnauthenticated
+	case "FORBIDDEN":
+		return ErrForbidden
+	case "NOT_FOUND":
+		return ErrNotFound
+	case "CONFLICT":
+		return ErrConflict
+	case "RATE_LIMITED":
+		return ErrRateLimited
+	case "QUOTA_EXCEEDED":
+		return ErrQuotaExceeded
+	}
+	return codeErrors[code]
+}
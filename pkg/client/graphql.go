@@ -0,0 +1,36 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+)
+
+type graphQLRequest struct {
+	Query     string         `json:"query"`
+	Variables map[string]any `json:"variables,omitempty"`
+}
+
+type graphQLResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []GraphQLError  `json:"errors"`
+}
+
+// GraphQL executes query against the /query endpoint and decodes the "data"
+// member of the response into out. The first entry of "errors" is returned as a
+// *GraphQLError.
+func (c *Client) GraphQL(ctx context.Context, query string, variables map[string]any, out any) error {
+	response := &graphQLResponse{}
+	_, err := c.do(ctx, http.MethodPost, "/query", &graphQLRequest{Query: query, Variables: variables}, response)
+	if err != nil {
+		return err
+	}
+
+	if len(response.Errors) > 0 {
+		return &response.Errors[0]
+	}
+	if out == nil || len(response.Data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(response.Data, out)
+}
@@ -0,0 +1,100 @@
+package client
+
+import "context"
+
+const defaultPageSize = 100
+
+// DeviceIterator walks over all devices page by page:
+//
+//	it := c.Devices(50)
+//	for it.Next(ctx) {
+//		device := it.Device()
+//	}
+//	if err := it.Err(); err != nil {
+//	}
+type DeviceIterator struct {
+	client       *Client
+	pageSize     int
+	filter       string
+	selector     string
+	connectivity Connectivity
+	offset       int
+	page         []Device
+	index        int
+	done         bool
+	err          error
+}
+
+// Devices returns an iterator fetching pageSize devices per request. A
+// non-positive pageSize uses the default of 100.
+func (c *Client) Devices(pageSize int) *DeviceIterator {
+	if pageSize <= 0 {
+		pageSize = defaultPageSize
+	}
+	return &DeviceIterator{client: c, pageSize: pageSize, index: -1}
+}
+
+// DevicesMatching is like Devices but only walks over the devices matching the
+// filter expression.
+func (c *Client) DevicesMatching(filter string, pageSize int) *DeviceIterator {
+	it := c.Devices(pageSize)
+	it.filter = filter
+	return it
+}
+
+// WithSelector restricts the iterator to the devices whose labels match the
+// selector. It must be called before the first call to Next.
+func (it *DeviceIterator) WithSelector(selector string) *DeviceIterator {
+	it.selector = selector
+	return it
+}
+
+// WithConnectivity restricts the iterator to the devices in the given
+// connectivity state. It must be called before the first call to Next.
+func (it *DeviceIterator) WithConnectivity(connectivity Connectivity) *DeviceIterator {
+	it.connectivity = connectivity
+	return it
+}
+
+// Next advances to the next device, fetching the next page when needed. It
+// returns false when there are no more devices or an error occurred.
+func (it *DeviceIterator) Next(ctx context.Context) bool {
+	if it.err != nil {
+		return false
+	}
+
+	it.index++
+	if it.index < len(it.page) {
+		return true
+	}
+	if it.done {
+		return false
+	}
+
+	page, err := it.client.ListDevices(ctx, &ListOptions{
+		Limit:        it.pageSize,
+		Offset:       it.offset,
+		Filter:       it.filter,
+		Selector:     it.selector,
+		Connectivity: it.connectivity,
+	})
+	if err != nil {
+		it.err = err
+		return false
+	}
+
+	it.page = page.Devices
+	it.index = 0
+	it.offset += len(page.Devices)
+	it.done = len(page.Devices) < it.pageSize || it.offset >= page.Total
+
+	return len(it.page) > 0
+}
+
+func (it *DeviceIterator) Device() Device {
+	return it.page[it.index]
+}
+
+func (it *DeviceIterator) Err() error {
+	return it.err
+}
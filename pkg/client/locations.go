@@ -0,0 +1,147 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// LocationKind is the level of a location in the tree, from sites down to
+// racks.
+type LocationKind string
+
+const (
+	LocationSite     LocationKind = "site"
+	LocationBuilding LocationKind = "building"
+	LocationRoom     LocationKind = "room"
+	LocationRack     LocationKind = "rack"
+)
+
+type Location struct {
+	ID   string       `json:"id"`
+	Name string       `json:"name"`
+	Kind LocationKind `json:"kind"`
+	// ParentID is empty for the sites at the roots of the tree.
+	ParentID  string    `json:"parentId,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	TenantID  string    `json:"tenantId"`
+}
+
+type NewLocationRequest struct {
+	Name     string       `json:"name"`
+	Kind     LocationKind `json:"kind"`
+	ParentID string       `json:"parentId,omitempty"`
+}
+
+// LocationChange records a move of a device. The first change of a device
+// placed on creation has no From.
+type LocationChange struct {
+	From   string    `json:"from,omitempty"`
+	To     string    `json:"to,omitempty"`
+	Reason string    `json:"reason,omitempty"`
+	At     time.Time `json:"at"`
+	Actor  string    `json:"actor,omitempty"`
+}
+
+type moveLocationRequest struct {
+	ParentID string `json:"parentId"`
+}
+
+type moveDeviceRequest struct {
+	LocationID string `json:"locationId"`
+	Reason     string `json:"reason,omitempty"`
+}
+
+// ListLocations lists the children of the parent location, or the sites when
+// parentID is empty.
+func (c *Client) ListLocations(ctx context.Context, parentID string) ([]Location, error) {
+	path := "/v1/locations"
+	if parentID != "" {
+		path += "?parentId=" + url.QueryEscape(parentID)
+	}
+	locations := make([]Location, 0)
+	_, err := c.do(ctx, http.MethodGet, path, nil, &locations)
+	return locations, err
+}
+
+func (c *Client) GetLocation(ctx context.Context, id string) (*Location, error) {
+	location := &Location{}
+	if _, err := c.do(ctx, http.MethodGet, locationPath(id), nil, location); err != nil {
+		return nil, err
+	}
+	return location, nil
+}
+
+// ResolveLocation finds a location by a path of names such as
+// "HQ/Building A/Room 101".
+func (c *Client) ResolveLocation(ctx context.Context, path string) (*Location, error) {
+	location := &Location{}
+	if _, err := c.do(ctx, http.MethodGet, "/v1/locations/resolve?path="+url.QueryEscape(path), nil, location); err != nil {
+		return nil, err
+	}
+	return location, nil
+}
+
+// LocationPath returns the locations from the site down to the location.
+func (c *Client) LocationPath(ctx context.Context, id string) ([]Location, error) {
+	response := &struct {
+		Locations []Location `json:"locations"`
+	}{}
+	if _, err := c.do(ctx, http.MethodGet, locationPath(id)+"/path", nil, response); err != nil {
+		return nil, err
+	}
+	return response.Locations, nil
+}
+
+func (c *Client) CreateLocation(ctx context.Context, request *NewLocationRequest) (*Location, error) {
+	location := &Location{}
+	if _, err := c.do(ctx, http.MethodPost, "/v1/locations", request, location); err != nil {
+		return nil, err
+	}
+	return location, nil
+}
+
+// MoveLocation moves the location with its subtree under another parent, or
+// makes it a root when parentID is empty.
+func (c *Client) MoveLocation(ctx context.Context, id string, parentID string) (*Location, error) {
+	location := &Location{}
+	request := &moveLocationRequest{ParentID: parentID}
+	if _, err := c.do(ctx, http.MethodPost, locationPath(id)+"/move", request, location); err != nil {
+		return nil, err
+	}
+	return location, nil
+}
+
+// DeleteLocation deletes an empty location.
+func (c *Client) DeleteLocation(ctx context.Context, id string) error {
+	_, err := c.do(ctx, http.MethodDelete, locationPath(id), nil, nil)
+	return err
+}
+
+// LocationDevices lists the devices in the location and, if recursive, in all
+// the locations below it.
+func (c *Client) LocationDevices(ctx context.Context, id string, recursive bool) ([]Device, error) {
+	path := locationPath(id) + "/devices"
+	if !recursive {
+		path += "?recursive=false"
+	}
+	devices := make([]Device, 0)
+	_, err := c.do(ctx, http.MethodGet, path, nil, &devices)
+	return devices, err
+}
+
+// MoveDevice places the device in the location, or takes it out of any
+// location when locationID is empty.
+func (c *Client) MoveDevice(ctx context.Context, id string, locationID string, reason string) (*Device, error) {
+	device := &Device{}
+	request := &moveDeviceRequest{LocationID: locationID, Reason: reason}
+	if _, err := c.do(ctx, http.MethodPost, devicePath(id)+"/move", request, device); err != nil {
+		return nil, err
+	}
+	return device, nil
+}
+
+func locationPath(id string) string {
+	return "/v1/locations/" + url.PathEscape(id)
+}
@@ -0,0 +1,172 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// MaintenanceKind tells how the due dates of a maintenance plan are computed.
+type MaintenanceKind string
+
+const (
+	// MaintenanceInterval plans are due a recurrence after the device was last
+	// serviced, e.g. a filter changed every 90 days.
+	MaintenanceInterval MaintenanceKind = "interval"
+	// MaintenanceCalendar plans are due on fixed dates whenever the device was
+	// serviced, e.g. an inspection on the first of every quarter.
+	MaintenanceCalendar MaintenanceKind = "calendar"
+)
+
+type RecurrenceUnit string
+
+const (
+	UnitDay   RecurrenceUnit = "day"
+	UnitWeek  RecurrenceUnit = "week"
+	UnitMonth RecurrenceUnit = "month"
+	UnitYear  RecurrenceUnit = "year"
+)
+
+// MaintenancePlan schedules periodic maintenance of a device every Every
+// Units, starting at StartsAt.
+type MaintenancePlan struct {
+	ID          string          `json:"id"`
+	DeviceID    string          `json:"deviceId"`
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	Kind        MaintenanceKind `json:"kind"`
+	Every       int             `json:"every"`
+	Unit        RecurrenceUnit  `json:"unit"`
+	StartsAt    time.Time       `json:"startsAt"`
+	NextDueAt   *time.Time      `json:"nextDueAt,omitempty"`
+	CreatedAt   time.Time       `json:"createdAt"`
+	// CreatedBy is the subject of the principal who created the plan, if any.
+	CreatedBy string `json:"createdBy,omitempty"`
+	TenantID  string `json:"tenantId"`
+}
+
+type NewMaintenancePlanRequest struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	Kind        MaintenanceKind `json:"kind"`
+	Every       int             `json:"every"`
+	Unit        RecurrenceUnit  `json:"unit"`
+	// StartsAt defaults to one recurrence from now.
+	StartsAt *time.Time `json:"startsAt,omitempty"`
+}
+
+// ServiceKind tells planned maintenance from repairs.
+type ServiceKind string
+
+const (
+	ServiceMaintenance ServiceKind = "maintenance"
+	ServiceRepair      ServiceKind = "repair"
+)
+
+// ServiceRecord records maintenance or a repair done on a device.
+type ServiceRecord struct {
+	ID       string      `json:"id"`
+	DeviceID string      `json:"deviceId"`
+	Kind     ServiceKind `json:"kind"`
+	// PlanID is the plan whose task the service completed, if any.
+	PlanID string `json:"planId,omitempty"`
+	// DueAt is when the completed task of the plan was due.
+	DueAt       *time.Time `json:"dueAt,omitempty"`
+	PerformedAt time.Time  `json:"performedAt"`
+	PerformedBy string     `json:"performedBy,omitempty"`
+	Cost        float64    `json:"cost"`
+	Currency    string     `json:"currency,omitempty"`
+	Notes       string     `json:"notes,omitempty"`
+	// RecordedBy is the subject of the principal who recorded the service, if
+	// any.
+	RecordedBy string `json:"recordedBy,omitempty"`
+	TenantID   string `json:"tenantId"`
+}
+
+type ServiceRecordRequest struct {
+	// Kind defaults to maintenance.
+	Kind   ServiceKind `json:"kind,omitempty"`
+	PlanID string      `json:"planId,omitempty"`
+	// PerformedAt defaults to now.
+	PerformedAt *time.Time `json:"performedAt,omitempty"`
+	PerformedBy string     `json:"performedBy,omitempty"`
+	Cost        float64    `json:"cost"`
+	Currency    string     `json:"currency,omitempty"`
+	Notes       string     `json:"notes,omitempty"`
+}
+
+// MaintenanceTask is a due date of a maintenance plan.
+type MaintenanceTask struct {
+	PlanID   string    `json:"planId"`
+	PlanName string    `json:"planName"`
+	DeviceID string    `json:"deviceId"`
+	DueAt    time.Time `json:"dueAt"`
+	Overdue  bool      `json:"overdue"`
+	// DeviceInRepair flags tasks of devices in repair, which cannot be
+	// serviced until they are back.
+	DeviceInRepair bool `json:"deviceInRepair"`
+}
+
+func (c *Client) CreateMaintenancePlan(ctx context.Context, id string, request *NewMaintenancePlanRequest) (*MaintenancePlan, error) {
+	plan := &MaintenancePlan{}
+	if _, err := c.do(ctx, http.MethodPost, devicePath(id)+"/maintenance-plans", request, plan); err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+func (c *Client) DeleteMaintenancePlan(ctx context.Context, id string, planID string) error {
+	_, err := c.do(ctx, http.MethodDelete, devicePath(id)+"/maintenance-plans/"+url.PathEscape(planID), nil, nil)
+	return err
+}
+
+// MaintenancePlans returns the plans of the device with when they are due
+// next.
+func (c *Client) MaintenancePlans(ctx context.Context, id string) ([]MaintenancePlan, error) {
+	plans := make([]MaintenancePlan, 0)
+	_, err := c.do(ctx, http.MethodGet, devicePath(id)+"/maintenance-plans", nil, &plans)
+	return plans, err
+}
+
+// RecordService records maintenance or a repair of the device. Naming a plan
+// completes its task due next.
+func (c *Client) RecordService(ctx context.Context, id string, request *ServiceRecordRequest) (*ServiceRecord, error) {
+	record := &ServiceRecord{}
+	if _, err := c.do(ctx, http.MethodPost, devicePath(id)+"/service-records", request, record); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+func (c *Client) ServiceRecords(ctx context.Context, id string) ([]ServiceRecord, error) {
+	records := make([]ServiceRecord, 0)
+	_, err := c.do(ctx, http.MethodGet, devicePath(id)+"/service-records", nil, &records)
+	return records, err
+}
+
+// DeviceMaintenanceTasks returns the tasks of the device due within days,
+// overdue ones included.
+func (c *Client) DeviceMaintenanceTasks(ctx context.Context, id string, days int) ([]MaintenanceTask, error) {
+	tasks := make([]MaintenanceTask, 0)
+	_, err := c.do(ctx, http.MethodGet, devicePath(id)+"/maintenance?days="+strconv.Itoa(days), nil, &tasks)
+	return tasks, err
+}
+
+// UpcomingMaintenance returns the tasks of every device due within days,
+// overdue ones included.
+func (c *Client) UpcomingMaintenance(ctx context.Context, days int) ([]MaintenanceTask, error) {
+	tasks := make([]MaintenanceTask, 0)
+	_, err := c.do(ctx, http.MethodGet, "/v1/maintenance/upcoming?days="+strconv.Itoa(days), nil, &tasks)
+	return tasks, err
+}
+
+// OverdueMaintenance returns the tasks overdue at the given time, the longest
+// overdue first.
+func (c *Client) OverdueMaintenance(ctx context.Context, at time.Time) ([]MaintenanceTask, error) {
+	tasks := make([]MaintenanceTask, 0)
+	path := "/v1/maintenance/overdue?at=" + url.QueryEscape(at.Format(time.RFC3339))
+	_, err := c.do(ctx, http.MethodGet, path, nil, &tasks)
+	return tasks, err
+}
@@ -0,0 +1,116 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RelationType is the kind of a link between two devices.
+type RelationType string
+
+const (
+	// RelationContains links a device, such as a chassis, to a device inside
+	// it, such as a blade.
+	RelationContains RelationType = "contains"
+	// RelationConnectedTo links two devices plugged into each other. It has no
+	// direction.
+	RelationConnectedTo RelationType = "connected_to"
+	// RelationReplaces links a device to the device it took over from.
+	RelationReplaces RelationType = "replaces"
+)
+
+// Relationship links the device FromID to the device ToID.
+type Relationship struct {
+	ID        string       `json:"id"`
+	Type      RelationType `json:"type"`
+	FromID    string       `json:"fromId"`
+	ToID      string       `json:"toId"`
+	CreatedAt time.Time    `json:"createdAt"`
+	// CreatedBy is the subject of the principal who linked the devices, if any.
+	CreatedBy string `json:"createdBy,omitempty"`
+	TenantID  string `json:"tenantId"`
+}
+
+type linkRequest struct {
+	Type     RelationType `json:"type"`
+	TargetID string       `json:"targetId"`
+}
+
+// DeviceGraph is the part of the device graph a traversal reached.
+type DeviceGraph struct {
+	Nodes []GraphNode    `json:"nodes"`
+	Edges []Relationship `json:"edges"`
+}
+
+// GraphNode is a device and its distance from the device the traversal
+// started at.
+type GraphNode struct {
+	Device Device `json:"device"`
+	Depth  int    `json:"depth"`
+}
+
+// LinkDevices links the device to the target, e.g. a chassis contains a blade.
+func (c *Client) LinkDevices(ctx context.Context, id string, relationType RelationType, targetID string) (*Relationship, error) {
+	relationship := &Relationship{}
+	request := &linkRequest{Type: relationType, TargetID: targetID}
+	if _, err := c.do(ctx, http.MethodPost, devicePath(id)+"/relationships", request, relationship); err != nil {
+		return nil, err
+	}
+	return relationship, nil
+}
+
+func (c *Client) UnlinkDevices(ctx context.Context, id string, relationshipID string) error {
+	_, err := c.do(ctx, http.MethodDelete, devicePath(id)+"/relationships/"+url.PathEscape(relationshipID), nil, nil)
+	return err
+}
+
+// DeviceRelationships returns the relationships the device is the source or
+// the target of.
+func (c *Client) DeviceRelationships(ctx context.Context, id string) ([]Relationship, error) {
+	relationships := make([]Relationship, 0)
+	_, err := c.do(ctx, http.MethodGet, devicePath(id)+"/relationships", nil, &relationships)
+	return relationships, err
+}
+
+// ChildDevices returns the devices the device contains.
+func (c *Client) ChildDevices(ctx context.Context, id string) ([]Device, error) {
+	devices := make([]Device, 0)
+	_, err := c.do(ctx, http.MethodGet, devicePath(id)+"/children", nil, &devices)
+	return devices, err
+}
+
+// DeviceGraph returns the devices and relationships up to depth relationships
+// away from the device, following only the given types if any.
+func (c *Client) DeviceGraph(ctx context.Context, id string, depth int, types ...RelationType) (*DeviceGraph, error) {
+	query := url.Values{}
+	if depth > 0 {
+		query.Set("depth", strconv.Itoa(depth))
+	}
+	if len(types) > 0 {
+		names := make([]string, len(types))
+		for i, relationType := range types {
+			names[i] = string(relationType)
+		}
+		query.Set("type", strings.Join(names, ","))
+	}
+	path := devicePath(id) + "/graph"
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+
+	graph := &DeviceGraph{}
+	if _, err := c.do(ctx, http.MethodGet, path, nil, graph); err != nil {
+		return nil, err
+	}
+	return graph, nil
+}
+
+// DeleteDeviceTree deletes the device with the devices it contains.
+func (c *Client) DeleteDeviceTree(ctx context.Context, id string) error {
+	_, err := c.do(ctx, http.MethodDelete, devicePath(id)+"?cascade=true", nil, nil)
+	return err
+}
@@ -0,0 +1,173 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+type SoftwareKind string
+
+const (
+	SoftwareFirmware    SoftwareKind = "firmware"
+	SoftwareOS          SoftwareKind = "os"
+	SoftwareApplication SoftwareKind = "application"
+)
+
+// InstalledSoftware is a firmware, an operating system or an application
+// running on a device.
+type InstalledSoftware struct {
+	ID       string       `json:"id"`
+	DeviceID string       `json:"deviceId"`
+	Kind     SoftwareKind `json:"kind"`
+	Name     string       `json:"name"`
+	// Version is a semantic version such as 2.3.1.
+	Version     string    `json:"version"`
+	InstalledAt time.Time `json:"installedAt"`
+	// CreatedBy is the subject of the principal who recorded the software, if
+	// any.
+	CreatedBy string `json:"createdBy,omitempty"`
+	TenantID  string `json:"tenantId"`
+}
+
+type SoftwareRequest struct {
+	Kind    SoftwareKind `json:"kind"`
+	Name    string       `json:"name"`
+	Version string       `json:"version"`
+	// InstalledAt defaults to when the software is recorded.
+	InstalledAt *time.Time `json:"installedAt,omitempty"`
+}
+
+// SoftwarePolicy requires the devices of a brand and of a type, or of any
+// when they are empty, to run at least MinVersion of a software.
+type SoftwarePolicy struct {
+	ID          string       `json:"id"`
+	Description string       `json:"description,omitempty"`
+	Brand       string       `json:"brand,omitempty"`
+	Type        string       `json:"type,omitempty"`
+	Kind        SoftwareKind `json:"kind"`
+	// Software is the name of the software, empty for every software of the
+	// kind, e.g. whatever firmware the devices run.
+	Software   string    `json:"software,omitempty"`
+	MinVersion string    `json:"minVersion"`
+	CreatedAt  time.Time `json:"createdAt"`
+	CreatedBy  string    `json:"createdBy,omitempty"`
+	TenantID   string    `json:"tenantId"`
+}
+
+type SoftwarePolicyRequest struct {
+	Description string `json:"description,omitempty"`
+	Brand       string `json:"brand,omitempty"`
+	Type        string `json:"type,omitempty"`
+	// Kind defaults to firmware.
+	Kind       SoftwareKind `json:"kind,omitempty"`
+	Software   string       `json:"software,omitempty"`
+	MinVersion string       `json:"minVersion"`
+}
+
+type ViolationReason string
+
+const (
+	// ViolationOutdated is a software older than the policy requires.
+	ViolationOutdated ViolationReason = "outdated"
+	// ViolationMissing is a device running no software the policy covers.
+	ViolationMissing ViolationReason = "missing"
+)
+
+type PolicyViolation struct {
+	Policy SoftwarePolicy  `json:"policy"`
+	Reason ViolationReason `json:"reason"`
+	// Software is the name of the outdated software, or the required one if
+	// the policy names it.
+	Software string `json:"software,omitempty"`
+	// Installed is the version of the outdated software.
+	Installed string `json:"installed,omitempty"`
+	Required  string `json:"required"`
+}
+
+// DeviceCompliance tells whether a device complies with the policies which
+// apply to it.
+type DeviceCompliance struct {
+	Device     Device            `json:"device"`
+	Compliant  bool              `json:"compliant"`
+	Violations []PolicyViolation `json:"violations"`
+	// Policies is the number of policies applying to the device.
+	Policies int `json:"policies"`
+}
+
+// ComplianceReport lists the devices violating a policy.
+type ComplianceReport struct {
+	CheckedAt time.Time `json:"checkedAt"`
+	Policies  int       `json:"policies"`
+	// Devices is the number of devices at least one policy applies to.
+	Devices   int                `json:"devices"`
+	Compliant int                `json:"compliant"`
+	Violators []DeviceCompliance `json:"violators"`
+}
+
+// RecordSoftware records the version of a software running on the device,
+// replacing the one recorded for the same kind and name.
+func (c *Client) RecordSoftware(ctx context.Context, id string, request *SoftwareRequest) (*InstalledSoftware, error) {
+	software := &InstalledSoftware{}
+	if _, err := c.do(ctx, http.MethodPut, devicePath(id)+"/software", request, software); err != nil {
+		return nil, err
+	}
+	return software, nil
+}
+
+func (c *Client) DeleteSoftware(ctx context.Context, id string, softwareID string) error {
+	_, err := c.do(ctx, http.MethodDelete, devicePath(id)+"/software/"+url.PathEscape(softwareID), nil, nil)
+	return err
+}
+
+// Software returns the software installed on the device, sorted by kind and
+// name.
+func (c *Client) Software(ctx context.Context, id string) ([]InstalledSoftware, error) {
+	software := make([]InstalledSoftware, 0)
+	_, err := c.do(ctx, http.MethodGet, devicePath(id)+"/software", nil, &software)
+	return software, err
+}
+
+// DeviceCompliance checks the software of the device against the policies
+// which apply to it.
+func (c *Client) DeviceCompliance(ctx context.Context, id string) (*DeviceCompliance, error) {
+	compliance := &DeviceCompliance{}
+	if _, err := c.do(ctx, http.MethodGet, devicePath(id)+"/compliance", nil, compliance); err != nil {
+		return nil, err
+	}
+	return compliance, nil
+}
+
+// ComplianceReport lists the devices violating the software policies, or the
+// one with policyID unless it is empty.
+func (c *Client) ComplianceReport(ctx context.Context, policyID string) (*ComplianceReport, error) {
+	path := "/v1/compliance"
+	if policyID != "" {
+		path += "?" + url.Values{"policy": {policyID}}.Encode()
+	}
+	report := &ComplianceReport{}
+	if _, err := c.do(ctx, http.MethodGet, path, nil, report); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+func (c *Client) SoftwarePolicies(ctx context.Context) ([]SoftwarePolicy, error) {
+	policies := make([]SoftwarePolicy, 0)
+	_, err := c.do(ctx, http.MethodGet, "/v1/compliance/policies", nil, &policies)
+	return policies, err
+}
+
+func (c *Client) CreateSoftwarePolicy(ctx context.Context, request *SoftwarePolicyRequest) (*SoftwarePolicy, error) {
+	policy := &SoftwarePolicy{}
+	if _, err := c.do(ctx, http.MethodPost, "/v1/compliance/policies", request, policy); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+func (c *Client) DeleteSoftwarePolicy(ctx context.Context, policyID string) error {
+	_, err := c.do(ctx, http.MethodDelete, "/v1/compliance/policies/"+url.PathEscape(policyID), nil, nil)
+	return err
+}
@@ -0,0 +1,72 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Reading is a value of a metric of a device at a time. Readings the server
+// downsampled are averaged over their bucket and carry its start.
+type Reading struct {
+	Metric string    `json:"metric"`
+	Value  float64   `json:"value"`
+	At     time.Time `json:"at"`
+}
+
+type ReadingBatch struct {
+	Readings []Reading `json:"readings"`
+}
+
+// ReadingAggregate summarizes the readings of a metric from Start until End.
+type ReadingAggregate struct {
+	Metric string    `json:"metric"`
+	Start  time.Time `json:"start"`
+	End    time.Time `json:"end"`
+	Count  int       `json:"count"`
+	Min    float64   `json:"min"`
+	Max    float64   `json:"max"`
+	Mean   float64   `json:"mean"`
+	// Last is the value of the latest reading of the period.
+	Last float64 `json:"last"`
+}
+
+// IngestReadings sends a batch of readings of the device, readings without a
+// time are taken when the server receives them.
+func (c *Client) IngestReadings(ctx context.Context, id string, readings []Reading) error {
+	_, err := c.do(ctx, http.MethodPost, devicePath(id)+"/telemetry", &ReadingBatch{Readings: readings}, nil)
+	return err
+}
+
+// LatestReadings returns the most recent reading of every metric of the
+// device.
+func (c *Client) LatestReadings(ctx context.Context, id string) ([]Reading, error) {
+	readings := make([]Reading, 0)
+	_, err := c.do(ctx, http.MethodGet, devicePath(id)+"/telemetry", nil, &readings)
+	return readings, err
+}
+
+// Readings returns the readings of the metric taken in [from, to), readings
+// older than a day are downsampled.
+func (c *Client) Readings(ctx context.Context, id string, metric string, from time.Time, to time.Time) ([]Reading, error) {
+	readings := make([]Reading, 0)
+	_, err := c.do(ctx, http.MethodGet, devicePath(id)+"/telemetry/"+url.PathEscape(metric)+"?"+readingsQuery(from, to).Encode(), nil, &readings)
+	return readings, err
+}
+
+// ReadingAggregates summarizes the readings of the metric taken in [from, to)
+// per step, or over the whole range with a zero step.
+func (c *Client) ReadingAggregates(ctx context.Context, id string, metric string, from time.Time, to time.Time, step time.Duration) ([]ReadingAggregate, error) {
+	query := readingsQuery(from, to)
+	if step > 0 {
+		query.Set("step", step.String())
+	}
+	aggregates := make([]ReadingAggregate, 0)
+	_, err := c.do(ctx, http.MethodGet, devicePath(id)+"/telemetry/"+url.PathEscape(metric)+"/aggregate?"+query.Encode(), nil, &aggregates)
+	return aggregates, err
+}
+
+func readingsQuery(from time.Time, to time.Time) url.Values {
+	return url.Values{"from": {from.Format(time.RFC3339)}, "to": {to.Format(time.RFC3339)}}
+}
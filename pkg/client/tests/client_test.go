@@ -0,0 +1,193 @@
+package tests
+
+import (
+	"context"
+	"devices_crud/internal/apierrors"
+	"devices_crud/internal/devices"
+	"devices_crud/internal/drivers/graph"
+	"devices_crud/internal/drivers/rest"
+	"devices_crud/pkg/client"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	deps := devices.NewDevicesDependencies(
+		&devices.DeviceDependencies{UseMocks: true, Logger: slog.New(slog.NewTextHandler(os.Stdout, nil))})
+	rest.BuildRoutes(router, deps)
+	graph.BuildRoutes(router, deps)
+	return router
+}
+
+func setupClient(t *testing.T, handler http.Handler) *client.Client {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return client.New(server.URL, client.WithBackoff(time.Millisecond, 5*time.Millisecond))
+}
+
+func TestShouldManageDeviceLifecycle(t *testing.T) {
+	c := setupClient(t, setupRouter())
+	ctx := context.Background()
+
+	id, err := c.CreateDevice(ctx, &client.NewDeviceRequest{Name: "phone", DeviceBrand: "Apple"})
+	assert.Nil(t, err)
+
+	device, err := c.GetDevice(ctx, id)
+	assert.Nil(t, err)
+	assert.Equal(t, "phone", device.Name)
+
+	brand := "Samsung"
+	err = c.PatchDevice(ctx, &client.PatchDeviceRequest{ID: id, DeviceBrand: &brand})
+	assert.Nil(t, err)
+
+	device, err = c.GetDevice(ctx, id)
+	assert.Nil(t, err)
+	assert.Equal(t, "Samsung", device.DeviceBrand)
+
+	device.Name = "tablet"
+	replaced, err := c.ReplaceDevice(ctx, device)
+	assert.Nil(t, err)
+	assert.Equal(t, "tablet", replaced.Name)
+	assert.Equal(t, "Samsung", replaced.DeviceBrand)
+
+	found, err := c.SearchDevices(ctx, "samsung")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(found))
+
+	err = c.DeleteDevice(ctx, id)
+	assert.Nil(t, err)
+
+	_, err = c.GetDevice(ctx, id)
+	assert.True(t, errors.Is(err, client.ErrNotFound))
+
+	var apiErr *client.APIError
+	assert.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, 404, apiErr.StatusCode)
+}
+
+func TestShouldIterateOverAllPages(t *testing.T) {
+	c := setupClient(t, setupRouter())
+	ctx := context.Background()
+
+	created := make([]string, 0)
+	for i := 0; i < 7; i++ {
+		id, err := c.CreateDevice(ctx, &client.NewDeviceRequest{Name: fmt.Sprintf("device_%d", i), DeviceBrand: "brand"})
+		assert.Nil(t, err)
+		created = append(created, id)
+	}
+
+	page, err := c.ListDevices(ctx, &client.ListOptions{Limit: 3, Offset: 6})
+	assert.Nil(t, err)
+	assert.Equal(t, 7, page.Total)
+	assert.Equal(t, 1, len(page.Devices))
+
+	iterated := make([]string, 0)
+	it := c.Devices(3)
+	for it.Next(ctx) {
+		iterated = append(iterated, it.Device().ID)
+	}
+	assert.Nil(t, it.Err())
+	assert.ElementsMatch(t, created, iterated)
+}
+
+func TestShouldRetryOnServerErrors(t *testing.T) {
+	router := setupRouter()
+	var calls atomic.Int32
+	flaky := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch calls.Add(1) {
+		case 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case 2:
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			router.ServeHTTP(w, r)
+		}
+	})
+	c := setupClient(t, flaky)
+
+	page, err := c.ListDevices(context.Background(), nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(page.Devices))
+	assert.Equal(t, int32(3), calls.Load())
+}
+
+func TestShouldGiveUpAfterMaxRetries(t *testing.T) {
+	var calls atomic.Int32
+	failing := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	server := httptest.NewServer(failing)
+	defer server.Close()
+	c := client.New(server.URL, client.WithRetries(2), client.WithBackoff(time.Millisecond, time.Millisecond))
+
+	_, err := c.GetDevice(context.Background(), "1")
+	assert.True(t, errors.Is(err, client.ErrServer))
+	assert.Equal(t, int32(3), calls.Load())
+
+	_, err = c.CreateDevice(context.Background(), &client.NewDeviceRequest{Name: "phone"})
+	assert.True(t, errors.Is(err, client.ErrServer))
+	assert.Equal(t, int32(4), calls.Load())
+}
+
+func TestShouldQueryGraphQL(t *testing.T) {
+	c := setupClient(t, setupRouter())
+	ctx := context.Background()
+
+	id, err := c.CreateDevice(ctx, &client.NewDeviceRequest{Name: "phone", DeviceBrand: "Apple"})
+	assert.Nil(t, err)
+
+	var result struct {
+		Device struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+		} `json:"device"`
+	}
+	err = c.GraphQL(ctx, `query($id: String!) { device(id: $id) { id name } }`, map[string]any{"id": id}, &result)
+	assert.Nil(t, err)
+	assert.Equal(t, id, result.Device.ID)
+	assert.Equal(t, "phone", result.Device.Name)
+
+	err = c.GraphQL(ctx, `query { device(id: "missing") { id } }`, nil, &result)
+	assert.True(t, errors.Is(err, client.ErrNotFound))
+}
+
+func TestShouldMapEveryServerErrorCode(t *testing.T) {
+	kinds := map[int]error{
+		http.StatusBadRequest:   client.ErrBadRequest,
+		http.StatusUnauthorized: client.ErrUnauthenticated,
+		http.StatusNotFound:     client.ErrNotFound,
+		http.StatusConflict:     client.ErrConflict,
+	}
+	mapped := map[error]string{}
+	for _, code := range apierrors.Codes {
+		graphQLErr := (&client.GraphQLError{Extensions: map[string]any{"code": code.Code}}).Unwrap()
+		apiErr := &client.APIError{StatusCode: code.Status, Code: code.Code}
+		if assert.NotNil(t, graphQLErr, code.Code) {
+			assert.True(t, errors.Is(apiErr, graphQLErr), code.Code)
+			assert.Empty(t, mapped[graphQLErr], "%s and %s map to the same error", code.Code, mapped[graphQLErr])
+			mapped[graphQLErr] = code.Code
+		}
+		if kind, ok := kinds[code.Status]; ok {
+			assert.True(t, errors.Is(apiErr, kind), code.Code)
+		}
+	}
+
+	err := &client.APIError{StatusCode: http.StatusConflict, Code: "DEVICE_ASSIGNED"}
+	assert.True(t, errors.Is(err, client.ErrDeviceAssigned))
+	assert.True(t, errors.Is(err, client.ErrConflict))
+	assert.False(t, errors.Is(err, client.ErrDuplicateDevice))
+}
@@ -0,0 +1,221 @@
+package tests
+
+import (
+	"devices_crud/internal/devices/model"
+	"devices_crud/pkg/client"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// assertSameWire checks that sdk decodes every field of the JSON encoding of
+// server and encodes it back unchanged, so that the SDK types keep up with the
+// API. server should set every field.
+func assertSameWire(t *testing.T, server any, sdk any) {
+	t.Helper()
+	encoded, err := json.Marshal(server)
+	assert.Nil(t, err)
+	assert.Nil(t, json.Unmarshal(encoded, sdk))
+	roundTripped, err := json.Marshal(sdk)
+	assert.Nil(t, err)
+	assert.JSONEq(t, string(encoded), string(roundTripped))
+}
+
+var wireTime = time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func TestShouldShareTheWireFormatOfDevices(t *testing.T) {
+	name, value := "phone", "prod"
+	device := model.Device{
+		ID: "id", Name: "phone", DeviceBrand: "Apple", CreatedAt: wireTime, TenantID: "acme", Type: "laptop",
+		Attributes:      model.Attributes{"ramGb": 16.0, "tags": []interface{}{"a"}},
+		Labels:          map[string]string{"env": "prod"},
+		Status:          model.StatusInService,
+		StatusHistory:   []model.StatusChange{{From: model.StatusOrdered, To: model.StatusInService, Reason: "ready", At: wireTime, Actor: "alice"}},
+		LocationID:      "room",
+		LocationHistory: []model.LocationChange{{From: "site", To: "room", Reason: "moved", At: wireTime, Actor: "alice"}},
+		LastSeenAt:      &wireTime,
+	}
+
+	assertSameWire(t, device, &client.Device{})
+	assertSameWire(t, model.SearchResult{Device: device, Score: 0.5}, &client.SearchResult{})
+	assertSameWire(t, model.NewDeviceRequest{
+		Name: "phone", DeviceBrand: "Apple", Type: "laptop", Attributes: model.Attributes{"ramGb": 16.0},
+		Labels: map[string]string{"env": "prod"}, Status: model.StatusReceived, LocationID: "room",
+	}, &client.NewDeviceRequest{})
+	assertSameWire(t, model.PatchDeviceRequest{
+		ID: "id", Name: &name, DeviceBrand: &name, Type: &name, Attributes: model.Attributes{"os": nil},
+		Labels: map[string]*string{"env": &value, "team": nil},
+	}, &client.PatchDeviceRequest{})
+
+	for _, status := range model.Statuses {
+		assert.Contains(t, []client.Status{
+			client.StatusOrdered, client.StatusReceived, client.StatusProvisioned, client.StatusInService,
+			client.StatusInRepair, client.StatusRetired, client.StatusDisposed,
+		}, client.Status(status))
+	}
+}
+
+func TestShouldShareTheWireFormatOfDeviceTypes(t *testing.T) {
+	assertSameWire(t, model.DeviceType{
+		Name: "laptop", Description: "portable", Schema: json.RawMessage(`{"type":"object"}`), CreatedAt: wireTime, TenantID: "acme",
+	}, &client.DeviceType{})
+}
+
+func TestShouldShareTheWireFormatOfAssignments(t *testing.T) {
+	assertSameWire(t, model.Assignment{
+		ID: "id", DeviceID: "device", Assignee: "bob@example.com", CheckedOutAt: wireTime, DueAt: &wireTime,
+		CheckedInAt: &wireTime, Note: "spare", CheckedOutBy: "alice", TenantID: "acme",
+	}, &client.Assignment{})
+	assertSameWire(t, model.CheckOutRequest{Assignee: "bob@example.com", DueAt: &wireTime, Note: "spare"}, &client.CheckOutRequest{})
+}
+
+func TestShouldShareTheWireFormatOfLocations(t *testing.T) {
+	assertSameWire(t, model.Location{
+		ID: "id", Name: "Room 101", Kind: model.LocationRoom, ParentID: "building", CreatedAt: wireTime, TenantID: "acme",
+	}, &client.Location{})
+	assertSameWire(t, model.NewLocationRequest{Name: "Room 101", Kind: model.LocationRoom, ParentID: "building"}, &client.NewLocationRequest{})
+
+	for _, kind := range model.LocationKinds {
+		assert.Contains(t, []client.LocationKind{
+			client.LocationSite, client.LocationBuilding, client.LocationRoom, client.LocationRack,
+		}, client.LocationKind(kind))
+	}
+}
+
+func TestShouldShareTheWireFormatOfBrands(t *testing.T) {
+	brand := model.Brand{
+		Name: "HP", Aliases: []string{"Hewlett-Packard"}, Models: []model.DeviceModel{{Name: "LaserJet Pro", Aliases: []string{"LJ Pro"}}},
+		CreatedAt: wireTime, TenantID: "acme",
+	}
+	assertSameWire(t, brand, &client.Brand{})
+	assertSameWire(t, model.MigrateBrandsRequest{DryRun: true, AddMissing: true}, &client.MigrateBrandsRequest{})
+	assertSameWire(t, model.BrandMigration{
+		DryRun: true, Changes: []model.BrandChange{{DeviceID: "id", From: "hp", To: "HP"}}, Added: []model.Brand{brand},
+	}, &client.BrandMigration{})
+}
+
+func TestShouldShareTheWireFormatOfRelationships(t *testing.T) {
+	relationship := model.Relationship{
+		ID: "id", Type: model.RelationContains, FromID: "chassis", ToID: "blade", CreatedAt: wireTime, CreatedBy: "alice", TenantID: "acme",
+	}
+	assertSameWire(t, relationship, &client.Relationship{})
+	assertSameWire(t, model.DeviceGraph{
+		Nodes: []model.GraphNode{{Device: model.Device{ID: "blade", Name: "blade", CreatedAt: wireTime, Status: model.StatusInService}, Depth: 1}},
+		Edges: []model.Relationship{relationship},
+	}, &client.DeviceGraph{})
+
+	for _, relationType := range model.RelationTypes {
+		assert.Contains(t, []client.RelationType{
+			client.RelationContains, client.RelationConnectedTo, client.RelationReplaces,
+		}, client.RelationType(relationType))
+	}
+}
+
+func TestShouldShareTheWireFormatOfMaintenance(t *testing.T) {
+	assertSameWire(t, model.MaintenancePlan{
+		ID: "id", DeviceID: "device", Name: "filter", Description: "change the filter", Kind: model.MaintenanceInterval,
+		Every: 3, Unit: model.UnitMonth, StartsAt: wireTime, NextDueAt: &wireTime, CreatedAt: wireTime, CreatedBy: "alice", TenantID: "acme",
+	}, &client.MaintenancePlan{})
+	assertSameWire(t, model.NewMaintenancePlanRequest{
+		Name: "filter", Description: "change the filter", Kind: model.MaintenanceCalendar, Every: 1, Unit: model.UnitYear, StartsAt: &wireTime,
+	}, &client.NewMaintenancePlanRequest{})
+	assertSameWire(t, model.ServiceRecord{
+		ID: "id", DeviceID: "device", Kind: model.ServiceMaintenance, PlanID: "plan", DueAt: &wireTime, PerformedAt: wireTime,
+		PerformedBy: "bob", Cost: 12.5, Currency: "EUR", Notes: "done", RecordedBy: "alice", TenantID: "acme",
+	}, &client.ServiceRecord{})
+	assertSameWire(t, model.ServiceRecordRequest{
+		Kind: model.ServiceRepair, PlanID: "plan", PerformedAt: &wireTime, PerformedBy: "bob", Cost: 12.5, Currency: "EUR", Notes: "done",
+	}, &client.ServiceRecordRequest{})
+	assertSameWire(t, model.MaintenanceTask{
+		PlanID: "plan", PlanName: "filter", DeviceID: "device", DueAt: wireTime, Overdue: true, DeviceInRepair: true,
+	}, &client.MaintenanceTask{})
+
+	for _, kind := range model.MaintenanceKinds {
+		assert.Contains(t, []client.MaintenanceKind{client.MaintenanceInterval, client.MaintenanceCalendar}, client.MaintenanceKind(kind))
+	}
+	for _, unit := range model.RecurrenceUnits {
+		assert.Contains(t, []client.RecurrenceUnit{client.UnitDay, client.UnitWeek, client.UnitMonth, client.UnitYear}, client.RecurrenceUnit(unit))
+	}
+	for _, kind := range model.ServiceKinds {
+		assert.Contains(t, []client.ServiceKind{client.ServiceMaintenance, client.ServiceRepair}, client.ServiceKind(kind))
+	}
+}
+
+func TestShouldShareTheWireFormatOfContracts(t *testing.T) {
+	contract := model.Contract{
+		ID: "id", DeviceID: "device", Kind: model.ContractSupport, Provider: "Acme", Reference: "C-1", StartsAt: wireTime,
+		EndsAt: wireTime.AddDate(1, 0, 0), Coverage: []string{"parts"}, CreatedAt: wireTime, CreatedBy: "alice", TenantID: "acme",
+	}
+	assertSameWire(t, contract, &client.Contract{})
+	assertSameWire(t, model.ContractRequest{
+		Kind: model.ContractWarranty, Provider: "Acme", Reference: "C-1", StartsAt: wireTime, EndsAt: wireTime.AddDate(1, 0, 0), Coverage: []string{"parts"},
+	}, &client.ContractRequest{})
+	assertSameWire(t, model.ExpiringContract{Contract: contract, DaysLeft: 3, Renewed: true}, &client.ExpiringContract{})
+
+	for _, kind := range model.ContractKinds {
+		assert.Contains(t, []client.ContractKind{client.ContractWarranty, client.ContractSupport}, client.ContractKind(kind))
+	}
+}
+
+func TestShouldShareTheWireFormatOfReadings(t *testing.T) {
+	assertSameWire(t, model.ReadingBatch{Readings: []model.Reading{{Metric: "battery", Value: 80, At: wireTime}}}, &client.ReadingBatch{})
+	assertSameWire(t, model.ReadingAggregate{
+		Metric: "battery", Start: wireTime, End: wireTime.Add(time.Hour), Count: 2, Min: 80, Max: 90, Mean: 85, Last: 80,
+	}, &client.ReadingAggregate{})
+}
+
+func TestShouldShareTheWireFormatOfConnectivity(t *testing.T) {
+	assertSameWire(t, model.ConnectivityStatus{
+		DeviceID: "device", Status: model.ConnectivityOnline, LastSeenAt: &wireTime, Timeout: "5m0s", At: wireTime,
+	}, &client.ConnectivityStatus{})
+
+	for _, connectivity := range model.Connectivities {
+		assert.Contains(t, []client.Connectivity{
+			client.ConnectivityOnline, client.ConnectivityOffline, client.ConnectivityUnknown,
+		}, client.Connectivity(connectivity))
+	}
+}
+
+func TestShouldShareTheWireFormatOfSoftware(t *testing.T) {
+	assertSameWire(t, model.InstalledSoftware{
+		ID: "id", DeviceID: "device", Kind: model.SoftwareFirmware, Name: "bios", Version: "2.3.1", InstalledAt: wireTime,
+		CreatedBy: "alice", TenantID: "acme",
+	}, &client.InstalledSoftware{})
+	assertSameWire(t, model.SoftwareRequest{Kind: model.SoftwareOS, Name: "linux", Version: "6.1.0", InstalledAt: &wireTime}, &client.SoftwareRequest{})
+	policy := model.SoftwarePolicy{
+		ID: "id", Description: "patched", Brand: "Apple", Type: "laptop", Kind: model.SoftwareOS, Software: "macos",
+		MinVersion: "14.0.0", CreatedAt: wireTime, CreatedBy: "alice", TenantID: "acme",
+	}
+	assertSameWire(t, policy, &client.SoftwarePolicy{})
+	assertSameWire(t, model.SoftwarePolicyRequest{
+		Description: "patched", Brand: "Apple", Type: "laptop", Kind: model.SoftwareOS, Software: "macos", MinVersion: "14.0.0",
+	}, &client.SoftwarePolicyRequest{})
+	compliance := model.DeviceCompliance{
+		Device: model.Device{ID: "device", Name: "laptop", CreatedAt: wireTime, Status: model.StatusInService},
+		Violations: []model.PolicyViolation{{
+			Policy: policy, Reason: model.ViolationOutdated, Software: "macos", Installed: "13.0.0", Required: "14.0.0",
+		}},
+		Policies: 1,
+	}
+	assertSameWire(t, compliance, &client.DeviceCompliance{})
+	assertSameWire(t, model.ComplianceReport{
+		CheckedAt: wireTime, Policies: 1, Devices: 2, Compliant: 1, Violators: []model.DeviceCompliance{compliance},
+	}, &client.ComplianceReport{})
+
+	for _, kind := range model.SoftwareKinds {
+		assert.Contains(t, []client.SoftwareKind{
+			client.SoftwareFirmware, client.SoftwareOS, client.SoftwareApplication,
+		}, client.SoftwareKind(kind))
+	}
+}
+
+func TestShouldShareTheWireFormatOfDuplicates(t *testing.T) {
+	assertSameWire(t, model.DuplicateCandidate{
+		Device:    model.Device{ID: "older", Name: "laptop", CreatedAt: wireTime, Status: model.StatusInService},
+		Duplicate: model.Device{ID: "newer", Name: "Laptop", CreatedAt: wireTime, Status: model.StatusOrdered},
+		Score:     0.9, Key: "serial",
+	}, &client.DuplicateCandidate{})
+	assertSameWire(t, model.MergeRequest{SourceID: "newer"}, &client.MergeRequest{})
+}